@@ -0,0 +1,77 @@
+package risor
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/bytecode"
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+)
+
+// CapabilityUse is a capability a script could use, along with the env
+// functions that grant it.
+type CapabilityUse struct {
+	Capability object.Capability
+
+	// Functions lists the functions that need the capability, sorted, as
+	// "name" for builtins and "module.name" for module functions. A module
+	// the script uses as a value, rather than only through its attributes,
+	// is listed by its name alone.
+	Functions []string
+}
+
+// Capabilities reports what a compiled script could do outside the
+// sandbox, based on the capability tags of the builtins and modules it uses
+// from its env. Pass the options the code was compiled with. Like
+// AnalyzeDependencies, the analysis reads the bytecode without running it.
+//
+// Functions called through a module attribute, as in net.probe, contribute
+// their own capabilities. A module used in any other way, such as assigned
+// to a variable or passed to a function, contributes the capabilities of
+// all of its functions. Go functions and other values in the env are not
+// tagged and contribute nothing.
+func Capabilities(code *bytecode.Code, opts ...Option) []CapabilityUse {
+	o := collectOptions(opts...)
+	usage := code.Dependencies()
+	envKeys := code.EnvKeys()
+	found := map[object.Capability]map[string]bool{}
+	add := func(caps []object.Capability, function string) {
+		for _, c := range caps {
+			if found[c] == nil {
+				found[c] = map[string]bool{}
+			}
+			found[c][function] = true
+		}
+	}
+
+	for _, name := range usage.Reads {
+		if !slices.Contains(envKeys, name) {
+			continue
+		}
+		switch value := o.env[name].(type) {
+		case *object.Builtin:
+			add(value.Capabilities(), name)
+		case *object.Module:
+			if slices.Contains(usage.Values, name) {
+				add(value.Capabilities(), name)
+			}
+			for _, attr := range usage.Attributes[name] {
+				switch member, _ := value.GetAttr(attr); member := member.(type) {
+				case *object.Builtin:
+					add(member.Capabilities(), name+"."+attr)
+				case *object.Module:
+					add(member.Capabilities(), name+"."+attr)
+				}
+			}
+		}
+	}
+
+	uses := make([]CapabilityUse, 0, len(found))
+	for _, c := range slices.Sorted(maps.Keys(found)) {
+		uses = append(uses, CapabilityUse{
+			Capability: c,
+			Functions:  slices.Sorted(maps.Keys(found[c])),
+		})
+	}
+	return uses
+}
@@ -0,0 +1,57 @@
+package risor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+func capabilitiesEnv() map[string]any {
+	env := standardEnv()
+	env["read_file"] = object.NewNoopBuiltin("read_file").WithCapabilities(object.CapFSRead)
+	env["run"] = object.NewNoopBuiltin("run").WithCapabilities(object.CapExec, object.CapFSWrite)
+	return env
+}
+
+func TestCapabilities(t *testing.T) {
+	env := capabilitiesEnv()
+	code, err := Compile(context.Background(), `
+let ip = net.parse_ip("10.0.0.1")
+function check(host) { net.probe(host, 80) }
+if (false) { read_file("a.txt") }
+len([1, 2])
+`, WithEnv(env))
+	assert.Nil(t, err)
+
+	uses := Capabilities(code, WithEnv(env))
+	assert.Equal(t, uses, []CapabilityUse{
+		{Capability: object.CapFSRead, Functions: []string{"read_file"}},
+		{Capability: object.CapNet, Functions: []string{"net.probe"}},
+	})
+}
+
+func TestCapabilitiesModuleValue(t *testing.T) {
+	env := capabilitiesEnv()
+	code, err := Compile(context.Background(), `
+let n = net
+n.parse_ip("10.0.0.1")
+[1].map(run)
+`, WithEnv(env))
+	assert.Nil(t, err)
+
+	uses := Capabilities(code, WithEnv(env))
+	assert.Equal(t, uses, []CapabilityUse{
+		{Capability: object.CapExec, Functions: []string{"run"}},
+		{Capability: object.CapFSWrite, Functions: []string{"run"}},
+		{Capability: object.CapNet, Functions: []string{"net"}},
+	})
+}
+
+func TestCapabilitiesNone(t *testing.T) {
+	env := capabilitiesEnv()
+	code, err := Compile(context.Background(), `math.sqrt(len("abc"))`, WithEnv(env))
+	assert.Nil(t, err)
+	assert.Len(t, Capabilities(code, WithEnv(env)), 0)
+}
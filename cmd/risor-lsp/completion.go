@@ -10,22 +10,22 @@ import (
 
 // Risor keywords for completion
 var risorKeywords = []string{
-	"catch", "const", "else", "false", "finally",
+	"catch", "const", "else", "enum", "false", "finally",
 	"function", "if", "in", "let", "match", "nil", "not", "null", "return", "struct",
 	"throw", "true", "try",
 }
 
 // Common built-in functions
 var risorBuiltins = []string{
-	"all", "any", "assert", "bool", "byte", "call", "chunk", "coalesce",
-	"decode", "encode", "filter", "float", "getattr",
-	"int", "keys", "len", "list", "reversed",
-	"sorted", "sprintf", "string", "type",
+	"all", "any", "assert", "bool", "buffer", "byte", "call", "chunk",
+	"coalesce", "decode", "dir", "encode", "filter", "float", "fnmatch",
+	"getattr", "inspect", "int", "iter", "keys", "len", "list", "query",
+	"reversed", "sizeof", "sorted", "sprintf", "string", "tuple", "type",
 }
 
 // Common modules
 var risorModules = []string{
-	"math", "rand", "regexp", "strings", "time",
+	"atexit", "binary", "cache", "ctx", "diff", "errors", "events", "funcs", "iters", "math", "net", "os", "parallel", "prompt", "proto", "rand", "rate", "regexp", "render", "retry", "stats", "strings", "sync", "table", "task", "term", "time", "unicode", "valid",
 }
 
 func (s *Server) Completion(ctx context.Context, params *protocol.CompletionParams) (*protocol.CompletionList, error) {
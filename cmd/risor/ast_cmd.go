@@ -99,6 +99,12 @@ func nodeToJSON(node ast.Node) *ASTNode {
 			result.Children = append(result.Children, nodeToJSON(n.Value))
 		}
 
+	case *ast.Enum:
+		result.Value = n.Name.Name
+		for _, member := range n.Members {
+			result.Children = append(result.Children, nodeToJSON(member))
+		}
+
 	case *ast.Assign:
 		if n.Name != nil {
 			result.Children = append(result.Children, nodeToJSON(n.Name))
@@ -173,6 +179,11 @@ func nodeToJSON(node ast.Node) *ASTNode {
 			result.Children = append(result.Children, nodeToJSON(item))
 		}
 
+	case *ast.Tuple:
+		for _, item := range n.Items {
+			result.Children = append(result.Children, nodeToJSON(item))
+		}
+
 	case *ast.Map:
 		for _, pair := range n.Items {
 			pairNode := &ASTNode{Type: "MapPair"}
@@ -383,6 +394,16 @@ func printNode(node ast.Node, indent string, isLast bool) {
 			printNode(n.Value, childIndent, true)
 		}
 
+	case *ast.Enum:
+		printLine(tui.Group(
+			tui.Text("%s%s", indent, connector).Style(mutedStyle),
+			tui.Text("%s", typeName).Style(nodeStyle),
+			tui.Text(" %s", n.Name).Style(valueStyle),
+		))
+		for i, member := range n.Members {
+			printNode(member, childIndent, i == len(n.Members)-1)
+		}
+
 	case *ast.Assign:
 		printLine(tui.Group(
 			tui.Text("%s%s", indent, connector).Style(mutedStyle),
@@ -544,6 +565,16 @@ func printNode(node ast.Node, indent string, isLast bool) {
 			printNode(item, childIndent, i == len(n.Items)-1)
 		}
 
+	case *ast.Tuple:
+		printLine(tui.Group(
+			tui.Text("%s%s", indent, connector).Style(mutedStyle),
+			tui.Text("%s", typeName).Style(nodeStyle),
+			tui.Text(" (%d items)", len(n.Items)).Style(mutedStyle),
+		))
+		for i, item := range n.Items {
+			printNode(item, childIndent, i == len(n.Items)-1)
+		}
+
 	case *ast.Map:
 		printLine(tui.Group(
 			tui.Text("%s%s", indent, connector).Style(mutedStyle),
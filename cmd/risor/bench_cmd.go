@@ -11,6 +11,8 @@ import (
 	"time"
 
 	"github.com/deepnoodle-ai/risor/v2"
+	"github.com/deepnoodle-ai/risor/v2/pkg/bytecode"
+	"github.com/deepnoodle-ai/risor/v2/pkg/testing"
 	"github.com/deepnoodle-ai/wonton/cli"
 	"github.com/deepnoodle-ai/wonton/tui"
 )
@@ -37,6 +39,14 @@ func benchHandler(ctx *cli.Context) error {
 		return err
 	}
 
+	// Files that define bench_* functions benchmark each function
+	env := defaultGlobals()
+	if compiled, err := risor.Compile(ctx.Context(), code, risor.WithEnv(env)); err == nil {
+		if len(testing.DiscoverBenchFunctions(compiled)) > 0 {
+			return benchFunctionsHandler(ctx, compiled, env)
+		}
+	}
+
 	iterations := ctx.Int("iterations")
 	if iterations <= 0 {
 		iterations = 1000
@@ -74,7 +84,6 @@ func benchHandler(ctx *cli.Context) error {
 		fmt.Println(tui.Sprint(tui.Text("Verifying code...").Style(mutedStyle)))
 	}
 
-	env := risor.Builtins()
 	_, verifyErr := risor.Eval(context.Background(), code, risor.WithEnv(env))
 	if verifyErr != nil {
 		return fmt.Errorf("code error: %w", verifyErr)
@@ -91,7 +100,7 @@ func benchHandler(ctx *cli.Context) error {
 	}
 
 	for i := 0; i < warmup; i++ {
-		env := risor.Builtins()
+		env := defaultGlobals()
 		_, _ = risor.Eval(context.Background(), code, risor.WithEnv(env))
 	}
 
@@ -109,7 +118,7 @@ func benchHandler(ctx *cli.Context) error {
 	durations := make([]time.Duration, iterations)
 
 	for i := 0; i < iterations; i++ {
-		env := risor.Builtins()
+		env := defaultGlobals()
 		start := time.Now()
 		_, _ = risor.Eval(context.Background(), code, risor.WithEnv(env))
 		elapsed := time.Since(start)
@@ -204,6 +213,146 @@ func benchHandler(ctx *cli.Context) error {
 	return nil
 }
 
+// BenchFunctionResult holds the statistics of one bench_* function, in the
+// form saved with --save and read back with --baseline.
+type BenchFunctionResult struct {
+	Name        string `json:"name"`
+	Iterations  int    `json:"iterations"`
+	NsPerOp     int64  `json:"ns_per_op"`
+	AllocsPerOp int64  `json:"allocs_per_op"`
+	BytesPerOp  int64  `json:"bytes_per_op"`
+	Error       string `json:"error,omitempty"`
+
+	// Change in ns/op relative to the baseline, as a percentage
+	BaselineDelta *float64 `json:"baseline_delta,omitempty"`
+}
+
+// benchFile is the JSON document written by --save and --output json.
+type benchFile struct {
+	Benchmarks []BenchFunctionResult `json:"benchmarks"`
+}
+
+// benchFunctionsHandler runs the bench_* functions of a compiled file,
+// scaling each one's iteration count as Go's testing.B does.
+func benchFunctionsHandler(ctx *cli.Context, code *bytecode.Code, env map[string]any) error {
+	cfg := &testing.BenchConfig{RunPattern: ctx.String("run")}
+	if ctx.IsSet("iterations") {
+		cfg.Iterations = ctx.Int("iterations")
+	}
+	if t := ctx.String("time"); t != "" {
+		d, err := time.ParseDuration(t)
+		if err != nil {
+			return fmt.Errorf("invalid --time: %w", err)
+		}
+		cfg.Time = d
+	}
+
+	var baseline map[string]BenchFunctionResult
+	if path := ctx.String("baseline"); path != "" {
+		var err error
+		if baseline, err = loadBenchBaseline(path); err != nil {
+			return err
+		}
+	}
+
+	results, err := testing.RunBenchmarks(ctx.Context(), code, env, cfg)
+	if err != nil {
+		return err
+	}
+
+	report := benchFile{Benchmarks: []BenchFunctionResult{}}
+	failed := false
+	for _, r := range results {
+		entry := BenchFunctionResult{
+			Name:        r.Name,
+			Iterations:  r.N,
+			NsPerOp:     r.NsPerOp(),
+			AllocsPerOp: r.AllocsPerOp(),
+			BytesPerOp:  r.BytesPerOp(),
+		}
+		if r.Error != nil {
+			entry.Error = r.Error.Error()
+			failed = true
+		} else if base, ok := baseline[r.Name]; ok && base.NsPerOp > 0 {
+			delta := float64(entry.NsPerOp-base.NsPerOp) / float64(base.NsPerOp) * 100
+			entry.BaselineDelta = &delta
+		}
+		report.Benchmarks = append(report.Benchmarks, entry)
+	}
+
+	if path := ctx.String("save"); path != "" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+			return err
+		}
+	}
+
+	if ctx.String("output") == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			return err
+		}
+	} else {
+		printBenchFunctions(report.Benchmarks)
+	}
+	if failed {
+		os.Exit(1)
+	}
+	return nil
+}
+
+func loadBenchBaseline(path string) (map[string]BenchFunctionResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file benchFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid baseline %s: %w", path, err)
+	}
+	baseline := make(map[string]BenchFunctionResult, len(file.Benchmarks))
+	for _, b := range file.Benchmarks {
+		baseline[b.Name] = b
+	}
+	return baseline, nil
+}
+
+func printBenchFunctions(results []BenchFunctionResult) {
+	nameStyle := tui.NewStyle().WithFgRGB(tui.RGB{R: 180, G: 140, B: 220})
+	valueStyle := tui.NewStyle().WithFgRGB(tui.RGB{R: 100, G: 220, B: 100})
+	errorStyle := tui.NewStyle().WithFgRGB(tui.RGB{R: 255, G: 100, B: 100})
+	mutedStyle := tui.NewStyle().WithFgRGB(tui.RGB{R: 120, G: 120, B: 130})
+
+	width := 0
+	for _, r := range results {
+		width = max(width, len(r.Name))
+	}
+	for _, r := range results {
+		name := tui.Text("%-*s  ", width, r.Name).Style(nameStyle)
+		if r.Error != "" {
+			fmt.Println(tui.Sprint(tui.Group(name, tui.Text("FAIL: %s", r.Error).Style(errorStyle))))
+			continue
+		}
+		line := []tui.View{
+			name,
+			tui.Text("%10d  ", r.Iterations).Style(mutedStyle),
+			tui.Text("%12d ns/op  %8d allocs/op  %10d B/op", r.NsPerOp, r.AllocsPerOp, r.BytesPerOp).Style(valueStyle),
+		}
+		if r.BaselineDelta != nil {
+			style := valueStyle
+			if *r.BaselineDelta > 0 {
+				style = errorStyle
+			}
+			line = append(line, tui.Text("  %+.1f%%", *r.BaselineDelta).Style(style))
+		}
+		fmt.Println(tui.Sprint(tui.Group(line...)))
+	}
+}
+
 func getBenchCode(ctx *cli.Context) (string, error) {
 	codeSet := ctx.IsSet("code")
 	stdinSet := ctx.Bool("stdin")
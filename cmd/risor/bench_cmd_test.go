@@ -2,7 +2,9 @@ package main
 
 import (
 	"bytes"
+	"encoding/json"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -110,6 +112,59 @@ func TestBenchHandler_NoInput(t *testing.T) {
 	assert.True(t, contains(err.Error(), "no input"))
 }
 
+func TestBenchHandler_Functions(t *testing.T) {
+	oldEnabled := color.Enabled
+	color.Enabled = false
+	defer func() { color.Enabled = oldEnabled }()
+
+	dir := t.TempDir()
+	baselinePath := filepath.Join(dir, "baseline.json")
+	assert.Nil(t, os.WriteFile(baselinePath, []byte(`{"benchmarks": [{"name": "bench_add", "ns_per_op": 1}]}`), 0o644))
+
+	app := cli.New("risor").SetColorEnabled(false)
+	app.Command("bench").
+		Args("file?").
+		Flags(
+			cli.String("code", "c"),
+			cli.Bool("stdin", ""),
+			cli.Int("iterations", "n").Default(10),
+			cli.Int("warmup", "w").Default(2),
+			cli.String("output", "o"),
+			cli.String("run", "r"),
+			cli.String("time", "t"),
+			cli.String("save", ""),
+			cli.String("baseline", "b"),
+		).
+		Run(benchHandler)
+
+	old := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	savePath := filepath.Join(dir, "results.json")
+	code := "function bench_add() { 1 + 2 }\nfunction bench_other() { 3 }"
+	err := app.ExecuteArgs([]string{"bench", "-c", code, "-n", "20", "-r", "add",
+		"-o", "json", "-b", baselinePath, "--save", savePath})
+
+	w.Close()
+	os.Stdout = old
+	assert.Nil(t, err)
+
+	var buf bytes.Buffer
+	_, _ = buf.ReadFrom(r)
+
+	var report benchFile
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &report))
+	assert.Len(t, report.Benchmarks, 1)
+	assert.Equal(t, report.Benchmarks[0].Name, "bench_add")
+	assert.Equal(t, report.Benchmarks[0].Iterations, 20)
+	assert.NotNil(t, report.Benchmarks[0].BaselineDelta)
+
+	saved, err := loadBenchBaseline(savePath)
+	assert.Nil(t, err)
+	assert.Equal(t, saved["bench_add"].Iterations, 20)
+}
+
 func TestSortDurations(t *testing.T) {
 	durations := []time.Duration{
 		5 * time.Millisecond,
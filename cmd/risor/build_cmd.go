@@ -0,0 +1,233 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/deepnoodle-ai/risor/v2"
+	"github.com/deepnoodle-ai/risor/v2/pkg/bytecode"
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+	"github.com/deepnoodle-ai/wonton/cli"
+	"github.com/deepnoodle-ai/wonton/color"
+)
+
+// A built program is a copy of the risor executable with the marshaled
+// bytecode appended, followed by a trailer holding the bytecode length, a
+// byte of build flags, and programMagic. On startup the executable checks
+// its own tail for the trailer and, if present, runs the program instead of
+// the CLI.
+const (
+	programMagic       = "risorprg"
+	programTrailerSize = 8 + 1 + len(programMagic)
+)
+
+// Build flags recorded in the trailer.
+const (
+	// programDefaultGlobals means the program was compiled with the default
+	// globals, which were left out with --no-default-globals.
+	programDefaultGlobals byte = 1 << iota
+)
+
+// builtProgram is a program appended to an executable.
+type builtProgram struct {
+	bytecode       []byte
+	defaultGlobals bool
+}
+
+func buildHandler(ctx *cli.Context) error {
+	if err := checkBuildPlatform(runtime.GOOS, runtime.GOARCH); err != nil {
+		return err
+	}
+	file := ctx.Arg(0)
+	for _, name := range []string{"var", "var-json"} {
+		if ctx.IsSet(name) {
+			return fmt.Errorf("--%s is not supported by build; values are not embedded in the program", name)
+		}
+	}
+	source, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	output := ctx.String("output")
+	if output == "" {
+		output = strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+	}
+
+	defaultGlobals := !ctx.Bool("no-default-globals")
+	code, err := risor.Compile(ctx.Context(), string(source),
+		append(buildEnvOptions(defaultGlobals, nil), risor.WithFilename(file))...)
+	if err != nil {
+		return formatRisorError(ctx, err)
+	}
+	data, err := bytecode.Marshal(code)
+	if err != nil {
+		return err
+	}
+	program := &builtProgram{bytecode: data, defaultGlobals: defaultGlobals}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("locating the risor executable: %w", err)
+	}
+	base, err := os.ReadFile(exe)
+	if err != nil {
+		return fmt.Errorf("reading the risor executable: %w", err)
+	}
+	exeData, err := appendProgram(base, program)
+	if err != nil {
+		return fmt.Errorf("reading the risor executable: %w", err)
+	}
+	if err := os.WriteFile(output, exeData, 0o755); err != nil {
+		return err
+	}
+	fmt.Println("built", output)
+	return nil
+}
+
+// checkBuildPlatform reports whether built programs can run on the given
+// platform. Executables on darwin/arm64 must carry a valid code signature,
+// and appending a program invalidates the one the linker adds. The signature
+// cannot be redone either: codesign rejects files with data after the end
+// of the Mach-O image. The kernel would kill the program on launch, so build
+// refuses instead of writing it.
+func checkBuildPlatform(goos, goarch string) error {
+	if goos == "darwin" && goarch == "arm64" {
+		return errors.New("build is not supported on darwin/arm64: appending a program invalidates the executable's code signature")
+	}
+	return nil
+}
+
+// buildEnvOptions returns the environment available to built programs. The
+// same environment is used to compile the program and to run it, so that
+// the globals it was compiled against are present at run time.
+func buildEnvOptions(withDefaults bool, args []string) []risor.Option {
+	var opts []risor.Option
+	if withDefaults {
+		opts = append(opts, risor.WithEnv(defaultGlobals()))
+	}
+	return append(opts,
+		risor.WithEnv(newOutputBuiltins(stdoutWriter, stderrWriter)),
+		risor.WithEnv(newScriptArgsEnv(args)),
+	)
+}
+
+// appendProgram returns the runtime executable with the program appended.
+// A program already appended to the runtime, as when it is itself a built
+// program, is replaced.
+func appendProgram(runtime []byte, program *builtProgram) ([]byte, error) {
+	start, _, err := readTrailer(bytes.NewReader(runtime), int64(len(runtime)))
+	if err != nil {
+		return nil, err
+	}
+	runtime = runtime[:start]
+	var flags byte
+	if program.defaultGlobals {
+		flags |= programDefaultGlobals
+	}
+	var buf bytes.Buffer
+	buf.Grow(len(runtime) + len(program.bytecode) + programTrailerSize)
+	buf.Write(runtime)
+	buf.Write(program.bytecode)
+	binary.Write(&buf, binary.LittleEndian, uint64(len(program.bytecode)))
+	buf.WriteByte(flags)
+	buf.WriteString(programMagic)
+	return buf.Bytes(), nil
+}
+
+// readTrailer returns the offset at which the program appended to an
+// executable of the given size starts, along with its build flags. The
+// offset is the size of the executable if there is no program.
+func readTrailer(r io.ReaderAt, size int64) (int64, byte, error) {
+	if size < int64(programTrailerSize) {
+		return size, 0, nil
+	}
+	trailer := make([]byte, programTrailerSize)
+	if _, err := r.ReadAt(trailer, size-int64(programTrailerSize)); err != nil {
+		return 0, 0, err
+	}
+	if string(trailer[9:]) != programMagic {
+		return size, 0, nil
+	}
+	n := binary.LittleEndian.Uint64(trailer[:8])
+	if n > uint64(size-int64(programTrailerSize)) {
+		return 0, 0, errors.New("invalid embedded program length")
+	}
+	return size - int64(programTrailerSize) - int64(n), trailer[8], nil
+}
+
+// readProgram returns the program appended to an executable of the given
+// size, or nil if there is none.
+func readProgram(r io.ReaderAt, size int64) (*builtProgram, error) {
+	start, flags, err := readTrailer(r, size)
+	if err != nil || start == size {
+		return nil, err
+	}
+	data := make([]byte, size-int64(programTrailerSize)-start)
+	if _, err := r.ReadAt(data, start); err != nil {
+		return nil, err
+	}
+	return &builtProgram{
+		bytecode:       data,
+		defaultGlobals: flags&programDefaultGlobals != 0,
+	}, nil
+}
+
+// embeddedProgram returns the program appended to the running executable,
+// or nil if this is a plain risor executable.
+func embeddedProgram() (*builtProgram, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, nil
+	}
+	f, err := os.Open(exe)
+	if err != nil {
+		return nil, nil
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	return readProgram(f, info.Size())
+}
+
+// runProgram runs a built program with the process arguments and returns
+// the exit code. All arguments belong to the program, so flags need no
+// "--" separator. The program's result is discarded.
+func runProgram(program *builtProgram) int {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if err := runProgramArgs(ctx, program, os.Args); err != nil {
+		var exitErr *object.ExitError
+		if errors.As(err, &exitErr) {
+			return exitErr.Code
+		}
+		useColor := color.ShouldColorize(os.Stderr)
+		printError(formatError(err, useColor).Error())
+		return 1
+	}
+	return 0
+}
+
+// runProgramArgs runs a built program with the given arguments, in the
+// environment it was compiled against.
+func runProgramArgs(ctx context.Context, program *builtProgram, args []string) error {
+	code, err := bytecode.Unmarshal(program.bytecode)
+	if err != nil {
+		return fmt.Errorf("loading embedded program: %w", err)
+	}
+	if err := bytecode.Verify(code); err != nil {
+		return fmt.Errorf("loading embedded program: %w", err)
+	}
+	_, err = risor.Run(ctx, code, buildEnvOptions(program.defaultGlobals, args)...)
+	return err
+}
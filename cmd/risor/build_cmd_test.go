@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/deepnoodle-ai/risor/v2"
+	"github.com/deepnoodle-ai/risor/v2/pkg/bytecode"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+func TestReadProgram(t *testing.T) {
+	runtime := []byte("\x7fELF runtime bytes")
+	exe, err := appendProgram(runtime, &builtProgram{bytecode: []byte(`{"program":true}`), defaultGlobals: true})
+	assert.Nil(t, err)
+
+	program, err := readProgram(bytes.NewReader(exe), int64(len(exe)))
+	assert.Nil(t, err)
+	assert.Equal(t, string(program.bytecode), `{"program":true}`)
+	assert.True(t, program.defaultGlobals)
+
+	// A plain executable has no program
+	program, err = readProgram(bytes.NewReader(runtime), int64(len(runtime)))
+	assert.Nil(t, err)
+	assert.Nil(t, program)
+
+	// Too short to hold a trailer
+	program, err = readProgram(bytes.NewReader([]byte("x")), 1)
+	assert.Nil(t, err)
+	assert.Nil(t, program)
+}
+
+func TestAppendProgramReplacesProgram(t *testing.T) {
+	runtime := []byte("\x7fELF runtime bytes")
+	first, err := appendProgram(runtime, &builtProgram{bytecode: []byte("first"), defaultGlobals: true})
+	assert.Nil(t, err)
+
+	// Building with a built program as the runtime replaces its program
+	second, err := appendProgram(first, &builtProgram{bytecode: []byte("second")})
+	assert.Nil(t, err)
+	assert.Equal(t, len(second), len(runtime)+len("second")+programTrailerSize)
+	assert.True(t, bytes.HasPrefix(second, runtime))
+
+	program, err := readProgram(bytes.NewReader(second), int64(len(second)))
+	assert.Nil(t, err)
+	assert.Equal(t, string(program.bytecode), "second")
+	assert.False(t, program.defaultGlobals)
+}
+
+func TestCheckBuildPlatform(t *testing.T) {
+	assert.Nil(t, checkBuildPlatform("linux", "amd64"))
+	assert.Nil(t, checkBuildPlatform("linux", "arm64"))
+	assert.Nil(t, checkBuildPlatform("windows", "amd64"))
+	assert.Nil(t, checkBuildPlatform("darwin", "amd64"))
+
+	err := checkBuildPlatform("darwin", "arm64")
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "darwin/arm64")
+}
+
+func TestReadProgramInvalidLength(t *testing.T) {
+	exe, err := appendProgram(nil, &builtProgram{bytecode: []byte("abc")})
+	assert.Nil(t, err)
+	exe = exe[1:] // the recorded length now exceeds the available bytes
+	_, err = readProgram(bytes.NewReader(exe), int64(len(exe)))
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "invalid embedded program length")
+}
+
+func TestRunProgramArgs(t *testing.T) {
+	ctx := context.Background()
+	code, err := risor.Compile(ctx, `
+		let opts = flags.parse({count: {type: "int"}})
+		if (opts.count != 2 || os.args[0] != "tool") {
+			throw "unexpected arguments"
+		}
+		math.sqrt(opts.count)
+	`, buildEnvOptions(true, nil)...)
+	assert.Nil(t, err)
+	data, err := bytecode.Marshal(code)
+	assert.Nil(t, err)
+	program := &builtProgram{bytecode: data, defaultGlobals: true}
+
+	assert.Nil(t, runProgramArgs(ctx, program, []string{"tool", "--count", "2"}))
+
+	err = runProgramArgs(ctx, program, []string{"tool", "--count", "3"})
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "unexpected arguments")
+
+	err = runProgramArgs(ctx, &builtProgram{bytecode: []byte("not bytecode")}, []string{"tool"})
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "loading embedded program")
+}
+
+func TestRunProgramArgsWithoutDefaultGlobals(t *testing.T) {
+	ctx := context.Background()
+	// Without the default globals, a program may declare names they use
+	code, err := risor.Compile(ctx, `let math = 2; math * 2`, buildEnvOptions(false, nil)...)
+	assert.Nil(t, err)
+	data, err := bytecode.Marshal(code)
+	assert.Nil(t, err)
+	assert.Nil(t, runProgramArgs(ctx, &builtProgram{bytecode: data}, []string{"tool"}))
+
+	// The recorded flag decides which globals the program runs with
+	code, err = risor.Compile(ctx, `math.sqrt(4)`, buildEnvOptions(true, nil)...)
+	assert.Nil(t, err)
+	data, err = bytecode.Marshal(code)
+	assert.Nil(t, err)
+	assert.Nil(t, runProgramArgs(ctx, &builtProgram{bytecode: data, defaultGlobals: true}, []string{"tool"}))
+	err = runProgramArgs(ctx, &builtProgram{bytecode: data}, []string{"tool"})
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "math")
+}
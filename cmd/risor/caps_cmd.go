@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/deepnoodle-ai/risor/v2"
+	"github.com/deepnoodle-ai/wonton/cli"
+)
+
+func capsHandler(ctx *cli.Context) error {
+	opts, err := getRisorOptions(ctx, false)
+	if err != nil {
+		return err
+	}
+	code, err := getRisorCode(ctx)
+	if err != nil {
+		return err
+	}
+	if code == "" {
+		return errors.New("no input provided")
+	}
+	name := "<stdin>"
+	if file := ctx.Arg(0); file != "" {
+		name = file
+		opts = append(opts, risor.WithFilename(file))
+	} else if ctx.IsSet("code") {
+		name = "<code>"
+	}
+	// Check the script against the same env "risor run" gives it
+	opts = append(opts, risor.WithEnv(newScriptArgsEnv(nil)))
+
+	compiled, err := risor.Compile(ctx.Context(), code, opts...)
+	if err != nil {
+		return err
+	}
+	uses := risor.Capabilities(compiled, opts...)
+	if ctx.String("output") == "json" {
+		return printCapsJSON(os.Stdout, uses)
+	}
+	printCaps(os.Stdout, name, uses)
+	return nil
+}
+
+// printCaps writes a readable list of the capabilities a script could use,
+// one per line, followed by the functions that grant it.
+func printCaps(w io.Writer, name string, uses []risor.CapabilityUse) {
+	if len(uses) == 0 {
+		fmt.Fprintf(w, "%s uses no capabilities\n", name)
+		return
+	}
+	width := 0
+	for _, use := range uses {
+		width = max(width, len(use.Capability))
+	}
+	fmt.Fprintf(w, "%s could use:\n", name)
+	for _, use := range uses {
+		fmt.Fprintf(w, "  %-*s  %s\n", width, use.Capability, strings.Join(use.Functions, ", "))
+	}
+}
+
+func printCapsJSON(w io.Writer, uses []risor.CapabilityUse) error {
+	type capJSON struct {
+		Capability string   `json:"capability"`
+		Functions  []string `json:"functions"`
+	}
+	out := make([]capJSON, 0, len(uses))
+	for _, use := range uses {
+		out = append(out, capJSON{Capability: string(use.Capability), Functions: use.Functions})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
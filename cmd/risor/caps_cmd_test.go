@@ -0,0 +1,39 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/deepnoodle-ai/risor/v2"
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+func TestPrintCaps(t *testing.T) {
+	uses := []risor.CapabilityUse{
+		{Capability: object.CapFSRead, Functions: []string{"read_file"}},
+		{Capability: object.CapNet, Functions: []string{"net.lookup_host", "net.probe"}},
+	}
+	var out bytes.Buffer
+	printCaps(&out, "check.risor", uses)
+	assert.Equal(t, out.String(), "check.risor could use:\n"+
+		"  fs-read  read_file\n"+
+		"  net      net.lookup_host, net.probe\n")
+
+	out.Reset()
+	printCaps(&out, "pure.risor", nil)
+	assert.Equal(t, out.String(), "pure.risor uses no capabilities\n")
+}
+
+func TestPrintCapsJSON(t *testing.T) {
+	var out bytes.Buffer
+	assert.Nil(t, printCapsJSON(&out, []risor.CapabilityUse{
+		{Capability: object.CapNet, Functions: []string{"net.probe"}},
+	}))
+	assert.Contains(t, out.String(), `"capability": "net"`)
+	assert.Contains(t, out.String(), `"net.probe"`)
+
+	out.Reset()
+	assert.Nil(t, printCapsJSON(&out, nil))
+	assert.Equal(t, out.String(), "[]\n")
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	goerrors "errors"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/errors"
+	"github.com/deepnoodle-ai/risor/v2/pkg/parser"
+)
+
+// Diagnostic is a problem found in a source file, in the form written by
+// lint, fmt --check, and test with --output json so that CI systems and
+// pre-commit hooks can annotate the source. Line and column are 1-based.
+type Diagnostic struct {
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Column   int    `json:"col"`
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"` // "error" or "warning"
+	Message  string `json:"message"`
+}
+
+// diagnosticReport is the JSON document written for a set of diagnostics.
+type diagnosticReport struct {
+	Issues   []Diagnostic `json:"issues"`
+	Errors   int          `json:"errors"`
+	Warnings int          `json:"warnings"`
+}
+
+func newDiagnosticReport(diags []Diagnostic) diagnosticReport {
+	report := diagnosticReport{Issues: diags}
+	if report.Issues == nil {
+		report.Issues = []Diagnostic{}
+	}
+	for _, d := range diags {
+		if d.Severity == "error" {
+			report.Errors++
+		} else {
+			report.Warnings++
+		}
+	}
+	return report
+}
+
+// errorDiagnostics converts a parse, compile, or runtime error into
+// diagnostics, one for each error it holds, tagged with the given rule.
+// Errors without a source position are reported at line 1, column 1.
+func errorDiagnostics(file, rule string, err error) []Diagnostic {
+	var errs []error
+	var parseErrs *parser.Errors
+	var compileErrs *errors.CompileErrors
+	switch {
+	case goerrors.As(err, &parseErrs):
+		for _, e := range parseErrs.Errors() {
+			errs = append(errs, e)
+		}
+	case goerrors.As(err, &compileErrs):
+		for _, e := range compileErrs.Errors {
+			errs = append(errs, e)
+		}
+	default:
+		errs = []error{err}
+	}
+	diags := make([]Diagnostic, 0, len(errs))
+	for _, e := range errs {
+		d := Diagnostic{
+			File:     file,
+			Line:     1,
+			Column:   1,
+			Rule:     rule,
+			Severity: "error",
+			Message:  e.Error(),
+		}
+		var formattable errors.FormattableError
+		if goerrors.As(e, &formattable) {
+			if f := formattable.ToFormatted(); f != nil && f.Line > 0 {
+				d.Line, d.Column, d.Message = f.Line, max(f.Column, 1), f.Message
+			}
+		}
+		diags = append(diags, d)
+	}
+	return diags
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/parser"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+func TestErrorDiagnostics_ParseError(t *testing.T) {
+	_, err := parser.Parse(context.Background(), "let x = 1\nlet y = (", nil)
+	assert.NotNil(t, err)
+
+	diags := errorDiagnostics("main.risor", "parse-error", err)
+	assert.True(t, len(diags) > 0)
+	assert.Equal(t, diags[0].File, "main.risor")
+	assert.Equal(t, diags[0].Line, 2)
+	assert.Equal(t, diags[0].Rule, "parse-error")
+	assert.Equal(t, diags[0].Severity, "error")
+}
+
+func TestErrorDiagnostics_NoPosition(t *testing.T) {
+	diags := errorDiagnostics("main.risor", "test-error", errors.New("boom"))
+	assert.Equal(t, diags, []Diagnostic{{
+		File:     "main.risor",
+		Line:     1,
+		Column:   1,
+		Rule:     "test-error",
+		Severity: "error",
+		Message:  "boom",
+	}})
+}
+
+func TestFormatDiagnostics(t *testing.T) {
+	assert.Len(t, formatDiagnostics("a.risor", "let x = 1\n", "let x = 1\n"), 0)
+
+	diags := formatDiagnostics("a.risor", "let x = 1\nlet y=2\n", "let x = 1\nlet y = 2\n")
+	assert.Len(t, diags, 1)
+	assert.Equal(t, diags[0].Line, 2)
+	assert.Equal(t, diags[0].Rule, "format")
+}
+
+func TestNewDiagnosticReport(t *testing.T) {
+	report := newDiagnosticReport(nil)
+	assert.NotNil(t, report.Issues)
+	assert.Equal(t, report.Errors, 0)
+
+	report = newDiagnosticReport([]Diagnostic{{Severity: "error"}, {Severity: "warning"}, {Severity: "warning"}})
+	assert.Equal(t, report.Errors, 1)
+	assert.Equal(t, report.Warnings, 2)
+}
@@ -3,14 +3,13 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"maps"
 	"os"
 	"sort"
 	"strings"
 
 	"github.com/deepnoodle-ai/risor/v2/pkg/builtins"
-	"github.com/deepnoodle-ai/risor/v2/pkg/modules/math"
-	"github.com/deepnoodle-ai/risor/v2/pkg/modules/rand"
-	"github.com/deepnoodle-ai/risor/v2/pkg/modules/regexp"
+	_ "github.com/deepnoodle-ai/risor/v2/pkg/modules/all"
 	"github.com/deepnoodle-ai/risor/v2/pkg/object"
 	"github.com/deepnoodle-ai/wonton/cli"
 	"github.com/deepnoodle-ai/wonton/tui"
@@ -21,14 +20,54 @@ func typeDocs() map[string]object.TypeSpec {
 	return object.TypeDocsMap()
 }
 
-// Module documentation - generated from modules themselves
-var moduleDocs = map[string]struct {
+// moduleDoc is the documentation of one module.
+type moduleDoc struct {
 	Doc   string
 	Funcs []object.FuncSpec
-}{
-	"math":   {Doc: math.ModuleDoc(), Funcs: math.Docs()},
-	"rand":   {Doc: rand.ModuleDoc(), Funcs: rand.Docs()},
-	"regexp": {Doc: regexp.ModuleDoc(), Funcs: regexp.Docs()},
+}
+
+// Module documentation - registered by the modules themselves, plus any
+// other module in the environment scripts run with.
+var moduleDocs = collectModuleDocs(docEnv())
+
+// docEnv returns the environment the CLI runs scripts with.
+func docEnv() map[string]any {
+	env := defaultGlobals()
+	maps.Copy(env, newScriptArgsEnv(nil))
+	return env
+}
+
+// collectModuleDocs returns documentation for every registered module and
+// every module in env. Modules without registered documentation are
+// described by their function names, and skipped if they have none.
+func collectModuleDocs(env map[string]any) map[string]moduleDoc {
+	result := map[string]moduleDoc{}
+	for _, spec := range object.ModuleDocs() {
+		result[spec.Name] = moduleDoc{Doc: spec.Doc, Funcs: spec.Funcs}
+	}
+	for name, value := range env {
+		m, ok := value.(*object.Module)
+		if !ok {
+			continue
+		}
+		if _, ok := result[name]; ok {
+			continue
+		}
+		if spec := object.DescribeModule(m); len(spec.Funcs) > 0 {
+			result[name] = moduleDoc{Doc: spec.Doc, Funcs: spec.Funcs}
+		}
+	}
+	return result
+}
+
+// moduleNames returns the names of the documented modules, sorted.
+func moduleNames() []string {
+	names := make([]string, 0, len(moduleDocs))
+	for name := range moduleDocs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
 }
 
 func docHandler(ctx *cli.Context) error {
@@ -37,6 +76,11 @@ func docHandler(ctx *cli.Context) error {
 	quick := ctx.Bool("quick")
 	all := ctx.Bool("all")
 
+	// Document a source file
+	if isSourceTopic(topic) {
+		return docSource(topic, format)
+	}
+
 	// Handle --quick mode
 	if quick {
 		return docQuickReference(format)
@@ -70,7 +114,7 @@ func docQuickReference(format string) error {
 		Topics: map[string]string{
 			"builtins": fmt.Sprintf("%d built-in functions (len, map, filter, range, ...)", len(builtins.Docs())),
 			"types":    fmt.Sprintf("%d types (string, list, map, int, float, ...)", len(typeDocs())),
-			"modules":  fmt.Sprintf("%d modules (math, rand, regexp)", len(moduleDocs)),
+			"modules":  fmt.Sprintf("%d modules (%s)", len(moduleDocs), strings.Join(moduleNames(), ", ")),
 			"syntax":   "Complete syntax reference",
 			"errors":   "Common errors and debugging",
 		},
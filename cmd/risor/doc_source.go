@@ -0,0 +1,248 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/ast"
+	"github.com/deepnoodle-ai/risor/v2/pkg/parser"
+	"github.com/deepnoodle-ai/wonton/tui"
+)
+
+// isSourceTopic reports whether a doc topic names a Risor source file rather
+// than a builtin, type, or module.
+func isSourceTopic(topic string) bool {
+	return strings.HasSuffix(topic, ".risor") || strings.ContainsAny(topic, `/\`)
+}
+
+// docSource documents the functions defined in a Risor source file.
+func docSource(path, format string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	doc, err := extractSourceDoc(path, string(data))
+	if err != nil {
+		return err
+	}
+	switch format {
+	case "json":
+		return jsonEncode(doc)
+	case "markdown":
+		fmt.Print(sourceMarkdown(doc))
+		return nil
+	default:
+		return sourceText(doc)
+	}
+}
+
+// commentBlock is a run of comments on consecutive lines, each on a line of
+// its own.
+type commentBlock struct {
+	last  int // 0-based line number of the last comment line
+	lines []string
+}
+
+// extractSourceDoc parses source and documents its top-level functions:
+// named function statements and functions assigned with let or const. A
+// function's doc is the comment block that ends on the line above it. A
+// comment block before the first statement that is not attached to it
+// documents the file.
+func extractSourceDoc(file, source string) (*SourceDoc, error) {
+	program, err := parser.Parse(context.Background(), source, &parser.Config{Filename: file})
+	if err != nil {
+		return nil, err
+	}
+	blocks := commentBlocks(program.Comments, strings.Split(source, "\n"))
+	docAbove := func(line int) string {
+		for _, block := range blocks {
+			if block.last == line-1 {
+				return strings.Join(block.lines, "\n")
+			}
+		}
+		return ""
+	}
+
+	doc := &SourceDoc{File: file, Functions: []SourceFunc{}}
+	if len(blocks) > 0 {
+		first := blocks[0]
+		if len(program.Stmts) == 0 || first.last+1 < program.Stmts[0].Pos().Line {
+			doc.Doc = strings.Join(first.lines, "\n")
+		}
+	}
+	for _, stmt := range program.Stmts {
+		name, fn := sourceFunc(stmt)
+		if fn == nil {
+			continue
+		}
+		params := sourceParams(fn)
+		var returns string
+		if fn.ReturnType != nil {
+			returns = fn.ReturnType.Name
+		}
+		doc.Functions = append(doc.Functions, SourceFunc{
+			Name:      name,
+			Signature: sourceSignature(name, params, returns),
+			Params:    params,
+			Returns:   returns,
+			Doc:       docAbove(stmt.Pos().Line),
+			Line:      stmt.Pos().LineNumber(),
+		})
+	}
+	return doc, nil
+}
+
+// sourceFunc returns the name and definition of the function a top-level
+// statement defines, or a nil definition if it defines none.
+func sourceFunc(stmt ast.Node) (string, *ast.Func) {
+	switch stmt := stmt.(type) {
+	case *ast.Func:
+		if stmt.Name != nil {
+			return stmt.Name.Name, stmt
+		}
+	case *ast.Var:
+		if fn, ok := stmt.Value.(*ast.Func); ok {
+			return stmt.Name.Name, fn
+		}
+	case *ast.Const:
+		if fn, ok := stmt.Value.(*ast.Func); ok {
+			return stmt.Name.Name, fn
+		}
+	}
+	return "", nil
+}
+
+func sourceParams(fn *ast.Func) []SourceParam {
+	params := make([]SourceParam, 0, len(fn.Params)+1)
+	for _, p := range fn.Params {
+		ident, ok := p.(*ast.Ident)
+		if !ok {
+			// Destructuring parameters are shown as written.
+			params = append(params, SourceParam{Name: p.String()})
+			continue
+		}
+		param := SourceParam{Name: ident.Name, Type: typeName(fn.ParamTypes[ident.Name])}
+		if def, ok := fn.Defaults[ident.Name]; ok && def != nil {
+			param.Default = def.String()
+		}
+		params = append(params, param)
+	}
+	if fn.RestParam != nil {
+		params = append(params, SourceParam{
+			Name: fn.RestParam.Name,
+			Type: typeName(fn.ParamTypes[fn.RestParam.Name]),
+			Rest: true,
+		})
+	}
+	return params
+}
+
+// typeName returns the name in a type annotation, or "" if there is none.
+func typeName(typ *ast.TypeAnnotation) string {
+	if typ == nil {
+		return ""
+	}
+	return typ.Name
+}
+
+func sourceSignature(name string, params []SourceParam, returns string) string {
+	args := make([]string, len(params))
+	for i, p := range params {
+		arg := p.Name
+		if p.Rest {
+			arg = "..." + arg
+		}
+		if p.Type != "" {
+			arg += ": " + p.Type
+		}
+		if p.Default != "" {
+			arg += " = " + p.Default
+		}
+		args[i] = arg
+	}
+	sig := formatSignature(name, args)
+	if returns != "" {
+		sig += " -> " + returns
+	}
+	return sig
+}
+
+// commentBlocks groups comments that sit on lines of their own into blocks
+// of consecutive lines. Comments that follow code on the same line are
+// ignored.
+func commentBlocks(comments []*ast.Comment, lines []string) []commentBlock {
+	var blocks []commentBlock
+	for _, c := range comments {
+		pos := c.Pos()
+		if pos.Line < len(lines) {
+			prefix := []rune(lines[pos.Line])
+			if pos.Column < len(prefix) && strings.TrimSpace(string(prefix[:pos.Column])) != "" {
+				continue
+			}
+		}
+		last := pos.Line + strings.Count(c.Text, "\n")
+		if n := len(blocks); n > 0 && blocks[n-1].last == pos.Line-1 {
+			blocks[n-1].last = last
+			blocks[n-1].lines = append(blocks[n-1].lines, c.Lines()...)
+			continue
+		}
+		blocks = append(blocks, commentBlock{last: last, lines: c.Lines()})
+	}
+	return blocks
+}
+
+func sourceText(doc *SourceDoc) error {
+	titleStyle := tui.NewStyle().WithFgRGB(tui.RGB{R: 255, G: 200, B: 80}).WithBold()
+	headingStyle := tui.NewStyle().WithFgRGB(tui.RGB{R: 180, G: 140, B: 220}).WithBold()
+	nameStyle := tui.NewStyle().WithFgRGB(tui.RGB{R: 100, G: 200, B: 255})
+	docStyle := tui.NewStyle().WithFgRGB(tui.RGB{R: 180, G: 180, B: 190})
+	mutedStyle := tui.NewStyle().WithFgRGB(tui.RGB{R: 120, G: 120, B: 130})
+
+	views := []tui.View{tui.Text("File: %s", doc.File).Style(titleStyle)}
+	for _, line := range splitDoc(doc.Doc) {
+		views = append(views, tui.Text("%s", line).Style(docStyle))
+	}
+	views = append(views, tui.Text(""), tui.Text("FUNCTIONS").Style(headingStyle))
+	if len(doc.Functions) == 0 {
+		views = append(views, tui.Text("  (none)").Style(mutedStyle))
+	}
+	for _, fn := range doc.Functions {
+		views = append(views, tui.Group(
+			tui.Text("  %s", fn.Signature).Style(nameStyle),
+			tui.Text("  line %d", fn.Line).Style(mutedStyle),
+		))
+		for _, line := range splitDoc(fn.Doc) {
+			views = append(views, tui.Text("      %s", line).Style(docStyle))
+		}
+	}
+	tui.Print(tui.Stack(views...).Gap(0))
+	fmt.Println()
+	return nil
+}
+
+func sourceMarkdown(doc *SourceDoc) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("## File: %s\n\n", doc.File))
+	if doc.Doc != "" {
+		sb.WriteString(fmt.Sprintf("%s\n\n", doc.Doc))
+	}
+	sb.WriteString("### Functions\n\n")
+	for _, fn := range doc.Functions {
+		sb.WriteString(fmt.Sprintf("#### `%s`\n\n", fn.Signature))
+		if fn.Doc != "" {
+			sb.WriteString(fmt.Sprintf("%s\n\n", fn.Doc))
+		}
+	}
+	return sb.String()
+}
+
+// splitDoc splits a doc comment into lines, returning none for an empty
+// comment.
+func splitDoc(doc string) []string {
+	if doc == "" {
+		return nil
+	}
+	return strings.Split(doc, "\n")
+}
@@ -28,6 +28,7 @@ var syntaxSections = []SyntaxSection{
 		Items: []SyntaxItem{
 			{Syntax: "let x = value", Notes: "Mutable variable"},
 			{Syntax: "const X = value", Notes: "Immutable constant"},
+			{Syntax: "enum Color { Red, Green }", Notes: "Enum (Color.Red.ordinal, Color.values())"},
 			{Syntax: "let {a, b} = obj", Notes: "Destructuring assignment"},
 			{Syntax: "let [x, y] = list", Notes: "List destructuring"},
 		},
@@ -40,6 +41,7 @@ var syntaxSections = []SyntaxSection{
 			{Syntax: "x => expr", Notes: "Arrow function (single param)"},
 			{Syntax: "(a, b) => expr", Notes: "Arrow function (multiple params)"},
 			{Syntax: "(a, b) => { stmts }", Notes: "Arrow function with block"},
+			{Syntax: "items.each x => expr", Notes: "Trailing function (same as items.each(x => expr))"},
 		},
 	},
 	{
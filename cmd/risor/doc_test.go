@@ -2,9 +2,11 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"os"
 	"testing"
 
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
 	"github.com/deepnoodle-ai/wonton/assert"
 	"github.com/deepnoodle-ai/wonton/cli"
 	"github.com/deepnoodle-ai/wonton/color"
@@ -196,6 +198,40 @@ func TestModuleDocs(t *testing.T) {
 	}
 }
 
+func TestCollectModuleDocs(t *testing.T) {
+	noop := func(ctx context.Context, args ...object.Object) (object.Object, error) {
+		return object.Nil, nil
+	}
+	env := map[string]any{
+		"math": object.NewBuiltinsModule("math", nil),
+		"inventory": object.NewBuiltinsModule("inventory", map[string]object.Object{
+			"lookup": object.NewBuiltin("lookup", noop),
+			"count":  object.NewBuiltin("count", noop),
+			"limit":  object.NewInt(10),
+		}),
+		"settings": object.NewBuiltinsModule("settings", map[string]object.Object{
+			"debug": object.False,
+		}),
+	}
+	docs := collectModuleDocs(env)
+
+	// Registered modules keep their documentation.
+	assert.Equal(t, docs["math"].Doc, moduleDocs["math"].Doc)
+	assert.True(t, len(docs["math"].Funcs) > 1)
+	_, ok := docs["flags"]
+	assert.True(t, ok)
+
+	// Host modules are described by their functions.
+	inventory, ok := docs["inventory"]
+	assert.True(t, ok)
+	assert.Equal(t, len(inventory.Funcs), 2)
+	assert.Equal(t, inventory.Funcs[0].Name, "count")
+	assert.Equal(t, inventory.Funcs[1].Name, "lookup")
+
+	_, ok = docs["settings"]
+	assert.False(t, ok)
+}
+
 func TestFormatSignature(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -400,15 +436,23 @@ func TestDocHandler_JSONFormat(t *testing.T) {
 	r, w, _ := os.Pipe()
 	os.Stdout = w
 
+	// The full JSON output is larger than the pipe buffer, so read it
+	// while the command runs
+	var buf bytes.Buffer
+	done := make(chan struct{})
+	go func() {
+		_, _ = buf.ReadFrom(r)
+		close(done)
+	}()
+
 	err := app.ExecuteArgs([]string{"doc", "--format", "json"})
 
 	w.Close()
 	os.Stdout = old
+	<-done
 
 	assert.Nil(t, err)
 
-	var buf bytes.Buffer
-	_, _ = buf.ReadFrom(r)
 	output := buf.String()
 
 	// Should be valid JSON with expected structure
@@ -476,3 +520,93 @@ func TestErrorPatterns(t *testing.T) {
 		}
 	}
 }
+
+func TestExtractSourceDoc(t *testing.T) {
+	source, err := os.ReadFile("testdata/doc/mylib.risor")
+	assert.Nil(t, err)
+
+	doc, err := extractSourceDoc("mylib.risor", string(source))
+	assert.Nil(t, err)
+	assert.Equal(t, doc.Doc, "Helpers for talking to the inventory service.\n\nImport with `import mylib`.")
+	assert.Len(t, doc.Functions, 3)
+
+	fetch := doc.Functions[0]
+	assert.Equal(t, fetch.Name, "fetch")
+	assert.Equal(t, fetch.Signature, "fetch(id, retries = 3, ...opts)")
+	assert.Equal(t, fetch.Doc, "fetch loads an item by id.\nReturns null if the item does not exist.")
+	assert.Equal(t, fetch.Line, 7)
+	assert.Equal(t, fetch.Params, []SourceParam{
+		{Name: "id"},
+		{Name: "retries", Default: "3"},
+		{Name: "opts", Rest: true},
+	})
+
+	total := doc.Functions[1]
+	assert.Equal(t, total.Signature, "total(items)")
+	assert.Equal(t, total.Doc, "total sums the quantities\nof a list of items.")
+
+	// A trailing comment on the same line is not a doc comment.
+	double := doc.Functions[2]
+	assert.Equal(t, double.Signature, "double(x)")
+	assert.Equal(t, double.Doc, "")
+}
+
+func TestExtractSourceDoc_NoFileDoc(t *testing.T) {
+	doc, err := extractSourceDoc("lib.risor", "// add returns a + b.\nfunction add(a, b) { return a + b }\n")
+	assert.Nil(t, err)
+	assert.Equal(t, doc.Doc, "")
+	assert.Len(t, doc.Functions, 1)
+	assert.Equal(t, doc.Functions[0].Doc, "add returns a + b.")
+}
+
+func TestExtractSourceDoc_TypeAnnotations(t *testing.T) {
+	source := "function price(base: float, qty: int = 1, ...tags: list) -> float { base * qty }\n"
+	doc, err := extractSourceDoc("lib.risor", source)
+	assert.Nil(t, err)
+	assert.Len(t, doc.Functions, 1)
+
+	fn := doc.Functions[0]
+	assert.Equal(t, fn.Signature, "price(base: float, qty: int = 1, ...tags: list) -> float")
+	assert.Equal(t, fn.Returns, "float")
+	assert.Equal(t, fn.Params, []SourceParam{
+		{Name: "base", Type: "float"},
+		{Name: "qty", Type: "int", Default: "1"},
+		{Name: "tags", Type: "list", Rest: true},
+	})
+}
+
+func TestExtractSourceDoc_ParseError(t *testing.T) {
+	_, err := extractSourceDoc("bad.risor", "function (")
+	assert.NotNil(t, err)
+}
+
+func TestDocHandler_SourceFile(t *testing.T) {
+	app := cli.New("risor").SetColorEnabled(false)
+	app.Command("doc").
+		Args("topic?").
+		Flags(
+			cli.String("format", "f").Enum("json", "text", "markdown"),
+			cli.Bool("quick", "q"),
+			cli.Bool("all", "a"),
+		).
+		Run(docHandler)
+
+	old := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	err := app.ExecuteArgs([]string{"doc", "testdata/doc/mylib.risor", "--format", "markdown"})
+
+	w.Close()
+	os.Stdout = old
+
+	assert.Nil(t, err)
+
+	var buf bytes.Buffer
+	_, _ = buf.ReadFrom(r)
+	output := buf.String()
+
+	assert.True(t, contains(output, "## File: testdata/doc/mylib.risor"))
+	assert.True(t, contains(output, "#### `fetch(id, retries = 3, ...opts)`"))
+	assert.True(t, contains(output, "fetch loads an item by id."))
+}
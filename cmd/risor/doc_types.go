@@ -60,6 +60,31 @@ type ModulesOverview struct {
 	Modules []ModuleInfo `json:"modules"`
 }
 
+// SourceDoc documents the top-level functions of a Risor source file.
+type SourceDoc struct {
+	File      string       `json:"file"`
+	Doc       string       `json:"doc,omitempty"`
+	Functions []SourceFunc `json:"functions"`
+}
+
+// SourceFunc documents a function defined in a source file.
+type SourceFunc struct {
+	Name      string        `json:"name"`
+	Signature string        `json:"signature"`
+	Params    []SourceParam `json:"params"`
+	Returns   string        `json:"returns,omitempty"`
+	Doc       string        `json:"doc,omitempty"`
+	Line      int           `json:"line"`
+}
+
+// SourceParam describes a function parameter.
+type SourceParam struct {
+	Name    string `json:"name"`
+	Type    string `json:"type,omitempty"`
+	Default string `json:"default,omitempty"`
+	Rest    bool   `json:"rest,omitempty"`
+}
+
 // ModuleInfo provides summary information about a module.
 type ModuleInfo struct {
 	Name      string   `json:"name"`
@@ -36,9 +36,13 @@ func evalHandler(ctx *cli.Context) error {
 			}
 			enc := json.NewEncoder(os.Stdout)
 			enc.SetIndent("", "  ")
-			return enc.Encode(out)
+			if err := enc.Encode(out); err != nil {
+				return err
+			}
+			// The error is reported on stdout, so only the status remains
+			return &cli.ExitError{Code: errorExitCode(ctx)}
 		}
-		return err
+		return scriptExitError(ctx, err)
 	}
 
 	if quiet {
@@ -4,9 +4,12 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"io"
 	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/deepnoodle-ai/risor/v2"
 	"github.com/deepnoodle-ai/risor/v2/pkg/object"
 	"github.com/deepnoodle-ai/wonton/assert"
 	"github.com/deepnoodle-ai/wonton/cli"
@@ -287,6 +290,149 @@ func TestEvalHandler_JsonOutput_Map(t *testing.T) {
 	assert.Equal(t, output, "{\n  \"hello\": \"foo\"\n}\n")
 }
 
+func TestEvalHandler_JsonOutput_NoHTMLEscape(t *testing.T) {
+	oldEnabled := color.Enabled
+	color.Enabled = false
+	defer func() { color.Enabled = oldEnabled }()
+
+	app := cli.New("risor").SetColorEnabled(false)
+	app.Command("eval").
+		Args("expr?").
+		Flags(
+			cli.String("code", "c"),
+			cli.Bool("stdin", ""),
+			cli.String("output", "o").Enum("json", "yaml", "text"),
+			cli.Bool("raw", "r"),
+			cli.Bool("quiet", "q"),
+		).
+		Run(evalHandler)
+
+	old := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	err := app.ExecuteArgs([]string{"eval", "-o", "json", "-c", `{expr: "a < b && c"}`})
+
+	w.Close()
+	os.Stdout = old
+
+	assert.Nil(t, err)
+
+	var buf bytes.Buffer
+	_, _ = buf.ReadFrom(r)
+	output := buf.String()
+
+	// Characters that are special in HTML are written as is
+	assert.Equal(t, output, "{\n  \"expr\": \"a < b && c\"\n}\n")
+}
+
+func TestEvalHandler_YamlOutput(t *testing.T) {
+	oldEnabled := color.Enabled
+	color.Enabled = false
+	defer func() { color.Enabled = oldEnabled }()
+
+	app := cli.New("risor").SetColorEnabled(false)
+	app.Command("eval").
+		Args("expr?").
+		Flags(
+			cli.String("code", "c"),
+			cli.Bool("stdin", ""),
+			cli.String("output", "o").Enum("json", "yaml", "text"),
+			cli.Bool("raw", "r"),
+			cli.Bool("quiet", "q"),
+		).
+		Run(evalHandler)
+
+	old := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	err := app.ExecuteArgs([]string{"eval", "-o", "yaml", "-c", `{name: "risor", tags: ["a", "b"], meta: {stars: 5}}`})
+
+	w.Close()
+	os.Stdout = old
+
+	assert.Nil(t, err)
+
+	var buf bytes.Buffer
+	_, _ = buf.ReadFrom(r)
+	output := buf.String()
+
+	assert.Equal(t, output, "meta:\n  stars: 5\nname: risor\ntags:\n  - a\n  - b\n")
+}
+
+func TestEvalHandler_RawOutput(t *testing.T) {
+	oldEnabled := color.Enabled
+	color.Enabled = false
+	defer func() { color.Enabled = oldEnabled }()
+
+	app := cli.New("risor").SetColorEnabled(false)
+	app.Command("eval").
+		Args("expr?").
+		Flags(
+			cli.String("code", "c"),
+			cli.Bool("stdin", ""),
+			cli.String("output", "o").Enum("json", "yaml", "text"),
+			cli.Bool("raw", "r"),
+			cli.Bool("quiet", "q"),
+		).
+		Run(evalHandler)
+
+	old := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	err := app.ExecuteArgs([]string{"eval", "-r", "-c", `"hello"`})
+
+	w.Close()
+	os.Stdout = old
+
+	assert.Nil(t, err)
+
+	var buf bytes.Buffer
+	_, _ = buf.ReadFrom(r)
+	output := buf.String()
+
+	// String results are printed without quotes
+	assert.Equal(t, output, "hello\n")
+}
+
+func TestEvalHandler_RawOutput_NonString(t *testing.T) {
+	oldEnabled := color.Enabled
+	color.Enabled = false
+	defer func() { color.Enabled = oldEnabled }()
+
+	app := cli.New("risor").SetColorEnabled(false)
+	app.Command("eval").
+		Args("expr?").
+		Flags(
+			cli.String("code", "c"),
+			cli.Bool("stdin", ""),
+			cli.String("output", "o").Enum("json", "yaml", "text"),
+			cli.Bool("raw", "r"),
+			cli.Bool("quiet", "q"),
+		).
+		Run(evalHandler)
+
+	old := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	err := app.ExecuteArgs([]string{"eval", "-r", "-o", "json", "-c", `[1, 2]`})
+
+	w.Close()
+	os.Stdout = old
+
+	assert.Nil(t, err)
+
+	var buf bytes.Buffer
+	_, _ = buf.ReadFrom(r)
+	output := buf.String()
+
+	// Other results are formatted as usual
+	assert.Equal(t, output, "[\n  1,\n  2\n]\n")
+}
+
 func TestEvalHandler_WithVarFlag(t *testing.T) {
 	oldEnabled := color.Enabled
 	color.Enabled = false
@@ -621,3 +767,126 @@ func TestNewPrintBuiltin_NoArgs(t *testing.T) {
 	// Should just be a newline
 	assert.Equal(t, buf.String(), "\n")
 }
+
+func TestNewOutputBuiltins(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	env := newOutputBuiltins(
+		func() io.Writer { return &stdout },
+		func() io.Writer { return &stderr },
+	)
+	ctx := context.Background()
+	call := func(name string, args ...object.Object) {
+		t.Helper()
+		_, err := env[name].(*object.Builtin).Call(ctx, args...)
+		assert.Nil(t, err)
+	}
+
+	call("print", object.NewString("result"))
+	call("printf", object.NewString("%s=%d;"), object.NewString("n"), object.NewInt(3))
+	call("eprint", object.NewString("warning:"), object.NewInt(1))
+	call("eprintf", object.NewString("%.1f%%"), object.NewFloat(99.5))
+
+	assert.Equal(t, stdout.String(), "result\nn=3;")
+	assert.Equal(t, stderr.String(), "warning: 1\n99.5%")
+
+	_, err := env["printf"].(*object.Builtin).Call(ctx)
+	assert.NotNil(t, err)
+	_, err = env["printf"].(*object.Builtin).Call(ctx, object.NewInt(1))
+	assert.NotNil(t, err)
+}
+
+func TestNewScriptArgsEnv(t *testing.T) {
+	env := newScriptArgsEnv([]string{"tool.risor", "-v", "--count", "3", "input.txt"})
+	result, err := risor.Eval(context.Background(), `
+		let opts = flags.parse({verbose: {type: "bool", short: "v"}, count: {type: "int"}})
+		[os.args, opts.verbose, opts.count, opts.args]
+	`, risor.WithEnv(env))
+	assert.Nil(t, err)
+	assert.Equal(t, result, []any{
+		[]any{"tool.risor", "-v", "--count", "3", "input.txt"},
+		true,
+		int64(3),
+		[]any{"input.txt"},
+	})
+
+	// Code that is not run from a file has no arguments
+	result, err = risor.Eval(context.Background(), `[os.args, flags.parse({}).args]`,
+		risor.WithEnv(newScriptArgsEnv(nil)))
+	assert.Nil(t, err)
+	assert.Equal(t, result, []any{[]any{}, []any{}})
+}
+
+func TestScriptArgsEnvStore(t *testing.T) {
+	dir := t.TempDir()
+	script := filepath.Join(dir, "tool.risor")
+	source := `
+		let db = store.open("state.db")
+		let runs = (db.get("runs", "count") ?? 0) + 1
+		db.set("runs", "count", runs)
+		runs
+	`
+	// Databases are kept next to the script and persist between runs
+	for _, want := range []int64{1, 2} {
+		result, err := risor.Eval(context.Background(), source,
+			risor.WithEnv(newScriptArgsEnv([]string{script})))
+		assert.Nil(t, err)
+		assert.Equal(t, result, want)
+	}
+	_, err := os.Stat(filepath.Join(dir, "state.db"))
+	assert.Nil(t, err)
+
+	// Names that leave the script's directory are rejected
+	_, err = risor.Eval(context.Background(), `store.open("../state.db")`,
+		risor.WithEnv(newScriptArgsEnv([]string{script})))
+	assert.NotNil(t, err)
+}
+
+func TestScriptArgsEnvSqlite(t *testing.T) {
+	dir := t.TempDir()
+	env := risor.WithEnv(newScriptArgsEnv([]string{filepath.Join(dir, "tool.risor")}))
+	_, err := risor.Eval(context.Background(), `
+		let db = sqlite.open("data.db")
+		db.exec("create table t (n integer)")
+		db.exec("insert into t values (?)", 42)
+	`, env)
+	assert.Nil(t, err)
+	_, err = os.Stat(filepath.Join(dir, "data.db"))
+	assert.Nil(t, err)
+
+	result, err := risor.Eval(context.Background(), `sqlite.open("data.db").query("select n from t")`, env)
+	assert.Nil(t, err)
+	assert.Equal(t, result, []any{map[string]any{"n": int64(42)}})
+}
+
+func TestScriptExitError(t *testing.T) {
+	env := risor.WithEnv(newScriptArgsEnv(nil))
+	run := func(args []string, source string) error {
+		app := cli.New("risor").SetColorEnabled(false)
+		var capturedErr error
+		app.Command("run").
+			Flags(
+				cli.Bool("no-color", ""),
+				cli.Int("error-exit-code", "").Default(1),
+			).
+			Run(func(ctx *cli.Context) error {
+				_, err := risor.Eval(context.Background(), source, env)
+				capturedErr = scriptExitError(ctx, err)
+				return nil
+			})
+		assert.Nil(t, app.ExecuteArgs(append([]string{"run"}, args...)))
+		return capturedErr
+	}
+
+	// os.exit sets the exit code and prints nothing
+	err := run(nil, `os.exit(3)`)
+	assert.Equal(t, cli.GetExitCode(err), 3)
+	assert.Equal(t, err.Error(), "")
+
+	// Uncaught errors are reported with the configured exit code
+	err = run(nil, `throw "boom"`)
+	assert.Equal(t, cli.GetExitCode(err), 1)
+	assert.Contains(t, err.Error(), "boom")
+
+	err = run([]string{"--error-exit-code", "7"}, `throw "boom"`)
+	assert.Equal(t, cli.GetExitCode(err), 7)
+}
@@ -458,8 +458,10 @@ func examplesHandler(ctx *cli.Context) error {
 		fmt.Println(tui.Sprint(tui.Text("%s", strings.Repeat("-", 40)).Style(mutedStyle)))
 
 		// Execute the code with print function
-		env := risor.Builtins()
-		env["print"] = newPrintBuiltin()
+		env := defaultGlobals()
+		for name, fn := range newOutputBuiltins(stdoutWriter, stderrWriter) {
+			env[name] = fn
+		}
 		result, err := risor.Eval(context.Background(), found.Code, risor.WithEnv(env))
 		if err != nil {
 			fmt.Println(tui.Sprint(tui.Text("Error: %v", err).Style(tui.NewStyle().WithFgRGB(tui.RGB{R: 255, G: 100, B: 100}))))
@@ -3,6 +3,7 @@ package main
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -16,6 +17,7 @@ import (
 
 func fmtHandler(ctx *cli.Context) error {
 	write := ctx.Bool("write")
+	check := ctx.Bool("check")
 
 	// Get code from -c flag, --stdin, or file argument
 	code, filePath, err := getFmtCode(ctx)
@@ -26,12 +28,19 @@ func fmtHandler(ctx *cli.Context) error {
 	// Parse the code
 	program, err := parser.Parse(context.Background(), code, nil)
 	if err != nil {
+		if check {
+			return reportFmtCheck(ctx, errorDiagnostics(fmtFileName(filePath), "parse-error", err))
+		}
 		return err
 	}
 
 	// Format the code
 	formatted := formatProgram(program)
 
+	if check {
+		return reportFmtCheck(ctx, formatDiagnostics(fmtFileName(filePath), code, formatted))
+	}
+
 	if write && filePath != "" {
 		// Write back to file
 		return os.WriteFile(filePath, []byte(formatted), 0o644)
@@ -42,6 +51,53 @@ func fmtHandler(ctx *cli.Context) error {
 	return nil
 }
 
+func fmtFileName(filePath string) string {
+	if filePath == "" {
+		return "<stdin>"
+	}
+	return filePath
+}
+
+// formatDiagnostics reports source that differs from its formatted form,
+// positioned at the first line that changes.
+func formatDiagnostics(file, source, formatted string) []Diagnostic {
+	if source == formatted {
+		return nil
+	}
+	srcLines := strings.Split(source, "\n")
+	fmtLines := strings.Split(formatted, "\n")
+	line := 1
+	for line <= len(srcLines) && line <= len(fmtLines) && srcLines[line-1] == fmtLines[line-1] {
+		line++
+	}
+	return []Diagnostic{{
+		File:     file,
+		Line:     line,
+		Column:   1,
+		Rule:     "format",
+		Severity: "error",
+		Message:  "file is not formatted; run risor fmt -w",
+	}}
+}
+
+// reportFmtCheck prints the result of fmt --check and exits with status 1
+// if any diagnostics were found.
+func reportFmtCheck(ctx *cli.Context, diags []Diagnostic) error {
+	if ctx.String("output") == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(newDiagnosticReport(diags))
+	} else {
+		for _, d := range diags {
+			fmt.Printf("%s:%d:%d: %s [%s]\n", d.File, d.Line, d.Column, d.Message, d.Rule)
+		}
+	}
+	if len(diags) > 0 {
+		os.Exit(1)
+	}
+	return nil
+}
+
 func getFmtCode(ctx *cli.Context) (string, string, error) {
 	codeSet := ctx.IsSet("code")
 	stdinSet := ctx.Bool("stdin")
@@ -123,6 +179,7 @@ func (f *Formatter) formatNode(node ast.Node) {
 	case *ast.Var:
 		f.buf.WriteString("let ")
 		f.buf.WriteString(n.Name.Name)
+		f.formatType(": ", n.Type)
 		if n.Value != nil {
 			f.buf.WriteString(" = ")
 			f.formatNode(n.Value)
@@ -131,11 +188,24 @@ func (f *Formatter) formatNode(node ast.Node) {
 	case *ast.Const:
 		f.buf.WriteString("const ")
 		f.buf.WriteString(n.Name.Name)
+		f.formatType(": ", n.Type)
 		if n.Value != nil {
 			f.buf.WriteString(" = ")
 			f.formatNode(n.Value)
 		}
 
+	case *ast.Enum:
+		f.buf.WriteString("enum ")
+		f.buf.WriteString(n.Name.Name)
+		f.buf.WriteString(" { ")
+		for i, member := range n.Members {
+			if i > 0 {
+				f.buf.WriteString(", ")
+			}
+			f.buf.WriteString(member.Name)
+		}
+		f.buf.WriteString(" }")
+
 	case *ast.Assign:
 		if n.Name != nil {
 			f.formatNode(n.Name)
@@ -189,8 +259,12 @@ func (f *Formatter) formatNode(node ast.Node) {
 			f.buf.WriteString(n.Name.Name)
 		}
 		f.buf.WriteString("(")
-		f.formatParams(n.Params, n.Defaults, n.RestParam)
+		f.formatParams(n)
 		f.buf.WriteString(") ")
+		if n.ReturnType != nil {
+			f.formatType("-> ", n.ReturnType)
+			f.buf.WriteString(" ")
+		}
 		f.formatNode(n.Body)
 
 	case *ast.Call:
@@ -341,6 +415,19 @@ func (f *Formatter) formatNode(node ast.Node) {
 		}
 		f.buf.WriteString("]")
 
+	case *ast.Tuple:
+		f.buf.WriteString("(")
+		for i, item := range n.Items {
+			if i > 0 {
+				f.buf.WriteString(", ")
+			}
+			f.formatNode(item)
+		}
+		if len(n.Items) == 1 {
+			f.buf.WriteString(",")
+		}
+		f.buf.WriteString(")")
+
 	case *ast.Map:
 		if len(n.Items) == 0 {
 			f.buf.WriteString("{}")
@@ -457,15 +544,16 @@ func (f *Formatter) formatNode(node ast.Node) {
 	}
 }
 
-func (f *Formatter) formatParams(params []ast.FuncParam, defaults map[string]ast.Expr, rest *ast.Ident) {
-	for i, p := range params {
+func (f *Formatter) formatParams(fn *ast.Func) {
+	for i, p := range fn.Params {
 		if i > 0 {
 			f.buf.WriteString(", ")
 		}
-		// For simple identifier params, check for defaults
+		// For simple identifier params, check for annotations and defaults
 		if ident, ok := p.(*ast.Ident); ok {
 			f.buf.WriteString(ident.Name)
-			if def, ok := defaults[ident.Name]; ok && def != nil {
+			f.formatType(": ", fn.ParamTypes[ident.Name])
+			if def, ok := fn.Defaults[ident.Name]; ok && def != nil {
 				f.buf.WriteString(" = ")
 				f.formatNode(def)
 			}
@@ -474,11 +562,20 @@ func (f *Formatter) formatParams(params []ast.FuncParam, defaults map[string]ast
 			f.buf.WriteString(p.String())
 		}
 	}
-	if rest != nil {
-		if len(params) > 0 {
+	if fn.RestParam != nil {
+		if len(fn.Params) > 0 {
 			f.buf.WriteString(", ")
 		}
 		f.buf.WriteString("...")
-		f.buf.WriteString(rest.Name)
+		f.buf.WriteString(fn.RestParam.Name)
+		f.formatType(": ", fn.ParamTypes[fn.RestParam.Name])
+	}
+}
+
+// formatType writes a type annotation after prefix, if there is one.
+func (f *Formatter) formatType(prefix string, typ *ast.TypeAnnotation) {
+	if typ != nil {
+		f.buf.WriteString(prefix)
+		f.buf.WriteString(typ.Name)
 	}
 }
@@ -30,6 +30,11 @@ func TestFormatProgram(t *testing.T) {
 			input:    "const PI=3.14",
 			expected: "const PI = 3.14\n",
 		},
+		{
+			name:     "enum",
+			input:    "enum Color {\n  Red,\n  Green,\n}",
+			expected: "enum Color { Red, Green }\n",
+		},
 		{
 			name:  "function",
 			input: "function add(a,b){return a+b}",
@@ -186,6 +191,21 @@ func TestFormatterFunctionWithDefaults(t *testing.T) {
 	assert.True(t, contains(result, "greeting = \"Hello\"") || contains(result, "greeting=\"Hello\""))
 }
 
+func TestFormatterTypeAnnotations(t *testing.T) {
+	input := "let   x:int=1\nconst s :string = \"a\"\nfunction f(a:int, b:float=2.5, ...rest:list)->float { a + b }"
+	program, err := parser.Parse(context.Background(), input, nil)
+	assert.Nil(t, err)
+
+	result := formatProgram(program)
+	assert.Contains(t, result, "let x: int = 1")
+	assert.Contains(t, result, `const s: string = "a"`)
+	assert.Contains(t, result, "function f(a: int, b: float = 2.5, ...rest: list) -> float {")
+
+	program, err = parser.Parse(context.Background(), "let g = (n:int, m:int=1) => n + m", nil)
+	assert.Nil(t, err)
+	assert.Contains(t, formatProgram(program), "function(n: int, m: int = 1) {")
+}
+
 func TestFormatterTryCatch(t *testing.T) {
 	input := "try { throw error(\"oops\") } catch e { e }"
 	program, err := parser.Parse(context.Background(), input, nil)
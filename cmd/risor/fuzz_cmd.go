@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/fuzz"
+	"github.com/deepnoodle-ai/wonton/cli"
+)
+
+// fuzzConfig controls a run of the fuzz command.
+type fuzzConfig struct {
+	Count    int           // Programs to check
+	Seed     int64         // Random seed for the generator
+	Duration time.Duration // Stop early after this long, if set
+	Seeds    []string      // Programs to mutate; fuzz.Seeds if empty
+	Save     string        // Directory to write failing programs to
+	Check    fuzz.Config
+}
+
+// fuzzReport summarizes a run of the fuzz command.
+type fuzzReport struct {
+	Seed     int64          `json:"seed"`
+	Programs int            `json:"programs"`
+	Failures []*fuzzFailure `json:"failures"`
+}
+
+type fuzzFailure struct {
+	Stage  string `json:"stage"`
+	Reason string `json:"reason"`
+	Error  string `json:"error"`
+	Source string `json:"source"`
+	Stack  string `json:"stack,omitempty"`
+	File   string `json:"file,omitempty"`
+}
+
+func fuzzHandler(ctx *cli.Context) error {
+	cfg := &fuzzConfig{
+		Count: ctx.Int("count"),
+		Seed:  int64(ctx.Int("seed")),
+		Save:  ctx.String("save"),
+		Check: fuzz.Config{
+			MaxSteps: int64(ctx.Int("max-steps")),
+		},
+	}
+	if !ctx.IsSet("seed") {
+		cfg.Seed = time.Now().UnixNano()
+	}
+	if t := ctx.String("time"); t != "" {
+		d, err := time.ParseDuration(t)
+		if err != nil {
+			return fmt.Errorf("invalid --time: %w", err)
+		}
+		cfg.Duration = d
+	}
+	if t := ctx.String("timeout"); t != "" {
+		d, err := time.ParseDuration(t)
+		if err != nil {
+			return fmt.Errorf("invalid --timeout: %w", err)
+		}
+		cfg.Check.Timeout = d
+	}
+	if dir := ctx.Arg(0); dir != "" {
+		seeds, err := loadFuzzSeeds(dir)
+		if err != nil {
+			return err
+		}
+		cfg.Seeds = seeds
+	}
+
+	report, err := runFuzz(ctx.Context(), cfg)
+	if err != nil {
+		return err
+	}
+	if ctx.String("output") == "json" {
+		if err := printFuzzJSON(os.Stdout, report); err != nil {
+			return err
+		}
+	} else {
+		printFuzzReport(os.Stdout, report)
+	}
+	if len(report.Failures) > 0 {
+		return &cli.ExitError{Code: 1}
+	}
+	return nil
+}
+
+// loadFuzzSeeds reads the .risor files in dir to use as seed programs.
+func loadFuzzSeeds(dir string) ([]string, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.risor"))
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no .risor files found in %s", dir)
+	}
+	seeds := make([]string, 0, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		seeds = append(seeds, string(data))
+	}
+	return seeds, nil
+}
+
+// runFuzz checks generated programs until the count or duration is reached,
+// collecting the programs that fail. Failures are written to cfg.Save when
+// it is set.
+func runFuzz(ctx context.Context, cfg *fuzzConfig) (*fuzzReport, error) {
+	if cfg.Save != "" {
+		if err := os.MkdirAll(cfg.Save, 0o755); err != nil {
+			return nil, err
+		}
+	}
+	var deadline time.Time
+	if cfg.Duration > 0 {
+		deadline = time.Now().Add(cfg.Duration)
+	}
+	report := &fuzzReport{Seed: cfg.Seed, Failures: []*fuzzFailure{}}
+	gen := fuzz.NewGenerator(cfg.Seed, cfg.Seeds)
+	for i := 0; i < cfg.Count; i++ {
+		if ctx.Err() != nil || (!deadline.IsZero() && time.Now().After(deadline)) {
+			break
+		}
+		source := gen.Next()
+		report.Programs++
+		err := fuzz.Check(ctx, source, &cfg.Check)
+		if err == nil {
+			continue
+		}
+		var failure *fuzz.Failure
+		if !errors.As(err, &failure) {
+			return nil, err
+		}
+		f := &fuzzFailure{
+			Stage:  failure.Stage,
+			Reason: failure.Reason,
+			Error:  failure.Err.Error(),
+			Source: failure.Source,
+			Stack:  failure.Stack,
+		}
+		if cfg.Save != "" {
+			f.File = filepath.Join(cfg.Save, fmt.Sprintf("failure-%d.risor", len(report.Failures)+1))
+			if err := os.WriteFile(f.File, []byte(source), 0o644); err != nil {
+				return nil, err
+			}
+		}
+		report.Failures = append(report.Failures, f)
+	}
+	return report, nil
+}
+
+// printFuzzReport writes each failure followed by a one-line summary.
+func printFuzzReport(w io.Writer, report *fuzzReport) {
+	for i, f := range report.Failures {
+		fmt.Fprintf(w, "FAIL %d: %s: %s: %s\n", i+1, f.Stage, f.Reason, f.Error)
+		if f.File != "" {
+			fmt.Fprintf(w, "  saved to %s\n", f.File)
+		}
+		for _, line := range strings.Split(f.Source, "\n") {
+			fmt.Fprintf(w, "  | %s\n", line)
+		}
+		fmt.Fprintln(w)
+	}
+	fmt.Fprintf(w, "%d programs checked, %d failures (seed %d)\n",
+		report.Programs, len(report.Failures), report.Seed)
+}
+
+func printFuzzJSON(w io.Writer, report *fuzzReport) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
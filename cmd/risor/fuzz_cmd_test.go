@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/fuzz"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+func TestRunFuzz(t *testing.T) {
+	report, err := runFuzz(context.Background(), &fuzzConfig{Count: 50, Seed: 7})
+	assert.Nil(t, err)
+	assert.Equal(t, report.Programs, 50)
+	assert.Equal(t, report.Seed, int64(7))
+	assert.Len(t, report.Failures, 0)
+}
+
+func TestRunFuzz_SaveFailures(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "failures")
+	cfg := &fuzzConfig{
+		Count: 20,
+		Seeds: []string{"explode()"},
+		Save:  dir,
+		Check: fuzz.Config{Env: map[string]any{
+			"explode": func() { panic("boom") },
+		}},
+	}
+	report, err := runFuzz(context.Background(), cfg)
+	assert.Nil(t, err)
+	assert.Equal(t, report.Programs, 20)
+	assert.True(t, len(report.Failures) > 0)
+	for _, f := range report.Failures {
+		data, err := os.ReadFile(f.File)
+		assert.Nil(t, err)
+		assert.Equal(t, string(data), f.Source)
+	}
+}
+
+func TestLoadFuzzSeeds(t *testing.T) {
+	dir := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "a.risor"), []byte("1 + 2"), 0o644))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "b.txt"), []byte("ignored"), 0o644))
+	seeds, err := loadFuzzSeeds(dir)
+	assert.Nil(t, err)
+	assert.Equal(t, seeds, []string{"1 + 2"})
+
+	_, err = loadFuzzSeeds(t.TempDir())
+	assert.NotNil(t, err)
+}
+
+func TestPrintFuzzReport(t *testing.T) {
+	var out bytes.Buffer
+	printFuzzReport(&out, &fuzzReport{
+		Seed:     3,
+		Programs: 10,
+		Failures: []*fuzzFailure{{
+			Stage:  "run",
+			Reason: "panic",
+			Error:  "boom",
+			Source: "explode()",
+		}},
+	})
+	assert.Equal(t, out.String(), "FAIL 1: run: panic: boom\n"+
+		"  | explode()\n\n"+
+		"10 programs checked, 1 failures (seed 3)\n")
+}
@@ -1,21 +1,45 @@
 module github.com/deepnoodle-ai/risor/v2/cmd/risor
 
-go 1.25
+go 1.25.0
 
-replace github.com/deepnoodle-ai/risor/v2 => ../..
+replace (
+	github.com/deepnoodle-ai/risor/v2 => ../..
+	github.com/deepnoodle-ai/risor/v2/pkg/modules/proto => ../../pkg/modules/proto
+	github.com/deepnoodle-ai/risor/v2/pkg/modules/render => ../../pkg/modules/render
+	github.com/deepnoodle-ai/risor/v2/pkg/modules/sqlite => ../../pkg/modules/sqlite
+	github.com/deepnoodle-ai/risor/v2/pkg/modules/store/bolt => ../../pkg/modules/store/bolt
+	github.com/deepnoodle-ai/risor/v2/pkg/modules/unicode => ../../pkg/modules/unicode
+)
 
 require (
 	github.com/deepnoodle-ai/risor/v2 v2.0.0
+	github.com/deepnoodle-ai/risor/v2/pkg/modules/proto v0.0.0
+	github.com/deepnoodle-ai/risor/v2/pkg/modules/render v0.0.0
+	github.com/deepnoodle-ai/risor/v2/pkg/modules/sqlite v0.0.0
+	github.com/deepnoodle-ai/risor/v2/pkg/modules/store/bolt v0.0.0
+	github.com/deepnoodle-ai/risor/v2/pkg/modules/unicode v0.0.0
 	github.com/deepnoodle-ai/wonton v0.0.25
+	golang.org/x/term v0.38.0
 )
 
 require (
 	github.com/alecthomas/chroma/v2 v2.20.0 // indirect
 	github.com/dlclark/regexp2 v1.11.5 // indirect
+	github.com/dustin/go-humanize v1.0.1 // indirect
 	github.com/google/go-cmp v0.7.0 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/mattn/go-isatty v0.0.24 // indirect
 	github.com/mattn/go-runewidth v0.0.17 // indirect
-	github.com/rivo/uniseg v0.2.0 // indirect
+	github.com/ncruces/go-strftime v1.0.0 // indirect
+	github.com/remyoudompheng/bigfft v0.0.0-20230129092748-24d4a6f8daec // indirect
+	github.com/rivo/uniseg v0.4.7 // indirect
 	github.com/yuin/goldmark v1.7.13 // indirect
-	golang.org/x/sys v0.39.0 // indirect
-	golang.org/x/term v0.38.0 // indirect
+	go.etcd.io/bbolt v1.5.0 // indirect
+	golang.org/x/sys v0.47.0 // indirect
+	golang.org/x/text v0.36.0 // indirect
+	google.golang.org/protobuf v1.36.11 // indirect
+	modernc.org/libc v1.75.7 // indirect
+	modernc.org/mathutil v1.7.1 // indirect
+	modernc.org/memory v1.12.1 // indirect
+	modernc.org/sqlite v1.59.0 // indirect
 )
@@ -0,0 +1,297 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"go/token"
+	"os"
+	"path/filepath"
+	"text/template"
+
+	"github.com/deepnoodle-ai/wonton/cli"
+)
+
+// embedFile is a Go source file produced by the init-embed command.
+type embedFile struct {
+	Name     string
+	Template *template.Template
+}
+
+// embedParams are the values substituted into the embedding templates.
+type embedParams struct {
+	Package string
+}
+
+var embedFiles = []embedFile{
+	{Name: "risor_engine.go", Template: template.Must(template.New("engine").Parse(embedEngineTemplate))},
+	{Name: "risor_engine_test.go", Template: template.Must(template.New("test").Parse(embedTestTemplate))},
+}
+
+func initEmbedHandler(ctx *cli.Context) error {
+	dir := ctx.Arg(0)
+	if dir == "" {
+		dir = "."
+	}
+	pkg := ctx.String("package")
+	if pkg == "" {
+		pkg = filepath.Base(mustAbs(dir))
+	}
+	if !token.IsIdentifier(pkg) {
+		return fmt.Errorf("invalid package name %q (use --package to set one)", pkg)
+	}
+
+	files, err := renderEmbedFiles(embedParams{Package: pkg})
+	if err != nil {
+		return err
+	}
+
+	// Refuse to clobber existing files unless asked to
+	if !ctx.Bool("force") {
+		for _, f := range embedFiles {
+			path := filepath.Join(dir, f.Name)
+			if _, err := os.Stat(path); err == nil {
+				return fmt.Errorf("%s already exists (use --force to overwrite)", path)
+			}
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	for _, f := range embedFiles {
+		path := filepath.Join(dir, f.Name)
+		if err := os.WriteFile(path, files[f.Name], 0o644); err != nil {
+			return err
+		}
+		fmt.Println("created", path)
+	}
+	return nil
+}
+
+// renderEmbedFiles executes each embedding template and gofmts the result.
+func renderEmbedFiles(params embedParams) (map[string][]byte, error) {
+	files := make(map[string][]byte, len(embedFiles))
+	for _, f := range embedFiles {
+		var buf bytes.Buffer
+		if err := f.Template.Execute(&buf, params); err != nil {
+			return nil, fmt.Errorf("rendering %s: %w", f.Name, err)
+		}
+		src, err := format.Source(buf.Bytes())
+		if err != nil {
+			return nil, errors.Join(fmt.Errorf("formatting %s", f.Name), err)
+		}
+		files[f.Name] = src
+	}
+	return files, nil
+}
+
+func mustAbs(dir string) string {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return dir
+	}
+	return abs
+}
+
+const embedEngineTemplate = `package {{.Package}}
+
+// This file was generated by "risor init-embed". It is a starting point for
+// running Risor scripts inside a Go service; adapt it freely.
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/deepnoodle-ai/risor/v2"
+	"github.com/deepnoodle-ai/risor/v2/pkg/bytecode"
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+)
+
+// Limits bound the resources a single script run may consume. Scripts are
+// untrusted input: keep every limit set in production.
+type Limits struct {
+	MaxSteps      int64
+	MaxStackDepth int
+	Timeout       time.Duration
+}
+
+// DefaultLimits are conservative limits suitable for short rules and
+// expressions.
+var DefaultLimits = Limits{
+	MaxSteps:      1_000_000,
+	MaxStackDepth: 256,
+	Timeout:       time.Second,
+}
+
+// Schema declares the globals scripts may use and their types. Compilation
+// fails if a script misuses one of them and Run fails if the host forgets
+// to supply one. Edit this to match the values your service provides.
+var Schema = map[string]risor.Type{
+	"input":  risor.TypeAny,
+	"config": "map",
+}
+
+// Engine compiles and runs Risor scripts. Compiled bytecode is cached by
+// source, and is immutable, so an Engine is safe for concurrent use.
+type Engine struct {
+	limits Limits
+	env    map[string]any
+
+	mu    sync.RWMutex
+	cache map[string]*bytecode.Code
+}
+
+// NewEngine returns an Engine with the standard library, the host functions
+// defined below, and the given limits.
+func NewEngine(limits Limits) *Engine {
+	env := risor.Builtins()
+	for name, fn := range hostFunctions() {
+		env[name] = fn
+	}
+	return &Engine{
+		limits: limits,
+		env:    env,
+		cache:  map[string]*bytecode.Code{},
+	}
+}
+
+// hostFunctions are the Go functions exposed to scripts. Keep them small,
+// side-effect free where possible, and safe to call concurrently.
+func hostFunctions() map[string]any {
+	return map[string]any{
+		"now": object.NewBuiltin("now", func(ctx context.Context, args ...object.Object) (object.Object, error) {
+			if len(args) != 0 {
+				return nil, fmt.Errorf("now: expected 0 arguments, got %d", len(args))
+			}
+			return object.NewTime(time.Now()), nil
+		}),
+	}
+}
+
+// Compile compiles a script against the engine's environment and Schema.
+func (e *Engine) Compile(ctx context.Context, source string) (*bytecode.Code, error) {
+	e.mu.RLock()
+	code, ok := e.cache[source]
+	e.mu.RUnlock()
+	if ok {
+		return code, nil
+	}
+	code, err := risor.Compile(ctx, source, e.options(nil)...)
+	if err != nil {
+		return nil, &ScriptError{Phase: "compile", Err: err}
+	}
+	e.mu.Lock()
+	e.cache[source] = code
+	e.mu.Unlock()
+	return code, nil
+}
+
+// Run compiles (or reuses) the script and runs it with the given values for
+// the globals declared in Schema.
+func (e *Engine) Run(ctx context.Context, source string, vars map[string]any) (any, error) {
+	code, err := e.Compile(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+	result, err := risor.Run(ctx, code, e.options(vars)...)
+	if err != nil {
+		return nil, &ScriptError{Phase: "run", Err: err, LimitExceeded: isLimitError(err)}
+	}
+	return result, nil
+}
+
+func (e *Engine) options(vars map[string]any) []risor.Option {
+	env := make(map[string]any, len(e.env)+len(vars))
+	for name, value := range e.env {
+		env[name] = value
+	}
+	for name, value := range vars {
+		env[name] = value
+	}
+	opts := []risor.Option{
+		risor.WithEnvSchema(Schema),
+		risor.WithEnv(env),
+	}
+	if e.limits.MaxSteps > 0 {
+		opts = append(opts, risor.WithMaxSteps(e.limits.MaxSteps))
+	}
+	if e.limits.MaxStackDepth > 0 {
+		opts = append(opts, risor.WithMaxStackDepth(e.limits.MaxStackDepth))
+	}
+	if e.limits.Timeout > 0 {
+		opts = append(opts, risor.WithTimeout(e.limits.Timeout))
+	}
+	return opts
+}
+
+// ScriptError wraps an error raised while compiling or running a script.
+// Risor errors carry line, column, and stack details in their message.
+type ScriptError struct {
+	Phase         string // "compile" or "run"
+	Err           error
+	LimitExceeded bool // true if a resource limit stopped the script
+}
+
+func (e *ScriptError) Error() string {
+	return fmt.Sprintf("script %s error: %v", e.Phase, e.Err)
+}
+
+func (e *ScriptError) Unwrap() error {
+	return e.Err
+}
+
+func isLimitError(err error) bool {
+	return errors.Is(err, risor.ErrStepLimitExceeded) ||
+		errors.Is(err, risor.ErrStackOverflow) ||
+		errors.Is(err, context.DeadlineExceeded)
+}
+`
+
+const embedTestTemplate = `package {{.Package}}
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestEngineRun(t *testing.T) {
+	engine := NewEngine(DefaultLimits)
+	result, err := engine.Run(context.Background(), "input * 2", map[string]any{
+		"input":  21,
+		"config": map[string]any{},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result != int64(42) {
+		t.Fatalf("expected 42, got %v", result)
+	}
+}
+
+func TestEngineCompileError(t *testing.T) {
+	engine := NewEngine(DefaultLimits)
+	_, err := engine.Run(context.Background(), "let x = ", nil)
+	var scriptErr *ScriptError
+	if !errors.As(err, &scriptErr) || scriptErr.Phase != "compile" {
+		t.Fatalf("expected a compile error, got %v", err)
+	}
+}
+
+func TestEngineStepLimit(t *testing.T) {
+	engine := NewEngine(Limits{MaxSteps: 1000})
+	_, err := engine.Run(context.Background(), "range(1000000).each(x => x)", map[string]any{
+		"input":  nil,
+		"config": map[string]any{},
+	})
+	var scriptErr *ScriptError
+	if !errors.As(err, &scriptErr) || !scriptErr.LimitExceeded {
+		t.Fatalf("expected a limit error, got %v", err)
+	}
+}
+`
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/deepnoodle-ai/wonton/assert"
+	"github.com/deepnoodle-ai/wonton/cli"
+)
+
+func newInitEmbedApp() *cli.App {
+	app := cli.New("risor").SetColorEnabled(false)
+	app.Command("init-embed").
+		Args("dir?").
+		Flags(
+			cli.String("package", "p"),
+			cli.Bool("force", ""),
+		).
+		Run(initEmbedHandler)
+	return app
+}
+
+func TestRenderEmbedFiles(t *testing.T) {
+	files, err := renderEmbedFiles(embedParams{Package: "rules"})
+	assert.Nil(t, err)
+	assert.Len(t, files, len(embedFiles))
+
+	fset := token.NewFileSet()
+	for name, src := range files {
+		f, err := parser.ParseFile(fset, name, src, parser.ImportsOnly)
+		assert.Nil(t, err, name)
+		assert.Equal(t, f.Name.Name, "rules")
+	}
+}
+
+func TestInitEmbedHandler(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "scripting")
+
+	err := newInitEmbedApp().ExecuteArgs([]string{"init-embed", dir})
+	assert.Nil(t, err)
+	for _, f := range embedFiles {
+		data, err := os.ReadFile(filepath.Join(dir, f.Name))
+		assert.Nil(t, err)
+		assert.Contains(t, string(data), "package scripting")
+	}
+
+	// Existing files are not overwritten without --force
+	err = newInitEmbedApp().ExecuteArgs([]string{"init-embed", dir})
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "already exists")
+
+	err = newInitEmbedApp().ExecuteArgs([]string{"init-embed", "--force", "-p", "hooks", dir})
+	assert.Nil(t, err)
+	data, err := os.ReadFile(filepath.Join(dir, "risor_engine.go"))
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), "package hooks")
+}
+
+func TestInitEmbedHandler_InvalidPackage(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "my-service")
+	err := newInitEmbedApp().ExecuteArgs([]string{"init-embed", dir})
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "invalid package name")
+}
@@ -0,0 +1,557 @@
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	goerrors "errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/errors"
+	"github.com/deepnoodle-ai/risor/v2/pkg/parser"
+	"github.com/deepnoodle-ai/wonton/cli"
+)
+
+// jupyterProtocolVersion is the version of the Jupyter messaging protocol
+// the kernel implements.
+const jupyterProtocolVersion = "5.3"
+
+// jupyterDelimiter separates routing ids from the rest of a message.
+const jupyterDelimiter = "<IDS|MSG>"
+
+// kernelConnection is the connection file Jupyter passes to a kernel.
+type kernelConnection struct {
+	Transport       string `json:"transport"`
+	IP              string `json:"ip"`
+	ShellPort       int    `json:"shell_port"`
+	IOPubPort       int    `json:"iopub_port"`
+	StdinPort       int    `json:"stdin_port"`
+	ControlPort     int    `json:"control_port"`
+	HBPort          int    `json:"hb_port"`
+	Key             string `json:"key"`
+	SignatureScheme string `json:"signature_scheme"`
+}
+
+// kernelSpec is the kernel.json file that registers the kernel with Jupyter.
+type kernelSpec struct {
+	Argv        []string `json:"argv"`
+	DisplayName string   `json:"display_name"`
+	Language    string   `json:"language"`
+}
+
+func kernelInstallHandler(ctx *cli.Context) error {
+	dir := ctx.String("dir")
+	if dir == "" {
+		var err error
+		if dir, err = jupyterKernelsDir(); err != nil {
+			return err
+		}
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("locating the risor executable: %w", err)
+	}
+	path, err := installKernelSpec(dir, exe)
+	if err != nil {
+		return err
+	}
+	fmt.Println("installed", path)
+	return nil
+}
+
+// installKernelSpec writes a kernel spec that runs exe as the kernel into
+// the "risor" subdirectory of dir and returns the path of the spec.
+func installKernelSpec(dir, exe string) (string, error) {
+	spec, err := json.MarshalIndent(kernelSpec{
+		Argv:        []string{exe, "kernel", "run", "--connection-file", "{connection_file}"},
+		DisplayName: "Risor",
+		Language:    "risor",
+	}, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	dir = filepath.Join(dir, "risor")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, "kernel.json")
+	if err := os.WriteFile(path, append(spec, '\n'), 0o644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// jupyterKernelsDir returns the per-user directory Jupyter searches for
+// kernel specs.
+func jupyterKernelsDir() (string, error) {
+	if dir := os.Getenv("JUPYTER_DATA_DIR"); dir != "" {
+		return filepath.Join(dir, "kernels"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	switch runtime.GOOS {
+	case "darwin":
+		return filepath.Join(home, "Library", "Jupyter", "kernels"), nil
+	case "windows":
+		if appData := os.Getenv("APPDATA"); appData != "" {
+			return filepath.Join(appData, "jupyter", "kernels"), nil
+		}
+		return filepath.Join(home, "AppData", "Roaming", "jupyter", "kernels"), nil
+	}
+	if dataHome := os.Getenv("XDG_DATA_HOME"); dataHome != "" {
+		return filepath.Join(dataHome, "jupyter", "kernels"), nil
+	}
+	return filepath.Join(home, ".local", "share", "jupyter", "kernels"), nil
+}
+
+func kernelRunHandler(ctx *cli.Context) error {
+	data, err := os.ReadFile(ctx.String("connection-file"))
+	if err != nil {
+		return err
+	}
+	var conn kernelConnection
+	if err := json.Unmarshal(data, &conn); err != nil {
+		return fmt.Errorf("invalid connection file: %w", err)
+	}
+	env, err := getReplEnv(ctx)
+	if err != nil {
+		return err
+	}
+	k, err := newKernel(conn, env)
+	if err != nil {
+		return err
+	}
+	defer k.Close()
+	return k.Serve(ctx.Context())
+}
+
+// kernel is a Jupyter kernel backed by a persistent REPL VM, so variables
+// and functions defined in one cell are available in later cells.
+type kernel struct {
+	key     []byte
+	session string
+	vm      *replVM
+
+	shell, control, stdin, iopub, hb *zmtpSocket
+
+	executionCount int
+	parent         *jupyterMessage // request being executed, for print output
+
+	mu       sync.Mutex
+	cancel   context.CancelFunc // cancels the running execution
+	shutdown chan struct{}
+	once     sync.Once
+}
+
+// kernelStream publishes text written by the print builtins as stream
+// output of the request being executed.
+type kernelStream struct {
+	kernel *kernel
+	name   string
+}
+
+func (s *kernelStream) Write(p []byte) (int, error) {
+	s.kernel.publish(s.kernel.parent, "stream", map[string]any{"name": s.name, "text": string(p)})
+	return len(p), nil
+}
+
+// jupyterMessage is a message in the Jupyter wire format.
+type jupyterMessage struct {
+	Identities   [][]byte
+	Header       jupyterHeader
+	ParentHeader json.RawMessage
+	Metadata     json.RawMessage
+	Content      json.RawMessage
+
+	rawHeader []byte // header as received, echoed as the parent of replies
+}
+
+type jupyterHeader struct {
+	MsgID    string `json:"msg_id"`
+	Session  string `json:"session"`
+	Username string `json:"username"`
+	Date     string `json:"date"`
+	MsgType  string `json:"msg_type"`
+	Version  string `json:"version"`
+}
+
+func newKernel(conn kernelConnection, env map[string]any) (*kernel, error) {
+	if conn.Transport != "" && conn.Transport != "tcp" {
+		return nil, fmt.Errorf("unsupported transport %q (only tcp is supported)", conn.Transport)
+	}
+	if conn.Key != "" && conn.SignatureScheme != "" && conn.SignatureScheme != "hmac-sha256" {
+		return nil, fmt.Errorf("unsupported signature scheme %q", conn.SignatureScheme)
+	}
+	k := &kernel{
+		key:      []byte(conn.Key),
+		session:  newMessageID(),
+		shutdown: make(chan struct{}),
+	}
+	if env == nil {
+		env = map[string]any{}
+	}
+	stdout := &kernelStream{kernel: k, name: "stdout"}
+	stderr := &kernelStream{kernel: k, name: "stderr"}
+	for name, fn := range newOutputBuiltins(
+		func() io.Writer { return stdout },
+		func() io.Writer { return stderr },
+	) {
+		env[name] = fn
+	}
+	vm, err := newReplVM(env)
+	if err != nil {
+		return nil, err
+	}
+	k.vm = vm
+
+	sockets := []struct {
+		sock **zmtpSocket
+		kind string
+		port int
+	}{
+		{&k.shell, zmtpRouter, conn.ShellPort},
+		{&k.control, zmtpRouter, conn.ControlPort},
+		{&k.stdin, zmtpRouter, conn.StdinPort},
+		{&k.iopub, zmtpPub, conn.IOPubPort},
+		{&k.hb, zmtpEcho, conn.HBPort},
+	}
+	for _, s := range sockets {
+		sock, err := listenZMTP(s.kind, fmt.Sprintf("%s:%d", conn.IP, s.port))
+		if err != nil {
+			k.Close()
+			return nil, err
+		}
+		*s.sock = sock
+	}
+	return k, nil
+}
+
+// Close closes the kernel's sockets.
+func (k *kernel) Close() {
+	for _, s := range []*zmtpSocket{k.shell, k.control, k.stdin, k.iopub, k.hb} {
+		if s != nil {
+			s.Close()
+		}
+	}
+}
+
+// Serve handles requests until a shutdown request arrives or the context
+// is cancelled. Shell requests run one at a time; control requests are
+// handled concurrently so that a running cell can be interrupted.
+func (k *kernel) Serve(ctx context.Context) error {
+	k.publish(nil, "status", map[string]any{"execution_state": "starting"})
+	go k.serveChannel(ctx, k.control)
+	k.serveChannel(ctx, k.shell)
+	return nil
+}
+
+func (k *kernel) serveChannel(ctx context.Context, sock *zmtpSocket) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-k.shutdown:
+			return
+		case frames := <-sock.Incoming():
+			msg, err := k.decode(frames)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "risor kernel: dropping message: %v\n", err)
+				continue
+			}
+			k.handle(ctx, sock, msg)
+		}
+	}
+}
+
+// handle dispatches a shell or control request, publishing busy and idle
+// status around it as Jupyter clients expect.
+func (k *kernel) handle(ctx context.Context, sock *zmtpSocket, msg *jupyterMessage) {
+	k.publish(msg, "status", map[string]any{"execution_state": "busy"})
+	defer k.publish(msg, "status", map[string]any{"execution_state": "idle"})
+
+	msgType := msg.Header.MsgType
+	replyType := strings.TrimSuffix(msgType, "_request") + "_reply"
+	switch msgType {
+	case "kernel_info_request":
+		k.reply(sock, msg, replyType, k.kernelInfo())
+	case "execute_request":
+		k.reply(sock, msg, replyType, k.execute(ctx, msg))
+	case "is_complete_request":
+		var content struct {
+			Code string `json:"code"`
+		}
+		json.Unmarshal(msg.Content, &content)
+		k.reply(sock, msg, replyType, map[string]any{"status": isComplete(ctx, content.Code)})
+	case "complete_request":
+		var content struct {
+			Code      string `json:"code"`
+			CursorPos int    `json:"cursor_pos"`
+		}
+		json.Unmarshal(msg.Content, &content)
+		k.reply(sock, msg, replyType, k.complete(content.Code, content.CursorPos))
+	case "comm_info_request":
+		k.reply(sock, msg, replyType, map[string]any{"status": "ok", "comms": map[string]any{}})
+	case "history_request":
+		k.reply(sock, msg, replyType, map[string]any{"status": "ok", "history": []any{}})
+	case "interrupt_request":
+		k.mu.Lock()
+		if k.cancel != nil {
+			k.cancel()
+		}
+		k.mu.Unlock()
+		k.reply(sock, msg, replyType, map[string]any{"status": "ok"})
+	case "shutdown_request":
+		var content struct {
+			Restart bool `json:"restart"`
+		}
+		json.Unmarshal(msg.Content, &content)
+		k.reply(sock, msg, replyType, map[string]any{"status": "ok", "restart": content.Restart})
+		k.once.Do(func() { close(k.shutdown) })
+	default:
+		fmt.Fprintf(os.Stderr, "risor kernel: unsupported message type %q\n", msgType)
+	}
+}
+
+func (k *kernel) kernelInfo() map[string]any {
+	return map[string]any{
+		"status":                 "ok",
+		"protocol_version":       jupyterProtocolVersion,
+		"implementation":         "risor",
+		"implementation_version": version,
+		"banner":                 "Risor " + version,
+		"help_links":             []any{},
+		"language_info": map[string]any{
+			"name":            "risor",
+			"version":         version,
+			"mimetype":        "text/x-risor",
+			"file_extension":  ".risor",
+			"codemirror_mode": "javascript",
+			"pygments_lexer":  "javascript",
+		},
+	}
+}
+
+// execute runs a cell on the persistent VM and publishes its input, output,
+// and result or error. It returns the content of the execute_reply.
+func (k *kernel) execute(ctx context.Context, msg *jupyterMessage) map[string]any {
+	var content struct {
+		Code   string `json:"code"`
+		Silent bool   `json:"silent"`
+	}
+	json.Unmarshal(msg.Content, &content)
+	if !content.Silent {
+		k.executionCount++
+		k.publish(msg, "execute_input", map[string]any{
+			"code":            content.Code,
+			"execution_count": k.executionCount,
+		})
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	k.mu.Lock()
+	k.cancel = cancel
+	k.mu.Unlock()
+	k.parent = msg
+	result, err := k.vm.Eval(runCtx, content.Code)
+	interrupted := runCtx.Err() != nil
+	k.parent = nil
+	k.mu.Lock()
+	k.cancel = nil
+	k.mu.Unlock()
+	cancel()
+
+	if err != nil {
+		ename, evalue := "error", err.Error()
+		if interrupted {
+			ename, evalue = "KeyboardInterrupt", "execution interrupted"
+		} else if f, ok := err.(errors.FormattableError); ok {
+			formatted := f.ToFormatted()
+			ename, evalue = formatted.Kind, formatted.Message
+		}
+		errContent := map[string]any{
+			"ename":     ename,
+			"evalue":    evalue,
+			"traceback": strings.Split(strings.TrimRight(formatError(err, false).Error(), "\n"), "\n"),
+		}
+		k.publish(msg, "error", errContent)
+		errContent["status"] = "error"
+		errContent["execution_count"] = k.executionCount
+		return errContent
+	}
+	if result != nil && !content.Silent {
+		k.publish(msg, "execute_result", map[string]any{
+			"execution_count": k.executionCount,
+			"data":            map[string]any{"text/plain": formatKernelResult(result)},
+			"metadata":        map[string]any{},
+		})
+	}
+	return map[string]any{
+		"status":           "ok",
+		"execution_count":  k.executionCount,
+		"user_expressions": map[string]any{},
+		"payload":          []any{},
+	}
+}
+
+// formatKernelResult renders a cell result as the CLI prints it.
+func formatKernelResult(result any) string {
+	data, err := formatJSON(result, true)
+	if err != nil {
+		return fmt.Sprintf("%v", result)
+	}
+	return string(data)
+}
+
+// isComplete reports whether code is ready to run, for the
+// is_complete_request message.
+func isComplete(ctx context.Context, code string) string {
+	_, err := parser.Parse(ctx, code, nil)
+	switch {
+	case err == nil:
+		return "complete"
+	case isIncompleteInput(err):
+		return "incomplete"
+	default:
+		return "invalid"
+	}
+}
+
+// complete suggests global names that start with the identifier before the
+// cursor. The cursor position counts Unicode code points.
+func (k *kernel) complete(code string, cursorPos int) map[string]any {
+	runes := []rune(code)
+	cursorPos = min(max(cursorPos, 0), len(runes))
+	start := cursorPos
+	for start > 0 && (unicode.IsLetter(runes[start-1]) || unicode.IsDigit(runes[start-1]) || runes[start-1] == '_') {
+		start--
+	}
+	prefix := string(runes[start:cursorPos])
+	matches := []string{}
+	seen := map[string]bool{}
+	for _, name := range k.vm.GlobalNames() {
+		if strings.HasPrefix(name, prefix) && !seen[name] {
+			seen[name] = true
+			matches = append(matches, name)
+		}
+	}
+	return map[string]any{
+		"status":       "ok",
+		"matches":      matches,
+		"cursor_start": start,
+		"cursor_end":   cursorPos,
+		"metadata":     map[string]any{},
+	}
+}
+
+// reply sends a response to a request on the channel it arrived on.
+func (k *kernel) reply(sock *zmtpSocket, parent *jupyterMessage, msgType string, content any) {
+	frames, err := k.encode(parent.Identities, parent, msgType, content)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "risor kernel: %v\n", err)
+		return
+	}
+	sock.Send(frames)
+}
+
+// publish broadcasts a message on the IOPub channel.
+func (k *kernel) publish(parent *jupyterMessage, msgType string, content any) {
+	topic := [][]byte{[]byte("kernel." + k.session + "." + msgType)}
+	frames, err := k.encode(topic, parent, msgType, content)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "risor kernel: %v\n", err)
+		return
+	}
+	k.iopub.Send(frames)
+}
+
+func (k *kernel) sign(parts ...[]byte) string {
+	if len(k.key) == 0 {
+		return ""
+	}
+	mac := hmac.New(sha256.New, k.key)
+	for _, p := range parts {
+		mac.Write(p)
+	}
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// encode builds the frames of a message: routing ids, the delimiter, the
+// signature, and the header, parent header, metadata, and content.
+func (k *kernel) encode(ids [][]byte, parent *jupyterMessage, msgType string, content any) ([][]byte, error) {
+	header, err := json.Marshal(jupyterHeader{
+		MsgID:    newMessageID(),
+		Session:  k.session,
+		Username: "kernel",
+		Date:     time.Now().UTC().Format(time.RFC3339Nano),
+		MsgType:  msgType,
+		Version:  jupyterProtocolVersion,
+	})
+	if err != nil {
+		return nil, err
+	}
+	parentHeader := []byte("{}")
+	if parent != nil {
+		parentHeader = parent.rawHeader
+	}
+	body, err := json.Marshal(content)
+	if err != nil {
+		return nil, fmt.Errorf("encoding %s: %w", msgType, err)
+	}
+	metadata := []byte("{}")
+	frames := append([][]byte{}, ids...)
+	return append(frames,
+		[]byte(jupyterDelimiter),
+		[]byte(k.sign(header, parentHeader, metadata, body)),
+		header, parentHeader, metadata, body,
+	), nil
+}
+
+// decode parses and authenticates a received message.
+func (k *kernel) decode(frames [][]byte) (*jupyterMessage, error) {
+	i := 0
+	for i < len(frames) && string(frames[i]) != jupyterDelimiter {
+		i++
+	}
+	if len(frames) < i+6 {
+		return nil, goerrors.New("malformed message")
+	}
+	signature := frames[i+1]
+	parts := frames[i+2 : i+6]
+	if len(k.key) > 0 && !hmac.Equal([]byte(k.sign(parts...)), signature) {
+		return nil, goerrors.New("invalid signature")
+	}
+	msg := &jupyterMessage{
+		Identities:   frames[:i],
+		ParentHeader: parts[1],
+		Metadata:     parts[2],
+		Content:      parts[3],
+		rawHeader:    parts[0],
+	}
+	if err := json.Unmarshal(parts[0], &msg.Header); err != nil {
+		return nil, fmt.Errorf("invalid header: %w", err)
+	}
+	return msg, nil
+}
+
+// newMessageID returns a random UUID for message and session ids.
+func newMessageID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	h := hex.EncodeToString(b[:])
+	return h[0:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:]
+}
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/deepnoodle-ai/risor/v2"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+// kernelClient talks to a kernel over the shell and IOPub channels, the way
+// a Jupyter client does.
+type kernelClient struct {
+	t      *testing.T
+	k      *kernel
+	shell  *zmtpConn
+	iopub  *zmtpConn
+	client *kernel // used only to sign and decode messages
+}
+
+func dialZMTP(t *testing.T, sock *zmtpSocket, socketType string) *zmtpConn {
+	t.Helper()
+	conn, err := net.Dial("tcp", sock.Addr().String())
+	assert.Nil(t, err)
+	t.Cleanup(func() { conn.Close() })
+	c, err := zmtpHandshake(conn, socketType)
+	assert.Nil(t, err)
+	return c
+}
+
+func startTestKernel(t *testing.T) *kernelClient {
+	t.Helper()
+	conn := kernelConnection{Transport: "tcp", IP: "127.0.0.1", Key: "secret", SignatureScheme: "hmac-sha256"}
+	k, err := newKernel(conn, risor.Builtins())
+	assert.Nil(t, err)
+	t.Cleanup(k.Close)
+
+	c := &kernelClient{t: t, k: k, client: &kernel{key: []byte("secret"), session: "client"}}
+	c.iopub = dialZMTP(t, k.iopub, "SUB")
+	assert.Nil(t, c.iopub.writeMessage([][]byte{{1}})) // subscribe to all topics
+	c.shell = dialZMTP(t, k.shell, "DEALER")
+
+	done := make(chan struct{})
+	go func() {
+		k.Serve(context.Background())
+		close(done)
+	}()
+	t.Cleanup(func() {
+		k.once.Do(func() { close(k.shutdown) })
+		<-done
+	})
+	return c
+}
+
+// request sends a shell request and returns the reply along with the IOPub
+// messages published for it, up to the idle status.
+func (c *kernelClient) request(msgType string, content any) (*jupyterMessage, []*jupyterMessage) {
+	c.t.Helper()
+	frames, err := c.client.encode(nil, nil, msgType, content)
+	assert.Nil(c.t, err)
+	var header jupyterHeader
+	assert.Nil(c.t, json.Unmarshal(frames[2], &header))
+	assert.Nil(c.t, c.shell.writeMessage(frames))
+
+	var published []*jupyterMessage
+	for {
+		msg := c.read(c.iopub)
+		var parent jupyterHeader
+		json.Unmarshal(msg.ParentHeader, &parent)
+		if parent.MsgID != header.MsgID {
+			continue
+		}
+		if msg.Header.MsgType == "status" && strings.Contains(string(msg.Content), "idle") {
+			break
+		}
+		published = append(published, msg)
+	}
+	reply := c.read(c.shell)
+	assert.Equal(c.t, reply.Header.MsgType, strings.TrimSuffix(msgType, "_request")+"_reply")
+	return reply, published
+}
+
+func (c *kernelClient) read(conn *zmtpConn) *jupyterMessage {
+	c.t.Helper()
+	conn.conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	frames, err := conn.readMessage()
+	assert.Nil(c.t, err)
+	msg, err := c.client.decode(frames)
+	assert.Nil(c.t, err)
+	return msg
+}
+
+func contentOf(t *testing.T, msg *jupyterMessage) map[string]any {
+	t.Helper()
+	var content map[string]any
+	assert.Nil(t, json.Unmarshal(msg.Content, &content))
+	return content
+}
+
+func TestKernelExecute(t *testing.T) {
+	c := startTestKernel(t)
+
+	reply, published := c.request("execute_request", map[string]any{
+		"code": "let x = 2\nprint(\"hi\")\nx * 21",
+	})
+	assert.Equal(t, contentOf(t, reply)["status"], "ok")
+	assert.Equal(t, contentOf(t, reply)["execution_count"], float64(1))
+
+	var types []string
+	for _, msg := range published {
+		types = append(types, msg.Header.MsgType)
+	}
+	assert.Equal(t, types, []string{"status", "execute_input", "stream", "execute_result"})
+	assert.Equal(t, contentOf(t, published[2])["text"], "hi\n")
+	result := contentOf(t, published[3])["data"].(map[string]any)
+	assert.Equal(t, result["text/plain"], "42")
+
+	// State persists between cells
+	reply, published = c.request("execute_request", map[string]any{"code": "x + 1"})
+	assert.Equal(t, contentOf(t, reply)["execution_count"], float64(2))
+	result = contentOf(t, published[len(published)-1])["data"].(map[string]any)
+	assert.Equal(t, result["text/plain"], "3")
+}
+
+func TestKernelExecuteError(t *testing.T) {
+	c := startTestKernel(t)
+
+	reply, published := c.request("execute_request", map[string]any{"code": "missing + 1"})
+	content := contentOf(t, reply)
+	assert.Equal(t, content["status"], "error")
+	assert.Contains(t, content["evalue"], "undefined variable")
+	last := published[len(published)-1]
+	assert.Equal(t, last.Header.MsgType, "error")
+	assert.True(t, len(contentOf(t, last)["traceback"].([]any)) > 0)
+}
+
+func TestKernelInfoAndCompletion(t *testing.T) {
+	c := startTestKernel(t)
+
+	reply, _ := c.request("kernel_info_request", map[string]any{})
+	content := contentOf(t, reply)
+	assert.Equal(t, content["protocol_version"], jupyterProtocolVersion)
+	assert.Equal(t, content["language_info"].(map[string]any)["name"], "risor")
+
+	reply, _ = c.request("is_complete_request", map[string]any{"code": "if (true) {"})
+	assert.Equal(t, contentOf(t, reply)["status"], "incomplete")
+	reply, _ = c.request("is_complete_request", map[string]any{"code": "1 + 1"})
+	assert.Equal(t, contentOf(t, reply)["status"], "complete")
+
+	c.request("execute_request", map[string]any{"code": "let total_count = 1"})
+	reply, _ = c.request("complete_request", map[string]any{"code": "1 + total_c", "cursor_pos": 11})
+	content = contentOf(t, reply)
+	assert.Equal(t, content["matches"], []any{"total_count"})
+	assert.Equal(t, content["cursor_start"], float64(4))
+}
+
+func TestKernelShutdown(t *testing.T) {
+	c := startTestKernel(t)
+	reply, _ := c.request("shutdown_request", map[string]any{"restart": false})
+	assert.Equal(t, contentOf(t, reply)["status"], "ok")
+	select {
+	case <-c.k.shutdown:
+	case <-time.After(5 * time.Second):
+		t.Fatal("kernel did not shut down")
+	}
+}
+
+func TestKernelRejectsBadSignature(t *testing.T) {
+	k := &kernel{key: []byte("secret"), session: "test"}
+	frames, err := k.encode([][]byte{[]byte("id")}, nil, "kernel_info_request", map[string]any{})
+	assert.Nil(t, err)
+
+	msg, err := k.decode(frames)
+	assert.Nil(t, err)
+	assert.Equal(t, msg.Identities, [][]byte{[]byte("id")})
+	assert.Equal(t, msg.Header.MsgType, "kernel_info_request")
+
+	frames[2] = []byte("forged")
+	_, err = k.decode(frames)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "invalid signature")
+}
+
+func TestZMTPHeartbeatAndLongFrames(t *testing.T) {
+	sock, err := listenZMTP(zmtpEcho, "127.0.0.1:0")
+	assert.Nil(t, err)
+	defer sock.Close()
+
+	c := dialZMTP(t, sock, "REQ")
+	long := []byte(strings.Repeat("x", 1000))
+	assert.Nil(t, c.writeMessage([][]byte{{}, []byte("ping"), long}))
+	frames, err := c.readMessage()
+	assert.Nil(t, err)
+	assert.Equal(t, frames, [][]byte{{}, []byte("ping"), long})
+}
+
+func TestKernelInstall(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("JUPYTER_DATA_DIR", dir)
+	kernels, err := jupyterKernelsDir()
+	assert.Nil(t, err)
+	assert.Equal(t, kernels, filepath.Join(dir, "kernels"))
+
+	path, err := installKernelSpec(kernels, "/usr/local/bin/risor")
+	assert.Nil(t, err)
+	assert.Equal(t, path, filepath.Join(kernels, "risor", "kernel.json"))
+	data, err := os.ReadFile(path)
+	assert.Nil(t, err)
+	var spec kernelSpec
+	assert.Nil(t, json.Unmarshal(data, &spec))
+	assert.Equal(t, spec.Argv, []string{"/usr/local/bin/risor", "kernel", "run", "--connection-file", "{connection_file}"})
+	assert.Equal(t, spec.Language, "risor")
+}
+
+func TestKernelInterrupt(t *testing.T) {
+	c := startTestKernel(t)
+	control := dialZMTP(t, c.k.control, "DEALER")
+
+	frames, err := c.client.encode(nil, nil, "execute_request", map[string]any{
+		"code": "range(1000000000).each(x => x)",
+	})
+	assert.Nil(t, err)
+	assert.Nil(t, c.shell.writeMessage(frames))
+	time.Sleep(50 * time.Millisecond)
+
+	frames, err = c.client.encode(nil, nil, "interrupt_request", map[string]any{})
+	assert.Nil(t, err)
+	assert.Nil(t, control.writeMessage(frames))
+	assert.Equal(t, c.read(control).Header.MsgType, "interrupt_reply")
+
+	reply := c.read(c.shell)
+	assert.Equal(t, reply.Header.MsgType, "execute_reply")
+	content := contentOf(t, reply)
+	assert.Equal(t, content["status"], "error")
+	assert.Equal(t, content["ename"], "KeyboardInterrupt", content["evalue"])
+}
@@ -9,8 +9,11 @@ import (
 	"os"
 	"strings"
 
+	"github.com/deepnoodle-ai/risor/v2/internal/lexer"
+	"github.com/deepnoodle-ai/risor/v2/internal/token"
 	"github.com/deepnoodle-ai/risor/v2/pkg/ast"
 	"github.com/deepnoodle-ai/risor/v2/pkg/parser"
+	"github.com/deepnoodle-ai/risor/v2/pkg/syntax"
 	"github.com/deepnoodle-ai/wonton/cli"
 	"github.com/deepnoodle-ai/wonton/tui"
 )
@@ -37,24 +40,35 @@ func lintHandler(ctx *cli.Context) error {
 
 	outputFormat := ctx.String("output")
 
-	// Parse the code
+	if ctx.Bool("fix") {
+		if filename == "<stdin>" {
+			return errors.New("--fix requires a file")
+		}
+		if fixed := fixLintIssues(code); fixed != code {
+			if err := os.WriteFile(filename, []byte(fixed), 0o644); err != nil {
+				return err
+			}
+			code = fixed
+		}
+	}
+
+	// Parse the code, reporting parse errors as lint issues
+	var issues []LintIssue
 	program, parseErr := parser.Parse(context.Background(), code, nil)
 	if parseErr != nil {
-		// Report parse error as a lint issue
-		issues := []LintIssue{{
-			Line:    1,
-			Column:  1,
-			Rule:    "parse-error",
-			Message: parseErr.Error(),
-			Level:   "error",
-		}}
-		printLintResults(filename, issues, outputFormat)
-		return nil
+		for _, d := range errorDiagnostics(filename, "parse-error", parseErr) {
+			issues = append(issues, LintIssue{
+				Line:    d.Line,
+				Column:  d.Column,
+				Rule:    d.Rule,
+				Message: d.Message,
+				Level:   d.Severity,
+			})
+		}
+	} else {
+		issues = lintProgram(program, code)
 	}
 
-	// Run linting checks
-	issues := lintProgram(program, code)
-
 	// Print results
 	printLintResults(filename, issues, outputFormat)
 
@@ -69,6 +83,44 @@ func lintHandler(ctx *cli.Context) error {
 	return nil
 }
 
+// fixLintIssues returns the source with the issues that have a safe
+// automatic fix corrected. Only trailing whitespace is fixed, and only
+// outside string literals. Source that does not tokenize is returned as is.
+func fixLintIssues(source string) string {
+	inString, err := stringLines(source)
+	if err != nil {
+		return source
+	}
+	lines := strings.Split(source, "\n")
+	for i, line := range lines {
+		if !inString[i] {
+			lines[i] = strings.TrimRight(line, " \t")
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// stringLines returns the 0-indexed lines of the source that end inside a
+// multi-line string literal, where trailing whitespace is part of the value.
+func stringLines(source string) (map[int]bool, error) {
+	lines := map[int]bool{}
+	l := lexer.New(source)
+	for {
+		tok, err := l.Next()
+		if err != nil {
+			return nil, err
+		}
+		if tok.Type == token.EOF {
+			return lines, nil
+		}
+		if tok.Type == token.STRING || tok.Type == token.TEMPLATE {
+			for line := tok.StartPosition.Line; line < tok.EndPosition.Line; line++ {
+				lines[line] = true
+			}
+		}
+	}
+}
+
 func getLintCode(ctx *cli.Context) (string, string, error) {
 	codeSet := ctx.IsSet("code")
 	stdinSet := ctx.Bool("stdin")
@@ -121,6 +173,19 @@ func lintProgram(program *ast.Program, source string) []LintIssue {
 	constants := make(map[string]bool)      // track constants for reassignment check
 	shadowWarnings := make(map[string]bool) // prevent duplicate shadow warnings
 
+	// Collect enum declarations for match exhaustiveness hints
+	enums := make(map[string][]string) // enum name -> member names
+	ast.Inspect(program, func(node ast.Node) bool {
+		if n, ok := node.(*ast.Enum); ok {
+			members := make([]string, len(n.Members))
+			for i, member := range n.Members {
+				members[i] = member.Name
+			}
+			enums[n.Name.Name] = members
+		}
+		return true
+	})
+
 	// Visit all nodes
 	ast.Inspect(program, func(node ast.Node) bool {
 		if node == nil {
@@ -153,6 +218,18 @@ func lintProgram(program *ast.Program, source string) []LintIssue {
 			used[name] = false
 			constants[name] = true
 
+		case *ast.Enum:
+			name := n.Name.Name
+			line := n.Name.Pos().Line
+			declared[name] = line
+			used[name] = false
+			constants[name] = true
+
+		case *ast.Match:
+			if issue, ok := checkEnumMatch(n, enums); ok {
+				issues = append(issues, issue)
+			}
+
 		case *ast.Assign:
 			if n.Name != nil {
 				name := n.Name.Name
@@ -240,12 +317,14 @@ func lintProgram(program *ast.Program, source string) []LintIssue {
 		return true
 	})
 
-	// Check line-level issues
+	// Check line-level issues. Trailing whitespace inside a string literal
+	// is part of its value, not an issue.
+	inString, _ := stringLines(source)
 	for i, line := range lines {
 		lineNum := i + 1
 
 		// Check for trailing whitespace
-		if strings.HasSuffix(line, " ") || strings.HasSuffix(line, "\t") {
+		if !inString[i] && (strings.HasSuffix(line, " ") || strings.HasSuffix(line, "\t")) {
 			issues = append(issues, LintIssue{
 				Line:    lineNum,
 				Column:  len(line),
@@ -278,9 +357,71 @@ func lintProgram(program *ast.Program, source string) []LintIssue {
 		}
 	}
 
+	// Check values against type annotations
+	for _, e := range syntax.NewTypeChecker(nil).Validate(program) {
+		issues = append(issues, LintIssue{
+			Line:    e.Position.LineNumber(),
+			Column:  e.Position.ColumnNumber(),
+			Rule:    "type-check",
+			Message: e.Message,
+			Level:   "warning",
+		})
+	}
+
 	return issues
 }
 
+// checkEnumMatch reports a match whose arms compare against members of a
+// single enum declared in the file but do not cover all of its members.
+// Unhandled members silently fall through to the default arm, which is
+// usually a sign that a new member was added without updating the match.
+func checkEnumMatch(n *ast.Match, enums map[string][]string) (LintIssue, bool) {
+	enumName := ""
+	covered := make(map[string]bool)
+	for _, arm := range n.Arms {
+		pattern, ok := arm.Pattern.(*ast.LiteralPattern)
+		if !ok {
+			return LintIssue{}, false
+		}
+		attr, ok := pattern.Value.(*ast.GetAttr)
+		if !ok {
+			return LintIssue{}, false
+		}
+		ident, ok := attr.X.(*ast.Ident)
+		if !ok {
+			return LintIssue{}, false
+		}
+		if _, ok := enums[ident.Name]; !ok || (enumName != "" && ident.Name != enumName) {
+			return LintIssue{}, false
+		}
+		enumName = ident.Name
+		// A guarded arm does not handle every value of its member
+		if arm.Guard == nil {
+			covered[attr.Attr.Name] = true
+		}
+	}
+	if enumName == "" {
+		return LintIssue{}, false
+	}
+	var missing []string
+	for _, member := range enums[enumName] {
+		if !covered[member] {
+			missing = append(missing, enumName+"."+member)
+		}
+	}
+	if len(missing) == 0 {
+		return LintIssue{}, false
+	}
+	return LintIssue{
+		Line:   n.Pos().LineNumber(),
+		Column: n.Pos().ColumnNumber(),
+		Rule:   "enum-match-exhaustive",
+		Message: fmt.Sprintf("match on %s does not handle %s (handled by the default arm)",
+			enumName, strings.Join(missing, ", ")),
+		Level: "warning",
+	}, true
+}
+
 func printLintResults(filename string, issues []LintIssue, outputFormat string) {
 	if outputFormat == "json" {
 		printLintResultsJSON(filename, issues)
@@ -337,22 +478,23 @@ func printLintResults(filename string, issues []LintIssue, outputFormat string)
 }
 
 func printLintResultsJSON(filename string, issues []LintIssue) {
+	diags := make([]Diagnostic, len(issues))
+	for i, issue := range issues {
+		diags[i] = Diagnostic{
+			File:     filename,
+			Line:     issue.Line,
+			Column:   issue.Column,
+			Rule:     issue.Rule,
+			Severity: issue.Level,
+			Message:  issue.Message,
+		}
+	}
 	result := struct {
-		File     string      `json:"file"`
-		Issues   []LintIssue `json:"issues"`
-		Errors   int         `json:"errors"`
-		Warnings int         `json:"warnings"`
+		File string `json:"file"`
+		diagnosticReport
 	}{
-		File:   filename,
-		Issues: issues,
-	}
-
-	for _, issue := range issues {
-		if issue.Level == "error" {
-			result.Errors++
-		} else {
-			result.Warnings++
-		}
+		File:             filename,
+		diagnosticReport: newDiagnosticReport(diags),
 	}
 
 	enc := json.NewEncoder(os.Stdout)
@@ -132,6 +132,26 @@ func TestLintProgram_FixmeComment(t *testing.T) {
 	assert.True(t, found, "expected todo-comment warning for FIXME")
 }
 
+func TestLintProgram_TypeCheck(t *testing.T) {
+	code := "function add(a: int, b: int) -> int { a + b }\nadd(1, \"2\")"
+	program, err := parser.Parse(context.Background(), code, nil)
+	assert.Nil(t, err)
+
+	issues := lintProgram(program, code)
+
+	var found *LintIssue
+	for i, issue := range issues {
+		if issue.Rule == "type-check" {
+			found = &issues[i]
+			break
+		}
+	}
+	assert.NotNil(t, found, "expected type-check warning")
+	assert.Equal(t, found.Level, "warning")
+	assert.Equal(t, found.Line, 2)
+	assert.Contains(t, found.Message, "argument 2 to add")
+}
+
 func TestLintProgram_VariableShadowing(t *testing.T) {
 	code := `let x = 1
 let x = 2`
@@ -241,3 +261,55 @@ func TestGetLintCode_NoInput(t *testing.T) {
 		assert.Equal(t, issue.Column, 5)
 	})
 }
+
+func TestLintProgram_EnumMatchExhaustive(t *testing.T) {
+	code := `enum Color { Red, Green, Blue }
+let c = Color.Red
+match c { Color.Red => 1, Color.Green if true => 2, _ => 3 }`
+	program, err := parser.Parse(context.Background(), code, nil)
+	assert.Nil(t, err)
+
+	issues := lintProgram(program, code)
+	var found *LintIssue
+	for i, issue := range issues {
+		if issue.Rule == "enum-match-exhaustive" {
+			found = &issues[i]
+		}
+	}
+	assert.NotNil(t, found, "expected enum-match-exhaustive warning")
+	assert.Equal(t, found.Line, 3)
+	assert.Equal(t, found.Message,
+		"match on Color does not handle Color.Green, Color.Blue (handled by the default arm)")
+}
+
+func TestLintProgram_EnumMatchComplete(t *testing.T) {
+	code := `enum Color { Red, Green }
+let c = Color.Red
+match c { Color.Red => 1, Color.Green => 2, _ => 3 }
+match c { Color.Red => 1, "other" => 2, _ => 3 }`
+	program, err := parser.Parse(context.Background(), code, nil)
+	assert.Nil(t, err)
+
+	for _, issue := range lintProgram(program, code) {
+		assert.NotEqual(t, issue.Rule, "enum-match-exhaustive")
+	}
+}
+
+func TestFixLintIssues(t *testing.T) {
+	assert.Equal(t, fixLintIssues("let x = 1  \nlet y = 2\t\n"), "let x = 1\nlet y = 2\n")
+	assert.Equal(t, fixLintIssues("let x = 1\n"), "let x = 1\n")
+	// Whitespace at the end of a line inside a string is kept
+	source := "let s = `a  \nb\t\n`  \nlet t = \"x \"\n"
+	assert.Equal(t, fixLintIssues(source), "let s = `a  \nb\t\n`\nlet t = \"x \"\n")
+	// Source that does not tokenize is left alone
+	assert.Equal(t, fixLintIssues("let s = `a  \n"), "let s = `a  \n")
+}
+
+func TestLintProgram_TrailingWhitespaceInString(t *testing.T) {
+	source := "let s = `a  \nb`\nprint(s)\n"
+	program, err := parser.Parse(context.Background(), source, nil)
+	assert.Nil(t, err)
+	for _, issue := range lintProgram(program, source) {
+		assert.NotEqual(t, issue.Rule, "trailing-whitespace")
+	}
+}
@@ -3,6 +3,8 @@ package main
 import (
 	"os"
 
+	"github.com/deepnoodle-ai/risor/v2/pkg/fuzz"
+	"github.com/deepnoodle-ai/risor/v2/pkg/vm"
 	"github.com/deepnoodle-ai/wonton/cli"
 	"github.com/deepnoodle-ai/wonton/color"
 )
@@ -14,6 +16,14 @@ var (
 )
 
 func main() {
+	// Executables produced by "risor build" run their embedded program
+	if program, err := embeddedProgram(); err != nil {
+		printError(err.Error())
+		os.Exit(1)
+	} else if program != nil {
+		os.Exit(runProgram(program))
+	}
+
 	app := cli.New("risor").
 		Description("Fast and flexible scripting for Go developers").
 		Version(version).
@@ -28,6 +38,7 @@ func main() {
 		cli.String("cpu-profile", "").Help("Capture CPU profile"),
 		cli.Bool("no-color", "").Env("NO_COLOR").Help("Disable colored output"),
 		cli.Bool("no-default-globals", "").Help("Disable the standard library"),
+		cli.Int("error-exit-code", "").Default(1).Help("Exit code when a script fails with an error"),
 	)
 
 	// Root command: runs code or starts REPL
@@ -35,7 +46,8 @@ func main() {
 		Args("file?").
 		Flags(
 			cli.Bool("timing", "").Help("Show execution time"),
-			cli.String("output", "o").Enum("json", "text").Help("Output format"),
+			cli.String("output", "o").Enum("json", "yaml", "text").Help("Output format"),
+			cli.Bool("raw", "r").Help("Print string results without quotes"),
 			cli.Bool("no-repl", "").Help("Disable the REPL"),
 		).
 		Run(runHandler)
@@ -66,13 +78,14 @@ func main() {
 		Flags(
 			cli.Bool("verbose", "v").Help("Verbose output"),
 			cli.String("run", "r").Help("Run only tests matching pattern"),
+			cli.String("output", "o").Enum("json", "text").Help("Output format"),
 		).
 		Run(testHandler)
 
 	// Documentation command
 	app.Command("doc").
 		Alias("d").
-		Description("Browse language documentation").
+		Description("Browse language documentation, or document a .risor file").
 		Args("topic?").
 		Flags(
 			cli.String("format", "f").Enum("json", "text", "markdown").Help("Output format"),
@@ -101,6 +114,8 @@ func main() {
 			cli.String("code", "c").Help("Code to format"),
 			cli.Bool("stdin", "").Help("Read code from stdin"),
 			cli.Bool("write", "w").Help("Write result to source file"),
+			cli.Bool("check", "").Help("Report unformatted code instead of printing it"),
+			cli.String("output", "o").Enum("json", "text").Help("Output format for --check"),
 		).
 		Run(fmtHandler)
 
@@ -123,11 +138,23 @@ func main() {
 		Flags(
 			cli.String("code", "c").Help("Expression to evaluate"),
 			cli.Bool("stdin", "").Help("Read from stdin"),
-			cli.String("output", "o").Enum("json", "text").Help("Output format"),
+			cli.String("output", "o").Enum("json", "yaml", "text").Help("Output format"),
+			cli.Bool("raw", "r").Help("Print string results without quotes"),
 			cli.Bool("quiet", "q").Help("Suppress output"),
 		).
 		Run(evalHandler)
 
+	// Map command
+	app.Command("map").
+		Description("Evaluate an expression for each line of stdin").
+		Args("expr?").
+		Flags(
+			cli.String("code", "c").Help("Expression to evaluate"),
+			cli.Bool("json", "j").Help("Read stdin as NDJSON, one record per line"),
+			cli.String("output", "o").Enum("json", "text").Help("Output format"),
+		).
+		Run(mapHandler)
+
 	// Lint command
 	app.Command("lint").
 		Description("Check code for issues").
@@ -136,12 +163,24 @@ func main() {
 			cli.String("code", "c").Help("Code to check"),
 			cli.Bool("stdin", "").Help("Read code from stdin"),
 			cli.String("output", "o").Enum("json", "text").Help("Output format"),
+			cli.Bool("fix", "").Help("Fix trailing whitespace in the file before checking"),
 		).
 		Run(lintHandler)
 
+	// Capabilities command
+	app.Command("caps").
+		Description("List the capabilities a script could use").
+		Args("file?").
+		Flags(
+			cli.String("code", "c").Help("Code to check"),
+			cli.Bool("stdin", "").Help("Read code from stdin"),
+			cli.String("output", "o").Enum("json", "text").Help("Output format"),
+		).
+		Run(capsHandler)
+
 	// Benchmark command
 	app.Command("bench").
-		Description("Benchmark code execution").
+		Description("Benchmark code execution or a file's bench_* functions").
 		Args("file?").
 		Flags(
 			cli.String("code", "c").Help("Code to benchmark"),
@@ -149,14 +188,105 @@ func main() {
 			cli.Int("iterations", "n").Help("Number of iterations").Default(1000),
 			cli.Int("warmup", "w").Help("Warmup iterations").Default(100),
 			cli.String("output", "o").Enum("json", "text").Help("Output format"),
+			cli.String("run", "r").Help("Run only bench_* functions matching pattern"),
+			cli.String("time", "t").Help("Target time per bench_* function").Default("1s"),
+			cli.String("save", "").Help("Save bench_* results as a baseline file"),
+			cli.String("baseline", "b").Help("Compare bench_* results to a baseline file"),
 		).
 		Run(benchHandler)
 
+	// Fuzz command
+	app.Command("fuzz").
+		Description("Check that generated programs never panic or return unstructured errors").
+		Args("dir?").
+		Flags(
+			cli.Int("count", "n").Default(10_000).Help("Number of programs to check"),
+			cli.Int("seed", "s").Help("Random seed (defaults to the current time)"),
+			cli.String("time", "t").Help("Stop after this long, even if count is not reached"),
+			cli.Int("max-steps", "").Default(fuzz.DefaultMaxSteps).Help("Step limit per program"),
+			cli.String("timeout", "").Default(fuzz.DefaultTimeout.String()).Help("Time limit per program"),
+			cli.String("save", "").Help("Directory to write failing programs to"),
+			cli.String("output", "o").Enum("json", "text").Help("Output format"),
+		).
+		Run(fuzzHandler)
+
+	// Standalone executable builder
+	app.Command("build").
+		Description("Build a script into a standalone executable (not supported on darwin/arm64)").
+		Args("file").
+		Flags(
+			cli.String("output", "o").Help("Output path (defaults to the script name)"),
+		).
+		Run(buildHandler)
+
+	// Web playground
+	app.Command("playground").
+		Description("Start a web playground for evaluating scripts").
+		Flags(
+			cli.String("addr", "a").Default("127.0.0.1:8080").Help("Address to listen on"),
+			cli.Int("max-steps", "").Default(10_000_000).Help("Step limit per evaluation (0 for none)"),
+			cli.String("timeout", "").Default("5s").Help("Time limit per evaluation (0 for none)"),
+		).
+		Run(playgroundHandler)
+
+	// Jupyter kernel
+	kernel := app.Group("kernel").
+		Description("Run Risor as a Jupyter kernel")
+	kernel.Command("install").
+		Description("Register the Risor kernel with Jupyter").
+		Flags(
+			cli.String("dir", "").Help("Kernel spec directory (defaults to the user's Jupyter kernels directory)"),
+		).
+		Run(kernelInstallHandler)
+	kernel.Command("run").
+		Description("Start the kernel (invoked by Jupyter)").
+		Flags(
+			cli.String("connection-file", "f").Required().Help("Jupyter connection file"),
+		).
+		Run(kernelRunHandler)
+
+	// Execution traces
+	trace := app.Group("trace").
+		Description("Record and view execution traces")
+	trace.Command("run").
+		Description("Run a script and record an execution trace").
+		Args("file?").
+		Flags(
+			cli.String("trace", "t").Default("risor.trace").Help("Trace file to write"),
+			cli.String("mode", "m").Enum("step", "line", "call").Default("step").
+				Help("Record every instruction, one per source line, or only calls"),
+			cli.Int("limit", "n").Default(vm.DefaultTraceCapacity).Help("Keep only the most recent entries"),
+			cli.Int("stack", "").Default(2).Help("Stack values recorded with each instruction"),
+			cli.String("output", "o").Enum("json", "yaml", "text").Help("Output format"),
+			cli.Bool("raw", "r").Help("Print string results without quotes"),
+		).
+		Run(traceRunHandler)
+	trace.Command("replay").
+		Description("Print a recorded execution trace").
+		Args("file").
+		Flags(
+			cli.Int("tail", "n").Help("Show only the last n entries"),
+		).
+		Run(traceReplayHandler)
+
+	// Embedding scaffold generator
+	app.Command("init-embed").
+		Description("Generate Go code for embedding Risor in a service").
+		Args("dir?").
+		Flags(
+			cli.String("package", "p").Help("Go package name (defaults to the directory name)"),
+			cli.Bool("force", "").Help("Overwrite existing files"),
+		).
+		Run(initEmbedHandler)
+
 	if err := app.Execute(); err != nil {
 		if cli.IsHelpRequested(err) {
 			return
 		}
-		printError(err.Error())
+		// Scripts that call os.exit end with an exit error and no message
+		if msg := err.Error(); msg != "" {
+			printError(msg)
+		}
 		os.Exit(cli.GetExitCode(err))
 	}
 }
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	goerrors "errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/ast"
+	"github.com/deepnoodle-ai/risor/v2/pkg/compiler"
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+	"github.com/deepnoodle-ai/risor/v2/pkg/parser"
+	"github.com/deepnoodle-ai/risor/v2/pkg/vm"
+	"github.com/deepnoodle-ai/wonton/cli"
+)
+
+// maxRecordSize is the longest input line map accepts.
+const maxRecordSize = 64 * 1024 * 1024
+
+func mapHandler(ctx *cli.Context) error {
+	source, err := getEvalExpr(ctx)
+	if err != nil {
+		return err
+	}
+	env, err := getReplEnv(ctx)
+	if err != nil {
+		return err
+	}
+	m, err := newRecordMapper(ctx.Context(), source, env, ctx.Bool("json"))
+	if err != nil {
+		return formatRisorError(ctx, err)
+	}
+	err = m.run(ctx.Context(), os.Stdin, os.Stdout, ctx.String("output") == "json")
+	var recordErr *mapRecordError
+	if goerrors.As(err, &recordErr) {
+		// Report which input line failed along with the script error
+		var exitErr *object.ExitError
+		if goerrors.As(recordErr.err, &exitErr) {
+			return &cli.ExitError{Code: exitErr.Code}
+		}
+		return &cli.ExitError{
+			Code:    errorExitCode(ctx),
+			Message: fmt.Sprintf("input line %d: %s", recordErr.line, formatRisorError(ctx, recordErr.err)),
+		}
+	}
+	return err
+}
+
+// mapRecordError is an error raised by the expression for one input line.
+type mapRecordError struct {
+	line int // 1-based
+	err  error
+}
+
+func (e *mapRecordError) Error() string {
+	return fmt.Sprintf("input line %d: %s", e.line, e.err)
+}
+
+func (e *mapRecordError) Unwrap() error {
+	return e.err
+}
+
+// recordMapper evaluates an expression once per input record. The
+// expression is compiled once, as the body of a function that takes the
+// record and its index, and each record is a call of that function on the
+// same VM.
+type recordMapper struct {
+	machine *vm.VirtualMachine
+	fn      *object.Closure
+	json    bool
+}
+
+// newRecordMapper compiles source for map. The record is bound to line, or
+// with jsonInput to record, and its 0-based position to index.
+func newRecordMapper(ctx context.Context, source string, env map[string]any, jsonInput bool) (*recordMapper, error) {
+	program, err := parser.Parse(ctx, source, nil)
+	if err != nil {
+		return nil, err
+	}
+	name := "line"
+	if jsonInput {
+		name = "record"
+	}
+	fn := &ast.Func{
+		Params: []ast.FuncParam{&ast.Ident{Name: name}, &ast.Ident{Name: "index"}},
+		Body:   &ast.Block{Stmts: program.Stmts},
+	}
+	code, err := compiler.Compile(&ast.Program{Stmts: []ast.Node{fn}}, &compiler.Config{
+		GlobalNames: slices.Sorted(maps.Keys(env)),
+		Source:      source,
+	})
+	if err != nil {
+		return nil, err
+	}
+	machine, err := vm.New(code, vm.WithGlobals(env))
+	if err != nil {
+		return nil, err
+	}
+	if err := machine.Run(ctx); err != nil {
+		return nil, err
+	}
+	result, _ := machine.TOS()
+	closure, ok := result.(*object.Closure)
+	if !ok {
+		return nil, fmt.Errorf("map: expected a function, got %v", result)
+	}
+	return &recordMapper{machine: machine, fn: closure, json: jsonInput}, nil
+}
+
+// run evaluates the expression for each line of in and writes each result
+// to out on a line of its own. Null results are skipped, so the expression
+// can filter records. Strings are written as is unless jsonOutput is set;
+// other values are written as compact JSON.
+func (m *recordMapper) run(ctx context.Context, in io.Reader, out io.Writer, jsonOutput bool) error {
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64*1024), maxRecordSize)
+	index := 0
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		var record object.Object = object.NewString(text)
+		if m.json {
+			// Blank lines between NDJSON records are ignored
+			if strings.TrimSpace(text) == "" {
+				continue
+			}
+			var value any
+			if err := json.Unmarshal([]byte(text), &value); err != nil {
+				return fmt.Errorf("input line %d: invalid JSON: %w", line, err)
+			}
+			record = object.FromGoType(value)
+		}
+		result, err := m.machine.Call(ctx, m.fn, []object.Object{record, object.NewInt(int64(index))})
+		if err != nil {
+			return &mapRecordError{line: line, err: err}
+		}
+		index++
+		output, ok, err := mapOutput(result, jsonOutput)
+		if err != nil {
+			return fmt.Errorf("input line %d: %w", line, err)
+		}
+		if !ok {
+			continue
+		}
+		if _, err := fmt.Fprintln(out, output); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// mapOutput formats the result for one record, reporting false if there is
+// nothing to write.
+func mapOutput(result object.Object, jsonOutput bool) (string, bool, error) {
+	if result == nil || result == object.Nil {
+		return "", false, nil
+	}
+	if s, ok := result.(*object.String); ok && !jsonOutput {
+		return s.Value(), true, nil
+	}
+	data, err := marshalJSON(result.Interface(), "")
+	if err != nil {
+		return "", false, err
+	}
+	return string(data), true, nil
+}
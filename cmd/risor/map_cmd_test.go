@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/deepnoodle-ai/risor/v2"
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+func runMap(t *testing.T, source, input string, jsonInput, jsonOutput bool) (string, error) {
+	t.Helper()
+	ctx := context.Background()
+	m, err := newRecordMapper(ctx, source, risor.Builtins(), jsonInput)
+	assert.Nil(t, err)
+	var out bytes.Buffer
+	err = m.run(ctx, strings.NewReader(input), &out, jsonOutput)
+	return out.String(), err
+}
+
+func TestRecordMapper_Lines(t *testing.T) {
+	out, err := runMap(t, `line.to_upper()`, "a\nb\n", false, false)
+	assert.Nil(t, err)
+	assert.Equal(t, out, "A\nB\n")
+
+	// The last line needs no newline, and index counts from 0
+	out, err = runMap(t, `[index, line]`, "a\nb", false, false)
+	assert.Nil(t, err)
+	assert.Equal(t, out, "[0,\"a\"]\n[1,\"b\"]\n")
+}
+
+func TestRecordMapper_Filter(t *testing.T) {
+	out, err := runMap(t, `if (line.has_prefix("#")) { nil } else { line }`, "a\n# note\nb\n", false, false)
+	assert.Nil(t, err)
+	assert.Equal(t, out, "a\nb\n")
+}
+
+func TestRecordMapper_Statements(t *testing.T) {
+	out, err := runMap(t, `
+		let fields = line.split(",")
+		{name: fields[0], size: int(fields[1])}
+	`, "x,1\ny,2\n", false, false)
+	assert.Nil(t, err)
+	assert.Equal(t, out, "{\"name\":\"x\",\"size\":1}\n{\"name\":\"y\",\"size\":2}\n")
+}
+
+func TestRecordMapper_JSON(t *testing.T) {
+	input := "{\"name\": \"a\", \"tags\": [\"x\"]}\n\n{\"name\": \"b\", \"tags\": []}\n"
+	out, err := runMap(t, `record.name`, input, true, false)
+	assert.Nil(t, err)
+	assert.Equal(t, out, "a\nb\n")
+
+	// JSON output quotes strings so every line is a JSON value
+	out, err = runMap(t, `record.name`, input, true, true)
+	assert.Nil(t, err)
+	assert.Equal(t, out, "\"a\"\n\"b\"\n")
+
+	_, err = runMap(t, `record`, "{\"ok\": true}\nnot json\n", true, false)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "input line 2: invalid JSON")
+}
+
+func TestRecordMapper_Error(t *testing.T) {
+	out, err := runMap(t, `if (index == 1) { throw "bad record" }; line`, "a\nb\nc\n", false, false)
+	assert.Equal(t, out, "a\n")
+	var recordErr *mapRecordError
+	assert.True(t, errors.As(err, &recordErr))
+	assert.Equal(t, recordErr.line, 2)
+	assert.Contains(t, err.Error(), "bad record")
+}
+
+func TestRecordMapper_Exit(t *testing.T) {
+	ctx := context.Background()
+	env := risor.Builtins()
+	env["os"] = newScriptArgsEnv(nil)["os"]
+	m, err := newRecordMapper(ctx, `if (line == "stop") { os.exit(2) }; line`, env, false)
+	assert.Nil(t, err)
+	var out bytes.Buffer
+	err = m.run(ctx, strings.NewReader("a\nstop\nb\n"), &out, false)
+	assert.Equal(t, out.String(), "a\n")
+	var exitErr *object.ExitError
+	assert.True(t, errors.As(err, &exitErr))
+	assert.Equal(t, exitErr.Code, 2)
+}
+
+func TestRecordMapper_CompileError(t *testing.T) {
+	_, err := newRecordMapper(context.Background(), `undefined_name`, risor.Builtins(), false)
+	assert.NotNil(t, err)
+}
@@ -1,9 +1,13 @@
 package main
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"maps"
 	"os"
+	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/deepnoodle-ai/wonton/cli"
@@ -14,6 +18,11 @@ func formatOutput(ctx *cli.Context, result any) (string, error) {
 	format := strings.ToLower(ctx.String("output"))
 	noColor := ctx.Bool("no-color")
 
+	// --raw prints a string result as is, for use in shell pipelines
+	if s, ok := result.(string); ok && ctx.Bool("raw") {
+		return s, nil
+	}
+
 	switch format {
 	case "":
 		// Default: try JSON, fall back to string representation
@@ -31,6 +40,8 @@ func formatOutput(ctx *cli.Context, result any) (string, error) {
 			return "", err
 		}
 		return string(output), nil
+	case "yaml":
+		return formatYAML(result)
 	case "text":
 		return fmt.Sprintf("%v", result), nil
 	default:
@@ -38,8 +49,22 @@ func formatOutput(ctx *cli.Context, result any) (string, error) {
 	}
 }
 
+// marshalJSON encodes a result as JSON. Map keys are sorted and HTML
+// characters are left unescaped, so the same value always produces the
+// same text.
+func marshalJSON(result any, indent string) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", indent)
+	if err := enc.Encode(result); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
 func formatJSON(result any, noColor bool) ([]byte, error) {
-	data, err := json.MarshalIndent(result, "", "  ")
+	data, err := marshalJSON(result, "  ")
 	if err != nil {
 		return nil, err
 	}
@@ -52,6 +77,119 @@ func formatJSON(result any, noColor bool) ([]byte, error) {
 	return data, nil
 }
 
+// formatYAML encodes a result as YAML. The result is first encoded as JSON,
+// so YAML output accepts the same values and formats numbers the same way.
+func formatYAML(result any) (string, error) {
+	data, err := marshalJSON(result, "")
+	if err != nil {
+		return "", err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		return "", err
+	}
+	return strings.Join(yamlLines(value), "\n"), nil
+}
+
+// yamlLines renders a decoded JSON value as lines of block-style YAML.
+func yamlLines(value any) []string {
+	var lines []string
+	switch v := value.(type) {
+	case map[string]any:
+		if len(v) == 0 {
+			return []string{"{}"}
+		}
+		for _, key := range slices.Sorted(maps.Keys(v)) {
+			child := yamlLines(v[key])
+			if isYAMLBlock(v[key]) {
+				lines = append(lines, yamlString(key)+":")
+				lines = append(lines, indentLines(child, "  ", "  ")...)
+			} else {
+				lines = append(lines, yamlString(key)+": "+child[0])
+			}
+		}
+	case []any:
+		if len(v) == 0 {
+			return []string{"[]"}
+		}
+		for _, item := range v {
+			lines = append(lines, indentLines(yamlLines(item), "- ", "  ")...)
+		}
+	case string:
+		lines = []string{yamlString(v)}
+	case json.Number:
+		lines = []string{v.String()}
+	case bool:
+		lines = []string{strconv.FormatBool(v)}
+	default:
+		lines = []string{"null"}
+	}
+	return lines
+}
+
+// isYAMLBlock reports whether a value is written as an indented block
+// rather than on the line of its key.
+func isYAMLBlock(value any) bool {
+	switch v := value.(type) {
+	case map[string]any:
+		return len(v) > 0
+	case []any:
+		return len(v) > 0
+	}
+	return false
+}
+
+// indentLines prefixes the first line with first and the rest with rest.
+func indentLines(lines []string, first, rest string) []string {
+	out := make([]string, len(lines))
+	for i, line := range lines {
+		if i == 0 {
+			out[i] = first + line
+		} else {
+			out[i] = rest + line
+		}
+	}
+	return out
+}
+
+// yamlString writes s as a plain scalar when YAML would read it back as the
+// same string, and double-quoted otherwise.
+func yamlString(s string) string {
+	if isPlainYAML(s) {
+		return s
+	}
+	return strconv.Quote(s)
+}
+
+func isPlainYAML(s string) bool {
+	if s == "" || s != strings.TrimSpace(s) {
+		return false
+	}
+	// Words YAML reads as null or booleans
+	switch strings.ToLower(s) {
+	case "~", "null", "true", "false", "yes", "no", "on", "off", "y", "n":
+		return false
+	}
+	// Indicator characters, and anything that may read as a number or date
+	if strings.ContainsRune("-?:,[]{}#&*!|>'\"%@`.+0123456789", rune(s[0])) {
+		return false
+	}
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return false
+	}
+	if strings.HasSuffix(s, ":") || strings.Contains(s, ": ") || strings.Contains(s, " #") {
+		return false
+	}
+	for _, r := range s {
+		if r < 0x20 || r == 0x7f {
+			return false
+		}
+	}
+	return true
+}
+
 // colorizeJSON applies syntax highlighting to JSON output
 func colorizeJSON(data []byte) []byte {
 	s := string(data)
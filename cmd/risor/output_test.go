@@ -0,0 +1,62 @@
+package main
+
+import (
+	"math"
+	"testing"
+
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+func TestFormatYAML(t *testing.T) {
+	tests := []struct {
+		name   string
+		result any
+		want   string
+	}{
+		{"nil", nil, "null"},
+		{"bool", true, "true"},
+		{"int", int64(42), "42"},
+		{"float", 2.5, "2.5"},
+		{"string", "hello world", "hello world"},
+		{"empty string", "", `""`},
+		{"keyword", "yes", `"yes"`},
+		{"numeric string", "123", `"123"`},
+		{"indicator", "- item", `"- item"`},
+		{"colon", "key: value", `"key: value"`},
+		{"multiline", "a\nb", `"a\nb"`},
+		{"empty list", []any{}, "[]"},
+		{"empty map", map[string]any{}, "{}"},
+		{"list", []any{int64(1), "two"}, "- 1\n- two"},
+		{"nested list", []any{[]any{int64(1), int64(2)}}, "- - 1\n  - 2"},
+		{"map", map[string]any{"b": int64(2), "a": int64(1)}, "a: 1\nb: 2"},
+		{
+			"list of maps",
+			[]any{map[string]any{"name": "x", "tags": []any{"a"}}},
+			"- name: x\n  tags:\n    - a",
+		},
+		{
+			"nested map",
+			map[string]any{"outer": map[string]any{"inner": nil, "list": []any{}}},
+			"outer:\n  inner: null\n  list: []",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := formatYAML(tt.result)
+			assert.Nil(t, err)
+			assert.Equal(t, got, tt.want)
+		})
+	}
+}
+
+func TestFormatYAML_Unsupported(t *testing.T) {
+	_, err := formatYAML(math.Inf(1))
+	assert.NotNil(t, err)
+}
+
+func TestMarshalJSON_Stable(t *testing.T) {
+	result := map[string]any{"b": "<tag>", "a": []any{int64(1), "&"}}
+	data, err := marshalJSON(result, "")
+	assert.Nil(t, err)
+	assert.Equal(t, string(data), `{"a":[1,"&"],"b":"<tag>"}`)
+}
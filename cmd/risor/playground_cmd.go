@@ -0,0 +1,274 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	goerrors "errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/deepnoodle-ai/risor/v2"
+	"github.com/deepnoodle-ai/risor/v2/pkg/errors"
+	"github.com/deepnoodle-ai/wonton/cli"
+)
+
+// Limits on playground requests and output.
+const (
+	playgroundMaxSource = 1 << 20 // bytes of posted source
+	playgroundMaxOutput = 1 << 20 // bytes of captured print output
+)
+
+// playground evaluates posted scripts over HTTP. Each request runs in a
+// fresh VM with the standard library, a print builtin that writes to the
+// response, and the configured resource limits. The parallel and task
+// modules are left out, since they let one request use several CPUs.
+type playground struct {
+	maxSteps int64
+	timeout  time.Duration
+}
+
+// playgroundRequest is the body of POST /api/eval.
+type playgroundRequest struct {
+	Code string `json:"code"`
+}
+
+// playgroundResponse is returned by POST /api/eval. Error is nil when the
+// script succeeds.
+type playgroundResponse struct {
+	Result     any              `json:"result"`
+	Stdout     string           `json:"stdout"`
+	Truncated  bool             `json:"truncated,omitempty"`
+	Error      *playgroundError `json:"error"`
+	DurationMS float64          `json:"duration_ms"`
+}
+
+// playgroundError describes a parse, compile, or runtime error.
+type playgroundError struct {
+	Kind    string                 `json:"kind"`
+	Code    string                 `json:"code,omitempty"`
+	Message string                 `json:"message"`
+	Line    int                    `json:"line,omitempty"`
+	Column  int                    `json:"column,omitempty"`
+	Hint    string                 `json:"hint,omitempty"`
+	Stack   []playgroundStackFrame `json:"stack,omitempty"`
+	Text    string                 `json:"text"` // formatted as the CLI prints it
+}
+
+type playgroundStackFrame struct {
+	Function string `json:"function,omitempty"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+}
+
+func playgroundHandler(ctx *cli.Context) error {
+	timeout, err := time.ParseDuration(ctx.String("timeout"))
+	if err != nil {
+		return fmt.Errorf("invalid --timeout: %w", err)
+	}
+	p := &playground{
+		maxSteps: int64(ctx.Int("max-steps")),
+		timeout:  timeout,
+	}
+	listener, err := net.Listen("tcp", ctx.String("addr"))
+	if err != nil {
+		return err
+	}
+	server := &http.Server{
+		Handler:           p.routes(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Context().Done()
+		server.Close()
+	}()
+	fmt.Printf("Risor playground listening on http://%s\n", listener.Addr())
+	if err := server.Serve(listener); err != nil && !goerrors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func (p *playground) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(playgroundPage))
+	})
+	mux.HandleFunc("POST /api/eval", p.handleEval)
+	// Reject posts from other sites, so a page the user visits cannot run
+	// scripts through a playground listening on localhost
+	return http.NewCrossOriginProtection().Handler(mux)
+}
+
+func (p *playground) handleEval(w http.ResponseWriter, r *http.Request) {
+	// Requiring JSON also keeps cross-site HTML forms, which cannot send
+	// it, from posting scripts
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType != "application/json" {
+		http.Error(w, "invalid request: content type must be application/json", http.StatusUnsupportedMediaType)
+		return
+	}
+	var req playgroundRequest
+	body := http.MaxBytesReader(w, r.Body, playgroundMaxSource)
+	if err := json.NewDecoder(body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("invalid request: %v", err), http.StatusBadRequest)
+		return
+	}
+	resp := p.eval(r.Context(), req.Code)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// eval runs the source and reports its result, output, and error.
+func (p *playground) eval(ctx context.Context, source string) *playgroundResponse {
+	// The page has one output panel, so stderr is shown along with stdout
+	stdout := &limitedBuffer{max: playgroundMaxOutput}
+	output := func() io.Writer { return stdout }
+	opts := []risor.Option{
+		risor.WithEnv(playgroundGlobals()),
+		risor.WithEnv(newOutputBuiltins(output, output)),
+		risor.WithFilename("playground.risor"),
+	}
+	if p.maxSteps > 0 {
+		opts = append(opts, risor.WithMaxSteps(p.maxSteps))
+	}
+	if p.timeout > 0 {
+		opts = append(opts, risor.WithTimeout(p.timeout))
+	}
+
+	start := time.Now()
+	result, err := risor.Eval(ctx, source, opts...)
+	resp := &playgroundResponse{
+		Stdout:     stdout.buf.String(),
+		Truncated:  stdout.truncated,
+		DurationMS: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		resp.Error = newPlaygroundError(err)
+		return resp
+	}
+	// Results that cannot be represented as JSON are returned as text
+	if _, jsonErr := json.Marshal(result); jsonErr != nil {
+		result = fmt.Sprintf("%v", result)
+	}
+	resp.Result = result
+	return resp
+}
+
+// playgroundGlobals returns the default globals without the modules that
+// run work on other goroutines.
+func playgroundGlobals() map[string]any {
+	env := defaultGlobals()
+	delete(env, "parallel")
+	delete(env, "task")
+	return env
+}
+
+// newPlaygroundError converts an error to its JSON form. For parse errors
+// with several causes, the fields describe the first one.
+func newPlaygroundError(err error) *playgroundError {
+	pe := &playgroundError{
+		Kind:    "error",
+		Message: err.Error(),
+		Text:    formatError(err, false).Error(),
+	}
+	var formatted *errors.FormattedError
+	if multi, ok := err.(interface {
+		ToFormattedMultiple() []*errors.FormattedError
+	}); ok {
+		if all := multi.ToFormattedMultiple(); len(all) > 0 {
+			formatted = all[0]
+		}
+	} else if f, ok := err.(errors.FormattableError); ok {
+		formatted = f.ToFormatted()
+	}
+	if formatted == nil {
+		return pe
+	}
+	pe.Kind = formatted.Kind
+	pe.Code = string(formatted.Code)
+	pe.Message = formatted.Message
+	pe.Line = formatted.Line
+	pe.Column = formatted.Column
+	pe.Hint = formatted.Hint
+	for _, frame := range formatted.Stack {
+		pe.Stack = append(pe.Stack, playgroundStackFrame{
+			Function: frame.Function,
+			Line:     frame.Location.Line,
+			Column:   frame.Location.Column,
+		})
+	}
+	return pe
+}
+
+// limitedBuffer keeps the first max bytes written to it and discards the
+// rest, so a script that prints in a loop cannot exhaust memory.
+type limitedBuffer struct {
+	buf       bytes.Buffer
+	max       int
+	truncated bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := b.max - b.buf.Len(); len(p) > room {
+		b.buf.Write(p[:max(room, 0)])
+		b.truncated = true
+		return len(p), nil
+	}
+	return b.buf.Write(p)
+}
+
+const playgroundPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Risor Playground</title>
+<style>
+  body { font-family: system-ui, sans-serif; margin: 2rem; max-width: 60rem; }
+  textarea, pre { font-family: ui-monospace, monospace; font-size: 14px; width: 100%; box-sizing: border-box; }
+  textarea { height: 20rem; padding: 0.5rem; }
+  pre { background: #f4f4f4; padding: 0.5rem; min-height: 2rem; white-space: pre-wrap; }
+  .error { color: #b00020; }
+</style>
+</head>
+<body>
+<h1>Risor Playground</h1>
+<textarea id="code" spellcheck="false">let items = [1, 2, 3, 4, 5]
+print("sum:", items.reduce(0, (acc, x) => acc + x))
+items.filter(x => x % 2 == 1).map(x => x * x)</textarea>
+<p><button id="run">Run</button> <small>Ctrl+Enter</small> <span id="time"></span></p>
+<h3>Output</h3>
+<pre id="stdout"></pre>
+<h3>Result</h3>
+<pre id="result"></pre>
+<script>
+const $ = id => document.getElementById(id);
+async function run() {
+  const resp = await fetch("/api/eval", {
+    method: "POST",
+    headers: {"Content-Type": "application/json"},
+    body: JSON.stringify({code: $("code").value}),
+  });
+  const data = await resp.json();
+  $("stdout").textContent = data.stdout + (data.truncated ? "\n[output truncated]" : "");
+  $("time").textContent = data.duration_ms.toFixed(2) + " ms";
+  if (data.error) {
+    $("result").className = "error";
+    $("result").textContent = data.error.text;
+  } else {
+    $("result").className = "";
+    $("result").textContent = JSON.stringify(data.result, null, 2);
+  }
+}
+$("run").onclick = run;
+$("code").addEventListener("keydown", e => {
+  if (e.key === "Enter" && (e.ctrlKey || e.metaKey)) { e.preventDefault(); run(); }
+});
+</script>
+</body>
+</html>
+`
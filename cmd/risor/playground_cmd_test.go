@@ -0,0 +1,121 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+func postEval(t *testing.T, p *playground, body string) (*http.Response, *playgroundResponse) {
+	t.Helper()
+	server := httptest.NewServer(p.routes())
+	defer server.Close()
+	resp, err := http.Post(server.URL+"/api/eval", "application/json", strings.NewReader(body))
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return resp, nil
+	}
+	var result playgroundResponse
+	assert.Nil(t, json.NewDecoder(resp.Body).Decode(&result))
+	return resp, &result
+}
+
+func TestPlaygroundEval(t *testing.T) {
+	p := &playground{maxSteps: 100_000, timeout: time.Second}
+	_, resp := postEval(t, p, `{"code": "print(\"hi\", 1)\n[1, 2].map(x => x * 2)"}`)
+	assert.Nil(t, resp.Error)
+	assert.Equal(t, resp.Stdout, "hi 1\n")
+	assert.Equal(t, resp.Result, []any{float64(2), float64(4)})
+}
+
+func TestPlaygroundErrors(t *testing.T) {
+	p := &playground{maxSteps: 100_000, timeout: time.Second}
+
+	_, resp := postEval(t, p, `{"code": "let x = "}`)
+	assert.NotNil(t, resp.Error)
+	assert.Equal(t, resp.Error.Kind, "parse error")
+	assert.Equal(t, resp.Error.Line, 1)
+	assert.Contains(t, resp.Error.Text, "playground.risor:1:7")
+
+	_, resp = postEval(t, p, `{"code": "print(\"before\")\nmissing"}`)
+	assert.NotNil(t, resp.Error)
+	assert.Equal(t, resp.Error.Code, "E2001")
+	assert.Equal(t, resp.Stdout, "")
+
+	_, resp = postEval(t, p, `{"code": "print(\"before\")\nthrow \"boom\""}`)
+	assert.NotNil(t, resp.Error)
+	assert.Equal(t, resp.Error.Message, "boom")
+	assert.Equal(t, resp.Stdout, "before\n")
+}
+
+func TestPlaygroundStepLimit(t *testing.T) {
+	p := &playground{maxSteps: 1000}
+	_, resp := postEval(t, p, `{"code": "range(1000000).each(x => x)"}`)
+	assert.NotNil(t, resp.Error)
+	assert.Contains(t, resp.Error.Message, "step limit")
+}
+
+func TestPlaygroundOutputLimit(t *testing.T) {
+	p := &playground{maxSteps: 10_000_000}
+	_, resp := postEval(t, p, `{"code": "let s = \"x\".repeat(1000)\nrange(2000).each(i => print(s))"}`)
+	assert.Nil(t, resp.Error)
+	assert.True(t, resp.Truncated)
+	assert.Equal(t, len(resp.Stdout), playgroundMaxOutput)
+}
+
+func TestPlaygroundBadRequest(t *testing.T) {
+	resp, _ := postEval(t, &playground{}, `not json`)
+	assert.Equal(t, resp.StatusCode, http.StatusBadRequest)
+}
+
+func TestPlaygroundRejectsCrossSiteRequests(t *testing.T) {
+	server := httptest.NewServer((&playground{}).routes())
+	defer server.Close()
+	post := func(contentType string, headers map[string]string) int {
+		req, err := http.NewRequest("POST", server.URL+"/api/eval", strings.NewReader(`{"code": "1"}`))
+		assert.Nil(t, err)
+		req.Header.Set("Content-Type", contentType)
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		assert.Nil(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, post("application/json", nil), http.StatusOK)
+	assert.Equal(t, post("application/json; charset=utf-8", nil), http.StatusOK)
+	assert.Equal(t, post("application/json", map[string]string{"Sec-Fetch-Site": "same-origin"}), http.StatusOK)
+	assert.Equal(t, post("application/json", map[string]string{"Origin": server.URL}), http.StatusOK)
+
+	// Forms and requests from other sites are rejected
+	assert.Equal(t, post("text/plain", nil), http.StatusUnsupportedMediaType)
+	assert.Equal(t, post("application/x-www-form-urlencoded", nil), http.StatusUnsupportedMediaType)
+	assert.Equal(t, post("application/json", map[string]string{"Sec-Fetch-Site": "cross-site"}), http.StatusForbidden)
+	assert.Equal(t, post("application/json", map[string]string{"Origin": "https://example.com"}), http.StatusForbidden)
+}
+
+func TestPlaygroundOmitsConcurrencyModules(t *testing.T) {
+	p := &playground{maxSteps: 100_000, timeout: time.Second}
+	for _, name := range []string{"parallel", "task"} {
+		_, resp := postEval(t, p, `{"code": "`+name+`"}`)
+		assert.NotNil(t, resp.Error, name)
+	}
+}
+
+func TestPlaygroundPage(t *testing.T) {
+	server := httptest.NewServer((&playground{}).routes())
+	defer server.Close()
+	resp, err := http.Get(server.URL + "/")
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, resp.StatusCode, http.StatusOK)
+	assert.Contains(t, resp.Header.Get("Content-Type"), "text/html")
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -10,6 +11,7 @@ import (
 	"unicode"
 
 	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+	"github.com/deepnoodle-ai/wonton/cli"
 	"github.com/deepnoodle-ai/wonton/tui"
 )
 
@@ -24,7 +26,8 @@ type replApp struct {
 	historyIdx  int
 	historyPath string
 	showTiming  bool
-	multiLine   bool // true when input contains newlines
+	multiLine   bool  // true when input contains newlines
+	exitErr     error // set when the script calls os.exit
 }
 
 func runRepl(ctx context.Context, env map[string]any) error {
@@ -53,7 +56,10 @@ func runRepl(ctx context.Context, env map[string]any) error {
 	// Print branded header
 	app.runner.Print(app.headerView())
 
-	return app.runner.Run(app)
+	if err := app.runner.Run(app); err != nil {
+		return err
+	}
+	return app.exitErr
 }
 
 // headerView returns the branded REPL header with gradient logo
@@ -413,6 +419,13 @@ func (app *replApp) submit() []tui.Cmd {
 	app.history = append(app.history, input)
 	appendToHistory(app.historyPath, input)
 
+	// os.exit ends the REPL with the requested status
+	var exitErr *object.ExitError
+	if errors.As(err, &exitErr) {
+		app.exitErr = &cli.ExitError{Code: exitErr.Code}
+		return []tui.Cmd{tui.Quit()}
+	}
+
 	// Print result
 	if err != nil {
 		app.runner.Print(tui.Text("%s", err.Error()).Fg(tui.ColorRed).Wrap())
@@ -6,18 +6,34 @@ import (
 	goerrors "errors"
 	"fmt"
 	"io"
+	"maps"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"runtime/pprof"
 	"strings"
 	"syscall"
 	"time"
 
 	"github.com/deepnoodle-ai/risor/v2"
+	"github.com/deepnoodle-ai/risor/v2/pkg/builtins"
 	"github.com/deepnoodle-ai/risor/v2/pkg/errors"
+	"github.com/deepnoodle-ai/risor/v2/pkg/modules/flags"
+	"github.com/deepnoodle-ai/risor/v2/pkg/modules/net"
+	modOS "github.com/deepnoodle-ai/risor/v2/pkg/modules/os"
+	"github.com/deepnoodle-ai/risor/v2/pkg/modules/prompt"
+	"github.com/deepnoodle-ai/risor/v2/pkg/modules/proto"
+	"github.com/deepnoodle-ai/risor/v2/pkg/modules/render"
+	"github.com/deepnoodle-ai/risor/v2/pkg/modules/sqlite"
+	"github.com/deepnoodle-ai/risor/v2/pkg/modules/store"
+	"github.com/deepnoodle-ai/risor/v2/pkg/modules/store/bolt"
+	"github.com/deepnoodle-ai/risor/v2/pkg/modules/term"
+	"github.com/deepnoodle-ai/risor/v2/pkg/modules/unicode"
 	"github.com/deepnoodle-ai/risor/v2/pkg/object"
 	"github.com/deepnoodle-ai/wonton/cli"
 	"github.com/deepnoodle-ai/wonton/color"
+	"github.com/deepnoodle-ai/wonton/tui"
+	xterm "golang.org/x/term"
 )
 
 func runHandler(ctx *cli.Context) error {
@@ -58,10 +74,11 @@ func runHandler(ctx *cli.Context) error {
 	if file := ctx.Arg(0); file != "" {
 		opts = append(opts, risor.WithFilename(file))
 	}
+	opts = append(opts, risor.WithEnv(newScriptArgsEnv(ctx.Args())))
 
 	result, err := risor.Eval(ctx.Context(), code, opts...)
 	if err != nil {
-		return formatRisorError(ctx, err)
+		return scriptExitError(ctx, err)
 	}
 	dt := time.Since(start)
 
@@ -100,15 +117,25 @@ func versionHandler(ctx *cli.Context) error {
 	return nil
 }
 
+// defaultGlobals returns the globals scripts have unless the
+// --no-default-globals flag is given: the builtins and default modules
+// along with the optional standard modules, which the CLI always offers.
+func defaultGlobals() map[string]any {
+	env := risor.Builtins()
+	maps.Copy(env, risor.StandardModules())
+	env["proto"] = proto.Module()
+	env["unicode"] = unicode.Module()
+	return env
+}
+
 func getRisorOptions(ctx *cli.Context, injectStdin bool) ([]risor.Option, error) {
 	var opts []risor.Option
 	if !ctx.Bool("no-default-globals") {
-		opts = append(opts, risor.WithEnv(risor.Builtins()))
+		opts = append(opts, risor.WithEnv(defaultGlobals()))
 	}
-	// Provide print in CLI mode (not available in library mode by design)
-	opts = append(opts, risor.WithEnv(map[string]any{
-		"print": newPrintBuiltin(),
-	}))
+	// Provide print and its variants in CLI mode (not available in library
+	// mode by design)
+	opts = append(opts, risor.WithEnv(newOutputBuiltins(stdoutWriter, stderrWriter)))
 	// Auto-inject stdin as a variable when data is piped and stdin isn't
 	// being used to read code (via --stdin flag).
 	if injectStdin && !ctx.Bool("stdin") && cli.IsPiped() {
@@ -192,7 +219,7 @@ func parseVarFlags(flags []string) (map[string]any, error) {
 func getReplEnv(ctx *cli.Context) (map[string]any, error) {
 	var env map[string]any
 	if !ctx.Bool("no-default-globals") {
-		env = risor.Builtins()
+		env = defaultGlobals()
 	}
 	mergeInto := func(vars map[string]any) {
 		if env == nil {
@@ -202,7 +229,8 @@ func getReplEnv(ctx *cli.Context) (map[string]any, error) {
 			env[k] = v
 		}
 	}
-	mergeInto(map[string]any{"print": newPrintBuiltin()})
+	mergeInto(newOutputBuiltins(stdoutWriter, stderrWriter))
+	mergeInto(newScriptArgsEnv(nil))
 	if vars, err := parseVarFlags(ctx.Strings("var")); err != nil {
 		return nil, err
 	} else if len(vars) > 0 {
@@ -289,6 +317,12 @@ func handleSigForProfiler() {
 // formatRisorError formats a Risor error with colors and professional styling.
 func formatRisorError(ctx *cli.Context, err error) error {
 	useColor := !ctx.Bool("no-color") && color.ShouldColorize(os.Stderr)
+	return formatError(err, useColor)
+}
+
+// formatError renders parse, compile, and runtime errors with source
+// context. Other errors are returned unchanged.
+func formatError(err error, useColor bool) error {
 	formatter := errors.NewFormatter(useColor)
 
 	// Check for multi-error types (parser errors with multiple errors)
@@ -308,13 +342,158 @@ func formatRisorError(ctx *cli.Context, err error) error {
 	return err
 }
 
+// scriptExitError returns the error that ends the CLI when a script fails.
+// A script that calls os.exit exits with its status and prints nothing.
+// Other errors are printed and exit with the --error-exit-code status.
+func scriptExitError(ctx *cli.Context, err error) error {
+	var exitErr *object.ExitError
+	if goerrors.As(err, &exitErr) {
+		return &cli.ExitError{Code: exitErr.Code}
+	}
+	return &cli.ExitError{Code: errorExitCode(ctx), Message: formatRisorError(ctx, err).Error()}
+}
+
+// errorExitCode returns the exit status for a script that fails with an
+// error.
+func errorExitCode(ctx *cli.Context) int {
+	if ctx.IsSet("error-exit-code") {
+		return ctx.Int("error-exit-code")
+	}
+	return 1
+}
+
+// newScriptArgsEnv returns the globals that give a script access to its
+// command line: os.args holds the script path followed by its arguments,
+// os.exit ends the script with an exit status, and the flags module parses
+// the arguments after the path. Arguments meant for the script that look
+// like flags must follow "--" so the CLI does not interpret them. Scripts
+// run from the CLI may also use the network through the net module, ask the
+// user questions through the prompt module, draw on the terminal through
+// the render and term modules when stdout is one, and keep data between
+// runs through the store and sqlite modules.
+func newScriptArgsEnv(args []string) map[string]any {
+	var scriptArgs []string
+	if len(args) > 0 {
+		scriptArgs = args[1:]
+	}
+	return map[string]any{
+		"os":     modOS.Module(args),
+		"flags":  flags.Module(scriptArgs),
+		"net":    net.Module(net.WithNetworkAccess()),
+		"prompt": newPromptModule(),
+		"render": newRenderModule(),
+		"term":   newTermModule(),
+		"store":  store.Module(bolt.New(scriptDir(args))),
+		"sqlite": sqlite.Module(sqlite.WithDir(scriptDir(args))),
+	}
+}
+
+// scriptDir returns the directory that database names given to the store
+// and sqlite modules are relative to: the directory of the script, or the
+// working directory when the code does not come from a file.
+func scriptDir(args []string) string {
+	if len(args) > 0 && args[0] != "" {
+		return filepath.Dir(args[0])
+	}
+	return "."
+}
+
+// newPromptModule returns the prompt module, enabled only if stdin is a
+// terminal; piped input is given to the script as the stdin global instead.
+// Questions go to stderr so they stay out of the script's output.
+func newPromptModule() *object.Module {
+	if !color.IsTerminal(os.Stdin) {
+		return prompt.Module()
+	}
+	return prompt.Module(
+		prompt.WithInput(os.Stdin, os.Stderr),
+		prompt.WithPasswordReader(func() (string, error) {
+			password, err := xterm.ReadPassword(int(os.Stdin.Fd()))
+			return string(password), err
+		}),
+	)
+}
+
+// newRenderModule returns the render module, styled like the CLI's own
+// output if stdout is a terminal that accepts color.
+func newRenderModule() *object.Module {
+	if !color.ShouldColorize(os.Stdout) {
+		return render.Module()
+	}
+	return render.Module(
+		render.WithColor(true),
+		render.WithMarkdown(func(source string) (string, error) {
+			return strings.TrimRight(tui.Sprint(tui.Markdown(source, nil)), " \n"), nil
+		}),
+	)
+}
+
+// newTermModule returns the term module, enabled only if stdout is a
+// terminal. Colors follow the NO_COLOR convention.
+func newTermModule() *object.Module {
+	if !color.IsTerminal(os.Stdout) {
+		return term.Module()
+	}
+	return term.Module(
+		term.WithTerminal(os.Stdout),
+		term.WithColor(color.ShouldColorize(os.Stdout)),
+		term.WithSize(func() (int, int, bool) {
+			width, height, err := xterm.GetSize(int(os.Stdout.Fd()))
+			return width, height, err == nil
+		}),
+	)
+}
+
 func newPrintBuiltin() *object.Builtin {
-	return object.NewBuiltin("print", func(ctx context.Context, args ...object.Object) (object.Object, error) {
-		values := make([]any, len(args))
-		for i, arg := range args {
-			values[i] = object.PrintableValue(arg)
+	return newLineBuiltin("print", stdoutWriter)
+}
+
+// newOutputBuiltins returns print and printf, which write to stdout, and
+// eprint and eprintf, which write to stderr so that diagnostics stay out of
+// piped output. The writers are looked up on each call.
+func newOutputBuiltins(stdout, stderr func() io.Writer) map[string]any {
+	return map[string]any{
+		"print":   newLineBuiltin("print", stdout),
+		"printf":  newPrintfBuiltin("printf", stdout),
+		"eprint":  newLineBuiltin("eprint", stderr),
+		"eprintf": newPrintfBuiltin("eprintf", stderr),
+	}
+}
+
+func stdoutWriter() io.Writer { return os.Stdout }
+
+func stderrWriter() io.Writer { return os.Stderr }
+
+// newLineBuiltin returns a builtin that writes its arguments to w as a line,
+// separated by spaces.
+func newLineBuiltin(name string, w func() io.Writer) *object.Builtin {
+	return object.NewBuiltin(name, func(ctx context.Context, args ...object.Object) (object.Object, error) {
+		fmt.Fprintln(w(), printableValues(args)...)
+		return object.Nil, nil
+	})
+}
+
+// newPrintfBuiltin returns a builtin that formats its arguments as sprintf
+// does and writes the result to w without adding a newline.
+func newPrintfBuiltin(name string, w func() io.Writer) *object.Builtin {
+	return object.NewBuiltin(name, func(ctx context.Context, args ...object.Object) (object.Object, error) {
+		if len(args) < 1 {
+			return nil, object.NewArgsRangeError(name, 1, 64, len(args))
 		}
-		fmt.Println(values...)
+		text, err := builtins.Sprintf(ctx, args...)
+		if err != nil {
+			return nil, err
+		}
+		io.WriteString(w(), text.(*object.String).Value())
 		return object.Nil, nil
 	})
 }
+
+// printableValues converts print arguments to the values written for them.
+func printableValues(args []object.Object) []any {
+	values := make([]any, len(args))
+	for i, arg := range args {
+		values[i] = object.PrintableValue(arg)
+	}
+	return values
+}
@@ -1,7 +1,9 @@
 package main
 
 import (
+	"encoding/json"
 	"os"
+	"strings"
 
 	"github.com/deepnoodle-ai/risor/v2/pkg/testing"
 	"github.com/deepnoodle-ai/wonton/cli"
@@ -25,6 +27,15 @@ func testHandler(ctx *cli.Context) error {
 		return err
 	}
 
+	if ctx.String("output") == "json" {
+		// Files that fail to compile are reported as errors, so a CI run
+		// fails on them too
+		if errs := printTestResultsJSON(summary); errs > 0 || !summary.Success() {
+			os.Exit(1)
+		}
+		return nil
+	}
+
 	// Configure output
 	useColor := !ctx.Bool("no-color") && color.ShouldColorize(os.Stdout)
 	output := testing.NewOutput(testing.OutputConfig{
@@ -43,3 +54,66 @@ func testHandler(ctx *cli.Context) error {
 
 	return nil
 }
+
+// testCaseJSON is the outcome of one test function in the JSON output.
+type testCaseJSON struct {
+	File       string  `json:"file"`
+	Name       string  `json:"name"`
+	Status     string  `json:"status"`
+	DurationMS float64 `json:"duration_ms"`
+}
+
+// printTestResultsJSON writes each test's outcome along with diagnostics for
+// failed assertions, test errors, and files that did not compile. It returns
+// the number of error diagnostics.
+func printTestResultsJSON(summary *testing.Summary) int {
+	tests := []testCaseJSON{}
+	var diags []Diagnostic
+	for _, file := range summary.Files {
+		if file.CompileErr != nil {
+			diags = append(diags, errorDiagnostics(file.Filename, "compile-error", file.CompileErr)...)
+		}
+		for _, test := range file.Tests {
+			tests = append(tests, testCaseJSON{
+				File:       file.Filename,
+				Name:       test.Name,
+				Status:     strings.ToLower(test.Status.String()),
+				DurationMS: float64(test.Duration.Microseconds()) / 1000,
+			})
+			for _, failure := range test.Failures {
+				diags = append(diags, Diagnostic{
+					File:     file.Filename,
+					Line:     max(failure.Line, 1),
+					Column:   1,
+					Rule:     "test-failure",
+					Severity: "error",
+					Message:  test.Name + ": " + failure.Message,
+				})
+			}
+			if test.Error != nil {
+				for _, d := range errorDiagnostics(file.Filename, "test-error", test.Error) {
+					d.Message = test.Name + ": " + d.Message
+					diags = append(diags, d)
+				}
+			}
+		}
+	}
+	result := struct {
+		Tests   []testCaseJSON `json:"tests"`
+		Passed  int            `json:"passed"`
+		Failed  int            `json:"failed"`
+		Skipped int            `json:"skipped"`
+		diagnosticReport
+	}{
+		Tests:            tests,
+		Passed:           summary.Passed,
+		Failed:           summary.Failed + summary.Errors,
+		Skipped:          summary.Skipped,
+		diagnosticReport: newDiagnosticReport(diags),
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	enc.Encode(result)
+	return result.Errors
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/deepnoodle-ai/risor/v2"
+	"github.com/deepnoodle-ai/risor/v2/pkg/vm"
+	"github.com/deepnoodle-ai/wonton/cli"
+)
+
+// traceModes maps the --mode flag of "trace run" to the instructions the
+// recorder captures.
+var traceModes = map[string]vm.StepMode{
+	"step": vm.StepAll,
+	"line": vm.StepOnLine,
+	"call": vm.StepNone,
+}
+
+func traceRunHandler(ctx *cli.Context) error {
+	opts, err := getRisorOptions(ctx, true)
+	if err != nil {
+		return err
+	}
+	code, err := getRisorCode(ctx)
+	if err != nil {
+		return err
+	}
+	if file := ctx.Arg(0); file != "" {
+		opts = append(opts, risor.WithFilename(file))
+	}
+	opts = append(opts, risor.WithEnv(newScriptArgsEnv(ctx.Args())))
+
+	rec := vm.NewTraceRecorder(vm.TraceConfig{
+		StepMode:      traceModes[ctx.String("mode")],
+		StackSnapshot: ctx.Int("stack"),
+		Capacity:      ctx.Int("limit"),
+	})
+	result, runErr := risor.Eval(ctx.Context(), code, append(opts, risor.WithObserver(rec))...)
+
+	// The trace is written even when the script fails, since that is when
+	// it is most useful
+	path := ctx.String("trace")
+	if err := writeTraceFile(path, rec); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "trace: %d entries written to %s", len(rec.Entries()), path)
+	if dropped := rec.Dropped(); dropped > 0 {
+		fmt.Fprintf(os.Stderr, " (%d earlier entries dropped)", dropped)
+	}
+	fmt.Fprintln(os.Stderr)
+
+	if runErr != nil {
+		return scriptExitError(ctx, runErr)
+	}
+	output, err := formatOutput(ctx, result)
+	if err != nil {
+		return err
+	}
+	if output != "" {
+		fmt.Println(output)
+	}
+	return nil
+}
+
+func writeTraceFile(path string, rec *vm.TraceRecorder) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err := rec.WriteTo(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func traceReplayHandler(ctx *cli.Context) error {
+	f, err := os.Open(ctx.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	entries, err := vm.ReadTrace(f)
+	if err != nil {
+		return err
+	}
+	if tail := ctx.Int("tail"); tail > 0 && tail < len(entries) {
+		entries = entries[len(entries)-tail:]
+	}
+	printTrace(os.Stdout, entries, newSourceCache())
+	return nil
+}
+
+// printTrace writes a readable listing of trace entries. Each entry is
+// indented by its call depth, and the source line is shown whenever the
+// trace moves to a new line of a file that can be read.
+func printTrace(w io.Writer, entries []vm.TraceEntry, sources *sourceCache) {
+	var lastFile string
+	var lastLine int
+	for _, e := range entries {
+		if e.Line > 0 && (e.File != lastFile || e.Line != lastLine) {
+			lastFile, lastLine = e.File, e.Line
+			if src := sources.line(e.File, e.Line); src != "" {
+				fmt.Fprintf(w, "%s%s:%d | %s\n", traceIndent(e), e.File, e.Line,
+					strings.TrimSpace(src))
+			}
+		}
+		fmt.Fprintf(w, "#%-6d %s%s\n", e.Seq, traceIndent(e), describeTraceEntry(e))
+	}
+}
+
+func describeTraceEntry(e vm.TraceEntry) string {
+	switch e.Kind {
+	case "call":
+		name := e.Function
+		if name == "" {
+			name = "<anonymous>"
+		}
+		return fmt.Sprintf("-> call %s (%d args)", name, e.ArgCount)
+	case "return":
+		name := e.Function
+		if name == "" {
+			name = "<anonymous>"
+		}
+		return "<- return " + name
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%4d %s", e.IP, e.Opcode)
+	for _, operand := range e.Operands {
+		fmt.Fprintf(&b, " %d", operand)
+	}
+	if len(e.Stack) > 0 {
+		fmt.Fprintf(&b, "  stack: [%s]", strings.Join(e.Stack, ", "))
+	}
+	return b.String()
+}
+
+func traceIndent(e vm.TraceEntry) string {
+	return strings.Repeat("  ", max(e.FrameDepth-1, 0))
+}
+
+// sourceCache reads the source files referenced by a trace, once each.
+type sourceCache struct {
+	files map[string][]string
+}
+
+func newSourceCache() *sourceCache {
+	return &sourceCache{files: map[string][]string{}}
+}
+
+// line returns a 1-based line of a file, or "" if the file can't be read.
+func (c *sourceCache) line(file string, n int) string {
+	lines, ok := c.files[file]
+	if !ok {
+		if file != "" {
+			if data, err := os.ReadFile(file); err == nil {
+				lines = strings.Split(string(data), "\n")
+			}
+		}
+		c.files[file] = lines
+	}
+	if n < 1 || n > len(lines) {
+		return ""
+	}
+	return lines[n-1]
+}
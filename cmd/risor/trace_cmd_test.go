@@ -0,0 +1,49 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/deepnoodle-ai/risor/v2"
+	"github.com/deepnoodle-ai/risor/v2/pkg/vm"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+func TestTraceFileRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	script := filepath.Join(dir, "add.risor")
+	source := "function add(a, b) {\n  a + b\n}\nadd(1, 2)\n"
+	assert.Nil(t, os.WriteFile(script, []byte(source), 0o644))
+
+	rec := vm.NewTraceRecorder(vm.TraceConfig{StepMode: vm.StepOnLine})
+	_, err := risor.Eval(context.Background(), source,
+		risor.WithFilename(script), risor.WithObserver(rec))
+	assert.Nil(t, err)
+
+	path := filepath.Join(dir, "risor.trace")
+	assert.Nil(t, writeTraceFile(path, rec))
+	f, err := os.Open(path)
+	assert.Nil(t, err)
+	defer f.Close()
+	entries, err := vm.ReadTrace(f)
+	assert.Nil(t, err)
+	assert.Equal(t, len(entries), len(rec.Entries()))
+
+	var out bytes.Buffer
+	printTrace(&out, entries, newSourceCache())
+	listing := out.String()
+	assert.Contains(t, listing, script+":4 | add(1, 2)")
+	assert.Contains(t, listing, "-> call add (2 args)")
+	assert.Contains(t, listing, "  "+script+":2 | a + b")
+	assert.Contains(t, listing, "<- return add")
+}
+
+func TestDescribeTraceEntry(t *testing.T) {
+	step := vm.TraceEntry{Kind: "step", IP: 4, Opcode: "BINARY_OP", Operands: []int{1}, Stack: []string{"2", "40"}}
+	assert.Equal(t, describeTraceEntry(step), "   4 BINARY_OP 1  stack: [2, 40]")
+	assert.Equal(t, describeTraceEntry(vm.TraceEntry{Kind: "call", ArgCount: 1}), "-> call <anonymous> (1 args)")
+	assert.Equal(t, describeTraceEntry(vm.TraceEntry{Kind: "return", Function: "f"}), "<- return f")
+}
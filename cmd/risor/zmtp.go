@@ -0,0 +1,379 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+)
+
+// This file implements the subset of ZMTP 3.0, the ZeroMQ wire protocol
+// (https://rfc.zeromq.org/spec/23/), that a Jupyter kernel needs: TCP
+// transport, the NULL security mechanism, and ROUTER, PUB, and heartbeat
+// sockets. Jupyter clients connect with libzmq, so speaking the protocol
+// directly avoids a cgo dependency on libzmq in the CLI.
+
+const (
+	zmtpFlagMore    = 0x01
+	zmtpFlagLong    = 0x02
+	zmtpFlagCommand = 0x04
+
+	zmtpGreetingSize = 64
+	zmtpMaxFrameSize = 64 << 20
+)
+
+// Socket types supported by listenZMTP.
+const (
+	zmtpRouter = "ROUTER" // messages are prefixed with the peer's routing id
+	zmtpPub    = "PUB"    // messages are sent to every peer
+	zmtpEcho   = "REP"    // messages are sent back to the peer unchanged
+)
+
+// zmtpGreeting returns the greeting this side sends: the signature, version
+// 3.0, and the NULL mechanism.
+func zmtpGreeting() []byte {
+	g := make([]byte, zmtpGreetingSize)
+	g[0] = 0xff
+	g[9] = 0x7f
+	g[10] = 3
+	g[11] = 0
+	copy(g[12:32], "NULL")
+	return g
+}
+
+// zmtpConn is a ZMTP connection that has completed its handshake.
+type zmtpConn struct {
+	conn     net.Conn
+	r        *bufio.Reader
+	wmu      sync.Mutex
+	w        *bufio.Writer
+	identity []byte // Identity property sent by the peer, if any
+}
+
+// zmtpHandshake exchanges greetings and READY commands with a peer.
+func zmtpHandshake(conn net.Conn, socketType string) (*zmtpConn, error) {
+	c := &zmtpConn{conn: conn, r: bufio.NewReader(conn), w: bufio.NewWriter(conn)}
+	if _, err := conn.Write(zmtpGreeting()); err != nil {
+		return nil, err
+	}
+	greeting := make([]byte, zmtpGreetingSize)
+	if _, err := io.ReadFull(c.r, greeting); err != nil {
+		return nil, err
+	}
+	if greeting[0] != 0xff || greeting[9] != 0x7f {
+		return nil, errors.New("zmtp: invalid greeting signature")
+	}
+	if greeting[10] < 3 {
+		return nil, fmt.Errorf("zmtp: unsupported protocol version %d.%d", greeting[10], greeting[11])
+	}
+	if mechanism := string(bytes.TrimRight(greeting[12:32], "\x00")); mechanism != "NULL" {
+		return nil, fmt.Errorf("zmtp: unsupported security mechanism %q", mechanism)
+	}
+
+	ready := zmtpCommand("READY", map[string][]byte{"Socket-Type": []byte(socketType)})
+	if err := c.writeFrame(zmtpFlagCommand, ready); err != nil {
+		return nil, err
+	}
+	if err := c.w.Flush(); err != nil {
+		return nil, err
+	}
+	flags, body, err := c.readFrame()
+	if err != nil {
+		return nil, err
+	}
+	if flags&zmtpFlagCommand == 0 {
+		return nil, errors.New("zmtp: expected READY command")
+	}
+	name, props, err := parseZMTPCommand(body)
+	if err != nil {
+		return nil, err
+	}
+	switch name {
+	case "READY":
+		c.identity = props["Identity"]
+	case "ERROR":
+		return nil, fmt.Errorf("zmtp: peer rejected handshake: %s", props["reason"])
+	default:
+		return nil, fmt.Errorf("zmtp: expected READY command, got %s", name)
+	}
+	return c, nil
+}
+
+// zmtpCommand encodes a command body with its metadata properties.
+func zmtpCommand(name string, props map[string][]byte) []byte {
+	var buf bytes.Buffer
+	buf.WriteByte(byte(len(name)))
+	buf.WriteString(name)
+	for key, value := range props {
+		buf.WriteByte(byte(len(key)))
+		buf.WriteString(key)
+		binary.Write(&buf, binary.BigEndian, uint32(len(value)))
+		buf.Write(value)
+	}
+	return buf.Bytes()
+}
+
+// parseZMTPCommand decodes a command body. Properties are only decoded for
+// READY; the reason of an ERROR command is returned under "reason".
+func parseZMTPCommand(body []byte) (string, map[string][]byte, error) {
+	if len(body) < 1 || len(body) < 1+int(body[0]) {
+		return "", nil, errors.New("zmtp: malformed command")
+	}
+	name := string(body[1 : 1+body[0]])
+	data := body[1+body[0]:]
+	props := map[string][]byte{}
+	switch name {
+	case "ERROR":
+		if len(data) > 0 && len(data) >= 1+int(data[0]) {
+			props["reason"] = data[1 : 1+data[0]]
+		}
+	case "READY":
+		for len(data) > 0 {
+			n := int(data[0])
+			if len(data) < 1+n+4 {
+				return "", nil, errors.New("zmtp: malformed READY properties")
+			}
+			key := string(data[1 : 1+n])
+			size := binary.BigEndian.Uint32(data[1+n:])
+			data = data[1+n+4:]
+			if uint32(len(data)) < size {
+				return "", nil, errors.New("zmtp: malformed READY properties")
+			}
+			props[key] = data[:size]
+			data = data[size:]
+		}
+	}
+	return name, props, nil
+}
+
+func (c *zmtpConn) readFrame() (byte, []byte, error) {
+	flags, err := c.r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	var size uint64
+	if flags&zmtpFlagLong != 0 {
+		var b [8]byte
+		if _, err := io.ReadFull(c.r, b[:]); err != nil {
+			return 0, nil, err
+		}
+		size = binary.BigEndian.Uint64(b[:])
+	} else {
+		b, err := c.r.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+		size = uint64(b)
+	}
+	if size > zmtpMaxFrameSize {
+		return 0, nil, fmt.Errorf("zmtp: frame of %d bytes exceeds limit", size)
+	}
+	body := make([]byte, size)
+	if _, err := io.ReadFull(c.r, body); err != nil {
+		return 0, nil, err
+	}
+	return flags, body, nil
+}
+
+func (c *zmtpConn) writeFrame(flags byte, body []byte) error {
+	if len(body) > 255 {
+		flags |= zmtpFlagLong
+	}
+	if err := c.w.WriteByte(flags); err != nil {
+		return err
+	}
+	if flags&zmtpFlagLong != 0 {
+		var b [8]byte
+		binary.BigEndian.PutUint64(b[:], uint64(len(body)))
+		c.w.Write(b[:])
+	} else {
+		c.w.WriteByte(byte(len(body)))
+	}
+	_, err := c.w.Write(body)
+	return err
+}
+
+// readMessage reads the frames of the next message. Commands received
+// between messages, such as subscriptions, are ignored.
+func (c *zmtpConn) readMessage() ([][]byte, error) {
+	var frames [][]byte
+	for {
+		flags, body, err := c.readFrame()
+		if err != nil {
+			return nil, err
+		}
+		if flags&zmtpFlagCommand != 0 {
+			continue
+		}
+		frames = append(frames, body)
+		if flags&zmtpFlagMore == 0 {
+			return frames, nil
+		}
+	}
+}
+
+// writeMessage writes all frames of a message. It is safe to call from
+// multiple goroutines.
+func (c *zmtpConn) writeMessage(frames [][]byte) error {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	for i, frame := range frames {
+		var flags byte
+		if i < len(frames)-1 {
+			flags = zmtpFlagMore
+		}
+		if err := c.writeFrame(flags, frame); err != nil {
+			return err
+		}
+	}
+	return c.w.Flush()
+}
+
+// zmtpSocket is a listening socket that accepts any number of peers.
+type zmtpSocket struct {
+	kind     string
+	ln       net.Listener
+	incoming chan [][]byte
+
+	mu     sync.Mutex
+	peers  map[string]*zmtpConn
+	nextID uint32
+	closed bool
+}
+
+// listenZMTP listens on a TCP address. ROUTER sockets deliver received
+// messages on Incoming, PUB sockets discard them, and echo sockets reply
+// to each message with the message itself, which is how the Jupyter
+// heartbeat works.
+func listenZMTP(kind, addr string) (*zmtpSocket, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	s := &zmtpSocket{
+		kind:     kind,
+		ln:       ln,
+		incoming: make(chan [][]byte, 16),
+		peers:    map[string]*zmtpConn{},
+	}
+	go s.accept()
+	return s, nil
+}
+
+// Addr returns the address the socket is listening on.
+func (s *zmtpSocket) Addr() net.Addr {
+	return s.ln.Addr()
+}
+
+// Incoming returns the messages received by a ROUTER socket, each prefixed
+// with the routing id of the peer that sent it.
+func (s *zmtpSocket) Incoming() <-chan [][]byte {
+	return s.incoming
+}
+
+func (s *zmtpSocket) accept() {
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		go s.serve(conn)
+	}
+}
+
+func (s *zmtpSocket) serve(conn net.Conn) {
+	defer conn.Close()
+	c, err := zmtpHandshake(conn, s.kind)
+	if err != nil {
+		return
+	}
+	id := s.addPeer(c)
+	if id == "" {
+		return
+	}
+	defer s.removePeer(id)
+	for {
+		frames, err := c.readMessage()
+		if err != nil {
+			return
+		}
+		switch s.kind {
+		case zmtpRouter:
+			s.incoming <- append([][]byte{[]byte(id)}, frames...)
+		case zmtpEcho:
+			if err := c.writeMessage(frames); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// addPeer registers a connection and returns its routing id, which is the
+// identity the peer announced or a generated one. It returns "" if the
+// socket is closed.
+func (s *zmtpSocket) addPeer(c *zmtpConn) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return ""
+	}
+	id := string(c.identity)
+	if _, taken := s.peers[id]; id == "" || taken {
+		// Generated ids start with a zero byte, which announced ids may not
+		s.nextID++
+		var b [5]byte
+		binary.BigEndian.PutUint32(b[1:], s.nextID)
+		id = string(b[:])
+	}
+	s.peers[id] = c
+	return id
+}
+
+func (s *zmtpSocket) removePeer(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.peers, id)
+}
+
+// Send writes a message. On a ROUTER socket the first frame is the routing
+// id of the peer to send to; messages for unknown peers are dropped. On a
+// PUB socket the message goes to every peer.
+func (s *zmtpSocket) Send(frames [][]byte) error {
+	s.mu.Lock()
+	var targets []*zmtpConn
+	if s.kind == zmtpRouter {
+		if len(frames) == 0 {
+			s.mu.Unlock()
+			return errors.New("zmtp: message has no routing id")
+		}
+		if c, ok := s.peers[string(frames[0])]; ok {
+			targets = append(targets, c)
+		}
+		frames = frames[1:]
+	} else {
+		for _, c := range s.peers {
+			targets = append(targets, c)
+		}
+	}
+	s.mu.Unlock()
+	for _, c := range targets {
+		// A failed peer is removed when its read loop sees the error
+		c.writeMessage(frames)
+	}
+	return nil
+}
+
+// Close stops listening and disconnects all peers.
+func (s *zmtpSocket) Close() error {
+	s.mu.Lock()
+	s.closed = true
+	for _, c := range s.peers {
+		c.conn.Close()
+	}
+	s.mu.Unlock()
+	return s.ln.Close()
+}
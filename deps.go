@@ -0,0 +1,81 @@
+package risor
+
+import (
+	"slices"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/bytecode"
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+)
+
+// Dependencies describes what a compiled script uses from its env. Hosts
+// can check it against a capability allow-list before running the script:
+//
+//	code, _ := risor.Compile(ctx, source, risor.WithEnv(env))
+//	deps := risor.AnalyzeDependencies(code, risor.WithEnv(env))
+//	for _, name := range deps.Modules {
+//		if !allowed[name] {
+//			return fmt.Errorf("module %s is not allowed", name)
+//		}
+//	}
+//
+// Globals defined by the script itself are not listed; use the code's
+// Dependencies method for a report covering every global.
+type Dependencies struct {
+	// Reads lists the env globals the script reads, sorted by name. It
+	// includes the modules and builtins the script uses.
+	Reads []string
+
+	// Writes lists the env globals the script assigns, sorted by name.
+	Writes []string
+
+	// Modules lists the env globals read by the script that are modules.
+	Modules []string
+
+	// ModuleAttributes maps each module the script uses to the attributes
+	// it accesses directly, as in math.sqrt.
+	ModuleAttributes map[string][]string
+
+	// Builtins lists the env globals read by the script that are builtin
+	// functions, such as len or print.
+	Builtins []string
+}
+
+// AnalyzeDependencies reports the env globals, modules, and builtins used by
+// code and every function defined in it. Pass the options the code was
+// compiled with: the env values determine which globals are modules and
+// which are builtins. The analysis reads the bytecode without running it,
+// so it includes globals used on code paths that never execute.
+func AnalyzeDependencies(code *bytecode.Code, opts ...Option) Dependencies {
+	o := collectOptions(opts...)
+	usage := code.Dependencies()
+	envKeys := code.EnvKeys()
+	isEnv := func(name string) bool {
+		return slices.Contains(envKeys, name)
+	}
+
+	var deps Dependencies
+	for _, name := range usage.Writes {
+		if isEnv(name) {
+			deps.Writes = append(deps.Writes, name)
+		}
+	}
+	for _, name := range usage.Reads {
+		if !isEnv(name) {
+			continue
+		}
+		deps.Reads = append(deps.Reads, name)
+		switch o.env[name].(type) {
+		case *object.Module:
+			deps.Modules = append(deps.Modules, name)
+			if attrs, ok := usage.Attributes[name]; ok {
+				if deps.ModuleAttributes == nil {
+					deps.ModuleAttributes = map[string][]string{}
+				}
+				deps.ModuleAttributes[name] = attrs
+			}
+		case *object.Builtin:
+			deps.Builtins = append(deps.Builtins, name)
+		}
+	}
+	return deps
+}
@@ -0,0 +1,44 @@
+package risor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+func TestAnalyzeDependencies(t *testing.T) {
+	env := Builtins()
+	env["config"] = map[string]any{"limit": 3}
+	env["count"] = 0
+	source := `
+let items = [1, 2, 3]
+function root(x) { math.sqrt(x) }
+count = len(items)
+if (false) {
+	sprintf("%v %v", regexp.compile("a"), config.limit)
+}
+let pi = math?.pi
+`
+	code, err := Compile(context.Background(), source, WithEnv(env))
+	assert.Nil(t, err)
+
+	deps := AnalyzeDependencies(code, WithEnv(env))
+	assert.Equal(t, deps.Reads, []string{"config", "len", "math", "regexp", "sprintf"})
+	assert.Equal(t, deps.Writes, []string{"count"})
+	assert.Equal(t, deps.Modules, []string{"math", "regexp"})
+	assert.Equal(t, deps.Builtins, []string{"len", "sprintf"})
+	assert.Equal(t, deps.ModuleAttributes, map[string][]string{
+		"math":   {"sqrt"},
+		"regexp": {"compile"},
+	})
+}
+
+func TestAnalyzeDependenciesWithoutEnv(t *testing.T) {
+	code, err := Compile(context.Background(), "let x = 1; x + 1")
+	assert.Nil(t, err)
+	deps := AnalyzeDependencies(code)
+	assert.Nil(t, deps.Reads)
+	assert.Nil(t, deps.Writes)
+	assert.Nil(t, deps.Modules)
+}
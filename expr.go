@@ -0,0 +1,109 @@
+package risor
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/ast"
+	"github.com/deepnoodle-ai/risor/v2/pkg/bytecode"
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+)
+
+// Expr is a compiled expression whose result is converted to the Go type T.
+// It suits rule engines and other hosts that evaluate the same short
+// expression many times with different inputs:
+//
+//	price := risor.MustCompileExpr[float64]("base * markup",
+//		risor.WithEnvSchema(map[string]risor.Type{"base": "float", "markup": "float"}))
+//	total, err := price.Eval(ctx, map[string]any{"base": 20.0, "markup": 1.1})
+//
+// As with Compile, the expression's inputs must be known when it is
+// compiled, so references to undeclared variables are compile errors.
+// Declaring them with WithEnvSchema also makes Eval reject an env that is
+// missing an input or supplies one of the wrong type.
+//
+// The result is converted to T with the type registry, the same way
+// arguments of Go functions called from Risor are converted: an int result
+// satisfies T = float64, a list satisfies []string, and a map satisfies a
+// struct type. A null result becomes the zero value of T. Expr is safe for
+// concurrent use.
+type Expr[T any] struct {
+	code *bytecode.Code
+	opts []Option
+}
+
+// CompileExpr compiles source, which must be a single expression, into an
+// Expr. The options are used for compilation and as the base options for
+// Eval.
+func CompileExpr[T any](ctx context.Context, source string, opts ...Option) (*Expr[T], error) {
+	compileOpts := append(slices.Clone(opts), WithValidator(ValidatorFunc(singleExpression)))
+	code, err := Compile(ctx, source, compileOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Expr[T]{code: code, opts: slices.Clone(opts)}, nil
+}
+
+// MustCompileExpr is like CompileExpr but panics if the expression does not
+// compile. It simplifies initializing package-level variables holding
+// expressions known to be valid.
+func MustCompileExpr[T any](source string, opts ...Option) *Expr[T] {
+	e, err := CompileExpr[T](context.Background(), source, opts...)
+	if err != nil {
+		panic(fmt.Sprintf("risor: CompileExpr(%q): %v", source, err))
+	}
+	return e
+}
+
+// singleExpression rejects programs that are not exactly one expression.
+func singleExpression(program *ast.Program) []ValidationError {
+	if len(program.Stmts) == 0 {
+		return []ValidationError{{Message: "expected an expression"}}
+	}
+	if len(program.Stmts) > 1 {
+		stmt := program.Stmts[1]
+		return []ValidationError{{Message: "expected a single expression", Node: stmt, Position: stmt.Pos()}}
+	}
+	stmt := program.Stmts[0]
+	if _, ok := stmt.(ast.Expr); !ok {
+		return []ValidationError{{Message: "expected an expression, not a statement", Node: stmt, Position: stmt.Pos()}}
+	}
+	return nil
+}
+
+// Code returns the compiled code of the expression.
+func (e *Expr[T]) Code() *bytecode.Code {
+	return e.code
+}
+
+// Eval evaluates the expression with the given env, added to any env given
+// to CompileExpr, and converts the result to T.
+func (e *Expr[T]) Eval(ctx context.Context, env map[string]any) (T, error) {
+	var zero T
+	opts := append(slices.Clone(e.opts), WithEnv(env), WithRawResult())
+	result, err := Run(ctx, e.code, opts...)
+	if err != nil {
+		return zero, err
+	}
+	registry := collectOptions(opts...).typeRegistry
+	if registry == nil {
+		registry = object.DefaultRegistry()
+	}
+	obj := result.(object.Object)
+	value, err := registry.ToGo(obj, reflect.TypeFor[T]())
+	if err != nil {
+		return zero, fmt.Errorf("expression result %s cannot be converted to %s: %w",
+			obj.Type(), reflect.TypeFor[T](), err)
+	}
+	if value == nil {
+		return zero, nil
+	}
+	typed, ok := value.(T)
+	if !ok {
+		return zero, fmt.Errorf("expression result %s cannot be converted to %s",
+			obj.Type(), reflect.TypeFor[T]())
+	}
+	return typed, nil
+}
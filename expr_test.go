@@ -0,0 +1,80 @@
+package risor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+func TestExprEval(t *testing.T) {
+	ctx := context.Background()
+	schema := WithEnvSchema(map[string]Type{"price": "float", "quantity": "int"})
+
+	total, err := CompileExpr[float64](ctx, "price * quantity * 1.1", schema)
+	assert.Nil(t, err)
+	result, err := total.Eval(ctx, map[string]any{"price": 10.0, "quantity": 3})
+	assert.Nil(t, err)
+	assert.InDelta(t, result, 33.0, 1e-9)
+
+	// An int result converts to a float64
+	count := MustCompileExpr[float64]("quantity * 2", schema)
+	result, err = count.Eval(ctx, map[string]any{"price": 1.0, "quantity": 4})
+	assert.Nil(t, err)
+	assert.Equal(t, result, 8.0)
+}
+
+func TestExprResultTypes(t *testing.T) {
+	ctx := context.Background()
+
+	names, err := MustCompileExpr[[]string](`["a", "b"]`).Eval(ctx, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, names, []string{"a", "b"})
+
+	// A null result is the zero value
+	value, err := MustCompileExpr[int]("nil").Eval(ctx, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, value, 0)
+
+	allowed, err := MustCompileExpr[bool]("tier == 'gold'",
+		WithEnvSchema(map[string]Type{"tier": "string"})).Eval(ctx, map[string]any{"tier": "gold"})
+	assert.Nil(t, err)
+	assert.True(t, allowed)
+
+	_, err = MustCompileExpr[int](`"many"`).Eval(ctx, nil)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "cannot be converted to int")
+}
+
+func TestExprCompileErrors(t *testing.T) {
+	ctx := context.Background()
+	schema := WithEnvSchema(map[string]Type{"price": "float"})
+
+	_, err := CompileExpr[float64](ctx, "price * markup", schema)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "markup")
+
+	_, err = CompileExpr[float64](ctx, "let x = price", schema)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "expected an expression")
+
+	_, err = CompileExpr[float64](ctx, "price\nprice * 2", schema)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "expected a single expression")
+
+	_, err = CompileExpr[float64](ctx, "", schema)
+	assert.NotNil(t, err)
+
+	assert.Panics(t, func() { MustCompileExpr[float64]("price *", schema) })
+}
+
+func TestExprEvalChecksEnv(t *testing.T) {
+	ctx := context.Background()
+	expr := MustCompileExpr[float64]("price * 2", WithEnvSchema(map[string]Type{"price": "float"}))
+
+	_, err := expr.Eval(ctx, map[string]any{"price": "cheap"})
+	assert.NotNil(t, err)
+
+	_, err = expr.Eval(ctx, map[string]any{})
+	assert.NotNil(t, err)
+}
@@ -53,6 +53,18 @@ type Lexer struct {
 
 	// Name of the file be read
 	file string
+
+	// Comments skipped so far, in source order
+	comments []Comment
+}
+
+// Comment is a comment the lexer skipped over.
+type Comment struct {
+	// Text is the comment, including its "//" or "/* */" delimiters.
+	Text string
+
+	// Position is the position of the comment's first character.
+	Position token.Position
 }
 
 // Option is a configuration function for a Lexer.
@@ -123,6 +135,26 @@ func (l *Lexer) RestoreState(s State) {
 	l.tokenStartPosition = s.tokenStartPosition
 }
 
+// Comments returns the comments read so far, in source order. The shebang
+// line is not included.
+func (l *Lexer) Comments() []Comment {
+	return l.comments
+}
+
+// addComment records the comment that starts at start and ends before the
+// current character, less trailing whitespace. A comment read again after RestoreState is only
+// recorded once.
+func (l *Lexer) addComment(start token.Position) {
+	if n := len(l.comments); n > 0 && l.comments[n-1].Position.Char >= start.Char {
+		return
+	}
+	text := string(l.characters[start.Char:min(l.position, len(l.characters))])
+	l.comments = append(l.comments, Comment{
+		Text:     strings.TrimRight(text, " \t\r"),
+		Position: start,
+	})
+}
+
 // SetFilename sets the name of the file being read.
 func (l *Lexer) SetFilename(file string) {
 	l.file = file
@@ -153,13 +185,17 @@ func (l *Lexer) Next() (token.Token, error) {
 
 	// skip single-line comments
 	if l.ch == rune('/') && l.peekChar() == rune('/') {
+		start := l.Position()
 		l.skipComment()
+		l.addComment(start)
 		return l.Next()
 	}
 
 	// multi-line comments
 	if l.ch == rune('/') && l.peekChar() == rune('*') {
+		start := l.Position()
 		l.skipMultiLineComment()
+		l.addComment(start)
 	}
 
 	if l.prevToken.Type == token.EOF {
@@ -257,6 +293,10 @@ func (l *Lexer) Next() (token.Token, error) {
 			ch := l.ch
 			l.readChar()
 			tok = l.newToken(token.MINUS_EQUALS, string(ch)+string(l.ch))
+		} else if l.peekChar() == rune('>') {
+			ch := l.ch
+			l.readChar()
+			tok = l.newToken(token.MINUS_GT, string(ch)+string(l.ch))
 		} else {
 			tok = l.newToken(token.MINUS, string(l.ch))
 		}
@@ -1673,3 +1673,63 @@ func TestSlashEquals(t *testing.T) {
 		assert.Equal(t, tok.Literal, exp.literal, "token %d literal", i)
 	}
 }
+
+func TestMinusGt(t *testing.T) {
+	// "->" introduces a return type; "-->" is still a decrement then ">"
+	input := ") -> int\na-->b"
+	expected := []struct {
+		typ     token.Type
+		literal string
+	}{
+		{token.RPAREN, ")"},
+		{token.MINUS_GT, "->"},
+		{token.IDENT, "int"},
+		{token.NEWLINE, "\n"},
+		{token.IDENT, "a"},
+		{token.MINUS_MINUS, "--"},
+		{token.GT, ">"},
+		{token.IDENT, "b"},
+		{token.EOF, ""},
+	}
+	l := New(input)
+	for i, exp := range expected {
+		tok, err := l.Next()
+		assert.Nil(t, err)
+		assert.Equal(t, tok.Type, exp.typ, "token %d type", i)
+		assert.Equal(t, tok.Literal, exp.literal, "token %d literal", i)
+	}
+}
+
+func TestComments(t *testing.T) {
+	input := "#!/usr/bin/env risor\n// first\nx := 1 // trailing  \n/* block\n   comment */ y\n"
+	l := New(input)
+	for {
+		tok, err := l.Next()
+		assert.Nil(t, err)
+		if tok.Type == token.EOF {
+			break
+		}
+	}
+	comments := l.Comments()
+	assert.Len(t, comments, 3)
+	assert.Equal(t, comments[0].Text, "// first")
+	assert.Equal(t, comments[0].Position.LineNumber(), 2)
+	assert.Equal(t, comments[1].Text, "// trailing")
+	assert.Equal(t, comments[1].Position.ColumnNumber(), 8)
+	assert.Equal(t, comments[2].Text, "/* block\n   comment */")
+	assert.Equal(t, comments[2].Position.LineNumber(), 4)
+}
+
+func TestCommentsAfterRestore(t *testing.T) {
+	l := New("a // note\nb")
+	tok, err := l.Next()
+	assert.Nil(t, err)
+	assert.Equal(t, tok.Literal, "a")
+	state := l.SaveState()
+	_, err = l.Next()
+	assert.Nil(t, err)
+	l.RestoreState(state)
+	_, err = l.Next()
+	assert.Nil(t, err)
+	assert.Len(t, l.Comments(), 1)
+}
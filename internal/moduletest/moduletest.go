@@ -0,0 +1,40 @@
+// Package moduletest runs scripts for the tests of the modules under
+// pkg/modules, which cannot import the risor package because it imports
+// them.
+package moduletest
+
+import (
+	"context"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/builtins"
+	"github.com/deepnoodle-ai/risor/v2/pkg/compiler"
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+	"github.com/deepnoodle-ai/risor/v2/pkg/parser"
+	"github.com/deepnoodle-ai/risor/v2/pkg/vm"
+)
+
+// Eval runs source with the default builtins and the given globals, which
+// take precedence over builtins of the same name. Options are passed to the
+// VM.
+func Eval(ctx context.Context, source string, globals map[string]any, opts ...vm.Option) (object.Object, error) {
+	program, err := parser.Parse(ctx, source, nil)
+	if err != nil {
+		return nil, err
+	}
+	env := map[string]any{}
+	for name, value := range builtins.Builtins() {
+		env[name] = value
+	}
+	for name, value := range globals {
+		env[name] = value
+	}
+	names := make([]string, 0, len(env))
+	for name := range env {
+		names = append(names, name)
+	}
+	code, err := compiler.Compile(program, &compiler.Config{GlobalNames: names})
+	if err != nil {
+		return nil, err
+	}
+	return vm.Run(ctx, code, append([]vm.Option{vm.WithGlobals(env)}, opts...)...)
+}
@@ -67,6 +67,7 @@ const (
 	CONST           Type = "CONST"
 	FUNCTION        Type = "FUNCTION"
 	ELSE            Type = "ELSE"
+	ENUM            Type = "ENUM"
 	EOF             Type = "EOF"
 	EQ              Type = "=="
 	FALSE           Type = "FALSE"
@@ -87,6 +88,7 @@ const (
 	LET             Type = "LET"
 	MINUS           Type = "-"
 	MINUS_EQUALS    Type = "-="
+	MINUS_GT        Type = "->"
 	MINUS_MINUS     Type = "--"
 	MOD             Type = "%"
 	NOT_EQ          Type = "!="
@@ -129,6 +131,7 @@ const (
 var keywords = map[string]Type{
 	"const":    CONST,
 	"else":     ELSE,
+	"enum":     ENUM,
 	"false":    FALSE,
 	"function": FUNCTION,
 	"if":       IF,
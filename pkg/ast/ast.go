@@ -58,3 +58,17 @@ func (x *BadStmt) stmtNode() {}
 func (x *BadStmt) Pos() token.Position { return x.From }
 func (x *BadStmt) End() token.Position { return x.To }
 func (x *BadStmt) String() string      { return "<bad statement>" }
+
+// TypeAnnotation is an optional type annotation on a variable, constant,
+// function parameter, or function result, as in "let x: int = 1" or
+// "function f(s: string) -> bool". The name is a Risor type name such as
+// "int", "list", or "any". Annotations have no effect at runtime; they are
+// only checked when a type checking validator is used.
+type TypeAnnotation struct {
+	NamePos token.Position // position of the type name
+	Name    string         // type name
+}
+
+func (x *TypeAnnotation) Pos() token.Position { return x.NamePos }
+func (x *TypeAnnotation) End() token.Position { return x.NamePos.Advance(len(x.Name)) }
+func (x *TypeAnnotation) String() string      { return x.Name }
@@ -1135,3 +1135,21 @@ func TestAssignNilNameAndIndex(t *testing.T) {
 	}()
 	_ = assign.Pos()
 }
+
+func TestCommentLines(t *testing.T) {
+	tests := []struct {
+		text  string
+		lines []string
+	}{
+		{"// hello", []string{"hello"}},
+		{"//hello", []string{"hello"}},
+		{"//   indented", []string{"  indented"}},
+		{"/* one line */", []string{"one line"}},
+		{"/*\n * first\n *\n * second\n */", []string{"first", "", "second"}},
+		{"/* first\n   second */", []string{"first", "second"}},
+	}
+	for _, tt := range tests {
+		comment := &Comment{Text: tt.text}
+		assert.Equal(t, comment.Lines(), tt.lines, tt.text)
+	}
+}
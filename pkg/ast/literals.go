@@ -203,14 +203,16 @@ func (x *ArrayDestructureParam) String() string {
 
 // Func is an expression node that holds a function literal.
 type Func struct {
-	Func      token.Position  // position of "function" keyword
-	Name      *Ident          // function name; nil for anonymous functions
-	Lparen    token.Position  // position of "("
-	Params    []FuncParam     // parameter names or destructuring patterns
-	Defaults  map[string]Expr // default values for simple parameters
-	RestParam *Ident          // rest parameter (e.g., ...args); nil if none
-	Rparen    token.Position  // position of ")"
-	Body      *Block          // function body
+	Func       token.Position             // position of "function" keyword
+	Name       *Ident                     // function name; nil for anonymous functions
+	Lparen     token.Position             // position of "("
+	Params     []FuncParam                // parameter names or destructuring patterns
+	Defaults   map[string]Expr            // default values for simple parameters
+	ParamTypes map[string]*TypeAnnotation // annotations of simple and rest parameters; nil if none
+	RestParam  *Ident                     // rest parameter (e.g., ...args); nil if none
+	Rparen     token.Position             // position of ")"
+	ReturnType *TypeAnnotation            // result annotation after "->"; nil if none
+	Body       *Block                     // function body
 }
 
 func (x *Func) exprNode() {}
@@ -229,7 +231,11 @@ func (x *Func) String() string {
 	var out bytes.Buffer
 	params := make([]string, 0, len(x.Params))
 	for _, p := range x.Params {
-		params = append(params, p.String())
+		param := p.String()
+		if ident, ok := p.(*Ident); ok && x.ParamTypes[ident.Name] != nil {
+			param += ": " + x.ParamTypes[ident.Name].Name
+		}
+		params = append(params, param)
 	}
 	out.WriteString("function")
 	if x.Name != nil {
@@ -238,7 +244,13 @@ func (x *Func) String() string {
 	}
 	out.WriteString("(")
 	out.WriteString(strings.Join(params, ", "))
-	out.WriteString(") { ")
+	out.WriteString(") ")
+	if x.ReturnType != nil {
+		out.WriteString("-> ")
+		out.WriteString(x.ReturnType.Name)
+		out.WriteString(" ")
+	}
+	out.WriteString("{ ")
 	out.WriteString(x.Body.String())
 	out.WriteString(" }")
 	return out.String()
@@ -268,6 +280,31 @@ func (x *List) String() string {
 	return out.String()
 }
 
+// Tuple is an expression node that builds an immutable tuple, written as
+// parenthesized, comma-separated items: (1, "a"). A tuple with one item
+// needs a trailing comma: (1,).
+type Tuple struct {
+	Lparen token.Position // position of "("
+	Items  []Expr         // tuple elements
+	Rparen token.Position // position of ")"
+}
+
+func (x *Tuple) exprNode() {}
+
+func (x *Tuple) Pos() token.Position { return x.Lparen }
+func (x *Tuple) End() token.Position { return x.Rparen.Advance(1) }
+
+func (x *Tuple) String() string {
+	elements := make([]string, 0, len(x.Items))
+	for _, el := range x.Items {
+		elements = append(elements, el.String())
+	}
+	if len(elements) == 1 {
+		return "(" + elements[0] + ",)"
+	}
+	return "(" + strings.Join(elements, ", ") + ")"
+}
+
 // MapItem represents a single key-value pair in a map literal.
 // For spread expressions (...obj), Key is nil and Value is the spread expression.
 type MapItem struct {
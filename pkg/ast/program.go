@@ -2,6 +2,7 @@ package ast
 
 import (
 	"bytes"
+	"strings"
 
 	"github.com/deepnoodle-ai/risor/v2/internal/token"
 )
@@ -9,7 +10,50 @@ import (
 // Program represents a complete Risor program, which consists of a series of
 // statements.
 type Program struct {
-	Stmts []Node // statements in the program
+	Stmts    []Node     // statements in the program
+	Comments []*Comment // comments in source order
+}
+
+// Comment is a "//" line comment or a "/* */" block comment. Comments are
+// not statements; the parser collects them separately so tools can
+// associate them with nearby code.
+type Comment struct {
+	Slash token.Position // position of the opening "/"
+	Text  string         // comment text, including its delimiters
+}
+
+func (c *Comment) Pos() token.Position { return c.Slash }
+
+func (c *Comment) End() token.Position {
+	return c.Slash.Advance(len([]rune(c.Text)))
+}
+
+// Lines returns the text of the comment with its delimiters removed, one
+// entry per line. One space after "//" and a leading "*" on the lines of
+// a block comment are removed too.
+func (c *Comment) Lines() []string {
+	if text, ok := strings.CutPrefix(c.Text, "//"); ok {
+		return []string{strings.TrimPrefix(text, " ")}
+	}
+	text := strings.TrimSuffix(strings.TrimPrefix(c.Text, "/*"), "*/")
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if line != "*" {
+			line = strings.TrimPrefix(line, "* ")
+		} else {
+			line = ""
+		}
+		lines[i] = line
+	}
+	// Drop the empty lines left by "/*" and "*/" on lines of their own.
+	if len(lines) > 0 && lines[0] == "" {
+		lines = lines[1:]
+	}
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
 }
 
 func (p *Program) Pos() token.Position {
@@ -10,9 +10,10 @@ import (
 // Var is a statement that declares a new variable with an initial value.
 // This is used for "let x = value" statements.
 type Var struct {
-	Let   token.Position // position of "let" keyword
-	Name  *Ident         // variable name
-	Value Expr           // initial value
+	Let   token.Position  // position of "let" keyword
+	Name  *Ident          // variable name
+	Type  *TypeAnnotation // type annotation; nil if none
+	Value Expr            // initial value
 }
 
 func (x *Var) stmtNode() {}
@@ -29,6 +30,10 @@ func (x *Var) String() string {
 	var out bytes.Buffer
 	out.WriteString("let ")
 	out.WriteString(x.Name.Name)
+	if x.Type != nil {
+		out.WriteString(": ")
+		out.WriteString(x.Type.Name)
+	}
 	out.WriteString(" = ")
 	if x.Value != nil {
 		out.WriteString(x.Value.String())
@@ -168,9 +173,10 @@ func (x *ArrayDestructure) String() string {
 
 // Const is a statement that defines a named constant.
 type Const struct {
-	Const token.Position // position of "const" keyword
-	Name  *Ident         // constant name
-	Value Expr           // constant value
+	Const token.Position  // position of "const" keyword
+	Name  *Ident          // constant name
+	Type  *TypeAnnotation // type annotation; nil if none
+	Value Expr            // constant value
 }
 
 func (x *Const) stmtNode() {}
@@ -187,6 +193,10 @@ func (x *Const) String() string {
 	var out bytes.Buffer
 	out.WriteString("const ")
 	out.WriteString(x.Name.Name)
+	if x.Type != nil {
+		out.WriteString(": ")
+		out.WriteString(x.Type.Name)
+	}
 	out.WriteString(" = ")
 	if x.Value != nil {
 		out.WriteString(x.Value.String())
@@ -194,6 +204,36 @@ func (x *Const) String() string {
 	return out.String()
 }
 
+// Enum is a statement that defines an enumeration: a named, immutable set
+// of members, as in "enum Color { Red, Green, Blue }".
+type Enum struct {
+	Enum    token.Position // position of "enum" keyword
+	Name    *Ident         // enum name
+	Lbrace  token.Position // position of "{"
+	Members []*Ident       // member names, in declaration order
+	Rbrace  token.Position // position of "}"
+}
+
+func (x *Enum) stmtNode() {}
+
+func (x *Enum) Pos() token.Position { return x.Enum }
+func (x *Enum) End() token.Position { return x.Rbrace.Advance(1) }
+
+func (x *Enum) String() string {
+	var out bytes.Buffer
+	out.WriteString("enum ")
+	out.WriteString(x.Name.Name)
+	out.WriteString(" { ")
+	for i, member := range x.Members {
+		if i > 0 {
+			out.WriteString(", ")
+		}
+		out.WriteString(member.Name)
+	}
+	out.WriteString(" }")
+	return out.String()
+}
+
 // Return defines a return statement.
 type Return struct {
 	Return token.Position // position of "return" keyword
@@ -228,6 +228,10 @@ func Walk(v Visitor, node Node) {
 		for _, item := range n.Items {
 			Walk(v, item)
 		}
+	case *Tuple:
+		for _, item := range n.Items {
+			Walk(v, item)
+		}
 	case *Map:
 		for _, pair := range n.Items {
 			if pair.Key != nil {
@@ -515,6 +519,12 @@ func Preorder(root Node) iter.Seq[Node] {
 						return false
 					}
 				}
+			case *Tuple:
+				for _, item := range node.Items {
+					if !visit(item) {
+						return false
+					}
+				}
 			case *Map:
 				for _, pair := range node.Items {
 					if pair.Key != nil && !visit(pair.Key) {
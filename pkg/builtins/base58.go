@@ -0,0 +1,81 @@
+package builtins
+
+import "fmt"
+
+// base58Alphabet is the Bitcoin alphabet, which leaves out 0, O, I, and l
+// to avoid characters that are easily confused.
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+var base58Values = func() [256]int {
+	var values [256]int
+	for i := range values {
+		values[i] = -1
+	}
+	for i, c := range base58Alphabet {
+		values[c] = i
+	}
+	return values
+}()
+
+// base58Encode encodes data as base58. Each leading zero byte becomes a
+// leading "1".
+func base58Encode(data []byte) string {
+	zeros := 0
+	for zeros < len(data) && data[zeros] == 0 {
+		zeros++
+	}
+	// Digits of the number in base 58, least significant first
+	digits := make([]byte, 0, len(data)*138/100+1)
+	for _, b := range data[zeros:] {
+		carry := int(b)
+		for i := range digits {
+			carry += int(digits[i]) << 8
+			digits[i] = byte(carry % 58)
+			carry /= 58
+		}
+		for carry > 0 {
+			digits = append(digits, byte(carry%58))
+			carry /= 58
+		}
+	}
+	out := make([]byte, zeros+len(digits))
+	for i := 0; i < zeros; i++ {
+		out[i] = base58Alphabet[0]
+	}
+	for i, d := range digits {
+		out[len(out)-1-i] = base58Alphabet[d]
+	}
+	return string(out)
+}
+
+// base58Decode decodes base58 text. Each leading "1" becomes a leading zero
+// byte.
+func base58Decode(text []byte) ([]byte, error) {
+	zeros := 0
+	for zeros < len(text) && text[zeros] == base58Alphabet[0] {
+		zeros++
+	}
+	// Bytes of the number, least significant first
+	var bytes []byte
+	for i, c := range text[zeros:] {
+		value := base58Values[c]
+		if value < 0 {
+			return nil, fmt.Errorf("illegal base58 data at input byte %d", zeros+i)
+		}
+		carry := value
+		for j := range bytes {
+			carry += int(bytes[j]) * 58
+			bytes[j] = byte(carry)
+			carry >>= 8
+		}
+		for carry > 0 {
+			bytes = append(bytes, byte(carry))
+			carry >>= 8
+		}
+	}
+	out := make([]byte, zeros+len(bytes))
+	for i, b := range bytes {
+		out[len(out)-1-i] = b
+	}
+	return out, nil
+}
@@ -7,6 +7,7 @@ import (
 	"io"
 	"sort"
 	"strconv"
+	"strings"
 
 	"github.com/deepnoodle-ai/risor/v2/pkg/object"
 )
@@ -49,13 +50,60 @@ func Error(ctx context.Context, args ...object.Object) (object.Object, error) {
 	if err != nil {
 		return nil, err
 	}
+	// A trailing map that no format verb consumes is a data payload, and
+	// the message becomes the code
+	var data *object.Map
+	if m, ok := args[len(args)-1].(*object.Map); ok && len(args)-2 >= formatArgCount(fs) {
+		data = m
+		args = args[:len(args)-1]
+	}
 	fmtArgs := make([]interface{}, len(args)-1)
 	for i, v := range args[1:] {
 		fmtArgs[i] = v.Interface()
 	}
+	if data != nil {
+		return object.NewError(object.NewScriptError(fmt.Sprintf(fs, fmtArgs...), data)), nil
+	}
 	return object.NewError(fmt.Errorf(fs, fmtArgs...)), nil
 }
 
+// formatArgCount returns the number of arguments the fmt-style format
+// string consumes, counting `*` widths and precisions and explicit
+// argument indexes.
+func formatArgCount(format string) int {
+	count, next := 0, 0
+	use := func() {
+		next++
+		count = max(count, next)
+	}
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		for i++; i < len(format); i++ {
+			c := format[i]
+			if c == '[' {
+				end := strings.IndexByte(format[i:], ']')
+				if end < 0 {
+					break
+				}
+				if n, err := strconv.Atoi(format[i+1 : i+end]); err == nil && n > 0 {
+					next = n - 1
+				}
+				i += end
+			} else if c == '*' {
+				use()
+			} else if strings.IndexByte("+-# 0.123456789", c) < 0 {
+				if c != '%' {
+					use()
+				}
+				break
+			}
+		}
+	}
+	return count
+}
+
 func List(ctx context.Context, args ...object.Object) (object.Object, error) {
 	if len(args) > 1 {
 		return nil, fmt.Errorf("list: expected 0-1 arguments, got %d", len(args))
@@ -72,13 +120,66 @@ func List(ctx context.Context, args ...object.Object) (object.Object, error) {
 		return nil, object.TypeErrorf("list() expected an enumerable (%s given)", args[0].Type())
 	}
 	var items []object.Object
-	enumerable.Enumerate(ctx, func(key, value object.Object) bool {
+	if err := object.Iterate(ctx, enumerable, func(key, value object.Object) bool {
 		items = append(items, value)
 		return true
-	})
+	}); err != nil {
+		return nil, err
+	}
 	return object.NewList(items), nil
 }
 
+// Tuple converts an enumerable, such as a list or range, to an immutable
+// tuple.
+func Tuple(ctx context.Context, args ...object.Object) (object.Object, error) {
+	if len(args) > 1 {
+		return nil, fmt.Errorf("tuple: expected 0-1 arguments, got %d", len(args))
+	}
+	items := []object.Object{}
+	if len(args) == 0 {
+		return object.NewTuple(items), nil
+	}
+	enumerable, ok := args[0].(object.Enumerable)
+	if !ok {
+		return nil, object.TypeErrorf("tuple() expected an enumerable (%s given)", args[0].Type())
+	}
+	if err := object.Iterate(ctx, enumerable, func(key, value object.Object) bool {
+		items = append(items, value)
+		return true
+	}); err != nil {
+		return nil, err
+	}
+	return object.NewTuple(items), nil
+}
+
+// Buffer returns a new buffer for building a string or bytes, optionally
+// holding initial contents.
+func Buffer(ctx context.Context, args ...object.Object) (object.Object, error) {
+	if len(args) > 1 {
+		return nil, fmt.Errorf("buffer: expected 0-1 arguments, got %d", len(args))
+	}
+	buf := object.NewBuffer(nil)
+	if len(args) == 1 {
+		if _, err := buf.WriteObject(args[0]); err != nil {
+			return nil, err
+		}
+	}
+	return buf, nil
+}
+
+// Iter returns a lazy iterator over an enumerable, such as a list, map,
+// range or string. Iterating a map yields its keys.
+func Iter(ctx context.Context, args ...object.Object) (object.Object, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("iter: expected 1 argument, got %d", len(args))
+	}
+	enumerable, ok := args[0].(object.Enumerable)
+	if !ok {
+		return nil, object.TypeErrorf("iter() expected an enumerable (%s given)", args[0].Type())
+	}
+	return object.IterOf(enumerable), nil
+}
+
 func String(ctx context.Context, args ...object.Object) (object.Object, error) {
 	if len(args) > 1 {
 		return nil, fmt.Errorf("string: expected 0-1 arguments, got %d", len(args))
@@ -146,13 +247,15 @@ func Any(ctx context.Context, args ...object.Object) (object.Object, error) {
 		}
 	case object.Enumerable:
 		found := false
-		arg.Enumerate(ctx, func(key, value object.Object) bool {
+		if err := object.Iterate(ctx, arg, func(key, value object.Object) bool {
 			if value.IsTruthy() {
 				found = true
 				return false
 			}
 			return true
-		})
+		}); err != nil {
+			return nil, err
+		}
 		if found {
 			return object.True, nil
 		}
@@ -175,13 +278,15 @@ func All(ctx context.Context, args ...object.Object) (object.Object, error) {
 		}
 	case object.Enumerable:
 		allTruthy := true
-		arg.Enumerate(ctx, func(key, value object.Object) bool {
+		if err := object.Iterate(ctx, arg, func(key, value object.Object) bool {
 			if !value.IsTruthy() {
 				allTruthy = false
 				return false
 			}
 			return true
-		})
+		}); err != nil {
+			return nil, err
+		}
 		if !allTruthy {
 			return object.False, nil
 		}
@@ -213,7 +318,7 @@ func Filter(ctx context.Context, args ...object.Object) (object.Object, error) {
 		}
 	case object.Enumerable:
 		var filterErr error
-		container.Enumerate(ctx, func(key, value object.Object) bool {
+		if err := object.Iterate(ctx, container, func(key, value object.Object) bool {
 			decision, err := fn.Call(ctx, value)
 			if err != nil {
 				filterErr = err
@@ -223,7 +328,9 @@ func Filter(ctx context.Context, args ...object.Object) (object.Object, error) {
 				result = append(result, value)
 			}
 			return true
-		})
+		}); err != nil {
+			return nil, err
+		}
 		if filterErr != nil {
 			return nil, filterErr
 		}
@@ -322,6 +429,77 @@ func GetAttr(ctx context.Context, args ...object.Object) (object.Object, error)
 		args[0].Type(), attrName)
 }
 
+// Dir returns the sorted names of the attributes available on an object.
+func Dir(ctx context.Context, args ...object.Object) (object.Object, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("dir: expected 1 argument, got %d", len(args))
+	}
+	names := object.Dir(args[0])
+	items := make([]object.Object, len(names))
+	for i, name := range names {
+		items[i] = object.NewString(name)
+	}
+	return object.NewList(items), nil
+}
+
+// Inspect returns a string representation of a value. An optional map sets
+// the maximum nesting depth, the maximum items shown per container, whether
+// to indent nested containers, and the line width beyond which they are
+// indented.
+func Inspect(ctx context.Context, args ...object.Object) (object.Object, error) {
+	if len(args) < 1 || len(args) > 2 {
+		return nil, fmt.Errorf("inspect: expected 1-2 arguments, got %d", len(args))
+	}
+	var opts object.FormatOptions
+	if len(args) == 2 {
+		optsMap, err := object.AsMap(args[1])
+		if err != nil {
+			return nil, err
+		}
+		if err := optsMap.CheckStringKeys(); err != nil {
+			return nil, fmt.Errorf("inspect: %w", err)
+		}
+		for _, name := range optsMap.SortedKeys() {
+			switch name {
+			case "depth", "items", "width":
+				n, err := object.AsInt(optsMap.Get(name))
+				if err != nil {
+					return nil, err
+				}
+				if n < 0 {
+					return nil, object.ValueErrorf("inspect: %s must be non-negative, got %d", name, n)
+				}
+				switch name {
+				case "depth":
+					opts.MaxDepth = int(n)
+				case "items":
+					opts.MaxItems = int(n)
+				case "width":
+					opts.Width = int(n)
+				}
+			case "pretty":
+				pretty, err := object.AsBool(optsMap.Get(name))
+				if err != nil {
+					return nil, err
+				}
+				opts.Pretty = pretty
+			default:
+				return nil, object.ValueErrorf("inspect: unknown option %q", name)
+			}
+		}
+	}
+	return object.NewString(object.Format(args[0], opts)), nil
+}
+
+// SizeOf returns an estimate of the memory in bytes held by a value,
+// including the items of containers.
+func SizeOf(ctx context.Context, args ...object.Object) (object.Object, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("sizeof: expected 1 argument, got %d", len(args))
+	}
+	return object.NewInt(object.SizeOf(args[0])), nil
+}
+
 func Call(ctx context.Context, args ...object.Object) (object.Object, error) {
 	if len(args) < 1 || len(args) > 64 {
 		return nil, fmt.Errorf("call: expected 1-64 arguments, got %d", len(args))
@@ -344,10 +522,12 @@ func Keys(ctx context.Context, args ...object.Object) (object.Object, error) {
 		return arg.Keys(), nil
 	case object.Enumerable:
 		var keys []object.Object
-		arg.Enumerate(ctx, func(key, value object.Object) bool {
+		if err := object.Iterate(ctx, arg, func(key, value object.Object) bool {
 			keys = append(keys, key)
 			return true
-		})
+		}); err != nil {
+			return nil, err
+		}
 		return object.NewList(keys), nil
 	default:
 		return nil, object.TypeErrorf("keys() unsupported argument (%s given)", args[0].Type())
@@ -419,6 +599,8 @@ func Int(ctx context.Context, args ...object.Object) (object.Object, error) {
 		return object.NewInt(int64(obj.Value())), nil
 	case *object.Float:
 		return object.NewInt(int64(obj.Value())), nil
+	case *object.EnumMember:
+		return object.NewInt(int64(obj.Ordinal())), nil
 	case *object.String:
 		if i, err := strconv.ParseInt(obj.Value(), 0, 64); err == nil {
 			return object.NewInt(i), nil
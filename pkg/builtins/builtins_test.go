@@ -36,11 +36,50 @@ func TestError(t *testing.T) {
 	assert.True(t, ok)
 	assert.Equal(t, errObj.Value().Error(), "file test.txt not found at line 42")
 
+	// Trailing map sets the code and data
+	data := object.NewMap(map[string]object.Object{"id": object.NewInt(7)})
+	result, err = Error(ctx, object.NewString("not_found_%d"), object.NewInt(1), data)
+	assert.Nil(t, err)
+	errObj, ok = result.(*object.Error)
+	assert.True(t, ok)
+	se, ok := errObj.ScriptError()
+	assert.True(t, ok)
+	assert.Equal(t, se.Code, "not_found_1")
+	assert.Equal(t, se.Data, map[string]any{"id": int64(7)})
+
+	// A map consumed by a format verb is formatted, not used as data
+	result, err = Error(ctx, object.NewString("bad config: %v"), data)
+	assert.Nil(t, err)
+	errObj, ok = result.(*object.Error)
+	assert.True(t, ok)
+	assert.Equal(t, errObj.Value().Error(), "bad config: map[id:7]")
+	_, ok = errObj.ScriptError()
+	assert.False(t, ok)
+
 	// No arguments - should error
 	_, err = Error(ctx)
 	assert.NotNil(t, err)
 }
 
+func TestFormatArgCount(t *testing.T) {
+	tests := []struct {
+		format string
+		want   int
+	}{
+		{"plain", 0},
+		{"100%%", 0},
+		{"%v", 1},
+		{"%s and %d", 2},
+		{"%-8.2f", 1},
+		{"%*d", 2},
+		{"%[2]d %[1]d", 2},
+		{"%", 0},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, formatArgCount(tt.format), tt.want, tt.format)
+	}
+}
+
 type testCase struct {
 	input    object.Object
 	expected object.Object
@@ -362,6 +401,111 @@ func TestListErrors(t *testing.T) {
 	assert.NotNil(t, err)
 }
 
+func TestTuple(t *testing.T) {
+	ctx := context.Background()
+
+	result, err := Tuple(ctx)
+	assert.Nil(t, err)
+	assertObjectEqual(t, result, object.NewTuple(nil))
+	assert.Equal(t, result.Inspect(), "()")
+
+	result, err = Tuple(ctx, object.NewRange(0, 3, 1))
+	assert.Nil(t, err)
+	assert.Equal(t, result.Inspect(), "(0, 1, 2)")
+
+	// list() converts a tuple back to a list
+	result, err = List(ctx, result)
+	assert.Nil(t, err)
+	assert.Equal(t, result.Inspect(), "[0, 1, 2]")
+
+	_, err = Tuple(ctx, object.NewFloat(3.14))
+	assert.NotNil(t, err)
+}
+
+func TestDir(t *testing.T) {
+	ctx := context.Background()
+
+	result, err := Dir(ctx, object.NewMap(map[string]object.Object{"name": object.NewInt(1)}))
+	assert.Nil(t, err)
+	names := result.(*object.List)
+	assert.True(t, names.Contains(object.NewString("name")).Value())
+	assert.True(t, names.Contains(object.NewString("keys")).Value())
+
+	_, err = Dir(ctx)
+	assert.NotNil(t, err)
+}
+
+func TestInspect(t *testing.T) {
+	ctx := context.Background()
+	data := object.NewMap(map[string]object.Object{
+		"a": object.NewList([]object.Object{object.NewInt(1), object.NewInt(2), object.NewInt(3)}),
+	})
+
+	result, err := Inspect(ctx, data)
+	assert.Nil(t, err)
+	assert.Equal(t, result.(*object.String).Value(), data.Inspect())
+
+	result, err = Inspect(ctx, data, object.NewMap(map[string]object.Object{"depth": object.NewInt(1)}))
+	assert.Nil(t, err)
+	assert.Equal(t, result.(*object.String).Value(), `{"a": [...]}`)
+
+	result, err = Inspect(ctx, data, object.NewMap(map[string]object.Object{
+		"items":  object.NewInt(1),
+		"pretty": object.True,
+	}))
+	assert.Nil(t, err)
+	assert.Equal(t, result.(*object.String).Value(), "{\n  \"a\": [\n    1,\n    ... 2 more\n  ]\n}")
+
+	_, err = Inspect(ctx, data, object.NewMap(map[string]object.Object{"depth": object.NewInt(-1)}))
+	assert.NotNil(t, err)
+	result, err = Inspect(ctx, data, object.NewMap(map[string]object.Object{"width": object.NewInt(16)}))
+	assert.Nil(t, err)
+	assert.Equal(t, result.(*object.String).Value(), `{"a": [1, 2, 3]}`)
+
+	result, err = Inspect(ctx, data, object.NewMap(map[string]object.Object{"width": object.NewInt(15)}))
+	assert.Nil(t, err)
+	assert.Equal(t, result.(*object.String).Value(), "{\n  \"a\": [\n    1,\n    2,\n    3\n  ]\n}")
+
+	_, err = Inspect(ctx, data, object.NewMap(map[string]object.Object{"indent": object.NewInt(2)}))
+	assert.NotNil(t, err)
+	_, err = Inspect(ctx, data, object.NewInt(1))
+	assert.NotNil(t, err)
+}
+
+func TestSizeOf(t *testing.T) {
+	ctx := context.Background()
+
+	small, err := SizeOf(ctx, object.NewList(nil))
+	assert.Nil(t, err)
+	large, err := SizeOf(ctx, object.NewList([]object.Object{object.NewString("hello")}))
+	assert.Nil(t, err)
+	assert.True(t, large.(*object.Int).Value() > small.(*object.Int).Value())
+
+	_, err = SizeOf(ctx)
+	assert.NotNil(t, err)
+}
+
+func TestBuffer(t *testing.T) {
+	ctx := context.Background()
+
+	result, err := Buffer(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, result.Type(), object.BUFFER)
+	assert.Equal(t, result.(*object.Buffer).Len(), 0)
+
+	result, err = Buffer(ctx, object.NewString("start"))
+	assert.Nil(t, err)
+	assert.Equal(t, result.(*object.Buffer).String(), "start")
+
+	// string() returns the contents
+	s, err := String(ctx, result)
+	assert.Nil(t, err)
+	assert.Equal(t, s, object.NewString("start"))
+
+	_, err = Buffer(ctx, object.NewInt(1))
+	assert.NotNil(t, err)
+}
+
 func TestString(t *testing.T) {
 	ctx := context.Background()
 
@@ -960,3 +1104,30 @@ func TestRangeWithList(t *testing.T) {
 		assert.Equal(t, v.(*object.Int).Value(), expected[i])
 	}
 }
+
+func TestIter(t *testing.T) {
+	ctx := context.Background()
+	result, err := Iter(ctx, object.NewString("abc"))
+	assert.Nil(t, err)
+	it, ok := result.(*object.Iter)
+	assert.True(t, ok)
+	list, err := List(ctx, it)
+	assert.Nil(t, err)
+	assert.Equal(t, list.Inspect(), `["a", "b", "c"]`)
+
+	_, err = Iter(ctx, object.NewInt(1))
+	assert.NotNil(t, err)
+	_, err = Iter(ctx)
+	assert.NotNil(t, err)
+
+	// Errors raised while iterating reach the caller
+	fail := object.NewBuiltin("fail", func(ctx context.Context, args ...object.Object) (object.Object, error) {
+		return nil, object.Errorf("boom")
+	})
+	mapped, err := it.Map(fail)
+	assert.Nil(t, err)
+	_, err = List(ctx, mapped)
+	assert.Error(t, err)
+	_, err = Any(ctx, mapped)
+	assert.Error(t, err)
+}
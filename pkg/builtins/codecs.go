@@ -3,6 +3,7 @@ package builtins
 import (
 	"bytes"
 	"context"
+	"encoding/ascii85"
 	"encoding/base32"
 	"encoding/base64"
 	"encoding/csv"
@@ -10,8 +11,11 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"mime/quotedprintable"
 	"net/url"
 	"sort"
+	"strings"
 	"sync"
 
 	"github.com/deepnoodle-ai/risor/v2/pkg/object"
@@ -30,11 +34,16 @@ type Codec struct {
 
 func init() {
 	RegisterCodec("base64", &Codec{Encode: encodeBase64, Decode: decodeBase64})
+	RegisterCodec("base64url", &Codec{Encode: encodeBase64URL, Decode: decodeBase64URL})
 	RegisterCodec("base32", &Codec{Encode: encodeBase32, Decode: decodeBase32})
+	RegisterCodec("base58", &Codec{Encode: encodeBase58, Decode: decodeBase58})
+	RegisterCodec("base85", &Codec{Encode: encodeBase85, Decode: decodeBase85})
 	RegisterCodec("hex", &Codec{Encode: encodeHex, Decode: decodeHex})
 	RegisterCodec("json", &Codec{Encode: encodeJSON, Decode: decodeJSON})
 	RegisterCodec("csv", &Codec{Encode: encodeCsv, Decode: decodeCsv})
 	RegisterCodec("urlquery", &Codec{Encode: encodeUrlQuery, Decode: decodeUrlQuery})
+	RegisterCodec("quotedprintable", &Codec{Encode: encodeQuotedPrintable, Decode: decodeQuotedPrintable})
+	RegisterCodec("punycode", &Codec{Encode: encodePunycode, Decode: decodePunycode})
 }
 
 // RegisterCodec registers a new codec
@@ -92,6 +101,34 @@ func encodeBase32(ctx context.Context, obj object.Object) (object.Object, error)
 	return object.NewString(base32.StdEncoding.EncodeToString(data)), nil
 }
 
+// encodeBase64URL uses the URL and filename safe alphabet without padding,
+// as in JWTs.
+func encodeBase64URL(ctx context.Context, obj object.Object) (object.Object, error) {
+	data, err := object.AsBytes(obj)
+	if err != nil {
+		return nil, err
+	}
+	return object.NewString(base64.RawURLEncoding.EncodeToString(data)), nil
+}
+
+func encodeBase58(ctx context.Context, obj object.Object) (object.Object, error) {
+	data, err := object.AsBytes(obj)
+	if err != nil {
+		return nil, err
+	}
+	return object.NewString(base58Encode(data)), nil
+}
+
+func encodeBase85(ctx context.Context, obj object.Object) (object.Object, error) {
+	data, err := object.AsBytes(obj)
+	if err != nil {
+		return nil, err
+	}
+	dst := make([]byte, ascii85.MaxEncodedLen(len(data)))
+	count := ascii85.Encode(dst, data)
+	return object.NewString(string(dst[:count])), nil
+}
+
 func encodeHex(ctx context.Context, obj object.Object) (object.Object, error) {
 	data, err := object.AsBytes(obj)
 	if err != nil {
@@ -101,6 +138,9 @@ func encodeHex(ctx context.Context, obj object.Object) (object.Object, error) {
 }
 
 func encodeJSON(ctx context.Context, obj object.Object) (object.Object, error) {
+	if err := object.CheckKeys(obj); err != nil {
+		return nil, err
+	}
 	nativeObject := obj.Interface()
 	if nativeObject == nil {
 		return nil, object.ValueErrorf("encode() does not support %T", obj)
@@ -185,6 +225,9 @@ func encodeCsv(ctx context.Context, obj object.Object) (object.Object, error) {
 			if !ok {
 				return nil, object.ValueErrorf("encode(obj, \"csv\") requires a list of maps (got %s)", item.Type())
 			}
+			if err := innerMap.CheckStringKeys(); err != nil {
+				return nil, fmt.Errorf("encode(obj, \"csv\"): %w", err)
+			}
 			strList, err := csvStringListFromMap(innerMap, keys)
 			if err != nil {
 				return nil, err
@@ -245,6 +288,44 @@ func decodeBase32(ctx context.Context, obj object.Object) (object.Object, error)
 	return object.NewBytes(dst[:count]), nil
 }
 
+// decodeBase64URL accepts URL-safe base64 with or without padding.
+func decodeBase64URL(ctx context.Context, obj object.Object) (object.Object, error) {
+	data, err := object.AsString(obj)
+	if err != nil {
+		return nil, err
+	}
+	result, decodeErr := base64.RawURLEncoding.DecodeString(strings.TrimRight(data, "="))
+	if decodeErr != nil {
+		return nil, decodeErr
+	}
+	return object.NewBytes(result), nil
+}
+
+func decodeBase58(ctx context.Context, obj object.Object) (object.Object, error) {
+	data, err := object.AsBytes(obj)
+	if err != nil {
+		return nil, err
+	}
+	result, decodeErr := base58Decode(data)
+	if decodeErr != nil {
+		return nil, decodeErr
+	}
+	return object.NewBytes(result), nil
+}
+
+func decodeBase85(ctx context.Context, obj object.Object) (object.Object, error) {
+	data, err := object.AsBytes(obj)
+	if err != nil {
+		return nil, err
+	}
+	dst := make([]byte, 4*len(data))
+	count, _, decodeErr := ascii85.Decode(dst, data, true)
+	if decodeErr != nil {
+		return nil, decodeErr
+	}
+	return object.NewBytes(dst[:count]), nil
+}
+
 func decodeHex(ctx context.Context, obj object.Object) (object.Object, error) {
 	data, err := object.AsBytes(obj)
 	if err != nil {
@@ -303,3 +384,58 @@ func decodeUrlQuery(ctx context.Context, obj object.Object) (object.Object, erro
 	}
 	return object.NewString(result), nil
 }
+
+func encodeQuotedPrintable(ctx context.Context, obj object.Object) (object.Object, error) {
+	data, err := object.AsBytes(obj)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	writer := quotedprintable.NewWriter(&buf)
+	if _, err := writer.Write(data); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return object.NewString(buf.String()), nil
+}
+
+func decodeQuotedPrintable(ctx context.Context, obj object.Object) (object.Object, error) {
+	data, err := object.AsBytes(obj)
+	if err != nil {
+		return nil, err
+	}
+	result, readErr := io.ReadAll(quotedprintable.NewReader(bytes.NewReader(data)))
+	if readErr != nil {
+		return nil, readErr
+	}
+	return object.NewBytes(result), nil
+}
+
+// encodePunycode converts the labels of a domain name that contain
+// non-ASCII characters to their ASCII "xn--" form. It does not apply the
+// IDNA mapping rules, so callers should lowercase and normalize names first.
+func encodePunycode(ctx context.Context, obj object.Object) (object.Object, error) {
+	domain, err := object.AsString(obj)
+	if err != nil {
+		return nil, err
+	}
+	result, encodeErr := punycodeEncode(domain)
+	if encodeErr != nil {
+		return nil, encodeErr
+	}
+	return object.NewString(result), nil
+}
+
+func decodePunycode(ctx context.Context, obj object.Object) (object.Object, error) {
+	domain, err := object.AsString(obj)
+	if err != nil {
+		return nil, err
+	}
+	result, decodeErr := punycodeDecode(domain)
+	if decodeErr != nil {
+		return nil, decodeErr
+	}
+	return object.NewString(result), nil
+}
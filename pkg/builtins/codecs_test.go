@@ -53,3 +53,74 @@ func TestJsonCodec(t *testing.T) {
 	}
 	assert.Equal(t, decoded, object.NewString(value))
 }
+
+func TestJsonCodecKeyCollision(t *testing.T) {
+	ctx := context.Background()
+	m := object.NewMap(map[string]object.Object{"1": object.NewString("b")})
+	assert.Nil(t, m.SetItem(object.NewInt(1), object.NewString("a")))
+	_, err := Encode(ctx, object.NewList([]object.Object{m}), object.NewString("json"))
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "have the same string form")
+}
+
+func TestTextCodecs(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		codec   string
+		value   string
+		encoded string
+	}{
+		{"base64url", "\xfb\xff\xfe?", "-__-Pw"},
+		{"base58", "hello world", "StV1DL6CwTryKyV"},
+		{"base58", "\x00\x00\x01", "112"},
+		{"base58", "", ""},
+		{"base85", "hello", "BOu!rDZ"},
+		{"quotedprintable", "café = 1", "caf=C3=A9 =3D 1"},
+	}
+	for _, tc := range tests {
+		codecName := object.NewString(tc.codec)
+		encoded, err := Encode(ctx, object.NewString(tc.value), codecName)
+		assert.Nil(t, err)
+		assert.Equal(t, encoded, object.NewString(tc.encoded), "encode %s %q", tc.codec, tc.value)
+		decoded, err := Decode(ctx, encoded, codecName)
+		assert.Nil(t, err)
+		assert.Equal(t, decoded, object.NewBytes([]byte(tc.value)), "decode %s %q", tc.codec, tc.encoded)
+	}
+
+	// Padded URL-safe base64 is accepted too
+	decoded, err := Decode(ctx, object.NewString("-__-Pw=="), object.NewString("base64url"))
+	assert.Nil(t, err)
+	assert.Equal(t, decoded, object.NewBytes([]byte("\xfb\xff\xfe?")))
+
+	_, err = Decode(ctx, object.NewString("0OIl"), object.NewString("base58"))
+	assert.NotNil(t, err)
+}
+
+func TestPunycodeCodec(t *testing.T) {
+	ctx := context.Background()
+	codec := object.NewString("punycode")
+	tests := []struct {
+		domain  string
+		encoded string
+	}{
+		{"münchen.de", "xn--mnchen-3ya.de"},
+		{"例え.テスト", "xn--r8jz45g.xn--zckzah"},
+		{"bücher-straße.example", "xn--bcher-strae-46a18a.example"},
+		{"example.com", "example.com"},
+		{"ü", "xn--tda"},
+	}
+	for _, tc := range tests {
+		encoded, err := Encode(ctx, object.NewString(tc.domain), codec)
+		assert.Nil(t, err)
+		assert.Equal(t, encoded, object.NewString(tc.encoded), "encode %q", tc.domain)
+		decoded, err := Decode(ctx, encoded, codec)
+		assert.Nil(t, err)
+		assert.Equal(t, decoded, object.NewString(tc.domain), "decode %q", tc.encoded)
+	}
+	decoded, err := Decode(ctx, object.NewString("XN--mnchen-3ya.de"), codec)
+	assert.Nil(t, err)
+	assert.Equal(t, decoded, object.NewString("münchen.de"))
+
+	_, err = Decode(ctx, object.NewString("xn--a-ä"), codec)
+	assert.NotNil(t, err)
+}
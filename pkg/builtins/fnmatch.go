@@ -0,0 +1,150 @@
+package builtins
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+)
+
+// Fnmatch reports whether a name matches a glob pattern. Given a list of
+// names instead, it returns the names that match. Matching is purely textual
+// and never touches the filesystem, so it works equally for paths, object
+// keys, and resource names.
+//
+// In a pattern, * matches any run of characters other than / and ** matches
+// any run including /, so "src/**/*.go" finds Go files at any depth. ?
+// matches one character other than /. [abc] matches one character from a
+// set, with ranges like [a-z] and negation as [!abc] or [^abc]. {a,b}
+// matches either alternative, and alternatives may nest and contain
+// wildcards. A backslash matches the next character literally.
+func Fnmatch(ctx context.Context, args ...object.Object) (object.Object, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("fnmatch: expected 2 arguments, got %d", len(args))
+	}
+	pattern, err := object.AsString(args[0])
+	if err != nil {
+		return nil, err
+	}
+	re, err := compileGlob(pattern)
+	if err != nil {
+		return nil, err
+	}
+	switch names := args[1].(type) {
+	case *object.String:
+		return object.NewBool(re.MatchString(names.Value())), nil
+	case *object.List:
+		var matches []object.Object
+		for _, item := range names.Value() {
+			name, ok := item.(*object.String)
+			if !ok {
+				return nil, object.TypeErrorf("fnmatch: expected a list of strings, got %s in list", item.Type())
+			}
+			if re.MatchString(name.Value()) {
+				matches = append(matches, name)
+			}
+		}
+		return object.NewList(matches), nil
+	default:
+		return nil, object.TypeErrorf("fnmatch: expected string or list, got %s", args[1].Type())
+	}
+}
+
+// compileGlob translates a glob pattern into an anchored regular expression.
+func compileGlob(pattern string) (*regexp.Regexp, error) {
+	var sb strings.Builder
+	sb.WriteString(`(?s)^`)
+	runes := []rune(pattern)
+	depth := 0 // open brace groups
+	for i := 0; i < len(runes); i++ {
+		switch c := runes[i]; c {
+		case '*':
+			if i+1 < len(runes) && runes[i+1] == '*' {
+				i++
+				// "**/" at the start of a segment also matches no
+				// directories at all, so "a/**/b" matches "a/b".
+				atSegmentStart := i == 1 || runes[i-2] == '/'
+				if atSegmentStart && i+1 < len(runes) && runes[i+1] == '/' {
+					i++
+					sb.WriteString(`(?:.*/)?`)
+				} else {
+					sb.WriteString(`.*`)
+				}
+			} else {
+				sb.WriteString(`[^/]*`)
+			}
+		case '?':
+			sb.WriteString(`[^/]`)
+		case '[':
+			end, class, err := globClass(runes, i)
+			if err != nil {
+				return nil, fmt.Errorf("fnmatch: %w in pattern %q", err, pattern)
+			}
+			sb.WriteString(class)
+			i = end
+		case '{':
+			depth++
+			sb.WriteString(`(?:`)
+		case ',':
+			if depth > 0 {
+				sb.WriteString(`|`)
+			} else {
+				sb.WriteString(`,`)
+			}
+		case '}':
+			if depth > 0 {
+				depth--
+				sb.WriteString(`)`)
+			} else {
+				sb.WriteString(`\}`)
+			}
+		case '\\':
+			if i+1 == len(runes) {
+				return nil, fmt.Errorf("fnmatch: trailing backslash in pattern %q", pattern)
+			}
+			i++
+			sb.WriteString(regexp.QuoteMeta(string(runes[i])))
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	if depth > 0 {
+		return nil, fmt.Errorf("fnmatch: unclosed { in pattern %q", pattern)
+	}
+	sb.WriteString(`$`)
+	return regexp.Compile(sb.String())
+}
+
+// globClass translates the character class starting at runes[start] into a
+// regular expression class, returning the index of its closing bracket.
+func globClass(runes []rune, start int) (int, string, error) {
+	var sb strings.Builder
+	sb.WriteString(`[`)
+	i := start + 1
+	if i < len(runes) && (runes[i] == '!' || runes[i] == '^') {
+		sb.WriteString(`^/`)
+		i++
+	}
+	first := i
+	for ; i < len(runes); i++ {
+		c := runes[i]
+		switch {
+		case c == ']' && i > first:
+			sb.WriteString(`]`)
+			return i, sb.String(), nil
+		case c == '\\' && i+1 < len(runes):
+			i++
+			sb.WriteString(regexp.QuoteMeta(string(runes[i])))
+		case c == '-' && i > first && i+1 < len(runes) && runes[i+1] != ']':
+			sb.WriteRune('-')
+		case c == '\\' || c == '[' || c == ']' || c == '^' || c == '-':
+			sb.WriteRune('\\')
+			sb.WriteRune(c)
+		default:
+			sb.WriteRune(c)
+		}
+	}
+	return 0, "", fmt.Errorf("unclosed [")
+}
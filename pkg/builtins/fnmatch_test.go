@@ -0,0 +1,80 @@
+package builtins
+
+import (
+	"context"
+	"testing"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+func TestFnmatch(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		name     string
+		expected bool
+	}{
+		{"*.go", "main.go", true},
+		{"*.go", "cmd/main.go", false},
+		{"**/*.go", "main.go", true},
+		{"**/*.go", "cmd/risor/main.go", true},
+		{"src/**/*.go", "src/main.go", true},
+		{"src/**/*.go", "src/a/b/main.go", true},
+		{"src/**", "src/a/b", true},
+		{"src/**", "lib/a", false},
+		{"file?.txt", "file1.txt", true},
+		{"file?.txt", "file10.txt", false},
+		{"a?b", "a/b", false},
+		{"[abc].txt", "b.txt", true},
+		{"[a-c].txt", "d.txt", false},
+		{"[!a-c].txt", "d.txt", true},
+		{"[^a-c].txt", "a.txt", false},
+		{"[]]", "]", true},
+		{"*.{go,rs}", "lib.rs", true},
+		{"*.{go,rs}", "lib.py", false},
+		{"{src,lib}/**/*.{json,y{a,}ml}", "lib/config/app.yml", true},
+		{"{src,lib}/**/*.{json,y{a,}ml}", "lib/config/app.yaml", true},
+		{"{src,lib}/**/*.{json,y{a,}ml}", "test/app.yml", false},
+		{`\*.txt`, "*.txt", true},
+		{`\*.txt`, "a.txt", false},
+		{"prod-*-db", "prod-eu-1-db", true},
+		{"a,b}", "a,b}", true},
+		{"(x).+", "(x).+", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.name, func(t *testing.T) {
+			result, err := Fnmatch(context.Background(), object.NewString(tt.pattern), object.NewString(tt.name))
+			assert.Nil(t, err)
+			assert.Equal(t, result, object.NewBool(tt.expected))
+		})
+	}
+}
+
+func TestFnmatchList(t *testing.T) {
+	names := object.NewList([]object.Object{
+		object.NewString("web-1"),
+		object.NewString("db-1"),
+		object.NewString("web-2"),
+	})
+	result, err := Fnmatch(context.Background(), object.NewString("web-*"), names)
+	assert.Nil(t, err)
+	assert.Equal(t, result.Inspect(), `["web-1", "web-2"]`)
+
+	result, err = Fnmatch(context.Background(), object.NewString("cache-*"), names)
+	assert.Nil(t, err)
+	assert.Equal(t, result.Inspect(), `[]`)
+}
+
+func TestFnmatchErrors(t *testing.T) {
+	ctx := context.Background()
+	for _, pattern := range []string{"[abc", "{a,b", `abc\`} {
+		_, err := Fnmatch(ctx, object.NewString(pattern), object.NewString("a"))
+		assert.NotNil(t, err, pattern)
+	}
+	_, err := Fnmatch(ctx, object.NewString("*"), object.NewInt(1))
+	assert.NotNil(t, err)
+	_, err = Fnmatch(ctx, object.NewString("*"), object.NewList([]object.Object{object.NewInt(1)}))
+	assert.NotNil(t, err)
+	_, err = Fnmatch(ctx, object.NewString("*"))
+	assert.NotNil(t, err)
+}
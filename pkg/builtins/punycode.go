@@ -0,0 +1,214 @@
+package builtins
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"unicode/utf8"
+)
+
+// Bootstring parameters for Punycode, from RFC 3492.
+const (
+	punyBase        = 36
+	punyTMin        = 1
+	punyTMax        = 26
+	punySkew        = 38
+	punyDamp        = 700
+	punyInitialBias = 72
+	punyInitialN    = 128
+	punyPrefix      = "xn--"
+)
+
+func punyAdapt(delta, numPoints int, first bool) int {
+	if first {
+		delta /= punyDamp
+	} else {
+		delta /= 2
+	}
+	delta += delta / numPoints
+	k := 0
+	for delta > ((punyBase-punyTMin)*punyTMax)/2 {
+		delta /= punyBase - punyTMin
+		k += punyBase
+	}
+	return k + (punyBase-punyTMin+1)*delta/(delta+punySkew)
+}
+
+func punyThreshold(k, bias int) int {
+	switch {
+	case k <= bias:
+		return punyTMin
+	case k >= bias+punyTMax:
+		return punyTMax
+	default:
+		return k - bias
+	}
+}
+
+func punyDigit(d int) byte {
+	if d < 26 {
+		return byte('a' + d)
+	}
+	return byte('0' + d - 26)
+}
+
+func punyDigitValue(c byte) int {
+	switch {
+	case c >= 'a' && c <= 'z':
+		return int(c - 'a')
+	case c >= 'A' && c <= 'Z':
+		return int(c - 'A')
+	case c >= '0' && c <= '9':
+		return int(c-'0') + 26
+	default:
+		return punyBase
+	}
+}
+
+// punyEncodeLabel encodes a single label with the Punycode algorithm,
+// without the "xn--" prefix.
+func punyEncodeLabel(label string) (string, error) {
+	input := []rune(label)
+	var out strings.Builder
+	for _, r := range input {
+		if r < utf8.RuneSelf {
+			out.WriteRune(r)
+		}
+	}
+	basic := out.Len()
+	if basic > 0 {
+		out.WriteByte('-')
+	}
+	n, delta, bias := punyInitialN, 0, punyInitialBias
+	for h := basic; h < len(input); {
+		m := math.MaxInt32
+		for _, r := range input {
+			if int(r) >= n && int(r) < m {
+				m = int(r)
+			}
+		}
+		if (m - n) > (math.MaxInt32-delta)/(h+1) {
+			return "", fmt.Errorf("punycode: label is too long")
+		}
+		delta += (m - n) * (h + 1)
+		n = m
+		for _, r := range input {
+			if int(r) < n {
+				delta++
+			}
+			if int(r) != n {
+				continue
+			}
+			q := delta
+			for k := punyBase; ; k += punyBase {
+				t := punyThreshold(k, bias)
+				if q < t {
+					break
+				}
+				out.WriteByte(punyDigit(t + (q-t)%(punyBase-t)))
+				q = (q - t) / (punyBase - t)
+			}
+			out.WriteByte(punyDigit(q))
+			bias = punyAdapt(delta, h+1, h == basic)
+			delta = 0
+			h++
+		}
+		delta++
+		n++
+	}
+	return out.String(), nil
+}
+
+// punyDecodeLabel decodes a single Punycode label, without the "xn--"
+// prefix.
+func punyDecodeLabel(label string) (string, error) {
+	var output []rune
+	pos := 0
+	if b := strings.LastIndexByte(label, '-'); b >= 0 {
+		for i := 0; i < b; i++ {
+			if label[i] >= utf8.RuneSelf {
+				return "", fmt.Errorf("punycode: invalid input %q", label)
+			}
+			output = append(output, rune(label[i]))
+		}
+		pos = b + 1
+	}
+	n, i, bias := punyInitialN, 0, punyInitialBias
+	for pos < len(label) {
+		oldi, w := i, 1
+		for k := punyBase; ; k += punyBase {
+			if pos >= len(label) {
+				return "", fmt.Errorf("punycode: invalid input %q", label)
+			}
+			digit := punyDigitValue(label[pos])
+			pos++
+			if digit >= punyBase || digit > (math.MaxInt32-i)/w {
+				return "", fmt.Errorf("punycode: invalid input %q", label)
+			}
+			i += digit * w
+			t := punyThreshold(k, bias)
+			if digit < t {
+				break
+			}
+			if w > math.MaxInt32/(punyBase-t) {
+				return "", fmt.Errorf("punycode: invalid input %q", label)
+			}
+			w *= punyBase - t
+		}
+		count := len(output) + 1
+		bias = punyAdapt(i-oldi, count, oldi == 0)
+		n += i / count
+		i %= count
+		if n > utf8.MaxRune || !utf8.ValidRune(rune(n)) {
+			return "", fmt.Errorf("punycode: invalid input %q", label)
+		}
+		output = append(output, 0)
+		copy(output[i+1:], output[i:])
+		output[i] = rune(n)
+		i++
+	}
+	return string(output), nil
+}
+
+// punycodeEncode converts each label of a domain name that contains
+// non-ASCII characters to its "xn--" form.
+func punycodeEncode(domain string) (string, error) {
+	labels := strings.Split(domain, ".")
+	for i, label := range labels {
+		if isASCII(label) {
+			continue
+		}
+		encoded, err := punyEncodeLabel(label)
+		if err != nil {
+			return "", err
+		}
+		labels[i] = punyPrefix + encoded
+	}
+	return strings.Join(labels, "."), nil
+}
+
+// punycodeDecode converts each "xn--" label of a domain name back to
+// Unicode.
+func punycodeDecode(domain string) (string, error) {
+	labels := strings.Split(domain, ".")
+	for i, label := range labels {
+		if len(label) < len(punyPrefix) || !strings.EqualFold(label[:len(punyPrefix)], punyPrefix) {
+			continue
+		}
+		decoded, err := punyDecodeLabel(label[len(punyPrefix):])
+		if err != nil {
+			return "", err
+		}
+		labels[i] = decoded
+	}
+	return strings.Join(labels, "."), nil
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
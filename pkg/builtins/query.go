@@ -0,0 +1,1005 @@
+package builtins
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+)
+
+// Query evaluates a jq-style path expression against nested maps and lists,
+// such as decoded JSON. Expressions that iterate with [] return a list of
+// every match; other expressions return a single value, or null if nothing
+// matched.
+func Query(ctx context.Context, args ...object.Object) (object.Object, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("query: expected 2 arguments, got %d", len(args))
+	}
+	src, err := object.AsString(args[1])
+	if err != nil {
+		return nil, err
+	}
+	expr, err := parseQuery(src)
+	if err != nil {
+		return nil, err
+	}
+	results, err := expr.eval(args[0])
+	if err != nil {
+		return nil, err
+	}
+	if streams(expr) {
+		return object.NewList(results), nil
+	}
+	if len(results) == 0 {
+		return object.Nil, nil
+	}
+	return results[0], nil
+}
+
+// queryNode is a parsed query expression. Evaluating a node against an
+// input produces zero or more outputs.
+type queryNode interface {
+	eval(in object.Object) ([]object.Object, error)
+}
+
+type (
+	identityNode struct{}
+	fieldNode    struct{ name string }
+	indexNode    struct{ index int }
+	sliceNode    struct{ start, end *int }
+	iterateNode  struct{}
+	literalNode  struct{ value object.Object }
+	pipeNode     struct{ left, right queryNode }
+	collectNode  struct{ inner queryNode }
+	compareNode  struct {
+		op          string
+		left, right queryNode
+	}
+	logicNode struct {
+		and         bool
+		left, right queryNode
+	}
+	callNode struct {
+		name string
+		args []queryNode
+	}
+)
+
+// streams reports whether an expression can produce more than one output,
+// in which case Query returns its outputs as a list.
+func streams(n queryNode) bool {
+	switch n := n.(type) {
+	case iterateNode:
+		return true
+	case pipeNode:
+		return streams(n.left) || streams(n.right)
+	case compareNode:
+		return streams(n.left) || streams(n.right)
+	case logicNode:
+		return streams(n.left) || streams(n.right)
+	}
+	return false
+}
+
+func (identityNode) eval(in object.Object) ([]object.Object, error) {
+	return []object.Object{in}, nil
+}
+
+func (n fieldNode) eval(in object.Object) ([]object.Object, error) {
+	switch in := in.(type) {
+	case *object.Map:
+		return []object.Object{in.Get(n.name)}, nil
+	case *object.NilType:
+		return []object.Object{object.Nil}, nil
+	case *object.List:
+		return nil, object.TypeErrorf("query: cannot get field %q of a list (use [] to iterate)", n.name)
+	}
+	return nil, object.TypeErrorf("query: cannot get field %q of %s", n.name, in.Type())
+}
+
+func (n indexNode) eval(in object.Object) ([]object.Object, error) {
+	switch in := in.(type) {
+	case *object.List:
+		items := in.Value()
+		i := n.index
+		if i < 0 {
+			i += len(items)
+		}
+		if i < 0 || i >= len(items) {
+			return []object.Object{object.Nil}, nil
+		}
+		return []object.Object{items[i]}, nil
+	case *object.NilType:
+		return []object.Object{object.Nil}, nil
+	}
+	return nil, object.TypeErrorf("query: cannot index %s", in.Type())
+}
+
+func (n sliceNode) eval(in object.Object) ([]object.Object, error) {
+	bounds := func(size int) (int, int) {
+		clamp := func(p *int, def int) int {
+			if p == nil {
+				return def
+			}
+			i := *p
+			if i < 0 {
+				i += size
+			}
+			return max(0, min(i, size))
+		}
+		start, end := clamp(n.start, 0), clamp(n.end, size)
+		return start, max(start, end)
+	}
+	switch in := in.(type) {
+	case *object.List:
+		items := in.Value()
+		start, end := bounds(len(items))
+		return []object.Object{object.NewList(append([]object.Object{}, items[start:end]...))}, nil
+	case *object.String:
+		runes := []rune(in.Value())
+		start, end := bounds(len(runes))
+		return []object.Object{object.NewString(string(runes[start:end]))}, nil
+	case *object.NilType:
+		return []object.Object{object.Nil}, nil
+	}
+	return nil, object.TypeErrorf("query: cannot slice %s", in.Type())
+}
+
+func (iterateNode) eval(in object.Object) ([]object.Object, error) {
+	return queryItems(in, "iterate over")
+}
+
+func (n literalNode) eval(in object.Object) ([]object.Object, error) {
+	return []object.Object{n.value}, nil
+}
+
+func (n pipeNode) eval(in object.Object) ([]object.Object, error) {
+	left, err := n.left.eval(in)
+	if err != nil {
+		return nil, err
+	}
+	var results []object.Object
+	for _, item := range left {
+		right, err := n.right.eval(item)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, right...)
+	}
+	return results, nil
+}
+
+func (n collectNode) eval(in object.Object) ([]object.Object, error) {
+	if n.inner == nil {
+		return []object.Object{object.NewList(nil)}, nil
+	}
+	items, err := n.inner.eval(in)
+	if err != nil {
+		return nil, err
+	}
+	return []object.Object{object.NewList(items)}, nil
+}
+
+func (n compareNode) eval(in object.Object) ([]object.Object, error) {
+	left, err := n.left.eval(in)
+	if err != nil {
+		return nil, err
+	}
+	right, err := n.right.eval(in)
+	if err != nil {
+		return nil, err
+	}
+	var results []object.Object
+	for _, a := range left {
+		for _, b := range right {
+			var result bool
+			switch n.op {
+			case "==":
+				result = a.Equals(b)
+			case "!=":
+				result = !a.Equals(b)
+			default:
+				cmp, err := queryCompare(a, b)
+				if err != nil {
+					return nil, err
+				}
+				switch n.op {
+				case "<":
+					result = cmp < 0
+				case "<=":
+					result = cmp <= 0
+				case ">":
+					result = cmp > 0
+				case ">=":
+					result = cmp >= 0
+				}
+			}
+			results = append(results, object.NewBool(result))
+		}
+	}
+	return results, nil
+}
+
+func (n logicNode) eval(in object.Object) ([]object.Object, error) {
+	left, err := n.left.eval(in)
+	if err != nil {
+		return nil, err
+	}
+	var results []object.Object
+	for _, a := range left {
+		// Short-circuit: "false and x" is false and "true or x" is true
+		if queryTruthy(a) != n.and {
+			results = append(results, object.NewBool(!n.and))
+			continue
+		}
+		right, err := n.right.eval(in)
+		if err != nil {
+			return nil, err
+		}
+		for _, b := range right {
+			results = append(results, object.NewBool(queryTruthy(b)))
+		}
+	}
+	return results, nil
+}
+
+// queryTruthy follows jq, where only false and null are false. This keeps
+// select(.count) from dropping items whose count is zero.
+func queryTruthy(obj object.Object) bool {
+	return obj != object.Nil && obj != object.False
+}
+
+func queryCompare(a, b object.Object) (int, error) {
+	if a == object.Nil || b == object.Nil {
+		if a == b {
+			return 0, nil
+		}
+		if a == object.Nil {
+			return -1, nil
+		}
+		return 1, nil
+	}
+	comparable, ok := a.(object.Comparable)
+	if !ok {
+		return 0, object.TypeErrorf("query: cannot compare %s and %s", a.Type(), b.Type())
+	}
+	cmp, err := comparable.Compare(b)
+	if err != nil {
+		return 0, object.TypeErrorf("query: cannot compare %s and %s", a.Type(), b.Type())
+	}
+	return cmp, nil
+}
+
+// queryItems returns the elements of a list or the values of a map ordered
+// by key. Null has no items, so iterating a missing field yields nothing.
+func queryItems(in object.Object, action string) ([]object.Object, error) {
+	switch in := in.(type) {
+	case *object.List:
+		return in.Value(), nil
+	case *object.Map:
+		return in.Values().Value(), nil
+	case *object.NilType:
+		return nil, nil
+	}
+	return nil, object.TypeErrorf("query: cannot %s %s", action, in.Type())
+}
+
+func queryList(name string, in object.Object) ([]object.Object, error) {
+	switch in := in.(type) {
+	case *object.List:
+		return in.Value(), nil
+	case *object.NilType:
+		return nil, nil
+	}
+	return nil, object.TypeErrorf("query: %s() expected a list (%s given)", name, in.Type())
+}
+
+func querySort(name string, items []object.Object, keys []object.Object) ([]object.Object, error) {
+	order := make([]int, len(items))
+	for i := range order {
+		order[i] = i
+	}
+	var sortErr error
+	sort.SliceStable(order, func(i, j int) bool {
+		cmp, err := queryCompare(keys[order[i]], keys[order[j]])
+		if err != nil && sortErr == nil {
+			sortErr = fmt.Errorf("%s(): %w", name, err)
+		}
+		return cmp < 0
+	})
+	if sortErr != nil {
+		return nil, sortErr
+	}
+	sorted := make([]object.Object, len(items))
+	for i, idx := range order {
+		sorted[i] = items[idx]
+	}
+	return sorted, nil
+}
+
+// queryFuncs maps function names to the number of arguments they take.
+var queryFuncs = map[string]int{
+	"contains":   1,
+	"endswith":   1,
+	"first":      0,
+	"flatten":    0,
+	"has":        1,
+	"join":       1,
+	"keys":       0,
+	"last":       0,
+	"length":     0,
+	"map":        1,
+	"max":        0,
+	"min":        0,
+	"not":        0,
+	"reverse":    0,
+	"select":     1,
+	"sort":       0,
+	"sort_by":    1,
+	"startswith": 1,
+	"sum":        0,
+	"type":       0,
+	"unique":     0,
+	"values":     0,
+}
+
+// arg evaluates the function argument at index i against the input and
+// returns its first output.
+func (n callNode) arg(in object.Object, i int) (object.Object, error) {
+	results, err := n.args[i].eval(in)
+	if err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return object.Nil, nil
+	}
+	return results[0], nil
+}
+
+func (n callNode) stringArg(in object.Object, i int) (string, error) {
+	arg, err := n.arg(in, i)
+	if err != nil {
+		return "", err
+	}
+	s, ok := arg.(*object.String)
+	if !ok {
+		return "", object.TypeErrorf("query: %s() expected a string argument (%s given)", n.name, arg.Type())
+	}
+	return s.Value(), nil
+}
+
+func (n callNode) eval(in object.Object) ([]object.Object, error) {
+	one := func(obj object.Object) ([]object.Object, error) {
+		return []object.Object{obj}, nil
+	}
+	switch n.name {
+	case "select":
+		results, err := n.args[0].eval(in)
+		if err != nil {
+			return nil, err
+		}
+		for _, result := range results {
+			if queryTruthy(result) {
+				return one(in)
+			}
+		}
+		return nil, nil
+	case "map":
+		items, err := queryItems(in, "map over")
+		if err != nil {
+			return nil, err
+		}
+		mapped := []object.Object{}
+		for _, item := range items {
+			results, err := n.args[0].eval(item)
+			if err != nil {
+				return nil, err
+			}
+			mapped = append(mapped, results...)
+		}
+		return one(object.NewList(mapped))
+	case "sort", "sort_by", "unique":
+		items, err := queryList(n.name, in)
+		if err != nil {
+			return nil, err
+		}
+		keys := items
+		if n.name == "sort_by" {
+			keys = make([]object.Object, len(items))
+			for i, item := range items {
+				if keys[i], err = n.arg(item, 0); err != nil {
+					return nil, err
+				}
+			}
+		}
+		sorted, err := querySort(n.name, items, keys)
+		if err != nil {
+			return nil, err
+		}
+		if n.name == "unique" {
+			var deduped []object.Object
+			for _, item := range sorted {
+				if len(deduped) == 0 || !deduped[len(deduped)-1].Equals(item) {
+					deduped = append(deduped, item)
+				}
+			}
+			sorted = deduped
+		}
+		return one(object.NewList(sorted))
+	case "length":
+		switch in := in.(type) {
+		case *object.NilType:
+			return one(object.NewInt(0))
+		case object.Container:
+			return one(in.Len())
+		}
+		return nil, object.TypeErrorf("query: length() unsupported input (%s given)", in.Type())
+	case "keys":
+		switch in := in.(type) {
+		case *object.Map:
+			return one(in.Keys())
+		case *object.List:
+			indexes := make([]object.Object, len(in.Value()))
+			for i := range indexes {
+				indexes[i] = object.NewInt(int64(i))
+			}
+			return one(object.NewList(indexes))
+		}
+		return nil, object.TypeErrorf("query: keys() expected a map or list (%s given)", in.Type())
+	case "values":
+		items, err := queryItems(in, "get values of")
+		if err != nil {
+			return nil, err
+		}
+		return one(object.NewList(items))
+	case "reverse":
+		switch in := in.(type) {
+		case *object.String:
+			return one(in.Reversed())
+		}
+		items, err := queryList(n.name, in)
+		if err != nil {
+			return nil, err
+		}
+		reversed := make([]object.Object, len(items))
+		for i, item := range items {
+			reversed[len(items)-1-i] = item
+		}
+		return one(object.NewList(reversed))
+	case "first", "last":
+		items, err := queryList(n.name, in)
+		if err != nil {
+			return nil, err
+		}
+		if len(items) == 0 {
+			return one(object.Nil)
+		}
+		if n.name == "first" {
+			return one(items[0])
+		}
+		return one(items[len(items)-1])
+	case "min", "max":
+		items, err := queryList(n.name, in)
+		if err != nil {
+			return nil, err
+		}
+		if len(items) == 0 {
+			return one(object.Nil)
+		}
+		best := items[0]
+		for _, item := range items[1:] {
+			cmp, err := queryCompare(item, best)
+			if err != nil {
+				return nil, err
+			}
+			if (n.name == "min" && cmp < 0) || (n.name == "max" && cmp > 0) {
+				best = item
+			}
+		}
+		return one(best)
+	case "sum":
+		items, err := queryList(n.name, in)
+		if err != nil {
+			return nil, err
+		}
+		var intSum int64
+		var floatSum float64
+		isFloat := false
+		for _, item := range items {
+			switch item := item.(type) {
+			case *object.Int:
+				intSum += item.Value()
+			case *object.Float:
+				floatSum += item.Value()
+				isFloat = true
+			default:
+				return nil, object.TypeErrorf("query: sum() expected numbers (%s given)", item.Type())
+			}
+		}
+		if isFloat {
+			return one(object.NewFloat(floatSum + float64(intSum)))
+		}
+		return one(object.NewInt(intSum))
+	case "flatten":
+		items, err := queryList(n.name, in)
+		if err != nil {
+			return nil, err
+		}
+		flat := []object.Object{}
+		for _, item := range items {
+			if list, ok := item.(*object.List); ok {
+				flat = append(flat, list.Value()...)
+			} else {
+				flat = append(flat, item)
+			}
+		}
+		return one(object.NewList(flat))
+	case "not":
+		return one(object.NewBool(!queryTruthy(in)))
+	case "type":
+		return one(object.NewString(string(in.Type())))
+	case "startswith", "endswith":
+		affix, err := n.stringArg(in, 0)
+		if err != nil {
+			return nil, err
+		}
+		s, ok := in.(*object.String)
+		if !ok {
+			return one(object.False)
+		}
+		if n.name == "startswith" {
+			return one(object.NewBool(strings.HasPrefix(s.Value(), affix)))
+		}
+		return one(object.NewBool(strings.HasSuffix(s.Value(), affix)))
+	case "contains":
+		arg, err := n.arg(in, 0)
+		if err != nil {
+			return nil, err
+		}
+		switch in := in.(type) {
+		case *object.String:
+			sub, ok := arg.(*object.String)
+			if !ok {
+				return nil, object.TypeErrorf("query: contains() expected a string argument (%s given)", arg.Type())
+			}
+			return one(object.NewBool(strings.Contains(in.Value(), sub.Value())))
+		case *object.List:
+			for _, item := range in.Value() {
+				if item.Equals(arg) {
+					return one(object.True)
+				}
+			}
+			return one(object.False)
+		case *object.Map:
+			return one(in.Contains(arg))
+		case *object.NilType:
+			return one(object.False)
+		}
+		return nil, object.TypeErrorf("query: contains() unsupported input (%s given)", in.Type())
+	case "has":
+		arg, err := n.arg(in, 0)
+		if err != nil {
+			return nil, err
+		}
+		switch in := in.(type) {
+		case *object.Map:
+			return one(in.Contains(arg))
+		case *object.List:
+			i, ok := arg.(*object.Int)
+			return one(object.NewBool(ok && i.Value() >= 0 && i.Value() < int64(len(in.Value()))))
+		}
+		return nil, object.TypeErrorf("query: has() expected a map or list (%s given)", in.Type())
+	case "join":
+		sep, err := n.stringArg(in, 0)
+		if err != nil {
+			return nil, err
+		}
+		items, err := queryList(n.name, in)
+		if err != nil {
+			return nil, err
+		}
+		parts := make([]string, len(items))
+		for i, item := range items {
+			switch item := item.(type) {
+			case *object.String:
+				parts[i] = item.Value()
+			case *object.NilType:
+			default:
+				parts[i] = item.Inspect()
+			}
+		}
+		return one(object.NewString(strings.Join(parts, sep)))
+	}
+	return nil, fmt.Errorf("query: unknown function %s()", n.name)
+}
+
+// Parsing
+
+type queryToken struct {
+	kind string // "ident", "string", "int", "float", "op", or "eof"
+	text string
+	pos  int
+}
+
+func lexQuery(src string) ([]queryToken, error) {
+	var tokens []queryToken
+	runes := []rune(src)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '_' || unicode.IsLetter(r):
+			start := i
+			for i < len(runes) && (runes[i] == '_' || unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i])) {
+				i++
+			}
+			tokens = append(tokens, queryToken{"ident", string(runes[start:i]), start})
+		case unicode.IsDigit(r):
+			start := i
+			kind := "int"
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				if runes[i] == '.' {
+					// A dot not followed by a digit belongs to a path
+					if kind == "float" || i+1 >= len(runes) || !unicode.IsDigit(runes[i+1]) {
+						break
+					}
+					kind = "float"
+				}
+				i++
+			}
+			tokens = append(tokens, queryToken{kind, string(runes[start:i]), start})
+		case r == '"' || r == '\'':
+			start := i
+			var sb strings.Builder
+			i++
+			for {
+				if i >= len(runes) {
+					return nil, object.ValueErrorf("query: unterminated string at position %d", start)
+				}
+				c := runes[i]
+				if c == r {
+					i++
+					break
+				}
+				if c == '\\' && i+1 < len(runes) {
+					i++
+					switch runes[i] {
+					case 'n':
+						c = '\n'
+					case 't':
+						c = '\t'
+					default:
+						c = runes[i]
+					}
+				}
+				sb.WriteRune(c)
+				i++
+			}
+			tokens = append(tokens, queryToken{"string", sb.String(), start})
+		default:
+			op := string(r)
+			if i+1 < len(runes) {
+				switch two := string(runes[i : i+2]); two {
+				case "==", "!=", "<=", ">=":
+					op = two
+				}
+			}
+			if !strings.Contains(".[]()|,:<>-", op) && len(op) == 1 {
+				return nil, object.ValueErrorf("query: unexpected character %q at position %d", r, i)
+			}
+			tokens = append(tokens, queryToken{"op", op, i})
+			i += len(op)
+		}
+	}
+	tokens = append(tokens, queryToken{"eof", "", len(runes)})
+	return tokens, nil
+}
+
+type queryParser struct {
+	tokens []queryToken
+	pos    int
+}
+
+func parseQuery(src string) (queryNode, error) {
+	tokens, err := lexQuery(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &queryParser{tokens: tokens}
+	node, err := p.parsePipe()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != "eof" {
+		return nil, p.unexpected(tok)
+	}
+	return node, nil
+}
+
+func (p *queryParser) peek() queryToken {
+	return p.tokens[p.pos]
+}
+
+func (p *queryParser) next() queryToken {
+	tok := p.tokens[p.pos]
+	if tok.kind != "eof" {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *queryParser) isOp(text string) bool {
+	tok := p.peek()
+	return tok.kind == "op" && tok.text == text
+}
+
+func (p *queryParser) expect(text string) error {
+	if !p.isOp(text) {
+		return p.unexpected(p.peek())
+	}
+	p.next()
+	return nil
+}
+
+func (p *queryParser) unexpected(tok queryToken) error {
+	if tok.kind == "eof" {
+		return object.ValueErrorf("query: unexpected end of expression")
+	}
+	return object.ValueErrorf("query: unexpected %q at position %d", tok.text, tok.pos)
+}
+
+func (p *queryParser) parsePipe() (queryNode, error) {
+	left, err := p.parseLogic(false)
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("|") {
+		p.next()
+		right, err := p.parseLogic(false)
+		if err != nil {
+			return nil, err
+		}
+		left = pipeNode{left, right}
+	}
+	return left, nil
+}
+
+// parseLogic parses "or" expressions, or "and" expressions when and is
+// true, so that "and" binds more tightly.
+func (p *queryParser) parseLogic(and bool) (queryNode, error) {
+	keyword := "or"
+	operand := func() (queryNode, error) { return p.parseLogic(true) }
+	if and {
+		keyword = "and"
+		operand = p.parseComparison
+	}
+	left, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	for tok := p.peek(); tok.kind == "ident" && tok.text == keyword; tok = p.peek() {
+		p.next()
+		right, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		left = logicNode{and, left, right}
+	}
+	return left, nil
+}
+
+func (p *queryParser) parseComparison() (queryNode, error) {
+	left, err := p.parsePostfix()
+	if err != nil {
+		return nil, err
+	}
+	tok := p.peek()
+	switch tok.text {
+	case "==", "!=", "<", "<=", ">", ">=":
+		if tok.kind != "op" {
+			return left, nil
+		}
+		p.next()
+		right, err := p.parsePostfix()
+		if err != nil {
+			return nil, err
+		}
+		return compareNode{tok.text, left, right}, nil
+	}
+	return left, nil
+}
+
+func (p *queryParser) parsePostfix() (queryNode, error) {
+	node, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		switch {
+		case p.isOp("."):
+			p.next()
+			if p.isOp("[") {
+				continue
+			}
+			name, err := p.parseFieldName()
+			if err != nil {
+				return nil, err
+			}
+			node = pipeNode{node, fieldNode{name}}
+		case p.isOp("["):
+			suffix, err := p.parseBracket()
+			if err != nil {
+				return nil, err
+			}
+			node = pipeNode{node, suffix}
+		default:
+			return node, nil
+		}
+	}
+}
+
+func (p *queryParser) parseFieldName() (string, error) {
+	tok := p.next()
+	if tok.kind != "ident" && tok.kind != "string" {
+		return "", p.unexpected(tok)
+	}
+	return tok.text, nil
+}
+
+// parseBracket parses [], [n], [a:b], or ["key"] following a value.
+func (p *queryParser) parseBracket() (queryNode, error) {
+	if err := p.expect("["); err != nil {
+		return nil, err
+	}
+	if p.isOp("]") {
+		p.next()
+		return iterateNode{}, nil
+	}
+	if tok := p.peek(); tok.kind == "string" {
+		p.next()
+		return fieldNode{tok.text}, p.expect("]")
+	}
+	start, err := p.parseOptionalInt()
+	if err != nil {
+		return nil, err
+	}
+	if p.isOp(":") {
+		p.next()
+		end, err := p.parseOptionalInt()
+		if err != nil {
+			return nil, err
+		}
+		return sliceNode{start, end}, p.expect("]")
+	}
+	if start == nil {
+		return nil, p.unexpected(p.peek())
+	}
+	return indexNode{*start}, p.expect("]")
+}
+
+func (p *queryParser) parseOptionalInt() (*int, error) {
+	negative := p.isOp("-")
+	if negative {
+		p.next()
+	}
+	tok := p.peek()
+	if tok.kind != "int" {
+		if negative {
+			return nil, p.unexpected(tok)
+		}
+		return nil, nil
+	}
+	p.next()
+	i, err := strconv.Atoi(tok.text)
+	if err != nil {
+		return nil, object.ValueErrorf("query: invalid index %q", tok.text)
+	}
+	if negative {
+		i = -i
+	}
+	return &i, nil
+}
+
+func (p *queryParser) parsePrimary() (queryNode, error) {
+	tok := p.next()
+	switch tok.kind {
+	case "string":
+		return literalNode{object.NewString(tok.text)}, nil
+	case "int", "float":
+		return parseQueryNumber(tok, false)
+	case "ident":
+		switch tok.text {
+		case "true":
+			return literalNode{object.True}, nil
+		case "false":
+			return literalNode{object.False}, nil
+		case "null":
+			return literalNode{object.Nil}, nil
+		}
+		if arity, ok := queryFuncs[tok.text]; ok && (arity == 0 || p.isOp("(")) {
+			return p.parseCall(tok.text, arity)
+		}
+		// A leading name is a field of the input, as in "items[].name"
+		return fieldNode{tok.text}, nil
+	case "op":
+		switch tok.text {
+		case ".":
+			if next := p.peek(); next.kind == "ident" || next.kind == "string" {
+				p.next()
+				return fieldNode{next.text}, nil
+			}
+			return identityNode{}, nil
+		case "-":
+			num := p.next()
+			if num.kind != "int" && num.kind != "float" {
+				return nil, p.unexpected(num)
+			}
+			return parseQueryNumber(num, true)
+		case "(":
+			node, err := p.parsePipe()
+			if err != nil {
+				return nil, err
+			}
+			return node, p.expect(")")
+		case "[":
+			if p.isOp("]") {
+				p.next()
+				return collectNode{}, nil
+			}
+			node, err := p.parsePipe()
+			if err != nil {
+				return nil, err
+			}
+			return collectNode{node}, p.expect("]")
+		}
+	}
+	return nil, p.unexpected(tok)
+}
+
+func (p *queryParser) parseCall(name string, arity int) (queryNode, error) {
+	var args []queryNode
+	if p.isOp("(") {
+		p.next()
+		for !p.isOp(")") {
+			if len(args) > 0 {
+				if err := p.expect(","); err != nil {
+					return nil, err
+				}
+			}
+			arg, err := p.parsePipe()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+		}
+		p.next()
+	}
+	if len(args) != arity {
+		return nil, object.ValueErrorf("query: %s() takes %d argument(s), got %d", name, arity, len(args))
+	}
+	return callNode{name, args}, nil
+}
+
+func parseQueryNumber(tok queryToken, negative bool) (queryNode, error) {
+	text := tok.text
+	if negative {
+		text = "-" + text
+	}
+	if tok.kind == "int" {
+		i, err := strconv.ParseInt(text, 10, 64)
+		if err != nil {
+			return nil, object.ValueErrorf("query: invalid number %q", text)
+		}
+		return literalNode{object.NewInt(i)}, nil
+	}
+	f, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return nil, object.ValueErrorf("query: invalid number %q", text)
+	}
+	return literalNode{object.NewFloat(f)}, nil
+}
@@ -0,0 +1,103 @@
+package builtins
+
+import (
+	"context"
+	"testing"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+const queryTestJSON = `{
+	"count": 3,
+	"next": null,
+	"items": [
+		{"name": "alpha", "size": 3, "active": true, "tags": ["x", "y"]},
+		{"name": "bravo", "size": 1, "active": false, "tags": []},
+		{"name": "apple", "size": 2, "active": true, "tags": ["y"], "owner": {"id": "u1"}}
+	]
+}`
+
+func queryTestData(t *testing.T) object.Object {
+	t.Helper()
+	data, err := Decode(context.Background(), object.NewString(queryTestJSON), object.NewString("json"))
+	assert.Nil(t, err)
+	return data
+}
+
+func TestQuery(t *testing.T) {
+	data := queryTestData(t)
+	tests := []struct {
+		expr     string
+		expected string
+	}{
+		{".", data.Inspect()},
+		{"count", "3"},
+		{".count", "3"},
+		{"next", "null"},
+		{"missing.deeper", "null"},
+		{"items[0].name", `"alpha"`},
+		{"items[-1].name", `"apple"`},
+		{"items[9]", "null"},
+		{`items[2]["owner"].id`, `"u1"`},
+		{"items[].name", `["alpha", "bravo", "apple"]`},
+		{"items[].owner.id", `[null, null, "u1"]`},
+		{"items[].tags[]", `["x", "y", "y"]`},
+		{"items[1:] | length", "2"},
+		{"items[:1][].name", `["alpha"]`},
+		{"items[].name | select(startswith('a'))", `["alpha", "apple"]`},
+		{`items[] | select(.active and .size > 2) | .name`, `["alpha"]`},
+		{`items[] | select(.active | not) | .name`, `["bravo"]`},
+		{`items[] | select(.tags | contains("y")) | .name`, `["alpha", "apple"]`},
+		{`items[] | select(has("owner")) | .name`, `["apple"]`},
+		{`items[] | select(.name == "nope")`, `[]`},
+		{`[items[].size] | sum`, "6"},
+		{`[items[].size] | max`, "3"},
+		{`[items[].tags[]] | unique`, `["x", "y"]`},
+		{`items | map(.name) | sort | join(", ")`, `"alpha, apple, bravo"`},
+		{`items | sort_by(.size) | first | .name`, `"bravo"`},
+		{`items | map(.tags) | flatten | length`, "3"},
+		{`items[0] | keys`, `["active", "name", "size", "tags"]`},
+		{`items[0].name | type`, `"string"`},
+		{`items[0].name[1:3]`, `"lp"`},
+		{`items | reverse | last | .name`, `"alpha"`},
+		{`count >= 3 or missing`, "true"},
+		{`[]`, "[]"},
+		{`-1`, "-1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			result, err := Query(context.Background(), data, object.NewString(tt.expr))
+			assert.Nil(t, err)
+			assert.Equal(t, result.Inspect(), tt.expected)
+		})
+	}
+}
+
+func TestQueryErrors(t *testing.T) {
+	data := queryTestData(t)
+	tests := []struct {
+		expr     string
+		expected string
+	}{
+		{"items.name", `cannot get field "name" of a list (use [] to iterate)`},
+		{"count[]", "cannot iterate over float"},
+		{"items[", "unexpected end of expression"},
+		{"items ]", `unexpected "]" at position 6`},
+		{"items[] | select()", "select() takes 1 argument(s), got 0"},
+		{`"abc`, "unterminated string"},
+		{"count ! 3", "unexpected character '!'"},
+		{"items[] | .name < 1", "cannot compare string and int"},
+		{"[count, next] | sum", "unexpected \",\""},
+		{"items | map(.size) | join(1)", "join() expected a string argument (int given)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := Query(context.Background(), data, object.NewString(tt.expr))
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expected)
+		})
+	}
+	_, err := Query(context.Background(), data)
+	assert.Error(t, err)
+}
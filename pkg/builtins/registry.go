@@ -48,6 +48,14 @@ var registry = []Entry{
 		Returns: "bool",
 		Example: "bool(1)",
 	},
+	{
+		Name:    "buffer",
+		Fn:      Buffer,
+		Doc:     "Create a buffer for building large strings efficiently",
+		Args:    []string{"initial?"},
+		Returns: "buffer",
+		Example: "let out = buffer(); out.writeln(\"header\"); out.string()",
+	},
 	{
 		Name:    "byte",
 		Fn:      Byte,
@@ -96,6 +104,14 @@ var registry = []Entry{
 		Returns: "any",
 		Example: "decode(\"json\", '{\"a\": 1}')",
 	},
+	{
+		Name:    "dir",
+		Fn:      Dir,
+		Doc:     "List the attribute and method names of a value",
+		Args:    []string{"obj"},
+		Returns: "list",
+		Example: "dir(\"hello\")",
+	},
 	{
 		Name:    "encode",
 		Fn:      Encode,
@@ -107,7 +123,7 @@ var registry = []Entry{
 	{
 		Name:    "error",
 		Fn:      Error,
-		Doc:     "Create an error value (does not throw)",
+		Doc:     "Create an error value (does not throw); a trailing map not used by the format sets the error's code and data",
 		Args:    []string{"message", "args..."},
 		Returns: "error",
 		Example: "error(\"file %s not found\", name)",
@@ -128,6 +144,14 @@ var registry = []Entry{
 		Returns: "float",
 		Example: "float(\"3.14\")",
 	},
+	{
+		Name:    "fnmatch",
+		Fn:      Fnmatch,
+		Doc:     "Match a name, or filter a list of names, against a glob pattern",
+		Args:    []string{"pattern", "names"},
+		Returns: "bool|list",
+		Example: "fnmatch(\"src/**/*.{go,rs}\", paths)",
+	},
 	{
 		Name:    "getattr",
 		Fn:      GetAttr,
@@ -136,6 +160,14 @@ var registry = []Entry{
 		Returns: "any",
 		Example: "getattr(obj, \"name\", \"unknown\")",
 	},
+	{
+		Name:    "inspect",
+		Fn:      Inspect,
+		Doc:     "Return a string representation with optional depth, items, pretty, and width settings",
+		Args:    []string{"value", "options?"},
+		Returns: "string",
+		Example: "inspect(data, {depth: 2, pretty: true})",
+	},
 	{
 		Name:    "int",
 		Fn:      Int,
@@ -144,6 +176,14 @@ var registry = []Entry{
 		Returns: "int",
 		Example: "int(\"42\")",
 	},
+	{
+		Name:    "iter",
+		Fn:      Iter,
+		Doc:     "Create a lazy iterator over an enumerable",
+		Args:    []string{"enumerable"},
+		Returns: "iter",
+		Example: "iter(range(1000000)).map(x => x * 2).take(3).collect()",
+	},
 	{
 		Name:    "keys",
 		Fn:      Keys,
@@ -168,6 +208,14 @@ var registry = []Entry{
 		Returns: "list",
 		Example: "list(range(5))",
 	},
+	{
+		Name:    "query",
+		Fn:      Query,
+		Doc:     "Extract values from nested maps and lists with a jq-style path",
+		Args:    []string{"data", "expr"},
+		Returns: "any",
+		Example: "query(resp, \"items[] | select(.active) | .name\")",
+	},
 	{
 		Name:    "range",
 		Fn:      Range,
@@ -184,6 +232,14 @@ var registry = []Entry{
 		Returns: "list|string",
 		Example: "reversed([1, 2, 3])",
 	},
+	{
+		Name:    "sizeof",
+		Fn:      SizeOf,
+		Doc:     "Estimate the memory in bytes held by a value",
+		Args:    []string{"value"},
+		Returns: "int",
+		Example: "sizeof([1, 2, 3])",
+	},
 	{
 		Name:    "sorted",
 		Fn:      Sorted,
@@ -208,6 +264,14 @@ var registry = []Entry{
 		Returns: "string",
 		Example: "string(123)",
 	},
+	{
+		Name:    "tuple",
+		Fn:      Tuple,
+		Doc:     "Convert enumerable to an immutable tuple",
+		Args:    []string{"enumerable?"},
+		Returns: "tuple",
+		Example: "tuple([1, 2])",
+	},
 	{
 		Name:    "type",
 		Fn:      Type,
@@ -43,6 +43,10 @@ type Code struct {
 	// environment at compile time (as opposed to globals defined in the
 	// script itself). Used for validation at run time.
 	envKeys []string
+
+	// envSchema maps env global names to the type names declared for them
+	// at compile time. Used to validate env values at run time.
+	envSchema map[string]string
 }
 
 // CodeParams contains parameters for creating a new Code.
@@ -63,7 +67,8 @@ type CodeParams struct {
 	GlobalCount  int
 	GlobalNames  []string
 	LocalNames   []string
-	EnvKeys      []string // Names of globals from compile-time env (for validation)
+	EnvKeys      []string          // Names of globals from compile-time env (for validation)
+	EnvSchema    map[string]string // Declared types of env globals (for validation)
 
 	ExceptionHandlers []ExceptionHandler
 }
@@ -97,6 +102,7 @@ func NewCode(params CodeParams) *Code {
 		globalNames:       copyStrings(params.GlobalNames),
 		localNames:        copyStrings(params.LocalNames),
 		envKeys:           copyStrings(params.EnvKeys),
+		envSchema:         copySchema(params.EnvSchema),
 		exceptionHandlers: copyHandlers(params.ExceptionHandlers),
 	}
 
@@ -328,6 +334,13 @@ func (c *Code) EnvKeys() []string {
 	return keys
 }
 
+// EnvSchema returns a copy of the env schema the code was compiled with,
+// mapping each declared env global to its type name. Returns nil if the
+// code was compiled without a schema.
+func (c *Code) EnvSchema() map[string]string {
+	return copySchema(c.envSchema)
+}
+
 // FunctionNames returns the names of all named functions in this code.
 // Anonymous functions are not included.
 func (c *Code) FunctionNames() []string {
@@ -1,6 +1,7 @@
 package bytecode
 
 import (
+	"slices"
 	"testing"
 
 	"github.com/deepnoodle-ai/risor/v2/pkg/op"
@@ -224,3 +225,56 @@ func TestLocationAt(t *testing.T) {
 		t.Errorf("expected zero location for 100, got {%d, %d}", loc.Line, loc.Column)
 	}
 }
+
+func TestCodeDependencies(t *testing.T) {
+	fnCode := NewCode(CodeParams{
+		ID:           "main.0",
+		Instructions: []op.Code{op.LoadGlobal, 1, op.StoreGlobal, 2, op.LoadGlobal, 0, op.ReturnValue},
+	})
+	fn := NewFunction(FunctionParams{ID: "f", Name: "f", Code: fnCode})
+	code := NewCode(CodeParams{
+		ID:   "main",
+		Name: "main",
+		Instructions: []op.Code{
+			op.LoadGlobal, 0, op.LoadAttr, 0, op.PopTop,
+			op.LoadConst, 0, op.StoreGlobal, 3,
+			op.LoadGlobal, 1, op.LoadAttrOrNil, 1, op.PopTop,
+		},
+		Constants:   []any{fn},
+		Names:       []string{"sqrt", "pi"},
+		GlobalNames: []string{"math", "len", "total", "f"},
+		GlobalCount: 4,
+		Children:    []*Code{fnCode},
+	})
+
+	deps := code.Dependencies()
+	if !slices.Equal(deps.Reads, []string{"len", "math"}) {
+		t.Errorf("expected reads [len math], got %v", deps.Reads)
+	}
+	if !slices.Equal(deps.Writes, []string{"f", "total"}) {
+		t.Errorf("expected writes [f total], got %v", deps.Writes)
+	}
+	if len(deps.Attributes) != 1 || !slices.Equal(deps.Attributes["math"], []string{"sqrt"}) {
+		t.Errorf("expected attributes {math: [sqrt]}, got %v", deps.Attributes)
+	}
+	// f returns math itself, and len is only read through an optional chain
+	if !slices.Equal(deps.Values, []string{"len", "math"}) {
+		t.Errorf("expected values [len math], got %v", deps.Values)
+	}
+	onlyAttrs := NewCode(CodeParams{
+		ID:           "attrs",
+		Instructions: []op.Code{op.LoadGlobal, 0, op.LoadAttr, 0, op.PopTop},
+		Names:        []string{"sqrt"},
+		GlobalNames:  []string{"math"},
+		GlobalCount:  1,
+	})
+	if values := onlyAttrs.Dependencies().Values; len(values) != 0 {
+		t.Errorf("expected no values, got %v", values)
+	}
+
+	// Nested code resolves global names against the root
+	nested := code.ChildAt(0).Dependencies()
+	if !slices.Equal(nested.Reads, []string{"len", "math"}) {
+		t.Errorf("expected nested reads [len math], got %v", nested.Reads)
+	}
+}
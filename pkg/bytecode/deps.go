@@ -0,0 +1,131 @@
+package bytecode
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/op"
+)
+
+// Dependencies describes how compiled code uses global variables, as found
+// by scanning the instructions of the code and of every function nested in
+// it. Hosts can use it to check a script against an allow-list before
+// running it.
+type Dependencies struct {
+	// Reads lists the globals the code reads, sorted by name.
+	Reads []string
+
+	// Writes lists the globals the code assigns, sorted by name.
+	Writes []string
+
+	// Attributes maps a global to the attributes the code loads from it
+	// directly, as in math.sqrt, sorted by name. Attributes loaded after
+	// the global passes through a local variable or an optional chain
+	// (math?.sqrt) are not included.
+	Attributes map[string][]string
+
+	// Values lists the globals the code reads other than to load an
+	// attribute directly, sorted by name: globals that are called, passed,
+	// assigned, or operated on, as in len(x) or let m = math. A global
+	// that is in Attributes but not in Values is only used through those
+	// attributes.
+	Values []string
+}
+
+// Dependencies returns the globals read and written by this code and every
+// function nested in it. Global indices are resolved against the root code,
+// so call it on the code returned by the compiler.
+func (c *Code) Dependencies() Dependencies {
+	root := c
+	for root.parent != nil {
+		root = root.parent
+	}
+	s := &depScanner{
+		root:   root,
+		seen:   map[*Code]bool{},
+		reads:  map[string]bool{},
+		writes: map[string]bool{},
+		attrs:  map[string]map[string]bool{},
+		values: map[string]bool{},
+	}
+	s.scan(c)
+
+	deps := Dependencies{
+		Reads:  slices.Sorted(maps.Keys(s.reads)),
+		Writes: slices.Sorted(maps.Keys(s.writes)),
+		Values: slices.Sorted(maps.Keys(s.values)),
+	}
+	if len(s.attrs) > 0 {
+		deps.Attributes = make(map[string][]string, len(s.attrs))
+		for name, attrs := range s.attrs {
+			deps.Attributes[name] = slices.Sorted(maps.Keys(attrs))
+		}
+	}
+	return deps
+}
+
+type depScanner struct {
+	root   *Code
+	seen   map[*Code]bool
+	reads  map[string]bool
+	writes map[string]bool
+	attrs  map[string]map[string]bool
+	values map[string]bool
+}
+
+func (s *depScanner) scan(code *Code) {
+	if code == nil || s.seen[code] {
+		return
+	}
+	s.seen[code] = true
+
+	instructions := code.instructions
+	lastRead := "" // global loaded by the previous instruction
+	for ip := 0; ip < len(instructions); {
+		opcode := instructions[ip]
+		count := op.GetInfo(opcode).OperandCount
+		if ip+count >= len(instructions) {
+			break
+		}
+		var operand op.Code
+		if count > 0 {
+			operand = instructions[ip+1]
+		}
+		read := ""
+		switch opcode {
+		case op.LoadGlobal:
+			if name := s.root.GlobalNameAt(int(operand)); name != "" {
+				s.reads[name] = true
+				read = name
+			}
+		case op.StoreGlobal:
+			if name := s.root.GlobalNameAt(int(operand)); name != "" {
+				s.writes[name] = true
+			}
+		}
+		if lastRead != "" {
+			if opcode == op.LoadAttr && int(operand) < len(code.names) {
+				if s.attrs[lastRead] == nil {
+					s.attrs[lastRead] = map[string]bool{}
+				}
+				s.attrs[lastRead][code.names[operand]] = true
+			} else {
+				s.values[lastRead] = true
+			}
+		}
+		lastRead = read
+		ip += 1 + count
+	}
+	if lastRead != "" {
+		s.values[lastRead] = true
+	}
+
+	for _, constant := range code.constants {
+		if fn, ok := constant.(*Function); ok && fn != nil {
+			s.scan(fn.code)
+		}
+	}
+	for _, child := range code.children {
+		s.scan(child)
+	}
+}
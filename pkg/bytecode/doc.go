@@ -32,6 +32,13 @@
 //	// code.Instructions() - does not exist
 //	// code.Constants() - does not exist
 //
+// # Verification
+//
+// Code produced by the compiler is always well-formed. Code loaded with
+// [Unmarshal] from an untrusted source should be checked with [Verify] before
+// it is executed, so that bad indices or jump targets are reported as errors
+// rather than reaching the VM.
+//
 // # Package Dependencies
 //
 // This package depends only on [github.com/deepnoodle-ai/risor/v2/pkg/op] to avoid
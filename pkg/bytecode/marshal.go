@@ -17,6 +17,9 @@ func Marshal(code *Code) ([]byte, error) {
 }
 
 // Unmarshal converts a JSON representation into a Code object.
+//
+// Unmarshal only checks that the data is structurally sound. Use [Verify]
+// before running code that came from an untrusted source.
 func Unmarshal(data []byte) (*Code, error) {
 	var state codeState
 	if err := json.Unmarshal(data, &state); err != nil {
@@ -99,6 +102,8 @@ type codeDef struct {
 	GlobalNames       []string              `json:"global_names,omitempty"`
 	LocalNames        []string              `json:"local_names,omitempty"`
 	ExceptionHandlers []exceptionHandlerDef `json:"exception_handlers,omitempty"`
+	EnvKeys           []string              `json:"env_keys,omitempty"`
+	EnvSchema         map[string]string     `json:"env_schema,omitempty"`
 }
 
 type codeState struct {
@@ -192,6 +197,8 @@ func stateFromCode(code *Code) (*codeState, error) {
 			GlobalNames:       globalNames,
 			LocalNames:        localNames,
 			ExceptionHandlers: handlers,
+			EnvKeys:           c.EnvKeys(),
+			EnvSchema:         c.EnvSchema(),
 		}
 	}
 
@@ -233,6 +240,10 @@ func codeFromState(state *codeState) (*Code, error) {
 		if len(def.ChildIndices) > 0 {
 			children = make([]*Code, len(def.ChildIndices))
 			for j, childIdx := range def.ChildIndices {
+				// Children always follow their parent in the flattened form
+				if childIdx <= i || childIdx >= len(codes) {
+					return nil, fmt.Errorf("code %d has invalid child index %d", i, childIdx)
+				}
 				children[j] = codes[childIdx]
 			}
 		}
@@ -262,9 +273,14 @@ func codeFromState(state *codeState) (*Code, error) {
 			GlobalNames:       def.GlobalNames,
 			LocalNames:        def.LocalNames,
 			ExceptionHandlers: handlers,
+			EnvKeys:           def.EnvKeys,
+			EnvSchema:         def.EnvSchema,
 		})
 	}
 
+	if len(codes) == 0 {
+		return nil, fmt.Errorf("no code found")
+	}
 	return codes[0], nil
 }
 
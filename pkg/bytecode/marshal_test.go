@@ -345,3 +345,31 @@ func TestMarshalUnmarshalEmptyLocalNames(t *testing.T) {
 		t.Errorf("expected 0 local names, got %v", restored.LocalNameCount())
 	}
 }
+
+func TestMarshalUnmarshalEnvSchema(t *testing.T) {
+	code := NewCode(CodeParams{
+		ID:           "test",
+		Instructions: []op.Code{op.LoadGlobal, 0, op.ReturnValue},
+		GlobalCount:  1,
+		GlobalNames:  []string{"limit"},
+		EnvKeys:      []string{"limit"},
+		EnvSchema:    map[string]string{"limit": "int"},
+	})
+
+	data, err := Marshal(code)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	restored, err := Unmarshal(data)
+	if err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	if keys := restored.EnvKeys(); len(keys) != 1 || keys[0] != "limit" {
+		t.Errorf("expected env keys [limit], got %v", keys)
+	}
+	if typ := restored.EnvSchema()["limit"]; typ != "int" {
+		t.Errorf("expected env schema type int, got %q", typ)
+	}
+}
@@ -0,0 +1,155 @@
+package bytecode
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/op"
+)
+
+// Metadata summarizes the structure of a code block and its children. It
+// leaves out instruction operands and constant values, so it is smaller and
+// easier to read than the output of Marshal, and it changes only when the
+// shape of the compiled code changes. Build pipelines can diff the metadata
+// of compiled artifacts between releases.
+type Metadata struct {
+	ID                    string         `json:"id"`
+	Name                  string         `json:"name,omitempty"`
+	Filename              string         `json:"filename,omitempty"`
+	InstructionCount      int            `json:"instruction_count"`
+	Opcodes               map[string]int `json:"opcodes,omitempty"`
+	ConstantKinds         []string       `json:"constant_kinds,omitempty"`
+	Names                 []string       `json:"names,omitempty"`
+	GlobalNames           []string       `json:"global_names,omitempty"`
+	LocalNames            []string       `json:"local_names,omitempty"`
+	LocalCount            int            `json:"local_count"`
+	GlobalCount           int            `json:"global_count"`
+	MaxCallArgs           int            `json:"max_call_args"`
+	ExceptionHandlerCount int            `json:"exception_handler_count,omitempty"`
+	SourceMap             []SourceSpan   `json:"source_map,omitempty"`
+	Children              []Metadata     `json:"children,omitempty"`
+}
+
+// SourceSpan maps a run of instructions, starting at IP and ending where the
+// next span starts, to the source position they were compiled from.
+type SourceSpan struct {
+	IP     int `json:"ip"`
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// Metadata returns the structural metadata of this code and its children.
+func (c *Code) Metadata() Metadata {
+	m := Metadata{
+		ID:                    c.id,
+		Name:                  c.name,
+		Filename:              c.filename,
+		Names:                 copyStrings(c.names),
+		GlobalNames:           copyStrings(c.globalNames),
+		LocalNames:            copyStrings(c.localNames),
+		LocalCount:            c.localCount,
+		GlobalCount:           c.globalCount,
+		MaxCallArgs:           c.maxCallArgs,
+		ExceptionHandlerCount: len(c.exceptionHandlers),
+	}
+
+	for ip := 0; ip < len(c.instructions); {
+		opcode := c.instructions[ip]
+		info := op.GetInfo(opcode)
+		name := info.Name
+		if name == "" {
+			name = fmt.Sprintf("OPCODE_%d", opcode)
+		}
+		if m.Opcodes == nil {
+			m.Opcodes = map[string]int{}
+		}
+		m.Opcodes[name]++
+		m.InstructionCount++
+		ip += 1 + info.OperandCount
+	}
+
+	for _, constant := range c.constants {
+		m.ConstantKinds = append(m.ConstantKinds, constantKind(constant))
+	}
+
+	var last SourceLocation
+	for ip, loc := range c.locations {
+		if loc.IsZero() || (loc.Line == last.Line && loc.Column == last.Column) {
+			continue
+		}
+		m.SourceMap = append(m.SourceMap, SourceSpan{IP: ip, Line: loc.Line, Column: loc.Column})
+		last = loc
+	}
+
+	for _, child := range c.children {
+		m.Children = append(m.Children, child.Metadata())
+	}
+	return m
+}
+
+// MetadataJSON returns the metadata of this code as indented JSON. The
+// output is deterministic, so it can be committed and compared with diff.
+func (c *Code) MetadataJSON() ([]byte, error) {
+	return json.MarshalIndent(c.Metadata(), "", "  ")
+}
+
+// String returns a human-readable summary of the code and its children.
+func (c *Code) String() string {
+	var b strings.Builder
+	c.writeSummary(&b, "")
+	return b.String()
+}
+
+func (c *Code) writeSummary(b *strings.Builder, indent string) {
+	m := c.Metadata()
+	name := m.Name
+	if name == "" {
+		name = m.ID
+	}
+	fmt.Fprintf(b, "%scode %s", indent, name)
+	if m.Filename != "" {
+		fmt.Fprintf(b, " (%s)", m.Filename)
+	}
+	b.WriteString("\n")
+	fmt.Fprintf(b, "%s  instructions: %d\n", indent, m.InstructionCount)
+	if len(m.ConstantKinds) > 0 {
+		fmt.Fprintf(b, "%s  constants: %d (%s)\n", indent, len(m.ConstantKinds), strings.Join(m.ConstantKinds, ", "))
+	}
+	if len(m.Names) > 0 {
+		fmt.Fprintf(b, "%s  names: %s\n", indent, strings.Join(m.Names, ", "))
+	}
+	if len(m.LocalNames) > 0 {
+		fmt.Fprintf(b, "%s  locals: %s\n", indent, strings.Join(m.LocalNames, ", "))
+	}
+	if len(m.GlobalNames) > 0 {
+		fmt.Fprintf(b, "%s  globals: %d\n", indent, len(m.GlobalNames))
+	}
+	if m.ExceptionHandlerCount > 0 {
+		fmt.Fprintf(b, "%s  exception handlers: %d\n", indent, m.ExceptionHandlerCount)
+	}
+	for _, child := range c.children {
+		child.writeSummary(b, indent+"  ")
+	}
+}
+
+// constantKind returns the name of a constant's type, matching the type
+// names used by Marshal.
+func constantKind(c any) string {
+	switch c.(type) {
+	case nil:
+		return "nil"
+	case bool:
+		return "bool"
+	case int, int64:
+		return "int"
+	case float32, float64:
+		return "float"
+	case string:
+		return "string"
+	case *Function:
+		return "function"
+	default:
+		return fmt.Sprintf("%T", c)
+	}
+}
@@ -0,0 +1,103 @@
+package bytecode
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/op"
+)
+
+func metadataTestCode() *Code {
+	childCode := NewCode(CodeParams{
+		ID:           "main.0",
+		Name:         "double",
+		Instructions: []op.Code{op.LoadFast, 0, op.LoadFast, 0, op.BinaryOp, op.Code(op.Add), op.ReturnValue},
+		LocalNames:   []string{"x"},
+		LocalCount:   1,
+		Locations:    []SourceLocation{{Line: 2, Column: 3}, {Line: 2, Column: 3}, {Line: 2, Column: 7}},
+	})
+	fn := NewFunction(FunctionParams{ID: "fn", Name: "double", Parameters: []string{"x"}, Code: childCode})
+	return NewCode(CodeParams{
+		ID:           "main",
+		Name:         "main",
+		Filename:     "double.risor",
+		Instructions: []op.Code{op.LoadConst, 0, op.StoreGlobal, 0, op.LoadGlobal, 0, op.LoadConst, 1, op.Call, 1, op.ReturnValue},
+		Constants:    []any{fn, 21},
+		GlobalNames:  []string{"double"},
+		GlobalCount:  1,
+		MaxCallArgs:  1,
+		Locations:    []SourceLocation{{Line: 1, Column: 1}, {}, {Line: 3, Column: 1}},
+		Children:     []*Code{childCode},
+	})
+}
+
+func TestCodeMetadata(t *testing.T) {
+	m := metadataTestCode().Metadata()
+
+	if m.InstructionCount != 6 {
+		t.Errorf("expected 6 instructions, got %d", m.InstructionCount)
+	}
+	if m.Opcodes["LOAD_CONST"] != 2 || m.Opcodes["CALL"] != 1 {
+		t.Errorf("unexpected opcode counts: %v", m.Opcodes)
+	}
+	if !slices.Equal(m.ConstantKinds, []string{"function", "int"}) {
+		t.Errorf("expected constant kinds [function int], got %v", m.ConstantKinds)
+	}
+	if len(m.SourceMap) != 2 || m.SourceMap[1] != (SourceSpan{IP: 2, Line: 3, Column: 1}) {
+		t.Errorf("unexpected source map: %v", m.SourceMap)
+	}
+	if len(m.Children) != 1 {
+		t.Fatalf("expected 1 child, got %d", len(m.Children))
+	}
+	child := m.Children[0]
+	if child.Name != "double" || child.InstructionCount != 4 || child.LocalCount != 1 {
+		t.Errorf("unexpected child metadata: %+v", child)
+	}
+	// Repeated locations are merged into one span
+	if len(child.SourceMap) != 2 {
+		t.Errorf("expected 2 child source spans, got %v", child.SourceMap)
+	}
+}
+
+func TestCodeMetadataJSON(t *testing.T) {
+	code := metadataTestCode()
+	data, err := code.MetadataJSON()
+	if err != nil {
+		t.Fatalf("MetadataJSON failed: %v", err)
+	}
+	again, err := code.MetadataJSON()
+	if err != nil || string(data) != string(again) {
+		t.Error("expected deterministic metadata JSON")
+	}
+
+	var decoded Metadata
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("invalid metadata JSON: %v", err)
+	}
+	if decoded.Filename != "double.risor" || decoded.Children[0].LocalNames[0] != "x" {
+		t.Errorf("unexpected decoded metadata: %+v", decoded)
+	}
+	if !strings.Contains(string(data), `"constant_kinds": [`) {
+		t.Errorf("expected snake_case keys in %s", data)
+	}
+}
+
+func TestCodeString(t *testing.T) {
+	str := metadataTestCode().String()
+	expected := []string{
+		"code main (double.risor)\n",
+		"  instructions: 6\n",
+		"  constants: 2 (function, int)\n",
+		"  globals: 1\n",
+		"  code double\n",
+		"    instructions: 4\n",
+		"    locals: x\n",
+	}
+	for _, want := range expected {
+		if !strings.Contains(str, want) {
+			t.Errorf("expected %q in:\n%s", want, str)
+		}
+	}
+}
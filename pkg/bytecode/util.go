@@ -12,6 +12,18 @@ func copyStrings(src []string) []string {
 	return dst
 }
 
+// copySchema returns a copy of the given env schema.
+func copySchema(src map[string]string) map[string]string {
+	if len(src) == 0 {
+		return nil
+	}
+	dst := make(map[string]string, len(src))
+	for k, v := range src {
+		dst[k] = v
+	}
+	return dst
+}
+
 // copyAny returns a copy of the given any slice.
 func copyAny(src []any) []any {
 	if src == nil {
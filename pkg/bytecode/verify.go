@@ -0,0 +1,337 @@
+package bytecode
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/op"
+)
+
+// VerifyError describes a single problem found while verifying bytecode.
+type VerifyError struct {
+	CodeID   string  // ID of the code block containing the problem
+	CodeName string  // name of the code block containing the problem
+	IP       int     // instruction index, or -1 if not tied to an instruction
+	Opcode   op.Code // opcode at IP (Invalid if not tied to an instruction)
+	Message  string  // description of the problem
+}
+
+// Error implements the error interface.
+func (e *VerifyError) Error() string {
+	name := e.CodeName
+	if name == "" {
+		name = e.CodeID
+	}
+	if e.IP < 0 {
+		return fmt.Sprintf("invalid bytecode in %q: %s", name, e.Message)
+	}
+	opName := fmt.Sprintf("opcode %d", e.Opcode)
+	if isKnownOpcode(e.Opcode) {
+		opName = op.GetInfo(e.Opcode).Name
+	}
+	return fmt.Sprintf("invalid bytecode in %q at %d (%s): %s", name, e.IP, opName, e.Message)
+}
+
+// VerifyErrors wraps all problems found while verifying bytecode.
+type VerifyErrors struct {
+	Errors []VerifyError
+}
+
+// Error implements the error interface.
+func (e *VerifyErrors) Error() string {
+	switch len(e.Errors) {
+	case 0:
+		return "no verification errors"
+	case 1:
+		return e.Errors[0].Error()
+	default:
+		var b strings.Builder
+		fmt.Fprintf(&b, "%d verification errors:\n", len(e.Errors))
+		for _, err := range e.Errors {
+			fmt.Fprintf(&b, "  - %s\n", err.Error())
+		}
+		return b.String()
+	}
+}
+
+// Unwrap returns the first error for errors.Is/As compatibility.
+func (e *VerifyErrors) Unwrap() error {
+	if len(e.Errors) > 0 {
+		return &e.Errors[0]
+	}
+	return nil
+}
+
+// Verify checks that code and all code reachable from it is well-formed
+// enough to be executed by the VM. It checks that every opcode is known and
+// has all of its operands, that constant, name, local, and global indices are
+// in range, that jumps and exception handlers target instruction boundaries
+// within the code block, that constants have supported types, and that the
+// names an enum is built from are string constants.
+//
+// The compiler always produces valid bytecode. Verify is intended for code
+// obtained from elsewhere, such as the output of [Unmarshal] on data that
+// may have been tampered with. It returns nil if no problems were found and
+// a *VerifyErrors otherwise.
+//
+// Verify does not simulate the operand stack, so it cannot rule out stack
+// underflow from a hand-crafted instruction sequence; the VM reports those
+// as runtime errors.
+func Verify(code *Code) error {
+	if code == nil {
+		return &VerifyErrors{Errors: []VerifyError{{IP: -1, Message: "code is nil"}}}
+	}
+	v := &verifier{root: code, seen: map[*Code]bool{}}
+	v.verifyCode(code)
+	if len(v.errs) > 0 {
+		return &VerifyErrors{Errors: v.errs}
+	}
+	return nil
+}
+
+type verifier struct {
+	root *Code
+	seen map[*Code]bool
+	errs []VerifyError
+}
+
+func (v *verifier) addError(code *Code, ip int, opcode op.Code, format string, args ...any) {
+	v.errs = append(v.errs, VerifyError{
+		CodeID:   code.id,
+		CodeName: code.name,
+		IP:       ip,
+		Opcode:   opcode,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+func (v *verifier) verifyCode(code *Code) {
+	if v.seen[code] {
+		return
+	}
+	v.seen[code] = true
+
+	starts := v.verifyInstructions(code)
+	v.verifyJumps(code, starts)
+	v.verifyHandlers(code, starts)
+	v.verifyConstants(code)
+
+	for i, child := range code.children {
+		if child == nil {
+			v.addError(code, -1, op.Invalid, "child %d is nil", i)
+			continue
+		}
+		v.verifyCode(child)
+	}
+}
+
+// verifyInstructions checks each opcode and its operands, returning the set
+// of instruction start positions. The returned slice has one extra entry so
+// that the end of the code is a valid jump target.
+func (v *verifier) verifyInstructions(code *Code) []bool {
+	instructions := code.instructions
+	starts := make([]bool, len(instructions)+1)
+	starts[len(instructions)] = true
+	var previous []int // Start positions of the instructions seen so far
+
+	for ip := 0; ip < len(instructions); {
+		opcode := instructions[ip]
+		starts[ip] = true
+		if !isKnownOpcode(opcode) {
+			v.addError(code, ip, opcode, "unknown opcode %d", opcode)
+			return starts
+		}
+		count := op.GetInfo(opcode).OperandCount
+		if ip+count >= len(instructions) {
+			v.addError(code, ip, opcode, "expected %d operand(s), found %d",
+				count, len(instructions)-ip-1)
+			return starts
+		}
+		operands := instructions[ip+1 : ip+1+count]
+		v.verifyOperands(code, ip, opcode, operands)
+		if opcode == op.BuildEnum {
+			v.verifyEnum(code, ip, int(operands[0]), previous)
+		}
+		previous = append(previous, ip)
+		ip += 1 + count
+	}
+	return starts
+}
+
+// verifyEnum checks that the enum name and member names a BuildEnum at ip
+// takes from the stack are loaded by the LoadConst instructions just before
+// it, and that each of them is a string constant.
+func (v *verifier) verifyEnum(code *Code, ip, count int, previous []int) {
+	if len(previous) < count+1 {
+		v.addError(code, ip, op.BuildEnum, "expected %d name constant(s) before the instruction", count+1)
+		return
+	}
+	for _, start := range previous[len(previous)-count-1:] {
+		if code.instructions[start] != op.LoadConst {
+			v.addError(code, ip, op.BuildEnum, "expected LoadConst at %d", start)
+			return
+		}
+		index := int(code.instructions[start+1])
+		if index >= len(code.constants) {
+			return // Already reported
+		}
+		if _, ok := code.constants[index].(string); !ok {
+			v.addError(code, ip, op.BuildEnum, "constant %d is not a string", index)
+			return
+		}
+	}
+}
+
+func (v *verifier) verifyOperands(code *Code, ip int, opcode op.Code, operands []op.Code) {
+	checkIndex := func(index op.Code, limit int, kind string) {
+		if int(index) >= limit {
+			v.addError(code, ip, opcode, "%s index %d out of range (%d available)", kind, index, limit)
+		}
+	}
+	switch opcode {
+	case op.LoadConst:
+		checkIndex(operands[0], len(code.constants), "constant")
+	case op.LoadClosure:
+		checkIndex(operands[0], len(code.constants), "constant")
+		if int(operands[0]) < len(code.constants) {
+			if _, ok := code.constants[operands[0]].(*Function); !ok {
+				v.addError(code, ip, opcode, "constant %d is not a function", operands[0])
+			}
+		}
+	case op.LoadAttr, op.LoadAttrOrNil, op.StoreAttr:
+		checkIndex(operands[0], len(code.names), "name")
+	case op.LoadFast, op.StoreFast:
+		checkIndex(operands[0], code.localCount, "local")
+	case op.LoadGlobal, op.StoreGlobal:
+		checkIndex(operands[0], v.root.globalCount, "global")
+	case op.MakeCell:
+		// The cell refers to a local of the code block framesBack levels up
+		// from the function being created here, starting with this one.
+		target := code
+		for i := 0; i < int(operands[1]) && target != nil; i++ {
+			target = target.parent
+		}
+		if target == nil {
+			v.addError(code, ip, opcode, "no enclosing code at depth %d", operands[1])
+			return
+		}
+		checkIndex(operands[0], target.localCount, "local")
+	case op.BinaryOp:
+		if op.BinaryOpType(operands[0]).String() == "" {
+			v.addError(code, ip, opcode, "unknown binary operation %d", operands[0])
+		}
+	case op.CompareOp:
+		if op.CompareOpType(operands[0]).String() == "" {
+			v.addError(code, ip, opcode, "unknown comparison operation %d", operands[0])
+		}
+	}
+}
+
+// verifyJumps checks that every jump lands on an instruction boundary.
+func (v *verifier) verifyJumps(code *Code, starts []bool) {
+	instructions := code.instructions
+	checkTarget := func(ip int, opcode op.Code, target int) {
+		if target < 0 || target >= len(starts) || !starts[target] {
+			v.addError(code, ip, opcode, "jump target %d is not an instruction boundary", target)
+		}
+	}
+	for ip := 0; ip < len(instructions); ip++ {
+		if !starts[ip] {
+			continue
+		}
+		opcode := instructions[ip]
+		if !isKnownOpcode(opcode) || ip+op.GetInfo(opcode).OperandCount >= len(instructions) {
+			break // Already reported
+		}
+		switch opcode {
+		case op.JumpForward,
+			op.PopJumpForwardIfTrue,
+			op.PopJumpForwardIfFalse,
+			op.PopJumpForwardIfNil,
+			op.PopJumpForwardIfNotNil:
+			checkTarget(ip, opcode, ip+int(instructions[ip+1]))
+		case op.JumpBackward:
+			checkTarget(ip, opcode, ip-int(instructions[ip+1]))
+		case op.PushExcept:
+			checkTarget(ip, opcode, ip+int(instructions[ip+1]))
+			if finally := int(instructions[ip+2]); finally != 0 {
+				checkTarget(ip, opcode, ip+finally)
+			}
+		}
+	}
+}
+
+// verifyHandlers checks exception handler ranges and catch variables.
+func (v *verifier) verifyHandlers(code *Code, starts []bool) {
+	// Catch variables are globals in the root code and locals elsewhere
+	varLimit, varKind := code.localCount, "local"
+	if code == v.root {
+		varLimit, varKind = code.globalCount, "global"
+	}
+	for i, h := range code.exceptionHandlers {
+		if h.TryStart < 0 || h.TryEnd < h.TryStart || h.TryEnd >= len(starts) {
+			v.addError(code, -1, op.Invalid, "exception handler %d has invalid range [%d, %d)",
+				i, h.TryStart, h.TryEnd)
+			continue
+		}
+		for _, target := range []struct {
+			name string
+			ip   int
+		}{
+			{"try", h.TryStart},
+			{"catch", h.CatchStart},
+			{"finally", h.FinallyStart},
+		} {
+			if target.ip == 0 && target.name != "try" {
+				continue
+			}
+			if target.ip < h.TryStart || target.ip > h.TryEnd || !starts[target.ip] {
+				v.addError(code, -1, op.Invalid, "exception handler %d has invalid %s start %d",
+					i, target.name, target.ip)
+			}
+		}
+		if h.CatchVarIdx < -1 || h.CatchVarIdx >= varLimit {
+			v.addError(code, -1, op.Invalid, "exception handler %d has %s index %d out of range (%d available)",
+				i, varKind, h.CatchVarIdx, varLimit)
+		}
+	}
+}
+
+// verifyConstants checks constant types and verifies the code of any
+// function constants.
+func (v *verifier) verifyConstants(code *Code) {
+	for i, constant := range code.constants {
+		fn, ok := constant.(*Function)
+		if !ok {
+			if !isScalarConstant(constant) {
+				v.addError(code, -1, op.Invalid, "constant %d has unsupported type %T", i, constant)
+			}
+			continue
+		}
+		if fn == nil || fn.code == nil {
+			v.addError(code, -1, op.Invalid, "function constant %d has no code", i)
+			continue
+		}
+		for j, d := range fn.defaults {
+			if !isScalarConstant(d) {
+				v.addError(code, -1, op.Invalid, "function constant %d has default %d of unsupported type %T",
+					i, j, d)
+			}
+		}
+		v.verifyCode(fn.code)
+	}
+}
+
+// isKnownOpcode reports whether the opcode is defined. The opcode table
+// only covers values below 256, so larger values must not be looked up.
+func isKnownOpcode(opcode op.Code) bool {
+	return int(opcode) < 256 && op.GetInfo(opcode).Name != ""
+}
+
+func isScalarConstant(c any) bool {
+	switch c.(type) {
+	case nil, bool, int, int64, float64, string:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,303 @@
+package bytecode
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/op"
+)
+
+func TestVerifyValidCode(t *testing.T) {
+	child := NewCode(CodeParams{
+		ID:           "child",
+		Name:         "add",
+		Instructions: []op.Code{op.LoadFast, 0, op.LoadFast, 1, op.BinaryOp, op.Code(op.Add), op.ReturnValue},
+		LocalCount:   2,
+	})
+	fn := NewFunction(FunctionParams{ID: "fn", Name: "add", Parameters: []string{"a", "b"}, Code: child})
+	root := NewCode(CodeParams{
+		ID:   "root",
+		Name: "main",
+		Instructions: []op.Code{
+			op.LoadConst, 0, // 0
+			op.StoreGlobal, 0, // 2
+			op.True,                     // 4
+			op.PopJumpForwardIfFalse, 6, // 5
+			op.LoadConst, 1, // 7
+			op.JumpForward, 4, // 9
+			op.LoadConst, 2, // 11
+			op.LoadConst, 2, // 13
+			op.BuildEnum, 1, // 15
+			op.ReturnValue, // 17
+		},
+		Constants:   []any{fn, int64(1), "two"},
+		GlobalCount: 1,
+		GlobalNames: []string{"add"},
+		Children:    []*Code{child},
+	})
+	if err := Verify(root); err != nil {
+		t.Fatalf("expected valid code, got: %v", err)
+	}
+}
+
+func TestVerifyInvalidCode(t *testing.T) {
+	tests := []struct {
+		name   string
+		params CodeParams
+		errMsg string
+	}{
+		{
+			name:   "unknown opcode",
+			params: CodeParams{Instructions: []op.Code{op.Nop, 199}},
+			errMsg: "unknown opcode 199",
+		},
+		{
+			name:   "opcode out of table range",
+			params: CodeParams{Instructions: []op.Code{1000}},
+			errMsg: "unknown opcode 1000",
+		},
+		{
+			name:   "missing operand",
+			params: CodeParams{Instructions: []op.Code{op.LoadConst}},
+			errMsg: "expected 1 operand(s), found 0",
+		},
+		{
+			name:   "truncated jump",
+			params: CodeParams{Instructions: []op.Code{op.Nop, op.JumpForward}},
+			errMsg: "expected 1 operand(s), found 0",
+		},
+		{
+			name:   "constant index",
+			params: CodeParams{Instructions: []op.Code{op.LoadConst, 1}, Constants: []any{int64(1)}},
+			errMsg: "constant index 1 out of range (1 available)",
+		},
+		{
+			name:   "name index",
+			params: CodeParams{Instructions: []op.Code{op.Nil, op.LoadAttr, 0}},
+			errMsg: "name index 0 out of range (0 available)",
+		},
+		{
+			name:   "local index",
+			params: CodeParams{Instructions: []op.Code{op.LoadFast, 2}, LocalCount: 2},
+			errMsg: "local index 2 out of range (2 available)",
+		},
+		{
+			name:   "global index",
+			params: CodeParams{Instructions: []op.Code{op.LoadGlobal, 3}, GlobalCount: 1},
+			errMsg: "global index 3 out of range (1 available)",
+		},
+		{
+			name:   "closure constant not a function",
+			params: CodeParams{Instructions: []op.Code{op.LoadClosure, 0, 0}, Constants: []any{"x"}},
+			errMsg: "constant 0 is not a function",
+		},
+		{
+			name:   "make cell depth",
+			params: CodeParams{Instructions: []op.Code{op.MakeCell, 0, 1}, LocalCount: 1},
+			errMsg: "no enclosing code at depth 1",
+		},
+		{
+			name:   "binary op type",
+			params: CodeParams{Instructions: []op.Code{op.Nil, op.Nil, op.BinaryOp, 99}},
+			errMsg: "unknown binary operation 99",
+		},
+		{
+			name:   "compare op type",
+			params: CodeParams{Instructions: []op.Code{op.Nil, op.Nil, op.CompareOp, 0}},
+			errMsg: "unknown comparison operation 0",
+		},
+		{
+			name:   "forward jump past end",
+			params: CodeParams{Instructions: []op.Code{op.JumpForward, 10, op.Nil}},
+			errMsg: "jump target 10 is not an instruction boundary",
+		},
+		{
+			name:   "forward jump into operand",
+			params: CodeParams{Instructions: []op.Code{op.JumpForward, 3, op.LoadConst, 0}, Constants: []any{nil}},
+			errMsg: "jump target 3 is not an instruction boundary",
+		},
+		{
+			name:   "backward jump before start",
+			params: CodeParams{Instructions: []op.Code{op.Nop, op.JumpBackward, 5}},
+			errMsg: "jump target -4 is not an instruction boundary",
+		},
+		{
+			name:   "conditional jump",
+			params: CodeParams{Instructions: []op.Code{op.True, op.PopJumpForwardIfTrue, 7}},
+			errMsg: "jump target 8 is not an instruction boundary",
+		},
+		{
+			name:   "push except target",
+			params: CodeParams{Instructions: []op.Code{op.PushExcept, 1, 0}},
+			errMsg: "jump target 1 is not an instruction boundary",
+		},
+		{
+			name: "handler range",
+			params: CodeParams{
+				Instructions:      []op.Code{op.Nop},
+				ExceptionHandlers: []ExceptionHandler{{TryStart: 0, TryEnd: 5, CatchVarIdx: -1}},
+			},
+			errMsg: "exception handler 0 has invalid range [0, 5)",
+		},
+		{
+			name: "handler catch start",
+			params: CodeParams{
+				Instructions:      []op.Code{op.LoadConst, 0, op.Nop},
+				Constants:         []any{nil},
+				ExceptionHandlers: []ExceptionHandler{{TryStart: 0, TryEnd: 3, CatchStart: 1, CatchVarIdx: -1}},
+			},
+			errMsg: "exception handler 0 has invalid catch start 1",
+		},
+		{
+			name: "handler catch variable",
+			params: CodeParams{
+				Instructions:      []op.Code{op.Nop, op.Nop},
+				ExceptionHandlers: []ExceptionHandler{{TryStart: 0, TryEnd: 2, CatchStart: 1, CatchVarIdx: 0}},
+			},
+			errMsg: "exception handler 0 has global index 0 out of range (0 available)",
+		},
+		{
+			name:   "constant type",
+			params: CodeParams{Instructions: []op.Code{op.Nop}, Constants: []any{[]int{1}}},
+			errMsg: "constant 0 has unsupported type []int",
+		},
+		{
+			name: "function without code",
+			params: CodeParams{
+				Instructions: []op.Code{op.Nop},
+				Constants:    []any{NewFunction(FunctionParams{Name: "f"})},
+			},
+			errMsg: "function constant 0 has no code",
+		},
+		{
+			name: "enum name is not a string",
+			params: CodeParams{
+				Instructions: []op.Code{op.LoadConst, 0, op.LoadConst, 1, op.BuildEnum, 1},
+				Constants:    []any{int64(1), "Red"},
+			},
+			errMsg: "constant 0 is not a string",
+		},
+		{
+			name: "enum names not loaded as constants",
+			params: CodeParams{
+				Instructions: []op.Code{op.Nil, op.LoadConst, 0, op.BuildEnum, 1},
+				Constants:    []any{"Red"},
+			},
+			errMsg: "expected LoadConst at 0",
+		},
+		{
+			name: "enum names missing",
+			params: CodeParams{
+				Instructions: []op.Code{op.LoadConst, 0, op.BuildEnum, 1},
+				Constants:    []any{"Color"},
+			},
+			errMsg: "expected 2 name constant(s) before the instruction",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.params.Name = "main"
+			err := Verify(NewCode(tt.params))
+			if err == nil {
+				t.Fatalf("expected error containing %q, got nil", tt.errMsg)
+			}
+			if !strings.Contains(err.Error(), tt.errMsg) {
+				t.Fatalf("expected error containing %q, got %q", tt.errMsg, err.Error())
+			}
+		})
+	}
+}
+
+func TestVerifyChildCode(t *testing.T) {
+	child := NewCode(CodeParams{
+		ID:           "child",
+		Name:         "f",
+		Instructions: []op.Code{op.LoadFast, 0, op.ReturnValue},
+	})
+	fn := NewFunction(FunctionParams{ID: "fn", Name: "f", Code: child})
+	root := NewCode(CodeParams{
+		ID:           "root",
+		Name:         "main",
+		Instructions: []op.Code{op.LoadConst, 0},
+		Constants:    []any{fn},
+		Children:     []*Code{child},
+	})
+
+	err := Verify(root)
+	var verr *VerifyErrors
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected *VerifyErrors, got %T: %v", err, err)
+	}
+	if len(verr.Errors) != 1 {
+		t.Fatalf("expected 1 error, got %d: %v", len(verr.Errors), err)
+	}
+	got := verr.Errors[0]
+	if got.CodeID != "child" || got.IP != 0 || got.Opcode != op.LoadFast {
+		t.Errorf("unexpected error details: %+v", got)
+	}
+	if got.Error() != `invalid bytecode in "f" at 0 (LOAD_FAST): local index 0 out of range (0 available)` {
+		t.Errorf("unexpected error message: %s", got.Error())
+	}
+}
+
+func TestVerifyCollectsAllErrors(t *testing.T) {
+	code := NewCode(CodeParams{
+		Name:         "main",
+		Instructions: []op.Code{op.LoadConst, 0, op.LoadGlobal, 0, op.JumpForward, 9},
+	})
+	err := Verify(code)
+	var verr *VerifyErrors
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected *VerifyErrors, got %T: %v", err, err)
+	}
+	if len(verr.Errors) != 3 {
+		t.Fatalf("expected 3 errors, got %d: %v", len(verr.Errors), err)
+	}
+	if !strings.HasPrefix(err.Error(), "3 verification errors:") {
+		t.Errorf("unexpected error message: %s", err.Error())
+	}
+	var first *VerifyError
+	if !errors.As(err, &first) || first.IP != 0 {
+		t.Errorf("expected first error at IP 0, got %v", first)
+	}
+}
+
+func TestVerifyNil(t *testing.T) {
+	if err := Verify(nil); err == nil {
+		t.Fatal("expected error for nil code")
+	}
+}
+
+func TestVerifyUnmarshaledCode(t *testing.T) {
+	code := NewCode(CodeParams{
+		ID:           "root",
+		Name:         "main",
+		Instructions: []op.Code{op.LoadConst, 0, op.ReturnValue},
+		Constants:    []any{int64(7)},
+	})
+	data, err := Marshal(code)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	restored, err := Unmarshal(data)
+	if err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if err := Verify(restored); err != nil {
+		t.Fatalf("expected valid code, got: %v", err)
+	}
+
+	// Tamper with the constant index
+	tampered := strings.Replace(string(data), `"instructions":[24,0,4]`, `"instructions":[24,5,4]`, 1)
+	if tampered == string(data) {
+		t.Fatal("failed to tamper with serialized code")
+	}
+	restored, err = Unmarshal([]byte(tampered))
+	if err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if err := Verify(restored); err == nil {
+		t.Fatal("expected verification error for tampered code")
+	}
+}
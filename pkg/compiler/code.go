@@ -51,6 +51,9 @@ type Code struct {
 	// Only set on root code. Used for validation at run time.
 	envKeys []string
 
+	// envSchema maps env global names to their declared type names.
+	envSchema map[string]string
+
 	// Used during compilation only
 	pipeActive bool
 }
@@ -388,6 +391,7 @@ func (c *Code) toBytecodeWithMap(codeMap map[*Code]*bytecode.Code) *bytecode.Cod
 		GlobalNames:       c.GlobalNames(),
 		LocalNames:        c.LocalNames(),
 		EnvKeys:           c.envKeys,
+		EnvSchema:         c.envSchema,
 		ExceptionHandlers: handlers,
 	})
 
@@ -49,7 +49,9 @@ package compiler
 
 import (
 	"fmt"
+	"maps"
 	"math"
+	"slices"
 	"sort"
 	"strings"
 
@@ -57,6 +59,7 @@ import (
 	"github.com/deepnoodle-ai/risor/v2/pkg/ast"
 	"github.com/deepnoodle-ai/risor/v2/pkg/bytecode"
 	"github.com/deepnoodle-ai/risor/v2/pkg/errors"
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
 	"github.com/deepnoodle-ai/risor/v2/pkg/op"
 )
 
@@ -89,6 +92,9 @@ type Compiler struct {
 	// Names of globals to be available during compilation
 	globalNames []string
 
+	// Declared types of env globals, used for usage checks
+	envSchema map[string]string
+
 	// Increments with each function compiled
 	funcIndex int
 
@@ -100,6 +106,49 @@ type Compiler struct {
 
 	// Current AST node being compiled (used for source map tracking)
 	currentNode ast.Node
+
+	// Controls the value of blocks whose last statement is not an expression
+	resultMode ResultMode
+
+	// Set when the next declaration or assignment must leave the value it
+	// stores on the stack (see takeStatementValue)
+	keepValue bool
+}
+
+// ResultMode controls the value that programs, blocks, and function bodies
+// evaluate to when their last statement is not a plain expression.
+type ResultMode uint8
+
+const (
+	// ResultDefault evaluates a block to the value of its last statement if
+	// that statement is an expression, including if, match, and try, and to
+	// nil otherwise. Declarations and assignments evaluate to nil.
+	ResultDefault ResultMode = iota
+
+	// ResultLastValue makes declarations and assignments evaluate to the
+	// value they store, so that a block always evaluates to the value of its
+	// last statement. For example, "let x = 1; x += 2" evaluates to 3.
+	ResultLastValue
+
+	// ResultNil makes if, match, and try evaluate to nil when they are the
+	// last statement of a program, so that a program evaluates to a value
+	// only when it ends with a plain expression. These constructs still
+	// produce values when used within expressions, as in "let x = if ...".
+	ResultNil
+)
+
+// String returns the name of the result mode.
+func (m ResultMode) String() string {
+	switch m {
+	case ResultDefault:
+		return "default"
+	case ResultLastValue:
+		return "last-value"
+	case ResultNil:
+		return "nil"
+	default:
+		return fmt.Sprintf("ResultMode(%d)", m)
+	}
 }
 
 // Config holds compiler configuration options.
@@ -114,6 +163,19 @@ type Config struct {
 	// Source is the original source code, used for better error messages.
 	Source string
 
+	// EnvSchema declares the type of each env global, mapping the global's
+	// name to a Risor type name such as "int", "string", or "function".
+	// Names in the schema are added to GlobalNames. Usages of schema-typed
+	// globals are checked during compilation: calling a non-callable value
+	// or accessing an attribute the type does not have is a compile error.
+	// Use the type name "any" to declare a global without a type.
+	EnvSchema map[string]string
+
+	// ResultMode controls the value of programs and blocks whose last
+	// statement is a declaration, an assignment, or a control flow
+	// expression. The zero value is ResultDefault.
+	ResultMode ResultMode
+
 	// Code is an existing code object to compile into. This is used for
 	// REPL-style incremental compilation where state must be preserved.
 	// If nil, a new code object is created.
@@ -141,9 +203,19 @@ func New(cfg *Config) (*Compiler, error) {
 	if cfg != nil {
 		c.globalNames = make([]string, len(cfg.GlobalNames))
 		copy(c.globalNames, cfg.GlobalNames) // isolate from caller
+		c.envSchema = copySchema(cfg.EnvSchema)
+		for name := range c.envSchema {
+			if !slices.Contains(c.globalNames, name) {
+				c.globalNames = append(c.globalNames, name)
+			}
+		}
 		c.filename = cfg.Filename
 		c.source = cfg.Source
 		c.main = cfg.Code
+		c.resultMode = cfg.ResultMode
+		if c.resultMode > ResultNil {
+			return nil, fmt.Errorf("invalid result mode: %s", c.resultMode)
+		}
 	}
 	// Create a default, empty code object to compile into if the caller didn't
 	// supply one. If the caller did supply one, it may be a situation like the
@@ -168,6 +240,7 @@ func New(cfg *Config) (*Compiler, error) {
 	// Store the env keys on the main code for later validation
 	c.main.envKeys = make([]string, len(c.globalNames))
 	copy(c.main.envKeys, c.globalNames)
+	c.main.envSchema = copySchema(c.envSchema)
 	// Start compiling into the main code object
 	c.current = c.main
 	return c, nil
@@ -201,6 +274,7 @@ func (c *Compiler) CompileAST(node ast.Node) (*Code, error) {
 		c.funcIndex = funcIdx
 		c.current = c.main
 		c.failure = nil
+		c.keepValue = false
 	}
 
 	// Use original source if available (better error messages with actual code),
@@ -340,6 +414,10 @@ func (c *Compiler) compile(node ast.Node) error {
 		if err := c.compileList(node); err != nil {
 			return err
 		}
+	case *ast.Tuple:
+		if err := c.compileTuple(node); err != nil {
+			return err
+		}
 	case *ast.Map:
 		if err := c.compileMap(node); err != nil {
 			return err
@@ -372,6 +450,10 @@ func (c *Compiler) compile(node ast.Node) error {
 		if err := c.compileConst(node); err != nil {
 			return err
 		}
+	case *ast.Enum:
+		if err := c.compileEnum(node); err != nil {
+			return err
+		}
 	case *ast.Postfix:
 		if err := c.compilePostfix(node); err != nil {
 			return err
@@ -416,6 +498,12 @@ func (c *Compiler) compile(node ast.Node) error {
 		return c.formatError("syntax error in expression", node.Pos())
 	case *ast.BadStmt:
 		return c.formatError("syntax error in statement", node.Pos())
+	case *ast.Spread:
+		// Spreads are compiled by the calls, lists, and maps that hold them
+		return c.formatError("spread syntax is only allowed in calls, lists, and maps", node.Pos())
+	case *ast.DefaultValue:
+		// Defaults are compiled by the destructuring patterns that hold them
+		return c.formatError("default values are only allowed in destructuring patterns", node.Pos())
 	default:
 		panic(fmt.Sprintf("compile error: unknown ast node type: %T", node))
 	}
@@ -462,21 +550,17 @@ func (c *Compiler) compileProgram(node *ast.Program) error {
 	if count == 0 {
 		// Guarantee that the program evaluates to a value
 		c.emit(op.Nil)
-	} else {
-		for i, stmt := range statements {
-			if err := c.compile(stmt); err != nil {
-				return err
-			}
-			if i < count-1 {
-				if isExpr(stmt) {
-					c.emit(op.PopTop)
-				}
-			}
+		return nil
+	}
+	for i, stmt := range statements {
+		if i == count-1 {
+			return c.compileLastStatement(stmt, true)
 		}
-		// Guarantee that the program evaluates to a value
-		lastStatement := statements[count-1]
-		if !isExpr(lastStatement) {
-			c.emit(op.Nil)
+		if err := c.compile(stmt); err != nil {
+			return err
+		}
+		if isExpr(stmt) {
+			c.emit(op.PopTop)
 		}
 	}
 	return nil
@@ -493,54 +577,111 @@ func (c *Compiler) compileBlock(node *ast.Block) error {
 	if count == 0 {
 		// Guarantee that the block evaluates to a value
 		c.emit(op.Nil)
-	} else {
-		for i, stmt := range statements {
-			if err := c.compile(stmt); err != nil {
-				return err
-			}
-			if i < count-1 {
-				if isExpr(stmt) {
-					c.emit(op.PopTop)
-				}
-			}
+		return nil
+	}
+	for i, stmt := range statements {
+		if i == count-1 {
+			return c.compileLastStatement(stmt, false)
 		}
-		// Guarantee that the block evaluates to a value
-		lastStatement := statements[count-1]
-		if !isExpr(lastStatement) {
-			c.emit(op.Nil)
+		if err := c.compile(stmt); err != nil {
+			return err
 		}
+		if isExpr(stmt) {
+			c.emit(op.PopTop)
+		}
+	}
+	return nil
+}
+
+// compileLastStatement compiles the last statement of a program or block,
+// leaving the value the program or block evaluates to on the stack, as
+// determined by the result mode.
+func (c *Compiler) compileLastStatement(stmt ast.Node, isProgram bool) error {
+	if c.resultMode == ResultLastValue && storesValue(stmt) {
+		c.keepValue = true
+		return c.compile(stmt)
+	}
+	if err := c.compile(stmt); err != nil {
+		return err
+	}
+	if c.resultMode == ResultNil && isProgram && isControlFlow(stmt) {
+		c.emit(op.PopTop)
+		c.emit(op.Nil)
+	} else if !isExpr(stmt) {
+		// Guarantee that the program or block evaluates to a value
+		c.emit(op.Nil)
 	}
 	return nil
 }
 
+// storesValue returns true if the statement is a declaration or assignment,
+// which evaluates to the value it stores in ResultLastValue mode.
+func storesValue(node ast.Node) bool {
+	switch node.(type) {
+	case *ast.Var, *ast.Const, *ast.Enum, *ast.MultiVar, *ast.ObjectDestructure,
+		*ast.ArrayDestructure, *ast.Assign, *ast.SetAttr, *ast.Postfix:
+		return true
+	}
+	return false
+}
+
+// isControlFlow returns true if the node is an if, match, or try expression.
+func isControlFlow(node ast.Node) bool {
+	switch node.(type) {
+	case *ast.If, *ast.Match, *ast.Try:
+		return true
+	}
+	return false
+}
+
+// takeStatementValue returns true if the declaration or assignment being
+// compiled must leave the value it stores on the stack. It clears the request
+// so that statements nested in the value expression are unaffected, so it
+// must be called before compiling any child nodes.
+func (c *Compiler) takeStatementValue() bool {
+	keep := c.keepValue
+	c.keepValue = false
+	return keep
+}
+
 func (c *Compiler) compileFunctionBlock(node *ast.Block) error {
 	code := c.current
 	code.symbols = code.symbols.NewBlock()
 	defer func() {
 		code.symbols = code.symbols.parent
 	}()
-	statements := normalizeFunctionBlock(node)
+	statements := normalizeFunctionBlock(node, c.resultMode == ResultLastValue)
 	count := len(statements)
 	for i, stmt := range statements {
+		// In ResultLastValue mode, a trailing declaration or assignment
+		// returns the value it stores
+		returnStored := i == count-1 && storesValue(stmt)
+		if returnStored {
+			c.keepValue = true
+		}
 		if err := c.compile(stmt); err != nil {
 			return err
 		}
-		if i < count-1 {
-			if isExpr(stmt) {
-				c.emit(op.PopTop)
-			}
+		if returnStored {
+			c.emit(op.ReturnValue)
+		} else if i < count-1 && isExpr(stmt) {
+			c.emit(op.PopTop)
 		}
 	}
 	return nil
 }
 
 func (c *Compiler) compileVar(node *ast.Var) error {
+	keep := c.takeStatementValue()
 	name := node.Name.Name
 	expr := node.Value
 	if err := c.compile(expr); err != nil {
 		return err
 	}
-	sym, err := c.current.symbols.InsertVariable(name)
+	if keep {
+		c.emit(op.Copy, 0)
+	}
+	sym, err := c.insertVariable(c.current.symbols, name, node.Name.Pos())
 	if err != nil {
 		return err
 	}
@@ -572,6 +713,7 @@ func (c *Compiler) compileIdent(node *ast.Ident) error {
 }
 
 func (c *Compiler) compileMultiVar(node *ast.MultiVar) error {
+	keep := c.takeStatementValue()
 	names := node.Names
 	expr := node.Value
 	if len(names) > math.MaxUint16 {
@@ -581,12 +723,15 @@ func (c *Compiler) compileMultiVar(node *ast.MultiVar) error {
 	if err := c.compile(expr); err != nil {
 		return err
 	}
+	if keep {
+		c.emit(op.Copy, 0)
+	}
 	// Emit the Unpack opcode to unpack the tuple-like object onto the stack
 	c.emit(op.Unpack, uint16(len(names)))
 	// Iterate through the names in reverse order and declare the variables
 	for i := len(names) - 1; i >= 0; i-- {
 		name := names[i].Name
-		sym, err := c.current.symbols.InsertVariable(name)
+		sym, err := c.insertVariable(c.current.symbols, name, names[i].Pos())
 		if err != nil {
 			return err
 		}
@@ -605,6 +750,7 @@ func (c *Compiler) compileMultiVar(node *ast.MultiVar) error {
 }
 
 func (c *Compiler) compileObjectDestructure(node *ast.ObjectDestructure) error {
+	keep := c.takeStatementValue()
 	bindings := node.Bindings
 	if len(bindings) > math.MaxUint16 {
 		return c.formatError("too many bindings in object destructuring", node.Pos())
@@ -652,7 +798,7 @@ func (c *Compiler) compileObjectDestructure(node *ast.ObjectDestructure) error {
 		}
 
 		// Insert the variable and store the value
-		sym, err := c.current.symbols.InsertVariable(varName)
+		sym, err := c.insertVariable(c.current.symbols, varName, node.Pos())
 		if err != nil {
 			return err
 		}
@@ -668,13 +814,17 @@ func (c *Compiler) compileObjectDestructure(node *ast.ObjectDestructure) error {
 		}
 	}
 
-	// Pop the remaining object from the stack
-	c.emit(op.PopTop)
+	// Pop the remaining object from the stack, unless it is the value of
+	// the statement
+	if !keep {
+		c.emit(op.PopTop)
+	}
 
 	return nil
 }
 
 func (c *Compiler) compileArrayDestructure(node *ast.ArrayDestructure) error {
+	keep := c.takeStatementValue()
 	elements := node.Elements
 	if len(elements) > math.MaxUint16 {
 		return c.formatError("too many elements in array destructuring", node.Pos())
@@ -693,6 +843,9 @@ func (c *Compiler) compileArrayDestructure(node *ast.ArrayDestructure) error {
 	if err := c.compile(node.Value); err != nil {
 		return err
 	}
+	if keep {
+		c.emit(op.Copy, 0)
+	}
 
 	// Emit the Unpack opcode to unpack the array onto the stack
 	c.emit(op.Unpack, uint16(len(elements)))
@@ -720,7 +873,7 @@ func (c *Compiler) compileArrayDestructure(node *ast.ArrayDestructure) error {
 			c.changeOperand(jumpPos, delta)
 		}
 
-		sym, err := c.current.symbols.InsertVariable(varName)
+		sym, err := c.insertVariable(c.current.symbols, varName, element.Name.Pos())
 		if err != nil {
 			return err
 		}
@@ -1079,6 +1232,7 @@ func (c *Compiler) compilePipe(node *ast.Pipe) error {
 }
 
 func (c *Compiler) compilePostfix(node *ast.Postfix) error {
+	keep := c.takeStatementValue()
 	// Determine the increment/decrement amount
 	var amount int64
 	switch node.Op {
@@ -1104,6 +1258,9 @@ func (c *Compiler) compilePostfix(node *ast.Postfix) error {
 		c.emit(op.LoadConst, c.constant(amount))
 		// Add
 		c.emit(op.BinaryOp, uint16(op.Add))
+		if keep {
+			c.emit(op.Copy, 0)
+		}
 		// Store back
 		c.emitStore(resolution)
 
@@ -1120,6 +1277,9 @@ func (c *Compiler) compilePostfix(node *ast.Postfix) error {
 		// 2. Add the increment amount
 		c.emit(op.LoadConst, c.constant(amount))
 		c.emit(op.BinaryOp, uint16(op.Add))
+		if keep {
+			c.emit(op.Copy, 0)
+		}
 		// 3. Store back
 		if err := c.compile(x.X); err != nil {
 			return err
@@ -1140,6 +1300,9 @@ func (c *Compiler) compilePostfix(node *ast.Postfix) error {
 		// 2. Add the increment amount
 		c.emit(op.LoadConst, c.constant(amount))
 		c.emit(op.BinaryOp, uint16(op.Add))
+		if keep {
+			c.emit(op.Copy, 0)
+		}
 		// 3. Store back
 		if err := c.compile(x.X); err != nil {
 			return err
@@ -1153,12 +1316,16 @@ func (c *Compiler) compilePostfix(node *ast.Postfix) error {
 }
 
 func (c *Compiler) compileConst(node *ast.Const) error {
+	keep := c.takeStatementValue()
 	name := node.Name.Name
 	expr := node.Value
 	if err := c.compile(expr); err != nil {
 		return err
 	}
-	sym, err := c.current.symbols.InsertConstant(name)
+	if keep {
+		c.emit(op.Copy, 0)
+	}
+	sym, err := c.insertConstant(c.current.symbols, name, node.Name.Pos())
 	if err != nil {
 		return err
 	}
@@ -1175,6 +1342,49 @@ func (c *Compiler) compileConst(node *ast.Const) error {
 	return nil
 }
 
+// compileEnum builds the enum at run time from its name and member names,
+// then binds it to a constant like a const declaration would.
+func (c *Compiler) compileEnum(node *ast.Enum) error {
+	keep := c.takeStatementValue()
+	if len(node.Members) > math.MaxUint16 {
+		return c.formatError("too many members in enum", node.Pos())
+	}
+	seen := make(map[string]bool, len(node.Members))
+	for _, member := range node.Members {
+		if seen[member.Name] {
+			return c.formatError(fmt.Sprintf("enum %s has duplicate member %q",
+				node.Name.Name, member.Name), member.Pos())
+		}
+		if object.IsEnumAttr(member.Name) {
+			return c.formatError(fmt.Sprintf("enum %s member %q conflicts with a built-in enum attribute",
+				node.Name.Name, member.Name), member.Pos())
+		}
+		seen[member.Name] = true
+	}
+	c.emit(op.LoadConst, c.constant(node.Name.Name))
+	for _, member := range node.Members {
+		c.emit(op.LoadConst, c.constant(member.Name))
+	}
+	c.emit(op.BuildEnum, uint16(len(node.Members)))
+	if keep {
+		c.emit(op.Copy, 0)
+	}
+	sym, err := c.insertConstant(c.current.symbols, node.Name.Name, node.Name.Pos())
+	if err != nil {
+		return err
+	}
+	if sym == nil {
+		c.emit(op.PopTop)
+		return nil
+	}
+	if c.current.parent == nil {
+		c.emit(op.StoreGlobal, sym.Index())
+	} else {
+		c.emit(op.StoreFast, sym.Index())
+	}
+	return nil
+}
+
 func (c *Compiler) compileIn(node *ast.In) error {
 	if err := c.compile(node.Y); err != nil {
 		return err
@@ -1230,6 +1440,9 @@ func (c *Compiler) compileCall(node *ast.Call) error {
 	if err := c.compile(node.Fun); err != nil {
 		return err
 	}
+	if err := c.checkEnvCall(node.Fun); err != nil {
+		return err
+	}
 
 	if !hasSpread {
 		// Fast path: no spread, use regular Call
@@ -1275,6 +1488,11 @@ func (c *Compiler) compileObjectCall(node *ast.ObjectCall) error {
 	if err := c.compile(node.X); err != nil {
 		return err
 	}
+	if !node.Optional {
+		if err := c.checkEnvAttr(node.X, node.Call.Fun.String(), node.Call.Fun.Pos()); err != nil {
+			return err
+		}
+	}
 	// Handle optional chaining (?.)
 	var jumpPos int
 	if node.Optional {
@@ -1313,6 +1531,11 @@ func (c *Compiler) compileGetAttr(node *ast.GetAttr) error {
 	if err := c.compile(node.X); err != nil {
 		return err
 	}
+	if !node.Optional {
+		if err := c.checkEnvAttr(node.X, node.Attr.Name, node.Attr.Pos()); err != nil {
+			return err
+		}
+	}
 	// Handle optional chaining (?.)
 	var jumpPos int
 	if node.Optional {
@@ -1395,6 +1618,19 @@ func (c *Compiler) compileList(node *ast.List) error {
 	return nil
 }
 
+func (c *Compiler) compileTuple(node *ast.Tuple) error {
+	if len(node.Items) > math.MaxUint16 {
+		return c.formatError("tuple literal exceeds max size", node.Pos())
+	}
+	for _, expr := range node.Items {
+		if err := c.compile(expr); err != nil {
+			return err
+		}
+	}
+	c.emit(op.BuildTuple, uint16(len(node.Items)))
+	return nil
+}
+
 func (c *Compiler) compileMap(node *ast.Map) error {
 	items := node.Items
 
@@ -1412,7 +1648,10 @@ func (c *Compiler) compileMap(node *ast.Map) error {
 			case *ast.Ident:
 				c.emit(op.LoadConst, c.constant(k.String()))
 			default:
-				return c.formatError(fmt.Sprintf("invalid map key type: %T", item.Key), item.Key.Pos())
+				// Other keys, such as ints and tuples, are evaluated
+				if err := c.compile(k); err != nil {
+					return err
+				}
 			}
 			if err := c.compile(item.Value); err != nil {
 				return err
@@ -1447,7 +1686,10 @@ func (c *Compiler) compileMap(node *ast.Map) error {
 			case *ast.Ident:
 				c.emit(op.LoadConst, c.constant(k.String()))
 			default:
-				return c.formatError(fmt.Sprintf("invalid map key type: %T", item.Key), item.Key.Pos())
+				// Other keys, such as ints and tuples, are evaluated
+				if err := c.compile(k); err != nil {
+					return err
+				}
 			}
 			if err := c.compile(item.Value); err != nil {
 				return err
@@ -1568,7 +1810,7 @@ func (c *Compiler) compileFunc(node *ast.Func) error {
 			}
 			continue
 		}
-		if _, err := code.symbols.InsertVariable(paramName); err != nil {
+		if _, err := c.insertVariable(code.symbols, paramName, node.Lparen); err != nil {
 			return err
 		}
 	}
@@ -1586,7 +1828,7 @@ func (c *Compiler) compileFunc(node *ast.Func) error {
 				return err
 			}
 		} else {
-			if _, err := code.symbols.InsertVariable(restParamName); err != nil {
+			if _, err := c.insertVariable(code.symbols, restParamName, restParam.Pos()); err != nil {
 				return err
 			}
 		}
@@ -1597,7 +1839,7 @@ func (c *Compiler) compileFunc(node *ast.Func) error {
 	// store extracted values into these local variables.
 	for _, di := range destructureParams {
 		for _, name := range di.param.ParamNames() {
-			if _, err := code.symbols.InsertVariable(name); err != nil {
+			if _, err := c.insertVariable(code.symbols, name, di.param.Pos()); err != nil {
 				return err
 			}
 		}
@@ -1607,7 +1849,7 @@ func (c *Compiler) compileFunc(node *ast.Func) error {
 	// calls to the function. Later when we create the function object, we'll
 	// add the object value to the table.
 	if code.isNamed {
-		if _, err := code.symbols.InsertConstant(functionName); err != nil {
+		if _, err := c.insertConstant(code.symbols, functionName, node.Name.Pos()); err != nil {
 			return err
 		}
 	}
@@ -1659,7 +1901,7 @@ func (c *Compiler) compileFunc(node *ast.Func) error {
 		funcSymbol, found := c.current.symbols.Get(functionName)
 		if !found {
 			var err error
-			funcSymbol, err = c.current.symbols.InsertConstant(functionName)
+			funcSymbol, err = c.insertConstant(c.current.symbols, functionName, node.Name.Pos())
 			if err != nil {
 				return err
 			}
@@ -1693,6 +1935,7 @@ func (c *Compiler) compileReturn(node *ast.Return) error {
 }
 
 func (c *Compiler) compileSetItem(node *ast.Assign) error {
+	keep := c.takeStatementValue()
 	index := node.Index
 
 	// Handle compound operators (*=, +=, etc.)
@@ -1731,6 +1974,10 @@ func (c *Compiler) compileSetItem(node *ast.Assign) error {
 		}
 	}
 
+	if keep {
+		c.emit(op.Copy, 0)
+	}
+
 	// 4. Store the result back
 	if err := c.compile(index.X); err != nil {
 		return err
@@ -1746,6 +1993,7 @@ func (c *Compiler) compileAssign(node *ast.Assign) error {
 	if node.Index != nil {
 		return c.compileSetItem(node)
 	}
+	keep := c.takeStatementValue()
 	name := node.Name.Name
 	// The blank identifier discards the value
 	if IsBlankIdentifier(name) {
@@ -1756,6 +2004,9 @@ func (c *Compiler) compileAssign(node *ast.Assign) error {
 		if err := c.compile(node.Value); err != nil {
 			return err
 		}
+		if keep {
+			c.emit(op.Copy, 0)
+		}
 		c.emit(op.PopTop)
 		return nil
 	}
@@ -1770,6 +2021,9 @@ func (c *Compiler) compileAssign(node *ast.Assign) error {
 		if err := c.compile(node.Value); err != nil {
 			return err
 		}
+		if keep {
+			c.emit(op.Copy, 0)
+		}
 		c.emitStore(resolution)
 		return nil
 	}
@@ -1790,12 +2044,16 @@ func (c *Compiler) compileAssign(node *ast.Assign) error {
 	case "/=":
 		c.emit(op.BinaryOp, uint16(op.Divide))
 	}
+	if keep {
+		c.emit(op.Copy, 0)
+	}
 	// Store TOS in LHS
 	c.emitStore(resolution)
 	return nil
 }
 
 func (c *Compiler) compileSetAttr(node *ast.SetAttr) error {
+	keep := c.takeStatementValue()
 	idx := c.current.addName(node.Attr.Name)
 
 	if node.Op == "=" {
@@ -1803,6 +2061,9 @@ func (c *Compiler) compileSetAttr(node *ast.SetAttr) error {
 		if err := c.compile(node.Value); err != nil {
 			return err
 		}
+		if keep {
+			c.emit(op.Copy, 0)
+		}
 		if err := c.compile(node.X); err != nil {
 			return err
 		}
@@ -1833,6 +2094,9 @@ func (c *Compiler) compileSetAttr(node *ast.SetAttr) error {
 	case "/=":
 		c.emit(op.BinaryOp, uint16(op.Divide))
 	}
+	if keep {
+		c.emit(op.Copy, 0)
+	}
 
 	// Compile the object again and store the attribute
 	if err := c.compile(node.X); err != nil {
@@ -2158,12 +2422,15 @@ func makeInstruction(opcode op.Code, operands ...uint16) []op.Code {
 	return instruction
 }
 
-func normalizeFunctionBlock(node *ast.Block) []ast.Node {
+func normalizeFunctionBlock(node *ast.Block, returnStored bool) []ast.Node {
 	// Return a new slice of ast.Node that has some guarantees:
 	// 1. The slice ends with the first return statement
 	// 2. If there are no return statements, append one implicitly
 	// 3. An implicit return will return either the value of the
 	//    last expression, or nil if the last statement is not an expression.
+	// 4. If returnStored is set and the last statement is a declaration or
+	//    assignment, no return is appended; the caller returns the stored
+	//    value instead.
 	returnNil := &ast.Return{Value: &ast.Nil{}}
 	statements := node.Stmts
 	count := len(statements)
@@ -2186,11 +2453,31 @@ func normalizeFunctionBlock(node *ast.Block) []ast.Node {
 	case ast.Expr:
 		statements[count-1] = &ast.Return{Value: last}
 	default:
-		statements = append(statements, returnNil)
+		if !returnStored || !storesValue(last) {
+			statements = append(statements, returnNil)
+		}
 	}
 	return statements
 }
 
+// insertVariable adds a variable to a symbol table, reporting a name that is
+// already declared in the scope as a compile error at pos.
+func (c *Compiler) insertVariable(symbols *SymbolTable, name string, pos token.Position) (*Symbol, error) {
+	if !IsBlankIdentifier(name) && symbols.IsDefined(name) {
+		return nil, c.formatError(fmt.Sprintf("variable %q already exists", name), pos)
+	}
+	return symbols.InsertVariable(name)
+}
+
+// insertConstant adds a constant to a symbol table, reporting a name that is
+// already declared in the scope as a compile error at pos.
+func (c *Compiler) insertConstant(symbols *SymbolTable, name string, pos token.Position) (*Symbol, error) {
+	if !IsBlankIdentifier(name) && symbols.IsDefined(name) {
+		return nil, c.formatError(fmt.Sprintf("variable %q already exists", name), pos)
+	}
+	return symbols.InsertConstant(name)
+}
+
 // formatError creates a detailed error message including file, line and column information
 func (c *Compiler) formatError(msg string, pos token.Position) error {
 	return c.formatErrorWithCode(errors.ErrorCode(""), msg, pos, nil)
@@ -2224,14 +2511,26 @@ func (c *Compiler) formatUndefinedVariableError(name string, pos token.Position)
 	// Find similar names
 	suggestions := errors.SuggestSimilar(name, allNames)
 
-	return c.formatErrorWithCode(
-		errors.E2001,
-		fmt.Sprintf("undefined variable %q", name),
-		pos,
-		suggestions,
-	)
+	msg := fmt.Sprintf("undefined variable %q", name)
+	if len(c.envSchema) == 0 {
+		return c.formatErrorWithCode(errors.E2001, msg, pos, suggestions)
+	}
+
+	// The host declared the script's environment, so say the name is missing
+	// from the schema and list what it does declare.
+	err := c.formatErrorWithCode(errors.E2001, msg+" (not declared in the env schema)", pos, suggestions).(*errors.CompileError)
+	declared := slices.Sorted(maps.Keys(c.envSchema))
+	if len(declared) > maxSchemaNamesInNote {
+		declared = append(declared[:maxSchemaNamesInNote], "...")
+	}
+	err.Note = "declared env globals: " + strings.Join(declared, ", ")
+	return err
 }
 
+// maxSchemaNamesInNote limits how many declared env globals an undefined
+// variable error lists.
+const maxSchemaNamesInNote = 10
+
 // getSourceLine retrieves a specific line from the source code.
 // lineNum is 0-indexed.
 func (c *Compiler) getSourceLine(lineNum int) string {
@@ -2340,7 +2639,7 @@ func (c *Compiler) compileTry(node *ast.Try) error {
 		// The error value will be on the stack when we enter the catch block
 		catchIdent := node.CatchIdent
 		if catchIdent != nil {
-			sym, err := code.symbols.InsertVariable(catchIdent.Name)
+			sym, err := c.insertVariable(code.symbols, catchIdent.Name, catchIdent.Pos())
 			if err != nil {
 				code.symbols = code.symbols.parent
 				return err
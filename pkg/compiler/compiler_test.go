@@ -2,11 +2,13 @@ package compiler
 
 import (
 	"context"
+	stderrors "errors"
 	"strings"
 	"testing"
 
 	"github.com/deepnoodle-ai/risor/v2/internal/token"
 	"github.com/deepnoodle-ai/risor/v2/pkg/ast"
+	"github.com/deepnoodle-ai/risor/v2/pkg/bytecode"
 	"github.com/deepnoodle-ai/risor/v2/pkg/errors"
 	"github.com/deepnoodle-ai/risor/v2/pkg/op"
 	"github.com/deepnoodle-ai/risor/v2/pkg/parser"
@@ -124,6 +126,42 @@ func TestBadStmtCompilation(t *testing.T) {
 	assert.True(t, strings.Contains(err.Error(), "syntax error in statement"))
 }
 
+func TestSpreadOutsideContainer(t *testing.T) {
+	program, err := parser.Parse(context.Background(), "...0", nil)
+	assert.Nil(t, err)
+	_, err = Compile(program, nil)
+	assert.NotNil(t, err)
+	assert.True(t, strings.Contains(err.Error(), "spread syntax is only allowed"))
+}
+
+func TestDefaultValueOutsidePattern(t *testing.T) {
+	program, err := parser.Parse(context.Background(), "[a = 0]", nil)
+	assert.Nil(t, err)
+	_, err = Compile(program, nil)
+	assert.NotNil(t, err)
+	assert.True(t, strings.Contains(err.Error(), "default values are only allowed"))
+}
+
+func TestDuplicateVariableError(t *testing.T) {
+	for _, input := range []string{
+		"let a = 1; let a = 2",
+		"let [a, a] = [1, 2]",
+		"let {a, b: a} = {a: 1, b: 2}",
+		"function f(a, a) { a }",
+		"function a(a) { a }",
+		"let f = function a(a) { a }",
+		"let a = 1; const a = 2",
+		"let a = 1; enum a { X }",
+	} {
+		program, err := parser.Parse(context.Background(), input, nil)
+		assert.Nil(t, err)
+		_, err = Compile(program, nil)
+		var compileErr *errors.CompileError
+		assert.True(t, stderrors.As(err, &compileErr), input)
+		assert.Equal(t, compileErr.Message, `variable "a" already exists`, input)
+	}
+}
+
 func TestBadExprInVarCompilation(t *testing.T) {
 	c, err := New(&Config{Filename: "test.risor"})
 	assert.Nil(t, err)
@@ -1217,3 +1255,64 @@ func TestCompileASTRollbackIncrementalREPL(t *testing.T) {
 	_, found = c.main.symbols.Get("bar")
 	assert.False(t, found)
 }
+
+func TestCompiledCodeVerifies(t *testing.T) {
+	inputs := []string{
+		`let x = 1; x + 2`,
+		`function add(a, b = 2) { return a + b }; add(1)`,
+		`function outer() { let n = 0; return function() { n += 1; return n } }; outer()()`,
+		`let xs = [1, 2, 3].map(x => x * 2); xs[0]`,
+		`try { throw "boom" } catch e { e } finally { 1 }`,
+		`function f() { try { return 1 } catch { return 2 } }; f()`,
+		`let m = {a: 1, ...{b: 2}}; m.a ?? m?.c`,
+		`let [a, b] = [1, 2]; if (a < b) { a } else { b }`,
+		`match 2 { 1 => "one", _ => "other" }`,
+		`let s = "x"; if (true) { s.to_upper() } else { s }`,
+	}
+	for _, input := range inputs {
+		t.Run(input, func(t *testing.T) {
+			program, err := parser.Parse(context.Background(), input, nil)
+			assert.Nil(t, err)
+			code, err := Compile(program, nil)
+			assert.Nil(t, err)
+			assert.Nil(t, bytecode.Verify(code))
+		})
+	}
+}
+
+func TestResultModeInstructions(t *testing.T) {
+	compile := func(input string, mode ResultMode) []op.Code {
+		program, err := parser.Parse(context.Background(), input, nil)
+		assert.Nil(t, err)
+		code, err := Compile(program, &Config{ResultMode: mode})
+		assert.Nil(t, err)
+		assert.Nil(t, bytecode.Verify(code))
+		instructions := make([]op.Code, code.InstructionCount())
+		for i := range instructions {
+			instructions[i] = code.InstructionAt(i)
+		}
+		return instructions
+	}
+
+	// By default the stored value is discarded and nil is the result
+	assert.Equal(t, compile(`let x = 1`, ResultDefault), []op.Code{
+		op.LoadConst, 0,
+		op.StoreGlobal, 0,
+		op.Nil,
+	})
+	// In last-value mode the stored value is kept as the result
+	assert.Equal(t, compile(`let x = 1`, ResultLastValue), []op.Code{
+		op.LoadConst, 0,
+		op.Copy, 0,
+		op.StoreGlobal, 0,
+	})
+	// In nil mode a trailing if is discarded
+	ifNil := compile(`if (true) { 1 }`, ResultNil)
+	assert.Equal(t, ifNil[len(ifNil)-2:], []op.Code{op.PopTop, op.Nil})
+}
+
+func TestInvalidResultMode(t *testing.T) {
+	_, err := New(&Config{ResultMode: ResultMode(9)})
+	assert.NotNil(t, err)
+	assert.Equal(t, err.Error(), "invalid result mode: ResultMode(9)")
+}
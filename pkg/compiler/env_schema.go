@@ -0,0 +1,102 @@
+package compiler
+
+import (
+	"fmt"
+
+	"github.com/deepnoodle-ai/risor/v2/internal/token"
+	"github.com/deepnoodle-ai/risor/v2/pkg/ast"
+	"github.com/deepnoodle-ai/risor/v2/pkg/errors"
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+)
+
+// AnyType is the env schema type name that accepts a value of any type.
+// Globals declared with it are exempt from compile-time usage checks.
+const AnyType = "any"
+
+// nonCallableTypes are the schema types whose values can never be called.
+var nonCallableTypes = map[string]bool{
+	string(object.BOOL):   true,
+	string(object.BYTE):   true,
+	string(object.BYTES):  true,
+	string(object.ERROR):  true,
+	string(object.FLOAT):  true,
+	string(object.INT):    true,
+	string(object.LIST):   true,
+	string(object.MAP):    true,
+	string(object.NIL):    true,
+	string(object.RANGE):  true,
+	string(object.STRING): true,
+	string(object.TIME):   true,
+	string(object.TUPLE):  true,
+}
+
+// closedAttrTypes are the schema types whose attributes are fully described
+// by their type documentation. Maps and modules are excluded because their
+// attributes depend on their contents.
+var closedAttrTypes = map[string]bool{
+	string(object.BOOL):   true,
+	string(object.BYTE):   true,
+	string(object.BYTES):  true,
+	string(object.ERROR):  true,
+	string(object.FLOAT):  true,
+	string(object.INT):    true,
+	string(object.LIST):   true,
+	string(object.NIL):    true,
+	string(object.RANGE):  true,
+	string(object.STRING): true,
+	string(object.TIME):   true,
+	string(object.TUPLE):  true,
+}
+
+// envTypeOf returns the schema type of the given expression if it is an
+// identifier that refers to a schema-declared env global. Identifiers that
+// shadow the global, or globals declared as AnyType, are not reported.
+func (c *Compiler) envTypeOf(expr ast.Expr) (string, bool) {
+	ident, ok := expr.(*ast.Ident)
+	if !ok || len(c.envSchema) == 0 {
+		return "", false
+	}
+	typ, ok := c.envSchema[ident.Name]
+	if !ok || typ == AnyType {
+		return "", false
+	}
+	resolution, found := c.current.symbols.Resolve(ident.Name)
+	if !found || resolution.Scope() != Global {
+		return "", false
+	}
+	global, found := c.main.symbols.Get(ident.Name)
+	if !found || global != resolution.Symbol() {
+		return "", false
+	}
+	return typ, true
+}
+
+// checkEnvCall reports an error if fun is an env global whose schema type
+// is not callable.
+func (c *Compiler) checkEnvCall(fun ast.Expr) error {
+	typ, ok := c.envTypeOf(fun)
+	if !ok || !nonCallableTypes[typ] {
+		return nil
+	}
+	msg := fmt.Sprintf("cannot call %s: env schema declares it as %s", fun.String(), typ)
+	return c.formatErrorWithCode(errors.E2011, msg, fun.Pos(), nil)
+}
+
+// checkEnvAttr reports an error if x is an env global whose schema type
+// does not have the named attribute.
+func (c *Compiler) checkEnvAttr(x ast.Expr, name string, pos token.Position) error {
+	typ, ok := c.envTypeOf(x)
+	if !ok || !closedAttrTypes[typ] {
+		return nil
+	}
+	if spec, found := object.TypeDoc(object.Type(typ)); found {
+		for _, attr := range spec.Attrs {
+			if attr.Name == name {
+				return nil
+			}
+		}
+	}
+	msg := fmt.Sprintf("%s has no attribute %q: env schema declares %s as %s",
+		typ, name, x.String(), typ)
+	return c.formatErrorWithCode(errors.E2011, msg, pos, nil)
+}
@@ -112,6 +112,7 @@ type codeDef struct {
 	Locations     []locationDef     `json:"locations,omitempty"`
 	MaxCallArgs   uint16            `json:"max_call_args,omitempty"`
 	EnvKeys       []string          `json:"env_keys,omitempty"`
+	EnvSchema     map[string]string `json:"env_schema,omitempty"`
 }
 
 // A representation of a Code object that can be marshalled more easily.
@@ -168,6 +169,7 @@ func codeFromState(state *state) (*Code, error) {
 			locations:    locationsFromDefs(c.Locations),
 			maxCallArgs:  c.MaxCallArgs,
 			envKeys:      copyStrings(c.EnvKeys),
+			envSchema:    copySchema(c.EnvSchema),
 		}
 		codesByID[code.id] = code
 		codes = append(codes, code)
@@ -375,6 +377,7 @@ func stateFromCode(code *Code) (*state, error) {
 			Locations:     locationsToDefs(code.locations),
 			MaxCallArgs:   code.maxCallArgs,
 			EnvKeys:       copyStrings(code.envKeys),
+			EnvSchema:     copySchema(code.envSchema),
 		}
 		if code.parent != nil {
 			cdef.ParentID = code.parent.id
@@ -451,6 +454,18 @@ func copyStrings(src []string) []string {
 	return dst
 }
 
+// copySchema returns a copy of the given env schema.
+func copySchema(src map[string]string) map[string]string {
+	if len(src) == 0 {
+		return nil
+	}
+	dst := make(map[string]string, len(src))
+	for k, v := range src {
+		dst[k] = v
+	}
+	return dst
+}
+
 func locationsFromDefs(defs []locationDef) []errors.SourceLocation {
 	if defs == nil {
 		return nil
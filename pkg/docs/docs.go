@@ -3,11 +3,10 @@ package docs
 import (
 	"encoding/json"
 	"sort"
+	"strings"
 
 	"github.com/deepnoodle-ai/risor/v2/pkg/builtins"
-	"github.com/deepnoodle-ai/risor/v2/pkg/modules/math"
-	"github.com/deepnoodle-ai/risor/v2/pkg/modules/rand"
-	"github.com/deepnoodle-ai/risor/v2/pkg/modules/regexp"
+	_ "github.com/deepnoodle-ai/risor/v2/pkg/modules/all"
 	"github.com/deepnoodle-ai/risor/v2/pkg/object"
 )
 
@@ -197,7 +196,7 @@ func buildQuickReference() docsQuickReference {
 		Topics: map[string]string{
 			"builtins": "Built-in functions (len, map, filter, range, ...)",
 			"types":    "Types (string, list, map, int, float, ...)",
-			"modules":  "Modules (math, rand, regexp)",
+			"modules":  "Modules (" + strings.Join(docsModuleNames(), ", ") + ")",
 			"syntax":   "Complete syntax reference",
 			"errors":   "Common errors and debugging",
 		},
@@ -363,14 +362,31 @@ func docsTypeDocs() map[string]object.TypeSpec {
 	return object.TypeDocsMap()
 }
 
-// Module documentation (internal) - generated from modules themselves
-var docsModuleDocs = map[string]struct {
+// docsModuleDoc is the documentation of one module.
+type docsModuleDoc struct {
 	Doc   string
 	Funcs []object.FuncSpec
-}{
-	"math":   {Doc: math.ModuleDoc(), Funcs: math.Docs()},
-	"rand":   {Doc: rand.ModuleDoc(), Funcs: rand.Docs()},
-	"regexp": {Doc: regexp.ModuleDoc(), Funcs: regexp.Docs()},
+}
+
+// Module documentation (internal) - registered by the modules themselves
+var docsModuleDocs = docsRegisteredModules()
+
+// docsModuleNames returns the names of the documented modules, sorted.
+func docsModuleNames() []string {
+	names := make([]string, 0, len(docsModuleDocs))
+	for name := range docsModuleDocs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func docsRegisteredModules() map[string]docsModuleDoc {
+	result := map[string]docsModuleDoc{}
+	for _, spec := range object.ModuleDocs() {
+		result[spec.Name] = docsModuleDoc{Doc: spec.Doc, Funcs: spec.Funcs}
+	}
+	return result
 }
 
 // Syntax quick reference
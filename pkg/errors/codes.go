@@ -31,6 +31,7 @@ const (
 	E2008 ErrorCode = "E2008" // Too many constants
 	E2009 ErrorCode = "E2009" // Too many free variables
 	E2010 ErrorCode = "E2010" // Invalid destructuring pattern
+	E2011 ErrorCode = "E2011" // Environment schema violation
 
 	// Runtime errors (E3xxx)
 	E3001 ErrorCode = "E3001" // Type error
@@ -68,6 +69,7 @@ var codeDescriptions = map[ErrorCode]string{
 	E2008: "too many constants",
 	E2009: "too many free variables",
 	E2010: "invalid destructuring pattern",
+	E2011: "environment schema violation",
 
 	E3001: "type error",
 	E3002: "division by zero",
@@ -198,3 +198,18 @@ func NewIndexError(err error) *IndexError {
 func IndexErrorf(format string, args ...any) *IndexError {
 	return NewIndexError(fmt.Errorf("index error: "+format, args...))
 }
+
+// ExitError stops a program with an exit status, as a call to os.exit does.
+// It is not an error in the program: try/catch cannot intercept it, and the
+// host decides what the status means, such as the exit code of a process.
+type ExitError struct {
+	Code int
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.Code)
+}
+
+func NewExitError(code int) *ExitError {
+	return &ExitError{Code: code}
+}
@@ -0,0 +1,127 @@
+package fuzz
+
+import (
+	"context"
+	goerrors "errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/deepnoodle-ai/risor/v2"
+	"github.com/deepnoodle-ai/risor/v2/pkg/ast"
+	"github.com/deepnoodle-ai/risor/v2/pkg/bytecode"
+	"github.com/deepnoodle-ai/risor/v2/pkg/compiler"
+	"github.com/deepnoodle-ai/risor/v2/pkg/errors"
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+)
+
+// Variant is one way of turning a program into bytecode. Differential runs
+// a program built by two variants and expects the same outcome from both,
+// so a variant may change how the code is built but not what it does.
+type Variant struct {
+	Name    string
+	Compile func(program *ast.Program, cfg *compiler.Config) (*bytecode.Code, error)
+}
+
+// Direct compiles the program as risor.Compile does.
+var Direct = Variant{
+	Name: "direct",
+	Compile: func(program *ast.Program, cfg *compiler.Config) (*bytecode.Code, error) {
+		return compiler.Compile(program, cfg)
+	},
+}
+
+// Marshaled compiles the program and round-trips the bytecode through
+// bytecode.Marshal and bytecode.Unmarshal, as a host loading cached
+// bytecode would.
+var Marshaled = Variant{
+	Name: "marshaled",
+	Compile: func(program *ast.Program, cfg *compiler.Config) (*bytecode.Code, error) {
+		code, err := compiler.Compile(program, cfg)
+		if err != nil {
+			return nil, err
+		}
+		data, err := bytecode.Marshal(code)
+		if err != nil {
+			return nil, err
+		}
+		return bytecode.Unmarshal(data)
+	},
+}
+
+// Mismatch describes a program whose outcome differs between two variants.
+type Mismatch struct {
+	Source   string // The program, as printed from its AST
+	A, B     string // Names of the variants
+	OutcomeA string // Result or error of variant A
+	OutcomeB string // Result or error of variant B
+}
+
+func (m *Mismatch) Error() string {
+	return fmt.Sprintf("%s: %s, %s: %s", m.A, m.OutcomeA, m.B, m.OutcomeB)
+}
+
+// Differential compiles and runs program with variants a and b, returning
+// a *Mismatch if their results or errors differ. Errors are compared by
+// kind and message, since variants may report different positions. If
+// either variant panics, a *Failure is returned instead.
+func Differential(ctx context.Context, program *ast.Program, a, b Variant, cfg *Config) error {
+	if cfg == nil {
+		cfg = &Config{}
+	}
+	source := program.String()
+	outcomeA, err := runVariant(ctx, program, source, a, cfg)
+	if err != nil {
+		return err
+	}
+	outcomeB, err := runVariant(ctx, program, source, b, cfg)
+	if err != nil {
+		return err
+	}
+	if outcomeA != outcomeB {
+		return &Mismatch{Source: source, A: a.Name, B: b.Name, OutcomeA: outcomeA, OutcomeB: outcomeB}
+	}
+	return nil
+}
+
+// runVariant compiles and runs program with variant v, returning a
+// description of its result or error.
+func runVariant(ctx context.Context, program *ast.Program, source string, v Variant, cfg *Config) (string, error) {
+	compilerCfg := &compiler.Config{GlobalNames: slices.Sorted(maps.Keys(cfg.env()))}
+
+	var code *bytecode.Code
+	var err error
+	if failure := guard("compile", source, func() {
+		code, err = v.Compile(program, compilerCfg)
+	}); failure != nil {
+		return "", failure
+	}
+	if err != nil {
+		return describeError(err), nil
+	}
+
+	var result any
+	opts := append(cfg.options(), risor.WithRawResult())
+	if failure := guard("run", source, func() {
+		result, err = risor.Run(ctx, code, opts...)
+	}); failure != nil {
+		return "", failure
+	}
+	if err != nil {
+		return describeError(err), nil
+	}
+	return result.(object.Object).Inspect(), nil
+}
+
+// describeError returns the kind and message of err without its position.
+func describeError(err error) string {
+	var compileErr *errors.CompileError
+	if goerrors.As(err, &compileErr) {
+		return "compile error: " + compileErr.Message
+	}
+	var structured *errors.StructuredError
+	if goerrors.As(err, &structured) {
+		return structured.Kind.String() + ": " + structured.Message
+	}
+	return "error: " + err.Error()
+}
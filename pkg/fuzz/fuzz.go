@@ -0,0 +1,190 @@
+// Package fuzz checks that arbitrary programs are handled safely by the
+// parser, compiler, and VM. Each program is parsed, compiled, and run with
+// resource limits. The checks are that no stage panics and that every
+// error returned is one of Risor's structured error types, so hosts running
+// untrusted scripts can rely on getting a well-formed error back.
+//
+// Check is the harness used by the Go fuzz targets in this package and by
+// the risor fuzz command. Pass Config.Env to include host builtins in the
+// programs that are checked.
+//
+// Differential runs a program built two ways, such as with and without a
+// compiler pass, and reports a *Mismatch when the outcomes differ. Use
+// ProgramGenerator to build programs for it that always compile.
+package fuzz
+
+import (
+	"cmp"
+	"context"
+	goerrors "errors"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"github.com/deepnoodle-ai/risor/v2"
+	"github.com/deepnoodle-ai/risor/v2/pkg/bytecode"
+	"github.com/deepnoodle-ai/risor/v2/pkg/errors"
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+	"github.com/deepnoodle-ai/risor/v2/pkg/parser"
+	"github.com/deepnoodle-ai/risor/v2/pkg/vm"
+)
+
+// Default limits applied when running a program.
+const (
+	DefaultMaxSteps      = 100_000
+	DefaultMaxStackDepth = 256
+	DefaultTimeout       = time.Second
+)
+
+// Config controls how Check runs a program.
+type Config struct {
+	// Env holds the globals available to the program. Default is
+	// risor.Builtins().
+	Env map[string]any
+
+	// MaxSteps limits the instructions the program may execute.
+	MaxSteps int64
+
+	// MaxStackDepth limits the call depth of the program.
+	MaxStackDepth int
+
+	// Timeout limits the running time of the program.
+	Timeout time.Duration
+}
+
+// Failure describes a program that broke one of the checks.
+type Failure struct {
+	Stage  string // "parse", "compile", or "run"
+	Reason string // "panic" or "unstructured error"
+	Source string // The program that failed
+	Err    error  // The error returned, or the recovered panic
+	Stack  string // Go stack trace of a panic
+}
+
+func (f *Failure) Error() string {
+	return fmt.Sprintf("%s: %s: %v", f.Stage, f.Reason, f.Err)
+}
+
+// Check parses, compiles, and runs source, returning a *Failure if any
+// stage panics or returns an error that is not structured. Structured
+// errors, including those for exceeded limits, are expected outcomes for
+// arbitrary programs, and Check returns nil for them.
+func Check(ctx context.Context, source string, cfg *Config) error {
+	if cfg == nil {
+		cfg = &Config{}
+	}
+	opts := cfg.options()
+
+	var err error
+	if failure := guard("parse", source, func() {
+		_, err = parser.Parse(ctx, source, nil)
+	}); failure != nil {
+		return failure
+	}
+	if err != nil {
+		return checkError("parse", source, err)
+	}
+
+	var code *bytecode.Code
+	if failure := guard("compile", source, func() {
+		code, err = risor.Compile(ctx, source, opts...)
+	}); failure != nil {
+		return failure
+	}
+	if err != nil {
+		return checkError("compile", source, err)
+	}
+
+	if failure := guard("run", source, func() {
+		_, err = risor.Run(ctx, code, opts...)
+	}); failure != nil {
+		return failure
+	}
+	if err != nil {
+		return checkError("run", source, err)
+	}
+	return nil
+}
+
+// env returns the globals for the program.
+func (cfg *Config) env() map[string]any {
+	if cfg.Env == nil {
+		return risor.Builtins()
+	}
+	return cfg.Env
+}
+
+// options returns the options for compiling and running the program with
+// the configured globals and limits.
+func (cfg *Config) options() []risor.Option {
+	return []risor.Option{
+		risor.WithEnv(cfg.env()),
+		risor.WithMaxSteps(cmp.Or(cfg.MaxSteps, DefaultMaxSteps)),
+		risor.WithMaxStackDepth(cmp.Or(cfg.MaxStackDepth, DefaultMaxStackDepth)),
+		risor.WithTimeout(cmp.Or(cfg.Timeout, DefaultTimeout)),
+	}
+}
+
+// guard calls fn, returning a failure if it panics.
+func guard(stage, source string, fn func()) (failure error) {
+	defer func() {
+		if r := recover(); r != nil {
+			failure = &Failure{
+				Stage:  stage,
+				Reason: "panic",
+				Source: source,
+				Err:    fmt.Errorf("%v", r),
+				Stack:  string(debug.Stack()),
+			}
+		}
+	}()
+	fn()
+	return nil
+}
+
+// checkError returns a failure if err came from a panic the VM recovered
+// or is not structured.
+func checkError(stage, source string, err error) error {
+	var se *object.ScriptError
+	if goerrors.As(err, &se) && se.Code == "panic" {
+		stack, _ := se.Data["stack"].(string)
+		return &Failure{Stage: stage, Reason: "panic", Source: source, Err: err, Stack: stack}
+	}
+	if !IsStructured(err) {
+		return &Failure{Stage: stage, Reason: "unstructured error", Source: source, Err: err}
+	}
+	return nil
+}
+
+// IsStructured reports whether err is one of the error types Risor returns
+// for a failed program: a parse or compile error, a runtime error carrying
+// its kind and location, a typed error from a builtin, an error thrown by
+// the script, or an exceeded limit.
+func IsStructured(err error) bool {
+	var (
+		parseErrs   *parser.Errors
+		compileErr  *errors.CompileError
+		compileErrs *errors.CompileErrors
+		structured  *errors.StructuredError
+		scriptErr   *object.ScriptError
+		evalErr     *errors.EvalError
+		argsErr     *errors.ArgsError
+		typeErr     *errors.TypeError
+		valueErr    *errors.ValueError
+		indexErr    *errors.IndexError
+	)
+	return goerrors.As(err, &parseErrs) ||
+		goerrors.As(err, &compileErr) ||
+		goerrors.As(err, &compileErrs) ||
+		goerrors.As(err, &structured) ||
+		goerrors.As(err, &scriptErr) ||
+		goerrors.As(err, &evalErr) ||
+		goerrors.As(err, &argsErr) ||
+		goerrors.As(err, &typeErr) ||
+		goerrors.As(err, &valueErr) ||
+		goerrors.As(err, &indexErr) ||
+		goerrors.Is(err, vm.ErrStepLimitExceeded) ||
+		goerrors.Is(err, vm.ErrStackOverflow) ||
+		goerrors.Is(err, context.DeadlineExceeded) ||
+		goerrors.Is(err, context.Canceled)
+}
@@ -0,0 +1,183 @@
+package fuzz
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/deepnoodle-ai/risor/v2"
+	"github.com/deepnoodle-ai/risor/v2/pkg/ast"
+	"github.com/deepnoodle-ai/risor/v2/pkg/bytecode"
+	"github.com/deepnoodle-ai/risor/v2/pkg/compiler"
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+// FuzzCheck runs arbitrary programs through the parser, compiler, and VM.
+func FuzzCheck(f *testing.F) {
+	for _, seed := range Seeds {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, source string) {
+		if err := Check(context.Background(), source, nil); err != nil {
+			t.Fatalf("%v\n\nprogram:\n%s\n\n%s", err, source, err.(*Failure).Stack)
+		}
+	})
+}
+
+// FuzzDifferential compares the outcome of generated programs between the
+// direct and marshaled variants.
+func FuzzDifferential(f *testing.F) {
+	for seed := int64(0); seed < 8; seed++ {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, seed int64) {
+		program := NewProgramGenerator(seed).Program()
+		if err := Differential(context.Background(), program, Direct, Marshaled, nil); err != nil {
+			t.Fatalf("%v\n\nprogram:\n%s", err, program)
+		}
+	})
+}
+
+func TestSeedsRun(t *testing.T) {
+	for _, seed := range Seeds {
+		_, err := risor.Eval(context.Background(), seed, risor.WithEnv(risor.Builtins()))
+		assert.Nil(t, err, seed)
+	}
+}
+
+func TestCheck_Structured(t *testing.T) {
+	for _, source := range []string{
+		"let x = (",
+		"undefined_name",
+		"1 + 'a'",
+		"throw 'boom'",
+		"[1][5]",
+		"function f() { f() }; f()",
+		"range(100000000).map(x => x)",
+		"function A(A B000){}",
+	} {
+		assert.Nil(t, Check(context.Background(), source, &Config{MaxSteps: 1000}), source)
+	}
+}
+
+func TestCheck_Panic(t *testing.T) {
+	env := risor.Builtins()
+	env["explode"] = object.NewBuiltin("explode", func(ctx context.Context, args ...object.Object) (object.Object, error) {
+		var m map[string]int
+		m["x"] = 1
+		return object.Nil, nil
+	})
+	err := Check(context.Background(), "explode()", &Config{Env: env})
+
+	var failure *Failure
+	assert.True(t, errors.As(err, &failure))
+	assert.Equal(t, failure.Stage, "run")
+	assert.Equal(t, failure.Reason, "panic")
+	assert.Equal(t, failure.Source, "explode()")
+	assert.Contains(t, failure.Stack, "TestCheck_Panic")
+}
+
+func TestCheckError(t *testing.T) {
+	assert.Nil(t, checkError("run", "x", object.ValueErrorf("bad value")))
+
+	var failure *Failure
+	assert.True(t, errors.As(checkError("run", "x", errors.New("plain")), &failure))
+	assert.Equal(t, failure.Reason, "unstructured error")
+
+	assert.True(t, errors.As(checkError("run", "x", object.NewPanicError("f", "oops", []byte("stack"))), &failure))
+	assert.Equal(t, failure.Reason, "panic")
+	assert.Equal(t, failure.Stack, "stack")
+}
+
+func TestIsStructured(t *testing.T) {
+	assert.True(t, IsStructured(object.TypeErrorf("bad type")))
+	assert.True(t, IsStructured(context.DeadlineExceeded))
+	assert.False(t, IsStructured(errors.New("plain")))
+}
+
+func TestGenerator(t *testing.T) {
+	a := NewGenerator(1, nil)
+	b := NewGenerator(1, nil)
+	for i := 0; i < 100; i++ {
+		assert.Equal(t, a.Next(), b.Next())
+	}
+
+	g := NewGenerator(2, []string{"let x = 1"})
+	changed := false
+	for i := 0; i < 20; i++ {
+		if g.Next() != "let x = 1" {
+			changed = true
+		}
+	}
+	assert.True(t, changed)
+}
+
+func TestGeneratedPrograms(t *testing.T) {
+	g := NewGenerator(42, nil)
+	for i := 0; i < 500; i++ {
+		source := g.Next()
+		if err := Check(context.Background(), source, nil); err != nil {
+			t.Fatalf("%v\n\nprogram:\n%s", err, source)
+		}
+	}
+}
+
+func TestProgramGenerator(t *testing.T) {
+	a := NewProgramGenerator(1)
+	b := NewProgramGenerator(1)
+	for i := 0; i < 50; i++ {
+		assert.Equal(t, a.Program().String(), b.Program().String())
+	}
+
+	// Generated programs always compile
+	g := NewProgramGenerator(2)
+	for i := 0; i < 200; i++ {
+		program := g.Program()
+		_, err := compiler.Compile(program, &compiler.Config{GlobalNames: []string{"len", "string"}})
+		assert.Nil(t, err, program.String())
+	}
+}
+
+func TestDifferential(t *testing.T) {
+	g := NewProgramGenerator(42)
+	for i := 0; i < 300; i++ {
+		program := g.Program()
+		if err := Differential(context.Background(), program, Direct, Marshaled, nil); err != nil {
+			t.Fatalf("%v\n\nprogram:\n%s", err, program)
+		}
+	}
+}
+
+func TestDifferential_Mismatch(t *testing.T) {
+	// A variant that drops the last statement, as a broken optimizer might
+	dropLast := Variant{
+		Name: "drop-last",
+		Compile: func(program *ast.Program, cfg *compiler.Config) (*bytecode.Code, error) {
+			stmts := program.Stmts[:len(program.Stmts)-1]
+			return compiler.Compile(&ast.Program{Stmts: stmts}, cfg)
+		},
+	}
+	program := &ast.Program{Stmts: []ast.Node{
+		&ast.Var{Name: ident("x"), Value: intLit(1)},
+		ident("x"),
+	}}
+	err := Differential(context.Background(), program, Direct, dropLast, nil)
+
+	var mismatch *Mismatch
+	assert.True(t, errors.As(err, &mismatch))
+	assert.Equal(t, mismatch.A, "direct")
+	assert.Equal(t, mismatch.B, "drop-last")
+	assert.Equal(t, mismatch.OutcomeA, "1")
+	assert.Equal(t, mismatch.OutcomeB, "null")
+}
+
+func TestDescribeError(t *testing.T) {
+	_, err := risor.Eval(context.Background(), "let x = 1\nlet y = x / 0")
+	assert.Equal(t, describeError(err), "value error: value error: division by zero")
+
+	_, err = risor.Compile(context.Background(), "let x = 1; let x = 2")
+	assert.Equal(t, describeError(err), `compile error: variable "x" already exists`)
+
+	assert.Equal(t, describeError(errors.New("plain")), "error: plain")
+}
@@ -0,0 +1,89 @@
+package fuzz
+
+import "math/rand"
+
+// Seeds are small programs covering the main language features. They are
+// the default starting points for Generator and the seed corpus of the Go
+// fuzz targets.
+var Seeds = []string{
+	`1 + 2 * 3 - 4 / 5 % 6`,
+	`let x = 1; x += 2; x`,
+	`const name = "risor"; name[1:3]`,
+	`let [a, b = 2] = [1]; let { c, d = 4 } = {c: 3}; a + b + c + d`,
+	`function add(a, b = 10) { return a + b }; add(1)`,
+	`function sum(...xs) { return xs.reduce(0, (acc, x) => acc + x) }; sum(1, 2, 3)`,
+	`let f = x => x * 2; [1, 2, 3].map(f).filter(x => x > 2)`,
+	`if (1 < 2) { "yes" } else if (2 < 3) { "maybe" } else { "no" }`,
+	`let x = 2; let y = if (x > 1) { x * 10 } else { 0 }; y`,
+	`let n = 5; match n { 1 => "one", n if n > 3 => "big", _ => "other" }`,
+	`try { throw "boom" } catch e { e.message() } finally { 1 }`,
+	`let m = {a: 1, b: [1, 2]}; m.b[0] = m.a ?? 0; m?.c?.d`,
+	`let s = "héllo"; s.to_upper().split("l")`,
+	`"a" in ["a", "b"] && !(1 == 2) || 3 >= 4`,
+	"let t = `x = ${1 + 2}`; t",
+	`enum Color { Red, Green }; Color.Red`,
+	`function fib(n) { if (n < 2) { return n }; return fib(n - 1) + fib(n - 2) }; fib(10)`,
+	`range(10).map(i => i ** 2)`,
+	`[3, 1, 2].sort().reverse()`,
+	`let counter = function() { let n = 0; return () => { n += 1; return n } }(); counter(); counter()`,
+	`string(int("42") + float("0.5"))`,
+	`{x: 1, y: 2} |> keys |> len`,
+}
+
+// fragments are pieces of syntax inserted into programs by Generator.
+var fragments = []string{
+	"(", ")", "[", "]", "{", "}", ",", ";", ".", "?.", "...", "=>", ":", "\n",
+	"+", "-", "*", "/", "%", "**", "==", "!=", "<", ">=", "&&", "||", "??", "!",
+	"=", "+=", "|>", "in", "not",
+	"let ", "const ", "function ", "return ", "if ", "else ", "try ", "catch ",
+	"finally ", "throw ", "match ", "_ =>", "enum ",
+	"x", "f", "null", "true", "false", "0", "-1", "1.5", "9223372036854775807",
+	`""`, `"s"`, "`${x}`", "[]", "{}", "[1, 2]", "{a: 1}", "f(x)", "x.y",
+	"x[0]", "x[1:]", "() => x", "function(a) { return a }", "(1, 2)",
+	"range(1000000)", "\"ab\" * 1000", "len(x)", "type(x)",
+}
+
+// Generator produces programs for fuzzing by mutating seed programs. The
+// sequence of programs is determined by the random seed, so a run can be
+// repeated.
+type Generator struct {
+	rng   *rand.Rand
+	seeds []string
+}
+
+// NewGenerator returns a generator that mutates the given seed programs,
+// or Seeds if none are given.
+func NewGenerator(seed int64, seeds []string) *Generator {
+	if len(seeds) == 0 {
+		seeds = Seeds
+	}
+	return &Generator{rng: rand.New(rand.NewSource(seed)), seeds: seeds}
+}
+
+// Next returns a new program: a seed with one to four random mutations.
+func (g *Generator) Next() string {
+	src := g.seeds[g.rng.Intn(len(g.seeds))]
+	for n := 1 + g.rng.Intn(4); n > 0; n-- {
+		src = g.mutate(src)
+	}
+	return src
+}
+
+// mutate applies one random change to src: inserting a fragment, deleting
+// or duplicating a span, or splicing in part of another seed.
+func (g *Generator) mutate(src string) string {
+	pos := g.rng.Intn(len(src) + 1)
+	end := pos + g.rng.Intn(len(src)-pos+1)
+	switch g.rng.Intn(4) {
+	case 0:
+		return src[:pos] + fragments[g.rng.Intn(len(fragments))] + src[pos:]
+	case 1:
+		return src[:pos] + src[end:]
+	case 2:
+		return src[:end] + src[pos:end] + src[end:]
+	default:
+		other := g.seeds[g.rng.Intn(len(g.seeds))]
+		cut := g.rng.Intn(len(other) + 1)
+		return src[:pos] + other[cut:]
+	}
+}
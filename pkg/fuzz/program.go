@@ -0,0 +1,322 @@
+package fuzz
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/ast"
+)
+
+// valueType is the type of value an expression built by ProgramGenerator
+// evaluates to.
+type valueType int
+
+const (
+	intType valueType = iota
+	boolType
+	stringType
+	listType
+	numTypes
+)
+
+type genVar struct {
+	name string
+	typ  valueType
+}
+
+type genFunc struct {
+	name  string
+	arity int
+}
+
+// ProgramGenerator builds random programs as ASTs. Unlike Generator, which
+// mutates source text and mostly produces syntax errors, every program it
+// builds compiles: variables are declared before use, assignments keep a
+// variable's type, and functions are called with the right number of
+// arguments. Programs can still fail at run time, for example by dividing
+// by zero. Each program ends with a list of its top-level variables, so
+// the result reflects the whole program state. The sequence of programs is
+// determined by the random seed.
+type ProgramGenerator struct {
+	rng   *rand.Rand
+	vars  []genVar
+	funcs []genFunc
+	names int
+	depth int
+}
+
+// Limits on the size of generated programs.
+const (
+	maxExprDepth  = 4
+	maxBlockDepth = 2
+	maxStmts      = 12
+)
+
+// NewProgramGenerator returns a generator seeded with seed.
+func NewProgramGenerator(seed int64) *ProgramGenerator {
+	return &ProgramGenerator{rng: rand.New(rand.NewSource(seed))}
+}
+
+// Program returns a new random program.
+func (g *ProgramGenerator) Program() *ast.Program {
+	g.vars, g.funcs, g.names, g.depth = nil, nil, 0, 0
+	program := &ast.Program{}
+	for n := 1 + g.rng.Intn(maxStmts); n > 0; n-- {
+		program.Stmts = append(program.Stmts, g.stmt(true))
+	}
+	result := &ast.List{}
+	for _, v := range g.vars {
+		result.Items = append(result.Items, ident(v.name))
+	}
+	program.Stmts = append(program.Stmts, result)
+	return program
+}
+
+func (g *ProgramGenerator) newName(prefix string) string {
+	g.names++
+	return fmt.Sprintf("%s%d", prefix, g.names)
+}
+
+// scope returns a function that drops the variables declared after it was
+// called, for use when a block ends.
+func (g *ProgramGenerator) scope() func() {
+	n := len(g.vars)
+	return func() { g.vars = g.vars[:n] }
+}
+
+func (g *ProgramGenerator) stmt(topLevel bool) ast.Node {
+	switch r := g.rng.Intn(10); {
+	case r < 4 || len(g.vars) == 0:
+		return g.declare()
+	case r < 6:
+		return g.assign()
+	case r < 7 && g.depth < maxBlockDepth:
+		return &ast.If{
+			Cond:        g.expr(boolType, 0),
+			Consequence: g.block(),
+			Alternative: g.optionalBlock(),
+		}
+	case r < 8 && g.depth < maxBlockDepth:
+		return g.try()
+	case r < 9 && topLevel:
+		return g.function()
+	default:
+		return g.declare()
+	}
+}
+
+func (g *ProgramGenerator) declare() ast.Node {
+	typ := valueType(g.rng.Intn(int(numTypes)))
+	value := g.expr(typ, 0)
+	name := g.newName("v")
+	g.vars = append(g.vars, genVar{name: name, typ: typ})
+	return &ast.Var{Name: ident(name), Value: value}
+}
+
+func (g *ProgramGenerator) assign() ast.Node {
+	v := g.vars[g.rng.Intn(len(g.vars))]
+	op := "="
+	if (v.typ == intType || v.typ == stringType) && g.rng.Intn(3) == 0 {
+		op = "+="
+	}
+	return &ast.Assign{Name: ident(v.name), Op: op, Value: g.expr(v.typ, 0)}
+}
+
+func (g *ProgramGenerator) block() *ast.Block {
+	g.depth++
+	defer g.scope()()
+	block := &ast.Block{}
+	for n := 1 + g.rng.Intn(3); n > 0; n-- {
+		block.Stmts = append(block.Stmts, g.stmt(false))
+	}
+	g.depth--
+	return block
+}
+
+func (g *ProgramGenerator) optionalBlock() *ast.Block {
+	if g.rng.Intn(2) == 0 {
+		return nil
+	}
+	return g.block()
+}
+
+// try returns a try/catch statement whose body sometimes throws.
+func (g *ProgramGenerator) try() ast.Node {
+	body := g.block()
+	if g.rng.Intn(2) == 0 {
+		body.Stmts = append(body.Stmts, &ast.Throw{Value: g.expr(stringType, 0)})
+	}
+	node := &ast.Try{Body: body}
+	if g.rng.Intn(4) > 0 {
+		node.CatchIdent = ident(g.newName("e"))
+		node.CatchBlock = g.block()
+	}
+	if node.CatchBlock == nil || g.rng.Intn(3) == 0 {
+		node.FinallyBlock = g.block()
+	}
+	return node
+}
+
+// function returns a named function that takes int parameters and returns
+// an int. It may call the functions declared before it but not itself, so
+// every call terminates.
+func (g *ProgramGenerator) function() ast.Node {
+	name := g.newName("f")
+	arity := g.rng.Intn(3)
+	fn := &ast.Func{Name: ident(name)}
+
+	g.depth++
+	restore := g.scope()
+	for i := 0; i < arity; i++ {
+		param := g.newName("p")
+		fn.Params = append(fn.Params, ident(param))
+		g.vars = append(g.vars, genVar{name: param, typ: intType})
+	}
+	body := &ast.Block{}
+	for n := g.rng.Intn(3); n > 0; n-- {
+		body.Stmts = append(body.Stmts, g.stmt(false))
+	}
+	body.Stmts = append(body.Stmts, &ast.Return{Value: g.expr(intType, 0)})
+	fn.Body = body
+	restore()
+	g.depth--
+
+	g.funcs = append(g.funcs, genFunc{name: name, arity: arity})
+	return fn
+}
+
+// expr returns an expression of the given type.
+func (g *ProgramGenerator) expr(typ valueType, depth int) ast.Expr {
+	if depth >= maxExprDepth || g.rng.Intn(3) == 0 {
+		return g.leaf(typ)
+	}
+	depth++
+	switch typ {
+	case intType:
+		switch g.rng.Intn(8) {
+		case 0:
+			return &ast.Prefix{Op: "-", X: g.expr(intType, depth)}
+		case 1:
+			if g.rng.Intn(2) == 0 {
+				return call(ident("len"), g.expr(stringType, depth))
+			}
+			return call(ident("len"), g.expr(listType, depth))
+		case 2:
+			if len(g.funcs) > 0 {
+				fn := g.funcs[g.rng.Intn(len(g.funcs))]
+				args := make([]ast.Expr, fn.arity)
+				for i := range args {
+					args[i] = g.expr(intType, depth)
+				}
+				return call(ident(fn.name), args...)
+			}
+		case 3:
+			return &ast.If{
+				Cond:        g.expr(boolType, depth),
+				Consequence: &ast.Block{Stmts: []ast.Node{g.expr(intType, depth)}},
+				Alternative: &ast.Block{Stmts: []ast.Node{g.expr(intType, depth)}},
+			}
+		case 4:
+			// Indexes are kept small so that most programs run to the end
+			if g.rng.Intn(4) == 0 {
+				return &ast.Index{X: g.expr(listType, depth), Index: intLit(int64(g.rng.Intn(2)))}
+			}
+		}
+		ops := []string{"+", "-", "*", "+", "-", "*", "/", "%"}
+		op := ops[g.rng.Intn(len(ops))]
+		if (op == "/" || op == "%") && g.rng.Intn(4) > 0 {
+			// Most divisors are nonzero so that most programs run to the end
+			return infix(g.expr(intType, depth), op, intLit(int64(1+g.rng.Intn(9))))
+		}
+		return infix(g.expr(intType, depth), op, g.expr(intType, depth))
+	case boolType:
+		switch g.rng.Intn(6) {
+		case 0:
+			return &ast.Prefix{Op: "!", X: g.expr(boolType, depth)}
+		case 1:
+			ops := []string{"&&", "||"}
+			return infix(g.expr(boolType, depth), ops[g.rng.Intn(len(ops))], g.expr(boolType, depth))
+		case 2:
+			return &ast.In{X: g.expr(intType, depth), Y: g.expr(listType, depth)}
+		case 3:
+			ops := []string{"==", "!="}
+			return infix(g.expr(stringType, depth), ops[g.rng.Intn(len(ops))], g.expr(stringType, depth))
+		}
+		ops := []string{"<", "<=", ">", ">=", "==", "!="}
+		return infix(g.expr(intType, depth), ops[g.rng.Intn(len(ops))], g.expr(intType, depth))
+	case stringType:
+		switch g.rng.Intn(3) {
+		case 0:
+			return call(ident("string"), g.expr(intType, depth))
+		case 1:
+			return &ast.Slice{X: g.expr(stringType, depth), Low: intLit(int64(g.rng.Intn(2)))}
+		}
+		return infix(g.expr(stringType, depth), "+", g.expr(stringType, depth))
+	default:
+		if g.rng.Intn(2) == 0 {
+			return infix(g.expr(listType, depth), "+", g.expr(listType, depth))
+		}
+		list := &ast.List{}
+		for n := g.rng.Intn(4); n > 0; n-- {
+			list.Items = append(list.Items, g.expr(intType, depth))
+		}
+		return list
+	}
+}
+
+// leaf returns a literal or a variable of the given type.
+func (g *ProgramGenerator) leaf(typ valueType) ast.Expr {
+	if g.rng.Intn(2) == 0 {
+		var names []string
+		for _, v := range g.vars {
+			if v.typ == typ {
+				names = append(names, v.name)
+			}
+		}
+		if len(names) > 0 {
+			return ident(names[g.rng.Intn(len(names))])
+		}
+	}
+	switch typ {
+	case intType:
+		if g.rng.Intn(20) == 0 {
+			return intLit(math.MaxInt64)
+		}
+		return intLit(int64(g.rng.Intn(10)))
+	case boolType:
+		b := g.rng.Intn(2) == 0
+		return &ast.Bool{Literal: strconv.FormatBool(b), Value: b}
+	case stringType:
+		strs := []string{"", "a", "risor", "héllo", "\n"}
+		s := strs[g.rng.Intn(len(strs))]
+		return &ast.String{Literal: strconv.Quote(s), Value: s}
+	default:
+		list := &ast.List{}
+		for n := g.rng.Intn(4); n > 0; n-- {
+			list.Items = append(list.Items, g.leaf(intType))
+		}
+		return list
+	}
+}
+
+func ident(name string) *ast.Ident {
+	return &ast.Ident{Name: name}
+}
+
+func intLit(v int64) *ast.Int {
+	return &ast.Int{Literal: strconv.FormatInt(v, 10), Value: v}
+}
+
+func infix(x ast.Expr, op string, y ast.Expr) *ast.Infix {
+	return &ast.Infix{X: x, Op: op, Y: y}
+}
+
+func call(fun ast.Expr, args ...ast.Expr) *ast.Call {
+	node := &ast.Call{Fun: fun}
+	for _, arg := range args {
+		node.Args = append(node.Args, arg)
+	}
+	return node
+}
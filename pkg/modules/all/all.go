@@ -0,0 +1,40 @@
+// Package all links in every standard module, registering their
+// documentation with the object package. Tools that document modules, such
+// as risor doc, import it for its side effects:
+//
+//	import _ "github.com/deepnoodle-ai/risor/v2/pkg/modules/all"
+//
+// The broker-specific queue packages, the bbolt store backend, and the
+// proto, render, sqlite, and unicode modules are separate Go modules and are
+// not included.
+package all
+
+import (
+	_ "github.com/deepnoodle-ai/risor/v2/pkg/modules/atexit"
+	_ "github.com/deepnoodle-ai/risor/v2/pkg/modules/binary"
+	_ "github.com/deepnoodle-ai/risor/v2/pkg/modules/cache"
+	_ "github.com/deepnoodle-ai/risor/v2/pkg/modules/ctx"
+	_ "github.com/deepnoodle-ai/risor/v2/pkg/modules/diff"
+	_ "github.com/deepnoodle-ai/risor/v2/pkg/modules/errors"
+	_ "github.com/deepnoodle-ai/risor/v2/pkg/modules/events"
+	_ "github.com/deepnoodle-ai/risor/v2/pkg/modules/flags"
+	_ "github.com/deepnoodle-ai/risor/v2/pkg/modules/funcs"
+	_ "github.com/deepnoodle-ai/risor/v2/pkg/modules/iters"
+	_ "github.com/deepnoodle-ai/risor/v2/pkg/modules/math"
+	_ "github.com/deepnoodle-ai/risor/v2/pkg/modules/net"
+	_ "github.com/deepnoodle-ai/risor/v2/pkg/modules/os"
+	_ "github.com/deepnoodle-ai/risor/v2/pkg/modules/parallel"
+	_ "github.com/deepnoodle-ai/risor/v2/pkg/modules/prompt"
+	_ "github.com/deepnoodle-ai/risor/v2/pkg/modules/queue"
+	_ "github.com/deepnoodle-ai/risor/v2/pkg/modules/rand"
+	_ "github.com/deepnoodle-ai/risor/v2/pkg/modules/rate"
+	_ "github.com/deepnoodle-ai/risor/v2/pkg/modules/regexp"
+	_ "github.com/deepnoodle-ai/risor/v2/pkg/modules/retry"
+	_ "github.com/deepnoodle-ai/risor/v2/pkg/modules/stats"
+	_ "github.com/deepnoodle-ai/risor/v2/pkg/modules/store"
+	_ "github.com/deepnoodle-ai/risor/v2/pkg/modules/sync"
+	_ "github.com/deepnoodle-ai/risor/v2/pkg/modules/table"
+	_ "github.com/deepnoodle-ai/risor/v2/pkg/modules/task"
+	_ "github.com/deepnoodle-ai/risor/v2/pkg/modules/term"
+	_ "github.com/deepnoodle-ai/risor/v2/pkg/modules/valid"
+)
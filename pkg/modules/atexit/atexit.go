@@ -0,0 +1,36 @@
+package atexit
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+)
+
+// Register schedules a function to run after the main program finishes,
+// whether it completes normally or fails with an error. Hooks run in reverse
+// registration order. Each hook receives the error the program failed with,
+// or null on success. Returns the function so it can be used inline.
+func Register(ctx context.Context, args ...object.Object) (object.Object, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("atexit.register: expected 1 argument, got %d", len(args))
+	}
+	fn, ok := args[0].(object.Callable)
+	if !ok {
+		return nil, object.TypeErrorf("atexit.register: expected a function (got %s)", args[0].Type())
+	}
+	register, ok := object.GetExitHookFunc(ctx)
+	if !ok {
+		return nil, fmt.Errorf("atexit.register: exit hooks are not supported in this context")
+	}
+	if err := register(fn); err != nil {
+		return nil, err
+	}
+	return args[0], nil
+}
+
+func Module() *object.Module {
+	return object.NewBuiltinsModule("atexit", map[string]object.Object{
+		"register": object.NewBuiltin("register", Register),
+	})
+}
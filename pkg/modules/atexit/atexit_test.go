@@ -0,0 +1,46 @@
+package atexit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+func TestRegister(t *testing.T) {
+	var hooks []object.Callable
+	ctx := object.WithExitHookFunc(context.Background(), func(fn object.Callable) error {
+		hooks = append(hooks, fn)
+		return nil
+	})
+	fn := object.NewBuiltin("flush", func(ctx context.Context, args ...object.Object) (object.Object, error) {
+		return object.Nil, nil
+	})
+
+	result, err := Register(ctx, fn)
+	assert.Nil(t, err)
+	assert.Equal(t, result, object.Object(fn))
+	assert.Len(t, hooks, 1)
+}
+
+func TestRegisterErrors(t *testing.T) {
+	ctx := context.Background()
+
+	// Wrong argument count
+	_, err := Register(ctx)
+	assert.NotNil(t, err)
+
+	// Not callable
+	_, err = Register(ctx, object.NewInt(1))
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "expected a function")
+
+	// No VM in the context
+	fn := object.NewBuiltin("noop", func(ctx context.Context, args ...object.Object) (object.Object, error) {
+		return object.Nil, nil
+	})
+	_, err = Register(ctx, fn)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "not supported")
+}
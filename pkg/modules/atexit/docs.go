@@ -0,0 +1,21 @@
+package atexit
+
+import "github.com/deepnoodle-ai/risor/v2/pkg/object"
+
+func init() {
+	object.RegisterModule("atexit", ModuleDoc(), Docs)
+}
+
+// Docs returns documentation for the atexit module.
+func Docs() []object.FuncSpec {
+	return atexitDocs
+}
+
+// ModuleDoc returns the module-level documentation.
+func ModuleDoc() string {
+	return "Functions that run when the program exits"
+}
+
+var atexitDocs = []object.FuncSpec{
+	{Name: "register", Doc: "Run a function after the program finishes", Args: []string{"fn"}, Returns: "function"},
+}
@@ -0,0 +1,353 @@
+package binary
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+)
+
+// field is one code of a format string with its repeat count. For "s" the
+// count is the length of the byte string instead.
+type field struct {
+	code  byte
+	count int
+}
+
+// sizes holds the packed size in bytes of each format code.
+var sizes = map[byte]int{
+	'x': 1, '?': 1, 'b': 1, 'B': 1, 'h': 2, 'H': 2, 'i': 4, 'I': 4,
+	'l': 4, 'L': 4, 'q': 8, 'Q': 8, 'f': 4, 'd': 8, 's': 1,
+}
+
+// byteOrder reads and appends integers in a fixed byte order.
+type byteOrder interface {
+	binary.ByteOrder
+	binary.AppendByteOrder
+}
+
+// parseFormat parses a format string such as "<HHI4s" into its byte order
+// and fields. Without a byte order prefix, the native order is used.
+func parseFormat(fn, format string) (byteOrder, []field, error) {
+	var order byteOrder = binary.NativeEndian
+	if len(format) > 0 {
+		switch format[0] {
+		case '<':
+			order, format = binary.LittleEndian, format[1:]
+		case '>', '!':
+			order, format = binary.BigEndian, format[1:]
+		case '=':
+			format = format[1:]
+		}
+	}
+	var fields []field
+	for i := 0; i < len(format); i++ {
+		c := format[i]
+		if c == ' ' || c == '\t' || c == '\n' {
+			continue
+		}
+		count, hasCount := 0, false
+		for ; i < len(format) && format[i] >= '0' && format[i] <= '9'; i++ {
+			count = count*10 + int(format[i]-'0')
+			hasCount = true
+			if count > math.MaxInt32 {
+				return nil, nil, object.ValueErrorf("binary.%s: repeat count too large in format", fn)
+			}
+		}
+		if i == len(format) {
+			return nil, nil, object.ValueErrorf("binary.%s: format ends with a repeat count", fn)
+		}
+		c = format[i]
+		if _, ok := sizes[c]; !ok {
+			return nil, nil, object.ValueErrorf("binary.%s: bad format character %q", fn, string(c))
+		}
+		if !hasCount {
+			count = 1
+		}
+		fields = append(fields, field{code: c, count: count})
+	}
+	return order, fields, nil
+}
+
+// formatSize returns the number of bytes the fields pack into.
+func formatSize(fields []field) int {
+	size := 0
+	for _, f := range fields {
+		size += sizes[f.code] * f.count
+	}
+	return size
+}
+
+// valueCount returns the number of values the fields pack or unpack.
+func valueCount(fields []field) int {
+	n := 0
+	for _, f := range fields {
+		switch f.code {
+		case 'x':
+		case 's':
+			n++
+		default:
+			n += f.count
+		}
+	}
+	return n
+}
+
+// intRanges holds the bounds of each signed or unsigned integer code.
+var intRanges = map[byte][2]int64{
+	'b': {math.MinInt8, math.MaxInt8},
+	'B': {0, math.MaxUint8},
+	'h': {math.MinInt16, math.MaxInt16},
+	'H': {0, math.MaxUint16},
+	'i': {math.MinInt32, math.MaxInt32},
+	'I': {0, math.MaxUint32},
+	'l': {math.MinInt32, math.MaxInt32},
+	'L': {0, math.MaxUint32},
+	'q': {math.MinInt64, math.MaxInt64},
+	'Q': {0, math.MaxInt64},
+}
+
+// packValue appends the encoding of value for a single code to buf.
+func packValue(buf []byte, order byteOrder, code byte, value object.Object) ([]byte, error) {
+	switch code {
+	case '?':
+		b, err := object.AsBool(value)
+		if err != nil {
+			return nil, err
+		}
+		if b {
+			return append(buf, 1), nil
+		}
+		return append(buf, 0), nil
+	case 'f', 'd':
+		f, err := object.AsFloat(value)
+		if err != nil {
+			return nil, err
+		}
+		if code == 'f' {
+			return order.AppendUint32(buf, math.Float32bits(float32(f))), nil
+		}
+		return order.AppendUint64(buf, math.Float64bits(f)), nil
+	}
+	n, err := object.AsInt(value)
+	if err != nil {
+		return nil, err
+	}
+	if r := intRanges[code]; n < r[0] || n > r[1] {
+		return nil, object.ValueErrorf("binary.pack: %d is out of range for format %q", n, string(code))
+	}
+	switch sizes[code] {
+	case 1:
+		return append(buf, byte(n)), nil
+	case 2:
+		return order.AppendUint16(buf, uint16(n)), nil
+	case 4:
+		return order.AppendUint32(buf, uint32(n)), nil
+	default:
+		return order.AppendUint64(buf, uint64(n)), nil
+	}
+}
+
+// unpackValue decodes a single code from the start of data.
+func unpackValue(data []byte, order byteOrder, code byte) (object.Object, error) {
+	switch code {
+	case '?':
+		return object.NewBool(data[0] != 0), nil
+	case 'b':
+		return object.NewInt(int64(int8(data[0]))), nil
+	case 'B':
+		return object.NewInt(int64(data[0])), nil
+	case 'h':
+		return object.NewInt(int64(int16(order.Uint16(data)))), nil
+	case 'H':
+		return object.NewInt(int64(order.Uint16(data))), nil
+	case 'i', 'l':
+		return object.NewInt(int64(int32(order.Uint32(data)))), nil
+	case 'I', 'L':
+		return object.NewInt(int64(order.Uint32(data))), nil
+	case 'q':
+		return object.NewInt(int64(order.Uint64(data))), nil
+	case 'Q':
+		n := order.Uint64(data)
+		if n > math.MaxInt64 {
+			return nil, object.ValueErrorf("binary.unpack: %d is too large for an int", n)
+		}
+		return object.NewInt(int64(n)), nil
+	case 'f':
+		return object.NewFloat(float64(math.Float32frombits(order.Uint32(data)))), nil
+	default:
+		return object.NewFloat(math.Float64frombits(order.Uint64(data))), nil
+	}
+}
+
+// Pack encodes values according to a format string and returns the bytes.
+func Pack(ctx context.Context, args ...object.Object) (object.Object, error) {
+	if len(args) < 1 {
+		return nil, fmt.Errorf("binary.pack: expected at least 1 argument, got 0")
+	}
+	format, err := object.AsString(args[0])
+	if err != nil {
+		return nil, err
+	}
+	order, fields, err := parseFormat("pack", format)
+	if err != nil {
+		return nil, err
+	}
+	values := args[1:]
+	if want := valueCount(fields); len(values) != want {
+		return nil, fmt.Errorf("binary.pack: format %q expects %d values, got %d", format, want, len(values))
+	}
+	buf := make([]byte, 0, formatSize(fields))
+	for _, f := range fields {
+		switch f.code {
+		case 'x':
+			buf = append(buf, make([]byte, f.count)...)
+		case 's':
+			data, err := object.AsBytes(values[0])
+			if err != nil {
+				return nil, err
+			}
+			values = values[1:]
+			padded := make([]byte, f.count)
+			copy(padded, data)
+			buf = append(buf, padded...)
+		default:
+			for range f.count {
+				if buf, err = packValue(buf, order, f.code, values[0]); err != nil {
+					return nil, err
+				}
+				values = values[1:]
+			}
+		}
+	}
+	return object.NewBytes(buf), nil
+}
+
+// Unpack decodes values from data according to a format string, starting
+// at an optional offset. Bytes beyond those the format describes are
+// ignored.
+func Unpack(ctx context.Context, args ...object.Object) (object.Object, error) {
+	if len(args) < 2 || len(args) > 3 {
+		return nil, object.NewArgsRangeError("binary.unpack", 2, 3, len(args))
+	}
+	format, err := object.AsString(args[0])
+	if err != nil {
+		return nil, err
+	}
+	data, err := object.AsBytes(args[1])
+	if err != nil {
+		return nil, err
+	}
+	if len(args) == 3 {
+		offset, err := object.AsInt(args[2])
+		if err != nil {
+			return nil, err
+		}
+		if offset < 0 || offset > int64(len(data)) {
+			return nil, object.ValueErrorf("binary.unpack: offset %d is out of range for %d bytes", offset, len(data))
+		}
+		data = data[offset:]
+	}
+	order, fields, err := parseFormat("unpack", format)
+	if err != nil {
+		return nil, err
+	}
+	if size := formatSize(fields); len(data) < size {
+		return nil, object.ValueErrorf("binary.unpack: format %q needs %d bytes, got %d", format, size, len(data))
+	}
+	values := make([]object.Object, 0, valueCount(fields))
+	for _, f := range fields {
+		switch f.code {
+		case 'x':
+			data = data[f.count:]
+		case 's':
+			values = append(values, object.NewBytes(append([]byte(nil), data[:f.count]...)))
+			data = data[f.count:]
+		default:
+			size := sizes[f.code]
+			for range f.count {
+				value, err := unpackValue(data, order, f.code)
+				if err != nil {
+					return nil, err
+				}
+				values = append(values, value)
+				data = data[size:]
+			}
+		}
+	}
+	return object.NewList(values), nil
+}
+
+// Size returns the number of bytes a format string describes.
+func Size(ctx context.Context, args ...object.Object) (object.Object, error) {
+	if len(args) != 1 {
+		return nil, object.NewArgsError("binary.size", 1, len(args))
+	}
+	format, err := object.AsString(args[0])
+	if err != nil {
+		return nil, err
+	}
+	_, fields, err := parseFormat("size", format)
+	if err != nil {
+		return nil, err
+	}
+	return object.NewInt(int64(formatSize(fields))), nil
+}
+
+// Hexdump returns a dump of data in the layout of "hexdump -C": offsets,
+// hex bytes, and printable ASCII.
+func Hexdump(ctx context.Context, args ...object.Object) (object.Object, error) {
+	if len(args) != 1 {
+		return nil, object.NewArgsError("binary.hexdump", 1, len(args))
+	}
+	data, err := object.AsBytes(args[0])
+	if err != nil {
+		return nil, err
+	}
+	return object.NewString(hex.Dump(data)), nil
+}
+
+// Bits returns count bits of data starting at bit offset, as an unsigned
+// int. Bits are numbered from the most significant bit of the first byte.
+func Bits(ctx context.Context, args ...object.Object) (object.Object, error) {
+	if len(args) != 3 {
+		return nil, object.NewArgsError("binary.bits", 3, len(args))
+	}
+	data, err := object.AsBytes(args[0])
+	if err != nil {
+		return nil, err
+	}
+	offset, err := object.AsInt(args[1])
+	if err != nil {
+		return nil, err
+	}
+	count, err := object.AsInt(args[2])
+	if err != nil {
+		return nil, err
+	}
+	if count < 1 || count > 63 {
+		return nil, object.ValueErrorf("binary.bits: count must be between 1 and 63 (got %d)", count)
+	}
+	if offset < 0 || offset+count > int64(len(data))*8 {
+		return nil, object.ValueErrorf("binary.bits: bits %d to %d are out of range for %d bytes", offset, offset+count, len(data))
+	}
+	var result int64
+	for bit := offset; bit < offset+count; bit++ {
+		b := data[bit/8] >> (7 - bit%8) & 1
+		result = result<<1 | int64(b)
+	}
+	return object.NewInt(result), nil
+}
+
+func Module() *object.Module {
+	return object.NewBuiltinsModule("binary", map[string]object.Object{
+		"pack":    object.NewBuiltin("pack", Pack),
+		"unpack":  object.NewBuiltin("unpack", Unpack),
+		"size":    object.NewBuiltin("size", Size),
+		"hexdump": object.NewBuiltin("hexdump", Hexdump),
+		"bits":    object.NewBuiltin("bits", Bits),
+	})
+}
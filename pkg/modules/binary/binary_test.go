@@ -0,0 +1,125 @@
+package binary
+
+import (
+	"context"
+	"testing"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+func ints(values ...int64) []object.Object {
+	result := make([]object.Object, len(values))
+	for i, v := range values {
+		result[i] = object.NewInt(v)
+	}
+	return result
+}
+
+func pack(t *testing.T, format string, values ...object.Object) []byte {
+	t.Helper()
+	args := append([]object.Object{object.NewString(format)}, values...)
+	result, err := Pack(context.Background(), args...)
+	assert.Nil(t, err)
+	return result.(*object.Bytes).Value()
+}
+
+func TestPack(t *testing.T) {
+	assert.Equal(t, pack(t, ">HI", ints(1, 2)...), []byte{0, 1, 0, 0, 0, 2})
+	assert.Equal(t, pack(t, "<HI", ints(1, 2)...), []byte{1, 0, 2, 0, 0, 0})
+	assert.Equal(t, pack(t, "!b2x?", object.NewInt(-1), object.True), []byte{0xff, 0, 0, 1})
+	assert.Equal(t, pack(t, ">3B", ints(1, 2, 3)...), []byte{1, 2, 3})
+	assert.Equal(t, pack(t, "4s2s", object.NewString("ab"), object.NewBytes([]byte("xyz"))),
+		[]byte{'a', 'b', 0, 0, 'x', 'y'})
+	assert.Equal(t, pack(t, ">f", object.NewFloat(1.5)), []byte{0x3f, 0xc0, 0, 0})
+	assert.Equal(t, len(pack(t, "qQd", ints(-1, 1, 2)...)), 24)
+}
+
+func TestPackErrors(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		args     []object.Object
+		contains string
+	}{
+		{[]object.Object{object.NewString("B"), object.NewInt(256)}, "out of range"},
+		{[]object.Object{object.NewString("H"), object.NewInt(-1)}, "out of range"},
+		{[]object.Object{object.NewString("HH"), object.NewInt(1)}, "expects 2 values, got 1"},
+		{[]object.Object{object.NewString("z"), object.NewInt(1)}, "bad format character"},
+		{[]object.Object{object.NewString("4"), object.NewInt(1)}, "ends with a repeat count"},
+		{[]object.Object{object.NewString("i"), object.NewString("1")}, "expected an int"},
+	}
+	for _, tc := range tests {
+		_, err := Pack(ctx, tc.args...)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), tc.contains)
+	}
+}
+
+func TestUnpack(t *testing.T) {
+	ctx := context.Background()
+	data := object.NewBytes([]byte{0xff, 0xfe, 0, 0, 0, 2, 'h', 'i', 0x3f, 0xc0, 0, 0, 9})
+	result, err := Unpack(ctx, object.NewString(">hI2sf"), data)
+	assert.Nil(t, err)
+	assert.Equal(t, result.Inspect(), `[-2, 2, bytes("hi"), 1.5]`)
+
+	result, err = Unpack(ctx, object.NewString("<H"), data, object.NewInt(1))
+	assert.Nil(t, err)
+	assert.Equal(t, result.Inspect(), "[254]")
+
+	result, err = Unpack(ctx, object.NewString(">2xB?"), object.NewBytes([]byte{1, 2, 3, 4}))
+	assert.Nil(t, err)
+	assert.Equal(t, result.Inspect(), "[3, true]")
+
+	_, err = Unpack(ctx, object.NewString(">Q"), object.NewBytes([]byte{1, 2}))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "needs 8 bytes, got 2")
+	_, err = Unpack(ctx, object.NewString(">Q"), object.NewBytes([]byte{0xff, 0, 0, 0, 0, 0, 0, 0}))
+	assert.Error(t, err)
+	_, err = Unpack(ctx, object.NewString("B"), data, object.NewInt(20))
+	assert.Error(t, err)
+}
+
+func TestRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	format := object.NewString("<bBhHiIqQfd?")
+	values := append(ints(-128, 255, -32768, 65535, -1, 4294967295, -9, 9),
+		object.NewFloat(0.25), object.NewFloat(-2.5), object.False)
+	packed, err := Pack(ctx, append([]object.Object{format}, values...)...)
+	assert.Nil(t, err)
+	size, err := Size(ctx, format)
+	assert.Nil(t, err)
+	assert.Equal(t, size, object.NewInt(int64(len(packed.(*object.Bytes).Value()))))
+	result, err := Unpack(ctx, format, packed)
+	assert.Nil(t, err)
+	assert.Equal(t, result, object.NewList(values))
+}
+
+func TestHexdump(t *testing.T) {
+	result, err := Hexdump(context.Background(), object.NewString("Hello, hexdump!\n"))
+	assert.Nil(t, err)
+	assert.Equal(t, result.(*object.String).Value(),
+		"00000000  48 65 6c 6c 6f 2c 20 68  65 78 64 75 6d 70 21 0a  |Hello, hexdump!.|\n")
+}
+
+func TestBits(t *testing.T) {
+	ctx := context.Background()
+	data := object.NewBytes([]byte{0b1010_0110, 0b1100_0001})
+	tests := []struct {
+		offset, count, expected int64
+	}{
+		{0, 1, 1},
+		{1, 3, 0b010},
+		{4, 8, 0b0110_1100},
+		{0, 16, 0b1010_0110_1100_0001},
+		{15, 1, 1},
+	}
+	for _, tc := range tests {
+		result, err := Bits(ctx, data, object.NewInt(tc.offset), object.NewInt(tc.count))
+		assert.Nil(t, err)
+		assert.Equal(t, result, object.NewInt(tc.expected), "offset %d, count %d", tc.offset, tc.count)
+	}
+	_, err := Bits(ctx, data, object.NewInt(10), object.NewInt(7))
+	assert.Error(t, err)
+	_, err = Bits(ctx, data, object.NewInt(0), object.NewInt(0))
+	assert.Error(t, err)
+}
@@ -0,0 +1,25 @@
+package binary
+
+import "github.com/deepnoodle-ai/risor/v2/pkg/object"
+
+func init() {
+	object.RegisterModule("binary", ModuleDoc(), Docs)
+}
+
+// Docs returns documentation for the binary module.
+func Docs() []object.FuncSpec {
+	return binaryDocs
+}
+
+// ModuleDoc returns the module-level documentation.
+func ModuleDoc() string {
+	return "Pack and unpack binary data"
+}
+
+var binaryDocs = []object.FuncSpec{
+	{Name: "pack", Doc: "Encode values using a format string", Args: []string{"format", "values..."}, Returns: "bytes"},
+	{Name: "unpack", Doc: "Decode values using a format string", Args: []string{"format", "data", "offset?"}, Returns: "list"},
+	{Name: "size", Doc: "Number of bytes a format describes", Args: []string{"format"}, Returns: "int"},
+	{Name: "hexdump", Doc: "Hex and ASCII dump of data", Args: []string{"data"}, Returns: "string"},
+	{Name: "bits", Doc: "Read a range of bits as an int", Args: []string{"data", "offset", "count"}, Returns: "int"},
+}
@@ -0,0 +1,143 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+)
+
+// Option configures the cache module.
+type Option func(*config)
+
+type config struct {
+	store *Store
+}
+
+// WithStore lets scripts open named caches from store with cache.shared.
+// A host that passes the same store to every run shares those caches, and
+// what they hold, between runs. Without a store cache.shared fails.
+func WithStore(store *Store) Option {
+	return func(c *config) {
+		c.store = store
+	}
+}
+
+// Store holds named caches that outlive a single run. It is safe for
+// concurrent use.
+type Store struct {
+	mu     sync.Mutex
+	caches map[string]*Cache
+}
+
+// NewStore returns an empty store.
+func NewStore() *Store {
+	return &Store{caches: map[string]*Cache{}}
+}
+
+// Open returns the cache named name, creating it with the given size and
+// time to live if it does not exist yet. The options of an existing cache
+// are left as they are.
+func (s *Store) Open(name string, max int, ttl time.Duration) *Cache {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if c, ok := s.caches[name]; ok {
+		return c
+	}
+	c := New(max, ttl)
+	s.caches[name] = c
+	return c
+}
+
+// options reads the "max" and "ttl" options given to fn.
+func options(fn string, arg object.Object) (int, time.Duration, error) {
+	m, ok := arg.(*object.Map)
+	if !ok {
+		return 0, 0, object.TypeErrorf("%s() expected a map of options (%s given)", fn, arg.Type())
+	}
+	var max int
+	var ttl time.Duration
+	if err := m.CheckStringKeys(); err != nil {
+		return 0, 0, fmt.Errorf("%s: %w", fn, err)
+	}
+	for _, key := range m.SortedKeys() {
+		value := m.Get(key)
+		switch key {
+		case "max":
+			n, err := object.AsInt(value)
+			if err != nil {
+				return 0, 0, fmt.Errorf("%s: max: %w", fn, err)
+			}
+			if n < 0 {
+				return 0, 0, object.ValueErrorf("%s: max must not be negative (got %d)", fn, n)
+			}
+			max = int(n)
+		case "ttl":
+			d, err := object.AsDuration(value)
+			if err != nil {
+				return 0, 0, fmt.Errorf("%s: ttl: %w", fn, err)
+			}
+			if d < 0 {
+				return 0, 0, object.ValueErrorf("%s: ttl must not be negative (got %s)", fn, value.Inspect())
+			}
+			ttl = d
+		default:
+			return 0, 0, fmt.Errorf("%s: unknown option %q", fn, key)
+		}
+	}
+	return max, ttl, nil
+}
+
+// NewCache creates a cache for the current run. The optional map accepts
+// "max", the number of entries kept, and "ttl", how long entries live.
+// Both default to no limit.
+func NewCache(ctx context.Context, args ...object.Object) (object.Object, error) {
+	if len(args) > 1 {
+		return nil, object.NewArgsRangeError("cache.new", 0, 1, len(args))
+	}
+	var max int
+	var ttl time.Duration
+	if len(args) == 1 {
+		var err error
+		if max, ttl, err = options("cache.new", args[0]); err != nil {
+			return nil, err
+		}
+	}
+	return New(max, ttl), nil
+}
+
+func (c *config) shared(ctx context.Context, args ...object.Object) (object.Object, error) {
+	if len(args) < 1 || len(args) > 2 {
+		return nil, object.NewArgsRangeError("cache.shared", 1, 2, len(args))
+	}
+	if c.store == nil {
+		return nil, fmt.Errorf("cache.shared: shared caches are not enabled by the host")
+	}
+	name, err := object.AsString(args[0])
+	if err != nil {
+		return nil, err
+	}
+	var max int
+	var ttl time.Duration
+	if len(args) == 2 {
+		if max, ttl, err = options("cache.shared", args[1]); err != nil {
+			return nil, err
+		}
+	}
+	return c.store.Open(name, max, ttl), nil
+}
+
+// Module returns the cache module. Options may give it a store of caches
+// shared between runs.
+func Module(opts ...Option) *object.Module {
+	c := &config{}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return object.NewBuiltinsModule("cache", map[string]object.Object{
+		"new":    object.NewBuiltin("new", NewCache),
+		"shared": object.NewBuiltin("shared", c.shared),
+	})
+}
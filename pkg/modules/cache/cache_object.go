@@ -0,0 +1,385 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+	"github.com/deepnoodle-ai/risor/v2/pkg/op"
+)
+
+const CACHE object.Type = "cache"
+
+var cacheMethods = object.NewMethodRegistry[*Cache]("cache")
+
+func init() {
+	cacheMethods.Define("get").
+		Doc("Get the value stored under a key, or a default if it is missing or expired").
+		Arg("key").
+		OptionalArg("default").
+		Returns("object").
+		Impl(func(c *Cache, ctx context.Context, args ...object.Object) (object.Object, error) {
+			key, err := object.AsString(args[0])
+			if err != nil {
+				return nil, err
+			}
+			if value, ok := c.Get(key); ok {
+				return value, nil
+			}
+			if len(args) > 1 {
+				return args[1], nil
+			}
+			return object.Nil, nil
+		})
+
+	cacheMethods.Define("set").
+		Doc("Store a value under a key, optionally with its own time to live").
+		Arg("key").
+		Arg("value").
+		OptionalArg("ttl").
+		Returns("null").
+		Impl(func(c *Cache, ctx context.Context, args ...object.Object) (object.Object, error) {
+			key, err := object.AsString(args[0])
+			if err != nil {
+				return nil, err
+			}
+			ttl, err := c.ttlArg("set", args[2:])
+			if err != nil {
+				return nil, err
+			}
+			if err := c.Set(key, args[1], ttl); err != nil {
+				return nil, err
+			}
+			return object.Nil, nil
+		})
+
+	cacheMethods.Define("get_or_set").
+		Doc("Get the value under a key, calling a loader to compute and store it if missing").
+		Arg("key").
+		Arg("loader").
+		OptionalArg("ttl").
+		Returns("object").
+		Impl(func(c *Cache, ctx context.Context, args ...object.Object) (object.Object, error) {
+			key, err := object.AsString(args[0])
+			if err != nil {
+				return nil, err
+			}
+			loader, ok := args[1].(object.Callable)
+			if !ok {
+				return nil, object.TypeErrorf("cache.get_or_set() expected a function (%s given)", args[1].Type())
+			}
+			ttl, err := c.ttlArg("get_or_set", args[2:])
+			if err != nil {
+				return nil, err
+			}
+			return c.GetOrSet(ctx, key, loader, ttl)
+		})
+
+	cacheMethods.Define("has").
+		Doc("Report whether a key holds a value that has not expired").
+		Arg("key").
+		Returns("bool").
+		Impl(func(c *Cache, ctx context.Context, args ...object.Object) (object.Object, error) {
+			key, err := object.AsString(args[0])
+			if err != nil {
+				return nil, err
+			}
+			_, ok := c.Get(key)
+			return object.NewBool(ok), nil
+		})
+
+	cacheMethods.Define("delete").
+		Doc("Remove a key, returning whether it was present").
+		Arg("key").
+		Returns("bool").
+		Impl(func(c *Cache, ctx context.Context, args ...object.Object) (object.Object, error) {
+			key, err := object.AsString(args[0])
+			if err != nil {
+				return nil, err
+			}
+			return object.NewBool(c.Delete(key)), nil
+		})
+
+	cacheMethods.Define("clear").
+		Doc("Remove every entry").
+		Returns("null").
+		Impl(func(c *Cache, ctx context.Context, args ...object.Object) (object.Object, error) {
+			c.Clear()
+			return object.Nil, nil
+		})
+
+	cacheMethods.Define("keys").
+		Doc("Keys of the entries that have not expired, sorted").
+		Returns("list").
+		Impl(func(c *Cache, ctx context.Context, args ...object.Object) (object.Object, error) {
+			keys := c.Keys()
+			items := make([]object.Object, len(keys))
+			for i, key := range keys {
+				items[i] = object.NewString(key)
+			}
+			return object.NewList(items), nil
+		})
+
+	cacheMethods.Define("size").
+		Doc("Number of entries that have not expired").
+		Returns("int").
+		Impl(func(c *Cache, ctx context.Context, args ...object.Object) (object.Object, error) {
+			return object.NewInt(int64(len(c.Keys()))), nil
+		})
+
+	cacheMethods.Define("stats").
+		Doc("Counts of hits, misses, and evictions").
+		Returns("map").
+		Impl(func(c *Cache, ctx context.Context, args ...object.Object) (object.Object, error) {
+			stats := c.Stats()
+			return object.NewMap(map[string]object.Object{
+				"hits":      object.NewInt(stats.Hits),
+				"misses":    object.NewInt(stats.Misses),
+				"evictions": object.NewInt(stats.Evictions),
+			}), nil
+		})
+}
+
+// entry is a cached value and the key it is stored under.
+type entry struct {
+	key     string
+	value   object.Object
+	expires time.Time // zero if the entry does not expire
+}
+
+// Stats counts how a cache has been used.
+type Stats struct {
+	Hits      int64
+	Misses    int64
+	Evictions int64
+}
+
+// Cache is an in-memory cache with string keys. When it holds more than
+// its maximum number of entries, the least recently used entry is evicted,
+// and entries with a time to live expire once it has passed. It can be
+// shared between goroutines, and between runs when the host shares it.
+//
+// Stored lists and maps are frozen with object.Freeze, since another
+// goroutine could otherwise modify them while they are being read.
+type Cache struct {
+	max int
+	ttl time.Duration
+	now func() time.Time
+
+	mu      sync.Mutex
+	order   *list.List
+	entries map[string]*list.Element
+	stats   Stats
+}
+
+// New returns a cache holding at most max entries, or any number if max is
+// 0. Entries expire after ttl, or never if ttl is 0.
+func New(max int, ttl time.Duration) *Cache {
+	return &Cache{
+		max:     max,
+		ttl:     ttl,
+		now:     time.Now,
+		order:   list.New(),
+		entries: map[string]*list.Element{},
+	}
+}
+
+func (c *Cache) Type() object.Type {
+	return CACHE
+}
+
+func (c *Cache) Inspect() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return fmt.Sprintf("cache(size=%d, max=%d)", c.order.Len(), c.max)
+}
+
+func (c *Cache) String() string {
+	return c.Inspect()
+}
+
+func (c *Cache) Interface() any {
+	return c
+}
+
+func (c *Cache) Equals(other object.Object) bool {
+	return c == other
+}
+
+func (c *Cache) Attrs() []object.AttrSpec {
+	return cacheMethods.Specs()
+}
+
+func (c *Cache) GetAttr(name string) (object.Object, bool) {
+	return cacheMethods.GetAttr(c, name)
+}
+
+func (c *Cache) SetAttr(name string, value object.Object) error {
+	return object.TypeErrorf("cache has no attribute %q", name)
+}
+
+func (c *Cache) IsTruthy() bool {
+	return true
+}
+
+func (c *Cache) RunOperation(opType op.BinaryOpType, right object.Object) (object.Object, error) {
+	return nil, object.TypeErrorf("unsupported operation for cache: %v", opType)
+}
+
+// ttlArg returns the time to live given to a method, or the cache's own if
+// none was given.
+func (c *Cache) ttlArg(method string, args []object.Object) (time.Duration, error) {
+	if len(args) == 0 {
+		return c.ttl, nil
+	}
+	ttl, err := object.AsDuration(args[0])
+	if err != nil {
+		return 0, fmt.Errorf("cache.%s: ttl: %w", method, err)
+	}
+	if ttl < 0 {
+		return 0, object.ValueErrorf("cache.%s: ttl must not be negative (got %s)", method, args[0].Inspect())
+	}
+	return ttl, nil
+}
+
+// lookup returns the live element for key, removing it if it has expired.
+// The caller must hold c.mu.
+func (c *Cache) lookup(key string, now time.Time) (*list.Element, bool) {
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if e := elem.Value.(*entry); !e.expires.IsZero() && !now.Before(e.expires) {
+		c.order.Remove(elem)
+		delete(c.entries, key)
+		return nil, false
+	}
+	return elem, true
+}
+
+// Get returns the value stored under key if it has not expired, and marks
+// it as recently used.
+func (c *Cache) Get(key string) (object.Object, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.lookup(key, c.now())
+	if !ok {
+		c.stats.Misses++
+		return nil, false
+	}
+	c.stats.Hits++
+	c.order.MoveToFront(elem)
+	return elem.Value.(*entry).value, true
+}
+
+// Set stores value under key, expiring after ttl, or never if ttl is 0. If
+// the cache is full, the least recently used entry is evicted.
+func (c *Cache) Set(key string, value object.Object, ttl time.Duration) error {
+	if err := object.Freeze(value); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	var expires time.Time
+	if ttl > 0 {
+		expires = now.Add(ttl)
+	}
+	if elem, ok := c.entries[key]; ok {
+		e := elem.Value.(*entry)
+		e.value, e.expires = value, expires
+		c.order.MoveToFront(elem)
+		return nil
+	}
+	c.entries[key] = c.order.PushFront(&entry{key: key, value: value, expires: expires})
+	if c.max > 0 && c.order.Len() > c.max {
+		c.evict(now)
+	}
+	return nil
+}
+
+// evict makes room for an entry, removing expired entries if there are
+// any and the least recently used entry otherwise. The caller must hold
+// c.mu.
+func (c *Cache) evict(now time.Time) {
+	for elem := c.order.Back(); elem != nil; {
+		prev := elem.Prev()
+		if e := elem.Value.(*entry); !e.expires.IsZero() && !now.Before(e.expires) {
+			c.order.Remove(elem)
+			delete(c.entries, e.key)
+		}
+		elem = prev
+	}
+	for c.order.Len() > c.max {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*entry).key)
+		c.stats.Evictions++
+	}
+}
+
+// GetOrSet returns the value stored under key. If it is missing or
+// expired, it calls loader, stores the result, and returns it. The loader
+// runs without holding the cache's lock, so goroutines that miss the same
+// key at the same time may each call it. A failed load stores nothing.
+func (c *Cache) GetOrSet(ctx context.Context, key string, loader object.Callable, ttl time.Duration) (object.Object, error) {
+	if value, ok := c.Get(key); ok {
+		return value, nil
+	}
+	value, err := loader.Call(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.Set(key, value, ttl); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// Delete removes key and reports whether it held a value that had not
+// expired.
+func (c *Cache) Delete(key string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.lookup(key, c.now())
+	if !ok {
+		return false
+	}
+	c.order.Remove(elem)
+	delete(c.entries, key)
+	return true
+}
+
+// Clear removes every entry.
+func (c *Cache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.order.Init()
+	clear(c.entries)
+}
+
+// Keys returns the keys of the entries that have not expired, sorted.
+func (c *Cache) Keys() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	keys := make([]string, 0, len(c.entries))
+	for key := range c.entries {
+		if _, ok := c.lookup(key, now); ok {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// Stats returns the cache's hit, miss, and eviction counts.
+func (c *Cache) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
@@ -0,0 +1,108 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/deepnoodle-ai/risor/v2/internal/moduletest"
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+// eval runs source with the given cache module and the default builtins.
+func eval(ctx context.Context, module *object.Module, source string) (object.Object, error) {
+	return moduletest.Eval(ctx, source, map[string]any{"cache": module})
+}
+
+func TestCache(t *testing.T) {
+	result, err := eval(context.Background(), Module(), `
+	let c = cache.new({max: 2})
+	c.set("a", 1)
+	c.set("b", 2)
+	c.get("a")
+	c.set("c", 3)
+	[c.keys(), c.get("b", "gone"), c.has("a"), c.delete("a"), c.delete("a"), c.size(), c.stats()]
+	`)
+	assert.Nil(t, err)
+	assert.Equal(t, result.Inspect(),
+		`[["a", "c"], "gone", true, true, false, 1, {"evictions": 1, "hits": 2, "misses": 1}]`)
+}
+
+func TestGetOrSet(t *testing.T) {
+	result, err := eval(context.Background(), Module(), `
+	let c = cache.new()
+	let loads = 0
+	let load = () => { loads += 1; return [loads] }
+	let first = c.get_or_set("k", load)
+	let second = c.get_or_set("k", load)
+	let failed = try { c.get_or_set("bad", () => { throw "nope" }) } catch e { e.message() }
+	let frozen = try { first.append(2) } catch e { "frozen" }
+	[first, second, loads, failed, c.has("bad"), frozen]
+	`)
+	assert.Nil(t, err)
+	assert.Equal(t, result.Inspect(), `[[1], [1], 1, "nope", false, "frozen"]`)
+}
+
+func TestExpiry(t *testing.T) {
+	now := time.Unix(1000, 0)
+	c := New(2, 0)
+	c.now = func() time.Time { return now }
+
+	assert.Nil(t, c.Set("a", object.NewInt(1), time.Minute))
+	assert.Nil(t, c.Set("b", object.NewInt(2), 10*time.Minute))
+	now = now.Add(2 * time.Minute)
+	_, ok := c.Get("a")
+	assert.False(t, ok)
+	_, ok = c.Get("b")
+	assert.True(t, ok)
+
+	// Expired entries are dropped before live ones are evicted
+	assert.Nil(t, c.Set("c", object.NewInt(3), time.Second))
+	now = now.Add(2 * time.Second)
+	assert.Nil(t, c.Set("d", object.NewInt(4), time.Minute))
+	assert.Equal(t, c.Keys(), []string{"b", "d"})
+	assert.Equal(t, c.Stats().Evictions, int64(0))
+
+	// Entries stored with a ttl of zero never expire
+	forever := New(0, 0)
+	forever.now = func() time.Time { return now }
+	assert.Nil(t, forever.Set("k", object.True, 0))
+	now = now.Add(100 * 24 * time.Hour)
+	_, ok = forever.Get("k")
+	assert.True(t, ok)
+}
+
+func TestShared(t *testing.T) {
+	store := NewStore()
+	module := Module(WithStore(store))
+	_, err := eval(context.Background(), module, `cache.shared("users", {ttl: "1h"}).set("42", "Ada")`)
+	assert.Nil(t, err)
+	result, err := eval(context.Background(), module, `cache.shared("users").get("42")`)
+	assert.Nil(t, err)
+	assert.Equal(t, result.Inspect(), `"Ada"`)
+	assert.Equal(t, store.Open("users", 0, 0).Keys(), []string{"42"})
+
+	_, err = eval(context.Background(), Module(), `cache.shared("users")`)
+	assert.Contains(t, err.Error(), "shared caches are not enabled by the host")
+}
+
+func TestOptions(t *testing.T) {
+	tests := []struct {
+		source string
+		want   string
+	}{
+		{`cache.new({max: -1})`, "max must not be negative"},
+		{`cache.new({ttl: "soon"})`, `invalid duration "soon"`},
+		{`cache.new({ttl: -5})`, "ttl must not be negative"},
+		{`cache.new({size: 1})`, `unknown option "size"`},
+		{`cache.new(1)`, "expected a map of options"},
+		{`cache.new().set("k", 1, "later")`, "cache.set: ttl"},
+		{`cache.new().get_or_set("k", 1)`, "expected a function"},
+	}
+	for _, tt := range tests {
+		_, err := eval(context.Background(), Module(), tt.source)
+		assert.NotNil(t, err)
+		assert.Contains(t, err.Error(), tt.want)
+	}
+}
@@ -0,0 +1,22 @@
+package cache
+
+import "github.com/deepnoodle-ai/risor/v2/pkg/object"
+
+func init() {
+	object.RegisterModule("cache", ModuleDoc(), Docs)
+}
+
+// Docs returns documentation for the cache module.
+func Docs() []object.FuncSpec {
+	return cacheDocs
+}
+
+// ModuleDoc returns the module-level documentation.
+func ModuleDoc() string {
+	return "In-memory caches with size limits and expiring entries"
+}
+
+var cacheDocs = []object.FuncSpec{
+	{Name: "new", Doc: "Create a cache, optionally limited by entry count and time to live", Args: []string{"options?"}, Returns: "cache"},
+	{Name: "shared", Doc: "Open a named cache kept by the host between runs", Args: []string{"name", "options?"}, Returns: "cache"},
+}
@@ -0,0 +1,96 @@
+package ctx
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+)
+
+// values returns the context values of the current run.
+func values(ctx context.Context, fn string) (*object.ContextValues, error) {
+	values, ok := object.GetContextValues(ctx)
+	if !ok {
+		return nil, fmt.Errorf("ctx.%s: context values are not available in this context", fn)
+	}
+	return values, nil
+}
+
+// Get returns the context value stored under a key, or the default (null if
+// not given) when the key is not set.
+func Get(ctx context.Context, args ...object.Object) (object.Object, error) {
+	if len(args) < 1 || len(args) > 2 {
+		return nil, object.NewArgsRangeError("ctx.get", 1, 2, len(args))
+	}
+	key, err := object.AsString(args[0])
+	if err != nil {
+		return nil, err
+	}
+	vals, err := values(ctx, "get")
+	if err != nil {
+		return nil, err
+	}
+	if value, ok := vals.Get(key); ok {
+		return value, nil
+	}
+	if len(args) == 2 {
+		return args[1], nil
+	}
+	return object.Nil, nil
+}
+
+// Set stores a context value, making it visible to Go builtins called later
+// in the run and to the host after it returns.
+func Set(ctx context.Context, args ...object.Object) (object.Object, error) {
+	if len(args) != 2 {
+		return nil, object.NewArgsError("ctx.set", 2, len(args))
+	}
+	key, err := object.AsString(args[0])
+	if err != nil {
+		return nil, err
+	}
+	vals, err := values(ctx, "set")
+	if err != nil {
+		return nil, err
+	}
+	vals.Set(key, args[1])
+	return object.Nil, nil
+}
+
+// Has reports whether a context value is set.
+func Has(ctx context.Context, args ...object.Object) (object.Object, error) {
+	if len(args) != 1 {
+		return nil, object.NewArgsError("ctx.has", 1, len(args))
+	}
+	key, err := object.AsString(args[0])
+	if err != nil {
+		return nil, err
+	}
+	vals, err := values(ctx, "has")
+	if err != nil {
+		return nil, err
+	}
+	_, ok := vals.Get(key)
+	return object.NewBool(ok), nil
+}
+
+// Keys returns the keys of the context values in sorted order.
+func Keys(ctx context.Context, args ...object.Object) (object.Object, error) {
+	if len(args) != 0 {
+		return nil, object.NewArgsError("ctx.keys", 0, len(args))
+	}
+	vals, err := values(ctx, "keys")
+	if err != nil {
+		return nil, err
+	}
+	return object.NewStringList(vals.Keys()), nil
+}
+
+func Module() *object.Module {
+	return object.NewBuiltinsModule("ctx", map[string]object.Object{
+		"get":  object.NewBuiltin("get", Get),
+		"set":  object.NewBuiltin("set", Set),
+		"has":  object.NewBuiltin("has", Has),
+		"keys": object.NewBuiltin("keys", Keys),
+	})
+}
@@ -0,0 +1,58 @@
+package ctx
+
+import (
+	"context"
+	"testing"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+func TestGetSet(t *testing.T) {
+	values := object.NewContextValues(map[string]object.Object{
+		"trace_id": object.NewString("abc"),
+	})
+	ctx := object.WithContextValues(context.Background(), values)
+
+	result, err := Get(ctx, object.NewString("trace_id"))
+	assert.Nil(t, err)
+	assert.Equal(t, result, object.NewString("abc"))
+
+	result, err = Get(ctx, object.NewString("missing"))
+	assert.Nil(t, err)
+	assert.Equal(t, result, object.Nil)
+
+	result, err = Get(ctx, object.NewString("missing"), object.NewInt(1))
+	assert.Nil(t, err)
+	assert.Equal(t, result, object.NewInt(1))
+
+	result, err = Set(ctx, object.NewString("user_id"), object.NewInt(7))
+	assert.Nil(t, err)
+	assert.Equal(t, result, object.Nil)
+	value, ok := values.Get("user_id")
+	assert.True(t, ok)
+	assert.Equal(t, value, object.NewInt(7))
+
+	result, err = Has(ctx, object.NewString("user_id"))
+	assert.Nil(t, err)
+	assert.Equal(t, result, object.True)
+
+	result, err = Keys(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, result, object.NewStringList([]string{"trace_id", "user_id"}))
+}
+
+func TestErrors(t *testing.T) {
+	_, err := Get(context.Background(), object.NewString("k"))
+	assert.Error(t, err, "ctx.get: context values are not available in this context")
+
+	ctx := object.WithContextValues(context.Background(), object.NewContextValues(nil))
+	_, err = Get(ctx)
+	assert.NotNil(t, err)
+	_, err = Set(ctx, object.NewString("k"))
+	assert.NotNil(t, err)
+	_, err = Get(ctx, object.NewInt(1))
+	assert.NotNil(t, err)
+	_, err = Keys(ctx, object.NewInt(1))
+	assert.NotNil(t, err)
+}
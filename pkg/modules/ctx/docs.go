@@ -0,0 +1,24 @@
+package ctx
+
+import "github.com/deepnoodle-ai/risor/v2/pkg/object"
+
+func init() {
+	object.RegisterModule("ctx", ModuleDoc(), Docs)
+}
+
+// Docs returns documentation for the ctx module.
+func Docs() []object.FuncSpec {
+	return ctxDocs
+}
+
+// ModuleDoc returns the module-level documentation.
+func ModuleDoc() string {
+	return "Request-scoped values shared with the host application"
+}
+
+var ctxDocs = []object.FuncSpec{
+	{Name: "get", Doc: "Get a context value, or a default if it is not set", Args: []string{"key", "default"}, Returns: "any"},
+	{Name: "has", Doc: "Check whether a context value is set", Args: []string{"key"}, Returns: "bool"},
+	{Name: "keys", Doc: "List the keys of the context values", Args: []string{}, Returns: "list"},
+	{Name: "set", Doc: "Set a context value", Args: []string{"key", "value"}, Returns: "null"},
+}
@@ -0,0 +1,112 @@
+package diff
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+)
+
+// defaultContext is the number of unchanged lines shown around each change
+// in a unified diff.
+const defaultContext = 3
+
+// Text returns a unified diff between two strings, or "" if they are equal.
+func Text(ctx context.Context, args ...object.Object) (object.Object, error) {
+	if len(args) < 2 || len(args) > 3 {
+		return nil, object.NewArgsRangeError("diff.text", 2, 3, len(args))
+	}
+	a, err := object.AsString(args[0])
+	if err != nil {
+		return nil, err
+	}
+	b, err := object.AsString(args[1])
+	if err != nil {
+		return nil, err
+	}
+	from, to, contextLines := "a", "b", defaultContext
+	if len(args) == 3 {
+		opts, err := object.AsMap(args[2])
+		if err != nil {
+			return nil, err
+		}
+		if err := opts.CheckStringKeys(); err != nil {
+			return nil, fmt.Errorf("diff.text: %w", err)
+		}
+		for _, name := range opts.SortedKeys() {
+			switch name {
+			case "context":
+				n, err := object.AsInt(opts.Get(name))
+				if err != nil {
+					return nil, err
+				}
+				if n < 0 {
+					return nil, object.ValueErrorf("diff.text: context must be non-negative, got %d", n)
+				}
+				contextLines = int(n)
+			case "from":
+				if from, err = object.AsString(opts.Get(name)); err != nil {
+					return nil, err
+				}
+			case "to":
+				if to, err = object.AsString(opts.Get(name)); err != nil {
+					return nil, err
+				}
+			default:
+				return nil, object.ValueErrorf("diff.text: unknown option %q", name)
+			}
+		}
+	}
+	edits := diffLines(splitLines(a), splitLines(b))
+	return object.NewString(unified(edits, from, to, contextLines)), nil
+}
+
+// Values returns the changes turning one value into another as a list of
+// JSON Patch operations, each a map with "op", "path", and "value" keys.
+// Removed and replaced values are also recorded under "old".
+func Values(ctx context.Context, args ...object.Object) (object.Object, error) {
+	if len(args) != 2 {
+		return nil, object.NewArgsError("diff.values", 2, len(args))
+	}
+	// Keys that are not strings appear in paths in their string form, which
+	// must not name another key of the same map
+	for _, arg := range args {
+		if err := object.CheckKeys(arg); err != nil {
+			return nil, fmt.Errorf("diff.values: %w", err)
+		}
+	}
+	changes := diffValues("", args[0], args[1], []object.Object{})
+	return object.NewList(changes), nil
+}
+
+// Patch applies a list of JSON Patch operations to a copy of a value and
+// returns the copy. The supported operations are add, remove, replace, and
+// test. If any operation fails, no result is returned.
+func Patch(ctx context.Context, args ...object.Object) (object.Object, error) {
+	if len(args) != 2 {
+		return nil, object.NewArgsError("diff.patch", 2, len(args))
+	}
+	ops, err := object.AsList(args[1])
+	if err != nil {
+		return nil, err
+	}
+	doc := clone(args[0])
+	for _, item := range ops.Value() {
+		op, ok := item.(*object.Map)
+		if !ok {
+			return nil, object.TypeErrorf("diff.patch: expected a list of maps, got %s in list", item.Type())
+		}
+		if doc, err = applyChange(doc, op); err != nil {
+			return nil, err
+		}
+	}
+	return doc, nil
+}
+
+func Module() *object.Module {
+	return object.NewBuiltinsModule("diff", map[string]object.Object{
+		"patch":  object.NewBuiltin("patch", Patch),
+		"text":   object.NewBuiltin("text", Text),
+		"values": object.NewBuiltin("values", Values),
+	})
+}
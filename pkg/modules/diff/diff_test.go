@@ -0,0 +1,199 @@
+package diff
+
+import (
+	"context"
+	"testing"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+func str(s string) *object.String { return object.NewString(s) }
+
+func text(t *testing.T, args ...object.Object) string {
+	t.Helper()
+	result, err := Text(context.Background(), args...)
+	assert.Nil(t, err)
+	return result.(*object.String).Value()
+}
+
+func TestText(t *testing.T) {
+	assert.Equal(t, text(t, str("same\n"), str("same\n")), "")
+
+	a := "one\ntwo\nthree\nfour\nfive\nsix\nseven\neight\nnine\nten\n"
+	b := "one\ntwo\nTHREE\nfour\nfive\nsix\nseven\neight\nnine\nten\neleven\n"
+	assert.Equal(t, text(t, str(a), str(b)), `--- a
++++ b
+@@ -1,6 +1,6 @@
+ one
+ two
+-three
++THREE
+ four
+ five
+ six
+@@ -8,3 +8,4 @@
+ eight
+ nine
+ ten
++eleven
+`)
+
+	opts := object.NewMap(map[string]object.Object{
+		"context": object.NewInt(0),
+		"from":    str("old.conf"),
+		"to":      str("new.conf"),
+	})
+	assert.Equal(t, text(t, str(a), str(b), opts), `--- old.conf
++++ new.conf
+@@ -3 +3 @@
+-three
++THREE
+@@ -10,0 +11 @@
++eleven
+`)
+
+	// Nearby changes share a hunk
+	assert.Equal(t, text(t, str("a\nb\nc\nd\n"), str("A\nb\nc\nD\n"), object.NewMap(map[string]object.Object{
+		"context": object.NewInt(1),
+	})), "--- a\n+++ b\n@@ -1,4 +1,4 @@\n-a\n+A\n b\n c\n-d\n+D\n")
+}
+
+func TestTextEdges(t *testing.T) {
+	assert.Equal(t, text(t, str(""), str("new\n")), "--- a\n+++ b\n@@ -0,0 +1 @@\n+new\n")
+	assert.Equal(t, text(t, str("old\n"), str("")), "--- a\n+++ b\n@@ -1 +0,0 @@\n-old\n")
+	assert.Equal(t, text(t, str("x\ny"), str("x\ny\n")),
+		"--- a\n+++ b\n@@ -1,2 +1,2 @@\n x\n-y\n\\ No newline at end of file\n+y\n")
+
+	_, err := Text(context.Background(), str("a"))
+	assert.NotNil(t, err)
+	_, err = Text(context.Background(), str("a"), str("b"), object.NewMap(map[string]object.Object{"context": object.NewInt(-1)}))
+	assert.NotNil(t, err)
+	_, err = Text(context.Background(), str("a"), str("b"), object.NewMap(map[string]object.Object{"width": object.NewInt(1)}))
+	assert.NotNil(t, err)
+}
+
+func TestDiffLinesIsMinimal(t *testing.T) {
+	a := splitLines("a\nb\nc\na\nb\nb\na\n")
+	b := splitLines("c\nb\na\nb\na\nc\n")
+	changed := 0
+	for _, e := range diffLines(a, b) {
+		if e.kind != ' ' {
+			changed++
+		}
+	}
+	assert.Equal(t, changed, 5)
+}
+
+func val(v any) object.Object { return object.FromGoType(v) }
+
+func TestValues(t *testing.T) {
+	a := val(map[string]any{
+		"name":  "web",
+		"port":  80,
+		"tags":  []any{"a", "b", "c"},
+		"tls":   map[string]any{"enabled": false},
+		"a/b~c": 1,
+	})
+	b := val(map[string]any{
+		"name":  "web",
+		"port":  8080.0,
+		"tags":  []any{"a", "x"},
+		"tls":   map[string]any{"enabled": true, "cert": "c.pem"},
+		"owner": "ops",
+	})
+	result, err := Values(context.Background(), a, b)
+	assert.Nil(t, err)
+	assert.Equal(t, result.Inspect(), `[`+
+		`{"old": 1, "op": "remove", "path": "/a~1b~0c"}, `+
+		`{"op": "add", "path": "/owner", "value": "ops"}, `+
+		`{"old": 80, "op": "replace", "path": "/port", "value": 8080}, `+
+		`{"old": "b", "op": "replace", "path": "/tags/1", "value": "x"}, `+
+		`{"old": "c", "op": "remove", "path": "/tags/2"}, `+
+		`{"op": "add", "path": "/tls/cert", "value": "c.pem"}, `+
+		`{"old": false, "op": "replace", "path": "/tls/enabled", "value": true}]`)
+
+	// Applying the changes reproduces the target
+	patched, err := Patch(context.Background(), a, result)
+	assert.Nil(t, err)
+	assert.True(t, patched.Equals(b))
+	assert.Equal(t, a.(*object.Map).Get("port"), object.NewInt(80))
+
+	result, err = Values(context.Background(), val(1), val(1.0))
+	assert.Nil(t, err)
+	assert.Equal(t, result.Inspect(), "[]")
+
+	result, err = Values(context.Background(), val([]any{1}), val("x"))
+	assert.Nil(t, err)
+	assert.Equal(t, result.Inspect(), `[{"old": [1], "op": "replace", "path": "", "value": "x"}]`)
+}
+
+func TestValuesNonStringKeys(t *testing.T) {
+	keyed := func(value string) *object.Map {
+		m := object.NewMap(map[string]object.Object{"name": str("x")})
+		m.SetItem(object.NewInt(1), str(value))
+		return m
+	}
+	a, b := keyed("a"), keyed("b")
+	b.SetItem(object.NewBool(true), str("t"))
+	result, err := Values(context.Background(), a, b)
+	assert.Nil(t, err)
+	assert.Equal(t, result.Inspect(), `[`+
+		`{"op": "add", "path": "/true", "value": "t"}, `+
+		`{"old": "a", "op": "replace", "path": "/1", "value": "b"}]`)
+
+	patched, err := Patch(context.Background(), a, val([]any{
+		map[string]any{"op": "replace", "path": "/1", "value": "b"},
+	}))
+	assert.Nil(t, err)
+	assert.True(t, patched.Equals(keyed("b")))
+
+	// The string form of a key must not name another key
+	a.Set("1", str("one"))
+	_, err = Values(context.Background(), a, b)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), `map keys 1 and "1" have the same string form`)
+}
+
+func TestPatch(t *testing.T) {
+	doc := val(map[string]any{"items": []any{1, 2}, "meta": map[string]any{"v": 1}})
+	ops := val([]any{
+		map[string]any{"op": "test", "path": "/meta/v", "value": 1},
+		map[string]any{"op": "add", "path": "/items/0", "value": 0},
+		map[string]any{"op": "add", "path": "/items/-", "value": 3},
+		map[string]any{"op": "remove", "path": "/items/1"},
+		map[string]any{"op": "replace", "path": "/meta", "value": "none"},
+	})
+	result, err := Patch(context.Background(), doc, ops)
+	assert.Nil(t, err)
+	assert.Equal(t, result.Inspect(), `{"items": [0, 2, 3], "meta": "none"}`)
+	assert.Equal(t, doc.Inspect(), `{"items": [1, 2], "meta": {"v": 1}}`)
+
+	result, err = Patch(context.Background(), doc, val([]any{
+		map[string]any{"op": "replace", "path": "", "value": []any{}},
+	}))
+	assert.Nil(t, err)
+	assert.Equal(t, result.Inspect(), "[]")
+}
+
+func TestPatchErrors(t *testing.T) {
+	doc := val(map[string]any{"items": []any{1, 2}, "n": 1})
+	for _, op := range []map[string]any{
+		{"op": "test", "path": "/n", "value": 2},
+		{"op": "remove", "path": "/missing"},
+		{"op": "replace", "path": "/items/2", "value": 0},
+		{"op": "add", "path": "/items/01", "value": 0},
+		{"op": "add", "path": "/n/x", "value": 0},
+		{"op": "add", "path": "/a/b", "value": 0},
+		{"op": "add", "path": "n", "value": 0},
+		{"op": "add", "path": "/n"},
+		{"op": "move", "path": "/n"},
+		{"op": "remove", "path": ""},
+		{"path": "/n"},
+	} {
+		_, err := Patch(context.Background(), doc, val([]any{op}))
+		assert.NotNil(t, err, "%v", op)
+	}
+	_, err := Patch(context.Background(), doc, val([]any{"add"}))
+	assert.NotNil(t, err)
+}
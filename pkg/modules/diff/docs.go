@@ -0,0 +1,23 @@
+package diff
+
+import "github.com/deepnoodle-ai/risor/v2/pkg/object"
+
+func init() {
+	object.RegisterModule("diff", ModuleDoc(), Docs)
+}
+
+// Docs returns documentation for the diff module.
+func Docs() []object.FuncSpec {
+	return diffDocs
+}
+
+// ModuleDoc returns the module-level documentation.
+func ModuleDoc() string {
+	return "Unified diffs of text and JSON Patch diffs of structures"
+}
+
+var diffDocs = []object.FuncSpec{
+	{Name: "text", Doc: "Unified diff between two strings", Args: []string{"a", "b", "options?"}, Returns: "string"},
+	{Name: "values", Doc: "JSON Patch operations turning one value into another", Args: []string{"a", "b"}, Returns: "list"},
+	{Name: "patch", Doc: "Apply JSON Patch operations to a copy of a value", Args: []string{"value", "ops"}, Returns: "any"},
+}
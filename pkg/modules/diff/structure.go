@@ -0,0 +1,287 @@
+package diff
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+)
+
+// diffValues appends the changes turning a into b to changes. Maps are
+// compared key by key and lists index by index; any other pair of values
+// that differ is replaced whole. Numbers compare by value, so 1 and 1.0 are
+// equal.
+func diffValues(path string, a, b object.Object, changes []object.Object) []object.Object {
+	switch a := a.(type) {
+	case *object.Map:
+		if b, ok := b.(*object.Map); ok {
+			return diffMaps(path, a, b, changes)
+		}
+	case *object.List:
+		if b, ok := b.(*object.List); ok {
+			return diffLists(path, a, b, changes)
+		}
+	}
+	if a.Equals(b) {
+		return changes
+	}
+	return append(changes, change("replace", path, b, a))
+}
+
+func diffMaps(path string, a, b *object.Map, changes []object.Object) []object.Object {
+	keys := a.Copy()
+	keys.Update(b)
+	for _, key := range keys.Keys().Value() {
+		keyPath := path + "/" + escapeToken(keyToken(key))
+		av, inA := mapItem(a, key)
+		bv, inB := mapItem(b, key)
+		switch {
+		case !inB:
+			changes = append(changes, change("remove", keyPath, nil, av))
+		case !inA:
+			changes = append(changes, change("add", keyPath, bv, nil))
+		default:
+			changes = diffValues(keyPath, av, bv, changes)
+		}
+	}
+	return changes
+}
+
+func diffLists(path string, a, b *object.List, changes []object.Object) []object.Object {
+	as, bs := a.Value(), b.Value()
+	common := min(len(as), len(bs))
+	for i := 0; i < common; i++ {
+		changes = diffValues(path+"/"+strconv.Itoa(i), as[i], bs[i], changes)
+	}
+	for i := common; i < len(bs); i++ {
+		changes = append(changes, change("add", path+"/"+strconv.Itoa(i), bs[i], nil))
+	}
+	// Remove from the end so each index is still valid when applied in order.
+	for i := len(as) - 1; i >= common; i-- {
+		changes = append(changes, change("remove", path+"/"+strconv.Itoa(i), nil, as[i]))
+	}
+	return changes
+}
+
+// change builds one JSON Patch operation. The "old" key is not part of
+// JSON Patch; it records the previous value for reports and is ignored
+// when the patch is applied.
+func change(op, path string, value, old object.Object) object.Object {
+	m := map[string]object.Object{
+		"op":   object.NewString(op),
+		"path": object.NewString(path),
+	}
+	if value != nil {
+		m["value"] = value
+	}
+	if old != nil {
+		m["old"] = old
+	}
+	return object.NewMap(m)
+}
+
+// mapItem returns the value of key in m, and whether it was found.
+func mapItem(m *object.Map, key object.Object) (object.Object, bool) {
+	value, err := m.GetItem(key)
+	return value, err == nil
+}
+
+// keyToken returns the path token for a map key: a string key itself, or
+// the string form of another key, such as "1" or "(1, 2)".
+func keyToken(key object.Object) string {
+	if s, ok := key.(*object.String); ok {
+		return s.Value()
+	}
+	return key.Inspect()
+}
+
+// mapKey returns the key of m that a path token names: the string key
+// token if there is one, otherwise a key of another type whose string form
+// is token.
+func mapKey(m *object.Map, token string) (object.Object, bool) {
+	key := object.NewString(token)
+	if m.Contains(key).Value() {
+		return key, true
+	}
+	for _, key := range m.Keys().Value() {
+		if keyToken(key) == token {
+			return key, true
+		}
+	}
+	return key, false
+}
+
+// escapeToken escapes a map key for use in a JSON Pointer (RFC 6901).
+func escapeToken(key string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(key)
+}
+
+// parsePointer splits a JSON Pointer into unescaped reference tokens. The
+// empty pointer refers to the whole document.
+func parsePointer(path string) ([]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(path, "/") {
+		return nil, object.ValueErrorf("diff.patch: path %q must be empty or start with /", path)
+	}
+	tokens := strings.Split(path[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+	}
+	return tokens, nil
+}
+
+// clone deeply copies maps and lists so a patch never modifies its input.
+// Other values are immutable and are shared.
+func clone(obj object.Object) object.Object {
+	switch obj := obj.(type) {
+	case *object.Map:
+		result := object.NewMap(nil)
+		for _, key := range obj.Keys().Value() {
+			value, _ := mapItem(obj, key)
+			result.SetItem(key, clone(value))
+		}
+		return result
+	case *object.List:
+		items := make([]object.Object, len(obj.Value()))
+		for i, value := range obj.Value() {
+			items[i] = clone(value)
+		}
+		return object.NewList(items)
+	default:
+		return obj
+	}
+}
+
+// applyChange applies one JSON Patch operation to doc, which it may modify,
+// and returns the resulting document.
+func applyChange(doc object.Object, op *object.Map) (object.Object, error) {
+	opName, err := object.AsString(op.Get("op"))
+	if err != nil {
+		return nil, object.ValueErrorf("diff.patch: each operation needs a string \"op\"")
+	}
+	pathStr, err := object.AsString(op.Get("path"))
+	if err != nil {
+		return nil, object.ValueErrorf("diff.patch: each operation needs a string \"path\"")
+	}
+	path, err := parsePointer(pathStr)
+	if err != nil {
+		return nil, err
+	}
+	value, hasValue := op.Value()["value"]
+	switch opName {
+	case "add", "replace", "test":
+		if !hasValue {
+			return nil, object.ValueErrorf("diff.patch: %s at %q needs a \"value\"", opName, pathStr)
+		}
+	case "remove":
+	default:
+		return nil, object.ValueErrorf("diff.patch: unsupported op %q", opName)
+	}
+	if opName == "test" {
+		current, err := resolve(doc, path, pathStr)
+		if err != nil {
+			return nil, err
+		}
+		if !current.Equals(value) {
+			return nil, object.ValueErrorf("diff.patch: test failed at %q: expected %s, got %s",
+				pathStr, value.Inspect(), current.Inspect())
+		}
+		return doc, nil
+	}
+	if len(path) == 0 {
+		if opName == "remove" {
+			return nil, object.ValueErrorf("diff.patch: cannot remove the whole document")
+		}
+		return clone(value), nil
+	}
+	parent, err := resolve(doc, path[:len(path)-1], pathStr)
+	if err != nil {
+		return nil, err
+	}
+	last := path[len(path)-1]
+	switch parent := parent.(type) {
+	case *object.Map:
+		key, exists := mapKey(parent, last)
+		if !exists && opName != "add" {
+			return nil, object.ValueErrorf("diff.patch: %s at %q: no such key", opName, pathStr)
+		}
+		if opName == "remove" {
+			if err := parent.DelItem(key); err != nil {
+				return nil, err
+			}
+		} else if err := parent.SetItem(key, clone(value)); err != nil {
+			return nil, err
+		}
+	case *object.List:
+		size := len(parent.Value())
+		if opName == "add" && last == "-" {
+			if err := parent.Append(clone(value)); err != nil {
+				return nil, err
+			}
+			return doc, nil
+		}
+		index, ok := listIndex(last)
+		if !ok || index > size || (index == size && opName != "add") {
+			return nil, object.ValueErrorf("diff.patch: %s at %q: index out of range", opName, pathStr)
+		}
+		switch opName {
+		case "add":
+			if err := parent.Insert(int64(index), clone(value)); err != nil {
+				return nil, err
+			}
+		case "replace":
+			if err := parent.SetItem(object.NewInt(int64(index)), clone(value)); err != nil {
+				return nil, err
+			}
+		case "remove":
+			if _, err := parent.Pop(int64(index)); err != nil {
+				return nil, err
+			}
+		}
+	default:
+		return nil, object.ValueErrorf("diff.patch: %s at %q: parent is %s, not a map or list",
+			opName, pathStr, parent.Type())
+	}
+	return doc, nil
+}
+
+// resolve returns the value at path within doc.
+func resolve(doc object.Object, path []string, pathStr string) (object.Object, error) {
+	current := doc
+	for _, token := range path {
+		switch container := current.(type) {
+		case *object.Map:
+			key, ok := mapKey(container, token)
+			if !ok {
+				return nil, object.ValueErrorf("diff.patch: path %q not found", pathStr)
+			}
+			current, _ = mapItem(container, key)
+		case *object.List:
+			index, ok := listIndex(token)
+			if !ok || index >= len(container.Value()) {
+				return nil, object.ValueErrorf("diff.patch: path %q not found", pathStr)
+			}
+			current = container.Value()[index]
+		default:
+			return nil, object.ValueErrorf("diff.patch: path %q not found", pathStr)
+		}
+	}
+	return current, nil
+}
+
+// listIndex parses a JSON Pointer array index, which has no sign or leading
+// zeros.
+func listIndex(token string) (int, bool) {
+	if token == "" || (len(token) > 1 && token[0] == '0') {
+		return 0, false
+	}
+	for _, c := range token {
+		if c < '0' || c > '9' {
+			return 0, false
+		}
+	}
+	index, err := strconv.Atoi(token)
+	return index, err == nil
+}
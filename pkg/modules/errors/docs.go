@@ -0,0 +1,24 @@
+package errors
+
+import "github.com/deepnoodle-ai/risor/v2/pkg/object"
+
+func init() {
+	object.RegisterModule("errors", ModuleDoc(), Docs)
+}
+
+// Docs returns documentation for the errors module.
+func Docs() []object.FuncSpec {
+	return errorsDocs
+}
+
+// ModuleDoc returns the module-level documentation.
+func ModuleDoc() string {
+	return "Error wrapping and cause chain inspection"
+}
+
+var errorsDocs = []object.FuncSpec{
+	{Name: "as", Doc: "Find the first error in the chain with a kind or code", Args: []string{"err", "kind"}, Returns: "error"},
+	{Name: "is", Doc: "Check whether an error or one of its causes equals a target error", Args: []string{"err", "target"}, Returns: "bool"},
+	{Name: "unwrap", Doc: "Get the error wrapped by an error", Args: []string{"err"}, Returns: "error"},
+	{Name: "wrap", Doc: "Wrap an error with a message describing its context", Args: []string{"err", "message", "args..."}, Returns: "error"},
+}
@@ -0,0 +1,116 @@
+package errors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+)
+
+// Wrap returns a new error that adds context to an existing one. The result
+// keeps the original as its cause, so is, as, and cause see through it.
+func Wrap(ctx context.Context, args ...object.Object) (object.Object, error) {
+	if len(args) < 2 {
+		return nil, fmt.Errorf("errors.wrap: expected at least 2 arguments, got %d", len(args))
+	}
+	errObj, err := object.AsError(args[0])
+	if err != nil {
+		return nil, err
+	}
+	format, err := object.AsString(args[1])
+	if err != nil {
+		return nil, err
+	}
+	fmtArgs := make([]any, len(args)-2)
+	for i, v := range args[2:] {
+		fmtArgs[i] = v.Interface()
+	}
+	message := fmt.Sprintf(format, fmtArgs...)
+	return object.NewError(fmt.Errorf("%s: %w", message, errObj.Value())), nil
+}
+
+// Unwrap returns the error wrapped by the given error, or null.
+func Unwrap(ctx context.Context, args ...object.Object) (object.Object, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("errors.unwrap: expected 1 argument, got %d", len(args))
+	}
+	errObj, err := object.AsError(args[0])
+	if err != nil {
+		return nil, err
+	}
+	if cause := errObj.Cause(); cause != nil {
+		return cause, nil
+	}
+	return object.Nil, nil
+}
+
+// Is reports whether err or any error in its chain of causes equals target
+// under ==, so that errors.is(err, target) agrees with err == target when
+// err wraps nothing.
+func Is(ctx context.Context, args ...object.Object) (object.Object, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("errors.is: expected 2 arguments, got %d", len(args))
+	}
+	errObj, err := object.AsError(args[0])
+	if err != nil {
+		return nil, err
+	}
+	target, err := object.AsError(args[1])
+	if err != nil {
+		return nil, err
+	}
+	for e := errObj; e != nil; e = e.Cause() {
+		if e.Equals(target) {
+			return object.True, nil
+		}
+	}
+	return object.False, nil
+}
+
+// As returns the first error in the chain of err with the given kind, such
+// as "type" or "value error", or with the given error code. It returns null
+// if no error in the chain matches.
+func As(ctx context.Context, args ...object.Object) (object.Object, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("errors.as: expected 2 arguments, got %d", len(args))
+	}
+	errObj, err := object.AsError(args[0])
+	if err != nil {
+		return nil, err
+	}
+	name, err := object.AsString(args[1])
+	if err != nil {
+		return nil, err
+	}
+	for e := errObj.Value(); e != nil; e = errors.Unwrap(e) {
+		if candidate := object.NewError(e); matches(candidate, name) {
+			return candidate, nil
+		}
+	}
+	return object.Nil, nil
+}
+
+// matches reports whether an error has the given kind or code. Kinds match
+// with or without the " error" suffix.
+func matches(e *object.Error, name string) bool {
+	if se, ok := e.Value().(*object.ScriptError); ok && se.Code == name {
+		return true
+	}
+	structured := e.Structured()
+	if structured == nil {
+		return false
+	}
+	kind := structured.Kind.String()
+	return kind == name || strings.TrimSuffix(kind, " error") == name
+}
+
+func Module() *object.Module {
+	return object.NewBuiltinsModule("errors", map[string]object.Object{
+		"as":     object.NewBuiltin("as", As),
+		"is":     object.NewBuiltin("is", Is),
+		"unwrap": object.NewBuiltin("unwrap", Unwrap),
+		"wrap":   object.NewBuiltin("wrap", Wrap),
+	})
+}
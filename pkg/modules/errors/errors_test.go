@@ -0,0 +1,89 @@
+package errors
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+func TestWrap(t *testing.T) {
+	ctx := context.Background()
+	inner := object.NewError(errors.New("connection refused"))
+
+	result, err := Wrap(ctx, inner, object.NewString("loading user %d"), object.NewInt(7))
+	assert.Nil(t, err)
+	wrapped, ok := result.(*object.Error)
+	assert.True(t, ok)
+	assert.Equal(t, wrapped.Message().Value(), "loading user 7: connection refused")
+	assert.Equal(t, wrapped.Cause(), inner)
+
+	result, err = Unwrap(ctx, wrapped)
+	assert.Nil(t, err)
+	assert.Equal(t, result, object.Object(inner))
+
+	result, err = Unwrap(ctx, inner)
+	assert.Nil(t, err)
+	assert.Equal(t, result, object.Object(object.Nil))
+
+	_, err = Wrap(ctx, inner)
+	assert.Error(t, err, "errors.wrap: expected at least 2 arguments, got 1")
+	_, err = Wrap(ctx, object.NewString("x"), object.NewString("y"))
+	assert.NotNil(t, err)
+}
+
+func TestIs(t *testing.T) {
+	ctx := context.Background()
+	sentinel := object.NewError(errors.New("not found"))
+	wrapped, err := Wrap(ctx, sentinel, object.NewString("loading config"))
+	assert.Nil(t, err)
+
+	result, err := Is(ctx, wrapped, sentinel)
+	assert.Nil(t, err)
+	assert.Equal(t, result, object.True)
+
+	// Errors in the chain are compared as with ==
+	other := object.NewError(errors.New("not found"))
+	result, err = Is(ctx, wrapped, other)
+	assert.Nil(t, err)
+	assert.Equal(t, result, object.True)
+	result, err = Is(ctx, sentinel, other)
+	assert.Nil(t, err)
+	assert.Equal(t, result, object.NewBool(sentinel.Equals(other)))
+
+	result, err = Is(ctx, wrapped, object.NewError(errors.New("timed out")))
+	assert.Nil(t, err)
+	assert.Equal(t, result, object.False)
+}
+
+func TestAs(t *testing.T) {
+	ctx := context.Background()
+	typeErr := object.NewError(object.TypeErrorf("bad operand"))
+	wrapped, err := Wrap(ctx, typeErr, object.NewString("adding"))
+	assert.Nil(t, err)
+
+	for _, kind := range []string{"type", "type error"} {
+		result, err := As(ctx, wrapped, object.NewString(kind))
+		assert.Nil(t, err)
+		found, ok := result.(*object.Error)
+		assert.True(t, ok)
+		assert.Equal(t, found.Message().Value(), typeErr.Message().Value())
+	}
+
+	result, err := As(ctx, wrapped, object.NewString("value"))
+	assert.Nil(t, err)
+	assert.Equal(t, result, object.Object(object.Nil))
+
+	coded := object.NewError(object.NewScriptError("not_found", nil))
+	wrapped, err = Wrap(ctx, coded, object.NewString("loading user"))
+	assert.Nil(t, err)
+	result, err = As(ctx, wrapped, object.NewString("not_found"))
+	assert.Nil(t, err)
+	found, ok := result.(*object.Error)
+	assert.True(t, ok)
+	se, ok := found.ScriptError()
+	assert.True(t, ok)
+	assert.Equal(t, se.Code, "not_found")
+}
@@ -0,0 +1,21 @@
+package events
+
+import "github.com/deepnoodle-ai/risor/v2/pkg/object"
+
+func init() {
+	object.RegisterModule("events", ModuleDoc(), Docs)
+}
+
+// Docs returns documentation for the events module.
+func Docs() []object.FuncSpec {
+	return eventsDocs
+}
+
+// ModuleDoc returns the module-level documentation.
+func ModuleDoc() string {
+	return "Emitters that call registered handlers when an event is emitted"
+}
+
+var eventsDocs = []object.FuncSpec{
+	{Name: "emitter", Doc: "Create an event emitter", Args: []string{}, Returns: "emitter"},
+}
@@ -0,0 +1,253 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+	"github.com/deepnoodle-ai/risor/v2/pkg/op"
+)
+
+const EMITTER object.Type = "emitter"
+
+var emitterMethods = object.NewMethodRegistry[*Emitter]("emitter")
+
+func init() {
+	emitterMethods.Define("on").
+		Doc("Call a function each time an event is emitted; returns the function").
+		Arg("event").
+		Arg("fn").
+		Returns("function").
+		Impl(func(e *Emitter, ctx context.Context, args ...object.Object) (object.Object, error) {
+			return e.add("on", args, false)
+		})
+
+	emitterMethods.Define("once").
+		Doc("Call a function the next time an event is emitted; returns the function").
+		Arg("event").
+		Arg("fn").
+		Returns("function").
+		Impl(func(e *Emitter, ctx context.Context, args ...object.Object) (object.Object, error) {
+			return e.add("once", args, true)
+		})
+
+	emitterMethods.Define("off").
+		Doc("Remove a handler, or every handler of an event if no function is given").
+		Arg("event").
+		OptionalArg("fn").
+		Returns("int").
+		Impl(func(e *Emitter, ctx context.Context, args ...object.Object) (object.Object, error) {
+			name, err := object.AsString(args[0])
+			if err != nil {
+				return nil, err
+			}
+			var fn object.Object
+			if len(args) > 1 {
+				fn = args[1]
+			}
+			return object.NewInt(int64(e.Off(name, fn))), nil
+		})
+
+	emitterMethods.Define("emit").
+		Doc("Call the handlers of an event with the given arguments; returns how many ran").
+		Arg("event").
+		Variadic("args").
+		Returns("int").
+		Impl(func(e *Emitter, ctx context.Context, args ...object.Object) (object.Object, error) {
+			name, err := object.AsString(args[0])
+			if err != nil {
+				return nil, err
+			}
+			n, err := e.Emit(ctx, name, args[1:]...)
+			if err != nil {
+				return nil, err
+			}
+			return object.NewInt(int64(n)), nil
+		})
+
+	emitterMethods.Define("events").
+		Doc("Names of the events that have handlers, sorted").
+		Returns("list").
+		Impl(func(e *Emitter, ctx context.Context, args ...object.Object) (object.Object, error) {
+			names := e.Events()
+			items := make([]object.Object, len(names))
+			for i, name := range names {
+				items[i] = object.NewString(name)
+			}
+			return object.NewList(items), nil
+		})
+
+	emitterMethods.Define("count").
+		Doc("Number of handlers of an event").
+		Arg("event").
+		Returns("int").
+		Impl(func(e *Emitter, ctx context.Context, args ...object.Object) (object.Object, error) {
+			name, err := object.AsString(args[0])
+			if err != nil {
+				return nil, err
+			}
+			return object.NewInt(int64(e.Count(name))), nil
+		})
+}
+
+// handler is a function registered for an event.
+type handler struct {
+	fn   object.Callable
+	once bool
+}
+
+// Emitter calls the functions registered for an event when the event is
+// emitted. Handlers run in the order they were added, on the goroutine that
+// emits the event. An emitter can be shared between a script and its host,
+// which can add Go handlers with On and emit events from builtins with
+// Emit. Risor handlers run on the VM found in the context passed to Emit,
+// so an event with Risor handlers must be emitted from the goroutine
+// running the script, such as from a builtin the script calls.
+type Emitter struct {
+	mu       sync.Mutex
+	handlers map[string][]*handler
+}
+
+// NewEmitter returns an emitter with no handlers.
+func NewEmitter() *Emitter {
+	return &Emitter{handlers: map[string][]*handler{}}
+}
+
+func (e *Emitter) Type() object.Type {
+	return EMITTER
+}
+
+func (e *Emitter) Inspect() string {
+	return fmt.Sprintf("emitter(events=%d)", len(e.Events()))
+}
+
+func (e *Emitter) String() string {
+	return e.Inspect()
+}
+
+func (e *Emitter) Interface() any {
+	return e
+}
+
+func (e *Emitter) Equals(other object.Object) bool {
+	return e == other
+}
+
+func (e *Emitter) Attrs() []object.AttrSpec {
+	return emitterMethods.Specs()
+}
+
+func (e *Emitter) GetAttr(name string) (object.Object, bool) {
+	return emitterMethods.GetAttr(e, name)
+}
+
+func (e *Emitter) SetAttr(name string, value object.Object) error {
+	return object.TypeErrorf("emitter has no attribute %q", name)
+}
+
+func (e *Emitter) IsTruthy() bool {
+	return true
+}
+
+func (e *Emitter) RunOperation(opType op.BinaryOpType, right object.Object) (object.Object, error) {
+	return nil, object.TypeErrorf("unsupported operation for emitter: %v", opType)
+}
+
+// add checks the arguments of on and once and registers the handler.
+func (e *Emitter) add(method string, args []object.Object, once bool) (object.Object, error) {
+	name, err := object.AsString(args[0])
+	if err != nil {
+		return nil, err
+	}
+	fn, ok := args[1].(object.Callable)
+	if !ok {
+		return nil, object.TypeErrorf("emitter.%s() expected a function (%s given)", method, args[1].Type())
+	}
+	if once {
+		e.Once(name, fn)
+	} else {
+		e.On(name, fn)
+	}
+	return args[1], nil
+}
+
+// On adds a handler that is called each time the event is emitted.
+func (e *Emitter) On(name string, fn object.Callable) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.handlers[name] = append(e.handlers[name], &handler{fn: fn})
+}
+
+// Once adds a handler that is called the next time the event is emitted.
+func (e *Emitter) Once(name string, fn object.Callable) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.handlers[name] = append(e.handlers[name], &handler{fn: fn, once: true})
+}
+
+// Off removes the handlers of an event that are equal to fn, or all of the
+// event's handlers if fn is nil, and returns how many were removed.
+func (e *Emitter) Off(name string, fn object.Object) int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	before := len(e.handlers[name])
+	kept := slices.DeleteFunc(e.handlers[name], func(h *handler) bool {
+		if fn == nil {
+			return true
+		}
+		obj, ok := h.fn.(object.Object)
+		return ok && obj.Equals(fn)
+	})
+	if len(kept) == 0 {
+		delete(e.handlers, name)
+	} else {
+		e.handlers[name] = kept
+	}
+	return before - len(kept)
+}
+
+// Emit calls the handlers of an event with args and returns how many were
+// called. Handlers added or removed while the event is being emitted take
+// effect from the next emit. If a handler fails, the remaining handlers
+// are skipped and the error is returned.
+func (e *Emitter) Emit(ctx context.Context, name string, args ...object.Object) (int, error) {
+	e.mu.Lock()
+	handlers := slices.Clone(e.handlers[name])
+	kept := slices.DeleteFunc(e.handlers[name], func(h *handler) bool {
+		return h.once
+	})
+	if len(kept) == 0 {
+		delete(e.handlers, name)
+	} else {
+		e.handlers[name] = kept
+	}
+	e.mu.Unlock()
+
+	for i, h := range handlers {
+		if _, err := h.fn.Call(ctx, args...); err != nil {
+			return i, fmt.Errorf("emitter.emit: %q handler: %w", name, err)
+		}
+	}
+	return len(handlers), nil
+}
+
+// Events returns the names of the events that have handlers, sorted.
+func (e *Emitter) Events() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	names := make([]string, 0, len(e.handlers))
+	for name := range e.handlers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Count returns the number of handlers of an event.
+func (e *Emitter) Count(name string) int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return len(e.handlers[name])
+}
@@ -0,0 +1,21 @@
+package events
+
+import (
+	"context"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+)
+
+// NewEventEmitter creates an emitter with no handlers.
+func NewEventEmitter(ctx context.Context, args ...object.Object) (object.Object, error) {
+	if len(args) != 0 {
+		return nil, object.NewArgsError("events.emitter", 0, len(args))
+	}
+	return NewEmitter(), nil
+}
+
+func Module() *object.Module {
+	return object.NewBuiltinsModule("events", map[string]object.Object{
+		"emitter": object.NewBuiltin("emitter", NewEventEmitter),
+	})
+}
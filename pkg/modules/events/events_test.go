@@ -0,0 +1,113 @@
+package events
+
+import (
+	"context"
+	"testing"
+
+	"github.com/deepnoodle-ai/risor/v2/internal/moduletest"
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+// eval runs source with the events module, the given globals, and the
+// default builtins.
+func eval(ctx context.Context, source string, extra map[string]any) (object.Object, error) {
+	globals := map[string]any{"events": Module()}
+	for name, value := range extra {
+		globals[name] = value
+	}
+	return moduletest.Eval(ctx, source, globals)
+}
+
+func TestEmitter(t *testing.T) {
+	result, err := eval(context.Background(), `
+	let bus = events.emitter()
+	let seen = []
+	bus.on("saved", (name, size) => seen.append("a:" + name + ":" + string(size)))
+	bus.on("saved", (name, _) => seen.append("b:" + name))
+	bus.once("saved", (name, _) => seen.append("once:" + name))
+	let first = bus.emit("saved", "x.txt", 10)
+	let second = bus.emit("saved", "y.txt", 20)
+	[first, second, seen, bus.emit("missing")]
+	`, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, result.Inspect(),
+		`[3, 2, ["a:x.txt:10", "b:x.txt", "once:x.txt", "a:y.txt:20", "b:y.txt"], 0]`)
+}
+
+func TestOff(t *testing.T) {
+	result, err := eval(context.Background(), `
+	let bus = events.emitter()
+	let count = 0
+	let handler = bus.on("tick", () => { count += 1 })
+	bus.on("tick", () => { count += 10 })
+	bus.on("done", () => {})
+	bus.emit("tick")
+	let removed = bus.off("tick", handler)
+	bus.emit("tick")
+	let events_before = bus.events()
+	let cleared = bus.off("tick")
+	[count, removed, cleared, events_before, bus.events(), bus.count("done")]
+	`, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, result.Inspect(), `[21, 1, 1, ["done", "tick"], ["done"], 1]`)
+}
+
+func TestHandlerError(t *testing.T) {
+	result, err := eval(context.Background(), `
+	let bus = events.emitter()
+	let ran = false
+	bus.on("go", () => { throw "broken" })
+	bus.on("go", () => { ran = true })
+	let message = try { bus.emit("go") } catch e { e.message() }
+	[message, ran]
+	`, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, result.Inspect(), `["emitter.emit: \"go\" handler: broken", false]`)
+
+	_, err = eval(context.Background(), `events.emitter().on("x", 1)`, nil)
+	assert.Contains(t, err.Error(), "expected a function")
+	_, err = eval(context.Background(), `events.emitter().emit()`, nil)
+	assert.Contains(t, err.Error(), "expected at least 1 argument")
+}
+
+func TestHostEmitter(t *testing.T) {
+	// The host shares an emitter with the script, listens with a Go
+	// handler, and emits from a builtin the script calls
+	bus := NewEmitter()
+	var received []string
+	bus.On("log", object.NewBuiltin("collect", func(ctx context.Context, args ...object.Object) (object.Object, error) {
+		received = append(received, args[0].(*object.String).Value())
+		return object.Nil, nil
+	}))
+	notify := object.NewBuiltin("notify", func(ctx context.Context, args ...object.Object) (object.Object, error) {
+		n, err := bus.Emit(ctx, "update", args...)
+		return object.NewInt(int64(n)), err
+	})
+	result, err := eval(context.Background(), `
+	let updates = []
+	bus.on("update", value => updates.append(value))
+	bus.emit("log", "started")
+	notify(42)
+	updates
+	`, map[string]any{"bus": bus, "notify": notify})
+	assert.Nil(t, err)
+	assert.Equal(t, result.Inspect(), "[42]")
+	assert.Equal(t, received, []string{"started"})
+	assert.Equal(t, bus.Inspect(), "emitter(events=2)")
+}
+
+func TestEmitDuringEmit(t *testing.T) {
+	// Handlers added while an event is emitted run from the next emit
+	result, err := eval(context.Background(), `
+	let bus = events.emitter()
+	let calls = 0
+	bus.on("e", () => { calls += 1; bus.on("e", () => { calls += 100 }) })
+	bus.emit("e")
+	let after_first = calls
+	bus.emit("e")
+	[after_first, calls, bus.count("e")]
+	`, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, result.Inspect(), "[1, 102, 3]")
+}
@@ -0,0 +1,22 @@
+package flags
+
+import "github.com/deepnoodle-ai/risor/v2/pkg/object"
+
+func init() {
+	object.RegisterModule("flags", ModuleDoc(), Docs)
+}
+
+// Docs returns documentation for the flags module.
+func Docs() []object.FuncSpec {
+	return flagsDocs
+}
+
+// ModuleDoc returns the module-level documentation.
+func ModuleDoc() string {
+	return "Command line flag parsing"
+}
+
+var flagsDocs = []object.FuncSpec{
+	{Name: "parse", Doc: "Parse command line flags", Args: []string{"spec", "args?"}, Returns: "map"},
+	{Name: "usage", Doc: "Describe the flags in a spec", Args: []string{"spec"}, Returns: "string"},
+}
@@ -0,0 +1,275 @@
+package flags
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+)
+
+// argsKey is the key under which parse returns the positional arguments.
+// It cannot be used as a flag name.
+const argsKey = "args"
+
+// flagSpec describes one flag declared in a spec map.
+type flagSpec struct {
+	name     string
+	typ      string
+	short    string
+	help     string
+	required bool
+	def      object.Object
+}
+
+// parseSpec converts a spec map such as {verbose: {type: "bool"}} into flag
+// specs, sorted by name.
+func parseSpec(fn string, obj object.Object) ([]*flagSpec, error) {
+	spec, ok := obj.(*object.Map)
+	if !ok {
+		return nil, object.TypeErrorf("%s: expected a map (got %s)", fn, obj.Type())
+	}
+	var specs []*flagSpec
+	shorts := map[string]string{}
+	if err := spec.CheckStringKeys(); err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+	for _, name := range spec.SortedKeys() {
+		if name == argsKey {
+			return nil, fmt.Errorf("%s: %q is reserved for positional arguments", fn, argsKey)
+		}
+		if name == "" || strings.HasPrefix(name, "-") {
+			return nil, fmt.Errorf("%s: invalid flag name %q", fn, name)
+		}
+		opts, ok := spec.Get(name).(*object.Map)
+		if !ok {
+			return nil, object.TypeErrorf("%s: flag %q: expected a map (got %s)", fn, name, spec.Get(name).Type())
+		}
+		f := &flagSpec{name: name, typ: "string"}
+		if err := opts.CheckStringKeys(); err != nil {
+			return nil, fmt.Errorf("%s: flag %q: %w", fn, name, err)
+		}
+		for _, key := range opts.SortedKeys() {
+			value := opts.Get(key)
+			var err error
+			switch key {
+			case "type":
+				f.typ, err = object.AsString(value)
+			case "short":
+				f.short, err = object.AsString(value)
+			case "help":
+				f.help, err = object.AsString(value)
+			case "required":
+				f.required, err = object.AsBool(value)
+			case "default":
+				f.def = value
+			default:
+				return nil, fmt.Errorf("%s: flag %q: unknown option %q", fn, name, key)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("%s: flag %q: %s: %w", fn, name, key, err)
+			}
+		}
+		zero, ok := zeroValue(f.typ)
+		if !ok {
+			return nil, fmt.Errorf("%s: flag %q: unsupported type %q (expected bool, string, int, or float)", fn, name, f.typ)
+		}
+		if f.def == nil {
+			f.def = zero
+		} else if f.def.Type() != zero.Type() {
+			return nil, object.TypeErrorf("%s: flag %q: default must be of type %s (got %s)", fn, name, f.typ, f.def.Type())
+		}
+		if f.short != "" {
+			if len(f.short) != 1 || f.short == "-" {
+				return nil, fmt.Errorf("%s: flag %q: short name must be a single character (got %q)", fn, name, f.short)
+			}
+			if other, ok := shorts[f.short]; ok {
+				return nil, fmt.Errorf("%s: flags %q and %q have the same short name %q", fn, other, name, f.short)
+			}
+			shorts[f.short] = name
+		}
+		specs = append(specs, f)
+	}
+	return specs, nil
+}
+
+func zeroValue(typ string) (object.Object, bool) {
+	switch typ {
+	case "bool":
+		return object.False, true
+	case "string":
+		return object.NewString(""), true
+	case "int":
+		return object.NewInt(0), true
+	case "float":
+		return object.NewFloat(0), true
+	}
+	return nil, false
+}
+
+// convert parses a command line value as the flag's type.
+func (f *flagSpec) convert(value string) (object.Object, error) {
+	switch f.typ {
+	case "bool":
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value %q for flag --%s: expected a bool", value, f.name)
+		}
+		return object.NewBool(b), nil
+	case "int":
+		i, err := strconv.ParseInt(value, 0, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value %q for flag --%s: expected an int", value, f.name)
+		}
+		return object.NewInt(i), nil
+	case "float":
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value %q for flag --%s: expected a float", value, f.name)
+		}
+		return object.NewFloat(v), nil
+	}
+	return object.NewString(value), nil
+}
+
+// parseArgs parses command line arguments against the flag specs. Flags
+// may be written as --name value, --name=value, -s value, or -s=value.
+// Bool flags take no value unless one is attached with "=". Arguments
+// after "--" are always positional.
+func parseArgs(specs []*flagSpec, args []string) (*object.Map, error) {
+	byName := map[string]*flagSpec{}
+	byShort := map[string]*flagSpec{}
+	for _, f := range specs {
+		byName[f.name] = f
+		if f.short != "" {
+			byShort[f.short] = f
+		}
+	}
+	values := map[string]object.Object{}
+	var positional []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			positional = append(positional, args[i+1:]...)
+			break
+		}
+		if len(arg) < 2 || arg[0] != '-' {
+			positional = append(positional, arg)
+			continue
+		}
+		name, value, hasValue := strings.Cut(strings.TrimPrefix(arg[1:], "-"), "=")
+		var f *flagSpec
+		if strings.HasPrefix(arg, "--") {
+			f = byName[name]
+		} else {
+			f = byShort[name]
+		}
+		if f == nil {
+			return nil, fmt.Errorf("unknown flag: %s", strings.SplitN(arg, "=", 2)[0])
+		}
+		if !hasValue {
+			if f.typ == "bool" {
+				value = "true"
+			} else if i+1 < len(args) {
+				i++
+				value = args[i]
+			} else {
+				return nil, fmt.Errorf("flag --%s requires a value", f.name)
+			}
+		}
+		v, err := f.convert(value)
+		if err != nil {
+			return nil, err
+		}
+		values[f.name] = v
+	}
+	for _, f := range specs {
+		if _, ok := values[f.name]; ok {
+			continue
+		}
+		if f.required {
+			return nil, fmt.Errorf("missing required flag: --%s", f.name)
+		}
+		values[f.name] = f.def
+	}
+	values[argsKey] = object.NewStringList(positional)
+	return object.NewMap(values), nil
+}
+
+// usage formats a help message listing each flag.
+func usage(specs []*flagSpec) string {
+	var lines []string
+	for _, f := range specs {
+		names := "--" + f.name
+		if f.short != "" {
+			names = "-" + f.short + ", " + names
+		}
+		if f.typ != "bool" {
+			names += " " + f.typ
+		}
+		var details []string
+		if f.help != "" {
+			details = append(details, f.help)
+		}
+		if f.required {
+			details = append(details, "(required)")
+		} else if f.def.IsTruthy() {
+			details = append(details, fmt.Sprintf("(default %s)", f.def.Inspect()))
+		}
+		line := "  " + names
+		if len(details) > 0 {
+			line += "\n        " + strings.Join(details, " ")
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Parse parses command line arguments against a spec map and returns a map
+// of flag values, with positional arguments under "args". Each spec entry
+// may set "type" (bool, string, int, or float; default string), "default",
+// "short", "help", and "required". The arguments default to those the
+// module was created with.
+func Parse(args []string) object.BuiltinFunction {
+	return func(ctx context.Context, callArgs ...object.Object) (object.Object, error) {
+		if len(callArgs) < 1 || len(callArgs) > 2 {
+			return nil, fmt.Errorf("flags.parse: expected 1 or 2 arguments, got %d", len(callArgs))
+		}
+		specs, err := parseSpec("flags.parse", callArgs[0])
+		if err != nil {
+			return nil, err
+		}
+		argv := args
+		if len(callArgs) == 2 {
+			argv, err = object.AsStringSlice(callArgs[1])
+			if err != nil {
+				return nil, fmt.Errorf("flags.parse: %w", err)
+			}
+		}
+		return parseArgs(specs, slices.Clone(argv))
+	}
+}
+
+// Usage returns a help message describing the flags in a spec map.
+func Usage(ctx context.Context, args ...object.Object) (object.Object, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("flags.usage: expected 1 argument, got %d", len(args))
+	}
+	specs, err := parseSpec("flags.usage", args[0])
+	if err != nil {
+		return nil, err
+	}
+	return object.NewString(usage(specs)), nil
+}
+
+// Module returns the flags module. The args are the command line arguments
+// that flags.parse reads when it is not given a list, excluding the program
+// or script name.
+func Module(args []string) *object.Module {
+	return object.NewBuiltinsModule("flags", map[string]object.Object{
+		"parse": object.NewBuiltin("parse", Parse(slices.Clone(args))),
+		"usage": object.NewBuiltin("usage", Usage),
+	})
+}
@@ -0,0 +1,142 @@
+package flags
+
+import (
+	"context"
+	"testing"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+func spec(flags map[string]map[string]object.Object) *object.Map {
+	m := map[string]object.Object{}
+	for name, opts := range flags {
+		m[name] = object.NewMap(opts)
+	}
+	return object.NewMap(m)
+}
+
+func testSpec() *object.Map {
+	return spec(map[string]map[string]object.Object{
+		"verbose": {"type": object.NewString("bool"), "short": object.NewString("v")},
+		"count":   {"type": object.NewString("int"), "default": object.NewInt(1)},
+		"ratio":   {"type": object.NewString("float")},
+		"name":    {"help": object.NewString("Name to greet")},
+	})
+}
+
+func parse(t *testing.T, args ...string) (*object.Map, error) {
+	t.Helper()
+	fn := Parse(args)
+	result, err := fn(context.Background(), testSpec())
+	if err != nil {
+		return nil, err
+	}
+	return result.(*object.Map), nil
+}
+
+func TestParseDefaults(t *testing.T) {
+	m, err := parse(t)
+	assert.Nil(t, err)
+	assert.Equal(t, m.Get("verbose"), object.Object(object.False))
+	assert.Equal(t, m.Get("count"), object.Object(object.NewInt(1)))
+	assert.Equal(t, m.Get("ratio"), object.Object(object.NewFloat(0)))
+	assert.Equal(t, m.Get("name"), object.Object(object.NewString("")))
+	assert.Equal(t, m.Get("args").Inspect(), "[]")
+}
+
+func TestParseFlags(t *testing.T) {
+	m, err := parse(t, "-v", "in.txt", "--count", "3", "--ratio=0.5", "--name=bob", "--", "--count")
+	assert.Nil(t, err)
+	assert.Equal(t, m.Get("verbose"), object.Object(object.True))
+	assert.Equal(t, m.Get("count"), object.Object(object.NewInt(3)))
+	assert.Equal(t, m.Get("ratio"), object.Object(object.NewFloat(0.5)))
+	assert.Equal(t, m.Get("name"), object.Object(object.NewString("bob")))
+	assert.Equal(t, m.Get("args").Inspect(), `["in.txt", "--count"]`)
+
+	m, err = parse(t, "--verbose=false", "-")
+	assert.Nil(t, err)
+	assert.Equal(t, m.Get("verbose"), object.Object(object.False))
+	assert.Equal(t, m.Get("args").Inspect(), `["-"]`)
+}
+
+func TestParseExplicitArgs(t *testing.T) {
+	fn := Parse([]string{"--count", "9"})
+	result, err := fn(context.Background(), testSpec(), object.NewStringList([]string{"--count", "2"}))
+	assert.Nil(t, err)
+	assert.Equal(t, result.(*object.Map).Get("count"), object.Object(object.NewInt(2)))
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		args []string
+		err  string
+	}{
+		{[]string{"--missing"}, "unknown flag: --missing"},
+		{[]string{"-x=1"}, "unknown flag: -x"},
+		{[]string{"--count"}, "flag --count requires a value"},
+		{[]string{"--count", "many"}, `invalid value "many" for flag --count: expected an int`},
+		{[]string{"--ratio=x"}, `invalid value "x" for flag --ratio: expected a float`},
+		{[]string{"--verbose=maybe"}, `invalid value "maybe" for flag --verbose: expected a bool`},
+	}
+	for _, tt := range tests {
+		_, err := parse(t, tt.args...)
+		assert.NotNil(t, err, tt.args)
+		assert.Equal(t, err.Error(), tt.err)
+	}
+}
+
+func TestParseRequired(t *testing.T) {
+	s := spec(map[string]map[string]object.Object{
+		"env": {"required": object.True},
+	})
+	_, err := Parse(nil)(context.Background(), s)
+	assert.NotNil(t, err)
+	assert.Equal(t, err.Error(), "missing required flag: --env")
+
+	result, err := Parse([]string{"--env", "prod"})(context.Background(), s)
+	assert.Nil(t, err)
+	assert.Equal(t, result.(*object.Map).Get("env"), object.Object(object.NewString("prod")))
+}
+
+func TestInvalidSpec(t *testing.T) {
+	tests := []struct {
+		spec object.Object
+		err  string
+	}{
+		{object.NewInt(1), "type error: flags.parse: expected a map (got int)"},
+		{spec(map[string]map[string]object.Object{"args": {}}), `flags.parse: "args" is reserved for positional arguments`},
+		{spec(map[string]map[string]object.Object{"a": {"type": object.NewString("date")}}), `flags.parse: flag "a": unsupported type "date" (expected bool, string, int, or float)`},
+		{spec(map[string]map[string]object.Object{"a": {"type": object.NewString("int"), "default": object.NewString("1")}}), `type error: flags.parse: flag "a": default must be of type int (got string)`},
+		{spec(map[string]map[string]object.Object{"a": {"short": object.NewString("ab")}}), `flags.parse: flag "a": short name must be a single character (got "ab")`},
+		{spec(map[string]map[string]object.Object{"a": {"short": object.NewString("x")}, "b": {"short": object.NewString("x")}}), `flags.parse: flags "a" and "b" have the same short name "x"`},
+		{spec(map[string]map[string]object.Object{"a": {"color": object.True}}), `flags.parse: flag "a": unknown option "color"`},
+		{object.NewMap(map[string]object.Object{"a": object.True}), `type error: flags.parse: flag "a": expected a map (got bool)`},
+	}
+	for _, tt := range tests {
+		_, err := Parse(nil)(context.Background(), tt.spec)
+		assert.NotNil(t, err)
+		assert.Equal(t, err.Error(), tt.err)
+	}
+}
+
+func TestUsage(t *testing.T) {
+	result, err := Usage(context.Background(), testSpec())
+	assert.Nil(t, err)
+	assert.Equal(t, result.(*object.String).Value(), `  --count int
+        (default 1)
+  --name string
+        Name to greet
+  --ratio float
+  -v, --verbose`)
+}
+
+func TestModule(t *testing.T) {
+	m := Module([]string{"-v"})
+	assert.Equal(t, m.Name().Value(), "flags")
+	parseFn, ok := m.GetAttr("parse")
+	assert.True(t, ok)
+	result, err := parseFn.(*object.Builtin).Call(context.Background(), testSpec())
+	assert.Nil(t, err)
+	assert.Equal(t, result.(*object.Map).Get("verbose"), object.Object(object.True))
+}
@@ -0,0 +1,26 @@
+package funcs
+
+import "github.com/deepnoodle-ai/risor/v2/pkg/object"
+
+func init() {
+	object.RegisterModule("funcs", ModuleDoc(), Docs)
+}
+
+// Docs returns documentation for the funcs module.
+func Docs() []object.FuncSpec {
+	return funcsDocs
+}
+
+// ModuleDoc returns the module-level documentation.
+func ModuleDoc() string {
+	return "Higher-order function helpers"
+}
+
+var funcsDocs = []object.FuncSpec{
+	{Name: "partial", Doc: "Bind leading arguments to a function", Args: []string{"fn", "args..."}, Returns: "function"},
+	{Name: "compose", Doc: "Chain functions from right to left", Args: []string{"fns..."}, Returns: "function"},
+	{Name: "memoize", Doc: "Cache results by argument value", Args: []string{"fn", "options?"}, Returns: "function"},
+	{Name: "once", Doc: "Call a function only the first time", Args: []string{"fn"}, Returns: "function"},
+	{Name: "throttle", Doc: "Space calls at least interval seconds apart", Args: []string{"fn", "interval"}, Returns: "function"},
+	{Name: "debounce", Doc: "Skip calls made within wait seconds of the last", Args: []string{"fn", "wait"}, Returns: "function"},
+}
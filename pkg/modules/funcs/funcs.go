@@ -0,0 +1,365 @@
+package funcs
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+)
+
+// callableArg returns arg as a Callable, or a type error naming fn.
+func callableArg(fn string, arg object.Object) (object.Callable, error) {
+	callable, ok := arg.(object.Callable)
+	if !ok {
+		return nil, object.TypeErrorf("funcs.%s() expected a function (%s given)", fn, arg.Type())
+	}
+	return callable, nil
+}
+
+// durationArg converts a number of seconds to a positive duration.
+func durationArg(fn string, arg object.Object) (time.Duration, error) {
+	seconds, err := object.AsFloat(arg)
+	if err != nil {
+		return 0, err
+	}
+	if seconds <= 0 {
+		return 0, object.ValueErrorf("funcs.%s() expected a positive number of seconds (got %s)", fn, arg.Inspect())
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// Partial returns a function that calls fn with the given arguments followed
+// by the arguments it is called with.
+func Partial(ctx context.Context, args ...object.Object) (object.Object, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("funcs.partial: expected at least 1 argument, got 0")
+	}
+	fn, err := callableArg("partial", args[0])
+	if err != nil {
+		return nil, err
+	}
+	bound := args[1:]
+	return object.NewBuiltin("partial", func(ctx context.Context, args ...object.Object) (object.Object, error) {
+		combined := make([]object.Object, 0, len(bound)+len(args))
+		combined = append(combined, bound...)
+		combined = append(combined, args...)
+		return fn.Call(ctx, combined...)
+	}), nil
+}
+
+// Compose returns a function that applies the given functions from right to
+// left. The rightmost function receives the call arguments and each other
+// function receives the result of the one after it.
+func Compose(ctx context.Context, args ...object.Object) (object.Object, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("funcs.compose: expected at least 1 argument, got 0")
+	}
+	fns := make([]object.Callable, len(args))
+	for i, arg := range args {
+		fn, err := callableArg("compose", arg)
+		if err != nil {
+			return nil, err
+		}
+		fns[i] = fn
+	}
+	return object.NewBuiltin("composed", func(ctx context.Context, args ...object.Object) (object.Object, error) {
+		result, err := fns[len(fns)-1].Call(ctx, args...)
+		if err != nil {
+			return nil, err
+		}
+		for i := len(fns) - 2; i >= 0; i-- {
+			if result, err = fns[i].Call(ctx, result); err != nil {
+				return nil, err
+			}
+		}
+		return result, nil
+	}), nil
+}
+
+// writeKey appends a representation of obj to b that is equal for equal
+// values of the same type. Only plain data can be used as a cache key.
+func writeKey(b *strings.Builder, obj object.Object) error {
+	switch obj := obj.(type) {
+	case *object.NilType:
+		b.WriteString("n")
+	case *object.Bool:
+		b.WriteString("b" + obj.Inspect())
+	case *object.Int:
+		b.WriteString("i" + obj.Inspect())
+	case *object.Float:
+		b.WriteString("f" + obj.Inspect())
+	case *object.Byte:
+		b.WriteString("y" + obj.Inspect())
+	case *object.String:
+		b.WriteString("s" + strconv.Quote(obj.Value()))
+	case *object.List:
+		b.WriteString("[")
+		for _, item := range obj.Value() {
+			if err := writeKey(b, item); err != nil {
+				return err
+			}
+			b.WriteString(",")
+		}
+		b.WriteString("]")
+	case *object.Tuple:
+		b.WriteString("(")
+		for _, item := range obj.Value() {
+			if err := writeKey(b, item); err != nil {
+				return err
+			}
+			b.WriteString(",")
+		}
+		b.WriteString(")")
+	case *object.Map:
+		b.WriteString("{")
+		for _, key := range obj.Keys().Value() {
+			value, _ := obj.GetItem(key)
+			if err := writeKey(b, key); err != nil {
+				return err
+			}
+			b.WriteString(":")
+			if err := writeKey(b, value); err != nil {
+				return err
+			}
+			b.WriteString(",")
+		}
+		b.WriteString("}")
+	default:
+		return object.TypeErrorf("funcs.memoize: cannot cache calls with a %s argument", obj.Type())
+	}
+	return nil
+}
+
+// cacheEntry is a cached result and the key it is stored under.
+type cacheEntry struct {
+	key   string
+	value object.Object
+}
+
+// memoCache maps argument keys to results. When maxSize is above zero, the
+// least recently used entry is evicted to make room for a new one.
+type memoCache struct {
+	mu      sync.Mutex
+	maxSize int
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+func (c *memoCache) get(key string) (object.Object, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(elem)
+	return elem.Value.(*cacheEntry).value, true
+}
+
+func (c *memoCache) put(key string, value object.Object) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[key]; ok {
+		elem.Value.(*cacheEntry).value = value
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&cacheEntry{key: key, value: value})
+	if c.maxSize > 0 && c.order.Len() > c.maxSize {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// Memoize returns a function that caches the results of fn by argument
+// value. Calls that fail are not cached. The optional map accepts
+// "max_size", which bounds the cache by evicting the least recently used
+// result.
+func Memoize(ctx context.Context, args ...object.Object) (object.Object, error) {
+	if len(args) < 1 || len(args) > 2 {
+		return nil, object.NewArgsRangeError("funcs.memoize", 1, 2, len(args))
+	}
+	fn, err := callableArg("memoize", args[0])
+	if err != nil {
+		return nil, err
+	}
+	cache := &memoCache{order: list.New(), entries: map[string]*list.Element{}}
+	if len(args) == 2 {
+		opts, ok := args[1].(*object.Map)
+		if !ok {
+			return nil, object.TypeErrorf("funcs.memoize() expected a map of options (%s given)", args[1].Type())
+		}
+		if err := opts.CheckStringKeys(); err != nil {
+			return nil, fmt.Errorf("funcs.memoize: %w", err)
+		}
+		for _, key := range opts.SortedKeys() {
+			switch key {
+			case "max_size":
+				size, err := object.AsInt(opts.Get(key))
+				if err != nil {
+					return nil, fmt.Errorf("funcs.memoize: max_size: %w", err)
+				}
+				if size < 0 {
+					return nil, object.ValueErrorf("funcs.memoize: max_size must not be negative (got %d)", size)
+				}
+				cache.maxSize = int(size)
+			default:
+				return nil, fmt.Errorf("funcs.memoize: unknown option %q", key)
+			}
+		}
+	}
+	return object.NewBuiltin("memoized", func(ctx context.Context, args ...object.Object) (object.Object, error) {
+		var b strings.Builder
+		for _, arg := range args {
+			if err := writeKey(&b, arg); err != nil {
+				return nil, err
+			}
+			b.WriteString(";")
+		}
+		key := b.String()
+		if result, ok := cache.get(key); ok {
+			return result, nil
+		}
+		result, err := fn.Call(ctx, args...)
+		if err != nil {
+			return nil, err
+		}
+		cache.put(key, result)
+		return result, nil
+	}), nil
+}
+
+// onceCallKey marks a context as belonging to a call made by a once
+// function, so that a recursive call fails instead of deadlocking.
+type onceCallKey struct{ state *onceState }
+
+type onceState struct {
+	mu     sync.Mutex
+	done   bool
+	result object.Object
+}
+
+// Once returns a function that calls fn the first time it is called and
+// returns that result on every later call. If the call fails, the error is
+// returned and the next call tries again.
+func Once(ctx context.Context, args ...object.Object) (object.Object, error) {
+	if len(args) != 1 {
+		return nil, object.NewArgsError("funcs.once", 1, len(args))
+	}
+	fn, err := callableArg("once", args[0])
+	if err != nil {
+		return nil, err
+	}
+	state := &onceState{}
+	return object.NewBuiltin("once", func(ctx context.Context, args ...object.Object) (object.Object, error) {
+		if ctx.Value(onceCallKey{state}) != nil {
+			return nil, fmt.Errorf("funcs.once: function called itself")
+		}
+		state.mu.Lock()
+		defer state.mu.Unlock()
+		if state.done {
+			return state.result, nil
+		}
+		result, err := fn.Call(context.WithValue(ctx, onceCallKey{state}, true), args...)
+		if err != nil {
+			return nil, err
+		}
+		state.done, state.result = true, result
+		return result, nil
+	}), nil
+}
+
+// Throttle returns a function that calls fn at most once per interval, given
+// in seconds. A call made sooner waits until the interval has passed, or
+// fails if the context is cancelled first.
+func Throttle(ctx context.Context, args ...object.Object) (object.Object, error) {
+	if len(args) != 2 {
+		return nil, object.NewArgsError("funcs.throttle", 2, len(args))
+	}
+	fn, err := callableArg("throttle", args[0])
+	if err != nil {
+		return nil, err
+	}
+	interval, err := durationArg("throttle", args[1])
+	if err != nil {
+		return nil, err
+	}
+	var mu sync.Mutex
+	var next time.Time
+	return object.NewBuiltin("throttled", func(ctx context.Context, args ...object.Object) (object.Object, error) {
+		mu.Lock()
+		if wait := time.Until(next); wait > 0 {
+			timer := time.NewTimer(wait)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				mu.Unlock()
+				return nil, ctx.Err()
+			}
+		}
+		next = time.Now().Add(interval)
+		mu.Unlock()
+		return fn.Call(ctx, args...)
+	}), nil
+}
+
+// Debounce returns a function that calls fn only once calls have paused for
+// wait seconds. A call made within wait of the previous call is skipped and
+// returns the result of the last call that ran, or null if none has. Every
+// call, skipped or not, restarts the wait.
+func Debounce(ctx context.Context, args ...object.Object) (object.Object, error) {
+	if len(args) != 2 {
+		return nil, object.NewArgsError("funcs.debounce", 2, len(args))
+	}
+	fn, err := callableArg("debounce", args[0])
+	if err != nil {
+		return nil, err
+	}
+	wait, err := durationArg("debounce", args[1])
+	if err != nil {
+		return nil, err
+	}
+	var mu sync.Mutex
+	var last time.Time
+	var lastResult object.Object = object.Nil
+	return object.NewBuiltin("debounced", func(ctx context.Context, args ...object.Object) (object.Object, error) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		mu.Lock()
+		now := time.Now()
+		skip := !last.IsZero() && now.Sub(last) < wait
+		last = now
+		result := lastResult
+		mu.Unlock()
+		if skip {
+			return result, nil
+		}
+		result, err := fn.Call(ctx, args...)
+		if err != nil {
+			return nil, err
+		}
+		mu.Lock()
+		lastResult = result
+		mu.Unlock()
+		return result, nil
+	}), nil
+}
+
+func Module() *object.Module {
+	return object.NewBuiltinsModule("funcs", map[string]object.Object{
+		"partial":  object.NewBuiltin("partial", Partial),
+		"compose":  object.NewBuiltin("compose", Compose),
+		"memoize":  object.NewBuiltin("memoize", Memoize),
+		"once":     object.NewBuiltin("once", Once),
+		"throttle": object.NewBuiltin("throttle", Throttle),
+		"debounce": object.NewBuiltin("debounce", Debounce),
+	})
+}
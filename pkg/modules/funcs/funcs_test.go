@@ -0,0 +1,188 @@
+package funcs
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+// counter returns a builtin that records how many times it was called and
+// returns its arguments as a list.
+func counter(calls *int) *object.Builtin {
+	return object.NewBuiltin("f", func(ctx context.Context, args ...object.Object) (object.Object, error) {
+		*calls++
+		return object.NewList(args), nil
+	})
+}
+
+func call(t *testing.T, fn object.Object, args ...object.Object) object.Object {
+	t.Helper()
+	result, err := fn.(object.Callable).Call(context.Background(), args...)
+	assert.Nil(t, err)
+	return result
+}
+
+func TestPartial(t *testing.T) {
+	var calls int
+	fn, err := Partial(context.Background(), counter(&calls), object.NewInt(1), object.NewInt(2))
+	assert.Nil(t, err)
+	assert.Equal(t, call(t, fn, object.NewInt(3)).Inspect(), "[1, 2, 3]")
+	assert.Equal(t, call(t, fn).Inspect(), "[1, 2]")
+
+	_, err = Partial(context.Background(), object.NewInt(1))
+	assert.Error(t, err)
+}
+
+func TestCompose(t *testing.T) {
+	double := object.NewBuiltin("double", func(ctx context.Context, args ...object.Object) (object.Object, error) {
+		n, err := object.AsInt(args[0])
+		if err != nil {
+			return nil, err
+		}
+		return object.NewInt(n * 2), nil
+	})
+	add := object.NewBuiltin("add", func(ctx context.Context, args ...object.Object) (object.Object, error) {
+		a, _ := object.AsInt(args[0])
+		b, _ := object.AsInt(args[1])
+		return object.NewInt(a + b), nil
+	})
+	fn, err := Compose(context.Background(), double, double, add)
+	assert.Nil(t, err)
+	assert.Equal(t, call(t, fn, object.NewInt(1), object.NewInt(2)), object.NewInt(12))
+
+	_, err = Compose(context.Background())
+	assert.Error(t, err)
+}
+
+func TestMemoize(t *testing.T) {
+	var calls int
+	fn, err := Memoize(context.Background(), counter(&calls))
+	assert.Nil(t, err)
+	call(t, fn, object.NewInt(1))
+	call(t, fn, object.NewInt(1))
+	call(t, fn, object.NewFloat(1))
+	call(t, fn, object.NewString("1"))
+	assert.Equal(t, calls, 3)
+
+	// Lists and maps are compared by value
+	items := object.NewList([]object.Object{object.NewString("a")})
+	call(t, fn, items)
+	call(t, fn, object.NewList([]object.Object{object.NewString("a")}))
+	assert.Equal(t, calls, 4)
+
+	// Keys that are not strings are part of the cache key
+	one := object.NewMap(nil)
+	one.SetItem(object.NewInt(1), object.NewString("a"))
+	two := one.Copy()
+	two.SetItem(object.NewInt(2), object.NewString("b"))
+	assert.Equal(t, call(t, fn, one).Inspect(), `[{1: "a"}]`)
+	assert.Equal(t, call(t, fn, two).Inspect(), `[{1: "a", 2: "b"}]`)
+	assert.Equal(t, calls, 6)
+
+	_, err = fn.(object.Callable).Call(context.Background(), counter(&calls))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot cache")
+}
+
+func TestMemoizeMaxSize(t *testing.T) {
+	var calls int
+	opts := object.NewMap(map[string]object.Object{"max_size": object.NewInt(2)})
+	fn, err := Memoize(context.Background(), counter(&calls), opts)
+	assert.Nil(t, err)
+	call(t, fn, object.NewInt(1))
+	call(t, fn, object.NewInt(2))
+	call(t, fn, object.NewInt(1)) // 1 is now the most recently used
+	call(t, fn, object.NewInt(3)) // evicts 2
+	assert.Equal(t, calls, 3)
+	call(t, fn, object.NewInt(1))
+	assert.Equal(t, calls, 3)
+	call(t, fn, object.NewInt(2))
+	assert.Equal(t, calls, 4)
+
+	bad := object.NewMap(map[string]object.Object{"size": object.NewInt(2)})
+	_, err = Memoize(context.Background(), counter(&calls), bad)
+	assert.Error(t, err)
+	bad = object.NewMap(map[string]object.Object{"max_size": object.NewInt(-1)})
+	_, err = Memoize(context.Background(), counter(&calls), bad)
+	assert.Error(t, err)
+	bad = object.NewMap(nil)
+	bad.SetItem(object.NewInt(1), object.NewInt(2))
+	_, err = Memoize(context.Background(), counter(&calls), bad)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "map keys must be strings")
+}
+
+func TestOnce(t *testing.T) {
+	var calls int
+	fn, err := Once(context.Background(), counter(&calls))
+	assert.Nil(t, err)
+	assert.Equal(t, call(t, fn, object.NewInt(1)).Inspect(), "[1]")
+	assert.Equal(t, call(t, fn, object.NewInt(2)).Inspect(), "[1]")
+	assert.Equal(t, calls, 1)
+}
+
+func TestOnceRetriesAfterError(t *testing.T) {
+	var calls int
+	flaky := object.NewBuiltin("flaky", func(ctx context.Context, args ...object.Object) (object.Object, error) {
+		calls++
+		if calls == 1 {
+			return nil, object.Errorf("not yet")
+		}
+		return object.NewInt(int64(calls)), nil
+	})
+	fn, err := Once(context.Background(), flaky)
+	assert.Nil(t, err)
+	_, err = fn.(object.Callable).Call(context.Background())
+	assert.Error(t, err)
+	assert.Equal(t, call(t, fn), object.NewInt(2))
+	assert.Equal(t, call(t, fn), object.NewInt(2))
+}
+
+func TestOnceRecursion(t *testing.T) {
+	var fn object.Object
+	recurse := object.NewBuiltin("recurse", func(ctx context.Context, args ...object.Object) (object.Object, error) {
+		return fn.(object.Callable).Call(ctx)
+	})
+	fn, err := Once(context.Background(), recurse)
+	assert.Nil(t, err)
+	_, err = fn.(object.Callable).Call(context.Background())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "called itself")
+}
+
+func TestThrottle(t *testing.T) {
+	var calls int
+	fn, err := Throttle(context.Background(), counter(&calls), object.NewFloat(0.05))
+	assert.Nil(t, err)
+	start := time.Now()
+	call(t, fn)
+	call(t, fn)
+	call(t, fn)
+	assert.Equal(t, calls, 3)
+	assert.True(t, time.Since(start) >= 100*time.Millisecond)
+
+	// A cancelled context ends the wait
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = fn.(object.Callable).Call(ctx)
+	assert.Equal(t, err, context.Canceled)
+	assert.Equal(t, calls, 3)
+
+	_, err = Throttle(context.Background(), counter(&calls), object.NewInt(0))
+	assert.Error(t, err)
+}
+
+func TestDebounce(t *testing.T) {
+	var calls int
+	fn, err := Debounce(context.Background(), counter(&calls), object.NewFloat(0.05))
+	assert.Nil(t, err)
+	assert.Equal(t, call(t, fn, object.NewInt(1)).Inspect(), "[1]")
+	assert.Equal(t, call(t, fn, object.NewInt(2)).Inspect(), "[1]")
+	assert.Equal(t, calls, 1)
+	time.Sleep(60 * time.Millisecond)
+	assert.Equal(t, call(t, fn, object.NewInt(3)).Inspect(), "[3]")
+	assert.Equal(t, calls, 2)
+}
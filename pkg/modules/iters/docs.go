@@ -0,0 +1,29 @@
+package iters
+
+import "github.com/deepnoodle-ai/risor/v2/pkg/object"
+
+func init() {
+	object.RegisterModule("iters", ModuleDoc(), Docs)
+}
+
+// Docs returns documentation for the iters module.
+func Docs() []object.FuncSpec {
+	return itersDocs
+}
+
+// ModuleDoc returns the module-level documentation.
+func ModuleDoc() string {
+	return "Lazy iterator building blocks"
+}
+
+var itersDocs = []object.FuncSpec{
+	{Name: "zip", Doc: "Combine values from each input into lists", Args: []string{"inputs..."}, Returns: "iter"},
+	{Name: "chain", Doc: "Values of each input in turn", Args: []string{"inputs..."}, Returns: "iter"},
+	{Name: "product", Doc: "Cartesian product of the inputs", Args: []string{"inputs..."}, Returns: "iter"},
+	{Name: "permutations", Doc: "Orderings of r values", Args: []string{"items", "r?"}, Returns: "iter"},
+	{Name: "combinations", Doc: "Selections of r values", Args: []string{"items", "r"}, Returns: "iter"},
+	{Name: "windowed", Doc: "Sliding windows of n values", Args: []string{"items", "n", "step?"}, Returns: "iter"},
+	{Name: "enumerate", Doc: "Pair each value with its index", Args: []string{"items", "start?"}, Returns: "iter"},
+	{Name: "count", Doc: "Count up without end", Args: []string{"start?", "step?"}, Returns: "iter"},
+	{Name: "repeat", Doc: "Repeat a value", Args: []string{"value", "n?"}, Returns: "iter"},
+}
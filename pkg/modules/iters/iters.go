@@ -0,0 +1,446 @@
+package iters
+
+import (
+	"context"
+	"fmt"
+	"iter"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+)
+
+// enumerableArg returns arg as an Enumerable, or a type error naming fn.
+func enumerableArg(fn string, arg object.Object) (object.Enumerable, error) {
+	e, ok := arg.(object.Enumerable)
+	if !ok {
+		return nil, object.TypeErrorf("iters.%s() expected an enumerable (%s given)", fn, arg.Type())
+	}
+	return e, nil
+}
+
+// enumerableArgs converts each argument with enumerableArg.
+func enumerableArgs(fn string, args []object.Object) ([]object.Enumerable, error) {
+	inputs := make([]object.Enumerable, len(args))
+	for i, arg := range args {
+		e, err := enumerableArg(fn, arg)
+		if err != nil {
+			return nil, err
+		}
+		inputs[i] = e
+	}
+	return inputs, nil
+}
+
+// each calls fn with the values of e in the order iter() yields them.
+func each(ctx context.Context, e object.Enumerable, fn func(value object.Object) bool) error {
+	return object.IterOf(e).Iterate(ctx, func(key, value object.Object) bool {
+		return fn(value)
+	})
+}
+
+// collect reads all values of e.
+func collect(ctx context.Context, e object.Enumerable) ([]object.Object, error) {
+	var items []object.Object
+	err := each(ctx, e, func(value object.Object) bool {
+		items = append(items, value)
+		return true
+	})
+	return items, err
+}
+
+// newIter returns an iterator over the values yielded by gen, with keys
+// numbered from 0.
+func newIter(desc string, gen func(ctx context.Context, yield func(object.Object) bool) error) *object.Iter {
+	return object.NewIterFunc(desc, func(ctx context.Context, fn func(key, value object.Object) bool) error {
+		index := int64(0)
+		return gen(ctx, func(value object.Object) bool {
+			index++
+			return fn(object.NewInt(index-1), value)
+		})
+	})
+}
+
+// tuple copies the items of pool at the given indices into a new list.
+func tuple(pool []object.Object, indices []int) *object.List {
+	items := make([]object.Object, len(indices))
+	for i, index := range indices {
+		items[i] = pool[index]
+	}
+	return object.NewList(items)
+}
+
+// Zip returns an iterator of lists holding one value from each input,
+// stopping when the shortest input is exhausted.
+func Zip(ctx context.Context, args ...object.Object) (object.Object, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("iters.zip: expected at least 1 argument, got 0")
+	}
+	inputs, err := enumerableArgs("zip", args)
+	if err != nil {
+		return nil, err
+	}
+	return newIter("zip", func(ctx context.Context, yield func(object.Object) bool) error {
+		nexts := make([]func() (object.Object, bool), len(inputs))
+		errs := make([]error, len(inputs))
+		for i, input := range inputs {
+			// Each input runs as a coroutine so values can be pulled from
+			// all of them in step
+			next, stop := iter.Pull(func(yield func(object.Object) bool) {
+				errs[i] = each(ctx, input, yield)
+			})
+			defer stop()
+			nexts[i] = next
+		}
+		for {
+			row := make([]object.Object, len(nexts))
+			for i, next := range nexts {
+				value, ok := next()
+				if !ok {
+					return errs[i]
+				}
+				row[i] = value
+			}
+			if !yield(object.NewList(row)) {
+				return nil
+			}
+		}
+	}), nil
+}
+
+// Chain returns an iterator over the values of each input in turn.
+func Chain(ctx context.Context, args ...object.Object) (object.Object, error) {
+	inputs, err := enumerableArgs("chain", args)
+	if err != nil {
+		return nil, err
+	}
+	return newIter("chain", func(ctx context.Context, yield func(object.Object) bool) error {
+		for _, input := range inputs {
+			stopped := false
+			err := each(ctx, input, func(value object.Object) bool {
+				stopped = !yield(value)
+				return !stopped
+			})
+			if err != nil || stopped {
+				return err
+			}
+		}
+		return nil
+	}), nil
+}
+
+// Product returns an iterator over the cartesian product of the inputs, as
+// lists with one value from each input. The last input varies fastest.
+// Each input is read in full when iteration starts.
+func Product(ctx context.Context, args ...object.Object) (object.Object, error) {
+	inputs, err := enumerableArgs("product", args)
+	if err != nil {
+		return nil, err
+	}
+	return newIter("product", func(ctx context.Context, yield func(object.Object) bool) error {
+		pools := make([][]object.Object, len(inputs))
+		for i, input := range inputs {
+			pool, err := collect(ctx, input)
+			if err != nil {
+				return err
+			}
+			if len(pool) == 0 {
+				return nil
+			}
+			pools[i] = pool
+		}
+		indices := make([]int, len(pools))
+		for {
+			row := make([]object.Object, len(pools))
+			for i, pool := range pools {
+				row[i] = pool[indices[i]]
+			}
+			if !yield(object.NewList(row)) {
+				return nil
+			}
+			i := len(pools) - 1
+			for ; i >= 0; i-- {
+				indices[i]++
+				if indices[i] < len(pools[i]) {
+					break
+				}
+				indices[i] = 0
+			}
+			if i < 0 {
+				return nil
+			}
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+	}), nil
+}
+
+// Permutations returns an iterator over the orderings of r values from the
+// input, as lists. r defaults to the number of values. The input is read in
+// full when iteration starts.
+func Permutations(ctx context.Context, args ...object.Object) (object.Object, error) {
+	if len(args) < 1 || len(args) > 2 {
+		return nil, object.NewArgsRangeError("iters.permutations", 1, 2, len(args))
+	}
+	input, err := enumerableArg("permutations", args[0])
+	if err != nil {
+		return nil, err
+	}
+	size := int64(-1)
+	if len(args) == 2 {
+		if size, err = object.AsInt(args[1]); err != nil {
+			return nil, err
+		}
+		if size < 0 {
+			return nil, object.ValueErrorf("iters.permutations() expected a non-negative size (got %d)", size)
+		}
+	}
+	return newIter("permutations", func(ctx context.Context, yield func(object.Object) bool) error {
+		pool, err := collect(ctx, input)
+		if err != nil {
+			return err
+		}
+		n, r := len(pool), int(size)
+		if size < 0 {
+			r = n
+		}
+		if r > n {
+			return nil
+		}
+		indices := make([]int, n)
+		for i := range indices {
+			indices[i] = i
+		}
+		cycles := make([]int, r)
+		for i := range cycles {
+			cycles[i] = n - i
+		}
+		if !yield(tuple(pool, indices[:r])) {
+			return nil
+		}
+	next:
+		for {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			for i := r - 1; i >= 0; i-- {
+				cycles[i]--
+				if cycles[i] == 0 {
+					// Rotate indices[i:] left by one
+					first := indices[i]
+					copy(indices[i:], indices[i+1:])
+					indices[n-1] = first
+					cycles[i] = n - i
+					continue
+				}
+				j := n - cycles[i]
+				indices[i], indices[j] = indices[j], indices[i]
+				if !yield(tuple(pool, indices[:r])) {
+					return nil
+				}
+				continue next
+			}
+			return nil
+		}
+	}), nil
+}
+
+// Combinations returns an iterator over the r-length selections of values
+// from the input, as lists in input order. The input is read in full when
+// iteration starts.
+func Combinations(ctx context.Context, args ...object.Object) (object.Object, error) {
+	if len(args) != 2 {
+		return nil, object.NewArgsError("iters.combinations", 2, len(args))
+	}
+	input, err := enumerableArg("combinations", args[0])
+	if err != nil {
+		return nil, err
+	}
+	size, err := object.AsInt(args[1])
+	if err != nil {
+		return nil, err
+	}
+	if size < 0 {
+		return nil, object.ValueErrorf("iters.combinations() expected a non-negative size (got %d)", size)
+	}
+	return newIter("combinations", func(ctx context.Context, yield func(object.Object) bool) error {
+		pool, err := collect(ctx, input)
+		if err != nil {
+			return err
+		}
+		n, r := len(pool), int(size)
+		if r > n {
+			return nil
+		}
+		indices := make([]int, r)
+		for i := range indices {
+			indices[i] = i
+		}
+		for {
+			if !yield(tuple(pool, indices)) {
+				return nil
+			}
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			i := r - 1
+			for i >= 0 && indices[i] == i+n-r {
+				i--
+			}
+			if i < 0 {
+				return nil
+			}
+			indices[i]++
+			for j := i + 1; j < r; j++ {
+				indices[j] = indices[j-1] + 1
+			}
+		}
+	}), nil
+}
+
+// Windowed returns an iterator over sliding windows of n consecutive
+// values, as lists. Windows start every step values (default 1). A final
+// window with fewer than n values is not produced.
+func Windowed(ctx context.Context, args ...object.Object) (object.Object, error) {
+	if len(args) < 2 || len(args) > 3 {
+		return nil, object.NewArgsRangeError("iters.windowed", 2, 3, len(args))
+	}
+	input, err := enumerableArg("windowed", args[0])
+	if err != nil {
+		return nil, err
+	}
+	size, err := object.AsInt(args[1])
+	if err != nil {
+		return nil, err
+	}
+	step := int64(1)
+	if len(args) == 3 {
+		if step, err = object.AsInt(args[2]); err != nil {
+			return nil, err
+		}
+	}
+	if size < 1 || step < 1 {
+		return nil, object.ValueErrorf("iters.windowed() expected a positive size and step (got %d, %d)", size, step)
+	}
+	return newIter("windowed", func(ctx context.Context, yield func(object.Object) bool) error {
+		window := make([]object.Object, 0, size)
+		skip := int64(0)
+		return each(ctx, input, func(value object.Object) bool {
+			if skip > 0 {
+				skip--
+				return true
+			}
+			window = append(window, value)
+			if int64(len(window)) < size {
+				return true
+			}
+			items := make([]object.Object, size)
+			copy(items, window)
+			if step >= size {
+				window = window[:0]
+				skip = step - size
+			} else {
+				window = append(window[:0], window[step:]...)
+			}
+			return yield(object.NewList(items))
+		})
+	}), nil
+}
+
+// Enumerate returns an iterator of [index, value] lists, with indexes
+// counting from start (default 0).
+func Enumerate(ctx context.Context, args ...object.Object) (object.Object, error) {
+	if len(args) < 1 || len(args) > 2 {
+		return nil, object.NewArgsRangeError("iters.enumerate", 1, 2, len(args))
+	}
+	input, err := enumerableArg("enumerate", args[0])
+	if err != nil {
+		return nil, err
+	}
+	start := int64(0)
+	if len(args) == 2 {
+		if start, err = object.AsInt(args[1]); err != nil {
+			return nil, err
+		}
+	}
+	return newIter("enumerate", func(ctx context.Context, yield func(object.Object) bool) error {
+		index := start
+		return each(ctx, input, func(value object.Object) bool {
+			index++
+			return yield(object.NewList([]object.Object{object.NewInt(index - 1), value}))
+		})
+	}), nil
+}
+
+// Count returns an unbounded iterator over start, start+step, and so on.
+// start defaults to 0 and step to 1.
+func Count(ctx context.Context, args ...object.Object) (object.Object, error) {
+	if len(args) > 2 {
+		return nil, object.NewArgsRangeError("iters.count", 0, 2, len(args))
+	}
+	start, step := int64(0), int64(1)
+	var err error
+	if len(args) > 0 {
+		if start, err = object.AsInt(args[0]); err != nil {
+			return nil, err
+		}
+	}
+	if len(args) > 1 {
+		if step, err = object.AsInt(args[1]); err != nil {
+			return nil, err
+		}
+	}
+	return newIter("count", func(ctx context.Context, yield func(object.Object) bool) error {
+		for value := start; ; value += step {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if !yield(object.NewInt(value)) {
+				return nil
+			}
+		}
+	}), nil
+}
+
+// Repeat returns an iterator that yields value n times, or without end if
+// n is not given.
+func Repeat(ctx context.Context, args ...object.Object) (object.Object, error) {
+	if len(args) < 1 || len(args) > 2 {
+		return nil, object.NewArgsRangeError("iters.repeat", 1, 2, len(args))
+	}
+	value := args[0]
+	times := int64(-1)
+	if len(args) == 2 {
+		var err error
+		if times, err = object.AsInt(args[1]); err != nil {
+			return nil, err
+		}
+		if times < 0 {
+			return nil, object.ValueErrorf("iters.repeat() expected a non-negative count (got %d)", times)
+		}
+	}
+	return newIter("repeat", func(ctx context.Context, yield func(object.Object) bool) error {
+		for i := int64(0); times < 0 || i < times; i++ {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if !yield(value) {
+				return nil
+			}
+		}
+		return nil
+	}), nil
+}
+
+func Module() *object.Module {
+	return object.NewBuiltinsModule("iters", map[string]object.Object{
+		"zip":          object.NewBuiltin("zip", Zip),
+		"chain":        object.NewBuiltin("chain", Chain),
+		"product":      object.NewBuiltin("product", Product),
+		"permutations": object.NewBuiltin("permutations", Permutations),
+		"combinations": object.NewBuiltin("combinations", Combinations),
+		"windowed":     object.NewBuiltin("windowed", Windowed),
+		"enumerate":    object.NewBuiltin("enumerate", Enumerate),
+		"count":        object.NewBuiltin("count", Count),
+		"repeat":       object.NewBuiltin("repeat", Repeat),
+	})
+}
@@ -0,0 +1,142 @@
+package iters
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+func ints(values ...int64) *object.List {
+	items := make([]object.Object, len(values))
+	for i, v := range values {
+		items[i] = object.NewInt(v)
+	}
+	return object.NewList(items)
+}
+
+// collectIter runs fn with args and collects the resulting iterator.
+func collectIter(t *testing.T, fn object.BuiltinFunction, args ...object.Object) string {
+	t.Helper()
+	ctx := context.Background()
+	result, err := fn(ctx, args...)
+	assert.Nil(t, err)
+	list, err := result.(*object.Iter).Collect(ctx)
+	assert.Nil(t, err)
+	return list.Inspect()
+}
+
+func TestZip(t *testing.T) {
+	strs := object.NewStringList([]string{"a", "b", "c"})
+	assert.Equal(t, collectIter(t, Zip, strs, ints(1, 2)), `[["a", 1], ["b", 2]]`)
+	assert.Equal(t, collectIter(t, Zip, strs), `[["a"], ["b"], ["c"]]`)
+
+	// Unbounded inputs are read only as far as needed
+	count, err := Count(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, collectIter(t, Zip, count, strs), `[[0, "a"], [1, "b"], [2, "c"]]`)
+
+	_, err = Zip(context.Background())
+	assert.Error(t, err)
+	_, err = Zip(context.Background(), object.NewInt(1))
+	assert.Error(t, err)
+}
+
+func TestZipError(t *testing.T) {
+	ctx := context.Background()
+	fail := object.NewBuiltin("fail", func(ctx context.Context, args ...object.Object) (object.Object, error) {
+		return nil, object.Errorf("boom")
+	})
+	failing, err := object.IterOf(ints(1, 2)).Map(fail)
+	assert.Nil(t, err)
+	result, err := Zip(ctx, ints(1, 2, 3), failing)
+	assert.Nil(t, err)
+	_, err = result.(*object.Iter).Collect(ctx)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "boom")
+}
+
+func TestChain(t *testing.T) {
+	m := object.NewMap(map[string]object.Object{"k": object.NewInt(1)})
+	assert.Equal(t, collectIter(t, Chain, ints(1, 2), object.NewRange(3, 5, 1), m), `[1, 2, 3, 4, "k"]`)
+	assert.Equal(t, collectIter(t, Chain), `[]`)
+
+	ctx := context.Background()
+	result, err := Chain(ctx, ints(1, 2), ints(3, 4))
+	assert.Nil(t, err)
+	taken, err := result.(*object.Iter).Take(3)
+	assert.Nil(t, err)
+	list, err := taken.Collect(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, list.Inspect(), "[1, 2, 3]")
+}
+
+func TestProduct(t *testing.T) {
+	strs := object.NewStringList([]string{"a", "b"})
+	assert.Equal(t, collectIter(t, Product, strs, ints(1, 2)), `[["a", 1], ["a", 2], ["b", 1], ["b", 2]]`)
+	assert.Equal(t, collectIter(t, Product, strs, ints()), `[]`)
+	assert.Equal(t, collectIter(t, Product), `[[]]`)
+}
+
+func TestPermutations(t *testing.T) {
+	assert.Equal(t, collectIter(t, Permutations, ints(1, 2, 3), object.NewInt(2)),
+		"[[1, 2], [1, 3], [2, 1], [2, 3], [3, 1], [3, 2]]")
+	assert.Equal(t, collectIter(t, Permutations, ints(1, 2, 3)),
+		"[[1, 2, 3], [1, 3, 2], [2, 1, 3], [2, 3, 1], [3, 1, 2], [3, 2, 1]]")
+	assert.Equal(t, collectIter(t, Permutations, ints(1), object.NewInt(2)), "[]")
+	assert.Equal(t, collectIter(t, Permutations, ints(1, 2), object.NewInt(0)), "[[]]")
+	_, err := Permutations(context.Background(), ints(1), object.NewInt(-1))
+	assert.Error(t, err)
+}
+
+func TestCombinations(t *testing.T) {
+	strs := object.NewStringList([]string{"a", "b", "c"})
+	assert.Equal(t, collectIter(t, Combinations, strs, object.NewInt(2)), `[["a", "b"], ["a", "c"], ["b", "c"]]`)
+	assert.Equal(t, collectIter(t, Combinations, strs, object.NewInt(3)), `[["a", "b", "c"]]`)
+	assert.Equal(t, collectIter(t, Combinations, strs, object.NewInt(4)), `[]`)
+	assert.Equal(t, collectIter(t, Combinations, ints(1, 2, 3, 4), object.NewInt(3)),
+		"[[1, 2, 3], [1, 2, 4], [1, 3, 4], [2, 3, 4]]")
+	_, err := Combinations(context.Background(), strs)
+	assert.Error(t, err)
+}
+
+func TestWindowed(t *testing.T) {
+	assert.Equal(t, collectIter(t, Windowed, ints(1, 2, 3, 4), object.NewInt(2)), "[[1, 2], [2, 3], [3, 4]]")
+	assert.Equal(t, collectIter(t, Windowed, object.NewRange(0, 7, 1), object.NewInt(3), object.NewInt(3)),
+		"[[0, 1, 2], [3, 4, 5]]")
+	assert.Equal(t, collectIter(t, Windowed, object.NewRange(0, 7, 1), object.NewInt(2), object.NewInt(3)),
+		"[[0, 1], [3, 4]]")
+	assert.Equal(t, collectIter(t, Windowed, ints(1), object.NewInt(2)), "[]")
+	_, err := Windowed(context.Background(), ints(1), object.NewInt(0))
+	assert.Error(t, err)
+}
+
+func TestEnumerate(t *testing.T) {
+	strs := object.NewStringList([]string{"a", "b"})
+	assert.Equal(t, collectIter(t, Enumerate, strs), `[[0, "a"], [1, "b"]]`)
+	assert.Equal(t, collectIter(t, Enumerate, strs, object.NewInt(1)), `[[1, "a"], [2, "b"]]`)
+}
+
+func TestCountAndRepeat(t *testing.T) {
+	ctx := context.Background()
+	result, err := Count(ctx, object.NewInt(10), object.NewInt(5))
+	assert.Nil(t, err)
+	taken, err := result.(*object.Iter).Take(3)
+	assert.Nil(t, err)
+	list, err := taken.Collect(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, list.Inspect(), "[10, 15, 20]")
+
+	assert.Equal(t, collectIter(t, Repeat, object.NewString("x"), object.NewInt(3)), `["x", "x", "x"]`)
+	assert.Equal(t, collectIter(t, Repeat, object.NewString("x"), object.NewInt(0)), `[]`)
+
+	// Unbounded iterators stop when the context is done
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	result, err = Repeat(ctx, object.Nil)
+	assert.Nil(t, err)
+	_, err = result.(*object.Iter).Collect(ctx)
+	assert.Equal(t, err, context.DeadlineExceeded)
+}
@@ -2,6 +2,10 @@ package math
 
 import "github.com/deepnoodle-ai/risor/v2/pkg/object"
 
+func init() {
+	object.RegisterModule("math", ModuleDoc(), Docs)
+}
+
 // Docs returns documentation for the math module.
 func Docs() []object.FuncSpec {
 	return mathDocs
@@ -24,12 +28,13 @@ var mathDocs = []object.FuncSpec{
 	{Name: "sign", Doc: "Sign of x (-1, 0, or 1)", Args: []string{"x"}, Returns: "int"},
 	{Name: "floor", Doc: "Floor (round down)", Args: []string{"x"}, Returns: "float"},
 	{Name: "ceil", Doc: "Ceiling (round up)", Args: []string{"x"}, Returns: "float"},
-	{Name: "round", Doc: "Round to nearest integer", Args: []string{"x"}, Returns: "float"},
+	{Name: "round", Doc: "Round to ndigits decimal places (default 0)", Args: []string{"x", "ndigits?"}, Returns: "float"},
 	{Name: "trunc", Doc: "Truncate toward zero", Args: []string{"x"}, Returns: "float"},
 	{Name: "min", Doc: "Minimum of values", Args: []string{"x..."}, Returns: "float"},
 	{Name: "max", Doc: "Maximum of values", Args: []string{"x..."}, Returns: "float"},
 	{Name: "clamp", Doc: "Clamp x to [min, max]", Args: []string{"x", "min", "max"}, Returns: "float"},
 	{Name: "sum", Doc: "Sum of list elements", Args: []string{"items"}, Returns: "float"},
+	{Name: "copysign", Doc: "Magnitude of x with the sign of y", Args: []string{"x", "y"}, Returns: "float"},
 	// Powers and roots
 	{Name: "sqrt", Doc: "Square root", Args: []string{"x"}, Returns: "float"},
 	{Name: "cbrt", Doc: "Cube root", Args: []string{"x"}, Returns: "float"},
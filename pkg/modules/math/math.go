@@ -87,21 +87,95 @@ func Floor(ctx context.Context, args ...object.Object) (object.Object, error) {
 	}
 }
 
-// Round returns the nearest integer, rounding half away from zero.
+// Round returns x rounded to ndigits decimal places (default 0), rounding
+// half away from zero. A negative ndigits rounds to the left of the decimal
+// point. Ints stay ints.
 func Round(ctx context.Context, args ...object.Object) (object.Object, error) {
-	if len(args) != 1 {
-		return nil, fmt.Errorf("math.round: expected 1 argument, got %d", len(args))
+	if len(args) < 1 || len(args) > 2 {
+		return nil, fmt.Errorf("math.round: expected 1 or 2 arguments, got %d", len(args))
+	}
+	var ndigits int64
+	if len(args) == 2 {
+		n, err := object.AsInt(args[1])
+		if err != nil {
+			return nil, err
+		}
+		ndigits = n
 	}
 	switch arg := args[0].(type) {
 	case *object.Int:
-		return arg, nil
+		v, err := roundInt(arg.Value(), ndigits)
+		if err != nil {
+			return nil, err
+		}
+		return object.NewInt(v), nil
 	case *object.Float:
-		return object.NewFloat(math.Round(arg.Value())), nil
+		return object.NewFloat(roundFloat(arg.Value(), ndigits)), nil
 	default:
 		return nil, object.TypeErrorf("math.round: expected number, got %s", args[0].Type())
 	}
 }
 
+// roundInt rounds v to a multiple of 10^-ndigits, reporting an error if the
+// result does not fit in an int.
+func roundInt(v, ndigits int64) (int64, error) {
+	if ndigits >= 0 {
+		return v, nil
+	}
+	if ndigits < -18 {
+		// Only a unit of 10^19 can round an int away from zero, to ±10^19
+		if ndigits == -19 && (v >= 5e18 || v <= -5e18) {
+			return 0, roundOverflow(v, ndigits)
+		}
+		return 0, nil
+	}
+	unit := int64(1)
+	for i := int64(0); i < -ndigits; i++ {
+		unit *= 10
+	}
+	rem := v % unit
+	switch {
+	case rem > 0 && 2*rem >= unit:
+		if v > math.MaxInt64-(unit-rem) {
+			return 0, roundOverflow(v, ndigits)
+		}
+		return v - rem + unit, nil
+	case rem < 0 && -2*rem >= unit:
+		if v < math.MinInt64+(unit+rem) {
+			return 0, roundOverflow(v, ndigits)
+		}
+		return v - rem - unit, nil
+	}
+	return v - rem, nil
+}
+
+func roundOverflow(v, ndigits int64) error {
+	return object.ValueErrorf("math.round: rounding %d to %d digits overflows an int", v, ndigits)
+}
+
+func roundFloat(x float64, ndigits int64) float64 {
+	switch {
+	case ndigits == 0 || math.IsNaN(x) || math.IsInf(x, 0):
+		return math.Round(x)
+	case ndigits > 0:
+		if ndigits > 308 {
+			return x
+		}
+		scale := math.Pow10(int(ndigits))
+		scaled := x * scale
+		if math.IsInf(scaled, 0) {
+			return x
+		}
+		return math.Round(scaled) / scale
+	default:
+		if ndigits < -308 {
+			return math.Copysign(0, x)
+		}
+		scale := math.Pow10(int(-ndigits))
+		return math.Round(x/scale) * scale
+	}
+}
+
 // Trunc returns the integer part of x, truncating toward zero.
 func Trunc(ctx context.Context, args ...object.Object) (object.Object, error) {
 	if len(args) != 1 {
@@ -427,6 +501,22 @@ func Hypot(ctx context.Context, args ...object.Object) (object.Object, error) {
 	return object.NewFloat(math.Hypot(x, y)), nil
 }
 
+// Copysign returns a value with the magnitude of x and the sign of y.
+func Copysign(ctx context.Context, args ...object.Object) (object.Object, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("math.copysign: expected 2 arguments, got %d", len(args))
+	}
+	x, err := object.AsFloat(args[0])
+	if err != nil {
+		return nil, err
+	}
+	y, err := object.AsFloat(args[1])
+	if err != nil {
+		return nil, err
+	}
+	return object.NewFloat(math.Copysign(x, y)), nil
+}
+
 // Sinh returns the hyperbolic sine of x.
 func Sinh(ctx context.Context, args ...object.Object) (object.Object, error) {
 	if len(args) != 1 {
@@ -533,13 +623,14 @@ func Module() *object.Module {
 		"nan": object.NewFloat(math.NaN()),
 
 		// Basic operations
-		"abs":   object.NewBuiltin("abs", Abs),
-		"sign":  object.NewBuiltin("sign", Sign),
-		"ceil":  object.NewBuiltin("ceil", Ceil),
-		"floor": object.NewBuiltin("floor", Floor),
-		"round": object.NewBuiltin("round", Round),
-		"trunc": object.NewBuiltin("trunc", Trunc),
-		"clamp": object.NewBuiltin("clamp", Clamp),
+		"abs":      object.NewBuiltin("abs", Abs),
+		"sign":     object.NewBuiltin("sign", Sign),
+		"ceil":     object.NewBuiltin("ceil", Ceil),
+		"floor":    object.NewBuiltin("floor", Floor),
+		"round":    object.NewBuiltin("round", Round),
+		"trunc":    object.NewBuiltin("trunc", Trunc),
+		"clamp":    object.NewBuiltin("clamp", Clamp),
+		"copysign": object.NewBuiltin("copysign", Copysign),
 
 		// Min/max/sum
 		"min": object.NewBuiltin("min", Min),
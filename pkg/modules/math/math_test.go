@@ -170,6 +170,38 @@ func TestRound(t *testing.T) {
 	}
 }
 
+func TestRoundDigits(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name     string
+		input    object.Object
+		ndigits  int64
+		expected object.Object
+	}{
+		{"two places", object.NewFloat(3.14159), 2, object.NewFloat(3.14)},
+		{"half away from zero", object.NewFloat(-0.125), 2, object.NewFloat(-0.13)},
+		{"tens", object.NewFloat(1234.5), -1, object.NewFloat(1230)},
+		{"too many places", object.NewFloat(1.5), 400, object.NewFloat(1.5)},
+		{"int unchanged", object.NewInt(42), 2, object.NewInt(42)},
+		{"int hundreds", object.NewInt(1250), -2, object.NewInt(1300)},
+		{"negative int hundreds", object.NewInt(-1249), -2, object.NewInt(-1200)},
+		{"negative int half", object.NewInt(-1250), -2, object.NewInt(-1300)},
+		{"int beyond range", object.NewInt(99), -20, object.NewInt(0)},
+		{"max int down", object.NewInt(math.MaxInt64), -2, object.NewInt(9223372036854775800)},
+		{"min int down", object.NewInt(math.MinInt64), -2, object.NewInt(-9223372036854775800)},
+		{"max int to 10^18", object.NewInt(math.MaxInt64), -18, object.NewInt(9e18)},
+		{"below half of 10^19", object.NewInt(4999999999999999999), -19, object.NewInt(0)},
+		{"max int beyond range", object.NewInt(math.MaxInt64), -20, object.NewInt(0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Round(ctx, tt.input, object.NewInt(tt.ndigits))
+			assert.Nil(t, err)
+			assert.Equal(t, result, tt.expected)
+		})
+	}
+}
+
 func TestRoundErrors(t *testing.T) {
 	ctx := context.Background()
 	_, err := Round(ctx)
@@ -177,6 +209,22 @@ func TestRoundErrors(t *testing.T) {
 
 	_, err = Round(ctx, object.NewString("hello"))
 	assert.NotNil(t, err)
+
+	_, err = Round(ctx, object.NewFloat(1.5), object.NewString("2"))
+	assert.NotNil(t, err)
+
+	// Rounding away from zero past the int range
+	for _, tt := range []struct{ v, ndigits int64 }{
+		{math.MaxInt64, -1},
+		{math.MinInt64, -1},
+		{math.MaxInt64 - 2, -1},
+		{5e18, -19},
+		{-5e18, -19},
+	} {
+		_, err = Round(ctx, object.NewInt(tt.v), object.NewInt(tt.ndigits))
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "overflows an int")
+	}
 }
 
 func TestTrunc(t *testing.T) {
@@ -577,6 +625,16 @@ func TestHypotErrors(t *testing.T) {
 	assert.NotNil(t, err)
 }
 
+func TestCopysign(t *testing.T) {
+	ctx := context.Background()
+	result, err := Copysign(ctx, object.NewInt(3), object.NewFloat(-0.5))
+	assert.Nil(t, err)
+	assert.Equal(t, result, object.NewFloat(-3))
+
+	_, err = Copysign(ctx, object.NewInt(3))
+	assert.NotNil(t, err)
+}
+
 func TestSinh(t *testing.T) {
 	ctx := context.Background()
 	result, err := Sinh(ctx, object.NewFloat(0))
@@ -0,0 +1,24 @@
+package net
+
+import "github.com/deepnoodle-ai/risor/v2/pkg/object"
+
+func init() {
+	object.RegisterModule("net", ModuleDoc(), Docs)
+}
+
+// Docs returns documentation for the net module.
+func Docs() []object.FuncSpec {
+	return netDocs
+}
+
+// ModuleDoc returns the module-level documentation.
+func ModuleDoc() string {
+	return "IP addresses, CIDR networks, and host lookups"
+}
+
+var netDocs = []object.FuncSpec{
+	{Name: "parse_ip", Doc: "Parse an IPv4 or IPv6 address", Args: []string{"s"}, Returns: "ip"},
+	{Name: "parse_cidr", Doc: "Parse a network in CIDR notation", Args: []string{"s"}, Returns: "cidr"},
+	{Name: "lookup_host", Doc: "Resolve a host name to its addresses", Args: []string{"host"}, Returns: "list"},
+	{Name: "probe", Doc: "Check if a TCP port accepts connections", Args: []string{"host", "port", "timeout?"}, Returns: "bool"},
+}
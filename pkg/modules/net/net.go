@@ -0,0 +1,155 @@
+package net
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"strconv"
+	"time"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+)
+
+// defaultProbeTimeout is how long probe waits for a connection when no
+// timeout is given.
+const defaultProbeTimeout = 3 * time.Second
+
+// Option configures the net module.
+type Option func(*config)
+
+type config struct {
+	networkAccess bool
+}
+
+// WithNetworkAccess enables the functions that use the network:
+// lookup_host and probe. Without it they return an error, so the module is
+// safe to include in sandboxed environments.
+func WithNetworkAccess() Option {
+	return func(c *config) {
+		c.networkAccess = true
+	}
+}
+
+// ParseIP parses an IPv4 or IPv6 address.
+func ParseIP(ctx context.Context, args ...object.Object) (object.Object, error) {
+	if len(args) != 1 {
+		return nil, object.NewArgsError("net.parse_ip", 1, len(args))
+	}
+	s, err := object.AsString(args[0])
+	if err != nil {
+		return nil, err
+	}
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return nil, object.ValueErrorf("net.parse_ip: invalid ip address %q", s)
+	}
+	return NewIPAddr(addr), nil
+}
+
+// ParseCIDR parses a network in CIDR notation. Host bits in the address are
+// cleared, so "10.1.2.3/8" parses as 10.0.0.0/8.
+func ParseCIDR(ctx context.Context, args ...object.Object) (object.Object, error) {
+	if len(args) != 1 {
+		return nil, object.NewArgsError("net.parse_cidr", 1, len(args))
+	}
+	s, err := object.AsString(args[0])
+	if err != nil {
+		return nil, err
+	}
+	prefix, err := netip.ParsePrefix(s)
+	if err != nil {
+		return nil, object.ValueErrorf("net.parse_cidr: invalid cidr %q", s)
+	}
+	return NewPrefix(prefix), nil
+}
+
+func lookupHost(c *config) object.BuiltinFunction {
+	return func(ctx context.Context, args ...object.Object) (object.Object, error) {
+		if len(args) != 1 {
+			return nil, object.NewArgsError("net.lookup_host", 1, len(args))
+		}
+		if !c.networkAccess {
+			return nil, fmt.Errorf("net.lookup_host: network access is not enabled")
+		}
+		host, err := object.AsString(args[0])
+		if err != nil {
+			return nil, err
+		}
+		addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+		if err != nil {
+			return nil, fmt.Errorf("net.lookup_host: %w", err)
+		}
+		items := make([]object.Object, len(addrs))
+		for i, addr := range addrs {
+			items[i] = NewIPAddr(addr)
+		}
+		return object.NewList(items), nil
+	}
+}
+
+func probe(c *config) object.BuiltinFunction {
+	return func(ctx context.Context, args ...object.Object) (object.Object, error) {
+		if len(args) < 2 || len(args) > 3 {
+			return nil, object.NewArgsRangeError("net.probe", 2, 3, len(args))
+		}
+		if !c.networkAccess {
+			return nil, fmt.Errorf("net.probe: network access is not enabled")
+		}
+		var host string
+		if ip, ok := args[0].(*IPAddr); ok {
+			host = ip.String()
+		} else {
+			s, err := object.AsString(args[0])
+			if err != nil {
+				return nil, err
+			}
+			host = s
+		}
+		port, err := object.AsInt(args[1])
+		if err != nil {
+			return nil, err
+		}
+		if port < 1 || port > 65535 {
+			return nil, object.ValueErrorf("net.probe: port must be between 1 and 65535 (got %d)", port)
+		}
+		timeout := defaultProbeTimeout
+		if len(args) == 3 {
+			seconds, err := object.AsFloat(args[2])
+			if err != nil {
+				return nil, err
+			}
+			if seconds <= 0 {
+				return nil, object.ValueErrorf("net.probe: timeout must be positive (got %v)", seconds)
+			}
+			timeout = time.Duration(seconds * float64(time.Second))
+		}
+		dialer := net.Dialer{Timeout: timeout}
+		conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(host, strconv.FormatInt(port, 10)))
+		if err != nil {
+			// Cancellation is an error; a refused or timed out connection
+			// just means the port is not open.
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return nil, ctxErr
+			}
+			return object.False, nil
+		}
+		conn.Close()
+		return object.True, nil
+	}
+}
+
+// Module returns the net module. lookup_host and probe are only usable if
+// the WithNetworkAccess option is given.
+func Module(opts ...Option) *object.Module {
+	c := &config{}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return object.NewBuiltinsModule("net", map[string]object.Object{
+		"parse_ip":    object.NewBuiltin("parse_ip", ParseIP),
+		"parse_cidr":  object.NewBuiltin("parse_cidr", ParseCIDR),
+		"lookup_host": object.NewBuiltin("lookup_host", lookupHost(c)).WithCapabilities(object.CapNet),
+		"probe":       object.NewBuiltin("probe", probe(c)).WithCapabilities(object.CapNet),
+	})
+}
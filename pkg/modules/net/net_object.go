@@ -0,0 +1,452 @@
+package net
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net/netip"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+	"github.com/deepnoodle-ai/risor/v2/pkg/op"
+)
+
+const (
+	IP   object.Type = "ip"
+	CIDR object.Type = "cidr"
+)
+
+var ipMethods = object.NewMethodRegistry[*IPAddr]("ip")
+
+var cidrMethods = object.NewMethodRegistry[*Prefix]("cidr")
+
+func init() {
+	ipMethods.Define("version").
+		Doc("IP version, 4 or 6").
+		Returns("int").
+		Impl(func(ip *IPAddr, ctx context.Context, args ...object.Object) (object.Object, error) {
+			return object.NewInt(int64(ip.Version())), nil
+		})
+
+	ipMethods.Define("is_loopback").
+		Doc("Check if this is a loopback address").
+		Returns("bool").
+		Impl(func(ip *IPAddr, ctx context.Context, args ...object.Object) (object.Object, error) {
+			return object.NewBool(ip.addr.IsLoopback()), nil
+		})
+
+	ipMethods.Define("is_private").
+		Doc("Check if this is a private address (RFC 1918 or RFC 4193)").
+		Returns("bool").
+		Impl(func(ip *IPAddr, ctx context.Context, args ...object.Object) (object.Object, error) {
+			return object.NewBool(ip.addr.IsPrivate()), nil
+		})
+
+	ipMethods.Define("is_multicast").
+		Doc("Check if this is a multicast address").
+		Returns("bool").
+		Impl(func(ip *IPAddr, ctx context.Context, args ...object.Object) (object.Object, error) {
+			return object.NewBool(ip.addr.IsMulticast()), nil
+		})
+
+	ipMethods.Define("is_link_local").
+		Doc("Check if this is a link-local unicast address").
+		Returns("bool").
+		Impl(func(ip *IPAddr, ctx context.Context, args ...object.Object) (object.Object, error) {
+			return object.NewBool(ip.addr.IsLinkLocalUnicast()), nil
+		})
+
+	ipMethods.Define("is_global").
+		Doc("Check if this is a global unicast address").
+		Returns("bool").
+		Impl(func(ip *IPAddr, ctx context.Context, args ...object.Object) (object.Object, error) {
+			return object.NewBool(ip.addr.IsGlobalUnicast()), nil
+		})
+
+	ipMethods.Define("is_unspecified").
+		Doc("Check if this is the unspecified address (0.0.0.0 or ::)").
+		Returns("bool").
+		Impl(func(ip *IPAddr, ctx context.Context, args ...object.Object) (object.Object, error) {
+			return object.NewBool(ip.addr.IsUnspecified()), nil
+		})
+
+	ipMethods.Define("next").
+		Doc("The following address, or null after the last one").
+		Returns("ip").
+		Impl(func(ip *IPAddr, ctx context.Context, args ...object.Object) (object.Object, error) {
+			next := ip.addr.Next()
+			if !next.IsValid() {
+				return object.Nil, nil
+			}
+			return NewIPAddr(next), nil
+		})
+
+	ipMethods.Define("prev").
+		Doc("The preceding address, or null before the first one").
+		Returns("ip").
+		Impl(func(ip *IPAddr, ctx context.Context, args ...object.Object) (object.Object, error) {
+			prev := ip.addr.Prev()
+			if !prev.IsValid() {
+				return object.Nil, nil
+			}
+			return NewIPAddr(prev), nil
+		})
+
+	ipMethods.Define("to_bytes").
+		Doc("The address as 4 or 16 bytes").
+		Returns("bytes").
+		Impl(func(ip *IPAddr, ctx context.Context, args ...object.Object) (object.Object, error) {
+			return object.NewBytes(ip.addr.AsSlice()), nil
+		})
+
+	cidrMethods.Define("contains").
+		Doc("Check if an ip or cidr lies within this network").
+		Arg("addr").
+		Returns("bool").
+		Impl(func(p *Prefix, ctx context.Context, args ...object.Object) (object.Object, error) {
+			if other, ok := args[0].(*Prefix); ok {
+				return object.NewBool(p.ContainsPrefix(other.prefix)), nil
+			}
+			addr, err := asAddr(args[0])
+			if err != nil {
+				return nil, err
+			}
+			return object.NewBool(p.prefix.Contains(addr)), nil
+		})
+
+	cidrMethods.Define("overlaps").
+		Doc("Check if this network shares any address with another").
+		Arg("other").
+		Returns("bool").
+		Impl(func(p *Prefix, ctx context.Context, args ...object.Object) (object.Object, error) {
+			other, err := asPrefix(args[0])
+			if err != nil {
+				return nil, err
+			}
+			return object.NewBool(p.prefix.Overlaps(other)), nil
+		})
+
+	cidrMethods.Define("version").
+		Doc("IP version, 4 or 6").
+		Returns("int").
+		Impl(func(p *Prefix, ctx context.Context, args ...object.Object) (object.Object, error) {
+			return object.NewInt(int64(NewIPAddr(p.prefix.Addr()).Version())), nil
+		})
+
+	cidrMethods.Define("prefix_len").
+		Doc("Number of bits in the network prefix").
+		Returns("int").
+		Impl(func(p *Prefix, ctx context.Context, args ...object.Object) (object.Object, error) {
+			return object.NewInt(int64(p.prefix.Bits())), nil
+		})
+
+	cidrMethods.Define("network").
+		Doc("The first address of the network").
+		Returns("ip").
+		Impl(func(p *Prefix, ctx context.Context, args ...object.Object) (object.Object, error) {
+			return NewIPAddr(p.prefix.Addr()), nil
+		})
+
+	cidrMethods.Define("last").
+		Doc("The last address of the network (the broadcast address for IPv4)").
+		Returns("ip").
+		Impl(func(p *Prefix, ctx context.Context, args ...object.Object) (object.Object, error) {
+			return NewIPAddr(p.Last()), nil
+		})
+
+	cidrMethods.Define("size").
+		Doc("Number of addresses in the network").
+		Returns("int").
+		Impl(func(p *Prefix, ctx context.Context, args ...object.Object) (object.Object, error) {
+			size, err := p.Size()
+			if err != nil {
+				return nil, err
+			}
+			return object.NewInt(size), nil
+		})
+
+	cidrMethods.Define("hosts").
+		Doc("Iterate over the usable host addresses").
+		Returns("iter").
+		Impl(func(p *Prefix, ctx context.Context, args ...object.Object) (object.Object, error) {
+			return p.Hosts(), nil
+		})
+
+	cidrMethods.Define("subnets").
+		Doc("Iterate over the subnets with a longer prefix length").
+		Arg("prefix_len").
+		Returns("iter").
+		Impl(func(p *Prefix, ctx context.Context, args ...object.Object) (object.Object, error) {
+			bits, err := object.AsInt(args[0])
+			if err != nil {
+				return nil, err
+			}
+			return p.Subnets(int(bits))
+		})
+}
+
+// asAddr converts an ip object or a string to an address.
+func asAddr(obj object.Object) (netip.Addr, error) {
+	switch obj := obj.(type) {
+	case *IPAddr:
+		return obj.addr, nil
+	case *object.String:
+		addr, err := netip.ParseAddr(obj.Value())
+		if err != nil {
+			return netip.Addr{}, object.ValueErrorf("invalid ip address %q", obj.Value())
+		}
+		return addr, nil
+	default:
+		return netip.Addr{}, object.TypeErrorf("expected an ip or string (%s given)", obj.Type())
+	}
+}
+
+// asPrefix converts a cidr object or a string to a masked prefix.
+func asPrefix(obj object.Object) (netip.Prefix, error) {
+	switch obj := obj.(type) {
+	case *Prefix:
+		return obj.prefix, nil
+	case *object.String:
+		prefix, err := netip.ParsePrefix(obj.Value())
+		if err != nil {
+			return netip.Prefix{}, object.ValueErrorf("invalid cidr %q", obj.Value())
+		}
+		return prefix.Masked(), nil
+	default:
+		return netip.Prefix{}, object.TypeErrorf("expected a cidr or string (%s given)", obj.Type())
+	}
+}
+
+// IPAddr is an IPv4 or IPv6 address.
+type IPAddr struct {
+	addr netip.Addr
+}
+
+// NewIPAddr returns an ip object for addr. IPv4-mapped IPv6 addresses are
+// converted to IPv4.
+func NewIPAddr(addr netip.Addr) *IPAddr {
+	return &IPAddr{addr: addr.Unmap()}
+}
+
+func (ip *IPAddr) Addr() netip.Addr {
+	return ip.addr
+}
+
+// Version returns 4 or 6.
+func (ip *IPAddr) Version() int {
+	if ip.addr.Is4() {
+		return 4
+	}
+	return 6
+}
+
+func (ip *IPAddr) Type() object.Type {
+	return IP
+}
+
+func (ip *IPAddr) Inspect() string {
+	return fmt.Sprintf("ip(%q)", ip.addr.String())
+}
+
+func (ip *IPAddr) String() string {
+	return ip.addr.String()
+}
+
+func (ip *IPAddr) Interface() any {
+	return ip.addr
+}
+
+func (ip *IPAddr) Equals(other object.Object) bool {
+	o, ok := other.(*IPAddr)
+	return ok && ip.addr == o.addr
+}
+
+// Compare orders IPv4 addresses before IPv6 addresses, and addresses of the
+// same version numerically.
+func (ip *IPAddr) Compare(other object.Object) (int, error) {
+	o, ok := other.(*IPAddr)
+	if !ok {
+		return 0, object.TypeErrorf("unable to compare ip and %s", other.Type())
+	}
+	return ip.addr.Compare(o.addr), nil
+}
+
+func (ip *IPAddr) Attrs() []object.AttrSpec {
+	return ipMethods.Specs()
+}
+
+func (ip *IPAddr) GetAttr(name string) (object.Object, bool) {
+	return ipMethods.GetAttr(ip, name)
+}
+
+func (ip *IPAddr) SetAttr(name string, value object.Object) error {
+	return object.TypeErrorf("ip has no attribute %q", name)
+}
+
+func (ip *IPAddr) IsTruthy() bool {
+	return true
+}
+
+func (ip *IPAddr) RunOperation(opType op.BinaryOpType, right object.Object) (object.Object, error) {
+	return nil, object.TypeErrorf("unsupported operation for ip: %v", opType)
+}
+
+func (ip *IPAddr) MarshalJSON() ([]byte, error) {
+	return ip.addr.MarshalText()
+}
+
+// Prefix is an IP network in CIDR notation. The address is always the
+// first address of the network.
+type Prefix struct {
+	prefix netip.Prefix
+}
+
+// NewPrefix returns a cidr object for prefix, with host bits cleared.
+func NewPrefix(prefix netip.Prefix) *Prefix {
+	return &Prefix{prefix: prefix.Masked()}
+}
+
+func (p *Prefix) Prefix() netip.Prefix {
+	return p.prefix
+}
+
+// ContainsPrefix reports whether other lies entirely within p.
+func (p *Prefix) ContainsPrefix(other netip.Prefix) bool {
+	return other.Bits() >= p.prefix.Bits() && p.prefix.Contains(other.Addr())
+}
+
+// Last returns the last address of the network.
+func (p *Prefix) Last() netip.Addr {
+	bytes := p.prefix.Addr().AsSlice()
+	for i := p.prefix.Bits(); i < len(bytes)*8; i++ {
+		bytes[i/8] |= 0x80 >> (i % 8)
+	}
+	addr, _ := netip.AddrFromSlice(bytes)
+	return addr
+}
+
+// hostBits returns the number of address bits outside the prefix.
+func (p *Prefix) hostBits() int {
+	return p.prefix.Addr().BitLen() - p.prefix.Bits()
+}
+
+// Size returns the number of addresses in the network, or an error if it is
+// too large for an int.
+func (p *Prefix) Size() (int64, error) {
+	if p.hostBits() > 62 {
+		return 0, object.ValueErrorf("cidr %s has too many addresses to count", p.prefix)
+	}
+	return int64(1) << p.hostBits(), nil
+}
+
+// Hosts returns an iterator over the usable host addresses. For IPv4
+// networks larger than /31 this leaves out the network and broadcast
+// addresses, and for IPv6 networks larger than /127 it leaves out the
+// network address.
+func (p *Prefix) Hosts() *object.Iter {
+	first, last := p.prefix.Addr(), p.Last()
+	if p.hostBits() > 1 {
+		first = first.Next()
+		if p.prefix.Addr().Is4() {
+			last = last.Prev()
+		}
+	}
+	desc := fmt.Sprintf("%s.hosts()", p.Inspect())
+	return addrRange(desc, first, last)
+}
+
+// addrRange returns an iterator over the addresses from first to last.
+func addrRange(desc string, first, last netip.Addr) *object.Iter {
+	return object.NewIterFunc(desc, func(ctx context.Context, fn func(key, value object.Object) bool) error {
+		index := int64(0)
+		for addr := first; addr.IsValid() && addr.Compare(last) <= 0; addr = addr.Next() {
+			if index%1024 == 0 {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+			}
+			if !fn(object.NewInt(index), NewIPAddr(addr)) {
+				return nil
+			}
+			index++
+		}
+		return nil
+	})
+}
+
+// Subnets returns an iterator over the subnets of p with the given prefix
+// length.
+func (p *Prefix) Subnets(bits int) (*object.Iter, error) {
+	if bits < p.prefix.Bits() || bits > p.prefix.Addr().BitLen() {
+		return nil, object.ValueErrorf("subnet prefix length must be between %d and %d (got %d)",
+			p.prefix.Bits(), p.prefix.Addr().BitLen(), bits)
+	}
+	// The distance between subnets, as a number added to the address
+	step := new(big.Int).Lsh(big.NewInt(1), uint(p.prefix.Addr().BitLen()-bits))
+	desc := fmt.Sprintf("%s.subnets(%d)", p.Inspect(), bits)
+	return object.NewIterFunc(desc, func(ctx context.Context, fn func(key, value object.Object) bool) error {
+		addr := p.prefix.Addr()
+		value := new(big.Int).SetBytes(addr.AsSlice())
+		for index := int64(0); p.prefix.Contains(addr); index++ {
+			if index%1024 == 0 {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+			}
+			if !fn(object.NewInt(index), NewPrefix(netip.PrefixFrom(addr, bits))) {
+				return nil
+			}
+			value.Add(value, step)
+			bytes := value.FillBytes(make([]byte, addr.BitLen()/8+1))
+			if bytes[0] != 0 {
+				return nil // wrapped past the end of the address space
+			}
+			addr, _ = netip.AddrFromSlice(bytes[1:])
+		}
+		return nil
+	}), nil
+}
+
+func (p *Prefix) Type() object.Type {
+	return CIDR
+}
+
+func (p *Prefix) Inspect() string {
+	return fmt.Sprintf("cidr(%q)", p.prefix.String())
+}
+
+func (p *Prefix) String() string {
+	return p.prefix.String()
+}
+
+func (p *Prefix) Interface() any {
+	return p.prefix
+}
+
+func (p *Prefix) Equals(other object.Object) bool {
+	o, ok := other.(*Prefix)
+	return ok && p.prefix == o.prefix
+}
+
+func (p *Prefix) Attrs() []object.AttrSpec {
+	return cidrMethods.Specs()
+}
+
+func (p *Prefix) GetAttr(name string) (object.Object, bool) {
+	return cidrMethods.GetAttr(p, name)
+}
+
+func (p *Prefix) SetAttr(name string, value object.Object) error {
+	return object.TypeErrorf("cidr has no attribute %q", name)
+}
+
+func (p *Prefix) IsTruthy() bool {
+	return true
+}
+
+func (p *Prefix) RunOperation(opType op.BinaryOpType, right object.Object) (object.Object, error) {
+	return nil, object.TypeErrorf("unsupported operation for cidr: %v", opType)
+}
+
+func (p *Prefix) MarshalJSON() ([]byte, error) {
+	return p.prefix.MarshalText()
+}
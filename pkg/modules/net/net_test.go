@@ -0,0 +1,206 @@
+package net
+
+import (
+	"context"
+	stdnet "net"
+	"testing"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+func callMethod(t *testing.T, obj object.Object, name string, args ...object.Object) object.Object {
+	t.Helper()
+	attr, ok := obj.(interface {
+		GetAttr(string) (object.Object, bool)
+	}).GetAttr(name)
+	assert.True(t, ok, name)
+	result, err := attr.(object.Callable).Call(context.Background(), args...)
+	assert.Nil(t, err)
+	return result
+}
+
+func parseIP(t *testing.T, s string) *IPAddr {
+	t.Helper()
+	result, err := ParseIP(context.Background(), object.NewString(s))
+	assert.Nil(t, err)
+	return result.(*IPAddr)
+}
+
+func parseCIDR(t *testing.T, s string) *Prefix {
+	t.Helper()
+	result, err := ParseCIDR(context.Background(), object.NewString(s))
+	assert.Nil(t, err)
+	return result.(*Prefix)
+}
+
+func TestParseIP(t *testing.T) {
+	ip := parseIP(t, "192.168.1.10")
+	assert.Equal(t, ip.Inspect(), `ip("192.168.1.10")`)
+	assert.Equal(t, callMethod(t, ip, "version"), object.NewInt(4))
+	assert.Equal(t, callMethod(t, ip, "is_private"), object.True)
+	assert.Equal(t, callMethod(t, ip, "is_loopback"), object.False)
+	assert.Equal(t, callMethod(t, ip, "next").Inspect(), `ip("192.168.1.11")`)
+	assert.Equal(t, callMethod(t, ip, "prev").Inspect(), `ip("192.168.1.9")`)
+	assert.Equal(t, callMethod(t, ip, "to_bytes"), object.NewBytes([]byte{192, 168, 1, 10}))
+
+	assert.Equal(t, parseIP(t, "::ffff:10.0.0.1").String(), "10.0.0.1")
+	assert.Equal(t, callMethod(t, parseIP(t, "::1"), "is_loopback"), object.True)
+	assert.Equal(t, callMethod(t, parseIP(t, "255.255.255.255"), "next"), object.Nil)
+
+	_, err := ParseIP(context.Background(), object.NewString("300.1.1.1"))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid ip address")
+}
+
+func TestIPCompare(t *testing.T) {
+	a, b := parseIP(t, "10.0.0.2"), parseIP(t, "10.0.0.10")
+	cmp, err := a.Compare(b)
+	assert.Nil(t, err)
+	assert.Equal(t, cmp, -1)
+	assert.True(t, a.Equals(parseIP(t, "10.0.0.2")))
+	assert.False(t, a.Equals(object.NewString("10.0.0.2")))
+	cmp, err = parseIP(t, "::").Compare(a)
+	assert.Nil(t, err)
+	assert.Equal(t, cmp, 1)
+}
+
+func TestParseCIDR(t *testing.T) {
+	network := parseCIDR(t, "10.1.2.3/8")
+	assert.Equal(t, network.Inspect(), `cidr("10.0.0.0/8")`)
+	assert.Equal(t, callMethod(t, network, "prefix_len"), object.NewInt(8))
+	assert.Equal(t, callMethod(t, network, "network").Inspect(), `ip("10.0.0.0")`)
+	assert.Equal(t, callMethod(t, network, "last").Inspect(), `ip("10.255.255.255")`)
+	assert.Equal(t, callMethod(t, network, "size"), object.NewInt(1<<24))
+	assert.Equal(t, callMethod(t, parseCIDR(t, "2001:db8::/120"), "last").Inspect(), `ip("2001:db8::ff")`)
+
+	_, err := ParseCIDR(context.Background(), object.NewString("10.0.0.0/33"))
+	assert.Error(t, err)
+	attr, _ := parseCIDR(t, "::/0").GetAttr("size")
+	_, err = attr.(object.Callable).Call(context.Background())
+	assert.Error(t, err)
+}
+
+func TestCIDRContains(t *testing.T) {
+	network := parseCIDR(t, "10.0.0.0/24")
+	assert.Equal(t, callMethod(t, network, "contains", object.NewString("10.0.0.42")), object.True)
+	assert.Equal(t, callMethod(t, network, "contains", parseIP(t, "10.0.1.1")), object.False)
+	assert.Equal(t, callMethod(t, network, "contains", parseIP(t, "::1")), object.False)
+	assert.Equal(t, callMethod(t, network, "contains", parseCIDR(t, "10.0.0.128/25")), object.True)
+	assert.Equal(t, callMethod(t, network, "contains", parseCIDR(t, "10.0.0.0/16")), object.False)
+	assert.Equal(t, callMethod(t, network, "overlaps", object.NewString("10.0.0.0/16")), object.True)
+	assert.Equal(t, callMethod(t, network, "overlaps", object.NewString("10.0.1.0/24")), object.False)
+
+	attr, _ := network.GetAttr("contains")
+	_, err := attr.(object.Callable).Call(context.Background(), object.NewString("nope"))
+	assert.Error(t, err)
+}
+
+func TestHosts(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		cidr     string
+		expected string
+	}{
+		{"192.168.0.0/30", `[ip("192.168.0.1"), ip("192.168.0.2")]`},
+		{"192.168.0.0/31", `[ip("192.168.0.0"), ip("192.168.0.1")]`},
+		{"192.168.0.7/32", `[ip("192.168.0.7")]`},
+		{"2001:db8::/126", `[ip("2001:db8::1"), ip("2001:db8::2"), ip("2001:db8::3")]`},
+	}
+	for _, tc := range tests {
+		hosts, err := parseCIDR(t, tc.cidr).Hosts().Collect(ctx)
+		assert.Nil(t, err)
+		assert.Equal(t, hosts.Inspect(), tc.expected, tc.cidr)
+	}
+
+	taken, err := parseCIDR(t, "2001:db8::/32").Hosts().Take(2)
+	assert.Nil(t, err)
+	first, err := taken.Collect(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, first.Inspect(), `[ip("2001:db8::1"), ip("2001:db8::2")]`)
+}
+
+func TestSubnets(t *testing.T) {
+	ctx := context.Background()
+	subnets, err := parseCIDR(t, "10.0.0.0/16").Subnets(18)
+	assert.Nil(t, err)
+	result, err := subnets.Collect(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, result.Inspect(),
+		`[cidr("10.0.0.0/18"), cidr("10.0.64.0/18"), cidr("10.0.128.0/18"), cidr("10.0.192.0/18")]`)
+
+	subnets, err = parseCIDR(t, "255.255.255.0/24").Subnets(25)
+	assert.Nil(t, err)
+	result, err = subnets.Collect(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, result.Inspect(), `[cidr("255.255.255.0/25"), cidr("255.255.255.128/25")]`)
+
+	subnets, err = parseCIDR(t, "::/0").Subnets(64)
+	assert.Nil(t, err)
+	taken, err := subnets.Take(2)
+	assert.Nil(t, err)
+	result, err = taken.Collect(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, result.Inspect(), `[cidr("::/64"), cidr("0:0:0:1::/64")]`)
+
+	_, err = parseCIDR(t, "10.0.0.0/16").Subnets(8)
+	assert.Error(t, err)
+}
+
+func TestNetworkAccessDisabled(t *testing.T) {
+	mod := Module()
+	lookup, _ := mod.GetAttr("lookup_host")
+	_, err := lookup.(object.Callable).Call(context.Background(), object.NewString("localhost"))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "network access is not enabled")
+
+	probe, _ := mod.GetAttr("probe")
+	_, err = probe.(object.Callable).Call(context.Background(), object.NewString("localhost"), object.NewInt(80))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "network access is not enabled")
+}
+
+func TestCapabilities(t *testing.T) {
+	mod := Module()
+	assert.Equal(t, mod.Capabilities(), []object.Capability{object.CapNet})
+	parse, _ := mod.GetAttr("parse_ip")
+	assert.Len(t, parse.(*object.Builtin).Capabilities(), 0)
+}
+
+func TestProbe(t *testing.T) {
+	listener, err := stdnet.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	port := listener.Addr().(*stdnet.TCPAddr).Port
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+
+	probe, _ := Module(WithNetworkAccess()).GetAttr("probe")
+	ctx := context.Background()
+	result, err := probe.(object.Callable).Call(ctx,
+		object.NewString("127.0.0.1"), object.NewInt(int64(port)), object.NewFloat(1))
+	assert.Nil(t, err)
+	assert.Equal(t, result, object.True)
+
+	listener.Close()
+	result, err = probe.(object.Callable).Call(ctx,
+		parseIP(t, "127.0.0.1"), object.NewInt(int64(port)), object.NewFloat(1))
+	assert.Nil(t, err)
+	assert.Equal(t, result, object.False)
+
+	_, err = probe.(object.Callable).Call(ctx, object.NewString("127.0.0.1"), object.NewInt(0))
+	assert.Error(t, err)
+}
+
+func TestLookupHost(t *testing.T) {
+	lookup, _ := Module(WithNetworkAccess()).GetAttr("lookup_host")
+	result, err := lookup.(object.Callable).Call(context.Background(), object.NewString("127.0.0.1"))
+	assert.Nil(t, err)
+	assert.Equal(t, result.Inspect(), `[ip("127.0.0.1")]`)
+}
@@ -0,0 +1,21 @@
+package os
+
+import "github.com/deepnoodle-ai/risor/v2/pkg/object"
+
+func init() {
+	object.RegisterModule("os", ModuleDoc(), Docs)
+}
+
+// Docs returns documentation for the os module.
+func Docs() []object.FuncSpec {
+	return osDocs
+}
+
+// ModuleDoc returns the module-level documentation.
+func ModuleDoc() string {
+	return "The program's command line and exit status"
+}
+
+var osDocs = []object.FuncSpec{
+	{Name: "exit", Doc: "Stop the program with an exit status", Args: []string{"code?"}, Returns: "null"},
+}
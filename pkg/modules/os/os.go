@@ -0,0 +1,38 @@
+package os
+
+import (
+	"context"
+	"slices"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+)
+
+// Exit stops the program with an exit status (default 0) by returning an
+// *object.ExitError. Exit hooks registered with atexit still run, but catch
+// and finally blocks do not. The host decides what the status means; the
+// risor CLI exits the process with it.
+func Exit(ctx context.Context, args ...object.Object) (object.Object, error) {
+	if len(args) > 1 {
+		return nil, object.NewArgsRangeError("os.exit", 0, 1, len(args))
+	}
+	code := int64(0)
+	if len(args) == 1 {
+		var err error
+		if code, err = object.AsInt(args[0]); err != nil {
+			return nil, err
+		}
+	}
+	if code < 0 || code > 255 {
+		return nil, object.ValueErrorf("os.exit: status must be between 0 and 255, got %d", code)
+	}
+	return nil, object.NewExitError(int(code))
+}
+
+// Module returns the os module. args is the program's command line, usually
+// the script path followed by its arguments.
+func Module(args []string) *object.Module {
+	return object.NewBuiltinsModule("os", map[string]object.Object{
+		"args": object.NewStringList(slices.Clone(args)),
+		"exit": object.NewBuiltin("exit", Exit),
+	})
+}
@@ -0,0 +1,53 @@
+package os
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+func TestExit(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := Exit(ctx)
+	var exitErr *object.ExitError
+	assert.True(t, errors.As(err, &exitErr))
+	assert.Equal(t, exitErr.Code, 0)
+
+	_, err = Exit(ctx, object.NewInt(3))
+	assert.True(t, errors.As(err, &exitErr))
+	assert.Equal(t, exitErr.Code, 3)
+	assert.Equal(t, err.Error(), "exit status 3")
+}
+
+func TestExitErrors(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := Exit(ctx, object.NewInt(256))
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "between 0 and 255")
+
+	_, err = Exit(ctx, object.NewInt(-1))
+	assert.NotNil(t, err)
+
+	_, err = Exit(ctx, object.NewString("1"))
+	assert.NotNil(t, err)
+
+	_, err = Exit(ctx, object.NewInt(1), object.NewInt(2))
+	assert.NotNil(t, err)
+}
+
+func TestModule(t *testing.T) {
+	args := []string{"script.risor", "a"}
+	m := Module(args)
+	args[1] = "changed"
+
+	value, ok := m.GetAttr("args")
+	assert.True(t, ok)
+	assert.Equal(t, value.Inspect(), `["script.risor", "a"]`)
+	_, ok = m.GetAttr("exit")
+	assert.True(t, ok)
+}
@@ -0,0 +1,22 @@
+package parallel
+
+import "github.com/deepnoodle-ai/risor/v2/pkg/object"
+
+func init() {
+	object.RegisterModule("parallel", ModuleDoc(), Docs)
+}
+
+// Docs returns documentation for the parallel module.
+func Docs() []object.FuncSpec {
+	return parallelDocs
+}
+
+// ModuleDoc returns the module-level documentation.
+func ModuleDoc() string {
+	return "Run a function over a list on a pool of workers"
+}
+
+var parallelDocs = []object.FuncSpec{
+	{Name: "map", Doc: "Call a function for each item in parallel and collect the results", Args: []string{"items", "fn", "options?"}, Returns: "list"},
+	{Name: "each", Doc: "Call a function for each item in parallel", Args: []string{"items", "fn", "options?"}, Returns: "null"},
+}
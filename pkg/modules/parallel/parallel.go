@@ -0,0 +1,220 @@
+package parallel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+)
+
+// Option configures the parallel module.
+type Option func(*config)
+
+type config struct {
+	maxWorkers int
+}
+
+// WithMaxWorkers limits the number of workers a single call may use.
+// Scripts that ask for more get this many. The default is the number of
+// CPUs Go may use.
+func WithMaxWorkers(n int) Option {
+	return func(c *config) {
+		c.maxWorkers = max(n, 1)
+	}
+}
+
+// options holds the settings shared by map and each.
+type options struct {
+	workers int
+}
+
+// parseArgs checks the arguments of map and each: a list, a function, and
+// an optional map of options.
+func (c *config) parseArgs(name string, args []object.Object) (*object.List, object.Callable, *options, error) {
+	if len(args) < 2 || len(args) > 3 {
+		return nil, nil, nil, object.NewArgsRangeError("parallel."+name, 2, 3, len(args))
+	}
+	items, err := object.AsList(args[0])
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	fn, ok := args[1].(object.Callable)
+	if !ok {
+		return nil, nil, nil, object.TypeErrorf("parallel.%s() expected a function (%s given)", name, args[1].Type())
+	}
+	opts := &options{workers: min(runtime.GOMAXPROCS(0), c.maxWorkers)}
+	if len(args) == 3 {
+		m, ok := args[2].(*object.Map)
+		if !ok {
+			return nil, nil, nil, object.TypeErrorf("parallel.%s() expected a map of options (%s given)", name, args[2].Type())
+		}
+		if err := m.CheckStringKeys(); err != nil {
+			return nil, nil, nil, fmt.Errorf("parallel.%s: %w", name, err)
+		}
+		for _, key := range m.SortedKeys() {
+			switch key {
+			case "workers":
+				n, err := object.AsInt(m.Get(key))
+				if err != nil {
+					return nil, nil, nil, fmt.Errorf("parallel.%s: workers: %w", name, err)
+				}
+				if n < 1 {
+					return nil, nil, nil, object.ValueErrorf("parallel.%s: workers must be at least 1 (got %d)", name, n)
+				}
+				opts.workers = int(min(n, int64(c.maxWorkers)))
+			default:
+				return nil, nil, nil, fmt.Errorf("parallel.%s: unknown option %q", name, key)
+			}
+		}
+	}
+	return items, fn, opts, nil
+}
+
+// callers returns one function per worker for calling fn. Closures run on a
+// VM forked from the caller's, one per worker, since a VM runs on a single
+// goroutine. Other callables are called directly.
+func callers(ctx context.Context, name string, fn object.Callable, workers int) ([]func(context.Context, []object.Object) (object.Object, error), error) {
+	calls := make([]func(context.Context, []object.Object) (object.Object, error), workers)
+	closure, isClosure := fn.(*object.Closure)
+	if !isClosure {
+		for i := range calls {
+			calls[i] = func(ctx context.Context, args []object.Object) (object.Object, error) {
+				return fn.Call(ctx, args...)
+			}
+		}
+		return calls, nil
+	}
+	fork, ok := object.GetForkFunc(ctx)
+	if !ok {
+		return nil, fmt.Errorf("parallel.%s: functions can only be run in parallel from a running program", name)
+	}
+	for i := range calls {
+		callFunc, err := fork()
+		if err != nil {
+			return nil, fmt.Errorf("parallel.%s: %w", name, err)
+		}
+		calls[i] = func(ctx context.Context, args []object.Object) (object.Object, error) {
+			return callFunc(ctx, closure, args)
+		}
+	}
+	return calls, nil
+}
+
+// run calls fn for each item on a pool of workers and returns the results
+// in the order of the items. Closures that declare two parameters receive
+// the index and the item, as with list.map. The first failure cancels the
+// calls that have not started, and the errors of every call that failed
+// are returned together.
+func (c *config) run(ctx context.Context, name string, args []object.Object) ([]object.Object, error) {
+	items, fn, opts, err := c.parseArgs(name, args)
+	if err != nil {
+		return nil, err
+	}
+	var passIndex bool
+	if closure, ok := fn.(*object.Closure); ok {
+		count := closure.ParameterCount()
+		if count < 1 || count > 2 {
+			return nil, object.TypeErrorf("parallel.%s() received an incompatible function", name)
+		}
+		passIndex = count == 2
+	}
+	values := items.Value()
+	workers := min(opts.workers, len(values))
+	if workers == 0 {
+		return []object.Object{}, nil
+	}
+	calls, err := callers(ctx, name, fn, workers)
+	if err != nil {
+		return nil, err
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	results := make([]object.Object, len(values))
+	errs := make([]error, len(values))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for _, call := range calls {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				callArgs := []object.Object{values[i]}
+				if passIndex {
+					callArgs = []object.Object{object.NewInt(int64(i)), values[i]}
+				}
+				result, err := call(runCtx, callArgs)
+				if err != nil {
+					errs[i] = err
+					cancel()
+					continue
+				}
+				results[i] = result
+			}
+		}()
+	}
+feed:
+	for i := range values {
+		select {
+		case next <- i:
+		case <-runCtx.Done():
+			break feed
+		}
+	}
+	close(next)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	var failures []error
+	for i, err := range errs {
+		// Calls interrupted by an earlier failure are not failures themselves
+		if err == nil || errors.Is(err, context.Canceled) {
+			continue
+		}
+		failures = append(failures, fmt.Errorf("item %d: %w", i, err))
+	}
+	switch len(failures) {
+	case 0:
+		return results, nil
+	case 1:
+		return nil, fmt.Errorf("parallel.%s: %w", name, failures[0])
+	default:
+		return nil, fmt.Errorf("parallel.%s: %d calls failed: %w", name, len(failures), errors.Join(failures...))
+	}
+}
+
+// mapItems calls fn for each item of a list on a pool of workers and
+// returns the results in the order of the items.
+func (c *config) mapItems(ctx context.Context, args ...object.Object) (object.Object, error) {
+	results, err := c.run(ctx, "map", args)
+	if err != nil {
+		return nil, err
+	}
+	return object.NewList(results), nil
+}
+
+// each calls fn for each item of a list on a pool of workers.
+func (c *config) each(ctx context.Context, args ...object.Object) (object.Object, error) {
+	if _, err := c.run(ctx, "each", args); err != nil {
+		return nil, err
+	}
+	return object.Nil, nil
+}
+
+// Module returns the parallel module. Options may change the number of
+// workers a call may use.
+func Module(opts ...Option) *object.Module {
+	c := &config{maxWorkers: runtime.GOMAXPROCS(0)}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return object.NewBuiltinsModule("parallel", map[string]object.Object{
+		"map":  object.NewBuiltin("map", c.mapItems),
+		"each": object.NewBuiltin("each", c.each),
+	})
+}
@@ -0,0 +1,142 @@
+package parallel
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/deepnoodle-ai/risor/v2/internal/moduletest"
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+	"github.com/deepnoodle-ai/risor/v2/pkg/vm"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+// eval runs source with the parallel module and the default builtins.
+func eval(ctx context.Context, source string) (object.Object, error) {
+	return moduletest.Eval(ctx, source, map[string]any{"parallel": Module()})
+}
+
+func TestMap(t *testing.T) {
+	result, err := eval(context.Background(), `
+	let offset = 100
+	parallel.map(list(range(20)), x => x * x + offset, {workers: 4})
+	`)
+	assert.Nil(t, err)
+	list := result.(*object.List).Value()
+	assert.Len(t, list, 20)
+	for i, item := range list {
+		assert.Equal(t, item, object.NewInt(int64(i*i+100)))
+	}
+}
+
+func TestMapWithIndex(t *testing.T) {
+	result, err := eval(context.Background(), `parallel.map(["a", "b"], (i, x) => x + string(i))`)
+	assert.Nil(t, err)
+	assert.Equal(t, result.Inspect(), `["a0", "b1"]`)
+}
+
+func TestMapBuiltin(t *testing.T) {
+	result, err := eval(context.Background(), `parallel.map([1, 2], string, {workers: 2})`)
+	assert.Nil(t, err)
+	assert.Equal(t, result.Inspect(), `["1", "2"]`)
+}
+
+func TestMapEmpty(t *testing.T) {
+	result, err := eval(context.Background(), `parallel.map([], x => x)`)
+	assert.Nil(t, err)
+	assert.Equal(t, result.Inspect(), "[]")
+}
+
+func TestEach(t *testing.T) {
+	result, err := eval(context.Background(), `parallel.each([1, 2, 3], x => x * 2)`)
+	assert.Nil(t, err)
+	assert.Equal(t, result, object.Object(object.Nil))
+
+	_, err = eval(context.Background(), `parallel.each([1], x => x.missing())`)
+	assert.NotNil(t, err)
+}
+
+func TestMapErrors(t *testing.T) {
+	_, err := eval(context.Background(), `
+	parallel.map([1, 2, 3], x => { if (x == 2) { throw "bad item" }; return x }, {workers: 1})
+	`)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "item 1")
+	assert.Contains(t, err.Error(), "bad item")
+
+	// Every failure is reported when the calls were already running
+	_, err = eval(context.Background(), `
+	parallel.map([1, 2], x => { throw "fail " + string(x) }, {workers: 2})
+	`)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "fail")
+}
+
+func TestCancel(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := eval(ctx, `parallel.each([1, 2, 3, 4], x => range(1000000000).each(i => i), {workers: 4})`)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
+
+func TestArgs(t *testing.T) {
+	ctx := context.Background()
+	fn := object.NewBuiltin("f", func(ctx context.Context, args ...object.Object) (object.Object, error) {
+		return args[0], nil
+	})
+	items := object.NewList([]object.Object{object.NewInt(1)})
+	c := &config{maxWorkers: 4}
+
+	_, err := c.mapItems(ctx, items)
+	assert.NotNil(t, err)
+
+	_, err = c.mapItems(ctx, items, object.NewInt(1))
+	assert.Contains(t, err.Error(), "expected a function")
+
+	_, err = c.mapItems(ctx, items, fn, object.NewMap(map[string]object.Object{"workers": object.NewInt(0)}))
+	assert.Contains(t, err.Error(), "workers must be at least 1")
+
+	_, err = c.mapItems(ctx, items, fn, object.NewMap(map[string]object.Object{"size": object.NewInt(1)}))
+	assert.Contains(t, err.Error(), `unknown option "size"`)
+
+	result, err := c.mapItems(ctx, items, fn)
+	assert.Nil(t, err)
+	assert.Equal(t, result.Inspect(), "[1]")
+}
+
+func TestStepLimit(t *testing.T) {
+	ctx := context.Background()
+	globals := map[string]any{"parallel": Module(WithMaxWorkers(64))}
+	work := "function work(x) { range(5000).each(i => i) }\n"
+	_, err := moduletest.Eval(ctx, work+`list(range(64)).map(work)`, globals, vm.WithMaxSteps(100000))
+	assert.True(t, errors.Is(err, vm.ErrStepLimitExceeded))
+
+	_, err = moduletest.Eval(ctx, work+`parallel.map(list(range(64)), work, {workers: 64})`, globals, vm.WithMaxSteps(100000))
+	assert.True(t, errors.Is(err, vm.ErrStepLimitExceeded))
+
+	_, err = moduletest.Eval(ctx, work+`parallel.map(list(range(4)), work, {workers: 4})`, globals, vm.WithMaxSteps(1000000))
+	assert.Nil(t, err)
+}
+
+func TestMaxWorkers(t *testing.T) {
+	var running, peak atomic.Int64
+	fn := object.NewBuiltin("f", func(ctx context.Context, args ...object.Object) (object.Object, error) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		return args[0], nil
+	})
+	globals := map[string]any{"parallel": Module(WithMaxWorkers(2)), "f": fn}
+	result, err := moduletest.Eval(context.Background(), `parallel.map(list(range(8)), f, {workers: 100})`, globals)
+	assert.Nil(t, err)
+	assert.Equal(t, result.Inspect(), "[0, 1, 2, 3, 4, 5, 6, 7]")
+	assert.True(t, peak.Load() <= 2)
+}
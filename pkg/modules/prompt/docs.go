@@ -0,0 +1,24 @@
+package prompt
+
+import "github.com/deepnoodle-ai/risor/v2/pkg/object"
+
+func init() {
+	object.RegisterModule("prompt", ModuleDoc(), Docs)
+}
+
+// Docs returns documentation for the prompt module.
+func Docs() []object.FuncSpec {
+	return promptDocs
+}
+
+// ModuleDoc returns the module-level documentation.
+func ModuleDoc() string {
+	return "Interactive questions for command line scripts"
+}
+
+var promptDocs = []object.FuncSpec{
+	{Name: "input", Doc: "Ask for a line of text", Args: []string{"message?", "default?"}, Returns: "string"},
+	{Name: "password", Doc: "Ask for a line of text without echoing it", Args: []string{"message?"}, Returns: "string"},
+	{Name: "confirm", Doc: "Ask a yes or no question", Args: []string{"message", "default?"}, Returns: "bool"},
+	{Name: "select", Doc: "Ask the user to pick one of a list of choices", Args: []string{"choices", "message?"}, Returns: "any"},
+}
@@ -0,0 +1,252 @@
+package prompt
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+)
+
+// Option configures the prompt module.
+type Option func(*config)
+
+type config struct {
+	in       *bufio.Reader
+	out      io.Writer
+	password func() (string, error)
+	mu       sync.Mutex // serializes prompts
+}
+
+// WithInput enables the prompts, which write their questions to out and
+// read answers from in, one line at a time. Without it every prompt returns
+// an error, so the module is safe to include in non-interactive
+// environments. Tests can pass a strings.Reader holding the answers.
+func WithInput(in io.Reader, out io.Writer) Option {
+	return func(c *config) {
+		c.in = bufio.NewReader(in)
+		c.out = out
+	}
+}
+
+// WithPasswordReader supplies the function password uses to read a line
+// without echoing it, such as one built on golang.org/x/term.ReadPassword.
+// Without it password reads a line from the input like input does.
+func WithPasswordReader(fn func() (string, error)) Option {
+	return func(c *config) {
+		c.password = fn
+	}
+}
+
+// ask writes question and reads one line of input, without its line ending.
+// Input that ends before any text is read is an error.
+func (c *config) ask(name, question string) (string, error) {
+	if c.in == nil {
+		return "", fmt.Errorf("prompt.%s: interactive input is not enabled", name)
+	}
+	io.WriteString(c.out, question)
+	line, err := c.in.ReadString('\n')
+	if err != nil && (!errors.Is(err, io.EOF) || line == "") {
+		if errors.Is(err, io.EOF) {
+			return "", fmt.Errorf("prompt.%s: end of input", name)
+		}
+		return "", fmt.Errorf("prompt.%s: %w", name, err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// question joins the non-empty parts of a prompt, followed by a space for
+// the answer.
+func question(parts ...string) string {
+	var sb strings.Builder
+	for _, part := range parts {
+		if part != "" {
+			sb.WriteString(part)
+			sb.WriteByte(' ')
+		}
+	}
+	return sb.String()
+}
+
+// optionalString returns args[i] as a string, or "" if it was not given.
+func optionalString(args []object.Object, i int) (string, error) {
+	if i >= len(args) {
+		return "", nil
+	}
+	return object.AsString(args[i])
+}
+
+func input(c *config) object.BuiltinFunction {
+	return func(ctx context.Context, args ...object.Object) (object.Object, error) {
+		if len(args) > 2 {
+			return nil, object.NewArgsRangeError("prompt.input", 0, 2, len(args))
+		}
+		message, err := optionalString(args, 0)
+		if err != nil {
+			return nil, err
+		}
+		fallback, err := optionalString(args, 1)
+		if err != nil {
+			return nil, err
+		}
+		hint := ""
+		if fallback != "" {
+			hint = "[" + fallback + "]"
+		}
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		answer, err := c.ask("input", question(message, hint))
+		if err != nil {
+			return nil, err
+		}
+		if answer == "" {
+			answer = fallback
+		}
+		return object.NewString(answer), nil
+	}
+}
+
+func password(c *config) object.BuiltinFunction {
+	return func(ctx context.Context, args ...object.Object) (object.Object, error) {
+		if len(args) > 1 {
+			return nil, object.NewArgsRangeError("prompt.password", 0, 1, len(args))
+		}
+		message, err := optionalString(args, 0)
+		if err != nil {
+			return nil, err
+		}
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if c.in == nil || c.password == nil {
+			answer, err := c.ask("password", question(message))
+			if err != nil {
+				return nil, err
+			}
+			return object.NewString(answer), nil
+		}
+		io.WriteString(c.out, question(message))
+		answer, err := c.password()
+		// The terminal does not echo the newline that ends the password.
+		io.WriteString(c.out, "\n")
+		if err != nil {
+			return nil, fmt.Errorf("prompt.password: %w", err)
+		}
+		return object.NewString(answer), nil
+	}
+}
+
+func confirm(c *config) object.BuiltinFunction {
+	return func(ctx context.Context, args ...object.Object) (object.Object, error) {
+		if len(args) < 1 || len(args) > 2 {
+			return nil, object.NewArgsRangeError("prompt.confirm", 1, 2, len(args))
+		}
+		message, err := object.AsString(args[0])
+		if err != nil {
+			return nil, err
+		}
+		fallback := false
+		if len(args) == 2 {
+			if fallback, err = object.AsBool(args[1]); err != nil {
+				return nil, err
+			}
+		}
+		hint := "[y/N]"
+		if fallback {
+			hint = "[Y/n]"
+		}
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		for {
+			answer, err := c.ask("confirm", question(message, hint))
+			if err != nil {
+				return nil, err
+			}
+			switch strings.ToLower(strings.TrimSpace(answer)) {
+			case "":
+				return object.NewBool(fallback), nil
+			case "y", "yes":
+				return object.True, nil
+			case "n", "no":
+				return object.False, nil
+			}
+			io.WriteString(c.out, "Please answer y or n.\n")
+		}
+	}
+}
+
+func choose(c *config) object.BuiltinFunction {
+	return func(ctx context.Context, args ...object.Object) (object.Object, error) {
+		if len(args) < 1 || len(args) > 2 {
+			return nil, object.NewArgsRangeError("prompt.select", 1, 2, len(args))
+		}
+		list, err := object.AsList(args[0])
+		if err != nil {
+			return nil, err
+		}
+		choices := list.Value()
+		if len(choices) == 0 {
+			return nil, object.ValueErrorf("prompt.select: no choices given")
+		}
+		message, err := optionalString(args, 1)
+		if err != nil {
+			return nil, err
+		}
+		if message == "" {
+			message = "Select"
+		}
+		labels := make([]string, len(choices))
+		var menu strings.Builder
+		for i, choice := range choices {
+			if s, ok := choice.(*object.String); ok {
+				labels[i] = s.Value()
+			} else {
+				labels[i] = choice.Inspect()
+			}
+			fmt.Fprintf(&menu, "  %d) %s\n", i+1, labels[i])
+		}
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if c.in == nil {
+			return nil, fmt.Errorf("prompt.select: interactive input is not enabled")
+		}
+		io.WriteString(c.out, menu.String())
+		hint := fmt.Sprintf("[1-%d]", len(choices))
+		for {
+			answer, err := c.ask("select", question(message, hint))
+			if err != nil {
+				return nil, err
+			}
+			answer = strings.TrimSpace(answer)
+			// Accept either the number of a choice or its text.
+			if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(choices) {
+				return choices[n-1], nil
+			}
+			for i, label := range labels {
+				if answer == label {
+					return choices[i], nil
+				}
+			}
+			fmt.Fprintf(c.out, "Please enter a number from 1 to %d.\n", len(choices))
+		}
+	}
+}
+
+// Module returns the prompt module. Its prompts return an error unless the
+// WithInput option is given.
+func Module(opts ...Option) *object.Module {
+	c := &config{}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return object.NewBuiltinsModule("prompt", map[string]object.Object{
+		"input":    object.NewBuiltin("input", input(c)),
+		"password": object.NewBuiltin("password", password(c)),
+		"confirm":  object.NewBuiltin("confirm", confirm(c)),
+		"select":   object.NewBuiltin("select", choose(c)),
+	})
+}
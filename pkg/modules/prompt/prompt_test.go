@@ -0,0 +1,116 @@
+package prompt
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+func call(m *object.Module, name string, args ...object.Object) (object.Object, error) {
+	fn, _ := m.GetAttr(name)
+	return fn.(object.Callable).Call(context.Background(), args...)
+}
+
+func TestNotEnabled(t *testing.T) {
+	m := Module()
+	calls := map[string][]object.Object{
+		"input":    nil,
+		"password": nil,
+		"confirm":  {object.NewString("Continue?")},
+		"select":   {object.NewList([]object.Object{object.NewString("a")})},
+	}
+	for name, args := range calls {
+		_, err := call(m, name, args...)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "interactive input is not enabled")
+	}
+}
+
+func TestInput(t *testing.T) {
+	var out strings.Builder
+	m := Module(WithInput(strings.NewReader("alice\n\nlast"), &out))
+
+	result, err := call(m, "input", object.NewString("Name?"))
+	assert.Nil(t, err)
+	assert.Equal(t, result, object.NewString("alice"))
+
+	result, err = call(m, "input", object.NewString("Region?"), object.NewString("us-east-1"))
+	assert.Nil(t, err)
+	assert.Equal(t, result, object.NewString("us-east-1"))
+
+	// The final line needs no newline.
+	result, err = call(m, "input")
+	assert.Nil(t, err)
+	assert.Equal(t, result, object.NewString("last"))
+	assert.Equal(t, out.String(), "Name? Region? [us-east-1] ")
+
+	_, err = call(m, "input")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "end of input")
+}
+
+func TestPassword(t *testing.T) {
+	var out strings.Builder
+	m := Module(WithInput(strings.NewReader("hunter2\r\n"), &out))
+	result, err := call(m, "password", object.NewString("Password:"))
+	assert.Nil(t, err)
+	assert.Equal(t, result, object.NewString("hunter2"))
+	assert.Equal(t, out.String(), "Password: ")
+
+	out.Reset()
+	m = Module(WithInput(strings.NewReader(""), &out), WithPasswordReader(func() (string, error) {
+		return "s3cret", nil
+	}))
+	result, err = call(m, "password", object.NewString("Password:"))
+	assert.Nil(t, err)
+	assert.Equal(t, result, object.NewString("s3cret"))
+	assert.Equal(t, out.String(), "Password: \n")
+}
+
+func TestConfirm(t *testing.T) {
+	var out strings.Builder
+	m := Module(WithInput(strings.NewReader("Yes\nmaybe\nn\n\n\n"), &out))
+
+	result, err := call(m, "confirm", object.NewString("Deploy?"))
+	assert.Nil(t, err)
+	assert.Equal(t, result, object.True)
+
+	result, err = call(m, "confirm", object.NewString("Deploy?"))
+	assert.Nil(t, err)
+	assert.Equal(t, result, object.False)
+
+	result, err = call(m, "confirm", object.NewString("Deploy?"))
+	assert.Nil(t, err)
+	assert.Equal(t, result, object.False)
+
+	result, err = call(m, "confirm", object.NewString("Deploy?"), object.True)
+	assert.Nil(t, err)
+	assert.Equal(t, result, object.True)
+
+	assert.Equal(t, out.String(), "Deploy? [y/N] Deploy? [y/N] Please answer y or n.\n"+
+		"Deploy? [y/N] Deploy? [y/N] Deploy? [Y/n] ")
+}
+
+func TestSelect(t *testing.T) {
+	var out strings.Builder
+	m := Module(WithInput(strings.NewReader("7\nprod\n1\n"), &out))
+	choices := object.NewList([]object.Object{object.NewString("dev"), object.NewString("prod")})
+
+	result, err := call(m, "select", choices, object.NewString("Environment"))
+	assert.Nil(t, err)
+	assert.Equal(t, result, object.NewString("prod"))
+	assert.Equal(t, out.String(), "  1) dev\n  2) prod\nEnvironment [1-2] "+
+		"Please enter a number from 1 to 2.\nEnvironment [1-2] ")
+
+	numbers := object.NewList([]object.Object{object.NewInt(10), object.NewInt(20)})
+	result, err = call(m, "select", numbers)
+	assert.Nil(t, err)
+	assert.Equal(t, result, object.NewInt(10))
+
+	_, err = call(m, "select", object.NewList(nil))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "no choices given")
+}
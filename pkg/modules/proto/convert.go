@@ -0,0 +1,307 @@
+package proto
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// fieldError prefixes err with the full name of the field it concerns.
+func fieldError(fd protoreflect.FieldDescriptor, err error) error {
+	return fmt.Errorf("field %s: %w", fd.FullName(), err)
+}
+
+// toMessage sets the fields of msg from the entries of a map. Keys may be
+// the field names from the .proto file or their JSON (camelCase) names.
+// Entries that are null are left unset.
+func toMessage(msg protoreflect.Message, obj object.Object) error {
+	m, err := object.AsMap(obj)
+	if err != nil {
+		return err
+	}
+	if err := m.CheckStringKeys(); err != nil {
+		return err
+	}
+	fields := msg.Descriptor().Fields()
+	for _, key := range m.SortedKeys() {
+		fd := fields.ByName(protoreflect.Name(key))
+		if fd == nil {
+			fd = fields.ByJSONName(key)
+		}
+		if fd == nil {
+			return object.ValueErrorf("%s has no field %q", msg.Descriptor().FullName(), key)
+		}
+		if err := setField(msg, fd, m.Get(key)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setField sets one field of msg from a Risor value.
+func setField(msg protoreflect.Message, fd protoreflect.FieldDescriptor, obj object.Object) error {
+	if obj == object.Nil {
+		return nil
+	}
+	switch {
+	case fd.IsList():
+		items, err := object.AsList(obj)
+		if err != nil {
+			return fieldError(fd, err)
+		}
+		list := msg.Mutable(fd).List()
+		for _, item := range items.Value() {
+			value, err := toListValue(list, fd, item)
+			if err != nil {
+				return err
+			}
+			list.Append(value)
+		}
+	case fd.IsMap():
+		m, err := object.AsMap(obj)
+		if err != nil {
+			return fieldError(fd, err)
+		}
+		entries := msg.Mutable(fd).Map()
+		for _, key := range m.Keys().Value() {
+			mapKey, err := toMapKey(fd.MapKey(), key)
+			if err != nil {
+				return fieldError(fd, err)
+			}
+			item, _ := m.GetItem(key)
+			var value protoreflect.Value
+			if fd.MapValue().Message() != nil {
+				value = entries.NewValue()
+				if err := toMessage(value.Message(), item); err != nil {
+					return fieldError(fd, err)
+				}
+			} else if value, err = toScalar(fd.MapValue(), item); err != nil {
+				return fieldError(fd, err)
+			}
+			entries.Set(mapKey, value)
+		}
+	case fd.Message() != nil:
+		if err := toMessage(msg.Mutable(fd).Message(), obj); err != nil {
+			return fieldError(fd, err)
+		}
+	default:
+		value, err := toScalar(fd, obj)
+		if err != nil {
+			return fieldError(fd, err)
+		}
+		msg.Set(fd, value)
+	}
+	return nil
+}
+
+// toListValue converts one element of a repeated field.
+func toListValue(list protoreflect.List, fd protoreflect.FieldDescriptor, obj object.Object) (protoreflect.Value, error) {
+	if fd.Message() == nil {
+		value, err := toScalar(fd, obj)
+		if err != nil {
+			return protoreflect.Value{}, fieldError(fd, err)
+		}
+		return value, nil
+	}
+	value := list.NewElement()
+	if err := toMessage(value.Message(), obj); err != nil {
+		return protoreflect.Value{}, fieldError(fd, err)
+	}
+	return value, nil
+}
+
+// toMapKey converts a Risor map key to the key type of a protobuf map.
+// String keys are parsed, so that maps decoded from JSON can be used for
+// maps with integer or bool keys.
+func toMapKey(fd protoreflect.FieldDescriptor, obj object.Object) (protoreflect.MapKey, error) {
+	s, ok := obj.(*object.String)
+	if !ok {
+		value, err := toScalar(fd, obj)
+		if err != nil {
+			return protoreflect.MapKey{}, err
+		}
+		return value.MapKey(), nil
+	}
+	key := s.Value()
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		return protoreflect.ValueOfString(key).MapKey(), nil
+	case protoreflect.BoolKind:
+		b, err := strconv.ParseBool(key)
+		if err != nil {
+			return protoreflect.MapKey{}, object.ValueErrorf("invalid bool map key %q", key)
+		}
+		return protoreflect.ValueOfBool(b).MapKey(), nil
+	}
+	n, err := strconv.ParseInt(key, 10, 64)
+	if err != nil {
+		return protoreflect.MapKey{}, object.ValueErrorf("invalid integer map key %q", key)
+	}
+	value, err := toScalar(fd, object.NewInt(n))
+	if err != nil {
+		return protoreflect.MapKey{}, err
+	}
+	return value.MapKey(), nil
+}
+
+// toScalar converts a Risor value to a protobuf value of a non-message kind.
+// Integers are checked against the range of the field type.
+func toScalar(fd protoreflect.FieldDescriptor, obj object.Object) (protoreflect.Value, error) {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		b, err := object.AsBool(obj)
+		if err != nil {
+			return protoreflect.Value{}, err
+		}
+		return protoreflect.ValueOfBool(b), nil
+	case protoreflect.StringKind:
+		s, err := object.AsString(obj)
+		if err != nil {
+			return protoreflect.Value{}, err
+		}
+		return protoreflect.ValueOfString(s), nil
+	case protoreflect.BytesKind:
+		data, err := object.AsBytes(obj)
+		if err != nil {
+			return protoreflect.Value{}, err
+		}
+		return protoreflect.ValueOfBytes(append([]byte(nil), data...)), nil
+	case protoreflect.FloatKind:
+		f, err := object.AsFloat(obj)
+		if err != nil {
+			return protoreflect.Value{}, err
+		}
+		return protoreflect.ValueOfFloat32(float32(f)), nil
+	case protoreflect.DoubleKind:
+		f, err := object.AsFloat(obj)
+		if err != nil {
+			return protoreflect.Value{}, err
+		}
+		return protoreflect.ValueOfFloat64(f), nil
+	case protoreflect.EnumKind:
+		if s, ok := obj.(*object.String); ok {
+			value := fd.Enum().Values().ByName(protoreflect.Name(s.Value()))
+			if value == nil {
+				return protoreflect.Value{}, object.ValueErrorf("%s has no value %q", fd.Enum().FullName(), s.Value())
+			}
+			return protoreflect.ValueOfEnum(value.Number()), nil
+		}
+		n, err := object.AsInt(obj)
+		if err != nil {
+			return protoreflect.Value{}, object.TypeErrorf("expected an enum name or number (%s given)", obj.Type())
+		}
+		if n < math.MinInt32 || n > math.MaxInt32 {
+			return protoreflect.Value{}, object.ValueErrorf("%d is out of range for an enum", n)
+		}
+		return protoreflect.ValueOfEnum(protoreflect.EnumNumber(n)), nil
+	}
+	n, err := object.AsInt(obj)
+	if err != nil {
+		return protoreflect.Value{}, err
+	}
+	switch fd.Kind() {
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		if n < math.MinInt32 || n > math.MaxInt32 {
+			return protoreflect.Value{}, object.ValueErrorf("%d is out of range for %s", n, fd.Kind())
+		}
+		return protoreflect.ValueOfInt32(int32(n)), nil
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		if n < 0 || n > math.MaxUint32 {
+			return protoreflect.Value{}, object.ValueErrorf("%d is out of range for %s", n, fd.Kind())
+		}
+		return protoreflect.ValueOfUint32(uint32(n)), nil
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		if n < 0 {
+			return protoreflect.Value{}, object.ValueErrorf("%d is out of range for %s", n, fd.Kind())
+		}
+		return protoreflect.ValueOfUint64(uint64(n)), nil
+	default:
+		return protoreflect.ValueOfInt64(n), nil
+	}
+}
+
+// fromMessage converts msg to a map with an entry for every field, keyed by
+// the field names from the .proto file. Unset fields that track presence,
+// such as message fields, oneof members, and optional fields, are null.
+// Other unset fields hold their default values.
+func fromMessage(msg protoreflect.Message) (*object.Map, error) {
+	fields := msg.Descriptor().Fields()
+	result := make(map[string]object.Object, fields.Len())
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		value, err := fromField(msg, fd)
+		if err != nil {
+			return nil, fieldError(fd, err)
+		}
+		result[string(fd.Name())] = value
+	}
+	return object.NewMap(result), nil
+}
+
+// fromField converts one field of msg to a Risor value.
+func fromField(msg protoreflect.Message, fd protoreflect.FieldDescriptor) (object.Object, error) {
+	if fd.HasPresence() && !msg.Has(fd) {
+		return object.Nil, nil
+	}
+	value := msg.Get(fd)
+	switch {
+	case fd.IsList():
+		list := value.List()
+		items := make([]object.Object, list.Len())
+		for i := range items {
+			item, err := fromValue(fd, list.Get(i))
+			if err != nil {
+				return nil, err
+			}
+			items[i] = item
+		}
+		return object.NewList(items), nil
+	case fd.IsMap():
+		result := make(map[string]object.Object, value.Map().Len())
+		var err error
+		value.Map().Range(func(key protoreflect.MapKey, value protoreflect.Value) bool {
+			var item object.Object
+			item, err = fromValue(fd.MapValue(), value)
+			result[key.String()] = item
+			return err == nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return object.NewMap(result), nil
+	}
+	return fromValue(fd, value)
+}
+
+// fromValue converts a single protobuf value of the kind of fd.
+func fromValue(fd protoreflect.FieldDescriptor, value protoreflect.Value) (object.Object, error) {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		return object.NewBool(value.Bool()), nil
+	case protoreflect.StringKind:
+		return object.NewString(value.String()), nil
+	case protoreflect.BytesKind:
+		return object.NewBytes(append([]byte(nil), value.Bytes()...)), nil
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		return object.NewFloat(value.Float()), nil
+	case protoreflect.EnumKind:
+		if v := fd.Enum().Values().ByNumber(value.Enum()); v != nil {
+			return object.NewString(string(v.Name())), nil
+		}
+		return object.NewInt(int64(value.Enum())), nil
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return fromMessage(value.Message())
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind,
+		protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		n := value.Uint()
+		if n > math.MaxInt64 {
+			return nil, object.ValueErrorf("%d is too large for an int", n)
+		}
+		return object.NewInt(int64(n)), nil
+	default:
+		return object.NewInt(value.Int()), nil
+	}
+}
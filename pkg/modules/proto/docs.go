@@ -0,0 +1,24 @@
+package proto
+
+import "github.com/deepnoodle-ai/risor/v2/pkg/object"
+
+func init() {
+	object.RegisterModule("proto", ModuleDoc(), Docs)
+}
+
+// Docs returns documentation for the proto module.
+func Docs() []object.FuncSpec {
+	return protoDocs
+}
+
+// ModuleDoc returns the module-level documentation.
+func ModuleDoc() string {
+	return "Encode and decode protobuf messages"
+}
+
+var protoDocs = []object.FuncSpec{
+	{Name: "encode", Doc: "Encode a map as a message in protobuf wire format", Args: []string{"message", "value"}, Returns: "bytes"},
+	{Name: "decode", Doc: "Decode a message in protobuf wire format to a map", Args: []string{"message", "data"}, Returns: "map"},
+	{Name: "messages", Doc: "Sorted full names of the known message types", Args: []string{}, Returns: "list"},
+	{Name: "load", Doc: "Load a schema from a serialized FileDescriptorSet", Args: []string{"descriptor_set"}, Returns: "proto_schema"},
+}
@@ -0,0 +1,16 @@
+module github.com/deepnoodle-ai/risor/v2/pkg/modules/proto
+
+go 1.25
+
+replace github.com/deepnoodle-ai/risor/v2 => ../../..
+
+require (
+	github.com/deepnoodle-ai/risor/v2 v2.0.0
+	github.com/deepnoodle-ai/wonton v0.0.25
+	google.golang.org/protobuf v1.36.11
+)
+
+require (
+	github.com/google/go-cmp v0.7.0 // indirect
+	golang.org/x/sys v0.39.0 // indirect
+)
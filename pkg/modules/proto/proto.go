@@ -0,0 +1,67 @@
+package proto
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// Option configures the proto module.
+type Option func(*config)
+
+type config struct {
+	files *protoregistry.Files
+}
+
+// WithFiles sets the file descriptors the module-level encode and decode
+// functions use. By default they use protoregistry.GlobalFiles, which holds
+// the descriptors of every generated protobuf type linked into the program.
+func WithFiles(files *protoregistry.Files) Option {
+	return func(c *config) {
+		c.files = files
+	}
+}
+
+// Load parses a serialized FileDescriptorSet and returns a schema for the
+// messages it defines.
+func Load(ctx context.Context, args ...object.Object) (object.Object, error) {
+	if len(args) != 1 {
+		return nil, object.NewArgsError("proto.load", 1, len(args))
+	}
+	data, err := object.AsBytes(args[0])
+	if err != nil {
+		return nil, err
+	}
+	schema, err := LoadSchema(data)
+	if err != nil {
+		return nil, fmt.Errorf("proto.load: %w", err)
+	}
+	return schema, nil
+}
+
+// Module returns the proto module. Its encode, decode, and messages
+// functions use the descriptors given with WithFiles.
+func Module(opts ...Option) *object.Module {
+	c := &config{files: protoregistry.GlobalFiles}
+	for _, opt := range opts {
+		opt(c)
+	}
+	schema := NewSchema(c.files)
+	return object.NewBuiltinsModule("proto", map[string]object.Object{
+		"encode": object.NewBuiltin("encode", func(ctx context.Context, args ...object.Object) (object.Object, error) {
+			return schema.encode("proto.encode", args...)
+		}),
+		"decode": object.NewBuiltin("decode", func(ctx context.Context, args ...object.Object) (object.Object, error) {
+			return schema.decode("proto.decode", args...)
+		}),
+		"messages": object.NewBuiltin("messages", func(ctx context.Context, args ...object.Object) (object.Object, error) {
+			if len(args) != 0 {
+				return nil, object.NewArgsError("proto.messages", 0, len(args))
+			}
+			return object.NewStringList(schema.MessageNames()), nil
+		}),
+		"load": object.NewBuiltin("load", Load),
+	})
+}
@@ -0,0 +1,213 @@
+package proto
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+	"github.com/deepnoodle-ai/risor/v2/pkg/op"
+	goproto "google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+const SCHEMA object.Type = "proto_schema"
+
+var schemaMethods = object.NewMethodRegistry[*Schema]("proto_schema")
+
+func init() {
+	schemaMethods.Define("encode").
+		Doc("Encode a map as a message in protobuf wire format").
+		Args("message", "value").
+		Returns("bytes").
+		Impl(func(s *Schema, ctx context.Context, args ...object.Object) (object.Object, error) {
+			return s.encode("proto_schema.encode", args...)
+		})
+
+	schemaMethods.Define("decode").
+		Doc("Decode a message in protobuf wire format to a map").
+		Args("message", "data").
+		Returns("map").
+		Impl(func(s *Schema, ctx context.Context, args ...object.Object) (object.Object, error) {
+			return s.decode("proto_schema.decode", args...)
+		})
+
+	schemaMethods.Define("messages").
+		Doc("Sorted full names of the message types in the schema").
+		Returns("list").
+		Impl(func(s *Schema, ctx context.Context, args ...object.Object) (object.Object, error) {
+			return object.NewStringList(s.MessageNames()), nil
+		})
+}
+
+// Schema is a set of protobuf file descriptors that messages are encoded
+// and decoded with.
+type Schema struct {
+	files *protoregistry.Files
+}
+
+// NewSchema returns a schema for the messages defined in files.
+func NewSchema(files *protoregistry.Files) *Schema {
+	return &Schema{files: files}
+}
+
+// LoadSchema returns a schema for a serialized FileDescriptorSet, as written
+// by protoc --descriptor_set_out. The set must include the imports of every
+// file in it (protoc --include_imports).
+func LoadSchema(data []byte) (*Schema, error) {
+	var set descriptorpb.FileDescriptorSet
+	if err := goproto.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("invalid descriptor set: %w", err)
+	}
+	files, err := protodesc.NewFiles(&set)
+	if err != nil {
+		return nil, fmt.Errorf("invalid descriptor set: %w", err)
+	}
+	return NewSchema(files), nil
+}
+
+// FindMessage returns the descriptor of the message type with the given
+// full name, such as "orders.v1.Order".
+func (s *Schema) FindMessage(name string) (protoreflect.MessageDescriptor, error) {
+	name = strings.TrimPrefix(name, ".")
+	desc, err := s.files.FindDescriptorByName(protoreflect.FullName(name))
+	if err != nil {
+		return nil, object.ValueErrorf("unknown message type %q", name)
+	}
+	md, ok := desc.(protoreflect.MessageDescriptor)
+	if !ok {
+		return nil, object.ValueErrorf("%q is not a message type", name)
+	}
+	return md, nil
+}
+
+// MessageNames returns the sorted full names of all message types in the
+// schema, including nested ones.
+func (s *Schema) MessageNames() []string {
+	var names []string
+	var add func(messages protoreflect.MessageDescriptors)
+	add = func(messages protoreflect.MessageDescriptors) {
+		for i := 0; i < messages.Len(); i++ {
+			md := messages.Get(i)
+			if md.IsMapEntry() {
+				continue
+			}
+			names = append(names, string(md.FullName()))
+			add(md.Messages())
+		}
+	}
+	s.files.RangeFiles(func(fd protoreflect.FileDescriptor) bool {
+		add(fd.Messages())
+		return true
+	})
+	sort.Strings(names)
+	return names
+}
+
+// Encode converts a Risor map to a message of the named type and returns
+// its wire format encoding. Map fields are encoded in key order, so equal
+// values produce equal bytes.
+func (s *Schema) Encode(name string, value object.Object) ([]byte, error) {
+	md, err := s.FindMessage(name)
+	if err != nil {
+		return nil, err
+	}
+	msg := dynamicpb.NewMessage(md)
+	if err := toMessage(msg, value); err != nil {
+		return nil, err
+	}
+	return goproto.MarshalOptions{Deterministic: true}.Marshal(msg)
+}
+
+// Decode parses data as a message of the named type and converts it to a
+// Risor map.
+func (s *Schema) Decode(name string, data []byte) (*object.Map, error) {
+	md, err := s.FindMessage(name)
+	if err != nil {
+		return nil, err
+	}
+	msg := dynamicpb.NewMessage(md)
+	if err := goproto.Unmarshal(data, msg); err != nil {
+		return nil, err
+	}
+	return fromMessage(msg)
+}
+
+func (s *Schema) encode(fn string, args ...object.Object) (object.Object, error) {
+	if len(args) != 2 {
+		return nil, object.NewArgsError(fn, 2, len(args))
+	}
+	name, err := object.AsString(args[0])
+	if err != nil {
+		return nil, err
+	}
+	data, err := s.Encode(name, args[1])
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+	return object.NewBytes(data), nil
+}
+
+func (s *Schema) decode(fn string, args ...object.Object) (object.Object, error) {
+	if len(args) != 2 {
+		return nil, object.NewArgsError(fn, 2, len(args))
+	}
+	name, err := object.AsString(args[0])
+	if err != nil {
+		return nil, err
+	}
+	data, err := object.AsBytes(args[1])
+	if err != nil {
+		return nil, err
+	}
+	result, err := s.Decode(name, data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+	return result, nil
+}
+
+func (s *Schema) Type() object.Type {
+	return SCHEMA
+}
+
+func (s *Schema) Inspect() string {
+	return fmt.Sprintf("proto_schema(files=%d)", s.files.NumFiles())
+}
+
+func (s *Schema) String() string {
+	return s.Inspect()
+}
+
+func (s *Schema) Interface() any {
+	return s.files
+}
+
+func (s *Schema) Equals(other object.Object) bool {
+	return s == other
+}
+
+func (s *Schema) Attrs() []object.AttrSpec {
+	return schemaMethods.Specs()
+}
+
+func (s *Schema) GetAttr(name string) (object.Object, bool) {
+	return schemaMethods.GetAttr(s, name)
+}
+
+func (s *Schema) SetAttr(name string, value object.Object) error {
+	return object.TypeErrorf("proto_schema has no attribute %q", name)
+}
+
+func (s *Schema) IsTruthy() bool {
+	return true
+}
+
+func (s *Schema) RunOperation(opType op.BinaryOpType, right object.Object) (object.Object, error) {
+	return nil, object.TypeErrorf("unsupported operation for proto_schema: %v", opType)
+}
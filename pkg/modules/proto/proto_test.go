@@ -0,0 +1,211 @@
+package proto
+
+import (
+	"context"
+	"testing"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+	"github.com/deepnoodle-ai/wonton/assert"
+	goproto "google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func field(name string, number int32, typ descriptorpb.FieldDescriptorProto_Type, label descriptorpb.FieldDescriptorProto_Label, typeName string) *descriptorpb.FieldDescriptorProto {
+	f := &descriptorpb.FieldDescriptorProto{
+		Name:     goproto.String(name),
+		JsonName: goproto.String(name),
+		Number:   goproto.Int32(number),
+		Type:     typ.Enum(),
+		Label:    label.Enum(),
+	}
+	if typeName != "" {
+		f.TypeName = goproto.String(typeName)
+	}
+	return f
+}
+
+// testDescriptorSet returns the serialized descriptors of this file:
+//
+//	syntax = "proto3";
+//	package orders.v1;
+//	enum Status { STATUS_UNKNOWN = 0; STATUS_PAID = 1; }
+//	message Item { string sku = 1; uint32 quantity = 2; }
+//	message Order {
+//	  int64 id = 1;
+//	  Status status = 2;
+//	  repeated Item items = 3;
+//	  map<string, string> labels = 4;
+//	  bytes payload = 5;
+//	  double total = 6;
+//	  Item gift = 7;
+//	  repeated int32 codes = 8;
+//	}
+func testDescriptorSet(t *testing.T) []byte {
+	t.Helper()
+	const (
+		optional = descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL
+		repeated = descriptorpb.FieldDescriptorProto_LABEL_REPEATED
+	)
+	file := &descriptorpb.FileDescriptorProto{
+		Name:    goproto.String("orders/v1/order.proto"),
+		Package: goproto.String("orders.v1"),
+		Syntax:  goproto.String("proto3"),
+		EnumType: []*descriptorpb.EnumDescriptorProto{{
+			Name: goproto.String("Status"),
+			Value: []*descriptorpb.EnumValueDescriptorProto{
+				{Name: goproto.String("STATUS_UNKNOWN"), Number: goproto.Int32(0)},
+				{Name: goproto.String("STATUS_PAID"), Number: goproto.Int32(1)},
+			},
+		}},
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: goproto.String("Item"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("sku", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, optional, ""),
+					field("quantity", 2, descriptorpb.FieldDescriptorProto_TYPE_UINT32, optional, ""),
+				},
+			},
+			{
+				Name: goproto.String("Order"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("id", 1, descriptorpb.FieldDescriptorProto_TYPE_INT64, optional, ""),
+					field("status", 2, descriptorpb.FieldDescriptorProto_TYPE_ENUM, optional, ".orders.v1.Status"),
+					field("items", 3, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, repeated, ".orders.v1.Item"),
+					field("labels", 4, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, repeated, ".orders.v1.Order.LabelsEntry"),
+					field("payload", 5, descriptorpb.FieldDescriptorProto_TYPE_BYTES, optional, ""),
+					field("total", 6, descriptorpb.FieldDescriptorProto_TYPE_DOUBLE, optional, ""),
+					field("gift", 7, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, optional, ".orders.v1.Item"),
+					field("codes", 8, descriptorpb.FieldDescriptorProto_TYPE_INT32, repeated, ""),
+				},
+				NestedType: []*descriptorpb.DescriptorProto{{
+					Name: goproto.String("LabelsEntry"),
+					Field: []*descriptorpb.FieldDescriptorProto{
+						field("key", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, optional, ""),
+						field("value", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING, optional, ""),
+					},
+					Options: &descriptorpb.MessageOptions{MapEntry: goproto.Bool(true)},
+				}},
+			},
+		},
+	}
+	data, err := goproto.Marshal(&descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{file}})
+	assert.Nil(t, err)
+	return data
+}
+
+func loadSchema(t *testing.T) *Schema {
+	t.Helper()
+	result, err := Load(context.Background(), object.NewBytes(testDescriptorSet(t)))
+	assert.Nil(t, err)
+	return result.(*Schema)
+}
+
+func TestMessages(t *testing.T) {
+	schema := loadSchema(t)
+	assert.Equal(t, schema.MessageNames(), []string{"orders.v1.Item", "orders.v1.Order"})
+}
+
+func TestEncodeWireFormat(t *testing.T) {
+	schema := loadSchema(t)
+	data, err := schema.Encode("orders.v1.Item", object.NewMap(map[string]object.Object{
+		"sku":      object.NewString("A"),
+		"quantity": object.NewInt(150),
+	}))
+	assert.Nil(t, err)
+	assert.Equal(t, data, []byte{0x0a, 0x01, 'A', 0x10, 0x96, 0x01})
+}
+
+func TestRoundTrip(t *testing.T) {
+	schema := loadSchema(t)
+	order := object.NewMap(map[string]object.Object{
+		"id":     object.NewInt(-42),
+		"status": object.NewString("STATUS_PAID"),
+		"items": object.NewList([]object.Object{
+			object.NewMap(map[string]object.Object{"sku": object.NewString("abc"), "quantity": object.NewInt(2)}),
+		}),
+		"labels":  object.NewMap(map[string]object.Object{"region": object.NewString("eu")}),
+		"payload": object.NewBytes([]byte{0, 1, 2}),
+		"total":   object.NewFloat(9.5),
+		"codes":   object.NewList([]object.Object{object.NewInt(1), object.NewInt(-1)}),
+	})
+	data, err := schema.Encode(".orders.v1.Order", order)
+	assert.Nil(t, err)
+	result, err := schema.Decode("orders.v1.Order", data)
+	assert.Nil(t, err)
+	assert.Equal(t, result.Get("id"), object.NewInt(-42))
+	assert.Equal(t, result.Get("status"), object.NewString("STATUS_PAID"))
+	assert.Equal(t, result.Get("items").Inspect(), `[{"quantity": 2, "sku": "abc"}]`)
+	assert.Equal(t, result.Get("labels").Inspect(), `{"region": "eu"}`)
+	assert.Equal(t, result.Get("payload"), object.NewBytes([]byte{0, 1, 2}))
+	assert.Equal(t, result.Get("total"), object.NewFloat(9.5))
+	assert.Equal(t, result.Get("gift"), object.Nil)
+	assert.Equal(t, result.Get("codes").Inspect(), "[1, -1]")
+}
+
+func TestDecodeDefaults(t *testing.T) {
+	schema := loadSchema(t)
+	result, err := schema.Decode("orders.v1.Order", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, result.Get("id"), object.NewInt(0))
+	assert.Equal(t, result.Get("status"), object.NewString("STATUS_UNKNOWN"))
+	assert.Equal(t, result.Get("items").Inspect(), "[]")
+	assert.Equal(t, result.Get("labels").Inspect(), "{}")
+	assert.Equal(t, result.Get("gift"), object.Nil)
+}
+
+func TestEncodeErrors(t *testing.T) {
+	schema := loadSchema(t)
+	tests := []struct {
+		message  string
+		value    map[string]object.Object
+		contains string
+	}{
+		{"orders.v1.Missing", nil, "unknown message type"},
+		{"orders.v1.Status", nil, "is not a message type"},
+		{"orders.v1.Item", map[string]object.Object{"color": object.NewString("red")}, `has no field "color"`},
+		{"orders.v1.Item", map[string]object.Object{"quantity": object.NewInt(-1)}, "out of range"},
+		{"orders.v1.Item", map[string]object.Object{"sku": object.NewInt(1)}, "orders.v1.Item.sku"},
+		{"orders.v1.Order", map[string]object.Object{"status": object.NewString("DONE")}, `has no value "DONE"`},
+		{"orders.v1.Order", map[string]object.Object{"items": object.NewString("x")}, "expected a list"},
+	}
+	for _, tc := range tests {
+		_, err := schema.Encode(tc.message, object.NewMap(tc.value))
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), tc.contains)
+	}
+
+	// Keys that are not strings are rejected rather than skipped
+	item := object.NewMap(nil)
+	item.SetItem(object.NewInt(1), object.NewString("abc"))
+	_, err := schema.Encode("orders.v1.Item", item)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "map keys must be strings")
+
+	labels := object.NewMap(nil)
+	labels.SetItem(object.NewInt(1), object.NewString("eu"))
+	_, err = schema.Encode("orders.v1.Order", object.NewMap(map[string]object.Object{"labels": labels}))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "orders.v1.Order.labels")
+}
+
+func TestModule(t *testing.T) {
+	schema := loadSchema(t)
+	mod := Module(WithFiles(schema.files))
+	encode, _ := mod.GetAttr("encode")
+	data, err := encode.(object.Callable).Call(context.Background(), object.NewString("orders.v1.Item"),
+		object.NewMap(map[string]object.Object{"sku": object.NewString("A")}))
+	assert.Nil(t, err)
+	decode, _ := mod.GetAttr("decode")
+	result, err := decode.(object.Callable).Call(context.Background(), object.NewString("orders.v1.Item"), data)
+	assert.Nil(t, err)
+	assert.Equal(t, result.Inspect(), `{"quantity": 0, "sku": "A"}`)
+
+	_, err = decode.(object.Callable).Call(context.Background(), object.NewString("orders.v1.Item"),
+		object.NewBytes([]byte{0xff}))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "proto.decode")
+
+	_, err = Load(context.Background(), object.NewBytes([]byte{0xff}))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid descriptor set")
+}
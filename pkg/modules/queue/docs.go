@@ -0,0 +1,22 @@
+package queue
+
+import "github.com/deepnoodle-ai/risor/v2/pkg/object"
+
+func init() {
+	object.RegisterModule("queue", ModuleDoc(), Docs)
+}
+
+// Docs returns documentation for the queue module.
+func Docs() []object.FuncSpec {
+	return queueDocs
+}
+
+// ModuleDoc returns the module-level documentation.
+func ModuleDoc() string {
+	return "Publish and consume messages through a host-provided broker"
+}
+
+var queueDocs = []object.FuncSpec{
+	{Name: "publish", Doc: "Publish a message to a topic", Args: []string{"topic", "value", "options?"}, Returns: "null"},
+	{Name: "subscribe", Doc: "Call a function for each message on a topic", Args: []string{"topic", "fn", "options?"}, Returns: "int"},
+}
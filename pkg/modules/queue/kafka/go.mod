@@ -0,0 +1,18 @@
+module github.com/deepnoodle-ai/risor/v2/pkg/modules/queue/kafka
+
+go 1.25
+
+replace github.com/deepnoodle-ai/risor/v2 => ../../../..
+
+require (
+	github.com/deepnoodle-ai/risor/v2 v2.0.0
+	github.com/deepnoodle-ai/wonton v0.0.25
+	github.com/segmentio/kafka-go v0.4.50
+)
+
+require (
+	github.com/google/go-cmp v0.7.0 // indirect
+	github.com/klauspost/compress v1.15.9 // indirect
+	github.com/pierrec/lz4/v4 v4.1.15 // indirect
+	golang.org/x/sys v0.39.0 // indirect
+)
@@ -0,0 +1,106 @@
+// Package kafka provides a queue.Driver backed by Kafka.
+//
+// Subscriptions join a consumer group, which is required: members of a
+// group share the partitions of a topic, and the group's committed offsets
+// record which messages have been processed. Ack commits a message's
+// offset. Kafka has no per-message rejection, so Nack leaves the offset
+// uncommitted, and the message is delivered again only if the group
+// restarts before a later message in the same partition is acknowledged.
+package kafka
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/modules/queue"
+	kafkago "github.com/segmentio/kafka-go"
+)
+
+// Driver publishes to and consumes from a Kafka cluster.
+type Driver struct {
+	brokers []string
+	writer  *kafkago.Writer
+}
+
+// New returns a driver for the cluster with the given broker addresses.
+// Messages with the same key are published to the same partition.
+func New(brokers ...string) *Driver {
+	return &Driver{
+		brokers: brokers,
+		writer: &kafkago.Writer{
+			Addr:     kafkago.TCP(brokers...),
+			Balancer: &kafkago.Hash{},
+		},
+	}
+}
+
+// Publish writes a message to its topic and waits until it is stored.
+func (d *Driver) Publish(ctx context.Context, msg queue.Message) error {
+	m := kafkago.Message{Topic: msg.Topic, Key: msg.Key, Value: msg.Value}
+	for k, v := range msg.Headers {
+		m.Headers = append(m.Headers, kafkago.Header{Key: k, Value: []byte(v)})
+	}
+	return d.writer.WriteMessages(ctx, m)
+}
+
+// Subscribe joins the consumer group given in opts and reads the topic.
+func (d *Driver) Subscribe(ctx context.Context, topic string, opts queue.SubscribeOptions) (queue.Subscription, error) {
+	if opts.Group == "" {
+		return nil, errors.New("kafka: subscribing requires a consumer group")
+	}
+	reader := kafkago.NewReader(kafkago.ReaderConfig{
+		Brokers: d.brokers,
+		GroupID: opts.Group,
+		Topic:   topic,
+	})
+	return &subscription{reader: reader}, nil
+}
+
+// Close flushes pending writes and releases the driver's connections.
+func (d *Driver) Close() error {
+	return d.writer.Close()
+}
+
+type subscription struct {
+	reader *kafkago.Reader
+}
+
+func (s *subscription) Next(ctx context.Context) (queue.Delivery, error) {
+	m, err := s.reader.FetchMessage(ctx)
+	if errors.Is(err, io.EOF) {
+		return nil, queue.ErrClosed
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &delivery{reader: s.reader, msg: m}, nil
+}
+
+func (s *subscription) Close() error {
+	return s.reader.Close()
+}
+
+type delivery struct {
+	reader *kafkago.Reader
+	msg    kafkago.Message
+}
+
+func (d *delivery) Message() queue.Message {
+	msg := queue.Message{Topic: d.msg.Topic, Key: d.msg.Key, Value: d.msg.Value}
+	if len(d.msg.Headers) > 0 {
+		msg.Headers = make(map[string]string, len(d.msg.Headers))
+		for _, h := range d.msg.Headers {
+			msg.Headers[h.Key] = string(h.Value)
+		}
+	}
+	return msg
+}
+
+func (d *delivery) Ack(ctx context.Context) error {
+	return d.reader.CommitMessages(ctx, d.msg)
+}
+
+func (d *delivery) Nack(ctx context.Context) error {
+	return nil
+}
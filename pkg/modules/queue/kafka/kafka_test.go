@@ -0,0 +1,33 @@
+package kafka
+
+import (
+	"context"
+	"testing"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/modules/queue"
+	"github.com/deepnoodle-ai/wonton/assert"
+	kafkago "github.com/segmentio/kafka-go"
+)
+
+var _ queue.Driver = (*Driver)(nil)
+
+func TestDeliveryMessage(t *testing.T) {
+	d := &delivery{msg: kafkago.Message{
+		Topic:   "orders",
+		Key:     []byte("customer-7"),
+		Value:   []byte("payload"),
+		Headers: []kafkago.Header{{Key: "source", Value: []byte("test")}},
+	}}
+	assert.Equal(t, d.Message(), queue.Message{
+		Topic:   "orders",
+		Key:     []byte("customer-7"),
+		Value:   []byte("payload"),
+		Headers: map[string]string{"source": "test"},
+	})
+}
+
+func TestSubscribeRequiresGroup(t *testing.T) {
+	_, err := New("localhost:9092").Subscribe(context.Background(), "orders", queue.SubscribeOptions{})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "consumer group")
+}
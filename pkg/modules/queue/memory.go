@@ -0,0 +1,157 @@
+package queue
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryDriver is an in-process Driver for tests and local development.
+// Each topic holds a single queue of messages, and every message goes to
+// one subscriber; consumer groups are ignored. Messages published before
+// anyone subscribes wait in the queue. Rejected messages return to the
+// front of the queue.
+type MemoryDriver struct {
+	mu     sync.Mutex
+	topics map[string][]Message
+	// ready is closed and replaced whenever a message is queued or the
+	// driver is closed, waking any waiting subscribers.
+	ready  chan struct{}
+	closed bool
+}
+
+// NewMemoryDriver returns an empty in-process driver.
+func NewMemoryDriver() *MemoryDriver {
+	return &MemoryDriver{
+		topics: map[string][]Message{},
+		ready:  make(chan struct{}),
+	}
+}
+
+// Publish adds a message to the end of its topic's queue.
+func (d *MemoryDriver) Publish(ctx context.Context, msg Message) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.closed {
+		return ErrClosed
+	}
+	d.topics[msg.Topic] = append(d.topics[msg.Topic], msg)
+	d.notify()
+	return nil
+}
+
+// Subscribe returns a subscription that takes messages from the topic's
+// queue.
+func (d *MemoryDriver) Subscribe(ctx context.Context, topic string, opts SubscribeOptions) (Subscription, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.closed {
+		return nil, ErrClosed
+	}
+	return &memorySubscription{driver: d, topic: topic}, nil
+}
+
+// Pending returns the number of messages waiting in a topic's queue.
+func (d *MemoryDriver) Pending(topic string) int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.topics[topic])
+}
+
+// Close ends all subscriptions. Messages still queued are discarded.
+func (d *MemoryDriver) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.closed {
+		d.closed = true
+		d.topics = nil
+		d.notify()
+	}
+	return nil
+}
+
+// notify wakes waiting subscribers. The caller must hold d.mu.
+func (d *MemoryDriver) notify() {
+	close(d.ready)
+	d.ready = make(chan struct{})
+}
+
+// take removes the first message of a topic, or returns a channel that is
+// closed when there may be one to take.
+func (d *MemoryDriver) take(topic string) (Message, bool, <-chan struct{}, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.closed {
+		return Message{}, false, nil, ErrClosed
+	}
+	if queue := d.topics[topic]; len(queue) > 0 {
+		d.topics[topic] = queue[1:]
+		return queue[0], true, nil, nil
+	}
+	return Message{}, false, d.ready, nil
+}
+
+// requeue returns a rejected message to the front of its topic's queue.
+func (d *MemoryDriver) requeue(msg Message) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.closed {
+		return
+	}
+	d.topics[msg.Topic] = append([]Message{msg}, d.topics[msg.Topic]...)
+	d.notify()
+}
+
+type memorySubscription struct {
+	driver *MemoryDriver
+	topic  string
+	mu     sync.Mutex
+	closed bool
+}
+
+func (s *memorySubscription) Next(ctx context.Context) (Delivery, error) {
+	for {
+		s.mu.Lock()
+		closed := s.closed
+		s.mu.Unlock()
+		if closed {
+			return nil, ErrClosed
+		}
+		msg, ok, ready, err := s.driver.take(s.topic)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return &memoryDelivery{driver: s.driver, msg: msg}, nil
+		}
+		select {
+		case <-ready:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func (s *memorySubscription) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	return nil
+}
+
+type memoryDelivery struct {
+	driver *MemoryDriver
+	msg    Message
+}
+
+func (d *memoryDelivery) Message() Message {
+	return d.msg
+}
+
+func (d *memoryDelivery) Ack(ctx context.Context) error {
+	return nil
+}
+
+func (d *memoryDelivery) Nack(ctx context.Context) error {
+	d.driver.requeue(d.msg)
+	return nil
+}
@@ -0,0 +1,20 @@
+module github.com/deepnoodle-ai/risor/v2/pkg/modules/queue/nats
+
+go 1.25
+
+replace github.com/deepnoodle-ai/risor/v2 => ../../../..
+
+require (
+	github.com/deepnoodle-ai/risor/v2 v2.0.0
+	github.com/deepnoodle-ai/wonton v0.0.25
+	github.com/nats-io/nats.go v1.48.0
+)
+
+require (
+	github.com/google/go-cmp v0.7.0 // indirect
+	github.com/klauspost/compress v1.18.0 // indirect
+	github.com/nats-io/nkeys v0.4.11 // indirect
+	github.com/nats-io/nuid v1.0.1 // indirect
+	golang.org/x/crypto v0.37.0 // indirect
+	golang.org/x/sys v0.39.0 // indirect
+)
@@ -0,0 +1,122 @@
+// Package nats provides a queue.Driver backed by NATS.
+//
+// Topics are NATS subjects and may contain wildcards when subscribing.
+// Consumer groups are NATS queue groups; without a group, every subscriber
+// receives every message. Core NATS has no acknowledgements, so Ack and
+// Nack only take effect for messages delivered by a JetStream consumer.
+package nats
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/modules/queue"
+	natsgo "github.com/nats-io/nats.go"
+)
+
+// KeyHeader is the header that carries message keys, which NATS has no
+// native field for.
+const KeyHeader = "Risor-Queue-Key"
+
+// Driver publishes and subscribes over a NATS connection.
+type Driver struct {
+	conn *natsgo.Conn
+}
+
+// New returns a driver that uses conn. The caller owns the connection and
+// closes it when done.
+func New(conn *natsgo.Conn) *Driver {
+	return &Driver{conn: conn}
+}
+
+// Publish sends a message to the subject named by its topic.
+func (d *Driver) Publish(ctx context.Context, msg queue.Message) error {
+	m := natsgo.NewMsg(msg.Topic)
+	m.Data = msg.Value
+	for k, v := range msg.Headers {
+		m.Header.Set(k, v)
+	}
+	if msg.Key != nil {
+		m.Header.Set(KeyHeader, string(msg.Key))
+	}
+	return d.conn.PublishMsg(m)
+}
+
+// Subscribe subscribes to a subject, joining the queue group given in opts
+// if there is one.
+func (d *Driver) Subscribe(ctx context.Context, topic string, opts queue.SubscribeOptions) (queue.Subscription, error) {
+	var sub *natsgo.Subscription
+	var err error
+	if opts.Group != "" {
+		sub, err = d.conn.QueueSubscribeSync(topic, opts.Group)
+	} else {
+		sub, err = d.conn.SubscribeSync(topic)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &subscription{sub: sub}, nil
+}
+
+type subscription struct {
+	sub *natsgo.Subscription
+}
+
+func (s *subscription) Next(ctx context.Context) (queue.Delivery, error) {
+	m, err := s.sub.NextMsgWithContext(ctx)
+	if errors.Is(err, natsgo.ErrBadSubscription) || errors.Is(err, natsgo.ErrConnectionClosed) {
+		return nil, queue.ErrClosed
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &delivery{msg: m}, nil
+}
+
+func (s *subscription) Close() error {
+	err := s.sub.Unsubscribe()
+	if errors.Is(err, natsgo.ErrBadSubscription) || errors.Is(err, natsgo.ErrConnectionClosed) {
+		return nil
+	}
+	return err
+}
+
+type delivery struct {
+	msg *natsgo.Msg
+}
+
+func (d *delivery) Message() queue.Message {
+	msg := queue.Message{Topic: d.msg.Subject, Value: d.msg.Data}
+	if len(d.msg.Header) > 0 {
+		msg.Headers = make(map[string]string, len(d.msg.Header))
+		for k := range d.msg.Header {
+			if k == KeyHeader {
+				msg.Key = []byte(d.msg.Header.Get(k))
+				continue
+			}
+			msg.Headers[k] = d.msg.Header.Get(k)
+		}
+	}
+	return msg
+}
+
+// isJetStream reports whether the message came from a JetStream consumer,
+// whose reply subject receives acknowledgements.
+func (d *delivery) isJetStream() bool {
+	return strings.HasPrefix(d.msg.Reply, "$JS.ACK.")
+}
+
+func (d *delivery) Ack(ctx context.Context) error {
+	if !d.isJetStream() {
+		return nil
+	}
+	return d.msg.Ack(natsgo.Context(ctx))
+}
+
+func (d *delivery) Nack(ctx context.Context) error {
+	if !d.isJetStream() {
+		return nil
+	}
+	return d.msg.Nak(natsgo.Context(ctx))
+}
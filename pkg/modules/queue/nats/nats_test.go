@@ -0,0 +1,34 @@
+package nats
+
+import (
+	"context"
+	"testing"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/modules/queue"
+	"github.com/deepnoodle-ai/wonton/assert"
+	natsgo "github.com/nats-io/nats.go"
+)
+
+var _ queue.Driver = (*Driver)(nil)
+
+func TestDeliveryMessage(t *testing.T) {
+	m := natsgo.NewMsg("orders.created")
+	m.Data = []byte("payload")
+	m.Header.Set("Source", "test")
+	m.Header.Set(KeyHeader, "customer-7")
+	msg := (&delivery{msg: m}).Message()
+	assert.Equal(t, msg, queue.Message{
+		Topic:   "orders.created",
+		Key:     []byte("customer-7"),
+		Value:   []byte("payload"),
+		Headers: map[string]string{"Source": "test"},
+	})
+}
+
+func TestCoreMessagesIgnoreAcks(t *testing.T) {
+	d := &delivery{msg: &natsgo.Msg{Subject: "orders", Reply: "_INBOX.abc"}}
+	assert.False(t, d.isJetStream())
+	assert.Nil(t, d.Ack(context.Background()))
+	assert.Nil(t, d.Nack(context.Background()))
+	assert.True(t, (&delivery{msg: &natsgo.Msg{Reply: "$JS.ACK.orders.c.1.1.1.1.0"}}).isJetStream())
+}
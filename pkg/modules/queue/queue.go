@@ -0,0 +1,210 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+)
+
+// ErrClosed is returned by Subscription.Next when the subscription or its
+// driver has been closed and no more messages will arrive.
+var ErrClosed = errors.New("queue: closed")
+
+// Message is a message published to or received from a topic.
+type Message struct {
+	Topic   string
+	Key     []byte
+	Value   []byte
+	Headers map[string]string
+}
+
+// Delivery is a message received from a subscription, which the receiver
+// acknowledges once it has been processed or rejects so it is delivered
+// again.
+type Delivery interface {
+	Message() Message
+	Ack(ctx context.Context) error
+	Nack(ctx context.Context) error
+}
+
+// Subscription delivers the messages of one topic.
+type Subscription interface {
+	// Next blocks until a message arrives, the context is done, or the
+	// subscription ends, in which case it returns ErrClosed.
+	Next(ctx context.Context) (Delivery, error)
+	Close() error
+}
+
+// SubscribeOptions configures a subscription.
+type SubscribeOptions struct {
+	// Group names a consumer group. Subscribers in the same group share the
+	// topic's messages between them. Drivers define what an empty group
+	// means.
+	Group string
+}
+
+// Driver connects the queue module to a message broker. Drivers must be
+// safe for concurrent use, since VMs sharing a module may call them at the
+// same time.
+type Driver interface {
+	Publish(ctx context.Context, msg Message) error
+	Subscribe(ctx context.Context, topic string, opts SubscribeOptions) (Subscription, error)
+}
+
+func publish(driver Driver) object.BuiltinFunction {
+	return func(ctx context.Context, args ...object.Object) (object.Object, error) {
+		if len(args) < 2 || len(args) > 3 {
+			return nil, object.NewArgsRangeError("queue.publish", 2, 3, len(args))
+		}
+		topic, err := object.AsString(args[0])
+		if err != nil {
+			return nil, err
+		}
+		value, err := object.AsBytes(args[1])
+		if err != nil {
+			return nil, err
+		}
+		msg := Message{Topic: topic, Value: value}
+		if len(args) == 3 {
+			opts, err := object.AsMap(args[2])
+			if err != nil {
+				return nil, err
+			}
+			if err := opts.CheckStringKeys(); err != nil {
+				return nil, fmt.Errorf("queue.publish: %w", err)
+			}
+			for _, name := range opts.SortedKeys() {
+				switch name {
+				case "key":
+					if msg.Key, err = object.AsBytes(opts.Get(name)); err != nil {
+						return nil, err
+					}
+				case "headers":
+					headers, err := object.AsMap(opts.Get(name))
+					if err != nil {
+						return nil, err
+					}
+					msg.Headers = make(map[string]string, headers.Size())
+					if err := headers.CheckStringKeys(); err != nil {
+						return nil, fmt.Errorf("queue.publish: headers: %w", err)
+					}
+					for _, key := range headers.SortedKeys() {
+						if msg.Headers[key], err = object.AsString(headers.Get(key)); err != nil {
+							return nil, err
+						}
+					}
+				default:
+					return nil, object.ValueErrorf("queue.publish: unknown option %q", name)
+				}
+			}
+		}
+		if err := driver.Publish(ctx, msg); err != nil {
+			return nil, fmt.Errorf("queue.publish: %w", err)
+		}
+		return object.Nil, nil
+	}
+}
+
+func subscribe(driver Driver) object.BuiltinFunction {
+	return func(ctx context.Context, args ...object.Object) (object.Object, error) {
+		if len(args) < 2 || len(args) > 3 {
+			return nil, object.NewArgsRangeError("queue.subscribe", 2, 3, len(args))
+		}
+		topic, err := object.AsString(args[0])
+		if err != nil {
+			return nil, err
+		}
+		fn, ok := args[1].(object.Callable)
+		if !ok {
+			return nil, object.TypeErrorf("queue.subscribe: expected a function (%s given)", args[1].Type())
+		}
+		var opts SubscribeOptions
+		var limit int64
+		if len(args) == 3 {
+			m, err := object.AsMap(args[2])
+			if err != nil {
+				return nil, err
+			}
+			if err := m.CheckStringKeys(); err != nil {
+				return nil, fmt.Errorf("queue.subscribe: %w", err)
+			}
+			for _, name := range m.SortedKeys() {
+				switch name {
+				case "group":
+					if opts.Group, err = object.AsString(m.Get(name)); err != nil {
+						return nil, err
+					}
+				case "limit":
+					if limit, err = object.AsInt(m.Get(name)); err != nil {
+						return nil, err
+					}
+					if limit < 1 {
+						return nil, object.ValueErrorf("queue.subscribe: limit must be positive (got %d)", limit)
+					}
+				default:
+					return nil, object.ValueErrorf("queue.subscribe: unknown option %q", name)
+				}
+			}
+		}
+		sub, err := driver.Subscribe(ctx, topic, opts)
+		if err != nil {
+			return nil, fmt.Errorf("queue.subscribe: %w", err)
+		}
+		defer sub.Close()
+		var count int64
+		for limit == 0 || count < limit {
+			delivery, err := sub.Next(ctx)
+			if errors.Is(err, ErrClosed) {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			count++
+			stop, err := handle(ctx, fn, delivery)
+			if err != nil {
+				return nil, err
+			}
+			if stop {
+				break
+			}
+		}
+		return object.NewInt(count), nil
+	}
+}
+
+// handle passes a delivery to the script callback. Messages the callback
+// did not settle itself are acknowledged if it returns normally and
+// rejected if it raises an error. The callback returning false stops the
+// subscription.
+func handle(ctx context.Context, fn object.Callable, delivery Delivery) (bool, error) {
+	msg := newMessageObject(delivery)
+	result, err := fn.Call(ctx, msg)
+	if err != nil {
+		if !msg.settled {
+			msg.settled = true
+			if nackErr := delivery.Nack(ctx); nackErr != nil {
+				return false, errors.Join(err, fmt.Errorf("queue.subscribe: %w", nackErr))
+			}
+		}
+		return false, err
+	}
+	if !msg.settled {
+		msg.settled = true
+		if err := delivery.Ack(ctx); err != nil {
+			return false, fmt.Errorf("queue.subscribe: %w", err)
+		}
+	}
+	return result == object.False, nil
+}
+
+// Module returns the queue module, which publishes and subscribes through
+// the given driver.
+func Module(driver Driver) *object.Module {
+	return object.NewBuiltinsModule("queue", map[string]object.Object{
+		"publish":   object.NewBuiltin("publish", publish(driver)),
+		"subscribe": object.NewBuiltin("subscribe", subscribe(driver)),
+	})
+}
@@ -0,0 +1,133 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+	"github.com/deepnoodle-ai/risor/v2/pkg/op"
+)
+
+const MESSAGE object.Type = "queue_message"
+
+var messageMethods = object.NewMethodRegistry[*MessageObject]("queue_message")
+
+func init() {
+	messageMethods.Define("topic").
+		Doc("Topic the message was received from").
+		Returns("string").
+		Getter(func(m *MessageObject) object.Object {
+			return object.NewString(m.msg.Topic)
+		})
+
+	messageMethods.Define("key").
+		Doc("Message key, or null if it has none").
+		Returns("bytes").
+		Getter(func(m *MessageObject) object.Object {
+			if m.msg.Key == nil {
+				return object.Nil
+			}
+			return object.NewBytes(m.msg.Key)
+		})
+
+	messageMethods.Define("value").
+		Doc("Message payload").
+		Returns("bytes").
+		Getter(func(m *MessageObject) object.Object {
+			return object.NewBytes(m.msg.Value)
+		})
+
+	messageMethods.Define("headers").
+		Doc("Message headers").
+		Returns("map").
+		Getter(func(m *MessageObject) object.Object {
+			headers := make(map[string]object.Object, len(m.msg.Headers))
+			for k, v := range m.msg.Headers {
+				headers[k] = object.NewString(v)
+			}
+			return object.NewMap(headers)
+		})
+
+	messageMethods.Define("ack").
+		Doc("Acknowledge the message as processed").
+		Returns("null").
+		Impl(func(m *MessageObject, ctx context.Context, args ...object.Object) (object.Object, error) {
+			if err := m.settle(ctx, "ack", m.delivery.Ack); err != nil {
+				return nil, err
+			}
+			return object.Nil, nil
+		})
+
+	messageMethods.Define("nack").
+		Doc("Reject the message so it is delivered again").
+		Returns("null").
+		Impl(func(m *MessageObject, ctx context.Context, args ...object.Object) (object.Object, error) {
+			if err := m.settle(ctx, "nack", m.delivery.Nack); err != nil {
+				return nil, err
+			}
+			return object.Nil, nil
+		})
+}
+
+// MessageObject is a message received by a subscribe callback.
+type MessageObject struct {
+	delivery Delivery
+	msg      Message
+	settled  bool
+}
+
+func newMessageObject(delivery Delivery) *MessageObject {
+	return &MessageObject{delivery: delivery, msg: delivery.Message()}
+}
+
+// settle acknowledges or rejects the message, which can happen only once.
+func (m *MessageObject) settle(ctx context.Context, name string, fn func(context.Context) error) error {
+	if m.settled {
+		return fmt.Errorf("queue_message.%s: message was already acknowledged or rejected", name)
+	}
+	m.settled = true
+	if err := fn(ctx); err != nil {
+		return fmt.Errorf("queue_message.%s: %w", name, err)
+	}
+	return nil
+}
+
+func (m *MessageObject) Type() object.Type {
+	return MESSAGE
+}
+
+func (m *MessageObject) Inspect() string {
+	return fmt.Sprintf("queue_message(topic=%q, size=%d)", m.msg.Topic, len(m.msg.Value))
+}
+
+func (m *MessageObject) String() string {
+	return m.Inspect()
+}
+
+func (m *MessageObject) Interface() any {
+	return m.msg
+}
+
+func (m *MessageObject) Equals(other object.Object) bool {
+	return m == other
+}
+
+func (m *MessageObject) Attrs() []object.AttrSpec {
+	return messageMethods.Specs()
+}
+
+func (m *MessageObject) GetAttr(name string) (object.Object, bool) {
+	return messageMethods.GetAttr(m, name)
+}
+
+func (m *MessageObject) SetAttr(name string, value object.Object) error {
+	return object.TypeErrorf("queue_message has no attribute %q", name)
+}
+
+func (m *MessageObject) IsTruthy() bool {
+	return true
+}
+
+func (m *MessageObject) RunOperation(opType op.BinaryOpType, right object.Object) (object.Object, error) {
+	return nil, object.TypeErrorf("unsupported operation for queue_message: %v", opType)
+}
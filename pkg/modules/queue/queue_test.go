@@ -0,0 +1,158 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+func call(t *testing.T, mod *object.Module, name string, args ...object.Object) (object.Object, error) {
+	t.Helper()
+	fn, ok := mod.GetAttr(name)
+	assert.True(t, ok, name)
+	return fn.(object.Callable).Call(context.Background(), args...)
+}
+
+// collector returns a callback that records the messages it receives and
+// then returns result.
+func collector(received *[]*MessageObject, result object.Object) *object.Builtin {
+	return object.NewBuiltin("collect", func(ctx context.Context, args ...object.Object) (object.Object, error) {
+		*received = append(*received, args[0].(*MessageObject))
+		return result, nil
+	})
+}
+
+func TestPublishSubscribe(t *testing.T) {
+	driver := NewMemoryDriver()
+	mod := Module(driver)
+	_, err := call(t, mod, "publish", object.NewString("orders"), object.NewString(`{"id": 1}`),
+		object.NewMap(map[string]object.Object{
+			"key":     object.NewString("customer-7"),
+			"headers": object.NewMap(map[string]object.Object{"source": object.NewString("test")}),
+		}))
+	assert.Nil(t, err)
+	_, err = call(t, mod, "publish", object.NewString("orders"), object.NewBytes([]byte("two")))
+	assert.Nil(t, err)
+	assert.Equal(t, driver.Pending("orders"), 2)
+
+	var received []*MessageObject
+	count, err := call(t, mod, "subscribe", object.NewString("orders"), collector(&received, object.Nil),
+		object.NewMap(map[string]object.Object{"limit": object.NewInt(2)}))
+	assert.Nil(t, err)
+	assert.Equal(t, count, object.NewInt(2))
+	assert.Equal(t, driver.Pending("orders"), 0)
+	assert.Len(t, received, 2)
+
+	first := received[0]
+	topic, _ := first.GetAttr("topic")
+	assert.Equal(t, topic, object.NewString("orders"))
+	key, _ := first.GetAttr("key")
+	assert.Equal(t, key, object.NewBytes([]byte("customer-7")))
+	value, _ := first.GetAttr("value")
+	assert.Equal(t, value, object.NewBytes([]byte(`{"id": 1}`)))
+	headers, _ := first.GetAttr("headers")
+	assert.Equal(t, headers.Inspect(), `{"source": "test"}`)
+	key, _ = received[1].GetAttr("key")
+	assert.Equal(t, key, object.Nil)
+	assert.True(t, first.settled)
+}
+
+func TestSubscribeStopsWhenCallbackReturnsFalse(t *testing.T) {
+	driver := NewMemoryDriver()
+	mod := Module(driver)
+	for _, v := range []string{"a", "b", "c"} {
+		_, err := call(t, mod, "publish", object.NewString("t"), object.NewString(v))
+		assert.Nil(t, err)
+	}
+	var received []*MessageObject
+	count, err := call(t, mod, "subscribe", object.NewString("t"), collector(&received, object.False))
+	assert.Nil(t, err)
+	assert.Equal(t, count, object.NewInt(1))
+	assert.Equal(t, driver.Pending("t"), 2)
+}
+
+func TestSubscribeErrorRequeues(t *testing.T) {
+	driver := NewMemoryDriver()
+	mod := Module(driver)
+	_, err := call(t, mod, "publish", object.NewString("t"), object.NewString("a"))
+	assert.Nil(t, err)
+	fail := object.NewBuiltin("fail", func(ctx context.Context, args ...object.Object) (object.Object, error) {
+		return nil, errors.New("boom")
+	})
+	_, err = call(t, mod, "subscribe", object.NewString("t"), fail)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "boom")
+	assert.Equal(t, driver.Pending("t"), 1)
+}
+
+func TestExplicitSettle(t *testing.T) {
+	driver := NewMemoryDriver()
+	mod := Module(driver)
+	_, err := call(t, mod, "publish", object.NewString("t"), object.NewString("a"))
+	assert.Nil(t, err)
+	nack := object.NewBuiltin("nack", func(ctx context.Context, args ...object.Object) (object.Object, error) {
+		fn, _ := args[0].(*MessageObject).GetAttr("nack")
+		if _, err := fn.(object.Callable).Call(ctx); err != nil {
+			return nil, err
+		}
+		_, err := fn.(object.Callable).Call(ctx)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "already acknowledged or rejected")
+		return object.Nil, nil
+	})
+	count, err := call(t, mod, "subscribe", object.NewString("t"), nack,
+		object.NewMap(map[string]object.Object{"limit": object.NewInt(1)}))
+	assert.Nil(t, err)
+	assert.Equal(t, count, object.NewInt(1))
+	assert.Equal(t, driver.Pending("t"), 1)
+}
+
+func TestSubscribeWaitsForMessages(t *testing.T) {
+	driver := NewMemoryDriver()
+	mod := Module(driver)
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		driver.Publish(context.Background(), Message{Topic: "t", Value: []byte("late")})
+	}()
+	var received []*MessageObject
+	count, err := call(t, mod, "subscribe", object.NewString("t"), collector(&received, object.Nil),
+		object.NewMap(map[string]object.Object{"limit": object.NewInt(1)}))
+	assert.Nil(t, err)
+	assert.Equal(t, count, object.NewInt(1))
+
+	// Closing the driver ends the subscription
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		driver.Close()
+	}()
+	count, err = call(t, mod, "subscribe", object.NewString("t"), collector(&received, object.Nil))
+	assert.Nil(t, err)
+	assert.Equal(t, count, object.NewInt(0))
+}
+
+func TestSubscribeCancelled(t *testing.T) {
+	mod := Module(NewMemoryDriver())
+	fn, _ := mod.GetAttr("subscribe")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	var received []*MessageObject
+	_, err := fn.(object.Callable).Call(ctx, object.NewString("t"), collector(&received, object.Nil))
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
+
+func TestOptionErrors(t *testing.T) {
+	mod := Module(NewMemoryDriver())
+	_, err := call(t, mod, "publish", object.NewString("t"), object.NewString("a"),
+		object.NewMap(map[string]object.Object{"partition": object.NewInt(1)}))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown option "partition"`)
+	_, err = call(t, mod, "subscribe", object.NewString("t"), object.NewString("not a function"))
+	assert.Error(t, err)
+	_, err = call(t, mod, "subscribe", object.NewString("t"), collector(nil, object.Nil),
+		object.NewMap(map[string]object.Object{"limit": object.NewInt(0)}))
+	assert.Error(t, err)
+}
@@ -2,6 +2,10 @@ package rand
 
 import "github.com/deepnoodle-ai/risor/v2/pkg/object"
 
+func init() {
+	object.RegisterModule("rand", ModuleDoc(), Docs)
+}
+
 // Docs returns documentation for the rand module.
 func Docs() []object.FuncSpec {
 	return randDocs
@@ -20,7 +24,9 @@ var randDocs = []object.FuncSpec{
 	{Name: "normal", Doc: "Random from normal distribution", Args: []string{"mu?", "sigma?"}, Returns: "float"},
 	{Name: "exponential", Doc: "Random from exponential distribution", Args: []string{"lambda?"}, Returns: "float"},
 	{Name: "choice", Doc: "Random element from list", Args: []string{"list"}, Returns: "any"},
+	{Name: "choices", Doc: "Random k elements with replacement, optionally weighted", Args: []string{"list", "k", "weights?"}, Returns: "list"},
 	{Name: "sample", Doc: "Random k elements from list", Args: []string{"list", "k"}, Returns: "list"},
 	{Name: "shuffle", Doc: "Shuffle list in place", Args: []string{"list"}, Returns: "list"},
 	{Name: "bytes", Doc: "Random bytes", Args: []string{"n"}, Returns: "list"},
+	{Name: "secure_bytes", Doc: "Cryptographically secure random bytes", Args: []string{"n"}, Returns: "bytes"},
 }
@@ -2,9 +2,10 @@ package rand
 
 import (
 	"context"
+	crand "crypto/rand"
 	"fmt"
 	"math"
-	"math/rand"
+	"sort"
 
 	"github.com/deepnoodle-ai/risor/v2/pkg/object"
 )
@@ -13,13 +14,28 @@ import (
 // As of Go 1.20, the global random source is automatically seeded.
 func Seed() {}
 
+// Option configures the rand module.
+type Option func(*config)
+
+type config struct {
+	seed    int64
+	hasSeed bool
+}
+
+// WithSeed gives the module its own generator seeded with seed, so a script
+// produces the same random values on every run. Without it the module draws
+// from the global generator, which is seeded randomly.
+func WithSeed(seed int64) Option {
+	return func(c *config) {
+		c.seed = seed
+		c.hasSeed = true
+	}
+}
+
 // Random returns a random float in [0.0, 1.0).
 // Equivalent to Python's random.random() or JavaScript's Math.random().
 func Random(ctx context.Context, args ...object.Object) (object.Object, error) {
-	if len(args) != 0 {
-		return nil, fmt.Errorf("rand.random: expected 0 arguments, got %d", len(args))
-	}
-	return object.NewFloat(rand.Float64()), nil
+	return global.random(ctx, args...)
 }
 
 // Int returns a random integer.
@@ -27,9 +43,100 @@ func Random(ctx context.Context, args ...object.Object) (object.Object, error) {
 // With one argument n: returns a random int in [0, n).
 // With two arguments min, max: returns a random int in [min, max).
 func Int(ctx context.Context, args ...object.Object) (object.Object, error) {
+	return global.integer(ctx, args...)
+}
+
+// Randint returns a random integer in [a, b] inclusive.
+// Matches Python's random.randint(a, b) behavior.
+func Randint(ctx context.Context, args ...object.Object) (object.Object, error) {
+	return global.randint(ctx, args...)
+}
+
+// Uniform returns a random float in [a, b].
+// Matches Python's random.uniform(a, b) behavior.
+func Uniform(ctx context.Context, args ...object.Object) (object.Object, error) {
+	return global.uniform(ctx, args...)
+}
+
+// Normal returns a random float from a normal (Gaussian) distribution.
+// With no arguments: mean=0, stddev=1 (standard normal).
+// With two arguments: mean=mu, stddev=sigma.
+func Normal(ctx context.Context, args ...object.Object) (object.Object, error) {
+	return global.normal(ctx, args...)
+}
+
+// Exponential returns a random float from an exponential distribution.
+// With no arguments: lambda=1.
+// With one argument: lambda (rate parameter).
+func Exponential(ctx context.Context, args ...object.Object) (object.Object, error) {
+	return global.exponential(ctx, args...)
+}
+
+// Choice returns a random element from a list.
+// Matches Python's random.choice(seq) behavior.
+func Choice(ctx context.Context, args ...object.Object) (object.Object, error) {
+	return global.choice(ctx, args...)
+}
+
+// Choices returns k elements chosen from a list with replacement. An
+// optional list of weights makes each element's chance proportional to its
+// weight. Like Python's random.choices(seq, weights, k=k).
+func Choices(ctx context.Context, args ...object.Object) (object.Object, error) {
+	return global.choices(ctx, args...)
+}
+
+// Sample returns k unique random elements from a list (without replacement).
+// Matches Python's random.sample(seq, k) behavior.
+func Sample(ctx context.Context, args ...object.Object) (object.Object, error) {
+	return global.sample(ctx, args...)
+}
+
+// Shuffle randomly reorders the elements of a list in place.
+func Shuffle(ctx context.Context, args ...object.Object) (object.Object, error) {
+	return global.shuffle(ctx, args...)
+}
+
+// Bytes returns a list of n random bytes (0-255).
+// Useful for generating random data.
+func Bytes(ctx context.Context, args ...object.Object) (object.Object, error) {
+	return global.bytes(ctx, args...)
+}
+
+// SecureBytes returns n bytes from the operating system's cryptographically
+// secure random number generator. Unlike the other functions it ignores the
+// module's seed.
+func SecureBytes(ctx context.Context, args ...object.Object) (object.Object, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("rand.secure_bytes: expected 1 argument, got %d", len(args))
+	}
+	n, err := object.AsInt(args[0])
+	if err != nil {
+		return nil, err
+	}
+	if n < 0 {
+		return nil, fmt.Errorf("rand.secure_bytes: n must be non-negative, got %d", n)
+	}
+	if n > math.MaxInt32 {
+		return nil, fmt.Errorf("rand.secure_bytes: n too large, got %d", n)
+	}
+	buf := make([]byte, n)
+	if _, err := crand.Read(buf); err != nil {
+		return nil, fmt.Errorf("rand.secure_bytes: %w", err)
+	}
+	return object.NewBytes(buf), nil
+}
+
+func (s *source) random(ctx context.Context, args ...object.Object) (object.Object, error) {
+	if len(args) != 0 {
+		return nil, fmt.Errorf("rand.random: expected 0 arguments, got %d", len(args))
+	}
+	return object.NewFloat(s.Float64()), nil
+}
+
+func (s *source) integer(ctx context.Context, args ...object.Object) (object.Object, error) {
 	switch len(args) {
 	case 0:
-		return object.NewInt(rand.Int63()), nil
+		return object.NewInt(s.Int63()), nil
 	case 1:
 		max, err := object.AsInt(args[0])
 		if err != nil {
@@ -38,7 +145,7 @@ func Int(ctx context.Context, args ...object.Object) (object.Object, error) {
 		if max <= 0 {
 			return nil, fmt.Errorf("rand.int: max must be positive, got %d", max)
 		}
-		return object.NewInt(rand.Int63n(max)), nil
+		return object.NewInt(s.Int63n(max)), nil
 	case 2:
 		min, err := object.AsInt(args[0])
 		if err != nil {
@@ -51,15 +158,13 @@ func Int(ctx context.Context, args ...object.Object) (object.Object, error) {
 		if max <= min {
 			return nil, fmt.Errorf("rand.int: max must be greater than min, got min=%d max=%d", min, max)
 		}
-		return object.NewInt(min + rand.Int63n(max-min)), nil
+		return object.NewInt(min + s.Int63n(max-min)), nil
 	default:
 		return nil, fmt.Errorf("rand.int: expected 0-2 arguments, got %d", len(args))
 	}
 }
 
-// Randint returns a random integer in [a, b] inclusive.
-// Matches Python's random.randint(a, b) behavior.
-func Randint(ctx context.Context, args ...object.Object) (object.Object, error) {
+func (s *source) randint(ctx context.Context, args ...object.Object) (object.Object, error) {
 	if len(args) != 2 {
 		return nil, fmt.Errorf("rand.randint: expected 2 arguments, got %d", len(args))
 	}
@@ -74,12 +179,10 @@ func Randint(ctx context.Context, args ...object.Object) (object.Object, error)
 	if b < a {
 		return nil, fmt.Errorf("rand.randint: b must be >= a, got a=%d b=%d", a, b)
 	}
-	return object.NewInt(a + rand.Int63n(b-a+1)), nil
+	return object.NewInt(a + s.Int63n(b-a+1)), nil
 }
 
-// Uniform returns a random float in [a, b].
-// Matches Python's random.uniform(a, b) behavior.
-func Uniform(ctx context.Context, args ...object.Object) (object.Object, error) {
+func (s *source) uniform(ctx context.Context, args ...object.Object) (object.Object, error) {
 	if len(args) != 2 {
 		return nil, fmt.Errorf("rand.uniform: expected 2 arguments, got %d", len(args))
 	}
@@ -91,13 +194,10 @@ func Uniform(ctx context.Context, args ...object.Object) (object.Object, error)
 	if err != nil {
 		return nil, err
 	}
-	return object.NewFloat(a + rand.Float64()*(b-a)), nil
+	return object.NewFloat(a + s.Float64()*(b-a)), nil
 }
 
-// Normal returns a random float from a normal (Gaussian) distribution.
-// With no arguments: mean=0, stddev=1 (standard normal).
-// With two arguments: mean=mu, stddev=sigma.
-func Normal(ctx context.Context, args ...object.Object) (object.Object, error) {
+func (s *source) normal(ctx context.Context, args ...object.Object) (object.Object, error) {
 	var mu, sigma float64 = 0, 1
 	switch len(args) {
 	case 0:
@@ -118,13 +218,10 @@ func Normal(ctx context.Context, args ...object.Object) (object.Object, error) {
 	default:
 		return nil, fmt.Errorf("rand.normal: expected 0 or 2 arguments, got %d", len(args))
 	}
-	return object.NewFloat(mu + sigma*rand.NormFloat64()), nil
+	return object.NewFloat(mu + sigma*s.NormFloat64()), nil
 }
 
-// Exponential returns a random float from an exponential distribution.
-// With no arguments: lambda=1.
-// With one argument: lambda (rate parameter).
-func Exponential(ctx context.Context, args ...object.Object) (object.Object, error) {
+func (s *source) exponential(ctx context.Context, args ...object.Object) (object.Object, error) {
 	var lambda float64 = 1
 	switch len(args) {
 	case 0:
@@ -142,12 +239,10 @@ func Exponential(ctx context.Context, args ...object.Object) (object.Object, err
 		return nil, fmt.Errorf("rand.exponential: expected 0 or 1 arguments, got %d", len(args))
 	}
 	// ExpFloat64 returns exponential with rate=1, scale by 1/lambda
-	return object.NewFloat(rand.ExpFloat64() / lambda), nil
+	return object.NewFloat(s.ExpFloat64() / lambda), nil
 }
 
-// Choice returns a random element from a list.
-// Matches Python's random.choice(seq) behavior.
-func Choice(ctx context.Context, args ...object.Object) (object.Object, error) {
+func (s *source) choice(ctx context.Context, args ...object.Object) (object.Object, error) {
 	if len(args) != 1 {
 		return nil, fmt.Errorf("rand.choice: expected 1 argument, got %d", len(args))
 	}
@@ -159,12 +254,75 @@ func Choice(ctx context.Context, args ...object.Object) (object.Object, error) {
 	if len(items) == 0 {
 		return nil, fmt.Errorf("rand.choice: cannot choose from empty list")
 	}
-	return items[rand.Intn(len(items))], nil
+	return items[s.Intn(len(items))], nil
 }
 
-// Sample returns k unique random elements from a list (without replacement).
-// Matches Python's random.sample(seq, k) behavior.
-func Sample(ctx context.Context, args ...object.Object) (object.Object, error) {
+func (s *source) choices(ctx context.Context, args ...object.Object) (object.Object, error) {
+	if len(args) < 2 || len(args) > 3 {
+		return nil, fmt.Errorf("rand.choices: expected 2 or 3 arguments, got %d", len(args))
+	}
+	ls, err := object.AsList(args[0])
+	if err != nil {
+		return nil, err
+	}
+	k, err := object.AsInt(args[1])
+	if err != nil {
+		return nil, err
+	}
+	if k < 0 {
+		return nil, fmt.Errorf("rand.choices: k must be non-negative, got %d", k)
+	}
+	if k > math.MaxInt32 {
+		return nil, fmt.Errorf("rand.choices: k too large, got %d", k)
+	}
+	items := ls.Value()
+	if len(items) == 0 {
+		return nil, fmt.Errorf("rand.choices: cannot choose from empty list")
+	}
+	result := make([]object.Object, k)
+	if len(args) == 2 {
+		for i := range result {
+			result[i] = items[s.Intn(len(items))]
+		}
+		return object.NewList(result), nil
+	}
+	weightList, err := object.AsList(args[2])
+	if err != nil {
+		return nil, err
+	}
+	weights := weightList.Value()
+	if len(weights) != len(items) {
+		return nil, fmt.Errorf("rand.choices: expected %d weights, got %d", len(items), len(weights))
+	}
+	// Running totals, so each draw is a binary search for a point in [0, total).
+	cumulative := make([]float64, len(weights))
+	var total float64
+	for i, w := range weights {
+		weight, err := object.AsFloat(w)
+		if err != nil {
+			return nil, err
+		}
+		if weight < 0 || math.IsNaN(weight) || math.IsInf(weight, 0) {
+			return nil, fmt.Errorf("rand.choices: weights must be finite and non-negative, got %v", weight)
+		}
+		total += weight
+		cumulative[i] = total
+	}
+	if total <= 0 {
+		return nil, fmt.Errorf("rand.choices: at least one weight must be positive")
+	}
+	for i := range result {
+		point := s.Float64() * total
+		j := sort.Search(len(cumulative), func(j int) bool { return cumulative[j] > point })
+		if j == len(cumulative) {
+			j = len(cumulative) - 1
+		}
+		result[i] = items[j]
+	}
+	return object.NewList(result), nil
+}
+
+func (s *source) sample(ctx context.Context, args ...object.Object) (object.Object, error) {
 	if len(args) != 2 {
 		return nil, fmt.Errorf("rand.sample: expected 2 arguments, got %d", len(args))
 	}
@@ -191,15 +349,14 @@ func Sample(ctx context.Context, args ...object.Object) (object.Object, error) {
 		indices[i] = i
 	}
 	for i := range k {
-		j := i + rand.Int63n(n-i)
+		j := i + s.Int63n(n-i)
 		indices[i], indices[j] = indices[j], indices[i]
 		result[i] = items[indices[i]]
 	}
 	return object.NewList(result), nil
 }
 
-// Shuffle randomly reorders the elements of a list in place.
-func Shuffle(ctx context.Context, args ...object.Object) (object.Object, error) {
+func (s *source) shuffle(ctx context.Context, args ...object.Object) (object.Object, error) {
 	if len(args) != 1 {
 		return nil, fmt.Errorf("rand.shuffle: expected 1 argument, got %d", len(args))
 	}
@@ -207,16 +364,19 @@ func Shuffle(ctx context.Context, args ...object.Object) (object.Object, error)
 	if err != nil {
 		return nil, err
 	}
-	items := ls.Value()
-	rand.Shuffle(len(items), func(i, j int) {
-		items[i], items[j] = items[j], items[i]
+	err = ls.Modify(func(items []object.Object) ([]object.Object, error) {
+		s.Shuffle(len(items), func(i, j int) {
+			items[i], items[j] = items[j], items[i]
+		})
+		return items, nil
 	})
+	if err != nil {
+		return nil, err
+	}
 	return ls, nil
 }
 
-// Bytes returns a list of n random bytes (0-255).
-// Useful for generating random data.
-func Bytes(ctx context.Context, args ...object.Object) (object.Object, error) {
+func (s *source) bytes(ctx context.Context, args ...object.Object) (object.Object, error) {
 	if len(args) != 1 {
 		return nil, fmt.Errorf("rand.bytes: expected 1 argument, got %d", len(args))
 	}
@@ -232,22 +392,34 @@ func Bytes(ctx context.Context, args ...object.Object) (object.Object, error) {
 	}
 	result := make([]object.Object, n)
 	for i := range n {
-		result[i] = object.NewInt(int64(rand.Intn(256)))
+		result[i] = object.NewInt(int64(s.Intn(256)))
 	}
 	return object.NewList(result), nil
 }
 
-func Module() *object.Module {
+// Module returns the rand module. Options may seed it for reproducible
+// results.
+func Module(opts ...Option) *object.Module {
+	c := &config{}
+	for _, opt := range opts {
+		opt(c)
+	}
+	s := global
+	if c.hasSeed {
+		s = newSource(c.seed)
+	}
 	return object.NewBuiltinsModule("rand", map[string]object.Object{
-		"random":      object.NewBuiltin("random", Random),
-		"int":         object.NewBuiltin("int", Int),
-		"randint":     object.NewBuiltin("randint", Randint),
-		"uniform":     object.NewBuiltin("uniform", Uniform),
-		"normal":      object.NewBuiltin("normal", Normal),
-		"exponential": object.NewBuiltin("exponential", Exponential),
-		"choice":      object.NewBuiltin("choice", Choice),
-		"sample":      object.NewBuiltin("sample", Sample),
-		"shuffle":     object.NewBuiltin("shuffle", Shuffle),
-		"bytes":       object.NewBuiltin("bytes", Bytes),
+		"random":       object.NewBuiltin("random", s.random),
+		"int":          object.NewBuiltin("int", s.integer),
+		"randint":      object.NewBuiltin("randint", s.randint),
+		"uniform":      object.NewBuiltin("uniform", s.uniform),
+		"normal":       object.NewBuiltin("normal", s.normal),
+		"exponential":  object.NewBuiltin("exponential", s.exponential),
+		"choice":       object.NewBuiltin("choice", s.choice),
+		"choices":      object.NewBuiltin("choices", s.choices),
+		"sample":       object.NewBuiltin("sample", s.sample),
+		"shuffle":      object.NewBuiltin("shuffle", s.shuffle),
+		"bytes":        object.NewBuiltin("bytes", s.bytes),
+		"secure_bytes": object.NewBuiltin("secure_bytes", SecureBytes),
 	})
 }
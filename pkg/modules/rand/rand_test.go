@@ -289,6 +289,58 @@ func TestChoiceErrors(t *testing.T) {
 	assert.NotNil(t, err)
 }
 
+func TestChoices(t *testing.T) {
+	ctx := context.Background()
+	list := object.NewList([]object.Object{
+		object.NewString("a"),
+		object.NewString("b"),
+		object.NewString("c"),
+	})
+
+	result, err := Choices(ctx, list, object.NewInt(50))
+	assert.Nil(t, err)
+	picks := result.(*object.List).Value()
+	assert.Len(t, picks, 50)
+	for _, pick := range picks {
+		s := pick.(*object.String).Value()
+		assert.True(t, s == "a" || s == "b" || s == "c")
+	}
+
+	// Zero-weight elements are never chosen
+	weights := object.NewList([]object.Object{
+		object.NewInt(0),
+		object.NewFloat(2.5),
+		object.NewInt(0),
+	})
+	result, err = Choices(ctx, list, object.NewInt(50), weights)
+	assert.Nil(t, err)
+	for _, pick := range result.(*object.List).Value() {
+		assert.Equal(t, pick, object.NewString("b"))
+	}
+}
+
+func TestChoicesErrors(t *testing.T) {
+	ctx := context.Background()
+	list := object.NewList([]object.Object{object.NewInt(1), object.NewInt(2)})
+
+	_, err := Choices(ctx, list)
+	assert.NotNil(t, err)
+
+	_, err = Choices(ctx, object.NewList([]object.Object{}), object.NewInt(1))
+	assert.NotNil(t, err)
+
+	_, err = Choices(ctx, list, object.NewInt(-1))
+	assert.NotNil(t, err)
+
+	// Weights must match the list and include a positive value
+	_, err = Choices(ctx, list, object.NewInt(1), object.NewList([]object.Object{object.NewInt(1)}))
+	assert.NotNil(t, err)
+	_, err = Choices(ctx, list, object.NewInt(1), object.NewList([]object.Object{object.NewInt(0), object.NewInt(0)}))
+	assert.NotNil(t, err)
+	_, err = Choices(ctx, list, object.NewInt(1), object.NewList([]object.Object{object.NewInt(-1), object.NewInt(2)}))
+	assert.NotNil(t, err)
+}
+
 func TestSample(t *testing.T) {
 	ctx := context.Background()
 
@@ -410,6 +462,22 @@ func TestShuffleEmpty(t *testing.T) {
 	assert.Len(t, resultList.Value(), 0)
 }
 
+func TestShuffleFrozen(t *testing.T) {
+	ctx := context.Background()
+
+	list := object.NewList([]object.Object{
+		object.NewInt(1),
+		object.NewInt(2),
+		object.NewInt(3),
+	})
+	assert.Nil(t, object.Freeze(list))
+
+	_, err := Shuffle(ctx, list)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "list is frozen")
+	assert.Equal(t, list.Inspect(), "[1, 2, 3]")
+}
+
 func TestShuffleErrors(t *testing.T) {
 	ctx := context.Background()
 
@@ -485,12 +553,52 @@ func TestModule(t *testing.T) {
 		"normal",
 		"exponential",
 		"choice",
+		"choices",
 		"sample",
 		"shuffle",
 		"bytes",
+		"secure_bytes",
 	}
 	for _, name := range functions {
 		_, ok := m.GetAttr(name)
 		assert.True(t, ok)
 	}
 }
+
+func TestSecureBytes(t *testing.T) {
+	ctx := context.Background()
+	result, err := SecureBytes(ctx, object.NewInt(16))
+	assert.Nil(t, err)
+	b, ok := result.(*object.Bytes)
+	assert.True(t, ok)
+	assert.Len(t, b.Value(), 16)
+
+	_, err = SecureBytes(ctx, object.NewInt(-1))
+	assert.NotNil(t, err)
+}
+
+func TestModuleWithSeed(t *testing.T) {
+	ctx := context.Background()
+	draw := func(m *object.Module) string {
+		var values []object.Object
+		for _, name := range []string{"random", "int", "normal", "exponential"} {
+			fn, ok := m.GetAttr(name)
+			assert.True(t, ok)
+			value, err := fn.(*object.Builtin).Call(ctx)
+			assert.Nil(t, err)
+			values = append(values, value)
+		}
+		shuffle, _ := m.GetAttr("shuffle")
+		list := object.NewList([]object.Object{object.NewInt(1), object.NewInt(2), object.NewInt(3), object.NewInt(4)})
+		_, err := shuffle.(*object.Builtin).Call(ctx, list)
+		assert.Nil(t, err)
+		return object.NewList(append(values, list)).Inspect()
+	}
+
+	first := draw(Module(WithSeed(42)))
+	second := draw(Module(WithSeed(42)))
+	assert.Equal(t, first, second)
+
+	other := draw(Module(WithSeed(7)))
+	assert.NotEqual(t, first, other)
+}
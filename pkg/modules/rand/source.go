@@ -0,0 +1,89 @@
+package rand
+
+import (
+	"math/rand"
+	"sync"
+)
+
+// source is the generator behind one rand module. A source without its own
+// generator draws from the global one, which is seeded randomly at startup.
+// A seeded source owns its generator, so two modules built with the same
+// seed produce the same sequence regardless of what other scripts do.
+type source struct {
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+// global backs the package-level functions and modules built without a seed.
+var global = &source{}
+
+func newSource(seed int64) *source {
+	return &source{rng: rand.New(rand.NewSource(seed))}
+}
+
+// The methods below mirror *rand.Rand. A *rand.Rand is not safe for
+// concurrent use, so a seeded source serializes access to it.
+
+func (s *source) Float64() float64 {
+	if s.rng == nil {
+		return rand.Float64()
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rng.Float64()
+}
+
+func (s *source) Int63() int64 {
+	if s.rng == nil {
+		return rand.Int63()
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rng.Int63()
+}
+
+func (s *source) Int63n(n int64) int64 {
+	if s.rng == nil {
+		return rand.Int63n(n)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rng.Int63n(n)
+}
+
+func (s *source) Intn(n int) int {
+	if s.rng == nil {
+		return rand.Intn(n)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rng.Intn(n)
+}
+
+func (s *source) NormFloat64() float64 {
+	if s.rng == nil {
+		return rand.NormFloat64()
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rng.NormFloat64()
+}
+
+func (s *source) ExpFloat64() float64 {
+	if s.rng == nil {
+		return rand.ExpFloat64()
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rng.ExpFloat64()
+}
+
+func (s *source) Shuffle(n int, swap func(i, j int)) {
+	if s.rng == nil {
+		rand.Shuffle(n, swap)
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rng.Shuffle(n, swap)
+}
@@ -0,0 +1,21 @@
+package rate
+
+import "github.com/deepnoodle-ai/risor/v2/pkg/object"
+
+func init() {
+	object.RegisterModule("rate", ModuleDoc(), Docs)
+}
+
+// Docs returns documentation for the rate module.
+func Docs() []object.FuncSpec {
+	return rateDocs
+}
+
+// ModuleDoc returns the module-level documentation.
+func ModuleDoc() string {
+	return "Limit how often a script does something"
+}
+
+var rateDocs = []object.FuncSpec{
+	{Name: "limiter", Doc: "Create a limiter allowing a number of events per second", Args: []string{"per_second", "options?"}, Returns: "limiter"},
+}
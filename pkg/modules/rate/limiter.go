@@ -0,0 +1,158 @@
+package rate
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+	"github.com/deepnoodle-ai/risor/v2/pkg/op"
+)
+
+const LIMITER object.Type = "limiter"
+
+var limiterMethods = object.NewMethodRegistry[*Limiter]("limiter")
+
+func init() {
+	limiterMethods.Define("acquire").
+		Doc("Wait until the rate allows another call").
+		Returns("null").
+		Impl(func(l *Limiter, ctx context.Context, args ...object.Object) (object.Object, error) {
+			if err := l.Acquire(ctx); err != nil {
+				return nil, err
+			}
+			return object.Nil, nil
+		})
+
+	limiterMethods.Define("try_acquire").
+		Doc("Take a turn if the rate allows one now, without waiting").
+		Returns("bool").
+		Impl(func(l *Limiter, ctx context.Context, args ...object.Object) (object.Object, error) {
+			return object.NewBool(l.TryAcquire()), nil
+		})
+
+	limiterMethods.Define("with").
+		Doc("Wait for a turn, then call a function and return its result").
+		Arg("fn").
+		Returns("object").
+		Impl(func(l *Limiter, ctx context.Context, args ...object.Object) (object.Object, error) {
+			fn, ok := args[0].(object.Callable)
+			if !ok {
+				return nil, object.TypeErrorf("limiter.with() expected a function (%s given)", args[0].Type())
+			}
+			if err := l.Acquire(ctx); err != nil {
+				return nil, err
+			}
+			return fn.Call(ctx)
+		})
+}
+
+// Limiter spaces out events to a steady rate, allowing a burst of events at
+// once after a quiet period. Turns are spread evenly over time, so a
+// limiter of 10 per second with a burst of 1 allows one event every 100ms.
+// It can be shared between goroutines, so parallel workers can use one to
+// stay under an API's rate limit together.
+type Limiter struct {
+	perSecond float64
+	interval  time.Duration
+	burst     int
+
+	mu sync.Mutex
+	// next is when the next turn is due if no burst were allowed. A turn
+	// may be taken up to burst-1 intervals before it.
+	next time.Time
+}
+
+// NewLimiter returns a limiter allowing perSecond events per second, with
+// bursts of up to burst events.
+func NewLimiter(perSecond float64, burst int) *Limiter {
+	return &Limiter{
+		perSecond: perSecond,
+		interval:  time.Duration(float64(time.Second) / perSecond),
+		burst:     burst,
+	}
+}
+
+func (l *Limiter) Type() object.Type {
+	return LIMITER
+}
+
+func (l *Limiter) Inspect() string {
+	return fmt.Sprintf("limiter(%s/s, burst=%d)", strconv.FormatFloat(l.perSecond, 'g', -1, 64), l.burst)
+}
+
+func (l *Limiter) String() string {
+	return l.Inspect()
+}
+
+func (l *Limiter) Interface() any {
+	return l
+}
+
+func (l *Limiter) Equals(other object.Object) bool {
+	return l == other
+}
+
+func (l *Limiter) Attrs() []object.AttrSpec {
+	return limiterMethods.Specs()
+}
+
+func (l *Limiter) GetAttr(name string) (object.Object, bool) {
+	return limiterMethods.GetAttr(l, name)
+}
+
+func (l *Limiter) SetAttr(name string, value object.Object) error {
+	return object.TypeErrorf("limiter has no attribute %q", name)
+}
+
+func (l *Limiter) IsTruthy() bool {
+	return true
+}
+
+func (l *Limiter) RunOperation(opType op.BinaryOpType, right object.Object) (object.Object, error) {
+	return nil, object.TypeErrorf("unsupported operation for limiter: %v", opType)
+}
+
+// reserve takes the next turn and returns how long the caller must wait
+// before using it. If wait is false and the turn is not available
+// immediately, it takes nothing and returns false.
+func (l *Limiter) reserve(now time.Time, wait bool) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	due := l.next
+	if due.Before(now) {
+		due = now
+	}
+	delay := max(due.Sub(now)-time.Duration(l.burst-1)*l.interval, 0)
+	if delay > 0 && !wait {
+		return 0, false
+	}
+	l.next = due.Add(l.interval)
+	return delay, true
+}
+
+// Acquire waits for the next turn, or until ctx is cancelled. A turn is
+// used up even if ctx is cancelled while waiting for it.
+func (l *Limiter) Acquire(ctx context.Context) error {
+	delay, _ := l.reserve(time.Now(), true)
+	if delay <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// TryAcquire takes a turn if one is available now and reports whether it
+// did.
+func (l *Limiter) TryAcquire() bool {
+	_, ok := l.reserve(time.Now(), false)
+	return ok
+}
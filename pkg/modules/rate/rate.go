@@ -0,0 +1,56 @@
+package rate
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+)
+
+// NewRateLimiter creates a limiter allowing a number of events per second.
+// The optional map accepts "burst", the number of events allowed at once
+// after a quiet period, which defaults to 1.
+func NewRateLimiter(ctx context.Context, args ...object.Object) (object.Object, error) {
+	if len(args) < 1 || len(args) > 2 {
+		return nil, object.NewArgsRangeError("rate.limiter", 1, 2, len(args))
+	}
+	perSecond, err := object.AsFloat(args[0])
+	if err != nil {
+		return nil, err
+	}
+	if perSecond <= 0 {
+		return nil, object.ValueErrorf("rate.limiter: rate must be positive (got %s)", args[0].Inspect())
+	}
+	burst := 1
+	if len(args) == 2 {
+		opts, ok := args[1].(*object.Map)
+		if !ok {
+			return nil, object.TypeErrorf("rate.limiter() expected a map of options (%s given)", args[1].Type())
+		}
+		if err := opts.CheckStringKeys(); err != nil {
+			return nil, fmt.Errorf("rate.limiter: %w", err)
+		}
+		for _, key := range opts.SortedKeys() {
+			switch key {
+			case "burst":
+				n, err := object.AsInt(opts.Get(key))
+				if err != nil {
+					return nil, fmt.Errorf("rate.limiter: burst: %w", err)
+				}
+				if n < 1 {
+					return nil, object.ValueErrorf("rate.limiter: burst must be at least 1 (got %d)", n)
+				}
+				burst = int(n)
+			default:
+				return nil, fmt.Errorf("rate.limiter: unknown option %q", key)
+			}
+		}
+	}
+	return NewLimiter(perSecond, burst), nil
+}
+
+func Module() *object.Module {
+	return object.NewBuiltinsModule("rate", map[string]object.Object{
+		"limiter": object.NewBuiltin("limiter", NewRateLimiter),
+	})
+}
@@ -0,0 +1,100 @@
+package rate
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/deepnoodle-ai/risor/v2/internal/moduletest"
+	"github.com/deepnoodle-ai/risor/v2/pkg/modules/parallel"
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+// eval runs source with the rate and parallel modules and the default
+// builtins.
+func eval(ctx context.Context, source string) (object.Object, error) {
+	return moduletest.Eval(ctx, source, map[string]any{"rate": Module(), "parallel": parallel.Module()})
+}
+
+func TestReserve(t *testing.T) {
+	l := NewLimiter(10, 1)
+	now := time.Now()
+	delay, ok := l.reserve(now, true)
+	assert.True(t, ok)
+	assert.Equal(t, delay, time.Duration(0))
+	delay, _ = l.reserve(now, true)
+	assert.Equal(t, delay, 100*time.Millisecond)
+	delay, _ = l.reserve(now, true)
+	assert.Equal(t, delay, 200*time.Millisecond)
+
+	// Turns not taken in a quiet period are not saved up beyond the burst
+	delay, _ = l.reserve(now.Add(time.Second), true)
+	assert.Equal(t, delay, time.Duration(0))
+	_, ok = l.reserve(now.Add(time.Second), false)
+	assert.False(t, ok)
+}
+
+func TestReserveBurst(t *testing.T) {
+	l := NewLimiter(10, 3)
+	now := time.Now()
+	for range 3 {
+		_, ok := l.reserve(now, false)
+		assert.True(t, ok)
+	}
+	_, ok := l.reserve(now, false)
+	assert.False(t, ok)
+	delay, _ := l.reserve(now, true)
+	assert.Equal(t, delay, 100*time.Millisecond)
+}
+
+func TestLimiter(t *testing.T) {
+	start := time.Now()
+	result, err := eval(context.Background(), `
+	let limiter = rate.limiter(100, {burst: 2})
+	let results = list(range(5)).map(i => limiter.with(() => i * 2))
+	[results, limiter.try_acquire(), string(limiter)]
+	`)
+	assert.Nil(t, err)
+	assert.Equal(t, result.Inspect(), `[[0, 2, 4, 6, 8], false, "limiter(100/s, burst=2)"]`)
+	// Two turns at once, then one every 10ms
+	assert.True(t, time.Since(start) >= 25*time.Millisecond)
+}
+
+func TestLimiterParallel(t *testing.T) {
+	start := time.Now()
+	result, err := eval(context.Background(), `
+	let limiter = rate.limiter(200)
+	parallel.map(list(range(10)), i => { limiter.acquire(); return i }, {workers: 5})
+	`)
+	assert.Nil(t, err)
+	assert.Equal(t, result.Inspect(), "[0, 1, 2, 3, 4, 5, 6, 7, 8, 9]")
+	// The workers share the limiter: ten turns at 5ms apart
+	assert.True(t, time.Since(start) >= 40*time.Millisecond)
+}
+
+func TestLimiterCancel(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := eval(ctx, `
+	let limiter = rate.limiter(0.1)
+	limiter.acquire()
+	limiter.acquire()
+	`)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
+
+func TestLimiterArgs(t *testing.T) {
+	ctx := context.Background()
+	_, err := NewRateLimiter(ctx, object.NewInt(0))
+	assert.Contains(t, err.Error(), "rate must be positive")
+	_, err = NewRateLimiter(ctx, object.NewInt(1), object.NewMap(map[string]object.Object{"burst": object.NewInt(0)}))
+	assert.Contains(t, err.Error(), "burst must be at least 1")
+	_, err = NewRateLimiter(ctx, object.NewInt(1), object.NewMap(map[string]object.Object{"size": object.NewInt(1)}))
+	assert.Contains(t, err.Error(), `unknown option "size"`)
+	obj, err := NewRateLimiter(ctx, object.NewFloat(0.5))
+	assert.Nil(t, err)
+	assert.Equal(t, obj.Type(), LIMITER)
+	assert.Equal(t, obj.Inspect(), "limiter(0.5/s, burst=1)")
+}
@@ -0,0 +1,40 @@
+package regexp
+
+import (
+	"regexp"
+	"sync"
+)
+
+// maxCached bounds the compiled pattern cache. Scripts typically use a
+// handful of fixed patterns; one that builds many patterns dynamically
+// empties the cache each time it fills rather than growing it without limit.
+const maxCached = 256
+
+var cache = struct {
+	sync.Mutex
+	patterns map[string]*regexp.Regexp
+}{patterns: map[string]*regexp.Regexp{}}
+
+// compile returns the compiled form of pattern, reusing an earlier
+// compilation when there is one, so the module functions can be called in a
+// loop without recompiling. A *regexp.Regexp is safe for concurrent use, so
+// one compilation is shared by every caller.
+func compile(pattern string) (*regexp.Regexp, error) {
+	cache.Lock()
+	r, ok := cache.patterns[pattern]
+	cache.Unlock()
+	if ok {
+		return r, nil
+	}
+	r, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	cache.Lock()
+	if len(cache.patterns) >= maxCached {
+		clear(cache.patterns)
+	}
+	cache.patterns[pattern] = r
+	cache.Unlock()
+	return r, nil
+}
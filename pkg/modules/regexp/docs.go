@@ -2,6 +2,10 @@ package regexp
 
 import "github.com/deepnoodle-ai/risor/v2/pkg/object"
 
+func init() {
+	object.RegisterModule("regexp", ModuleDoc(), Docs)
+}
+
 // Docs returns documentation for the regexp module.
 func Docs() []object.FuncSpec {
 	return regexpDocs
@@ -19,6 +23,9 @@ var regexpDocs = []object.FuncSpec{
 	{Name: "find_all", Doc: "Find all matches", Args: []string{"pattern", "s", "n?"}, Returns: "list"},
 	{Name: "search", Doc: "Find index of first match", Args: []string{"pattern", "s"}, Returns: "int"},
 	{Name: "replace", Doc: "Replace matches", Args: []string{"pattern", "s", "repl", "count?"}, Returns: "string"},
+	{Name: "replace_func", Doc: "Replace matches with the result of a function", Args: []string{"pattern", "s", "fn"}, Returns: "string"},
+	{Name: "find_all_index", Doc: "Find positions of all matches", Args: []string{"pattern", "s", "n?"}, Returns: "list"},
+	{Name: "named_groups", Doc: "Named capture groups of first match", Args: []string{"pattern", "s"}, Returns: "map|null"},
 	{Name: "split", Doc: "Split by pattern", Args: []string{"pattern", "s", "n?"}, Returns: "list"},
 	{Name: "escape", Doc: "Escape metacharacters", Args: []string{"s"}, Returns: "string"},
 }
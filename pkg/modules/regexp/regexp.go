@@ -4,11 +4,14 @@ import (
 	"context"
 	"fmt"
 	"regexp"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/deepnoodle-ai/risor/v2/pkg/object"
 )
 
 // Compile compiles a regular expression pattern and returns a Regexp object.
+// Compiled patterns are cached, as they are for the other module functions.
 func Compile(ctx context.Context, args ...object.Object) (object.Object, error) {
 	if len(args) != 1 {
 		return nil, fmt.Errorf("regexp.compile: expected 1 argument, got %d", len(args))
@@ -17,7 +20,7 @@ func Compile(ctx context.Context, args ...object.Object) (object.Object, error)
 	if err != nil {
 		return nil, err
 	}
-	r, rErr := regexp.Compile(pattern)
+	r, rErr := compile(pattern)
 	if rErr != nil {
 		return nil, rErr
 	}
@@ -25,8 +28,6 @@ func Compile(ctx context.Context, args ...object.Object) (object.Object, error)
 }
 
 // Match tests whether a pattern matches a string.
-// This is a convenience function that compiles the pattern each time.
-// For repeated use, compile the pattern first with regexp.compile().
 func Match(ctx context.Context, args ...object.Object) (object.Object, error) {
 	if len(args) != 2 {
 		return nil, fmt.Errorf("regexp.match: expected 2 arguments, got %d", len(args))
@@ -39,11 +40,11 @@ func Match(ctx context.Context, args ...object.Object) (object.Object, error) {
 	if err != nil {
 		return nil, err
 	}
-	matched, rErr := regexp.MatchString(pattern, str)
+	r, rErr := compile(pattern)
 	if rErr != nil {
 		return nil, rErr
 	}
-	return object.NewBool(matched), nil
+	return object.NewBool(r.MatchString(str)), nil
 }
 
 // Escape returns a string with all regular expression metacharacters escaped.
@@ -79,7 +80,7 @@ func Replace(ctx context.Context, args ...object.Object) (object.Object, error)
 		return nil, err
 	}
 
-	r, rErr := regexp.Compile(pattern)
+	r, rErr := compile(pattern)
 	if rErr != nil {
 		return nil, rErr
 	}
@@ -127,7 +128,7 @@ func Split(ctx context.Context, args ...object.Object) (object.Object, error) {
 		return nil, err
 	}
 
-	r, rErr := regexp.Compile(pattern)
+	r, rErr := compile(pattern)
 	if rErr != nil {
 		return nil, rErr
 	}
@@ -163,7 +164,7 @@ func Find(ctx context.Context, args ...object.Object) (object.Object, error) {
 		return nil, err
 	}
 
-	r, rErr := regexp.Compile(pattern)
+	r, rErr := compile(pattern)
 	if rErr != nil {
 		return nil, rErr
 	}
@@ -190,7 +191,7 @@ func FindAll(ctx context.Context, args ...object.Object) (object.Object, error)
 		return nil, err
 	}
 
-	r, rErr := regexp.Compile(pattern)
+	r, rErr := compile(pattern)
 	if rErr != nil {
 		return nil, rErr
 	}
@@ -226,7 +227,7 @@ func Search(ctx context.Context, args ...object.Object) (object.Object, error) {
 		return nil, err
 	}
 
-	r, rErr := regexp.Compile(pattern)
+	r, rErr := compile(pattern)
 	if rErr != nil {
 		return nil, rErr
 	}
@@ -240,15 +241,152 @@ func Search(ctx context.Context, args ...object.Object) (object.Object, error) {
 	return object.NewInt(int64(runeIndex)), nil
 }
 
+// ReplaceFunc replaces each match of pattern in a string with the result of
+// calling fn with the matched text. fn must return a string.
+func ReplaceFunc(ctx context.Context, args ...object.Object) (object.Object, error) {
+	if len(args) != 3 {
+		return nil, fmt.Errorf("regexp.replace_func: expected 3 arguments, got %d", len(args))
+	}
+	pattern, err := object.AsString(args[0])
+	if err != nil {
+		return nil, err
+	}
+	str, err := object.AsString(args[1])
+	if err != nil {
+		return nil, err
+	}
+	r, rErr := compile(pattern)
+	if rErr != nil {
+		return nil, rErr
+	}
+	return replaceFunc(ctx, "regexp.replace_func", r, str, args[2])
+}
+
+// FindAllIndex returns the [start, end) positions of all matches as a list
+// of two-element lists. With 3 arguments, limits to n matches.
+func FindAllIndex(ctx context.Context, args ...object.Object) (object.Object, error) {
+	if len(args) < 2 || len(args) > 3 {
+		return nil, fmt.Errorf("regexp.find_all_index: expected 2-3 arguments, got %d", len(args))
+	}
+	pattern, err := object.AsString(args[0])
+	if err != nil {
+		return nil, err
+	}
+	str, err := object.AsString(args[1])
+	if err != nil {
+		return nil, err
+	}
+	r, rErr := compile(pattern)
+	if rErr != nil {
+		return nil, rErr
+	}
+	n := -1
+	if len(args) == 3 {
+		nVal, err := object.AsInt(args[2])
+		if err != nil {
+			return nil, err
+		}
+		n = int(nVal)
+	}
+	return findAllIndex(r, str, n), nil
+}
+
+// NamedGroups returns the named capture groups of the first match as a map,
+// or null if there is no match.
+func NamedGroups(ctx context.Context, args ...object.Object) (object.Object, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("regexp.named_groups: expected 2 arguments, got %d", len(args))
+	}
+	pattern, err := object.AsString(args[0])
+	if err != nil {
+		return nil, err
+	}
+	str, err := object.AsString(args[1])
+	if err != nil {
+		return nil, err
+	}
+	r, rErr := compile(pattern)
+	if rErr != nil {
+		return nil, rErr
+	}
+	return namedGroups(r, str), nil
+}
+
+func replaceFunc(ctx context.Context, name string, r *regexp.Regexp, str string, fnObj object.Object) (object.Object, error) {
+	fn, ok := fnObj.(object.Callable)
+	if !ok {
+		return nil, object.TypeErrorf("%s: expected function, got %s", name, fnObj.Type())
+	}
+	var sb strings.Builder
+	last := 0
+	for _, loc := range r.FindAllStringIndex(str, -1) {
+		result, err := fn.Call(ctx, object.NewString(str[loc[0]:loc[1]]))
+		if err != nil {
+			return nil, err
+		}
+		repl, ok := result.(*object.String)
+		if !ok {
+			return nil, object.TypeErrorf("%s: function must return a string, got %s", name, result.Type())
+		}
+		sb.WriteString(str[last:loc[0]])
+		sb.WriteString(repl.Value())
+		last = loc[1]
+	}
+	sb.WriteString(str[last:])
+	return object.NewString(sb.String()), nil
+}
+
+// findAllIndex converts match byte offsets to rune offsets, matching search.
+func findAllIndex(r *regexp.Regexp, str string, n int) object.Object {
+	locs := r.FindAllStringIndex(str, n)
+	result := make([]object.Object, len(locs))
+	bytePos, runePos := 0, 0
+	runeIndex := func(offset int) int64 {
+		runePos += utf8.RuneCountInString(str[bytePos:offset])
+		bytePos = offset
+		return int64(runePos)
+	}
+	for i, loc := range locs {
+		start := runeIndex(loc[0])
+		end := runeIndex(loc[1])
+		result[i] = object.NewList([]object.Object{object.NewInt(start), object.NewInt(end)})
+	}
+	return object.NewList(result)
+}
+
+// namedGroups maps each named group to its text in the first match. A group
+// that did not take part in the match is null.
+func namedGroups(r *regexp.Regexp, str string) object.Object {
+	loc := r.FindStringSubmatchIndex(str)
+	if loc == nil {
+		return object.Nil
+	}
+	groups := map[string]object.Object{}
+	for i, name := range r.SubexpNames() {
+		if name == "" {
+			continue
+		}
+		if loc[2*i] < 0 {
+			groups[name] = object.Nil
+		} else {
+			groups[name] = object.NewString(str[loc[2*i]:loc[2*i+1]])
+		}
+	}
+	return object.NewMap(groups)
+}
+
 func Module() *object.Module {
 	return object.NewBuiltinsModule("regexp", map[string]object.Object{
-		"compile":  object.NewBuiltin("compile", Compile),
-		"match":    object.NewBuiltin("match", Match),
-		"escape":   object.NewBuiltin("escape", Escape),
-		"replace":  object.NewBuiltin("replace", Replace),
-		"split":    object.NewBuiltin("split", Split),
-		"find":     object.NewBuiltin("find", Find),
-		"find_all": object.NewBuiltin("find_all", FindAll),
-		"search":   object.NewBuiltin("search", Search),
+		"compile":        object.NewBuiltin("compile", Compile),
+		"match":          object.NewBuiltin("match", Match),
+		"escape":         object.NewBuiltin("escape", Escape),
+		"replace":        object.NewBuiltin("replace", Replace),
+		"split":          object.NewBuiltin("split", Split),
+		"find":           object.NewBuiltin("find", Find),
+		"find_all":       object.NewBuiltin("find_all", FindAll),
+		"search":         object.NewBuiltin("search", Search),
+		"replace_func":   object.NewBuiltin("replace_func", ReplaceFunc),
+		"find_all_index": object.NewBuiltin("find_all_index", FindAllIndex),
+		"named_groups":   object.NewBuiltin("named_groups", NamedGroups),
 	}, Compile)
 }
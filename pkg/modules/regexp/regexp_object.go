@@ -284,6 +284,62 @@ func (r *Regexp) GetAttr(name string) (object.Object, bool) {
 			},
 		), true
 
+	// Replace using a function
+	case "replace_func":
+		return object.NewBuiltin(
+			"regexp.replace_func",
+			func(ctx context.Context, args ...object.Object) (object.Object, error) {
+				if len(args) != 2 {
+					return nil, fmt.Errorf("regexp.replace_func: expected 2 arguments, got %d", len(args))
+				}
+				strValue, err := object.AsString(args[0])
+				if err != nil {
+					return nil, err
+				}
+				return replaceFunc(ctx, "regexp.replace_func", r.value, strValue, args[1])
+			},
+		), true
+
+	// Positions of all matches
+	case "find_all_index":
+		return object.NewBuiltin(
+			"regexp.find_all_index",
+			func(ctx context.Context, args ...object.Object) (object.Object, error) {
+				if len(args) < 1 || len(args) > 2 {
+					return nil, fmt.Errorf("regexp.find_all_index: expected 1-2 arguments, got %d", len(args))
+				}
+				strValue, err := object.AsString(args[0])
+				if err != nil {
+					return nil, err
+				}
+				n := -1
+				if len(args) == 2 {
+					i64, err := object.AsInt(args[1])
+					if err != nil {
+						return nil, err
+					}
+					n = int(i64)
+				}
+				return findAllIndex(r.value, strValue, n), nil
+			},
+		), true
+
+	// Named capture groups as a map
+	case "named_groups":
+		return object.NewBuiltin(
+			"regexp.named_groups",
+			func(ctx context.Context, args ...object.Object) (object.Object, error) {
+				if len(args) != 1 {
+					return nil, fmt.Errorf("regexp.named_groups: expected 1 argument, got %d", len(args))
+				}
+				strValue, err := object.AsString(args[0])
+				if err != nil {
+					return nil, err
+				}
+				return namedGroups(r.value, strValue), nil
+			},
+		), true
+
 	// Split
 	case "split":
 		return object.NewBuiltin(
@@ -611,6 +611,91 @@ func TestModuleSearchNoMatch(t *testing.T) {
 	assert.Equal(t, i.Value(), int64(-1))
 }
 
+func TestModuleReplaceFunc(t *testing.T) {
+	ctx := context.Background()
+	upper := object.NewBuiltin("upper", func(ctx context.Context, args ...object.Object) (object.Object, error) {
+		return object.NewString("<" + args[0].(*object.String).Value() + ">"), nil
+	})
+
+	result, err := ReplaceFunc(ctx, object.NewString(`\d+`), object.NewString("a1b22c"), upper)
+	assert.Nil(t, err)
+	assert.Equal(t, result, object.NewString("a<1>b<22>c"))
+
+	// Methods use the same implementation
+	obj := NewRegexp(regexp.MustCompile(`b+`))
+	method, ok := obj.GetAttr("replace_func")
+	assert.True(t, ok)
+	result, err = method.(*object.Builtin).Call(ctx, object.NewString("abbc"), upper)
+	assert.Nil(t, err)
+	assert.Equal(t, result, object.NewString("a<bb>c"))
+}
+
+func TestModuleReplaceFuncErrors(t *testing.T) {
+	ctx := context.Background()
+	toInt := object.NewBuiltin("to_int", func(ctx context.Context, args ...object.Object) (object.Object, error) {
+		return object.NewInt(1), nil
+	})
+
+	_, err := ReplaceFunc(ctx, object.NewString(`\d`), object.NewString("a1"), toInt)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "function must return a string, got int")
+
+	_, err = ReplaceFunc(ctx, object.NewString(`\d`), object.NewString("a1"), object.NewString("X"))
+	assert.NotNil(t, err)
+
+	_, err = ReplaceFunc(ctx, object.NewString(`\d`), object.NewString("a1"))
+	assert.NotNil(t, err)
+}
+
+func TestModuleFindAllIndex(t *testing.T) {
+	ctx := context.Background()
+
+	// Positions are in runes, like search
+	result, err := FindAllIndex(ctx, object.NewString(`\d+`), object.NewString("é12x3"))
+	assert.Nil(t, err)
+	assert.Equal(t, result.Inspect(), "[[1, 3], [4, 5]]")
+
+	result, err = FindAllIndex(ctx, object.NewString(`\d+`), object.NewString("é12x3"), object.NewInt(1))
+	assert.Nil(t, err)
+	assert.Equal(t, result.Inspect(), "[[1, 3]]")
+
+	result, err = FindAllIndex(ctx, object.NewString(`\d+`), object.NewString("none"))
+	assert.Nil(t, err)
+	assert.Equal(t, result.Inspect(), "[]")
+}
+
+func TestModuleNamedGroups(t *testing.T) {
+	ctx := context.Background()
+	pattern := object.NewString(`(?P<user>\w+)@(?P<host>\w+)(:(?P<port>\d+))?`)
+
+	result, err := NamedGroups(ctx, pattern, object.NewString("me@box:22"))
+	assert.Nil(t, err)
+	assert.Equal(t, result.Inspect(), `{"host": "box", "port": "22", "user": "me"}`)
+
+	result, err = NamedGroups(ctx, pattern, object.NewString("me@box"))
+	assert.Nil(t, err)
+	assert.Equal(t, result.Inspect(), `{"host": "box", "port": null, "user": "me"}`)
+
+	result, err = NamedGroups(ctx, pattern, object.NewString("nothing"))
+	assert.Nil(t, err)
+	assert.Equal(t, result, object.Nil)
+}
+
+func TestCompileCache(t *testing.T) {
+	ctx := context.Background()
+	first, err := Compile(ctx, object.NewString(`cached\d`))
+	assert.Nil(t, err)
+	second, err := Compile(ctx, object.NewString(`cached\d`))
+	assert.Nil(t, err)
+	assert.True(t, first.(*Regexp).value == second.(*Regexp).value)
+
+	// Failed compilations are not cached
+	_, err = Compile(ctx, object.NewString(`[cached`))
+	assert.NotNil(t, err)
+	_, ok := cache.patterns[`[cached`]
+	assert.False(t, ok)
+}
+
 func TestRegexpType(t *testing.T) {
 	r := NewRegexp(regexp.MustCompile(`foo`))
 	assert.Equal(t, r.Type(), REGEXP)
@@ -716,6 +801,7 @@ func TestModule(t *testing.T) {
 	// Verify all functions exist
 	functions := []string{
 		"compile", "match", "escape", "replace", "split", "find", "find_all", "search",
+		"replace_func", "find_all_index", "named_groups",
 	}
 	for _, name := range functions {
 		_, ok := m.GetAttr(name)
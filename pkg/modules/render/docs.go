@@ -0,0 +1,23 @@
+package render
+
+import "github.com/deepnoodle-ai/risor/v2/pkg/object"
+
+func init() {
+	object.RegisterModule("render", ModuleDoc(), Docs)
+}
+
+// Docs returns documentation for the render module.
+func Docs() []object.FuncSpec {
+	return renderDocs
+}
+
+// ModuleDoc returns the module-level documentation.
+func ModuleDoc() string {
+	return "Tables, Markdown, and JSON formatted for the terminal"
+}
+
+var renderDocs = []object.FuncSpec{
+	{Name: "table", Doc: "Lay out a list of maps in aligned columns", Args: []string{"rows", "options?"}, Returns: "string"},
+	{Name: "markdown", Doc: "Format a Markdown document for the terminal", Args: []string{"source"}, Returns: "string"},
+	{Name: "json", Doc: "Pretty-print a value as JSON, optionally in color", Args: []string{"value", "options?"}, Returns: "string"},
+}
@@ -0,0 +1,16 @@
+module github.com/deepnoodle-ai/risor/v2/pkg/modules/render
+
+go 1.25
+
+replace github.com/deepnoodle-ai/risor/v2 => ../../..
+
+require (
+	github.com/deepnoodle-ai/risor/v2 v2.0.0
+	github.com/deepnoodle-ai/wonton v0.0.25
+	github.com/rivo/uniseg v0.4.7
+)
+
+require (
+	github.com/google/go-cmp v0.7.0 // indirect
+	golang.org/x/sys v0.39.0 // indirect
+)
@@ -0,0 +1,118 @@
+package render
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+)
+
+// JSON token colors, as SGR parameters.
+const (
+	keyColor     = "34;1"
+	stringColor  = "32"
+	numberColor  = "36"
+	literalColor = "33"
+	nullColor    = "90"
+)
+
+func jsonText(c *config) object.BuiltinFunction {
+	return func(ctx context.Context, args ...object.Object) (object.Object, error) {
+		if len(args) < 1 || len(args) > 2 {
+			return nil, object.NewArgsRangeError("render.json", 1, 2, len(args))
+		}
+		color := c.color
+		indent := int64(2)
+		if len(args) == 2 {
+			opts, err := object.AsMap(args[1])
+			if err != nil {
+				return nil, err
+			}
+			if err := opts.CheckStringKeys(); err != nil {
+				return nil, fmt.Errorf("render.json: %w", err)
+			}
+			for _, name := range opts.SortedKeys() {
+				switch name {
+				case "color":
+					if color, err = object.AsBool(opts.Get(name)); err != nil {
+						return nil, err
+					}
+				case "indent":
+					if indent, err = object.AsInt(opts.Get(name)); err != nil {
+						return nil, err
+					}
+					if indent < 0 {
+						return nil, object.ValueErrorf("render.json: indent must be non-negative, got %d", indent)
+					}
+				default:
+					return nil, object.ValueErrorf("render.json: unknown option %q", name)
+				}
+			}
+		}
+		var data []byte
+		var err error
+		if indent == 0 {
+			data, err = json.Marshal(args[0])
+		} else {
+			data, err = json.MarshalIndent(args[0], "", strings.Repeat(" ", int(indent)))
+		}
+		if err != nil {
+			return nil, object.ValueErrorf("render.json: %v", err)
+		}
+		if !color {
+			return object.NewString(string(data)), nil
+		}
+		return object.NewString(colorize(string(data))), nil
+	}
+}
+
+// colorize adds colors to valid JSON text. Object keys are told apart from
+// string values by the colon that follows them.
+func colorize(text string) string {
+	var sb strings.Builder
+	for i := 0; i < len(text); {
+		switch ch := text[i]; {
+		case ch == '"':
+			end := i + 1
+			for text[end] != '"' {
+				if text[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			end++
+			next := end
+			for next < len(text) && strings.IndexByte(" \t\r\n", text[next]) >= 0 {
+				next++
+			}
+			if next < len(text) && text[next] == ':' {
+				sb.WriteString(sgr(keyColor, text[i:end]))
+			} else {
+				sb.WriteString(sgr(stringColor, text[i:end]))
+			}
+			i = end
+		case ch == '-' || (ch >= '0' && ch <= '9'):
+			end := i + 1
+			for end < len(text) && strings.IndexByte("0123456789.eE+-", text[end]) >= 0 {
+				end++
+			}
+			sb.WriteString(sgr(numberColor, text[i:end]))
+			i = end
+		case strings.HasPrefix(text[i:], "true"):
+			sb.WriteString(sgr(literalColor, "true"))
+			i += 4
+		case strings.HasPrefix(text[i:], "false"):
+			sb.WriteString(sgr(literalColor, "false"))
+			i += 5
+		case strings.HasPrefix(text[i:], "null"):
+			sb.WriteString(sgr(nullColor, "null"))
+			i += 4
+		default:
+			sb.WriteByte(ch)
+			i++
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,207 @@
+package render
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+	"github.com/rivo/uniseg"
+)
+
+// Option configures the render module.
+type Option func(*config)
+
+type config struct {
+	color    bool
+	markdown func(source string) (string, error)
+}
+
+// WithColor enables color in rendered output. The host passes it when output
+// goes to an interactive terminal. Without it output is plain text.
+func WithColor(enabled bool) Option {
+	return func(c *config) {
+		c.color = enabled
+	}
+}
+
+// WithMarkdown supplies the function markdown uses to render a document for
+// the terminal. Without it markdown returns the document unchanged, since
+// Markdown source is already readable as plain text.
+func WithMarkdown(fn func(source string) (string, error)) Option {
+	return func(c *config) {
+		c.markdown = fn
+	}
+}
+
+// sgr wraps s in an ANSI Select Graphic Rendition sequence.
+func sgr(code, s string) string {
+	return "\x1b[" + code + "m" + s + "\x1b[0m"
+}
+
+// cellText formats a value for a table cell.
+func cellText(cell object.Object) string {
+	switch cell := cell.(type) {
+	case *object.NilType:
+		return ""
+	case *object.String:
+		return cell.Value()
+	}
+	return cell.Inspect()
+}
+
+func isNumber(obj object.Object) bool {
+	switch obj.(type) {
+	case *object.Int, *object.Float:
+		return true
+	}
+	return false
+}
+
+func table(c *config) object.BuiltinFunction {
+	return func(ctx context.Context, args ...object.Object) (object.Object, error) {
+		if len(args) < 1 || len(args) > 2 {
+			return nil, object.NewArgsRangeError("render.table", 1, 2, len(args))
+		}
+		list, err := object.AsList(args[0])
+		if err != nil {
+			return nil, err
+		}
+		rows := make([]*object.Map, len(list.Value()))
+		for i, item := range list.Value() {
+			row, ok := item.(*object.Map)
+			if !ok {
+				return nil, object.TypeErrorf("render.table: row %d is a %s, not a map", i, item.Type())
+			}
+			rows[i] = row
+		}
+		var columns []string
+		if len(args) == 2 {
+			opts, err := object.AsMap(args[1])
+			if err != nil {
+				return nil, err
+			}
+			if err := opts.CheckStringKeys(); err != nil {
+				return nil, fmt.Errorf("render.table: %w", err)
+			}
+			for _, name := range opts.SortedKeys() {
+				switch name {
+				case "columns":
+					if columns, err = object.AsStringSlice(opts.Get(name)); err != nil {
+						return nil, err
+					}
+				default:
+					return nil, object.ValueErrorf("render.table: unknown option %q", name)
+				}
+			}
+		}
+		if columns == nil {
+			columns = allKeys(rows)
+		}
+		return object.NewString(renderTable(columns, rows, c.color)), nil
+	}
+}
+
+// allKeys returns every key found in rows, sorted.
+func allKeys(rows []*object.Map) []string {
+	seen := map[string]bool{}
+	var keys []string
+	for _, row := range rows {
+		for key := range row.Value() {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// renderTable lays rows out in aligned columns under a header. Numbers are
+// right-aligned and everything else left-aligned.
+func renderTable(columns []string, rows []*object.Map, color bool) string {
+	widths := make([]int, len(columns))
+	cells := make([][]string, len(rows))
+	for i, name := range columns {
+		widths[i] = uniseg.StringWidth(name)
+	}
+	for r, row := range rows {
+		cells[r] = make([]string, len(columns))
+		for i, name := range columns {
+			text := strings.ReplaceAll(cellText(row.Get(name)), "\n", " ")
+			cells[r][i] = text
+			widths[i] = max(widths[i], uniseg.StringWidth(text))
+		}
+	}
+	var sb strings.Builder
+	writeRow := func(texts []string, style string, alignRight func(int) bool) {
+		for i, text := range texts {
+			if i > 0 {
+				sb.WriteString("  ")
+			}
+			pad := strings.Repeat(" ", widths[i]-uniseg.StringWidth(text))
+			if color && style != "" {
+				text = sgr(style, text)
+			}
+			switch {
+			case alignRight(i):
+				sb.WriteString(pad + text)
+			case i < len(texts)-1:
+				sb.WriteString(text + pad)
+			default:
+				// Leave no trailing spaces after the last column.
+				sb.WriteString(text)
+			}
+		}
+		sb.WriteByte('\n')
+	}
+	left := func(int) bool { return false }
+	writeRow(columns, "1", left)
+	rules := make([]string, len(columns))
+	for i, width := range widths {
+		rules[i] = strings.Repeat("─", width)
+	}
+	writeRow(rules, "2", left)
+	for r, row := range rows {
+		writeRow(cells[r], "", func(i int) bool {
+			return isNumber(row.Get(columns[i]))
+		})
+	}
+	return sb.String()
+}
+
+func markdown(c *config) object.BuiltinFunction {
+	return func(ctx context.Context, args ...object.Object) (object.Object, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("render.markdown: expected 1 argument, got %d", len(args))
+		}
+		source, err := object.AsString(args[0])
+		if err != nil {
+			return nil, err
+		}
+		if c.markdown == nil {
+			return object.NewString(source), nil
+		}
+		rendered, err := c.markdown(source)
+		if err != nil {
+			return nil, fmt.Errorf("render.markdown: %w", err)
+		}
+		return object.NewString(rendered), nil
+	}
+}
+
+// Module returns the render module. Output is plain text unless the
+// WithColor option enables color.
+func Module(opts ...Option) *object.Module {
+	c := &config{}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return object.NewBuiltinsModule("render", map[string]object.Object{
+		"table":    object.NewBuiltin("table", table(c)),
+		"markdown": object.NewBuiltin("markdown", markdown(c)),
+		"json":     object.NewBuiltin("json", jsonText(c)),
+	})
+}
@@ -0,0 +1,86 @@
+package render
+
+import (
+	"context"
+	"testing"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+func call(m *object.Module, name string, args ...object.Object) (object.Object, error) {
+	fn, _ := m.GetAttr(name)
+	return fn.(object.Callable).Call(context.Background(), args...)
+}
+
+func rows() *object.List {
+	return object.NewList([]object.Object{
+		object.NewMap(map[string]object.Object{"name": object.NewString("bolt"), "qty": object.NewInt(4)}),
+		object.NewMap(map[string]object.Object{"name": object.NewString("wing nut"), "qty": object.NewInt(10), "note": object.NewString("zinc")}),
+	})
+}
+
+func TestTable(t *testing.T) {
+	result, err := call(Module(), "table", rows())
+	assert.Nil(t, err)
+	assert.Equal(t, result.(*object.String).Value(), ""+
+		"name      note  qty\n"+
+		"────────  ────  ───\n"+
+		"bolt              4\n"+
+		"wing nut  zinc   10\n")
+
+	opts := object.NewMap(map[string]object.Object{
+		"columns": object.NewStringList([]string{"qty", "name"}),
+	})
+	result, err = call(Module(), "table", rows(), opts)
+	assert.Nil(t, err)
+	assert.Equal(t, result.(*object.String).Value(), ""+
+		"qty  name\n"+
+		"───  ────────\n"+
+		"  4  bolt\n"+
+		" 10  wing nut\n")
+
+	result, err = call(Module(WithColor(true)), "table", rows(), opts)
+	assert.Nil(t, err)
+	assert.Contains(t, result.(*object.String).Value(), "\x1b[1mqty\x1b[0m  \x1b[1mname\x1b[0m\n")
+
+	_, err = call(Module(), "table", object.NewList([]object.Object{object.NewInt(1)}))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "row 0 is a int, not a map")
+}
+
+func TestMarkdown(t *testing.T) {
+	source := object.NewString("# Title\n\nSome *text*.\n")
+	result, err := call(Module(), "markdown", source)
+	assert.Nil(t, err)
+	assert.Equal(t, result, source)
+
+	m := Module(WithMarkdown(func(source string) (string, error) {
+		return "rendered:" + source, nil
+	}))
+	result, err = call(m, "markdown", source)
+	assert.Nil(t, err)
+	assert.Equal(t, result, object.NewString("rendered:# Title\n\nSome *text*.\n"))
+}
+
+func TestJSON(t *testing.T) {
+	value := object.NewMap(map[string]object.Object{
+		"name": object.NewString("a:b"),
+		"tags": object.NewList([]object.Object{object.NewInt(-1), object.True, object.Nil}),
+	})
+	result, err := call(Module(), "json", value)
+	assert.Nil(t, err)
+	assert.Equal(t, result.(*object.String).Value(), "{\n  \"name\": \"a:b\",\n  \"tags\": [\n    -1,\n    true,\n    null\n  ]\n}")
+
+	opts := object.NewMap(map[string]object.Object{"indent": object.NewInt(0)})
+	result, err = call(Module(WithColor(true)), "json", value, opts)
+	assert.Nil(t, err)
+	assert.Equal(t, result.(*object.String).Value(), "{"+
+		"\x1b[34;1m\"name\"\x1b[0m:\x1b[32m\"a:b\"\x1b[0m,"+
+		"\x1b[34;1m\"tags\"\x1b[0m:[\x1b[36m-1\x1b[0m,\x1b[33mtrue\x1b[0m,\x1b[90mnull\x1b[0m]}")
+
+	opts = object.NewMap(map[string]object.Object{"color": object.False, "indent": object.NewInt(0)})
+	result, err = call(Module(WithColor(true)), "json", object.NewString(`say "hi"`), opts)
+	assert.Nil(t, err)
+	assert.Equal(t, result, object.NewString(`"say \"hi\""`))
+}
@@ -0,0 +1,22 @@
+package retry
+
+import "github.com/deepnoodle-ai/risor/v2/pkg/object"
+
+func init() {
+	object.RegisterModule("retry", ModuleDoc(), Docs)
+}
+
+// Docs returns documentation for the retry module.
+func Docs() []object.FuncSpec {
+	return retryDocs
+}
+
+// ModuleDoc returns the module-level documentation.
+func ModuleDoc() string {
+	return "Retry failing calls with backoff and bound calls with timeouts"
+}
+
+var retryDocs = []object.FuncSpec{
+	{Name: "do", Doc: "Call a function until it succeeds, waiting longer between attempts", Args: []string{"fn", "options?"}, Returns: "object"},
+	{Name: "timeout", Doc: "Call a function and fail if it runs longer than a duration", Args: []string{"fn", "duration"}, Returns: "object"},
+}
@@ -0,0 +1,255 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+)
+
+// Defaults for the options of retry.do.
+const (
+	defaultAttempts = 3
+	defaultDelay    = 100 * time.Millisecond
+	defaultMaxDelay = 30 * time.Second
+)
+
+// callableArg returns arg as a Callable, or a type error naming fn.
+func callableArg(fn string, arg object.Object) (object.Callable, error) {
+	callable, ok := arg.(object.Callable)
+	if !ok {
+		return nil, object.TypeErrorf("retry.%s() expected a function (%s given)", fn, arg.Type())
+	}
+	return callable, nil
+}
+
+// durationArg converts a number of seconds, or a string such as "500ms" or
+// "2m", to a duration that is not negative.
+func durationArg(name string, arg object.Object) (time.Duration, error) {
+	d, err := object.AsDuration(arg)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", name, err)
+	}
+	if d < 0 {
+		return 0, object.ValueErrorf("%s must not be negative (got %s)", name, arg.Inspect())
+	}
+	return d, nil
+}
+
+// policy holds the options of retry.do.
+type policy struct {
+	attempts int
+	delay    time.Duration
+	maxDelay time.Duration
+	backoff  string
+	jitter   bool
+	retryIf  object.Callable
+}
+
+// parsePolicy reads the options of retry.do from m, which may be nil.
+func parsePolicy(m *object.Map) (*policy, error) {
+	p := &policy{
+		attempts: defaultAttempts,
+		delay:    defaultDelay,
+		maxDelay: defaultMaxDelay,
+		backoff:  "exponential",
+	}
+	if m == nil {
+		return p, nil
+	}
+	if err := m.CheckStringKeys(); err != nil {
+		return nil, fmt.Errorf("retry.do: %w", err)
+	}
+	for _, key := range m.SortedKeys() {
+		value := m.Get(key)
+		switch key {
+		case "attempts":
+			n, err := object.AsInt(value)
+			if err != nil {
+				return nil, fmt.Errorf("retry.do: attempts: %w", err)
+			}
+			if n < 1 {
+				return nil, object.ValueErrorf("retry.do: attempts must be at least 1 (got %d)", n)
+			}
+			p.attempts = int(n)
+		case "delay":
+			d, err := durationArg("retry.do: delay", value)
+			if err != nil {
+				return nil, err
+			}
+			p.delay = d
+		case "max_delay":
+			d, err := durationArg("retry.do: max_delay", value)
+			if err != nil {
+				return nil, err
+			}
+			if d == 0 {
+				return nil, object.ValueErrorf("retry.do: max_delay must be positive")
+			}
+			p.maxDelay = d
+		case "backoff":
+			s, err := object.AsString(value)
+			if err != nil {
+				return nil, fmt.Errorf("retry.do: backoff: %w", err)
+			}
+			switch s {
+			case "constant", "linear", "exponential":
+				p.backoff = s
+			default:
+				return nil, object.ValueErrorf("retry.do: backoff must be \"constant\", \"linear\", or \"exponential\" (got %q)", s)
+			}
+		case "jitter":
+			b, err := object.AsBool(value)
+			if err != nil {
+				return nil, fmt.Errorf("retry.do: jitter: %w", err)
+			}
+			p.jitter = b
+		case "retry_if":
+			fn, err := callableArg("do", value)
+			if err != nil {
+				return nil, err
+			}
+			p.retryIf = fn
+		default:
+			return nil, fmt.Errorf("retry.do: unknown option %q", key)
+		}
+	}
+	return p, nil
+}
+
+// wait returns how long to wait after the given failed attempt, counted
+// from 1.
+func (p *policy) wait(attempt int) time.Duration {
+	d := p.delay
+	switch p.backoff {
+	case "linear":
+		d = p.delay * time.Duration(attempt)
+	case "exponential":
+		for i := 1; i < attempt && d < p.maxDelay; i++ {
+			d *= 2
+		}
+	}
+	if d > p.maxDelay {
+		d = p.maxDelay
+	}
+	if p.jitter && d > 0 {
+		// Wait between half and all of the delay
+		d = d/2 + rand.N(d/2+1)
+	}
+	return d
+}
+
+// shouldRetry reports whether a failed call may be tried again. Exits and
+// the cancellation of the caller's context are never retried.
+func (p *policy) shouldRetry(ctx context.Context, err error) (bool, error) {
+	var exitErr *object.ExitError
+	if errors.As(err, &exitErr) || ctx.Err() != nil {
+		return false, nil
+	}
+	if p.retryIf == nil {
+		return true, nil
+	}
+	result, err := p.retryIf.Call(ctx, object.NewError(err))
+	if err != nil {
+		return false, fmt.Errorf("retry.do: retry_if: %w", err)
+	}
+	return result.IsTruthy(), nil
+}
+
+// sleep waits for d or until ctx is cancelled.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Do calls fn until it succeeds, waiting longer between each attempt, and
+// returns its result. The optional map sets the number of attempts, the
+// delays between them, and a retry_if function that decides which errors
+// are worth retrying. Once the attempts run out, the last error is
+// returned.
+func Do(ctx context.Context, args ...object.Object) (object.Object, error) {
+	if len(args) < 1 || len(args) > 2 {
+		return nil, object.NewArgsRangeError("retry.do", 1, 2, len(args))
+	}
+	fn, err := callableArg("do", args[0])
+	if err != nil {
+		return nil, err
+	}
+	var opts *object.Map
+	if len(args) == 2 {
+		m, ok := args[1].(*object.Map)
+		if !ok {
+			return nil, object.TypeErrorf("retry.do() expected a map of options (%s given)", args[1].Type())
+		}
+		opts = m
+	}
+	p, err := parsePolicy(opts)
+	if err != nil {
+		return nil, err
+	}
+	for attempt := 1; ; attempt++ {
+		result, err := fn.Call(ctx)
+		if err == nil {
+			return result, nil
+		}
+		retry, retryErr := p.shouldRetry(ctx, err)
+		if retryErr != nil {
+			return nil, retryErr
+		}
+		if !retry {
+			return nil, err
+		}
+		if attempt == p.attempts {
+			return nil, fmt.Errorf("retry.do: gave up after %d attempts: %w", attempt, err)
+		}
+		if err := sleep(ctx, p.wait(attempt)); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Timeout calls fn and fails if it does not return within the given
+// duration. The call's context is cancelled when the time runs out, which
+// stops Risor functions and the builtins they are waiting on.
+func Timeout(ctx context.Context, args ...object.Object) (object.Object, error) {
+	if len(args) != 2 {
+		return nil, object.NewArgsError("retry.timeout", 2, len(args))
+	}
+	fn, err := callableArg("timeout", args[0])
+	if err != nil {
+		return nil, err
+	}
+	d, err := durationArg("retry.timeout: duration", args[1])
+	if err != nil {
+		return nil, err
+	}
+	callCtx, cancel := context.WithTimeout(ctx, d)
+	defer cancel()
+	result, err := fn.Call(callCtx)
+	if err != nil {
+		if ctx.Err() == nil && errors.Is(callCtx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("retry.timeout: timed out after %s: %w", d, context.DeadlineExceeded)
+		}
+		return nil, err
+	}
+	return result, nil
+}
+
+func Module() *object.Module {
+	return object.NewBuiltinsModule("retry", map[string]object.Object{
+		"do":      object.NewBuiltin("do", Do),
+		"timeout": object.NewBuiltin("timeout", Timeout),
+	})
+}
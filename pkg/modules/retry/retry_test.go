@@ -0,0 +1,159 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/deepnoodle-ai/risor/v2/internal/moduletest"
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+// eval runs source with the retry module and the default builtins.
+func eval(ctx context.Context, source string) (object.Object, error) {
+	return moduletest.Eval(ctx, source, map[string]any{"retry": Module()})
+}
+
+func TestDo(t *testing.T) {
+	result, err := eval(context.Background(), `
+	let calls = 0
+	let value = retry.do(() => {
+		calls += 1
+		if (calls < 3) { throw "flaky" }
+		return "ok"
+	}, {attempts: 5, delay: 0.001})
+	[value, calls]
+	`)
+	assert.Nil(t, err)
+	assert.Equal(t, result.Inspect(), `["ok", 3]`)
+}
+
+func TestDoGivesUp(t *testing.T) {
+	result, err := eval(context.Background(), `
+	let calls = 0
+	try {
+		retry.do(() => { calls += 1; throw "down" }, {attempts: 4, delay: "1ms", backoff: "constant"})
+	} catch e {
+		[calls, e.message()]
+	}
+	`)
+	assert.Nil(t, err)
+	assert.Equal(t, result.Inspect(), `[4, "retry.do: gave up after 4 attempts: down"]`)
+}
+
+func TestDoRetryIf(t *testing.T) {
+	result, err := eval(context.Background(), `
+	let calls = 0
+	try {
+		retry.do(() => {
+			calls += 1
+			if (calls == 1) { throw "timeout" }
+			throw "not found"
+		}, {attempts: 5, delay: 0, retry_if: e => e.message() == "timeout"})
+	} catch e {
+		[calls, e.message()]
+	}
+	`)
+	assert.Nil(t, err)
+	assert.Equal(t, result.Inspect(), `[2, "not found"]`)
+
+	_, err = eval(context.Background(), `
+	retry.do(() => { throw "x" }, {delay: 0, retry_if: e => e.missing()})
+	`)
+	assert.Contains(t, err.Error(), "retry_if")
+}
+
+func TestDoCancel(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := eval(ctx, `retry.do(() => { throw "down" }, {attempts: 10, delay: 10})`)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.True(t, time.Since(start) < time.Second)
+}
+
+func TestWait(t *testing.T) {
+	p, err := parsePolicy(nil)
+	assert.Nil(t, err)
+	assert.Equal(t, p.wait(1), 100*time.Millisecond)
+	assert.Equal(t, p.wait(3), 400*time.Millisecond)
+	assert.Equal(t, p.wait(20), 30*time.Second)
+	assert.Equal(t, p.wait(1000), 30*time.Second)
+
+	p.backoff = "linear"
+	assert.Equal(t, p.wait(3), 300*time.Millisecond)
+	p.backoff = "constant"
+	assert.Equal(t, p.wait(3), 100*time.Millisecond)
+
+	p.jitter = true
+	for range 20 {
+		d := p.wait(1)
+		assert.True(t, d >= 50*time.Millisecond && d <= 100*time.Millisecond)
+	}
+}
+
+func TestOptions(t *testing.T) {
+	ctx := context.Background()
+	fn := object.NewBuiltin("f", func(ctx context.Context, args ...object.Object) (object.Object, error) {
+		return object.True, nil
+	})
+	opts := func(key string, value object.Object) *object.Map {
+		return object.NewMap(map[string]object.Object{key: value})
+	}
+	tests := []struct {
+		options *object.Map
+		want    string
+	}{
+		{opts("attempts", object.NewInt(0)), "attempts must be at least 1"},
+		{opts("backoff", object.NewString("fibonacci")), "backoff must be"},
+		{opts("delay", object.NewString("soon")), `invalid duration "soon"`},
+		{opts("delay", object.NewInt(-1)), "must not be negative"},
+		{opts("max_delay", object.NewInt(0)), "max_delay must be positive"},
+		{opts("retry_if", object.NewInt(1)), "expected a function"},
+		{opts("tries", object.NewInt(1)), `unknown option "tries"`},
+	}
+	for _, tt := range tests {
+		_, err := Do(ctx, fn, tt.options)
+		assert.NotNil(t, err)
+		assert.Contains(t, err.Error(), tt.want)
+	}
+
+	_, err := Do(ctx, object.NewInt(1))
+	assert.Contains(t, err.Error(), "expected a function")
+	result, err := Do(ctx, fn)
+	assert.Nil(t, err)
+	assert.Equal(t, result, object.Object(object.True))
+}
+
+func TestTimeout(t *testing.T) {
+	result, err := eval(context.Background(), `retry.timeout(() => 1 + 1, "1s")`)
+	assert.Nil(t, err)
+	assert.Equal(t, result, object.Object(object.NewInt(2)))
+
+	result, err = eval(context.Background(), `
+	try {
+		retry.timeout(() => range(1000000000).each(i => i), 0.01)
+	} catch e {
+		e.message()
+	}
+	`)
+	assert.Nil(t, err)
+	assert.Equal(t, result.Inspect(), `"retry.timeout: timed out after 10ms: context deadline exceeded"`)
+
+	// A timeout can be retried
+	result, err = eval(context.Background(), `
+	let calls = 0
+	retry.do(() => retry.timeout(() => {
+		calls += 1
+		if (calls == 1) { range(1000000000).each(i => i) }
+		return calls
+	}, "10ms"), {delay: 0})
+	`)
+	assert.Nil(t, err)
+	assert.Equal(t, result, object.Object(object.NewInt(2)))
+
+	_, err = eval(context.Background(), `retry.timeout(() => 1, -1)`)
+	assert.Contains(t, err.Error(), "must not be negative")
+}
@@ -0,0 +1,394 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+	"github.com/deepnoodle-ai/risor/v2/pkg/op"
+)
+
+const (
+	DATABASE    object.Type = "sqlite_db"
+	TRANSACTION object.Type = "sqlite_tx"
+)
+
+var (
+	dbMethods = object.NewMethodRegistry[*DB]("sqlite_db")
+	txMethods = object.NewMethodRegistry[*Tx]("sqlite_tx")
+)
+
+// conn is the part of *sql.DB and *sql.Tx that runs statements.
+type conn interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// runner returns the connection a database or transaction runs statements
+// on.
+type runner interface {
+	conn(ctx context.Context, method string) (conn, error)
+}
+
+// defineStatementMethods defines query and exec, which a database and a
+// transaction share.
+func defineStatementMethods[T runner](r *object.AttrRegistry[T], typeName string) {
+	r.Define("query").
+		Doc("Run a query and return its rows as a list of maps").
+		Arg("sql").
+		Variadic("params").
+		Returns("list").
+		Impl(func(t T, ctx context.Context, args ...object.Object) (object.Object, error) {
+			c, err := t.conn(ctx, "query")
+			if err != nil {
+				return nil, err
+			}
+			query, params, err := statement(typeName+".query", args)
+			if err != nil {
+				return nil, err
+			}
+			rows, err := c.QueryContext(ctx, query, params...)
+			if err != nil {
+				return nil, fmt.Errorf("%s.query: %w", typeName, err)
+			}
+			result, err := readRows(rows)
+			if err != nil {
+				return nil, fmt.Errorf("%s.query: %w", typeName, err)
+			}
+			return result, nil
+		})
+
+	r.Define("exec").
+		Doc("Run a statement and return the number of rows it changed and the last inserted row id").
+		Arg("sql").
+		Variadic("params").
+		Returns("map").
+		Impl(func(t T, ctx context.Context, args ...object.Object) (object.Object, error) {
+			c, err := t.conn(ctx, "exec")
+			if err != nil {
+				return nil, err
+			}
+			query, params, err := statement(typeName+".exec", args)
+			if err != nil {
+				return nil, err
+			}
+			res, err := c.ExecContext(ctx, query, params...)
+			if err != nil {
+				return nil, fmt.Errorf("%s.exec: %w", typeName, err)
+			}
+			affected, err := res.RowsAffected()
+			if err != nil {
+				return nil, fmt.Errorf("%s.exec: %w", typeName, err)
+			}
+			lastID, err := res.LastInsertId()
+			if err != nil {
+				return nil, fmt.Errorf("%s.exec: %w", typeName, err)
+			}
+			return object.NewMap(map[string]object.Object{
+				"rows_affected":  object.NewInt(affected),
+				"last_insert_id": object.NewInt(lastID),
+			}), nil
+		})
+}
+
+func init() {
+	defineStatementMethods(dbMethods, "sqlite_db")
+	defineStatementMethods(txMethods, "sqlite_tx")
+
+	dbMethods.Define("transaction").
+		Doc("Call a function with a transaction, committing if it returns normally").
+		Arg("fn").
+		Returns("object").
+		Impl(func(db *DB, ctx context.Context, args ...object.Object) (object.Object, error) {
+			fn, ok := args[0].(object.Callable)
+			if !ok {
+				return nil, object.TypeErrorf("sqlite_db.transaction() expected a function (%s given)", args[0].Type())
+			}
+			return db.Transaction(ctx, fn)
+		})
+
+	dbMethods.Define("close").
+		Doc("Close the database").
+		Returns("null").
+		Impl(func(db *DB, ctx context.Context, args ...object.Object) (object.Object, error) {
+			if err := db.Close(); err != nil {
+				return nil, fmt.Errorf("sqlite_db.close: %w", err)
+			}
+			return object.Nil, nil
+		})
+}
+
+// statement splits the arguments of query or exec into the SQL text and its
+// parameters. A single map argument supplies named parameters such as
+// :name; otherwise the arguments fill ? placeholders in order.
+func statement(fn string, args []object.Object) (string, []any, error) {
+	query, err := object.AsString(args[0])
+	if err != nil {
+		return "", nil, err
+	}
+	if len(args) == 2 {
+		if m, ok := args[1].(*object.Map); ok {
+			var params []any
+			if err := m.CheckStringKeys(); err != nil {
+				return "", nil, fmt.Errorf("%s: %w", fn, err)
+			}
+			for _, name := range m.SortedKeys() {
+				value, err := param(fn, m.Get(name))
+				if err != nil {
+					return "", nil, err
+				}
+				params = append(params, sql.Named(name, value))
+			}
+			return query, params, nil
+		}
+	}
+	params := make([]any, len(args)-1)
+	for i, arg := range args[1:] {
+		if params[i], err = param(fn, arg); err != nil {
+			return "", nil, err
+		}
+	}
+	return query, params, nil
+}
+
+// param converts a script value to a statement parameter.
+func param(fn string, value object.Object) (any, error) {
+	switch value := value.(type) {
+	case *object.NilType:
+		return nil, nil
+	case *object.Bool:
+		return value.Value(), nil
+	case *object.Int:
+		return value.Value(), nil
+	case *object.Byte:
+		return int64(value.Value()), nil
+	case *object.Float:
+		return value.Value(), nil
+	case *object.String:
+		return value.Value(), nil
+	case *object.Bytes:
+		return value.Value(), nil
+	case *object.Time:
+		return value.Value(), nil
+	default:
+		return nil, object.TypeErrorf("%s: unsupported parameter type %s", fn, value.Type())
+	}
+}
+
+// readRows converts each row to a map from column name to value and closes
+// rows.
+func readRows(rows *sql.Rows) (*object.List, error) {
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	var items []object.Object
+	values := make([]any, len(columns))
+	dest := make([]any, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		row := make(map[string]object.Object, len(columns))
+		for i, column := range columns {
+			row[column] = fromSQL(values[i])
+		}
+		items = append(items, object.NewMap(row))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return object.NewList(items), nil
+}
+
+// fromSQL converts a column value to a script value.
+func fromSQL(value any) object.Object {
+	switch value := value.(type) {
+	case nil:
+		return object.Nil
+	case int64:
+		return object.NewInt(value)
+	case float64:
+		return object.NewFloat(value)
+	case bool:
+		return object.NewBool(value)
+	case string:
+		return object.NewString(value)
+	case []byte:
+		return object.NewBytes(append([]byte(nil), value...))
+	case time.Time:
+		return object.NewTime(value)
+	default:
+		return object.NewString(fmt.Sprint(value))
+	}
+}
+
+// activeKey marks the context of a transaction callback.
+type activeKey struct{}
+
+// DB is a database opened by sqlite.open.
+type DB struct {
+	name string
+	db   *sql.DB
+
+	mu     sync.Mutex
+	closed bool
+}
+
+// NewDB wraps an open database. Hosts can use it to give scripts a
+// database they opened themselves.
+func NewDB(name string, db *sql.DB) *DB {
+	return &DB{name: name, db: db}
+}
+
+func (db *DB) Type() object.Type {
+	return DATABASE
+}
+
+func (db *DB) Inspect() string {
+	return fmt.Sprintf("sqlite_db(%q)", db.name)
+}
+
+func (db *DB) String() string {
+	return db.Inspect()
+}
+
+func (db *DB) Interface() any {
+	return db.db
+}
+
+func (db *DB) Equals(other object.Object) bool {
+	return db == other
+}
+
+func (db *DB) Attrs() []object.AttrSpec {
+	return dbMethods.Specs()
+}
+
+func (db *DB) GetAttr(name string) (object.Object, bool) {
+	return dbMethods.GetAttr(db, name)
+}
+
+func (db *DB) SetAttr(name string, value object.Object) error {
+	return object.TypeErrorf("sqlite_db has no attribute %q", name)
+}
+
+func (db *DB) IsTruthy() bool {
+	return true
+}
+
+func (db *DB) RunOperation(opType op.BinaryOpType, right object.Object) (object.Object, error) {
+	return nil, object.TypeErrorf("unsupported operation for sqlite_db: %v", opType)
+}
+
+// Close closes the database. Closing it again does nothing.
+func (db *DB) Close() error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if db.closed {
+		return nil
+	}
+	db.closed = true
+	return db.db.Close()
+}
+
+func (db *DB) conn(ctx context.Context, method string) (conn, error) {
+	db.mu.Lock()
+	closed := db.closed
+	db.mu.Unlock()
+	if closed {
+		return nil, fmt.Errorf("sqlite_db.%s: %s is closed", method, db.Inspect())
+	}
+	// The transaction holds the only connection, so a statement run outside
+	// it would wait forever.
+	if ctx.Value(activeKey{}) == db {
+		return nil, fmt.Errorf("sqlite_db.%s: called inside a transaction; use the transaction passed to the function", method)
+	}
+	return db.db, nil
+}
+
+// Transaction calls fn with a transaction and returns its result. The
+// transaction is committed if fn returns normally and rolled back if it
+// fails, in which case its error is returned unchanged.
+func (db *DB) Transaction(ctx context.Context, fn object.Callable) (object.Object, error) {
+	if _, err := db.conn(ctx, "transaction"); err != nil {
+		return nil, err
+	}
+	sqlTx, err := db.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite_db.transaction: %w", err)
+	}
+	tx := &Tx{tx: sqlTx}
+	result, err := fn.Call(context.WithValue(ctx, activeKey{}, db), tx)
+	tx.done.Store(true)
+	if err != nil {
+		sqlTx.Rollback()
+		return nil, err
+	}
+	if err := sqlTx.Commit(); err != nil {
+		return nil, fmt.Errorf("sqlite_db.transaction: %w", err)
+	}
+	return result, nil
+}
+
+// Tx is the transaction passed to the function given to a database's
+// transaction method.
+type Tx struct {
+	tx   *sql.Tx
+	done atomic.Bool
+}
+
+func (tx *Tx) Type() object.Type {
+	return TRANSACTION
+}
+
+func (tx *Tx) Inspect() string {
+	return "sqlite_tx()"
+}
+
+func (tx *Tx) String() string {
+	return tx.Inspect()
+}
+
+func (tx *Tx) Interface() any {
+	return tx.tx
+}
+
+func (tx *Tx) Equals(other object.Object) bool {
+	return tx == other
+}
+
+func (tx *Tx) Attrs() []object.AttrSpec {
+	return txMethods.Specs()
+}
+
+func (tx *Tx) GetAttr(name string) (object.Object, bool) {
+	return txMethods.GetAttr(tx, name)
+}
+
+func (tx *Tx) SetAttr(name string, value object.Object) error {
+	return object.TypeErrorf("sqlite_tx has no attribute %q", name)
+}
+
+func (tx *Tx) IsTruthy() bool {
+	return true
+}
+
+func (tx *Tx) RunOperation(opType op.BinaryOpType, right object.Object) (object.Object, error) {
+	return nil, object.TypeErrorf("unsupported operation for sqlite_tx: %v", opType)
+}
+
+func (tx *Tx) conn(ctx context.Context, method string) (conn, error) {
+	if tx.done.Load() {
+		return nil, fmt.Errorf("sqlite_tx.%s: the transaction has ended", method)
+	}
+	return tx.tx, nil
+}
@@ -0,0 +1,21 @@
+package sqlite
+
+import "github.com/deepnoodle-ai/risor/v2/pkg/object"
+
+func init() {
+	object.RegisterModule("sqlite", ModuleDoc(), Docs)
+}
+
+// Docs returns documentation for the sqlite module.
+func Docs() []object.FuncSpec {
+	return sqliteDocs
+}
+
+// ModuleDoc returns the module-level documentation.
+func ModuleDoc() string {
+	return "Query and update SQLite databases"
+}
+
+var sqliteDocs = []object.FuncSpec{
+	{Name: "open", Doc: "Open a database file, or an in-memory database with \":memory:\"", Args: []string{"name"}, Returns: "sqlite_db"},
+}
@@ -0,0 +1,100 @@
+// Package sqlite provides the sqlite module, which runs SQL against SQLite
+// databases using modernc.org/sqlite, a pure Go driver that needs no cgo.
+//
+// The module is not part of the default environment. By default scripts
+// can only open in-memory databases; WithDir lets them open database files
+// under a directory the host chooses.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+	_ "modernc.org/sqlite"
+)
+
+// Memory is the name of an in-memory database, which is empty when opened
+// and discarded when closed.
+const Memory = ":memory:"
+
+// Option configures the sqlite module.
+type Option func(*config)
+
+type config struct {
+	dir string
+}
+
+// WithDir lets scripts open database files in dir, named by paths relative
+// to it. Absolute paths and paths that climb out of dir with ".." are
+// rejected.
+func WithDir(dir string) Option {
+	return func(c *config) {
+		c.dir = dir
+	}
+}
+
+// path returns the data source for the database a script named.
+func (c *config) path(name string) (string, error) {
+	if name == Memory {
+		return Memory, nil
+	}
+	if c.dir == "" {
+		return "", fmt.Errorf("only %q databases are enabled by the host", Memory)
+	}
+	if !filepath.IsLocal(name) {
+		return "", fmt.Errorf("invalid database name %q: must be a relative path within the database directory", name)
+	}
+	return filepath.Join(c.dir, name), nil
+}
+
+func (c *config) open(ctx context.Context, args ...object.Object) (object.Object, error) {
+	if len(args) != 1 {
+		return nil, object.NewArgsError("sqlite.open", 1, len(args))
+	}
+	name, err := object.AsString(args[0])
+	if err != nil {
+		return nil, err
+	}
+	path, err := c.path(name)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite.open: %w", err)
+	}
+	sqlDB, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite.open: %w", err)
+	}
+	// Each connection to ":memory:" is a separate database, and a single
+	// connection keeps a script's statements in the order it wrote them.
+	sqlDB.SetMaxOpenConns(1)
+	if err := sqlDB.PingContext(ctx); err != nil {
+		sqlDB.Close()
+		return nil, fmt.Errorf("sqlite.open: %w", err)
+	}
+	db := NewDB(name, sqlDB)
+	// Close databases the script leaves open when the run ends.
+	if register, ok := object.GetExitHookFunc(ctx); ok {
+		closeOnExit := object.NewBuiltin("sqlite.close", func(ctx context.Context, args ...object.Object) (object.Object, error) {
+			return object.Nil, db.Close()
+		})
+		if err := register(closeOnExit); err != nil {
+			db.Close()
+			return nil, err
+		}
+	}
+	return db, nil
+}
+
+// Module returns the sqlite module. Options may let scripts open database
+// files.
+func Module(opts ...Option) *object.Module {
+	c := &config{}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return object.NewBuiltinsModule("sqlite", map[string]object.Object{
+		"open": object.NewBuiltin("open", c.open),
+	})
+}
@@ -0,0 +1,106 @@
+package sqlite
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/deepnoodle-ai/risor/v2/internal/moduletest"
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+// eval runs source with the given sqlite module and the default builtins.
+func eval(module *object.Module, source string) (object.Object, error) {
+	return moduletest.Eval(context.Background(), source, map[string]any{"sqlite": module})
+}
+
+func TestQuery(t *testing.T) {
+	result, err := eval(Module(), `
+	let db = sqlite.open(":memory:")
+	db.exec("create table items (id integer primary key, name text, price real, data blob)")
+	let first = db.exec("insert into items (name, price) values (?, ?)", "apple", 1.5)
+	db.exec("insert into items (name, price, data) values (:name, :price, :data)",
+		{name: "pear", price: 2, data: bytes("xy")})
+	let changed = db.exec("update items set price = price * 2")
+	[
+		first,
+		changed["rows_affected"],
+		db.query("select id, name, price, data from items order by id"),
+		db.query("select count(*) as n from items where price > ?", 3),
+		db.query("select * from items where name = ?", "plum"),
+	]
+	`)
+	assert.Nil(t, err)
+	assert.Equal(t, result.Inspect(), `[{"last_insert_id": 1, "rows_affected": 1}, 2, `+
+		`[{"data": null, "id": 1, "name": "apple", "price": 3}, `+
+		`{"data": bytes("xy"), "id": 2, "name": "pear", "price": 4}], [{"n": 1}], []]`)
+}
+
+func TestTransaction(t *testing.T) {
+	result, err := eval(Module(), `
+	let db = sqlite.open(":memory:")
+	db.exec("create table counts (name text primary key, n integer)")
+	let total = db.transaction(tx => {
+		tx.exec("insert into counts values ('a', 1)")
+		tx.exec("insert into counts values ('b', 2)")
+		return tx.query("select sum(n) as total from counts")[0]["total"]
+	})
+	let message = try {
+		db.transaction(tx => {
+			tx.exec("delete from counts")
+			throw "abort"
+		})
+	} catch e {
+		e.message()
+	}
+	[total, message, db.query("select name from counts order by name")]
+	`)
+	assert.Nil(t, err)
+	assert.Equal(t, result.Inspect(), `[3, "abort", [{"name": "a"}, {"name": "b"}]]`)
+}
+
+func TestErrors(t *testing.T) {
+	tests := []struct {
+		source string
+		want   string
+	}{
+		{`sqlite.open("data.db")`, `sqlite.open: only ":memory:" databases are enabled by the host`},
+		{`sqlite.open(":memory:").query("select * from missing")`, "sqlite_db.query: SQL logic error: no such table: missing"},
+		{`sqlite.open(":memory:").exec("select ?", [1])`, "sqlite_db.exec: unsupported parameter type list"},
+		{`let db = sqlite.open(":memory:"); db.transaction(tx => db.query("select 1"))`, "sqlite_db.query: called inside a transaction"},
+		{`let db = sqlite.open(":memory:"); let saved = nil; db.transaction(tx => { saved = tx }); saved.query("select 1")`, "sqlite_tx.query: the transaction has ended"},
+		{`let db = sqlite.open(":memory:"); db.close(); db.exec("select 1")`, `sqlite_db.exec: sqlite_db(":memory:") is closed`},
+		{`sqlite.open(":memory:").transaction(1)`, "expected a function"},
+	}
+	for _, tt := range tests {
+		_, err := eval(Module(), tt.source)
+		assert.NotNil(t, err)
+		assert.Contains(t, err.Error(), tt.want)
+	}
+}
+
+func TestDir(t *testing.T) {
+	dir := t.TempDir()
+	module := Module(WithDir(dir))
+	_, err := eval(module, `
+	let db = sqlite.open("data.db")
+	db.exec("create table runs (at text)")
+	db.exec("insert into runs values ('2026-10-16')")
+	`)
+	assert.Nil(t, err)
+	_, err = os.Stat(filepath.Join(dir, "data.db"))
+	assert.Nil(t, err)
+
+	result, err := eval(module, `sqlite.open("data.db").query("select at from runs")`)
+	assert.Nil(t, err)
+	assert.Equal(t, result.Inspect(), `[{"at": "2026-10-16"}]`)
+
+	c := &config{dir: dir}
+	for _, name := range []string{"../escape.db", "/tmp/abs.db", "", "a/../../b.db"} {
+		_, err := c.path(name)
+		assert.NotNil(t, err)
+		assert.Contains(t, err.Error(), "invalid database name")
+	}
+}
@@ -0,0 +1,31 @@
+package stats
+
+import "github.com/deepnoodle-ai/risor/v2/pkg/object"
+
+func init() {
+	object.RegisterModule("stats", ModuleDoc(), Docs)
+}
+
+// Docs returns documentation for the stats module.
+func Docs() []object.FuncSpec {
+	return statsDocs
+}
+
+// ModuleDoc returns the module-level documentation.
+func ModuleDoc() string {
+	return "Descriptive statistics, percentiles, histograms, and regression"
+}
+
+var statsDocs = []object.FuncSpec{
+	{Name: "correlation", Doc: "Pearson correlation coefficient of two lists", Args: []string{"xs", "ys"}, Returns: "float"},
+	{Name: "covariance", Doc: "Sample covariance of two lists", Args: []string{"xs", "ys"}, Returns: "float"},
+	{Name: "describe", Doc: "Count, mean, stddev, min, quartiles, and max of a list", Args: []string{"values"}, Returns: "map"},
+	{Name: "histogram", Doc: "Count values falling into bins", Args: []string{"values", "bins?"}, Returns: "list"},
+	{Name: "linear_regression", Doc: "Least squares fit of y = slope * x + intercept", Args: []string{"xs", "ys"}, Returns: "map"},
+	{Name: "mean", Doc: "Arithmetic mean", Args: []string{"values"}, Returns: "float"},
+	{Name: "median", Doc: "Middle value, or the mean of the two middle values", Args: []string{"values"}, Returns: "float"},
+	{Name: "mode", Doc: "Most common value", Args: []string{"values"}, Returns: "any"},
+	{Name: "percentile", Doc: "Percentile (0-100) with linear interpolation", Args: []string{"values", "p"}, Returns: "float"},
+	{Name: "stddev", Doc: "Sample standard deviation, or population if population is true", Args: []string{"values", "population?"}, Returns: "float"},
+	{Name: "variance", Doc: "Sample variance, or population if population is true", Args: []string{"values", "population?"}, Returns: "float"},
+}
@@ -0,0 +1,421 @@
+package stats
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+)
+
+// floats converts a list of numbers to float64 values. At least min values
+// are required.
+func floats(name string, arg object.Object, min int) ([]float64, error) {
+	list, ok := arg.(*object.List)
+	if !ok {
+		return nil, object.TypeErrorf("stats.%s: expected list, got %s", name, arg.Type())
+	}
+	items := list.Value()
+	if len(items) < min {
+		if min == 1 {
+			return nil, object.ValueErrorf("stats.%s: requires at least one value", name)
+		}
+		return nil, object.ValueErrorf("stats.%s: requires at least %d values", name, min)
+	}
+	values := make([]float64, len(items))
+	for i, item := range items {
+		switch item := item.(type) {
+		case *object.Int:
+			values[i] = float64(item.Value())
+		case *object.Float:
+			values[i] = item.Value()
+		default:
+			return nil, object.TypeErrorf("stats.%s: expected numbers, got %s", name, item.Type())
+		}
+	}
+	return values, nil
+}
+
+// pairs converts two lists of numbers of equal length to float64 values.
+func pairs(name string, args []object.Object, min int) ([]float64, []float64, error) {
+	if len(args) != 2 {
+		return nil, nil, fmt.Errorf("stats.%s: expected 2 arguments, got %d", name, len(args))
+	}
+	xs, err := floats(name, args[0], min)
+	if err != nil {
+		return nil, nil, err
+	}
+	ys, err := floats(name, args[1], min)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(xs) != len(ys) {
+		return nil, nil, object.ValueErrorf("stats.%s: lists have different lengths (%d and %d)", name, len(xs), len(ys))
+	}
+	return xs, ys, nil
+}
+
+func populationArg(name string, args []object.Object) (bool, error) {
+	if len(args) < 1 || len(args) > 2 {
+		return false, fmt.Errorf("stats.%s: expected 1 or 2 arguments, got %d", name, len(args))
+	}
+	if len(args) == 1 {
+		return false, nil
+	}
+	return object.AsBool(args[1])
+}
+
+func mean(values []float64) float64 {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+func variance(values []float64, population bool) float64 {
+	m := mean(values)
+	var ss float64
+	for _, v := range values {
+		ss += (v - m) * (v - m)
+	}
+	if population {
+		return ss / float64(len(values))
+	}
+	return ss / float64(len(values)-1)
+}
+
+func sorted(values []float64) []float64 {
+	s := append([]float64{}, values...)
+	sort.Float64s(s)
+	return s
+}
+
+// percentile interpolates linearly between the closest ranks of sorted
+// values, so the 50th percentile of an even-length list is the mean of the
+// two middle values.
+func percentile(sorted []float64, p float64) float64 {
+	rank := p / 100 * float64(len(sorted)-1)
+	lo := int(math.Floor(rank))
+	hi := int(math.Ceil(rank))
+	return sorted[lo] + (sorted[hi]-sorted[lo])*(rank-float64(lo))
+}
+
+func percentileArg(name string, arg object.Object) (float64, error) {
+	p, err := object.AsFloat(arg)
+	if err != nil {
+		return 0, err
+	}
+	if p < 0 || p > 100 || math.IsNaN(p) {
+		return 0, object.ValueErrorf("stats.%s: percentile must be between 0 and 100 (got %v)", name, p)
+	}
+	return p, nil
+}
+
+// Mean returns the arithmetic mean of a list of numbers.
+func Mean(ctx context.Context, args ...object.Object) (object.Object, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("stats.mean: expected 1 argument, got %d", len(args))
+	}
+	values, err := floats("mean", args[0], 1)
+	if err != nil {
+		return nil, err
+	}
+	return object.NewFloat(mean(values)), nil
+}
+
+// Median returns the middle value of a list of numbers, or the mean of the
+// two middle values if the list has an even length.
+func Median(ctx context.Context, args ...object.Object) (object.Object, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("stats.median: expected 1 argument, got %d", len(args))
+	}
+	values, err := floats("median", args[0], 1)
+	if err != nil {
+		return nil, err
+	}
+	return object.NewFloat(percentile(sorted(values), 50)), nil
+}
+
+// Mode returns the most common value in a list. Ties go to the value that
+// appears first. The values need not be numbers.
+func Mode(ctx context.Context, args ...object.Object) (object.Object, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("stats.mode: expected 1 argument, got %d", len(args))
+	}
+	list, ok := args[0].(*object.List)
+	if !ok {
+		return nil, object.TypeErrorf("stats.mode: expected list, got %s", args[0].Type())
+	}
+	items := list.Value()
+	if len(items) == 0 {
+		return nil, object.ValueErrorf("stats.mode: requires at least one value")
+	}
+	counts := map[string]int{}
+	for _, item := range items {
+		counts[modeKey(item)]++
+	}
+	best, bestCount := items[0], 0
+	for _, item := range items {
+		if count := counts[modeKey(item)]; count > bestCount {
+			best, bestCount = item, count
+		}
+	}
+	return best, nil
+}
+
+// modeKey identifies equal values. A whole float counts as the equal int.
+func modeKey(item object.Object) string {
+	if f, ok := item.(*object.Float); ok && f.Value() == math.Trunc(f.Value()) && math.Abs(f.Value()) < 1<<63 {
+		item = object.NewInt(int64(f.Value()))
+	}
+	return string(item.Type()) + ":" + item.Inspect()
+}
+
+// Variance returns the sample variance of a list of numbers, or the
+// population variance if the second argument is true.
+func Variance(ctx context.Context, args ...object.Object) (object.Object, error) {
+	population, err := populationArg("variance", args)
+	if err != nil {
+		return nil, err
+	}
+	min := 2
+	if population {
+		min = 1
+	}
+	values, err := floats("variance", args[0], min)
+	if err != nil {
+		return nil, err
+	}
+	return object.NewFloat(variance(values, population)), nil
+}
+
+// Stddev returns the sample standard deviation of a list of numbers, or the
+// population standard deviation if the second argument is true.
+func Stddev(ctx context.Context, args ...object.Object) (object.Object, error) {
+	population, err := populationArg("stddev", args)
+	if err != nil {
+		return nil, err
+	}
+	min := 2
+	if population {
+		min = 1
+	}
+	values, err := floats("stddev", args[0], min)
+	if err != nil {
+		return nil, err
+	}
+	return object.NewFloat(math.Sqrt(variance(values, population))), nil
+}
+
+// Percentile returns the pth percentile of a list of numbers, for p from 0
+// to 100. If p is a list, it returns a list with one percentile per entry.
+func Percentile(ctx context.Context, args ...object.Object) (object.Object, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("stats.percentile: expected 2 arguments, got %d", len(args))
+	}
+	values, err := floats("percentile", args[0], 1)
+	if err != nil {
+		return nil, err
+	}
+	s := sorted(values)
+	if ps, ok := args[1].(*object.List); ok {
+		results := make([]object.Object, len(ps.Value()))
+		for i, arg := range ps.Value() {
+			p, err := percentileArg("percentile", arg)
+			if err != nil {
+				return nil, err
+			}
+			results[i] = object.NewFloat(percentile(s, p))
+		}
+		return object.NewList(results), nil
+	}
+	p, err := percentileArg("percentile", args[1])
+	if err != nil {
+		return nil, err
+	}
+	return object.NewFloat(percentile(s, p)), nil
+}
+
+// Histogram counts the values of a list of numbers falling into bins. The
+// bins are either a count of equal-width bins spanning the values (10 by
+// default) or a list of bin edges. Each bin includes its lower edge, and the
+// last also includes its upper edge. It returns a list of maps with the
+// keys low, high, and count.
+func Histogram(ctx context.Context, args ...object.Object) (object.Object, error) {
+	if len(args) < 1 || len(args) > 2 {
+		return nil, fmt.Errorf("stats.histogram: expected 1 or 2 arguments, got %d", len(args))
+	}
+	values, err := floats("histogram", args[0], 1)
+	if err != nil {
+		return nil, err
+	}
+	var edges []float64
+	if len(args) == 2 {
+		if _, ok := args[1].(*object.List); ok {
+			if edges, err = floats("histogram", args[1], 2); err != nil {
+				return nil, err
+			}
+			for i := 1; i < len(edges); i++ {
+				if edges[i] <= edges[i-1] {
+					return nil, object.ValueErrorf("stats.histogram: bin edges must be increasing")
+				}
+			}
+		}
+	}
+	if edges == nil {
+		bins := int64(10)
+		if len(args) == 2 {
+			if bins, err = object.AsInt(args[1]); err != nil {
+				return nil, err
+			}
+			if bins < 1 || bins > 10000 {
+				return nil, object.ValueErrorf("stats.histogram: bins must be between 1 and 10000 (got %d)", bins)
+			}
+		}
+		s := sorted(values)
+		lo, hi := s[0], s[len(s)-1]
+		if lo == hi {
+			lo, hi = lo-0.5, hi+0.5
+		}
+		edges = make([]float64, bins+1)
+		for i := range edges {
+			edges[i] = lo + (hi-lo)*float64(i)/float64(bins)
+		}
+		edges[bins] = hi
+	}
+	counts := make([]int64, len(edges)-1)
+	last := len(edges) - 1
+	for _, v := range values {
+		if v < edges[0] || v > edges[last] {
+			continue
+		}
+		// The first edge above v closes the bin v falls in
+		i := sort.Search(len(edges), func(i int) bool { return edges[i] > v })
+		if i > last {
+			i = last
+		}
+		counts[i-1]++
+	}
+	bins := make([]object.Object, len(counts))
+	for i, count := range counts {
+		bins[i] = object.NewMap(map[string]object.Object{
+			"low":   object.NewFloat(edges[i]),
+			"high":  object.NewFloat(edges[i+1]),
+			"count": object.NewInt(count),
+		})
+	}
+	return object.NewList(bins), nil
+}
+
+// Covariance returns the sample covariance of two lists of numbers.
+func Covariance(ctx context.Context, args ...object.Object) (object.Object, error) {
+	xs, ys, err := pairs("covariance", args, 2)
+	if err != nil {
+		return nil, err
+	}
+	mx, my := mean(xs), mean(ys)
+	var sum float64
+	for i := range xs {
+		sum += (xs[i] - mx) * (ys[i] - my)
+	}
+	return object.NewFloat(sum / float64(len(xs)-1)), nil
+}
+
+// Correlation returns the Pearson correlation coefficient of two lists of
+// numbers.
+func Correlation(ctx context.Context, args ...object.Object) (object.Object, error) {
+	xs, ys, err := pairs("correlation", args, 2)
+	if err != nil {
+		return nil, err
+	}
+	mx, my := mean(xs), mean(ys)
+	var sxy, sxx, syy float64
+	for i := range xs {
+		dx, dy := xs[i]-mx, ys[i]-my
+		sxy += dx * dy
+		sxx += dx * dx
+		syy += dy * dy
+	}
+	if sxx == 0 || syy == 0 {
+		return nil, object.ValueErrorf("stats.correlation: a list has no variation")
+	}
+	return object.NewFloat(sxy / math.Sqrt(sxx*syy)), nil
+}
+
+// LinearRegression fits y = slope * x + intercept by least squares. It
+// returns a map with the keys slope, intercept, and r2, the coefficient of
+// determination.
+func LinearRegression(ctx context.Context, args ...object.Object) (object.Object, error) {
+	xs, ys, err := pairs("linear_regression", args, 2)
+	if err != nil {
+		return nil, err
+	}
+	mx, my := mean(xs), mean(ys)
+	var sxy, sxx, syy float64
+	for i := range xs {
+		dx, dy := xs[i]-mx, ys[i]-my
+		sxy += dx * dy
+		sxx += dx * dx
+		syy += dy * dy
+	}
+	if sxx == 0 {
+		return nil, object.ValueErrorf("stats.linear_regression: x values have no variation")
+	}
+	slope := sxy / sxx
+	r2 := 1.0
+	if syy != 0 {
+		r2 = sxy * sxy / (sxx * syy)
+	}
+	return object.NewMap(map[string]object.Object{
+		"slope":     object.NewFloat(slope),
+		"intercept": object.NewFloat(my - slope*mx),
+		"r2":        object.NewFloat(r2),
+	}), nil
+}
+
+// Describe summarizes a list of numbers in a map with the keys count,
+// mean, stddev, min, p25, median, p75, and max. The standard deviation is
+// the sample standard deviation, or 0 for a single value.
+func Describe(ctx context.Context, args ...object.Object) (object.Object, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("stats.describe: expected 1 argument, got %d", len(args))
+	}
+	values, err := floats("describe", args[0], 1)
+	if err != nil {
+		return nil, err
+	}
+	s := sorted(values)
+	stddev := 0.0
+	if len(values) > 1 {
+		stddev = math.Sqrt(variance(values, false))
+	}
+	return object.NewMap(map[string]object.Object{
+		"count":  object.NewInt(int64(len(values))),
+		"mean":   object.NewFloat(mean(values)),
+		"stddev": object.NewFloat(stddev),
+		"min":    object.NewFloat(s[0]),
+		"p25":    object.NewFloat(percentile(s, 25)),
+		"median": object.NewFloat(percentile(s, 50)),
+		"p75":    object.NewFloat(percentile(s, 75)),
+		"max":    object.NewFloat(s[len(s)-1]),
+	}), nil
+}
+
+// Module returns the stats module.
+func Module() *object.Module {
+	return object.NewBuiltinsModule("stats", map[string]object.Object{
+		"correlation":       object.NewBuiltin("correlation", Correlation),
+		"covariance":        object.NewBuiltin("covariance", Covariance),
+		"describe":          object.NewBuiltin("describe", Describe),
+		"histogram":         object.NewBuiltin("histogram", Histogram),
+		"linear_regression": object.NewBuiltin("linear_regression", LinearRegression),
+		"mean":              object.NewBuiltin("mean", Mean),
+		"median":            object.NewBuiltin("median", Median),
+		"mode":              object.NewBuiltin("mode", Mode),
+		"percentile":        object.NewBuiltin("percentile", Percentile),
+		"stddev":            object.NewBuiltin("stddev", Stddev),
+		"variance":          object.NewBuiltin("variance", Variance),
+	})
+}
@@ -0,0 +1,195 @@
+package stats
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+func nums(values ...float64) *object.List {
+	items := make([]object.Object, len(values))
+	for i, v := range values {
+		if v == math.Trunc(v) {
+			items[i] = object.NewInt(int64(v))
+		} else {
+			items[i] = object.NewFloat(v)
+		}
+	}
+	return object.NewList(items)
+}
+
+func assertFloat(t *testing.T, got object.Object, want float64) {
+	t.Helper()
+	f, ok := got.(*object.Float)
+	assert.True(t, ok, "got %s, want float", got.Type())
+	assert.True(t, math.Abs(f.Value()-want) < 1e-9, "got %v, want %v", f.Value(), want)
+}
+
+func TestCentralTendency(t *testing.T) {
+	ctx := context.Background()
+	values := nums(2, 4, 4, 4, 5, 5, 7, 9)
+
+	result, err := Mean(ctx, values)
+	assert.Nil(t, err)
+	assertFloat(t, result, 5)
+
+	result, err = Median(ctx, values)
+	assert.Nil(t, err)
+	assertFloat(t, result, 4.5)
+
+	result, err = Median(ctx, nums(3, 1, 2))
+	assert.Nil(t, err)
+	assertFloat(t, result, 2)
+
+	result, err = Mode(ctx, values)
+	assert.Nil(t, err)
+	assert.Equal(t, result, object.NewInt(4))
+
+	result, err = Mode(ctx, object.NewList([]object.Object{
+		object.NewString("b"), object.NewString("a"), object.NewString("a"), object.NewString("b"),
+	}))
+	assert.Nil(t, err)
+	assert.Equal(t, result, object.NewString("b"))
+
+	result, err = Mode(ctx, object.NewList([]object.Object{object.NewInt(1), object.NewFloat(2), object.NewInt(2)}))
+	assert.Nil(t, err)
+	assert.Equal(t, result, object.NewFloat(2))
+}
+
+func TestSpread(t *testing.T) {
+	ctx := context.Background()
+	values := nums(2, 4, 4, 4, 5, 5, 7, 9)
+
+	result, err := Stddev(ctx, values, object.True)
+	assert.Nil(t, err)
+	assertFloat(t, result, 2)
+
+	result, err = Variance(ctx, values)
+	assert.Nil(t, err)
+	assertFloat(t, result, 32.0/7)
+
+	result, err = Stddev(ctx, values)
+	assert.Nil(t, err)
+	assertFloat(t, result, math.Sqrt(32.0/7))
+
+	_, err = Stddev(ctx, nums(1))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "requires at least 2 values")
+
+	result, err = Variance(ctx, nums(1), object.True)
+	assert.Nil(t, err)
+	assertFloat(t, result, 0)
+}
+
+func TestPercentile(t *testing.T) {
+	ctx := context.Background()
+	values := nums(15, 20, 35, 40, 50)
+
+	result, err := Percentile(ctx, values, object.NewInt(40))
+	assert.Nil(t, err)
+	assertFloat(t, result, 29)
+
+	result, err = Percentile(ctx, values, nums(0, 100))
+	assert.Nil(t, err)
+	assert.Equal(t, result.Inspect(), "[15, 50]")
+
+	_, err = Percentile(ctx, values, object.NewInt(101))
+	assert.Error(t, err)
+
+	result, err = Describe(ctx, nums(1, 2, 3, 4))
+	assert.Nil(t, err)
+	m := result.(*object.Map)
+	assert.Equal(t, m.Get("count"), object.NewInt(4))
+	assertFloat(t, m.Get("mean"), 2.5)
+	assertFloat(t, m.Get("p25"), 1.75)
+	assertFloat(t, m.Get("median"), 2.5)
+	assertFloat(t, m.Get("p75"), 3.25)
+	assertFloat(t, m.Get("max"), 4)
+
+	result, err = Describe(ctx, nums(7))
+	assert.Nil(t, err)
+	assertFloat(t, result.(*object.Map).Get("stddev"), 0)
+}
+
+func TestHistogram(t *testing.T) {
+	ctx := context.Background()
+	result, err := Histogram(ctx, nums(0, 1, 2, 3, 4, 5, 6, 7, 8, 10), object.NewInt(5))
+	assert.Nil(t, err)
+	bins := result.(*object.List).Value()
+	assert.Len(t, bins, 5)
+	counts := make([]int64, len(bins))
+	for i, bin := range bins {
+		counts[i] = bin.(*object.Map).Get("count").(*object.Int).Value()
+	}
+	assert.Equal(t, counts, []int64{2, 2, 2, 2, 2})
+	assert.Equal(t, bins[4].Inspect(), `{"count": 2, "high": 10, "low": 8}`)
+
+	result, err = Histogram(ctx, nums(-1, 0, 0.5, 1, 2, 5), nums(0, 1, 2))
+	assert.Nil(t, err)
+	assert.Equal(t, result.Inspect(), `[{"count": 2, "high": 1, "low": 0}, {"count": 2, "high": 2, "low": 1}]`)
+
+	result, err = Histogram(ctx, nums(3, 3))
+	assert.Nil(t, err)
+	assert.Len(t, result.(*object.List).Value(), 10)
+
+	_, err = Histogram(ctx, nums(1), nums(2, 1))
+	assert.Error(t, err)
+	_, err = Histogram(ctx, nums(1), object.NewInt(0))
+	assert.Error(t, err)
+}
+
+func TestRelationships(t *testing.T) {
+	ctx := context.Background()
+	xs := nums(1, 2, 3, 4, 5)
+	ys := nums(3, 5, 7, 9, 11)
+
+	result, err := Correlation(ctx, xs, ys)
+	assert.Nil(t, err)
+	assertFloat(t, result, 1)
+
+	result, err = Correlation(ctx, xs, nums(5, 4, 3, 2, 1))
+	assert.Nil(t, err)
+	assertFloat(t, result, -1)
+
+	result, err = Covariance(ctx, xs, ys)
+	assert.Nil(t, err)
+	assertFloat(t, result, 5)
+
+	result, err = LinearRegression(ctx, xs, ys)
+	assert.Nil(t, err)
+	fit := result.(*object.Map)
+	assertFloat(t, fit.Get("slope"), 2)
+	assertFloat(t, fit.Get("intercept"), 1)
+	assertFloat(t, fit.Get("r2"), 1)
+
+	result, err = LinearRegression(ctx, nums(1, 2, 3), nums(1, 3, 2))
+	assert.Nil(t, err)
+	assertFloat(t, result.(*object.Map).Get("r2"), 0.25)
+
+	_, err = Correlation(ctx, xs, nums(1, 2))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "different lengths")
+	_, err = Correlation(ctx, xs, nums(1, 1, 1, 1, 1))
+	assert.Error(t, err)
+	_, err = LinearRegression(ctx, nums(1, 1), nums(1, 2))
+	assert.Error(t, err)
+}
+
+func TestErrors(t *testing.T) {
+	ctx := context.Background()
+	_, err := Mean(ctx, nums())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "stats.mean: requires at least one value")
+	_, err = Mean(ctx, object.NewList([]object.Object{object.NewString("x")}))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "expected numbers, got string")
+	_, err = Median(ctx, object.NewInt(1))
+	assert.Error(t, err)
+	_, err = Mode(ctx, nums())
+	assert.Error(t, err)
+	_, err = Mean(ctx)
+	assert.Error(t, err)
+}
@@ -0,0 +1,193 @@
+// Package bolt provides a store.Backend that keeps each database in a bbolt
+// file.
+//
+// Scripts name databases by paths relative to a directory the host
+// chooses. Absolute paths and paths that climb out of the directory with
+// ".." are rejected; symbolic links inside the directory are followed, so
+// the host should not place links there that it does not want scripts to
+// reach. Opening the same database more than once shares one bbolt handle,
+// which is closed when the last script closes it.
+package bolt
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/modules/store"
+	"go.etcd.io/bbolt"
+)
+
+// LockTimeout is how long opening a database waits for another process
+// to release the file.
+const LockTimeout = 5 * time.Second
+
+// Backend opens bbolt databases under a directory.
+type Backend struct {
+	dir string
+
+	mu   sync.Mutex
+	open map[string]*sharedDB
+}
+
+// New returns a backend that keeps databases in dir, which must exist.
+func New(dir string) *Backend {
+	return &Backend{dir: dir, open: map[string]*sharedDB{}}
+}
+
+type sharedDB struct {
+	db   *bbolt.DB
+	refs int
+}
+
+// Open opens the database file name within the backend's directory,
+// creating it if it does not exist.
+func (b *Backend) Open(ctx context.Context, name string) (store.DB, error) {
+	if !filepath.IsLocal(name) {
+		return nil, fmt.Errorf("invalid database name %q: must be a relative path within the store directory", name)
+	}
+	path := filepath.Join(b.dir, name)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	shared, ok := b.open[path]
+	if !ok {
+		db, err := bbolt.Open(path, 0o600, &bbolt.Options{Timeout: LockTimeout})
+		if err != nil {
+			return nil, err
+		}
+		shared = &sharedDB{db: db}
+		b.open[path] = shared
+	}
+	shared.refs++
+	return &handle{backend: b, path: path, db: shared.db}, nil
+}
+
+// release closes the database at path once no handle uses it.
+func (b *Backend) release(path string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	shared := b.open[path]
+	shared.refs--
+	if shared.refs > 0 {
+		return nil
+	}
+	delete(b.open, path)
+	return shared.db.Close()
+}
+
+// handle is one script's view of a shared database.
+type handle struct {
+	backend *Backend
+	path    string
+	db      *bbolt.DB
+	once    sync.Once
+}
+
+func (h *handle) View(ctx context.Context, fn func(store.Tx) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return h.db.View(func(tx *bbolt.Tx) error {
+		return fn(&boltTx{tx: tx})
+	})
+}
+
+func (h *handle) Update(ctx context.Context, fn func(store.Tx) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return h.db.Update(func(tx *bbolt.Tx) error {
+		return fn(&boltTx{tx: tx})
+	})
+}
+
+func (h *handle) Close() error {
+	var err error
+	h.once.Do(func() {
+		err = h.backend.release(h.path)
+	})
+	return err
+}
+
+type boltTx struct {
+	tx *bbolt.Tx
+}
+
+func (t *boltTx) Get(bucket, key string) ([]byte, bool, error) {
+	b := t.tx.Bucket([]byte(bucket))
+	if b == nil {
+		return nil, false, nil
+	}
+	value := b.Get([]byte(key))
+	if value == nil {
+		return nil, false, nil
+	}
+	// Values are only valid during the transaction
+	return append([]byte(nil), value...), true, nil
+}
+
+func (t *boltTx) writable() error {
+	if !t.tx.Writable() {
+		return store.ErrReadOnly
+	}
+	return nil
+}
+
+func (t *boltTx) Put(bucket, key string, value []byte) error {
+	if err := t.writable(); err != nil {
+		return err
+	}
+	b, err := t.tx.CreateBucketIfNotExists([]byte(bucket))
+	if err != nil {
+		return err
+	}
+	return b.Put([]byte(key), value)
+}
+
+func (t *boltTx) Delete(bucket, key string) (bool, error) {
+	if err := t.writable(); err != nil {
+		return false, err
+	}
+	b := t.tx.Bucket([]byte(bucket))
+	if b == nil || b.Get([]byte(key)) == nil {
+		return false, nil
+	}
+	return true, b.Delete([]byte(key))
+}
+
+func (t *boltTx) Keys(bucket, prefix string) ([]string, error) {
+	b := t.tx.Bucket([]byte(bucket))
+	if b == nil {
+		return nil, nil
+	}
+	var keys []string
+	c := b.Cursor()
+	for k, v := c.Seek([]byte(prefix)); k != nil && strings.HasPrefix(string(k), prefix); k, v = c.Next() {
+		if v != nil {
+			keys = append(keys, string(k))
+		}
+	}
+	return keys, nil
+}
+
+func (t *boltTx) Buckets() ([]string, error) {
+	var names []string
+	err := t.tx.ForEach(func(name []byte, _ *bbolt.Bucket) error {
+		names = append(names, string(name))
+		return nil
+	})
+	return names, err
+}
+
+func (t *boltTx) DeleteBucket(bucket string) (bool, error) {
+	if err := t.writable(); err != nil {
+		return false, err
+	}
+	if t.tx.Bucket([]byte(bucket)) == nil {
+		return false, nil
+	}
+	return true, t.tx.DeleteBucket([]byte(bucket))
+}
@@ -0,0 +1,82 @@
+package bolt
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/deepnoodle-ai/risor/v2/internal/moduletest"
+	"github.com/deepnoodle-ai/risor/v2/pkg/modules/store"
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+var _ store.Backend = (*Backend)(nil)
+
+// eval runs source with a store module using backend and the default
+// builtins.
+func eval(backend store.Backend, source string) (object.Object, error) {
+	return moduletest.Eval(context.Background(), source, map[string]any{"store": store.Module(backend)})
+}
+
+func TestPersistence(t *testing.T) {
+	dir := t.TempDir()
+	_, err := eval(New(dir), `
+	let db = store.open("state.db")
+	db.set("files", "a.csv", {rows: 10})
+	db.set("files", "b.csv", {rows: 20})
+	db.set("other", "x", 1)
+	`)
+	assert.Nil(t, err)
+	_, err = os.Stat(filepath.Join(dir, "state.db"))
+	assert.Nil(t, err)
+
+	// A new backend reads what the first run wrote, after the exit hook
+	// closed the file
+	result, err := eval(New(dir), `
+	let db = store.open("state.db")
+	let rolled_back = try {
+		db.update(tx => { tx.drop("other"); throw "abort" })
+	} catch e {
+		e.message()
+	}
+	db.update(tx => tx.delete("files", "b.csv"))
+	[db.get("files", "a.csv"), db.keys("files", "a"), db.buckets(), rolled_back,
+	 db.view(tx => tx.keys("files"))]
+	`)
+	assert.Nil(t, err)
+	assert.Equal(t, result.Inspect(), `[{"rows": 10}, ["a.csv"], ["files", "other"], "abort", ["a.csv"]]`)
+}
+
+func TestSharedHandles(t *testing.T) {
+	backend := New(t.TempDir())
+	result, err := eval(backend, `
+	let a = store.open("shared.db")
+	let b = store.open("shared.db")
+	a.set("k", "v", 1)
+	a.close()
+	b.get("k", "v")
+	`)
+	assert.Nil(t, err)
+	assert.Equal(t, result, object.Object(object.NewInt(1)))
+	assert.Equal(t, len(backend.open), 0)
+}
+
+func TestInvalidNames(t *testing.T) {
+	backend := New(t.TempDir())
+	for _, name := range []string{"../escape.db", "/etc/passwd", "", "a/../../b.db"} {
+		_, err := backend.Open(context.Background(), name)
+		assert.NotNil(t, err)
+		assert.Contains(t, err.Error(), "invalid database name")
+	}
+}
+
+func TestReadOnly(t *testing.T) {
+	_, err := eval(New(t.TempDir()), `
+	let db = store.open("ro.db")
+	db.view(tx => tx.delete("a", "b"))
+	`)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "transaction is read-only")
+}
@@ -0,0 +1,16 @@
+module github.com/deepnoodle-ai/risor/v2/pkg/modules/store/bolt
+
+go 1.25.0
+
+replace github.com/deepnoodle-ai/risor/v2 => ../../../..
+
+require (
+	github.com/deepnoodle-ai/risor/v2 v2.0.0
+	github.com/deepnoodle-ai/wonton v0.0.25
+	go.etcd.io/bbolt v1.5.0
+)
+
+require (
+	github.com/google/go-cmp v0.7.0 // indirect
+	golang.org/x/sys v0.45.0 // indirect
+)
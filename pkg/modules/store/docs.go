@@ -0,0 +1,21 @@
+package store
+
+import "github.com/deepnoodle-ai/risor/v2/pkg/object"
+
+func init() {
+	object.RegisterModule("store", ModuleDoc(), Docs)
+}
+
+// Docs returns documentation for the store module.
+func Docs() []object.FuncSpec {
+	return storeDocs
+}
+
+// ModuleDoc returns the module-level documentation.
+func ModuleDoc() string {
+	return "Persistent key-value databases provided by the host"
+}
+
+var storeDocs = []object.FuncSpec{
+	{Name: "open", Doc: "Open the database with the given name", Args: []string{"name"}, Returns: "store"},
+}
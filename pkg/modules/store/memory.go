@@ -0,0 +1,159 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// MemoryBackend is a Backend that keeps databases in memory, for tests and
+// sandboxed runs. Databases are identified by name and outlive the handles
+// scripts open, so scripts sharing a backend see each other's data.
+// Nothing is written to disk.
+type MemoryBackend struct {
+	mu  sync.Mutex
+	dbs map[string]*memoryDB
+}
+
+// NewMemoryBackend returns a backend with no databases.
+func NewMemoryBackend() *MemoryBackend {
+	return &MemoryBackend{dbs: map[string]*memoryDB{}}
+}
+
+// Open returns the database named name, creating it if needed. Any name is
+// accepted.
+func (b *MemoryBackend) Open(ctx context.Context, name string) (DB, error) {
+	if name == "" {
+		return nil, errors.New("database name is empty")
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	db, ok := b.dbs[name]
+	if !ok {
+		db = &memoryDB{buckets: map[string]map[string][]byte{}}
+		b.dbs[name] = db
+	}
+	return db, nil
+}
+
+// memoryDB holds buckets that are never modified once committed. A write
+// transaction copies the buckets it changes and commits by swapping in the
+// new set, so readers never see a partial transaction.
+type memoryDB struct {
+	write   sync.Mutex // held by the write transaction
+	mu      sync.Mutex // guards buckets
+	buckets map[string]map[string][]byte
+}
+
+func (db *memoryDB) snapshot() map[string]map[string][]byte {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return db.buckets
+}
+
+func (db *memoryDB) View(ctx context.Context, fn func(Tx) error) error {
+	return fn(&memoryTx{buckets: db.snapshot()})
+}
+
+func (db *memoryDB) Update(ctx context.Context, fn func(Tx) error) error {
+	db.write.Lock()
+	defer db.write.Unlock()
+	tx := &memoryTx{
+		buckets:  maps.Clone(db.snapshot()),
+		writable: true,
+		copied:   map[string]bool{},
+	}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	db.mu.Lock()
+	db.buckets = tx.buckets
+	db.mu.Unlock()
+	return nil
+}
+
+// Close does nothing; the data stays in the backend.
+func (db *memoryDB) Close() error {
+	return nil
+}
+
+type memoryTx struct {
+	buckets  map[string]map[string][]byte
+	writable bool
+	copied   map[string]bool // buckets this transaction may modify
+}
+
+func (tx *memoryTx) Get(bucket, key string) ([]byte, bool, error) {
+	value, ok := tx.buckets[bucket][key]
+	return value, ok, nil
+}
+
+// bucket returns a bucket the transaction may modify, copying it first if
+// it is shared with committed data.
+func (tx *memoryTx) bucket(name string) (map[string][]byte, error) {
+	if !tx.writable {
+		return nil, ErrReadOnly
+	}
+	if !tx.copied[name] {
+		tx.buckets[name] = maps.Clone(tx.buckets[name])
+		if tx.buckets[name] == nil {
+			tx.buckets[name] = map[string][]byte{}
+		}
+		tx.copied[name] = true
+	}
+	return tx.buckets[name], nil
+}
+
+func (tx *memoryTx) Put(bucket, key string, value []byte) error {
+	b, err := tx.bucket(bucket)
+	if err != nil {
+		return err
+	}
+	b[key] = slices.Clone(value)
+	return nil
+}
+
+func (tx *memoryTx) Delete(bucket, key string) (bool, error) {
+	if !tx.writable {
+		return false, ErrReadOnly
+	}
+	if _, ok := tx.buckets[bucket][key]; !ok {
+		return false, nil
+	}
+	b, err := tx.bucket(bucket)
+	if err != nil {
+		return false, err
+	}
+	delete(b, key)
+	return true, nil
+}
+
+func (tx *memoryTx) Keys(bucket, prefix string) ([]string, error) {
+	var keys []string
+	for key := range tx.buckets[bucket] {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	return keys, nil
+}
+
+func (tx *memoryTx) Buckets() ([]string, error) {
+	return slices.Sorted(maps.Keys(tx.buckets)), nil
+}
+
+func (tx *memoryTx) DeleteBucket(bucket string) (bool, error) {
+	if !tx.writable {
+		return false, ErrReadOnly
+	}
+	if _, ok := tx.buckets[bucket]; !ok {
+		return false, nil
+	}
+	delete(tx.buckets, bucket)
+	delete(tx.copied, bucket)
+	return true, nil
+}
@@ -0,0 +1,131 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+)
+
+// ErrReadOnly is returned by Tx methods that write when the transaction
+// was opened with DB.View.
+var ErrReadOnly = errors.New("store: transaction is read-only")
+
+// Backend opens the databases scripts name in store.open. It decides what a
+// name refers to, so a host controls where scripts can keep data: a file
+// backend should only open files under a directory it chose, and a
+// sandboxed host can use an in-memory backend that never touches disk.
+// Backends must be safe for concurrent use.
+type Backend interface {
+	Open(ctx context.Context, name string) (DB, error)
+}
+
+// DB is an open database of buckets, each holding keys and values.
+type DB interface {
+	// View runs fn in a read-only transaction.
+	View(ctx context.Context, fn func(Tx) error) error
+	// Update runs fn in a read-write transaction, which is committed if fn
+	// returns nil and rolled back otherwise.
+	Update(ctx context.Context, fn func(Tx) error) error
+	Close() error
+}
+
+// Tx reads and writes a database within a transaction. A Tx is only valid
+// until the function it was passed to returns.
+type Tx interface {
+	// Get returns the value of key in bucket and whether it was found.
+	Get(bucket, key string) ([]byte, bool, error)
+	// Put sets the value of key in bucket, creating the bucket if needed.
+	Put(bucket, key string, value []byte) error
+	// Delete removes key from bucket and reports whether it was present.
+	Delete(bucket, key string) (bool, error)
+	// Keys returns the keys in bucket that start with prefix, sorted.
+	Keys(bucket, prefix string) ([]string, error)
+	// Buckets returns the names of the buckets, sorted.
+	Buckets() ([]string, error)
+	// DeleteBucket removes a bucket and its keys and reports whether it
+	// existed.
+	DeleteBucket(bucket string) (bool, error)
+}
+
+// encode converts a script value to the JSON stored in a database.
+func encode(fn string, value object.Object) ([]byte, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		var marshalErr *json.MarshalerError
+		if errors.As(err, &marshalErr) {
+			err = marshalErr.Unwrap()
+		}
+		return nil, fmt.Errorf("%s: cannot store %s: %w", fn, value.Type(), err)
+	}
+	return data, nil
+}
+
+// registry converts decoded JSON to script values. Unlike the default
+// registry, it reads numbers written without a fraction or exponent as
+// ints, so ints survive a round trip.
+var registry = object.NewRegistryBuilder().
+	RegisterFromGo(reflect.TypeOf(json.Number("")), func(v any) (object.Object, error) {
+		n := v.(json.Number)
+		if i, err := n.Int64(); err == nil {
+			return object.NewInt(i), nil
+		}
+		f, err := n.Float64()
+		if err != nil {
+			return nil, err
+		}
+		return object.NewFloat(f), nil
+	}).
+	Build()
+
+// decode converts stored JSON back to a script value.
+func decode(fn string, data []byte) (object.Object, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, fmt.Errorf("%s: invalid stored value: %w", fn, err)
+	}
+	return registry.FromGo(value)
+}
+
+func open(backend Backend) object.BuiltinFunction {
+	return func(ctx context.Context, args ...object.Object) (object.Object, error) {
+		if len(args) != 1 {
+			return nil, object.NewArgsError("store.open", 1, len(args))
+		}
+		name, err := object.AsString(args[0])
+		if err != nil {
+			return nil, err
+		}
+		db, err := backend.Open(ctx, name)
+		if err != nil {
+			return nil, fmt.Errorf("store.open: %w", err)
+		}
+		s := newStore(name, db)
+		// Close databases the script leaves open, so file backends release
+		// their locks when the run ends.
+		if register, ok := object.GetExitHookFunc(ctx); ok {
+			closeOnExit := object.NewBuiltin("store.close", func(ctx context.Context, args ...object.Object) (object.Object, error) {
+				return object.Nil, s.Close()
+			})
+			if err := register(closeOnExit); err != nil {
+				s.Close()
+				return nil, err
+			}
+		}
+		return s, nil
+	}
+}
+
+// Module returns the store module, which opens databases with the given
+// backend.
+func Module(backend Backend) *object.Module {
+	return object.NewBuiltinsModule("store", map[string]object.Object{
+		"open": object.NewBuiltin("open", open(backend)),
+	})
+}
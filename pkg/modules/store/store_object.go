@@ -0,0 +1,396 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+	"github.com/deepnoodle-ai/risor/v2/pkg/op"
+)
+
+const (
+	STORE       object.Type = "store"
+	TRANSACTION object.Type = "transaction"
+)
+
+var (
+	storeMethods       = object.NewMethodRegistry[*Store]("store")
+	transactionMethods = object.NewMethodRegistry[*Transaction]("transaction")
+)
+
+// accessor runs a function in a transaction for the methods a store and a
+// transaction share.
+type accessor interface {
+	run(ctx context.Context, method string, write bool, fn func(Tx) error) error
+}
+
+// defineDataMethods defines the methods that read and write buckets. A
+// store runs each call in its own transaction; a transaction runs them in
+// itself.
+func defineDataMethods[T accessor](r *object.AttrRegistry[T]) {
+	r.Define("get").
+		Doc("Get the value of a key in a bucket, or a default if it is missing").
+		Args("bucket", "key").
+		OptionalArg("default").
+		Returns("object").
+		Impl(func(a T, ctx context.Context, args ...object.Object) (object.Object, error) {
+			bucket, key, err := bucketKey(args)
+			if err != nil {
+				return nil, err
+			}
+			var data []byte
+			var found bool
+			err = a.run(ctx, "get", false, func(tx Tx) error {
+				data, found, err = tx.Get(bucket, key)
+				return err
+			})
+			if err != nil {
+				return nil, err
+			}
+			if !found {
+				if len(args) > 2 {
+					return args[2], nil
+				}
+				return object.Nil, nil
+			}
+			return decode("store.get", data)
+		})
+
+	r.Define("set").
+		Doc("Set the value of a key in a bucket, creating the bucket if needed").
+		Args("bucket", "key", "value").
+		Returns("null").
+		Impl(func(a T, ctx context.Context, args ...object.Object) (object.Object, error) {
+			bucket, key, err := bucketKey(args)
+			if err != nil {
+				return nil, err
+			}
+			data, err := encode("store.set", args[2])
+			if err != nil {
+				return nil, err
+			}
+			err = a.run(ctx, "set", true, func(tx Tx) error {
+				return tx.Put(bucket, key, data)
+			})
+			if err != nil {
+				return nil, err
+			}
+			return object.Nil, nil
+		})
+
+	r.Define("delete").
+		Doc("Remove a key from a bucket, returning whether it was present").
+		Args("bucket", "key").
+		Returns("bool").
+		Impl(func(a T, ctx context.Context, args ...object.Object) (object.Object, error) {
+			bucket, key, err := bucketKey(args)
+			if err != nil {
+				return nil, err
+			}
+			var deleted bool
+			err = a.run(ctx, "delete", true, func(tx Tx) error {
+				deleted, err = tx.Delete(bucket, key)
+				return err
+			})
+			if err != nil {
+				return nil, err
+			}
+			return object.NewBool(deleted), nil
+		})
+
+	r.Define("keys").
+		Doc("Sorted keys of a bucket, optionally only those with a prefix").
+		Arg("bucket").
+		OptionalArg("prefix").
+		Returns("list").
+		Impl(func(a T, ctx context.Context, args ...object.Object) (object.Object, error) {
+			bucket, err := object.AsString(args[0])
+			if err != nil {
+				return nil, err
+			}
+			var prefix string
+			if len(args) > 1 {
+				if prefix, err = object.AsString(args[1]); err != nil {
+					return nil, err
+				}
+			}
+			var keys []string
+			err = a.run(ctx, "keys", false, func(tx Tx) error {
+				keys, err = tx.Keys(bucket, prefix)
+				return err
+			})
+			if err != nil {
+				return nil, err
+			}
+			return stringList(keys), nil
+		})
+
+	r.Define("buckets").
+		Doc("Sorted names of the buckets").
+		Returns("list").
+		Impl(func(a T, ctx context.Context, args ...object.Object) (object.Object, error) {
+			var names []string
+			err := a.run(ctx, "buckets", false, func(tx Tx) (err error) {
+				names, err = tx.Buckets()
+				return err
+			})
+			if err != nil {
+				return nil, err
+			}
+			return stringList(names), nil
+		})
+
+	r.Define("drop").
+		Doc("Remove a bucket and all its keys, returning whether it existed").
+		Arg("bucket").
+		Returns("bool").
+		Impl(func(a T, ctx context.Context, args ...object.Object) (object.Object, error) {
+			bucket, err := object.AsString(args[0])
+			if err != nil {
+				return nil, err
+			}
+			var dropped bool
+			err = a.run(ctx, "drop", true, func(tx Tx) error {
+				dropped, err = tx.DeleteBucket(bucket)
+				return err
+			})
+			if err != nil {
+				return nil, err
+			}
+			return object.NewBool(dropped), nil
+		})
+}
+
+func init() {
+	defineDataMethods(storeMethods)
+	defineDataMethods(transactionMethods)
+
+	storeMethods.Define("update").
+		Doc("Call a function with a read-write transaction, committing if it returns normally").
+		Arg("fn").
+		Returns("object").
+		Impl(func(s *Store, ctx context.Context, args ...object.Object) (object.Object, error) {
+			return s.transact(ctx, "update", true, args[0])
+		})
+
+	storeMethods.Define("view").
+		Doc("Call a function with a read-only transaction").
+		Arg("fn").
+		Returns("object").
+		Impl(func(s *Store, ctx context.Context, args ...object.Object) (object.Object, error) {
+			return s.transact(ctx, "view", false, args[0])
+		})
+
+	storeMethods.Define("close").
+		Doc("Close the database").
+		Returns("null").
+		Impl(func(s *Store, ctx context.Context, args ...object.Object) (object.Object, error) {
+			if err := s.Close(); err != nil {
+				return nil, fmt.Errorf("store.close: %w", err)
+			}
+			return object.Nil, nil
+		})
+}
+
+func bucketKey(args []object.Object) (string, string, error) {
+	bucket, err := object.AsString(args[0])
+	if err != nil {
+		return "", "", err
+	}
+	key, err := object.AsString(args[1])
+	if err != nil {
+		return "", "", err
+	}
+	return bucket, key, nil
+}
+
+func stringList(values []string) *object.List {
+	items := make([]object.Object, len(values))
+	for i, value := range values {
+		items[i] = object.NewString(value)
+	}
+	return object.NewList(items)
+}
+
+// activeKey marks the context of a store's update or view callback.
+type activeKey struct{}
+
+// Store is a database opened by store.open. Each of its data methods runs
+// in a transaction of its own; update and view group several calls into
+// one.
+type Store struct {
+	name string
+	db   DB
+
+	mu     sync.Mutex
+	closed bool
+}
+
+func newStore(name string, db DB) *Store {
+	return &Store{name: name, db: db}
+}
+
+func (s *Store) Type() object.Type {
+	return STORE
+}
+
+func (s *Store) Inspect() string {
+	return fmt.Sprintf("store(%q)", s.name)
+}
+
+func (s *Store) String() string {
+	return s.Inspect()
+}
+
+func (s *Store) Interface() any {
+	return s.db
+}
+
+func (s *Store) Equals(other object.Object) bool {
+	return s == other
+}
+
+func (s *Store) Attrs() []object.AttrSpec {
+	return storeMethods.Specs()
+}
+
+func (s *Store) GetAttr(name string) (object.Object, bool) {
+	return storeMethods.GetAttr(s, name)
+}
+
+func (s *Store) SetAttr(name string, value object.Object) error {
+	return object.TypeErrorf("store has no attribute %q", name)
+}
+
+func (s *Store) IsTruthy() bool {
+	return true
+}
+
+func (s *Store) RunOperation(opType op.BinaryOpType, right object.Object) (object.Object, error) {
+	return nil, object.TypeErrorf("unsupported operation for store: %v", opType)
+}
+
+// Close closes the database. Closing it again does nothing.
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	return s.db.Close()
+}
+
+func (s *Store) run(ctx context.Context, method string, write bool, fn func(Tx) error) error {
+	s.mu.Lock()
+	closed := s.closed
+	s.mu.Unlock()
+	if closed {
+		return fmt.Errorf("store.%s: %s is closed", method, s.Inspect())
+	}
+	// A second transaction on the same goroutine would wait for the first
+	// to finish, which never happens.
+	if ctx.Value(activeKey{}) == s {
+		return fmt.Errorf("store.%s: called inside a transaction; use the transaction passed to the function", method)
+	}
+	var err error
+	if write {
+		err = s.db.Update(ctx, fn)
+	} else {
+		err = s.db.View(ctx, fn)
+	}
+	if err != nil {
+		return fmt.Errorf("store.%s: %w", method, err)
+	}
+	return nil
+}
+
+// transact calls fn with a transaction and returns its result. Errors fn
+// raises roll the transaction back and are returned unchanged.
+func (s *Store) transact(ctx context.Context, method string, write bool, arg object.Object) (object.Object, error) {
+	fn, ok := arg.(object.Callable)
+	if !ok {
+		return nil, object.TypeErrorf("store.%s() expected a function (%s given)", method, arg.Type())
+	}
+	var result object.Object
+	var fnErr error
+	err := s.run(ctx, method, write, func(tx Tx) error {
+		t := &Transaction{tx: tx, writable: write}
+		defer t.done.Store(true)
+		result, fnErr = fn.Call(context.WithValue(ctx, activeKey{}, s), t)
+		return fnErr
+	})
+	if fnErr != nil {
+		return nil, fnErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Transaction is the transaction passed to the function given to a
+// store's update or view method.
+type Transaction struct {
+	tx       Tx
+	writable bool
+	done     atomic.Bool
+}
+
+func (t *Transaction) Type() object.Type {
+	return TRANSACTION
+}
+
+func (t *Transaction) Inspect() string {
+	if t.writable {
+		return "transaction(read-write)"
+	}
+	return "transaction(read-only)"
+}
+
+func (t *Transaction) String() string {
+	return t.Inspect()
+}
+
+func (t *Transaction) Interface() any {
+	return t.tx
+}
+
+func (t *Transaction) Equals(other object.Object) bool {
+	return t == other
+}
+
+func (t *Transaction) Attrs() []object.AttrSpec {
+	return transactionMethods.Specs()
+}
+
+func (t *Transaction) GetAttr(name string) (object.Object, bool) {
+	return transactionMethods.GetAttr(t, name)
+}
+
+func (t *Transaction) SetAttr(name string, value object.Object) error {
+	return object.TypeErrorf("transaction has no attribute %q", name)
+}
+
+func (t *Transaction) IsTruthy() bool {
+	return true
+}
+
+func (t *Transaction) RunOperation(opType op.BinaryOpType, right object.Object) (object.Object, error) {
+	return nil, object.TypeErrorf("unsupported operation for transaction: %v", opType)
+}
+
+func (t *Transaction) run(ctx context.Context, method string, write bool, fn func(Tx) error) error {
+	if t.done.Load() {
+		return fmt.Errorf("transaction.%s: the transaction has ended", method)
+	}
+	if write && !t.writable {
+		return fmt.Errorf("transaction.%s: %w", method, ErrReadOnly)
+	}
+	if err := fn(t.tx); err != nil {
+		return fmt.Errorf("transaction.%s: %w", method, err)
+	}
+	return nil
+}
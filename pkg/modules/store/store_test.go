@@ -0,0 +1,132 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/deepnoodle-ai/risor/v2/internal/moduletest"
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+// eval runs source with a store module using backend and the default
+// builtins.
+func eval(ctx context.Context, backend Backend, source string) (object.Object, error) {
+	return moduletest.Eval(ctx, source, map[string]any{"store": Module(backend)})
+}
+
+func TestStore(t *testing.T) {
+	result, err := eval(context.Background(), NewMemoryBackend(), `
+	let db = store.open("state.db")
+	db.set("files", "a.csv", {rows: 10, tags: ["x"]})
+	db.set("files", "b.csv", 2.5)
+	db.set("runs", "last", nil)
+	[
+		db.get("files", "a.csv"),
+		db.get("files", "b.csv"),
+		db.get("files", "missing", "none"),
+		db.get("nope", "x"),
+		db.keys("files"),
+		db.keys("files", "b"),
+		db.delete("files", "a.csv"),
+		db.delete("files", "a.csv"),
+		db.buckets(),
+		db.drop("runs"),
+		db.drop("runs"),
+		db.buckets(),
+	]
+	`)
+	assert.Nil(t, err)
+	assert.Equal(t, result.Inspect(), `[{"rows": 10, "tags": ["x"]}, 2.5, "none", null, `+
+		`["a.csv", "b.csv"], ["b.csv"], true, false, ["files", "runs"], true, false, ["files"]]`)
+}
+
+func TestPersistence(t *testing.T) {
+	// Databases in a backend outlive the runs that open them
+	backend := NewMemoryBackend()
+	_, err := eval(context.Background(), backend, `store.open("state.db").set("counts", "runs", 1)`)
+	assert.Nil(t, err)
+	result, err := eval(context.Background(), backend, `
+	let db = store.open("state.db")
+	db.update(tx => {
+		let runs = tx.get("counts", "runs") + 1
+		tx.set("counts", "runs", runs)
+		return runs
+	})
+	`)
+	assert.Nil(t, err)
+	assert.Equal(t, result, object.Object(object.NewInt(2)))
+	result, err = eval(context.Background(), backend, `store.open("other.db").buckets()`)
+	assert.Nil(t, err)
+	assert.Equal(t, result.Inspect(), `[]`)
+}
+
+func TestUpdateRollback(t *testing.T) {
+	result, err := eval(context.Background(), NewMemoryBackend(), `
+	let db = store.open("state.db")
+	db.set("a", "kept", 1)
+	let message = try {
+		db.update(tx => {
+			tx.set("a", "kept", 2)
+			tx.set("b", "new", 3)
+			tx.drop("a")
+			throw "abort"
+		})
+	} catch e {
+		e.message()
+	}
+	[message, db.get("a", "kept"), db.buckets()]
+	`)
+	assert.Nil(t, err)
+	assert.Equal(t, result.Inspect(), `["abort", 1, ["a"]]`)
+}
+
+func TestTransactionErrors(t *testing.T) {
+	tests := []struct {
+		source string
+		want   string
+	}{
+		{`let db = store.open("s"); db.view(tx => tx.set("a", "b", 1))`, "transaction.set: store: transaction is read-only"},
+		{`let db = store.open("s"); db.update(tx => db.get("a", "b"))`, "store.get: called inside a transaction"},
+		{`let db = store.open("s"); let saved = nil; db.update(tx => { saved = tx }); saved.get("a", "b")`, "the transaction has ended"},
+		{`let db = store.open("s"); db.close(); db.get("a", "b")`, `store.get: store("s") is closed`},
+		{`store.open("s").set("a", "b", () => 1)`, "store.set: cannot store function"},
+		{`store.open("s").update(1)`, "expected a function"},
+		{`store.open("")`, "store.open: database name is empty"},
+	}
+	for _, tt := range tests {
+		_, err := eval(context.Background(), NewMemoryBackend(), tt.source)
+		assert.NotNil(t, err)
+		assert.Contains(t, err.Error(), tt.want)
+	}
+}
+
+type closeCounter struct {
+	*memoryDB
+	closed *int
+}
+
+func (c closeCounter) Close() error {
+	*c.closed++
+	return nil
+}
+
+type countingBackend struct {
+	closed int
+}
+
+func (b *countingBackend) Open(ctx context.Context, name string) (DB, error) {
+	return closeCounter{memoryDB: &memoryDB{buckets: map[string]map[string][]byte{}}, closed: &b.closed}, nil
+}
+
+func TestCloseOnExit(t *testing.T) {
+	backend := &countingBackend{}
+	_, err := eval(context.Background(), backend, `
+	store.open("a").set("x", "y", 1)
+	let b = store.open("b")
+	b.close()
+	b.close()
+	`)
+	assert.Nil(t, err)
+	assert.Equal(t, backend.closed, 2)
+}
@@ -0,0 +1,23 @@
+package sync
+
+import "github.com/deepnoodle-ai/risor/v2/pkg/object"
+
+func init() {
+	object.RegisterModule("sync", ModuleDoc(), Docs)
+}
+
+// Docs returns documentation for the sync module.
+func Docs() []object.FuncSpec {
+	return syncDocs
+}
+
+// ModuleDoc returns the module-level documentation.
+func ModuleDoc() string {
+	return "Maps, lists, and semaphores that can be shared between goroutines"
+}
+
+var syncDocs = []object.FuncSpec{
+	{Name: "map", Doc: "Create a sync map", Args: []string{"items?"}, Returns: "sync_map"},
+	{Name: "list", Doc: "Create a sync list", Args: []string{"items?"}, Returns: "sync_list"},
+	{Name: "semaphore", Doc: "Create a semaphore that limits how many callers hold a slot at once", Args: []string{"n"}, Returns: "semaphore"},
+}
@@ -0,0 +1,159 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+	"github.com/deepnoodle-ai/risor/v2/pkg/op"
+)
+
+const SEMAPHORE object.Type = "semaphore"
+
+var semaphoreMethods = object.NewMethodRegistry[*Semaphore]("semaphore")
+
+func init() {
+	semaphoreMethods.Define("acquire").
+		Doc("Take a slot, waiting until one is free").
+		Returns("null").
+		Impl(func(s *Semaphore, ctx context.Context, args ...object.Object) (object.Object, error) {
+			if err := s.Acquire(ctx); err != nil {
+				return nil, err
+			}
+			return object.Nil, nil
+		})
+
+	semaphoreMethods.Define("try_acquire").
+		Doc("Take a slot if one is free, without waiting").
+		Returns("bool").
+		Impl(func(s *Semaphore, ctx context.Context, args ...object.Object) (object.Object, error) {
+			return object.NewBool(s.TryAcquire()), nil
+		})
+
+	semaphoreMethods.Define("release").
+		Doc("Give back a slot taken with acquire").
+		Returns("null").
+		Impl(func(s *Semaphore, ctx context.Context, args ...object.Object) (object.Object, error) {
+			if err := s.Release(); err != nil {
+				return nil, err
+			}
+			return object.Nil, nil
+		})
+
+	semaphoreMethods.Define("with").
+		Doc("Call a function while holding a slot and return its result").
+		Arg("fn").
+		Returns("object").
+		Impl(func(s *Semaphore, ctx context.Context, args ...object.Object) (object.Object, error) {
+			fn, ok := args[0].(object.Callable)
+			if !ok {
+				return nil, object.TypeErrorf("semaphore.with() expected a function (%s given)", args[0].Type())
+			}
+			return s.With(ctx, fn)
+		})
+
+	semaphoreMethods.Define("available").
+		Doc("Number of free slots").
+		Returns("int").
+		Impl(func(s *Semaphore, ctx context.Context, args ...object.Object) (object.Object, error) {
+			return object.NewInt(int64(s.Available())), nil
+		})
+}
+
+// Semaphore limits how many callers hold one of its slots at a time. It can
+// be shared between goroutines, so parallel workers can use one to bound
+// the number of requests they have in flight together.
+type Semaphore struct {
+	slots chan struct{}
+}
+
+// NewSemaphore returns a semaphore with n slots.
+func NewSemaphore(n int) *Semaphore {
+	return &Semaphore{slots: make(chan struct{}, n)}
+}
+
+func (s *Semaphore) Type() object.Type {
+	return SEMAPHORE
+}
+
+func (s *Semaphore) Inspect() string {
+	return fmt.Sprintf("semaphore(%d/%d)", len(s.slots), cap(s.slots))
+}
+
+func (s *Semaphore) String() string {
+	return s.Inspect()
+}
+
+func (s *Semaphore) Interface() any {
+	return s
+}
+
+func (s *Semaphore) Equals(other object.Object) bool {
+	return s == other
+}
+
+func (s *Semaphore) Attrs() []object.AttrSpec {
+	return semaphoreMethods.Specs()
+}
+
+func (s *Semaphore) GetAttr(name string) (object.Object, bool) {
+	return semaphoreMethods.GetAttr(s, name)
+}
+
+func (s *Semaphore) SetAttr(name string, value object.Object) error {
+	return object.TypeErrorf("semaphore has no attribute %q", name)
+}
+
+func (s *Semaphore) IsTruthy() bool {
+	return true
+}
+
+func (s *Semaphore) RunOperation(opType op.BinaryOpType, right object.Object) (object.Object, error) {
+	return nil, object.TypeErrorf("unsupported operation for semaphore: %v", opType)
+}
+
+// Acquire takes a slot, waiting until one is free or ctx is cancelled.
+func (s *Semaphore) Acquire(ctx context.Context) error {
+	select {
+	case s.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// TryAcquire takes a slot if one is free and reports whether it did.
+func (s *Semaphore) TryAcquire() bool {
+	select {
+	case s.slots <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// Release gives back a slot. Releasing more slots than were acquired is an
+// error.
+func (s *Semaphore) Release() error {
+	select {
+	case <-s.slots:
+		return nil
+	default:
+		return fmt.Errorf("semaphore.release: no slot is held")
+	}
+}
+
+// With calls fn while holding a slot and releases it when fn returns,
+// whether or not it fails.
+func (s *Semaphore) With(ctx context.Context, fn object.Callable) (object.Object, error) {
+	if err := s.Acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer s.Release()
+	return fn.Call(ctx)
+}
+
+// Available returns the number of free slots.
+func (s *Semaphore) Available() int {
+	return cap(s.slots) - len(s.slots)
+}
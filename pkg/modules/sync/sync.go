@@ -0,0 +1,70 @@
+package sync
+
+import (
+	"context"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+)
+
+// NewSyncMap creates a sync map, optionally seeded from a map.
+func NewSyncMap(ctx context.Context, args ...object.Object) (object.Object, error) {
+	if len(args) > 1 {
+		return nil, object.NewArgsRangeError("sync.map", 0, 1, len(args))
+	}
+	var items map[string]object.Object
+	if len(args) == 1 {
+		seed, err := object.AsMap(args[0])
+		if err != nil {
+			return nil, err
+		}
+		items = seed.Value()
+	}
+	m, err := NewMap(items)
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// NewSyncList creates a sync list, optionally seeded from a list.
+func NewSyncList(ctx context.Context, args ...object.Object) (object.Object, error) {
+	if len(args) > 1 {
+		return nil, object.NewArgsRangeError("sync.list", 0, 1, len(args))
+	}
+	var items []object.Object
+	if len(args) == 1 {
+		seed, err := object.AsList(args[0])
+		if err != nil {
+			return nil, err
+		}
+		items = seed.Value()
+	}
+	ls, err := NewList(items)
+	if err != nil {
+		return nil, err
+	}
+	return ls, nil
+}
+
+// NewSyncSemaphore creates a semaphore with the given number of slots.
+func NewSyncSemaphore(ctx context.Context, args ...object.Object) (object.Object, error) {
+	if len(args) != 1 {
+		return nil, object.NewArgsError("sync.semaphore", 1, len(args))
+	}
+	n, err := object.AsInt(args[0])
+	if err != nil {
+		return nil, err
+	}
+	if n < 1 {
+		return nil, object.ValueErrorf("sync.semaphore: size must be at least 1 (got %d)", n)
+	}
+	return NewSemaphore(int(n)), nil
+}
+
+func Module() *object.Module {
+	return object.NewBuiltinsModule("sync", map[string]object.Object{
+		"map":       object.NewBuiltin("map", NewSyncMap),
+		"list":      object.NewBuiltin("list", NewSyncList),
+		"semaphore": object.NewBuiltin("semaphore", NewSyncSemaphore),
+	})
+}
@@ -0,0 +1,617 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+	"github.com/deepnoodle-ai/risor/v2/pkg/op"
+)
+
+const (
+	MAP  object.Type = "sync_map"
+	LIST object.Type = "sync_list"
+)
+
+var mapMethods = object.NewMethodRegistry[*Map]("sync_map")
+
+var listMethods = object.NewMethodRegistry[*List]("sync_list")
+
+func init() {
+	mapMethods.Define("get").
+		Doc("Get the value for key, or default if missing").
+		Arg("key").
+		OptionalArg("default").
+		Returns("any").
+		Impl(func(m *Map, ctx context.Context, args ...object.Object) (object.Object, error) {
+			key, err := object.AsString(args[0])
+			if err != nil {
+				return nil, err
+			}
+			if value, ok := m.Get(key); ok {
+				return value, nil
+			}
+			if len(args) > 1 {
+				return args[1], nil
+			}
+			return object.Nil, nil
+		})
+
+	mapMethods.Define("set").
+		Doc("Set the value for key").
+		Args("key", "value").
+		Returns("null").
+		Impl(func(m *Map, ctx context.Context, args ...object.Object) (object.Object, error) {
+			key, err := object.AsString(args[0])
+			if err != nil {
+				return nil, err
+			}
+			if err := m.Set(key, args[1]); err != nil {
+				return nil, err
+			}
+			return object.Nil, nil
+		})
+
+	mapMethods.Define("delete").
+		Doc("Remove key and return its value, or null if missing").
+		Arg("key").
+		Returns("any").
+		Impl(func(m *Map, ctx context.Context, args ...object.Object) (object.Object, error) {
+			key, err := object.AsString(args[0])
+			if err != nil {
+				return nil, err
+			}
+			return m.Delete(key), nil
+		})
+
+	mapMethods.Define("has").
+		Doc("Check if key is present").
+		Arg("key").
+		Returns("bool").
+		Impl(func(m *Map, ctx context.Context, args ...object.Object) (object.Object, error) {
+			key, err := object.AsString(args[0])
+			if err != nil {
+				return nil, err
+			}
+			_, ok := m.Get(key)
+			return object.NewBool(ok), nil
+		})
+
+	mapMethods.Define("keys").
+		Doc("Sorted list of keys").
+		Returns("list").
+		Impl(func(m *Map, ctx context.Context, args ...object.Object) (object.Object, error) {
+			return object.NewStringList(m.Keys()), nil
+		})
+
+	mapMethods.Define("incr").
+		Doc("Add delta (default 1) to the int at key and return the result").
+		Arg("key").
+		OptionalArg("delta").
+		Returns("int").
+		Impl(func(m *Map, ctx context.Context, args ...object.Object) (object.Object, error) {
+			key, err := object.AsString(args[0])
+			if err != nil {
+				return nil, err
+			}
+			delta := int64(1)
+			if len(args) > 1 {
+				if delta, err = object.AsInt(args[1]); err != nil {
+					return nil, err
+				}
+			}
+			return m.Incr(key, delta)
+		})
+
+	mapMethods.Define("compare_and_set").
+		Doc("Set key to new if its value equals old (null for missing); returns whether it was set").
+		Args("key", "old", "new").
+		Returns("bool").
+		Impl(func(m *Map, ctx context.Context, args ...object.Object) (object.Object, error) {
+			key, err := object.AsString(args[0])
+			if err != nil {
+				return nil, err
+			}
+			swapped, err := m.CompareAndSet(key, args[1], args[2])
+			if err != nil {
+				return nil, err
+			}
+			return object.NewBool(swapped), nil
+		})
+
+	mapMethods.Define("to_map").
+		Doc("Copy the current contents into a plain map").
+		Returns("map").
+		Impl(func(m *Map, ctx context.Context, args ...object.Object) (object.Object, error) {
+			return m.ToMap(), nil
+		})
+
+	listMethods.Define("append").
+		Doc("Add item to end of list").
+		Arg("item").
+		Returns("null").
+		Impl(func(ls *List, ctx context.Context, args ...object.Object) (object.Object, error) {
+			if err := ls.Append(args[0]); err != nil {
+				return nil, err
+			}
+			return object.Nil, nil
+		})
+
+	listMethods.Define("get").
+		Doc("Get the item at index").
+		Arg("index").
+		Returns("any").
+		Impl(func(ls *List, ctx context.Context, args ...object.Object) (object.Object, error) {
+			value, err := ls.GetItem(args[0])
+			if err != nil {
+				return nil, err
+			}
+			return value, nil
+		})
+
+	listMethods.Define("set").
+		Doc("Set the item at index").
+		Args("index", "item").
+		Returns("null").
+		Impl(func(ls *List, ctx context.Context, args ...object.Object) (object.Object, error) {
+			if err := ls.SetItem(args[0], args[1]); err != nil {
+				return nil, err
+			}
+			return object.Nil, nil
+		})
+
+	listMethods.Define("pop").
+		Doc("Remove and return the item at index (default last)").
+		OptionalArg("index").
+		Returns("any").
+		Impl(func(ls *List, ctx context.Context, args ...object.Object) (object.Object, error) {
+			index := int64(-1)
+			if len(args) > 0 {
+				var err error
+				if index, err = object.AsInt(args[0]); err != nil {
+					return nil, err
+				}
+			}
+			return ls.Pop(index)
+		})
+
+	listMethods.Define("compare_and_set").
+		Doc("Set the item at index to new if it equals old; returns whether it was set").
+		Args("index", "old", "new").
+		Returns("bool").
+		Impl(func(ls *List, ctx context.Context, args ...object.Object) (object.Object, error) {
+			index, err := object.AsInt(args[0])
+			if err != nil {
+				return nil, err
+			}
+			swapped, err := ls.CompareAndSet(index, args[1], args[2])
+			if err != nil {
+				return nil, err
+			}
+			return object.NewBool(swapped), nil
+		})
+
+	listMethods.Define("to_list").
+		Doc("Copy the current contents into a plain list").
+		Returns("list").
+		Impl(func(ls *List, ctx context.Context, args ...object.Object) (object.Object, error) {
+			return ls.ToList(), nil
+		})
+}
+
+// Map is a map that can be shared between goroutines. Each operation holds
+// an internal lock, and incr and compare_and_set update a key atomically.
+//
+// Stored lists and maps are frozen with object.Freeze, since another
+// goroutine could otherwise modify them while they are being read.
+type Map struct {
+	mu    sync.RWMutex
+	items map[string]object.Object
+}
+
+// NewMap returns a Map holding a copy of items, which are frozen.
+func NewMap(items map[string]object.Object) (*Map, error) {
+	m := &Map{items: make(map[string]object.Object, len(items))}
+	for key, value := range items {
+		if err := m.Set(key, value); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+func (m *Map) Type() object.Type {
+	return MAP
+}
+
+func (m *Map) Inspect() string {
+	return fmt.Sprintf("sync_map(%s)", m.ToMap().Inspect())
+}
+
+func (m *Map) String() string {
+	return m.Inspect()
+}
+
+// Interface returns a snapshot of the contents as a Go map.
+func (m *Map) Interface() any {
+	return m.ToMap().Interface()
+}
+
+func (m *Map) Equals(other object.Object) bool {
+	return m == other
+}
+
+func (m *Map) Attrs() []object.AttrSpec {
+	return mapMethods.Specs()
+}
+
+func (m *Map) GetAttr(name string) (object.Object, bool) {
+	return mapMethods.GetAttr(m, name)
+}
+
+func (m *Map) SetAttr(name string, value object.Object) error {
+	return object.TypeErrorf("sync_map has no attribute %q", name)
+}
+
+func (m *Map) IsTruthy() bool {
+	return m.Size() > 0
+}
+
+func (m *Map) RunOperation(opType op.BinaryOpType, right object.Object) (object.Object, error) {
+	return nil, object.TypeErrorf("unsupported operation for sync_map: %v", opType)
+}
+
+// Get returns the value for key.
+func (m *Map) Get(key string) (object.Object, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	value, ok := m.items[key]
+	return value, ok
+}
+
+// Set stores value under key, freezing it first.
+func (m *Map) Set(key string, value object.Object) error {
+	if err := object.Freeze(value); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.items[key] = value
+	return nil
+}
+
+// Delete removes key and returns its value, or Nil if it was missing.
+func (m *Map) Delete(key string) object.Object {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	value, ok := m.items[key]
+	if !ok {
+		return object.Nil
+	}
+	delete(m.items, key)
+	return value
+}
+
+// Keys returns the keys in sorted order.
+func (m *Map) Keys() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	keys := make([]string, 0, len(m.items))
+	for key := range m.items {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Size returns the number of keys.
+func (m *Map) Size() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.items)
+}
+
+// Incr adds delta to the int stored under key, treating a missing key as
+// 0, and returns the new value.
+func (m *Map) Incr(key string, delta int64) (object.Object, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var current int64
+	if value, ok := m.items[key]; ok {
+		i, ok := value.(*object.Int)
+		if !ok {
+			return nil, object.TypeErrorf("sync_map.incr: value for %q is %s, not int", key, value.Type())
+		}
+		current = i.Value()
+	}
+	result := object.NewInt(current + delta)
+	m.items[key] = result
+	return result, nil
+}
+
+// CompareAndSet stores value under key if the current value equals old.
+// A missing key matches an old value of Nil.
+func (m *Map) CompareAndSet(key string, old, value object.Object) (bool, error) {
+	if err := object.Freeze(value); err != nil {
+		return false, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	current, ok := m.items[key]
+	if !ok {
+		current = object.Nil
+	}
+	if !object.Equals(current, old) {
+		return false, nil
+	}
+	m.items[key] = value
+	return true, nil
+}
+
+// ToMap returns a plain map holding the current contents.
+func (m *Map) ToMap() *object.Map {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	items := make(map[string]object.Object, len(m.items))
+	for key, value := range m.items {
+		items[key] = value
+	}
+	return object.NewMap(items)
+}
+
+// GetItem implements the [key] operator.
+func (m *Map) GetItem(key object.Object) (object.Object, *object.Error) {
+	s, ok := key.(*object.String)
+	if !ok {
+		return nil, object.TypeErrorf("sync_map key must be a string (got %s)", key.Type())
+	}
+	value, found := m.Get(s.Value())
+	if !found {
+		return nil, object.Errorf("key error: %q", s.Value())
+	}
+	return value, nil
+}
+
+// GetSlice implements the [start:stop] operator.
+func (m *Map) GetSlice(s object.Slice) (object.Object, *object.Error) {
+	return nil, object.TypeErrorf("sync_map does not support slice operations")
+}
+
+// SetItem implements the [key] = value operator.
+func (m *Map) SetItem(key, value object.Object) *object.Error {
+	s, ok := key.(*object.String)
+	if !ok {
+		return object.TypeErrorf("sync_map key must be a string (got %s)", key.Type())
+	}
+	if err := m.Set(s.Value(), value); err != nil {
+		return object.NewError(err)
+	}
+	return nil
+}
+
+// DelItem implements the del [key] operator.
+func (m *Map) DelItem(key object.Object) *object.Error {
+	s, ok := key.(*object.String)
+	if !ok {
+		return object.TypeErrorf("sync_map key must be a string (got %s)", key.Type())
+	}
+	m.Delete(s.Value())
+	return nil
+}
+
+// Contains returns true if the given key is present.
+func (m *Map) Contains(key object.Object) *object.Bool {
+	s, ok := key.(*object.String)
+	if !ok {
+		return object.False
+	}
+	_, found := m.Get(s.Value())
+	return object.NewBool(found)
+}
+
+// Len returns the number of keys.
+func (m *Map) Len() *object.Int {
+	return object.NewInt(int64(m.Size()))
+}
+
+// Enumerate implements Enumerable over a snapshot of the contents, in key
+// order. Changes made during enumeration are not seen.
+func (m *Map) Enumerate(ctx context.Context, fn func(key, value object.Object) bool) {
+	m.ToMap().Enumerate(ctx, fn)
+}
+
+// List is a list that can be shared between goroutines. Each operation
+// holds an internal lock, and compare_and_set updates an item atomically.
+//
+// Stored lists and maps are frozen with object.Freeze, since another
+// goroutine could otherwise modify them while they are being read.
+type List struct {
+	mu    sync.RWMutex
+	items []object.Object
+}
+
+// NewList returns a List holding a copy of items, which are frozen.
+func NewList(items []object.Object) (*List, error) {
+	ls := &List{items: make([]object.Object, 0, len(items))}
+	for _, item := range items {
+		if err := ls.Append(item); err != nil {
+			return nil, err
+		}
+	}
+	return ls, nil
+}
+
+func (ls *List) Type() object.Type {
+	return LIST
+}
+
+func (ls *List) Inspect() string {
+	return fmt.Sprintf("sync_list(%s)", ls.ToList().Inspect())
+}
+
+func (ls *List) String() string {
+	return ls.Inspect()
+}
+
+// Interface returns a snapshot of the contents as a Go slice.
+func (ls *List) Interface() any {
+	return ls.ToList().Interface()
+}
+
+func (ls *List) Equals(other object.Object) bool {
+	return ls == other
+}
+
+func (ls *List) Attrs() []object.AttrSpec {
+	return listMethods.Specs()
+}
+
+func (ls *List) GetAttr(name string) (object.Object, bool) {
+	return listMethods.GetAttr(ls, name)
+}
+
+func (ls *List) SetAttr(name string, value object.Object) error {
+	return object.TypeErrorf("sync_list has no attribute %q", name)
+}
+
+func (ls *List) IsTruthy() bool {
+	return ls.Size() > 0
+}
+
+func (ls *List) RunOperation(opType op.BinaryOpType, right object.Object) (object.Object, error) {
+	return nil, object.TypeErrorf("unsupported operation for sync_list: %v", opType)
+}
+
+// Append adds item to the end of the list, freezing it first.
+func (ls *List) Append(item object.Object) error {
+	if err := object.Freeze(item); err != nil {
+		return err
+	}
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	ls.items = append(ls.items, item)
+	return nil
+}
+
+// Pop removes and returns the item at index. Negative indexes count from
+// the end.
+func (ls *List) Pop(index int64) (object.Object, error) {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	idx, err := object.ResolveIndex(index, int64(len(ls.items)))
+	if err != nil {
+		return nil, err
+	}
+	item := ls.items[idx]
+	ls.items = append(ls.items[:idx], ls.items[idx+1:]...)
+	return item, nil
+}
+
+// CompareAndSet replaces the item at index with value if it equals old.
+func (ls *List) CompareAndSet(index int64, old, value object.Object) (bool, error) {
+	if err := object.Freeze(value); err != nil {
+		return false, err
+	}
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	idx, err := object.ResolveIndex(index, int64(len(ls.items)))
+	if err != nil {
+		return false, err
+	}
+	if !object.Equals(ls.items[idx], old) {
+		return false, nil
+	}
+	ls.items[idx] = value
+	return true, nil
+}
+
+// Size returns the number of items.
+func (ls *List) Size() int {
+	ls.mu.RLock()
+	defer ls.mu.RUnlock()
+	return len(ls.items)
+}
+
+// ToList returns a plain list holding the current contents.
+func (ls *List) ToList() *object.List {
+	ls.mu.RLock()
+	defer ls.mu.RUnlock()
+	items := make([]object.Object, len(ls.items))
+	copy(items, ls.items)
+	return object.NewList(items)
+}
+
+// GetItem implements the [index] operator.
+func (ls *List) GetItem(key object.Object) (object.Object, *object.Error) {
+	index, ok := key.(*object.Int)
+	if !ok {
+		return nil, object.TypeErrorf("sync_list index must be an int (got %s)", key.Type())
+	}
+	ls.mu.RLock()
+	defer ls.mu.RUnlock()
+	idx, err := object.ResolveIndex(index.Value(), int64(len(ls.items)))
+	if err != nil {
+		return nil, object.NewError(err)
+	}
+	return ls.items[idx], nil
+}
+
+// GetSlice implements the [start:stop] operator, returning a plain list.
+func (ls *List) GetSlice(s object.Slice) (object.Object, *object.Error) {
+	return ls.ToList().GetSlice(s)
+}
+
+// SetItem implements the [index] = value operator.
+func (ls *List) SetItem(key, value object.Object) *object.Error {
+	index, ok := key.(*object.Int)
+	if !ok {
+		return object.TypeErrorf("sync_list index must be an int (got %s)", key.Type())
+	}
+	if err := object.Freeze(value); err != nil {
+		return object.NewError(err)
+	}
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	idx, err := object.ResolveIndex(index.Value(), int64(len(ls.items)))
+	if err != nil {
+		return object.NewError(err)
+	}
+	ls.items[idx] = value
+	return nil
+}
+
+// DelItem implements the del [index] operator.
+func (ls *List) DelItem(key object.Object) *object.Error {
+	index, ok := key.(*object.Int)
+	if !ok {
+		return object.TypeErrorf("sync_list index must be an int (got %s)", key.Type())
+	}
+	if _, err := ls.Pop(index.Value()); err != nil {
+		return object.NewError(err)
+	}
+	return nil
+}
+
+// Contains returns true if the list holds an item equal to item.
+func (ls *List) Contains(item object.Object) *object.Bool {
+	ls.mu.RLock()
+	defer ls.mu.RUnlock()
+	for _, v := range ls.items {
+		if object.Equals(v, item) {
+			return object.True
+		}
+	}
+	return object.False
+}
+
+// Len returns the number of items.
+func (ls *List) Len() *object.Int {
+	return object.NewInt(int64(ls.Size()))
+}
+
+// Enumerate implements Enumerable over a snapshot of the contents.
+func (ls *List) Enumerate(ctx context.Context, fn func(key, value object.Object) bool) {
+	ls.ToList().Enumerate(ctx, fn)
+}
@@ -0,0 +1,189 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+func call(t *testing.T, obj object.Object, name string, args ...object.Object) (object.Object, error) {
+	t.Helper()
+	method, ok := obj.GetAttr(name)
+	assert.True(t, ok, "missing method %s", name)
+	return method.(object.Callable).Call(context.Background(), args...)
+}
+
+func TestMap(t *testing.T) {
+	ctx := context.Background()
+	seed := object.NewMap(map[string]object.Object{"a": object.NewInt(1)})
+	obj, err := NewSyncMap(ctx, seed)
+	assert.Nil(t, err)
+	m := obj.(*Map)
+	assert.Equal(t, m.Type(), MAP)
+
+	result, err := call(t, m, "incr", object.NewString("a"))
+	assert.Nil(t, err)
+	assert.Equal(t, result, object.NewInt(2))
+	result, err = call(t, m, "incr", object.NewString("b"), object.NewInt(5))
+	assert.Nil(t, err)
+	assert.Equal(t, result, object.NewInt(5))
+
+	result, err = call(t, m, "compare_and_set", object.NewString("c"), object.Nil, object.NewString("x"))
+	assert.Nil(t, err)
+	assert.Equal(t, result, object.True)
+	result, err = call(t, m, "compare_and_set", object.NewString("c"), object.Nil, object.NewString("y"))
+	assert.Nil(t, err)
+	assert.Equal(t, result, object.False)
+
+	_, err = call(t, m, "incr", object.NewString("c"))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "not int")
+
+	result, err = call(t, m, "get", object.NewString("missing"), object.NewInt(0))
+	assert.Nil(t, err)
+	assert.Equal(t, result, object.NewInt(0))
+	result, err = call(t, m, "delete", object.NewString("b"))
+	assert.Nil(t, err)
+	assert.Equal(t, result, object.NewInt(5))
+	assert.Equal(t, m.Inspect(), `sync_map({"a": 2, "c": "x"})`)
+	assert.Equal(t, m.Contains(object.NewString("a")), object.True)
+	assert.Equal(t, m.Len(), object.NewInt(2))
+
+	// Stored containers are frozen
+	items := object.NewList([]object.Object{object.NewInt(1)})
+	assert.Nil(t, m.SetItem(object.NewString("items"), items))
+	assert.True(t, object.IsFrozen(items))
+	_, getErr := m.GetItem(object.NewString("nope"))
+	assert.NotNil(t, getErr)
+}
+
+func TestList(t *testing.T) {
+	ctx := context.Background()
+	obj, err := NewSyncList(ctx, object.NewList([]object.Object{object.NewInt(0)}))
+	assert.Nil(t, err)
+	ls := obj.(*List)
+	assert.Equal(t, ls.Type(), LIST)
+
+	_, err = call(t, ls, "append", object.NewString("a"))
+	assert.Nil(t, err)
+	result, err := call(t, ls, "compare_and_set", object.NewInt(0), object.NewInt(0), object.NewInt(1))
+	assert.Nil(t, err)
+	assert.Equal(t, result, object.True)
+	result, err = call(t, ls, "compare_and_set", object.NewInt(0), object.NewInt(0), object.NewInt(2))
+	assert.Nil(t, err)
+	assert.Equal(t, result, object.False)
+	result, err = call(t, ls, "get", object.NewInt(-1))
+	assert.Nil(t, err)
+	assert.Equal(t, result, object.NewString("a"))
+	result, err = call(t, ls, "pop")
+	assert.Nil(t, err)
+	assert.Equal(t, result, object.NewString("a"))
+	assert.Equal(t, ls.Inspect(), "sync_list([1])")
+
+	_, err = call(t, ls, "get", object.NewInt(5))
+	assert.Error(t, err)
+	_, err = NewSyncList(ctx, object.NewInt(1))
+	assert.Error(t, err)
+}
+
+func TestConcurrentUpdates(t *testing.T) {
+	m, err := NewMap(nil)
+	assert.Nil(t, err)
+	ls, err := NewList(nil)
+	assert.Nil(t, err)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				_, err := m.Incr("n", 1)
+				assert.Nil(t, err)
+				assert.Nil(t, ls.Append(object.NewInt(int64(j))))
+				ls.Len()
+				m.ToMap()
+			}
+		}()
+	}
+	wg.Wait()
+	value, ok := m.Get("n")
+	assert.True(t, ok)
+	assert.Equal(t, value, object.NewInt(800))
+	assert.Equal(t, ls.Size(), 800)
+}
+
+func TestSemaphore(t *testing.T) {
+	ctx := context.Background()
+	obj, err := NewSyncSemaphore(ctx, object.NewInt(2))
+	assert.Nil(t, err)
+	s := obj.(*Semaphore)
+	assert.Equal(t, s.Type(), SEMAPHORE)
+
+	_, err = call(t, s, "acquire")
+	assert.Nil(t, err)
+	result, err := call(t, s, "try_acquire")
+	assert.Nil(t, err)
+	assert.Equal(t, result, object.True)
+	result, err = call(t, s, "try_acquire")
+	assert.Nil(t, err)
+	assert.Equal(t, result, object.False)
+	assert.Equal(t, s.Inspect(), "semaphore(2/2)")
+
+	// Waiting for a slot stops when the context is cancelled
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.Equal(t, s.Acquire(cancelled), context.Canceled)
+
+	_, err = call(t, s, "release")
+	assert.Nil(t, err)
+	_, err = call(t, s, "release")
+	assert.Nil(t, err)
+	_, err = call(t, s, "release")
+	assert.Contains(t, err.Error(), "no slot is held")
+
+	_, err = NewSyncSemaphore(ctx, object.NewInt(0))
+	assert.Error(t, err)
+}
+
+func TestSemaphoreWith(t *testing.T) {
+	s := NewSemaphore(3)
+	var mu sync.Mutex
+	var running, most int
+	fn := object.NewBuiltin("work", func(ctx context.Context, args ...object.Object) (object.Object, error) {
+		mu.Lock()
+		running++
+		most = max(most, running)
+		mu.Unlock()
+		time.Sleep(time.Millisecond)
+		mu.Lock()
+		running--
+		mu.Unlock()
+		return object.True, nil
+	})
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := call(t, s, "with", fn)
+			assert.Nil(t, err)
+			assert.Equal(t, result, object.True)
+		}()
+	}
+	wg.Wait()
+	assert.True(t, most <= 3)
+	assert.Equal(t, s.Available(), 3)
+
+	// The slot is given back when the function fails
+	failing := object.NewBuiltin("fail", func(ctx context.Context, args ...object.Object) (object.Object, error) {
+		return nil, errors.New("failed")
+	})
+	_, err := call(t, s, "with", failing)
+	assert.Error(t, err)
+	assert.Equal(t, s.Available(), 3)
+}
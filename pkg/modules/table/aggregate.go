@@ -0,0 +1,217 @@
+package table
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+)
+
+// aggregateFunc computes one value from the values of a column in a group.
+type aggregateFunc func(values []object.Object) (object.Object, error)
+
+var aggregateFuncs = map[string]aggregateFunc{
+	"count": func(values []object.Object) (object.Object, error) {
+		n := 0
+		for _, v := range values {
+			if v != object.Nil {
+				n++
+			}
+		}
+		return object.NewInt(int64(n)), nil
+	},
+	"sum": func(values []object.Object) (object.Object, error) {
+		return sum(values)
+	},
+	"mean": func(values []object.Object) (object.Object, error) {
+		total, err := sum(values)
+		if err != nil {
+			return nil, err
+		}
+		n := len(nonNull(values))
+		if n == 0 {
+			return object.Nil, nil
+		}
+		f, _ := object.AsFloat(total)
+		return object.NewFloat(f / float64(n)), nil
+	},
+	"min": func(values []object.Object) (object.Object, error) {
+		return extreme(values, -1)
+	},
+	"max": func(values []object.Object) (object.Object, error) {
+		return extreme(values, 1)
+	},
+	"first": func(values []object.Object) (object.Object, error) {
+		return values[0], nil
+	},
+	"last": func(values []object.Object) (object.Object, error) {
+		return values[len(values)-1], nil
+	},
+	"list": func(values []object.Object) (object.Object, error) {
+		return object.NewList(append([]object.Object{}, values...)), nil
+	},
+}
+
+func nonNull(values []object.Object) []object.Object {
+	var result []object.Object
+	for _, v := range values {
+		if v != object.Nil {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// sum adds the non-null values. The result is an int unless a float is
+// among them.
+func sum(values []object.Object) (object.Object, error) {
+	var intSum int64
+	var floatSum float64
+	isFloat := false
+	for _, v := range nonNull(values) {
+		switch v := v.(type) {
+		case *object.Int:
+			intSum += v.Value()
+		case *object.Float:
+			floatSum += v.Value()
+			isFloat = true
+		default:
+			return nil, object.TypeErrorf("expected numbers (%s given)", v.Type())
+		}
+	}
+	if isFloat {
+		return object.NewFloat(floatSum + float64(intSum)), nil
+	}
+	return object.NewInt(intSum), nil
+}
+
+// extreme returns the smallest non-null value if sign is -1, or the
+// largest if sign is 1.
+func extreme(values []object.Object, sign int) (object.Object, error) {
+	values = nonNull(values)
+	if len(values) == 0 {
+		return object.Nil, nil
+	}
+	best := values[0]
+	for _, v := range values[1:] {
+		cmp, err := compare(v, best)
+		if err != nil {
+			return nil, err
+		}
+		if cmp == sign {
+			best = v
+		}
+	}
+	return best, nil
+}
+
+var aggregateSpec = regexp.MustCompile(`^\s*(\w+)\(\s*([^()]*?)\s*\)\s*$`)
+
+// aggregation computes one output column of group_by.
+type aggregation struct {
+	name   string
+	fn     aggregateFunc   // applied to the values of column
+	column int             // -1 counts the group's rows
+	call   object.Callable // if set, called with the group's rows instead
+}
+
+func (t *Table) parseAggregation(name string, spec object.Object) (aggregation, error) {
+	if fn, ok := spec.(object.Callable); ok {
+		return aggregation{name: name, call: fn}, nil
+	}
+	text, ok := spec.(*object.String)
+	if !ok {
+		return aggregation{}, object.TypeErrorf("table.group_by: aggregation %q must be a string such as \"sum(col)\" or a function (%s given)", name, spec.Type())
+	}
+	m := aggregateSpec.FindStringSubmatch(text.Value())
+	if m == nil {
+		return aggregation{}, object.ValueErrorf("table.group_by: invalid aggregation %q", text.Value())
+	}
+	fn, ok := aggregateFuncs[m[1]]
+	if !ok {
+		return aggregation{}, object.ValueErrorf("table.group_by: unknown aggregation function %q", m[1])
+	}
+	if m[2] == "" {
+		if m[1] != "count" {
+			return aggregation{}, object.ValueErrorf("table.group_by: %s() requires a column", m[1])
+		}
+		return aggregation{name: name, column: -1}, nil
+	}
+	col, err := t.index("group_by", m[2])
+	if err != nil {
+		return aggregation{}, err
+	}
+	return aggregation{name: name, fn: fn, column: col}, nil
+}
+
+func (t *Table) groupBy(ctx context.Context, by []string, specs *object.Map) (object.Object, error) {
+	keyCols, err := t.indexes("group_by", by)
+	if err != nil {
+		return nil, err
+	}
+	aggs := make([]aggregation, 0, specs.Size())
+	if err := specs.CheckStringKeys(); err != nil {
+		return nil, fmt.Errorf("table.group_by: %w", err)
+	}
+	for _, name := range specs.SortedKeys() {
+		agg, err := t.parseAggregation(name, specs.Get(name))
+		if err != nil {
+			return nil, err
+		}
+		aggs = append(aggs, agg)
+	}
+	// Groups are kept in the order their first row appears
+	var keys []string
+	groups := map[string][]int{}
+	for i, row := range t.rows {
+		key := rowKey(row, keyCols)
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], i)
+	}
+	columns := append([]string{}, by...)
+	for _, agg := range aggs {
+		columns = append(columns, agg.name)
+	}
+	rows := make([][]object.Object, len(keys))
+	for i, key := range keys {
+		members := groups[key]
+		row := make([]object.Object, 0, len(columns))
+		for _, col := range keyCols {
+			row = append(row, t.rows[members[0]][col])
+		}
+		for _, agg := range aggs {
+			value, err := t.aggregate(ctx, agg, members)
+			if err != nil {
+				return nil, err
+			}
+			row = append(row, value)
+		}
+		rows[i] = row
+	}
+	return newChecked(columns, rows)
+}
+
+func (t *Table) aggregate(ctx context.Context, agg aggregation, members []int) (object.Object, error) {
+	switch {
+	case agg.call != nil:
+		rows := make([]object.Object, len(members))
+		for i, r := range members {
+			rows[i] = t.rowMap(r)
+		}
+		return agg.call.Call(ctx, object.NewList(rows))
+	case agg.column < 0:
+		return object.NewInt(int64(len(members))), nil
+	}
+	values := make([]object.Object, len(members))
+	for i, r := range members {
+		values[i] = t.rows[r][agg.column]
+	}
+	value, err := agg.fn(values)
+	if err != nil {
+		return nil, object.TypeErrorf("table.group_by: %s: %v", agg.name, err)
+	}
+	return value, nil
+}
@@ -0,0 +1,22 @@
+package table
+
+import "github.com/deepnoodle-ai/risor/v2/pkg/object"
+
+func init() {
+	object.RegisterModule("table", ModuleDoc(), Docs)
+}
+
+// Docs returns documentation for the table module.
+func Docs() []object.FuncSpec {
+	return tableDocs
+}
+
+// ModuleDoc returns the module-level documentation.
+func ModuleDoc() string {
+	return "Tables of rows for filtering, grouping, and joining data"
+}
+
+var tableDocs = []object.FuncSpec{
+	{Name: "from_csv", Doc: "Build a table from CSV text with a header row", Args: []string{"text", "options?"}, Returns: "table"},
+	{Name: "from_rows", Doc: "Build a table from a list of maps", Args: []string{"rows", "columns?"}, Returns: "table"},
+}
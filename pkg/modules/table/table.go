@@ -0,0 +1,144 @@
+package table
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+)
+
+// FromRows builds a table from a list of maps. The columns are the given
+// column names, or else every key found in the rows in sorted order.
+func FromRows(ctx context.Context, args ...object.Object) (object.Object, error) {
+	if len(args) < 1 || len(args) > 2 {
+		return nil, object.NewArgsRangeError("table.from_rows", 1, 2, len(args))
+	}
+	list, err := object.AsList(args[0])
+	if err != nil {
+		return nil, err
+	}
+	maps := make([]*object.Map, len(list.Value()))
+	for i, item := range list.Value() {
+		m, ok := item.(*object.Map)
+		if !ok {
+			return nil, object.TypeErrorf("table.from_rows: row %d is a %s, not a map", i, item.Type())
+		}
+		if err := m.CheckStringKeys(); err != nil {
+			return nil, fmt.Errorf("table.from_rows: row %d: %w", i, err)
+		}
+		maps[i] = m
+	}
+	var columns []string
+	if len(args) == 2 {
+		if columns, err = object.AsStringSlice(args[1]); err != nil {
+			return nil, err
+		}
+	} else {
+		seen := map[string]bool{}
+		for _, m := range maps {
+			for _, key := range m.SortedKeys() {
+				if !seen[key] {
+					seen[key] = true
+					columns = append(columns, key)
+				}
+			}
+		}
+		sort.Strings(columns)
+	}
+	rows := make([][]object.Object, len(maps))
+	for i, m := range maps {
+		rows[i] = make([]object.Object, len(columns))
+		for j, col := range columns {
+			rows[i][j] = m.Get(col)
+		}
+	}
+	return newChecked(columns, rows)
+}
+
+// FromCSV builds a table from CSV text whose first record names the
+// columns.
+func FromCSV(ctx context.Context, args ...object.Object) (object.Object, error) {
+	if len(args) < 1 || len(args) > 2 {
+		return nil, object.NewArgsRangeError("table.from_csv", 1, 2, len(args))
+	}
+	text, err := object.AsBytes(args[0])
+	if err != nil {
+		return nil, err
+	}
+	reader := csv.NewReader(strings.NewReader(string(text)))
+	infer := false
+	if len(args) == 2 {
+		opts, err := object.AsMap(args[1])
+		if err != nil {
+			return nil, err
+		}
+		if err := opts.CheckStringKeys(); err != nil {
+			return nil, fmt.Errorf("table.from_csv: %w", err)
+		}
+		for _, name := range opts.SortedKeys() {
+			switch name {
+			case "delimiter":
+				delim, err := object.AsString(opts.Get(name))
+				if err != nil {
+					return nil, err
+				}
+				if utf8.RuneCountInString(delim) != 1 {
+					return nil, object.ValueErrorf("table.from_csv: delimiter must be a single character")
+				}
+				reader.Comma, _ = utf8.DecodeRuneInString(delim)
+			case "infer":
+				if infer, err = object.AsBool(opts.Get(name)); err != nil {
+					return nil, err
+				}
+			default:
+				return nil, object.ValueErrorf("table.from_csv: unknown option %q", name)
+			}
+		}
+	}
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, object.ValueErrorf("table.from_csv: %v", err)
+	}
+	if len(records) == 0 {
+		return newTable(nil, nil), nil
+	}
+	rows := make([][]object.Object, len(records)-1)
+	for i, record := range records[1:] {
+		rows[i] = make([]object.Object, len(record))
+		for j, cell := range record {
+			rows[i][j] = parseCell(cell, infer)
+		}
+	}
+	return newChecked(records[0], rows)
+}
+
+// parseCell converts a CSV field to a value. With inference, integers and
+// floats become numbers, and empty fields become null.
+func parseCell(cell string, infer bool) object.Object {
+	if !infer {
+		return object.NewString(cell)
+	}
+	if cell == "" {
+		return object.Nil
+	}
+	if i, err := strconv.ParseInt(cell, 10, 64); err == nil {
+		return object.NewInt(i)
+	}
+	if f, err := strconv.ParseFloat(cell, 64); err == nil {
+		return object.NewFloat(f)
+	}
+	return object.NewString(cell)
+}
+
+// Module returns the table module.
+func Module() *object.Module {
+	return object.NewBuiltinsModule("table", map[string]object.Object{
+		"from_csv":  object.NewBuiltin("from_csv", FromCSV),
+		"from_rows": object.NewBuiltin("from_rows", FromRows),
+	})
+}
@@ -0,0 +1,667 @@
+package table
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+	"github.com/deepnoodle-ai/risor/v2/pkg/op"
+)
+
+const TABLE object.Type = "table"
+
+var tableMethods = object.NewMethodRegistry[*Table]("table")
+
+func init() {
+	tableMethods.Define("columns").
+		Doc("Column names in order").
+		Returns("list").
+		Getter(func(t *Table) object.Object {
+			names := make([]object.Object, len(t.columns))
+			for i, name := range t.columns {
+				names[i] = object.NewString(name)
+			}
+			return object.NewList(names)
+		})
+
+	tableMethods.Define("num_rows").
+		Doc("Number of rows").
+		Returns("int").
+		Getter(func(t *Table) object.Object {
+			return object.NewInt(int64(len(t.rows)))
+		})
+
+	tableMethods.Define("rows").
+		Doc("Rows as a list of maps").
+		Returns("list").
+		Impl(func(t *Table, ctx context.Context, args ...object.Object) (object.Object, error) {
+			rows := make([]object.Object, len(t.rows))
+			for i := range t.rows {
+				rows[i] = t.rowMap(i)
+			}
+			return object.NewList(rows), nil
+		})
+
+	tableMethods.Define("column").
+		Doc("Values of one column as a list").
+		Arg("name").
+		Returns("list").
+		Impl(func(t *Table, ctx context.Context, args ...object.Object) (object.Object, error) {
+			name, err := object.AsString(args[0])
+			if err != nil {
+				return nil, err
+			}
+			col, err := t.index("column", name)
+			if err != nil {
+				return nil, err
+			}
+			values := make([]object.Object, len(t.rows))
+			for i, row := range t.rows {
+				values[i] = row[col]
+			}
+			return object.NewList(values), nil
+		})
+
+	tableMethods.Define("select").
+		Doc("Keep only the given columns, in the given order").
+		Arg("columns").
+		Returns("table").
+		Impl(func(t *Table, ctx context.Context, args ...object.Object) (object.Object, error) {
+			names, err := columnNames("select", args[0])
+			if err != nil {
+				return nil, err
+			}
+			return t.project("select", names)
+		})
+
+	tableMethods.Define("drop").
+		Doc("Remove the given columns").
+		Arg("columns").
+		Returns("table").
+		Impl(func(t *Table, ctx context.Context, args ...object.Object) (object.Object, error) {
+			names, err := columnNames("drop", args[0])
+			if err != nil {
+				return nil, err
+			}
+			dropped := map[string]bool{}
+			for _, name := range names {
+				if _, err := t.index("drop", name); err != nil {
+					return nil, err
+				}
+				dropped[name] = true
+			}
+			var keep []string
+			for _, name := range t.columns {
+				if !dropped[name] {
+					keep = append(keep, name)
+				}
+			}
+			return t.project("drop", keep)
+		})
+
+	tableMethods.Define("rename").
+		Doc("Rename columns using a map of old names to new names").
+		Arg("names").
+		Returns("table").
+		Impl(func(t *Table, ctx context.Context, args ...object.Object) (object.Object, error) {
+			names, err := object.AsMap(args[0])
+			if err != nil {
+				return nil, err
+			}
+			columns := append([]string{}, t.columns...)
+			if err := names.CheckStringKeys(); err != nil {
+				return nil, fmt.Errorf("table.rename: %w", err)
+			}
+			for _, old := range names.SortedKeys() {
+				col, err := t.index("rename", old)
+				if err != nil {
+					return nil, err
+				}
+				if columns[col], err = object.AsString(names.Get(old)); err != nil {
+					return nil, err
+				}
+			}
+			return newChecked(columns, t.rows)
+		})
+
+	tableMethods.Define("filter").
+		Doc("Keep the rows for which fn(row) returns a truthy value").
+		Arg("fn").
+		Returns("table").
+		Impl(func(t *Table, ctx context.Context, args ...object.Object) (object.Object, error) {
+			fn, err := callable("filter", args[0])
+			if err != nil {
+				return nil, err
+			}
+			var rows [][]object.Object
+			for i, row := range t.rows {
+				keep, err := fn.Call(ctx, t.rowMap(i))
+				if err != nil {
+					return nil, err
+				}
+				if keep.IsTruthy() {
+					rows = append(rows, row)
+				}
+			}
+			return newTable(t.columns, rows), nil
+		})
+
+	tableMethods.Define("add_column").
+		Doc("Add or replace a column whose values are computed by fn(row)").
+		Args("name", "fn").
+		Returns("table").
+		Impl(func(t *Table, ctx context.Context, args ...object.Object) (object.Object, error) {
+			name, err := object.AsString(args[0])
+			if err != nil {
+				return nil, err
+			}
+			fn, err := callable("add_column", args[1])
+			if err != nil {
+				return nil, err
+			}
+			columns := t.columns
+			col, exists := t.lookup[name]
+			if !exists {
+				columns = append(append([]string{}, t.columns...), name)
+				col = len(t.columns)
+			}
+			rows := make([][]object.Object, len(t.rows))
+			for i, row := range t.rows {
+				value, err := fn.Call(ctx, t.rowMap(i))
+				if err != nil {
+					return nil, err
+				}
+				rows[i] = make([]object.Object, len(columns))
+				copy(rows[i], row)
+				rows[i][col] = value
+			}
+			return newTable(columns, rows), nil
+		})
+
+	tableMethods.Define("sort").
+		Doc("Sort rows by one or more columns").
+		Arg("columns").
+		OptionalArg("descending").
+		Returns("table").
+		Impl(func(t *Table, ctx context.Context, args ...object.Object) (object.Object, error) {
+			names, err := columnNames("sort", args[0])
+			if err != nil {
+				return nil, err
+			}
+			cols, err := t.indexes("sort", names)
+			if err != nil {
+				return nil, err
+			}
+			descending := false
+			if len(args) == 2 {
+				if descending, err = object.AsBool(args[1]); err != nil {
+					return nil, err
+				}
+			}
+			rows := append([][]object.Object{}, t.rows...)
+			var sortErr error
+			sort.SliceStable(rows, func(i, j int) bool {
+				for _, col := range cols {
+					cmp, err := compare(rows[i][col], rows[j][col])
+					if err != nil {
+						if sortErr == nil {
+							sortErr = fmt.Errorf("table.sort: column %q: %w", t.columns[col], err)
+						}
+						return false
+					}
+					if cmp != 0 {
+						return (cmp < 0) != descending
+					}
+				}
+				return false
+			})
+			if sortErr != nil {
+				return nil, sortErr
+			}
+			return newTable(t.columns, rows), nil
+		})
+
+	tableMethods.Define("head").
+		Doc("First n rows (default 5)").
+		OptionalArg("n").
+		Returns("table").
+		Impl(func(t *Table, ctx context.Context, args ...object.Object) (object.Object, error) {
+			n, err := countArg(args)
+			if err != nil {
+				return nil, err
+			}
+			return newTable(t.columns, t.rows[:min(n, len(t.rows))]), nil
+		})
+
+	tableMethods.Define("tail").
+		Doc("Last n rows (default 5)").
+		OptionalArg("n").
+		Returns("table").
+		Impl(func(t *Table, ctx context.Context, args ...object.Object) (object.Object, error) {
+			n, err := countArg(args)
+			if err != nil {
+				return nil, err
+			}
+			return newTable(t.columns, t.rows[len(t.rows)-min(n, len(t.rows)):]), nil
+		})
+
+	tableMethods.Define("distinct").
+		Doc("Drop rows that repeat earlier rows, comparing the given columns or all of them").
+		OptionalArg("columns").
+		Returns("table").
+		Impl(func(t *Table, ctx context.Context, args ...object.Object) (object.Object, error) {
+			names := t.columns
+			if len(args) == 1 {
+				var err error
+				if names, err = columnNames("distinct", args[0]); err != nil {
+					return nil, err
+				}
+			}
+			cols, err := t.indexes("distinct", names)
+			if err != nil {
+				return nil, err
+			}
+			seen := map[string]bool{}
+			var rows [][]object.Object
+			for _, row := range t.rows {
+				key := rowKey(row, cols)
+				if !seen[key] {
+					seen[key] = true
+					rows = append(rows, row)
+				}
+			}
+			return newTable(t.columns, rows), nil
+		})
+
+	tableMethods.Define("group_by").
+		Doc("Group rows by columns and compute one row per group from aggregations").
+		Args("columns", "aggregations").
+		Returns("table").
+		Impl(func(t *Table, ctx context.Context, args ...object.Object) (object.Object, error) {
+			names, err := columnNames("group_by", args[0])
+			if err != nil {
+				return nil, err
+			}
+			aggs, err := object.AsMap(args[1])
+			if err != nil {
+				return nil, err
+			}
+			return t.groupBy(ctx, names, aggs)
+		})
+
+	tableMethods.Define("join").
+		Doc("Join with another table on columns they share").
+		Args("other", "on").
+		OptionalArg("how").
+		Returns("table").
+		Impl(func(t *Table, ctx context.Context, args ...object.Object) (object.Object, error) {
+			other, ok := args[0].(*Table)
+			if !ok {
+				return nil, object.TypeErrorf("table.join: expected a table (%s given)", args[0].Type())
+			}
+			on, err := columnNames("join", args[1])
+			if err != nil {
+				return nil, err
+			}
+			how := "inner"
+			if len(args) == 3 {
+				if how, err = object.AsString(args[2]); err != nil {
+					return nil, err
+				}
+			}
+			return t.join(other, on, how)
+		})
+
+	tableMethods.Define("to_csv").
+		Doc("Render as CSV with a header row").
+		Returns("string").
+		Impl(func(t *Table, ctx context.Context, args ...object.Object) (object.Object, error) {
+			var buf bytes.Buffer
+			w := csv.NewWriter(&buf)
+			w.Write(t.columns)
+			for _, row := range t.rows {
+				record := make([]string, len(row))
+				for i, cell := range row {
+					record[i] = cellText(cell)
+				}
+				w.Write(record)
+			}
+			w.Flush()
+			if err := w.Error(); err != nil {
+				return nil, err
+			}
+			return object.NewString(buf.String()), nil
+		})
+
+	tableMethods.Define("to_json").
+		Doc("Render as a JSON array of objects, keeping the column order").
+		Returns("string").
+		Impl(func(t *Table, ctx context.Context, args ...object.Object) (object.Object, error) {
+			var buf bytes.Buffer
+			buf.WriteByte('[')
+			for i, row := range t.rows {
+				if i > 0 {
+					buf.WriteByte(',')
+				}
+				buf.WriteByte('{')
+				for j, cell := range row {
+					if j > 0 {
+						buf.WriteByte(',')
+					}
+					key, _ := json.Marshal(t.columns[j])
+					value, err := json.Marshal(cell)
+					if err != nil {
+						return nil, fmt.Errorf("table.to_json: column %q: %w", t.columns[j], err)
+					}
+					buf.Write(key)
+					buf.WriteByte(':')
+					buf.Write(value)
+				}
+				buf.WriteByte('}')
+			}
+			buf.WriteByte(']')
+			return object.NewString(buf.String()), nil
+		})
+
+	tableMethods.Define("to_markdown").
+		Doc("Render as a Markdown table").
+		Returns("string").
+		Impl(func(t *Table, ctx context.Context, args ...object.Object) (object.Object, error) {
+			var sb strings.Builder
+			writeRow := func(cells []string) {
+				sb.WriteString("|")
+				for _, cell := range cells {
+					cell = strings.ReplaceAll(cell, "|", `\|`)
+					cell = strings.ReplaceAll(cell, "\n", " ")
+					sb.WriteString(" " + cell + " |")
+				}
+				sb.WriteString("\n")
+			}
+			writeRow(t.columns)
+			sep := make([]string, len(t.columns))
+			for i := range sep {
+				sep[i] = "---"
+			}
+			writeRow(sep)
+			for _, row := range t.rows {
+				cells := make([]string, len(row))
+				for i, cell := range row {
+					cells[i] = cellText(cell)
+				}
+				writeRow(cells)
+			}
+			return object.NewString(sb.String()), nil
+		})
+}
+
+// Table is an immutable table of rows with named columns. Operations return
+// new tables and share row storage with the original where possible.
+type Table struct {
+	columns []string
+	lookup  map[string]int
+	rows    [][]object.Object
+}
+
+// New returns a table with the given columns and rows. Each row holds one
+// value per column, in column order.
+func New(columns []string, rows [][]object.Object) (*Table, error) {
+	seen := map[string]bool{}
+	for _, name := range columns {
+		if seen[name] {
+			return nil, object.ValueErrorf("table: duplicate column %q", name)
+		}
+		seen[name] = true
+	}
+	for i, row := range rows {
+		if len(row) != len(columns) {
+			return nil, object.ValueErrorf("table: row %d has %d values for %d columns", i, len(row), len(columns))
+		}
+	}
+	return newTable(columns, rows), nil
+}
+
+func newTable(columns []string, rows [][]object.Object) *Table {
+	lookup := make(map[string]int, len(columns))
+	for i, name := range columns {
+		lookup[name] = i
+	}
+	return &Table{columns: columns, lookup: lookup, rows: rows}
+}
+
+func newChecked(columns []string, rows [][]object.Object) (object.Object, error) {
+	t, err := New(columns, rows)
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (t *Table) index(method, name string) (int, error) {
+	col, ok := t.lookup[name]
+	if !ok {
+		return 0, object.ValueErrorf("table.%s: unknown column %q", method, name)
+	}
+	return col, nil
+}
+
+func (t *Table) indexes(method string, names []string) ([]int, error) {
+	cols := make([]int, len(names))
+	for i, name := range names {
+		col, err := t.index(method, name)
+		if err != nil {
+			return nil, err
+		}
+		cols[i] = col
+	}
+	return cols, nil
+}
+
+func (t *Table) rowMap(i int) *object.Map {
+	items := make(map[string]object.Object, len(t.columns))
+	for j, name := range t.columns {
+		items[name] = t.rows[i][j]
+	}
+	return object.NewMap(items)
+}
+
+func (t *Table) project(method string, names []string) (object.Object, error) {
+	cols, err := t.indexes(method, names)
+	if err != nil {
+		return nil, err
+	}
+	rows := make([][]object.Object, len(t.rows))
+	for i, row := range t.rows {
+		rows[i] = make([]object.Object, len(cols))
+		for j, col := range cols {
+			rows[i][j] = row[col]
+		}
+	}
+	return newChecked(append([]string{}, names...), rows)
+}
+
+func (t *Table) join(other *Table, on []string, how string) (object.Object, error) {
+	if how != "inner" && how != "left" {
+		return nil, object.ValueErrorf("table.join: how must be \"inner\" or \"left\" (got %q)", how)
+	}
+	leftCols, err := t.indexes("join", on)
+	if err != nil {
+		return nil, err
+	}
+	rightCols, err := other.indexes("join", on)
+	if err != nil {
+		return nil, err
+	}
+	// The result holds every left column, then the right columns that are
+	// not join keys. Right columns whose names are taken get a suffix.
+	isKey := map[string]bool{}
+	for _, name := range on {
+		isKey[name] = true
+	}
+	columns := append([]string{}, t.columns...)
+	var extra []int
+	for col, name := range other.columns {
+		if isKey[name] {
+			continue
+		}
+		if _, taken := t.lookup[name]; taken {
+			name += "_right"
+		}
+		columns = append(columns, name)
+		extra = append(extra, col)
+	}
+	matches := map[string][]int{}
+	for i, row := range other.rows {
+		key := rowKey(row, rightCols)
+		matches[key] = append(matches[key], i)
+	}
+	var rows [][]object.Object
+	for _, row := range t.rows {
+		found := matches[rowKey(row, leftCols)]
+		if len(found) == 0 && how == "left" {
+			joined := append(append([]object.Object{}, row...), make([]object.Object, len(extra))...)
+			for i := len(row); i < len(joined); i++ {
+				joined[i] = object.Nil
+			}
+			rows = append(rows, joined)
+		}
+		for _, r := range found {
+			joined := append([]object.Object{}, row...)
+			for _, col := range extra {
+				joined = append(joined, other.rows[r][col])
+			}
+			rows = append(rows, joined)
+		}
+	}
+	return newChecked(columns, rows)
+}
+
+// rowKey identifies a row by the values in the given columns, for grouping
+// and matching rows. Whole floats match the equal int, since decoded JSON
+// holds every number as a float.
+func rowKey(row []object.Object, cols []int) string {
+	parts := make([]string, len(cols))
+	for i, col := range cols {
+		cell := row[col]
+		if f, ok := cell.(*object.Float); ok && f.Value() == math.Trunc(f.Value()) && math.Abs(f.Value()) < 1<<63 {
+			cell = object.NewInt(int64(f.Value()))
+		}
+		parts[i] = string(cell.Type()) + ":" + cell.Inspect()
+	}
+	return strings.Join(parts, "\x00")
+}
+
+// compare orders two cells. Null sorts before every other value.
+func compare(a, b object.Object) (int, error) {
+	if a == object.Nil || b == object.Nil {
+		switch {
+		case a == b:
+			return 0, nil
+		case a == object.Nil:
+			return -1, nil
+		default:
+			return 1, nil
+		}
+	}
+	comparable, ok := a.(object.Comparable)
+	if !ok {
+		return 0, object.TypeErrorf("cannot compare %s values", a.Type())
+	}
+	return comparable.Compare(b)
+}
+
+// cellText renders a cell for CSV and Markdown output. Null is empty.
+func cellText(cell object.Object) string {
+	switch cell := cell.(type) {
+	case *object.NilType:
+		return ""
+	case *object.String:
+		return cell.Value()
+	}
+	return cell.Inspect()
+}
+
+func columnNames(method string, arg object.Object) ([]string, error) {
+	if name, ok := arg.(*object.String); ok {
+		return []string{name.Value()}, nil
+	}
+	names, err := object.AsStringSlice(arg)
+	if err != nil {
+		return nil, object.TypeErrorf("table.%s: expected a column name or a list of them (%s given)", method, arg.Type())
+	}
+	return names, nil
+}
+
+func callable(method string, arg object.Object) (object.Callable, error) {
+	fn, ok := arg.(object.Callable)
+	if !ok {
+		return nil, object.TypeErrorf("table.%s: expected a function (%s given)", method, arg.Type())
+	}
+	return fn, nil
+}
+
+func countArg(args []object.Object) (int, error) {
+	if len(args) == 0 {
+		return 5, nil
+	}
+	n, err := object.AsInt(args[0])
+	if err != nil {
+		return 0, err
+	}
+	if n < 0 {
+		return 0, object.ValueErrorf("table: row count must not be negative (got %d)", n)
+	}
+	return int(n), nil
+}
+
+func (t *Table) Type() object.Type {
+	return TABLE
+}
+
+func (t *Table) Inspect() string {
+	return fmt.Sprintf("table(columns=[%s], rows=%d)", strings.Join(t.columns, ", "), len(t.rows))
+}
+
+func (t *Table) String() string {
+	return t.Inspect()
+}
+
+func (t *Table) Interface() any {
+	rows := make([]map[string]any, len(t.rows))
+	for i, row := range t.rows {
+		rows[i] = make(map[string]any, len(row))
+		for j, cell := range row {
+			rows[i][t.columns[j]] = cell.Interface()
+		}
+	}
+	return rows
+}
+
+func (t *Table) Equals(other object.Object) bool {
+	return t == other
+}
+
+func (t *Table) Attrs() []object.AttrSpec {
+	return tableMethods.Specs()
+}
+
+func (t *Table) GetAttr(name string) (object.Object, bool) {
+	return tableMethods.GetAttr(t, name)
+}
+
+func (t *Table) SetAttr(name string, value object.Object) error {
+	return object.TypeErrorf("table has no attribute %q", name)
+}
+
+func (t *Table) IsTruthy() bool {
+	return len(t.rows) > 0
+}
+
+func (t *Table) RunOperation(opType op.BinaryOpType, right object.Object) (object.Object, error) {
+	return nil, object.TypeErrorf("unsupported operation for table: %v", opType)
+}
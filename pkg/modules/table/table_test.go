@@ -0,0 +1,209 @@
+package table
+
+import (
+	"context"
+	"testing"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+func call(t *testing.T, obj object.Object, name string, args ...object.Object) object.Object {
+	t.Helper()
+	result, err := callErr(t, obj, name, args...)
+	assert.Nil(t, err)
+	return result
+}
+
+func callErr(t *testing.T, obj object.Object, name string, args ...object.Object) (object.Object, error) {
+	t.Helper()
+	fn, ok := obj.GetAttr(name)
+	assert.True(t, ok, name)
+	return fn.(object.Callable).Call(context.Background(), args...)
+}
+
+func get(t *testing.T, obj object.Object, name string) object.Object {
+	t.Helper()
+	value, ok := obj.GetAttr(name)
+	assert.True(t, ok, name)
+	return value
+}
+
+func str(s string) *object.String { return object.NewString(s) }
+
+func fn(f func(row *object.Map) object.Object) *object.Builtin {
+	return object.NewBuiltin("fn", func(ctx context.Context, args ...object.Object) (object.Object, error) {
+		return f(args[0].(*object.Map)), nil
+	})
+}
+
+const salesCSV = `region,product,qty,price
+eu,bolt,4,0.5
+us,nut,10,0.25
+eu,nut,,0.25
+us,bolt,2,0.5
+`
+
+func sales(t *testing.T) *Table {
+	t.Helper()
+	tbl, err := FromCSV(context.Background(), str(salesCSV),
+		object.NewMap(map[string]object.Object{"infer": object.True}))
+	assert.Nil(t, err)
+	return tbl.(*Table)
+}
+
+func TestFromCSV(t *testing.T) {
+	tbl := sales(t)
+	assert.Equal(t, tbl.Inspect(), "table(columns=[region, product, qty, price], rows=4)")
+	assert.Equal(t, call(t, tbl, "column", str("qty")).Inspect(), "[4, 10, null, 2]")
+
+	raw, err := FromCSV(context.Background(), str("a;b\n1;x\n"),
+		object.NewMap(map[string]object.Object{"delimiter": str(";")}))
+	assert.Nil(t, err)
+	assert.Equal(t, call(t, raw, "rows").Inspect(), `[{"a": "1", "b": "x"}]`)
+
+	_, err = FromCSV(context.Background(), str("a,b\n1\n"))
+	assert.Error(t, err)
+}
+
+func TestFromRows(t *testing.T) {
+	rows := object.NewList([]object.Object{
+		object.NewMap(map[string]object.Object{"b": object.NewInt(1), "a": str("x")}),
+		object.NewMap(map[string]object.Object{"c": object.True}),
+	})
+	tbl, err := FromRows(context.Background(), rows)
+	assert.Nil(t, err)
+	assert.Equal(t, get(t, tbl, "columns").Inspect(), `["a", "b", "c"]`)
+	assert.Equal(t, get(t, tbl, "num_rows"), object.NewInt(2))
+
+	tbl, err = FromRows(context.Background(), rows, object.NewList([]object.Object{str("c"), str("a")}))
+	assert.Nil(t, err)
+	assert.Equal(t, call(t, tbl, "rows").Inspect(), `[{"a": "x", "c": null}, {"a": null, "c": true}]`)
+
+	_, err = FromRows(context.Background(), object.NewList([]object.Object{object.NewInt(1)}))
+	assert.Error(t, err)
+}
+
+func TestTransforms(t *testing.T) {
+	tbl := sales(t)
+	cols := object.NewList([]object.Object{str("product"), str("qty")})
+	assert.Equal(t, get(t, call(t, tbl, "select", cols), "columns").Inspect(), `["product", "qty"]`)
+	assert.Equal(t, get(t, call(t, tbl, "drop", str("price")), "columns").Inspect(), `["region", "product", "qty"]`)
+	renamed := call(t, tbl, "rename", object.NewMap(map[string]object.Object{"qty": str("quantity")}))
+	assert.Equal(t, get(t, renamed, "columns").Inspect(), `["region", "product", "quantity", "price"]`)
+
+	eu := call(t, tbl, "filter", fn(func(row *object.Map) object.Object {
+		return object.NewBool(row.Get("region").Equals(str("eu")))
+	}))
+	assert.Equal(t, get(t, eu, "num_rows"), object.NewInt(2))
+
+	withTotal := call(t, tbl, "add_column", str("total"), fn(func(row *object.Map) object.Object {
+		qty, ok := row.Get("qty").(*object.Int)
+		if !ok {
+			return object.Nil
+		}
+		return object.NewFloat(float64(qty.Value()) * row.Get("price").(*object.Float).Value())
+	}))
+	assert.Equal(t, call(t, withTotal, "column", str("total")).Inspect(), "[2, 2.5, null, 1]")
+	assert.Equal(t, get(t, tbl, "columns").Inspect(), `["region", "product", "qty", "price"]`)
+
+	sorted := call(t, tbl, "sort", str("qty"), object.True)
+	assert.Equal(t, call(t, sorted, "column", str("qty")).Inspect(), "[10, 4, 2, null]")
+	sorted = call(t, tbl, "sort", object.NewList([]object.Object{str("product"), str("region")}))
+	assert.Equal(t, call(t, sorted, "column", str("region")).Inspect(), `["eu", "us", "eu", "us"]`)
+
+	assert.Equal(t, call(t, call(t, tbl, "head", object.NewInt(1)), "column", str("qty")).Inspect(), "[4]")
+	assert.Equal(t, call(t, call(t, tbl, "tail", object.NewInt(2)), "column", str("qty")).Inspect(), "[null, 2]")
+	assert.Equal(t, get(t, call(t, tbl, "distinct", str("product")), "num_rows"), object.NewInt(2))
+}
+
+func TestGroupBy(t *testing.T) {
+	tbl := sales(t)
+	grouped := call(t, tbl, "group_by", str("region"), object.NewMap(map[string]object.Object{
+		"rows":     str("count()"),
+		"with_qty": str("count(qty)"),
+		"qty":      str("sum(qty)"),
+		"avg":      str("mean(price)"),
+		"products": str("list(product)"),
+		"cheapest": str("min(price)"),
+		"custom": object.NewBuiltin("custom", func(ctx context.Context, args ...object.Object) (object.Object, error) {
+			return object.NewInt(int64(len(args[0].(*object.List).Value()))), nil
+		}),
+	}))
+	assert.Equal(t, get(t, grouped, "columns").Inspect(),
+		`["region", "avg", "cheapest", "custom", "products", "qty", "rows", "with_qty"]`)
+	assert.Equal(t, call(t, grouped, "to_csv").(*object.String).Value(),
+		"region,avg,cheapest,custom,products,qty,rows,with_qty\n"+
+			"eu,0.375,0.25,2,\"[\"\"bolt\"\", \"\"nut\"\"]\",4,2,1\n"+
+			"us,0.375,0.25,2,\"[\"\"nut\"\", \"\"bolt\"\"]\",12,2,2\n")
+
+	for _, spec := range []string{"median(qty)", "sum(missing)", "sum()", "sum"} {
+		_, err := callErr(t, tbl, "group_by", str("region"), object.NewMap(map[string]object.Object{"x": str(spec)}))
+		assert.Error(t, err, spec)
+	}
+	_, err := callErr(t, tbl, "group_by", str("region"), object.NewMap(map[string]object.Object{"x": str("sum(product)")}))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "expected numbers (string given)")
+}
+
+func TestJoin(t *testing.T) {
+	users, err := FromRows(context.Background(), object.NewList([]object.Object{
+		object.NewMap(map[string]object.Object{"id": object.NewFloat(1), "name": str("ann")}),
+		object.NewMap(map[string]object.Object{"id": object.NewFloat(2), "name": str("bo")}),
+	}), object.NewList([]object.Object{str("id"), str("name")}))
+	assert.Nil(t, err)
+	orders, err := FromCSV(context.Background(), str("id,name,total\n1,first,5\n1,second,7\n3,third,1\n"),
+		object.NewMap(map[string]object.Object{"infer": object.True}))
+	assert.Nil(t, err)
+
+	inner := call(t, users, "join", orders, str("id"))
+	assert.Equal(t, get(t, inner, "columns").Inspect(), `["id", "name", "name_right", "total"]`)
+	assert.Equal(t, call(t, inner, "column", str("name_right")).Inspect(), `["first", "second"]`)
+
+	left := call(t, users, "join", orders, str("id"), str("left"))
+	assert.Equal(t, call(t, left, "column", str("total")).Inspect(), "[5, 7, null]")
+
+	_, err = callErr(t, users, "join", orders, str("id"), str("outer"))
+	assert.Error(t, err)
+	_, err = callErr(t, users, "join", orders, str("total"))
+	assert.Error(t, err)
+}
+
+func TestRender(t *testing.T) {
+	tbl := call(t, sales(t), "head", object.NewInt(3))
+	assert.Equal(t, call(t, tbl, "to_json").(*object.String).Value(),
+		`[{"region":"eu","product":"bolt","qty":4,"price":0.5},`+
+			`{"region":"us","product":"nut","qty":10,"price":0.25},`+
+			`{"region":"eu","product":"nut","qty":null,"price":0.25}]`)
+	assert.Equal(t, call(t, tbl, "to_markdown").(*object.String).Value(),
+		"| region | product | qty | price |\n"+
+			"| --- | --- | --- | --- |\n"+
+			"| eu | bolt | 4 | 0.5 |\n"+
+			"| us | nut | 10 | 0.25 |\n"+
+			"| eu | nut |  | 0.25 |\n")
+	assert.Equal(t, call(t, tbl, "to_csv").(*object.String).Value(),
+		"region,product,qty,price\neu,bolt,4,0.5\nus,nut,10,0.25\neu,nut,,0.25\n")
+}
+
+func TestErrors(t *testing.T) {
+	tbl := sales(t)
+	_, err := callErr(t, tbl, "select", str("missing"))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `table.select: unknown column "missing"`)
+	_, err = callErr(t, tbl, "rename", object.NewMap(map[string]object.Object{"qty": str("price")}))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `duplicate column "price"`)
+	_, err = callErr(t, tbl, "sort", str("qty"), object.NewInt(1))
+	assert.Error(t, err)
+	_, err = callErr(t, tbl, "filter", str("region"))
+	assert.Error(t, err)
+	_, err = callErr(t, tbl, "head", object.NewInt(-1))
+	assert.Error(t, err)
+	mixed, err := FromRows(context.Background(), object.NewList([]object.Object{
+		object.NewMap(map[string]object.Object{"v": object.NewInt(1)}),
+		object.NewMap(map[string]object.Object{"v": str("x")}),
+	}))
+	assert.Nil(t, err)
+	_, err = callErr(t, mixed, "sort", str("v"))
+	assert.Error(t, err)
+}
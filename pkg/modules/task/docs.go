@@ -0,0 +1,22 @@
+package task
+
+import "github.com/deepnoodle-ai/risor/v2/pkg/object"
+
+func init() {
+	object.RegisterModule("task", ModuleDoc(), Docs)
+}
+
+// Docs returns documentation for the task module.
+func Docs() []object.FuncSpec {
+	return taskDocs
+}
+
+// ModuleDoc returns the module-level documentation.
+func ModuleDoc() string {
+	return "Start functions in the background and wait for their results"
+}
+
+var taskDocs = []object.FuncSpec{
+	{Name: "spawn", Doc: "Call a function on a new goroutine and return a task for its result", Args: []string{"fn", "args..."}, Returns: "task"},
+	{Name: "all", Doc: "Return a task that resolves to the results of a list of tasks", Args: []string{"tasks"}, Returns: "task"},
+}
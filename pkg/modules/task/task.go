@@ -0,0 +1,106 @@
+package task
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+)
+
+// Spawn calls a function with the given arguments on a new goroutine and
+// returns a task for its result. A Risor function runs on a VM forked from
+// the caller's, since a VM runs on a single goroutine.
+func Spawn(ctx context.Context, args ...object.Object) (object.Object, error) {
+	if len(args) < 1 {
+		return nil, fmt.Errorf("task.spawn: expected at least 1 argument, got 0")
+	}
+	fn, ok := args[0].(object.Callable)
+	if !ok {
+		return nil, object.TypeErrorf("task.spawn() expected a function (%s given)", args[0].Type())
+	}
+	callArgs := args[1:]
+	closure, isClosure := fn.(*object.Closure)
+	if !isClosure {
+		return object.NewTask(ctx, func(ctx context.Context) (object.Object, error) {
+			return fn.Call(ctx, callArgs...)
+		}), nil
+	}
+	fork, ok := object.GetForkFunc(ctx)
+	if !ok {
+		return nil, fmt.Errorf("task.spawn: functions can only be spawned from a running program")
+	}
+	// Fork here, on the goroutine running the caller's VM
+	callFunc, err := fork()
+	if err != nil {
+		return nil, fmt.Errorf("task.spawn: %w", err)
+	}
+	return object.NewTask(ctx, func(ctx context.Context) (object.Object, error) {
+		return callFunc(ctx, closure, callArgs)
+	}), nil
+}
+
+// All returns a task that waits for every task in a list and resolves to a
+// list of their results, in the order of the list. Items that are not
+// tasks are passed through as results. The first task that fails cancels
+// the others and fails the returned task.
+func All(ctx context.Context, args ...object.Object) (object.Object, error) {
+	if len(args) != 1 {
+		return nil, object.NewArgsError("task.all", 1, len(args))
+	}
+	items, err := object.AsList(args[0])
+	if err != nil {
+		return nil, err
+	}
+	values := items.Value()
+	return object.NewTask(ctx, func(ctx context.Context) (object.Object, error) {
+		results := make([]object.Object, len(values))
+		finished := make(chan int, len(values))
+		pending := 0
+		for i, value := range values {
+			t, ok := value.(*object.Task)
+			if !ok {
+				results[i] = value
+				continue
+			}
+			pending++
+			go func() {
+				select {
+				case <-t.Wait():
+					finished <- i
+				case <-ctx.Done():
+				}
+			}()
+		}
+		for ; pending > 0; pending-- {
+			select {
+			case i := <-finished:
+				result, err := values[i].(*object.Task).Await(ctx)
+				if err != nil {
+					cancelAll(values)
+					return nil, fmt.Errorf("task.all: item %d: %w", i, err)
+				}
+				results[i] = result
+			case <-ctx.Done():
+				cancelAll(values)
+				return nil, ctx.Err()
+			}
+		}
+		return object.NewList(results), nil
+	}), nil
+}
+
+// cancelAll cancels every task in values.
+func cancelAll(values []object.Object) {
+	for _, value := range values {
+		if t, ok := value.(*object.Task); ok {
+			t.Cancel()
+		}
+	}
+}
+
+func Module() *object.Module {
+	return object.NewBuiltinsModule("task", map[string]object.Object{
+		"spawn": object.NewBuiltin("spawn", Spawn),
+		"all":   object.NewBuiltin("all", All),
+	})
+}
@@ -0,0 +1,148 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/deepnoodle-ai/risor/v2/internal/moduletest"
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+	"github.com/deepnoodle-ai/risor/v2/pkg/vm"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+// eval runs source with the task module, a slow builtin, and the default
+// builtins.
+func eval(ctx context.Context, source string) (object.Object, error) {
+	globals := map[string]any{
+		"task": Module(),
+		"slow": object.NewBuiltin("slow", func(ctx context.Context, args ...object.Object) (object.Object, error) {
+			value := args[0]
+			return object.NewTask(ctx, func(ctx context.Context) (object.Object, error) {
+				select {
+				case <-time.After(10 * time.Millisecond):
+					return value, nil
+				case <-ctx.Done():
+					return nil, ctx.Err()
+				}
+			}), nil
+		}),
+	}
+	return moduletest.Eval(ctx, source, globals)
+}
+
+func TestHostTask(t *testing.T) {
+	result, err := eval(context.Background(), `
+	let t = slow("a")
+	[t.done(), t.await(), t.done(), string(t)]
+	`)
+	assert.Nil(t, err)
+	assert.Equal(t, result.Inspect(), `[false, "a", true, "task(done)"]`)
+}
+
+func TestSpawn(t *testing.T) {
+	result, err := eval(context.Background(), `
+	let base = 10
+	let t = task.spawn((a, b) => a * b + base, 3, 4)
+	t.await()
+	`)
+	assert.Nil(t, err)
+	assert.Equal(t, result, object.Object(object.NewInt(22)))
+
+	result, err = eval(context.Background(), `task.spawn(string, 5).await()`)
+	assert.Nil(t, err)
+	assert.Equal(t, result, object.Object(object.NewString("5")))
+}
+
+func TestSpawnError(t *testing.T) {
+	result, err := eval(context.Background(), `
+	let t = task.spawn(() => { throw "no luck" })
+	try { t.await() } catch e { "caught: " + e.message() }
+	`)
+	assert.Nil(t, err)
+	assert.Equal(t, result, object.Object(object.NewString("caught: no luck")))
+
+	_, err = eval(context.Background(), `task.spawn(1)`)
+	assert.Contains(t, err.Error(), "expected a function")
+	_, err = eval(context.Background(), `task.spawn()`)
+	assert.Contains(t, err.Error(), "expected at least 1 argument")
+}
+
+func TestAll(t *testing.T) {
+	start := time.Now()
+	result, err := eval(context.Background(), `
+	task.all([slow(1), slow(2), 3, task.spawn(x => x * 2, 2), slow(5)]).await()
+	`)
+	assert.Nil(t, err)
+	assert.Equal(t, result.Inspect(), "[1, 2, 3, 4, 5]")
+	// The slow tasks run at the same time
+	assert.True(t, time.Since(start) < 40*time.Millisecond)
+
+	result, err = eval(context.Background(), `task.all([]).await()`)
+	assert.Nil(t, err)
+	assert.Equal(t, result.Inspect(), "[]")
+}
+
+func TestAllError(t *testing.T) {
+	_, err := eval(context.Background(), `
+	let failing = task.spawn(() => { throw "bad" })
+	task.all([slow(1), failing]).await()
+	`)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "task.all: item 1")
+	assert.Contains(t, err.Error(), "bad")
+
+	_, err = eval(context.Background(), `task.all(1)`)
+	assert.NotNil(t, err)
+}
+
+func TestAwaitCancel(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := eval(ctx, `task.spawn(() => range(1000000000).each(i => i)).await()`)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
+
+func TestTasksStopWhenRunEnds(t *testing.T) {
+	var calls, running atomic.Int64
+	globals := map[string]any{
+		"task": Module(),
+		"tick": object.NewBuiltin("tick", func(ctx context.Context, args ...object.Object) (object.Object, error) {
+			calls.Add(1)
+			return object.Nil, nil
+		}),
+		"wait": object.NewBuiltin("wait", func(ctx context.Context, args ...object.Object) (object.Object, error) {
+			return object.NewTask(ctx, func(ctx context.Context) (object.Object, error) {
+				running.Add(1)
+				defer running.Add(-1)
+				<-ctx.Done()
+				return nil, ctx.Err()
+			}), nil
+		}),
+	}
+	_, err := moduletest.Eval(context.Background(), `
+	task.spawn(() => range(1000000000).each(i => tick()))
+	wait()
+	task.spawn(() => { wait(); range(1000000000).each(i => tick()) })
+	`, globals)
+	assert.Nil(t, err)
+	assert.Equal(t, running.Load(), int64(0))
+	n := calls.Load()
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, calls.Load(), n)
+}
+
+func TestSpawnStepLimit(t *testing.T) {
+	globals := map[string]any{"task": Module()}
+	source := `
+	function work() { range(30000).each(i => i) }
+	task.all(list(range(8)).map(i => task.spawn(work))).await()
+	`
+	_, err := moduletest.Eval(context.Background(), source, globals, vm.WithMaxSteps(100000))
+	assert.True(t, errors.Is(err, vm.ErrStepLimitExceeded))
+
+	_, err = moduletest.Eval(context.Background(), source, globals, vm.WithMaxSteps(10000000))
+	assert.Nil(t, err)
+}
@@ -0,0 +1,32 @@
+package term
+
+import "github.com/deepnoodle-ai/risor/v2/pkg/object"
+
+func init() {
+	object.RegisterModule("term", ModuleDoc(), Docs)
+}
+
+// Docs returns documentation for the term module.
+func Docs() []object.FuncSpec {
+	return termDocs
+}
+
+// ModuleDoc returns the module-level documentation.
+func ModuleDoc() string {
+	return "Terminal colors, cursor control, progress bars, and spinners"
+}
+
+var termDocs = []object.FuncSpec{
+	{Name: "style", Doc: "Wrap text in color and formatting codes", Args: []string{"text", "styles..."}, Returns: "string"},
+	{Name: "strip", Doc: "Remove ANSI escape sequences from a string", Args: []string{"text"}, Returns: "string"},
+	{Name: "is_tty", Doc: "Whether output goes to an interactive terminal", Args: []string{}, Returns: "bool"},
+	{Name: "size", Doc: "Terminal width and height, or null if unknown", Args: []string{}, Returns: "map"},
+	{Name: "clear", Doc: "Clear the screen and move the cursor home", Args: []string{}, Returns: "nil"},
+	{Name: "clear_line", Doc: "Clear the current line", Args: []string{}, Returns: "nil"},
+	{Name: "hide_cursor", Doc: "Hide the cursor", Args: []string{}, Returns: "nil"},
+	{Name: "show_cursor", Doc: "Show the cursor", Args: []string{}, Returns: "nil"},
+	{Name: "cursor_up", Doc: "Move the cursor up n lines (default 1)", Args: []string{"n?"}, Returns: "nil"},
+	{Name: "cursor_down", Doc: "Move the cursor down n lines (default 1)", Args: []string{"n?"}, Returns: "nil"},
+	{Name: "progress", Doc: "Start a progress bar for total units of work", Args: []string{"total", "options?"}, Returns: "progress"},
+	{Name: "spinner", Doc: "Start a spinner with an optional message", Args: []string{"message?"}, Returns: "spinner"},
+}
@@ -0,0 +1,195 @@
+package term
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+	"github.com/deepnoodle-ai/risor/v2/pkg/op"
+)
+
+const PROGRESS object.Type = "progress"
+
+// defaultBarWidth is the number of cells in a progress bar.
+const defaultBarWidth = 30
+
+var progressMethods = object.NewMethodRegistry[*Progress]("progress")
+
+func init() {
+	progressMethods.Define("current").
+		Doc("Units of work completed").
+		Returns("int").
+		Getter(func(p *Progress) object.Object {
+			return object.NewInt(p.current)
+		})
+
+	progressMethods.Define("total").
+		Doc("Units of work in all").
+		Returns("int").
+		Getter(func(p *Progress) object.Object {
+			return object.NewInt(p.total)
+		})
+
+	progressMethods.Define("update").
+		Doc("Set the units of work completed and redraw").
+		Arg("n").
+		Returns("progress").
+		Impl(func(p *Progress, ctx context.Context, args ...object.Object) (object.Object, error) {
+			n, err := object.AsInt(args[0])
+			if err != nil {
+				return nil, err
+			}
+			p.set(n)
+			return p, nil
+		})
+
+	progressMethods.Define("increment").
+		Doc("Add to the units of work completed (default 1) and redraw").
+		OptionalArg("n").
+		Returns("progress").
+		Impl(func(p *Progress, ctx context.Context, args ...object.Object) (object.Object, error) {
+			n := int64(1)
+			if len(args) == 1 {
+				var err error
+				if n, err = object.AsInt(args[0]); err != nil {
+					return nil, err
+				}
+			}
+			p.set(p.current + n)
+			return p, nil
+		})
+
+	progressMethods.Define("done").
+		Doc("Fill the bar and end its line").
+		Returns("nil").
+		Impl(func(p *Progress, ctx context.Context, args ...object.Object) (object.Object, error) {
+			if !p.finished {
+				p.set(p.total)
+				p.finished = true
+				p.term.write("\n")
+			}
+			return object.Nil, nil
+		})
+}
+
+// Progress is a progress bar redrawn in place on the terminal as work
+// completes.
+type Progress struct {
+	term     *config
+	label    string
+	width    int
+	total    int64
+	current  int64
+	finished bool
+}
+
+func newProgress(c *config) object.BuiltinFunction {
+	return func(ctx context.Context, args ...object.Object) (object.Object, error) {
+		if len(args) < 1 || len(args) > 2 {
+			return nil, object.NewArgsRangeError("term.progress", 1, 2, len(args))
+		}
+		total, err := object.AsInt(args[0])
+		if err != nil {
+			return nil, err
+		}
+		if total <= 0 {
+			return nil, object.ValueErrorf("term.progress: total must be positive, got %d", total)
+		}
+		p := &Progress{term: c, total: total, width: defaultBarWidth}
+		if len(args) == 2 {
+			opts, err := object.AsMap(args[1])
+			if err != nil {
+				return nil, err
+			}
+			if err := opts.CheckStringKeys(); err != nil {
+				return nil, fmt.Errorf("term.progress: %w", err)
+			}
+			for _, name := range opts.SortedKeys() {
+				switch name {
+				case "label":
+					if p.label, err = object.AsString(opts.Get(name)); err != nil {
+						return nil, err
+					}
+				case "width":
+					width, err := object.AsInt(opts.Get(name))
+					if err != nil {
+						return nil, err
+					}
+					if width < 1 {
+						return nil, object.ValueErrorf("term.progress: width must be positive, got %d", width)
+					}
+					p.width = int(width)
+				default:
+					return nil, object.ValueErrorf("term.progress: unknown option %q", name)
+				}
+			}
+		}
+		p.render()
+		return p, nil
+	}
+}
+
+// set clamps n to [0, total] and redraws the bar.
+func (p *Progress) set(n int64) {
+	p.current = max(0, min(n, p.total))
+	p.render()
+}
+
+func (p *Progress) render() {
+	if p.finished {
+		return
+	}
+	filled := int(int64(p.width) * p.current / p.total)
+	var sb strings.Builder
+	sb.WriteString("\r\x1b[2K")
+	if p.label != "" {
+		sb.WriteString(p.label)
+		sb.WriteByte(' ')
+	}
+	sb.WriteByte('[')
+	sb.WriteString(strings.Repeat("#", filled))
+	sb.WriteString(strings.Repeat("-", p.width-filled))
+	fmt.Fprintf(&sb, "] %3d%% (%d/%d)", p.current*100/p.total, p.current, p.total)
+	p.term.write(sb.String())
+}
+
+func (p *Progress) Type() object.Type {
+	return PROGRESS
+}
+
+func (p *Progress) Inspect() string {
+	return fmt.Sprintf("progress(%d/%d)", p.current, p.total)
+}
+
+func (p *Progress) String() string {
+	return p.Inspect()
+}
+
+func (p *Progress) Interface() any {
+	return p
+}
+
+func (p *Progress) Equals(other object.Object) bool {
+	return p == other
+}
+
+func (p *Progress) Attrs() []object.AttrSpec {
+	return progressMethods.Specs()
+}
+
+func (p *Progress) GetAttr(name string) (object.Object, bool) {
+	return progressMethods.GetAttr(p, name)
+}
+
+func (p *Progress) SetAttr(name string, value object.Object) error {
+	return object.TypeErrorf("progress has no attribute %q", name)
+}
+
+func (p *Progress) IsTruthy() bool {
+	return true
+}
+
+func (p *Progress) RunOperation(opType op.BinaryOpType, right object.Object) (object.Object, error) {
+	return nil, object.TypeErrorf("unsupported operation for progress: %v", opType)
+}
@@ -0,0 +1,182 @@
+package term
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+	"github.com/deepnoodle-ai/risor/v2/pkg/op"
+)
+
+const SPINNER object.Type = "spinner"
+
+// spinnerInterval is how often a spinner advances to its next frame.
+const spinnerInterval = 100 * time.Millisecond
+
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+var spinnerMethods = object.NewMethodRegistry[*Spinner]("spinner")
+
+func init() {
+	spinnerMethods.Define("update").
+		Doc("Change the message shown beside the spinner").
+		Arg("message").
+		Returns("spinner").
+		Impl(func(s *Spinner, ctx context.Context, args ...object.Object) (object.Object, error) {
+			message, err := object.AsString(args[0])
+			if err != nil {
+				return nil, err
+			}
+			s.mu.Lock()
+			s.message = message
+			s.mu.Unlock()
+			return s, nil
+		})
+
+	spinnerMethods.Define("stop").
+		Doc("Stop the spinner, clearing its line or replacing it with a final message").
+		OptionalArg("message").
+		Returns("nil").
+		Impl(func(s *Spinner, ctx context.Context, args ...object.Object) (object.Object, error) {
+			final := ""
+			if len(args) == 1 {
+				var err error
+				if final, err = object.AsString(args[0]); err != nil {
+					return nil, err
+				}
+			}
+			s.stop(final)
+			return object.Nil, nil
+		})
+}
+
+// Spinner animates on the terminal, in the background, while a script does
+// work of unknown length.
+type Spinner struct {
+	term    *config
+	mu      sync.Mutex
+	message string
+	stopped bool
+	quit    chan struct{}
+	exited  chan struct{}
+}
+
+func newSpinner(c *config) object.BuiltinFunction {
+	return func(ctx context.Context, args ...object.Object) (object.Object, error) {
+		if len(args) > 1 {
+			return nil, object.NewArgsRangeError("term.spinner", 0, 1, len(args))
+		}
+		s := &Spinner{term: c}
+		if len(args) == 1 {
+			var err error
+			if s.message, err = object.AsString(args[0]); err != nil {
+				return nil, err
+			}
+		}
+		if c.out == nil {
+			s.stopped = true
+			return s, nil
+		}
+		s.quit = make(chan struct{})
+		s.exited = make(chan struct{})
+		// Make sure a spinner the script forgets to stop does not outlive
+		// the run.
+		if register, ok := object.GetExitHookFunc(ctx); ok {
+			stopOnExit := object.NewBuiltin("spinner.stop", func(ctx context.Context, args ...object.Object) (object.Object, error) {
+				s.stop("")
+				return object.Nil, nil
+			})
+			if err := register(stopOnExit); err != nil {
+				return nil, err
+			}
+		}
+		s.draw(0)
+		go s.run(ctx)
+		return s, nil
+	}
+}
+
+func (s *Spinner) run(ctx context.Context) {
+	defer close(s.exited)
+	ticker := time.NewTicker(spinnerInterval)
+	defer ticker.Stop()
+	for frame := 1; ; frame++ {
+		select {
+		case <-ticker.C:
+		case <-s.quit:
+			return
+		case <-ctx.Done():
+			return
+		}
+		s.draw(frame)
+	}
+}
+
+func (s *Spinner) draw(frame int) {
+	s.mu.Lock()
+	message := s.message
+	s.mu.Unlock()
+	s.term.write("\r\x1b[2K" + spinnerFrames[frame%len(spinnerFrames)] + " " + message)
+}
+
+// stop ends the animation and clears its line, writing final in its place
+// if it is not empty. Stopping twice has no further effect.
+func (s *Spinner) stop(final string) {
+	s.mu.Lock()
+	if s.stopped {
+		s.mu.Unlock()
+		return
+	}
+	s.stopped = true
+	s.mu.Unlock()
+	close(s.quit)
+	<-s.exited
+	s.term.write("\r\x1b[2K")
+	if final != "" {
+		s.term.write(final + "\n")
+	}
+}
+
+func (s *Spinner) Type() object.Type {
+	return SPINNER
+}
+
+func (s *Spinner) Inspect() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return fmt.Sprintf("spinner(%q)", s.message)
+}
+
+func (s *Spinner) String() string {
+	return s.Inspect()
+}
+
+func (s *Spinner) Interface() any {
+	return s
+}
+
+func (s *Spinner) Equals(other object.Object) bool {
+	return s == other
+}
+
+func (s *Spinner) Attrs() []object.AttrSpec {
+	return spinnerMethods.Specs()
+}
+
+func (s *Spinner) GetAttr(name string) (object.Object, bool) {
+	return spinnerMethods.GetAttr(s, name)
+}
+
+func (s *Spinner) SetAttr(name string, value object.Object) error {
+	return object.TypeErrorf("spinner has no attribute %q", name)
+}
+
+func (s *Spinner) IsTruthy() bool {
+	return true
+}
+
+func (s *Spinner) RunOperation(opType op.BinaryOpType, right object.Object) (object.Object, error) {
+	return nil, object.TypeErrorf("unsupported operation for spinner: %v", opType)
+}
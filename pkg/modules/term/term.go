@@ -0,0 +1,216 @@
+package term
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+)
+
+// Option configures the term module.
+type Option func(*config)
+
+type config struct {
+	out   io.Writer
+	color bool
+	size  func() (width, height int, ok bool)
+	mu    sync.Mutex // serializes writes to out
+}
+
+// WithTerminal directs cursor control, progress bars, and spinners to out
+// and enables colors. The host passes it only when out is an interactive
+// terminal. Without it the module writes nothing and styles return plain
+// text, so scripts that use it still produce clean output when piped or
+// embedded.
+func WithTerminal(out io.Writer) Option {
+	return func(c *config) {
+		c.out = out
+		c.color = true
+	}
+}
+
+// WithColor overrides whether style adds color and formatting codes, for
+// example to honor the NO_COLOR convention.
+func WithColor(enabled bool) Option {
+	return func(c *config) {
+		c.color = enabled
+	}
+}
+
+// WithSize supplies the terminal dimensions reported by size. fn returns
+// false if the size is unknown.
+func WithSize(fn func() (width, height int, ok bool)) Option {
+	return func(c *config) {
+		c.size = fn
+	}
+}
+
+// write sends s to the terminal, if there is one.
+func (c *config) write(s string) {
+	if c.out == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	io.WriteString(c.out, s)
+}
+
+// sgrCodes maps style names to ANSI Select Graphic Rendition parameters.
+var sgrCodes = map[string]string{
+	"bold":          "1",
+	"dim":           "2",
+	"italic":        "3",
+	"underline":     "4",
+	"reverse":       "7",
+	"strikethrough": "9",
+	"black":         "30",
+	"red":           "31",
+	"green":         "32",
+	"yellow":        "33",
+	"blue":          "34",
+	"magenta":       "35",
+	"cyan":          "36",
+	"white":         "37",
+	"gray":          "90",
+	"bg_black":      "40",
+	"bg_red":        "41",
+	"bg_green":      "42",
+	"bg_yellow":     "43",
+	"bg_blue":       "44",
+	"bg_magenta":    "45",
+	"bg_cyan":       "46",
+	"bg_white":      "47",
+	"bg_gray":       "100",
+}
+
+func style(c *config) object.BuiltinFunction {
+	return func(ctx context.Context, args ...object.Object) (object.Object, error) {
+		if len(args) < 1 {
+			return nil, fmt.Errorf("term.style: expected at least 1 argument, got %d", len(args))
+		}
+		text, err := object.AsString(args[0])
+		if err != nil {
+			return nil, err
+		}
+		codes := make([]string, 0, len(args)-1)
+		for _, arg := range args[1:] {
+			name, err := object.AsString(arg)
+			if err != nil {
+				return nil, err
+			}
+			code, ok := sgrCodes[name]
+			if !ok {
+				return nil, object.ValueErrorf("term.style: unknown style %q", name)
+			}
+			codes = append(codes, code)
+		}
+		if !c.color || len(codes) == 0 {
+			return object.NewString(text), nil
+		}
+		return object.NewString("\x1b[" + strings.Join(codes, ";") + "m" + text + "\x1b[0m"), nil
+	}
+}
+
+var escapeSequence = regexp.MustCompile(`\x1b\[[0-9;?]*[A-Za-z]`)
+
+// Strip removes ANSI escape sequences from a string.
+func Strip(ctx context.Context, args ...object.Object) (object.Object, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("term.strip: expected 1 argument, got %d", len(args))
+	}
+	text, err := object.AsString(args[0])
+	if err != nil {
+		return nil, err
+	}
+	return object.NewString(escapeSequence.ReplaceAllString(text, "")), nil
+}
+
+func isTTY(c *config) object.BuiltinFunction {
+	return func(ctx context.Context, args ...object.Object) (object.Object, error) {
+		if len(args) != 0 {
+			return nil, fmt.Errorf("term.is_tty: expected 0 arguments, got %d", len(args))
+		}
+		return object.NewBool(c.out != nil), nil
+	}
+}
+
+func size(c *config) object.BuiltinFunction {
+	return func(ctx context.Context, args ...object.Object) (object.Object, error) {
+		if len(args) != 0 {
+			return nil, fmt.Errorf("term.size: expected 0 arguments, got %d", len(args))
+		}
+		if c.size == nil {
+			return object.Nil, nil
+		}
+		width, height, ok := c.size()
+		if !ok {
+			return object.Nil, nil
+		}
+		return object.NewMap(map[string]object.Object{
+			"width":  object.NewInt(int64(width)),
+			"height": object.NewInt(int64(height)),
+		}), nil
+	}
+}
+
+// control returns a function that writes a fixed escape sequence.
+func control(c *config, name, seq string) object.BuiltinFunction {
+	return func(ctx context.Context, args ...object.Object) (object.Object, error) {
+		if len(args) != 0 {
+			return nil, fmt.Errorf("term.%s: expected 0 arguments, got %d", name, len(args))
+		}
+		c.write(seq)
+		return object.Nil, nil
+	}
+}
+
+// moveCursor returns a function that moves the cursor n lines (default 1)
+// in the direction given by code: "A" for up and "B" for down.
+func moveCursor(c *config, name, code string) object.BuiltinFunction {
+	return func(ctx context.Context, args ...object.Object) (object.Object, error) {
+		if len(args) > 1 {
+			return nil, fmt.Errorf("term.%s: expected 0 or 1 arguments, got %d", name, len(args))
+		}
+		n := int64(1)
+		if len(args) == 1 {
+			var err error
+			if n, err = object.AsInt(args[0]); err != nil {
+				return nil, err
+			}
+			if n < 0 {
+				return nil, object.ValueErrorf("term.%s: n must be non-negative, got %d", name, n)
+			}
+		}
+		if n > 0 {
+			c.write(fmt.Sprintf("\x1b[%d%s", n, code))
+		}
+		return object.Nil, nil
+	}
+}
+
+// Module returns the term module. Without options it never writes and
+// styles return plain text; see WithTerminal.
+func Module(opts ...Option) *object.Module {
+	c := &config{}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return object.NewBuiltinsModule("term", map[string]object.Object{
+		"style":       object.NewBuiltin("style", style(c)),
+		"strip":       object.NewBuiltin("strip", Strip),
+		"is_tty":      object.NewBuiltin("is_tty", isTTY(c)),
+		"size":        object.NewBuiltin("size", size(c)),
+		"clear":       object.NewBuiltin("clear", control(c, "clear", "\x1b[2J\x1b[H")),
+		"clear_line":  object.NewBuiltin("clear_line", control(c, "clear_line", "\r\x1b[2K")),
+		"hide_cursor": object.NewBuiltin("hide_cursor", control(c, "hide_cursor", "\x1b[?25l")),
+		"show_cursor": object.NewBuiltin("show_cursor", control(c, "show_cursor", "\x1b[?25h")),
+		"cursor_up":   object.NewBuiltin("cursor_up", moveCursor(c, "cursor_up", "A")),
+		"cursor_down": object.NewBuiltin("cursor_down", moveCursor(c, "cursor_down", "B")),
+		"progress":    object.NewBuiltin("progress", newProgress(c)),
+		"spinner":     object.NewBuiltin("spinner", newSpinner(c)),
+	})
+}
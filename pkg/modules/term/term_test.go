@@ -0,0 +1,144 @@
+package term
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+func call(t *testing.T, m *object.Module, name string, args ...object.Object) object.Object {
+	t.Helper()
+	fn, ok := m.GetAttr(name)
+	assert.True(t, ok, name)
+	result, err := fn.(object.Callable).Call(context.Background(), args...)
+	assert.Nil(t, err)
+	return result
+}
+
+func callMethod(t *testing.T, obj object.Object, name string, args ...object.Object) object.Object {
+	t.Helper()
+	attr, ok := obj.(interface {
+		GetAttr(string) (object.Object, bool)
+	}).GetAttr(name)
+	assert.True(t, ok, name)
+	if len(args) == 0 {
+		if _, isCallable := attr.(object.Callable); !isCallable {
+			return attr
+		}
+	}
+	result, err := attr.(object.Callable).Call(context.Background(), args...)
+	assert.Nil(t, err)
+	return result
+}
+
+func TestInertByDefault(t *testing.T) {
+	m := Module()
+	assert.Equal(t, call(t, m, "style", object.NewString("ok"), object.NewString("red")), object.NewString("ok"))
+	assert.Equal(t, call(t, m, "is_tty"), object.False)
+	assert.Equal(t, call(t, m, "size"), object.Nil)
+	assert.Equal(t, call(t, m, "clear"), object.Nil)
+
+	bar := call(t, m, "progress", object.NewInt(10))
+	callMethod(t, bar, "increment")
+	assert.Equal(t, callMethod(t, bar, "current"), object.NewInt(1))
+
+	spinner := call(t, m, "spinner", object.NewString("working"))
+	assert.Equal(t, callMethod(t, spinner, "stop", object.NewString("done")), object.Nil)
+}
+
+func TestStyle(t *testing.T) {
+	var out bytes.Buffer
+	m := Module(WithTerminal(&out))
+	result := call(t, m, "style", object.NewString("ok"), object.NewString("green"), object.NewString("bold"))
+	assert.Equal(t, result, object.NewString("\x1b[32;1mok\x1b[0m"))
+	assert.Equal(t, call(t, m, "style", object.NewString("ok")), object.NewString("ok"))
+	assert.Equal(t, out.Len(), 0)
+
+	m = Module(WithTerminal(&out), WithColor(false))
+	assert.Equal(t, call(t, m, "style", object.NewString("ok"), object.NewString("bg_red")), object.NewString("ok"))
+
+	fn, _ := m.GetAttr("style")
+	_, err := fn.(object.Callable).Call(context.Background(), object.NewString("ok"), object.NewString("mauve"))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown style "mauve"`)
+}
+
+func TestStrip(t *testing.T) {
+	result, err := Strip(context.Background(), object.NewString("\x1b[32;1mok\x1b[0m \x1b[?25lthen\x1b[2K"))
+	assert.Nil(t, err)
+	assert.Equal(t, result, object.NewString("ok then"))
+}
+
+func TestSize(t *testing.T) {
+	m := Module(WithSize(func() (int, int, bool) { return 120, 40, true }))
+	size := call(t, m, "size").(*object.Map)
+	assert.Equal(t, size.Get("width"), object.NewInt(120))
+	assert.Equal(t, size.Get("height"), object.NewInt(40))
+
+	m = Module(WithSize(func() (int, int, bool) { return 0, 0, false }))
+	assert.Equal(t, call(t, m, "size"), object.Nil)
+}
+
+func TestCursor(t *testing.T) {
+	var out bytes.Buffer
+	m := Module(WithTerminal(&out))
+	assert.Equal(t, call(t, m, "is_tty"), object.True)
+	call(t, m, "cursor_up")
+	call(t, m, "cursor_down", object.NewInt(3))
+	call(t, m, "cursor_up", object.NewInt(0))
+	call(t, m, "hide_cursor")
+	call(t, m, "show_cursor")
+	call(t, m, "clear_line")
+	assert.Equal(t, out.String(), "\x1b[1A\x1b[3B\x1b[?25l\x1b[?25h\r\x1b[2K")
+}
+
+func TestProgress(t *testing.T) {
+	var out bytes.Buffer
+	m := Module(WithTerminal(&out))
+	opts := object.NewMap(map[string]object.Object{
+		"label": object.NewString("copying"),
+		"width": object.NewInt(10),
+	})
+	bar := call(t, m, "progress", object.NewInt(4), opts)
+	assert.Equal(t, out.String(), "\r\x1b[2Kcopying [----------]   0% (0/4)")
+
+	out.Reset()
+	callMethod(t, bar, "increment")
+	assert.Equal(t, out.String(), "\r\x1b[2Kcopying [##--------]  25% (1/4)")
+
+	callMethod(t, bar, "update", object.NewInt(99))
+	assert.Equal(t, callMethod(t, bar, "current"), object.NewInt(4))
+	assert.Equal(t, callMethod(t, bar, "total"), object.NewInt(4))
+
+	out.Reset()
+	callMethod(t, bar, "update", object.NewInt(2))
+	callMethod(t, bar, "done")
+	callMethod(t, bar, "done")
+	assert.Equal(t, out.String(),
+		"\r\x1b[2Kcopying [#####-----]  50% (2/4)\r\x1b[2Kcopying [##########] 100% (4/4)\n")
+
+	fn, _ := m.GetAttr("progress")
+	_, err := fn.(object.Callable).Call(context.Background(), object.NewInt(0))
+	assert.Error(t, err)
+	_, err = fn.(object.Callable).Call(context.Background(), object.NewInt(1),
+		object.NewMap(map[string]object.Object{"colour": object.NewString("red")}))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown option "colour"`)
+}
+
+func TestSpinner(t *testing.T) {
+	var out bytes.Buffer
+	m := Module(WithTerminal(&out))
+	spinner := call(t, m, "spinner", object.NewString("working"))
+	callMethod(t, spinner, "update", object.NewString("still working"))
+	callMethod(t, spinner, "stop", object.NewString("finished"))
+	callMethod(t, spinner, "stop", object.NewString("again"))
+	output := out.String()
+	assert.Contains(t, output, "⠋ working")
+	assert.True(t, bytes.HasSuffix(out.Bytes(), []byte("\r\x1b[2Kfinished\n")))
+	assert.Equal(t, bytes.Count(out.Bytes(), []byte("finished")), 1)
+	assert.Equal(t, bytes.Count(out.Bytes(), []byte("again")), 0)
+}
@@ -0,0 +1,36 @@
+package unicode
+
+import "github.com/deepnoodle-ai/risor/v2/pkg/object"
+
+func init() {
+	object.RegisterModule("unicode", ModuleDoc(), Docs)
+}
+
+// Docs returns documentation for the unicode module.
+func Docs() []object.FuncSpec {
+	return unicodeDocs
+}
+
+// ModuleDoc returns the module-level documentation.
+func ModuleDoc() string {
+	return "Character classes, normalization, and grapheme clusters"
+}
+
+var unicodeDocs = []object.FuncSpec{
+	{Name: "is_letter", Doc: "Check if all characters are letters", Args: []string{"s"}, Returns: "bool"},
+	{Name: "is_digit", Doc: "Check if all characters are decimal digits", Args: []string{"s"}, Returns: "bool"},
+	{Name: "is_number", Doc: "Check if all characters are numeric", Args: []string{"s"}, Returns: "bool"},
+	{Name: "is_space", Doc: "Check if all characters are whitespace", Args: []string{"s"}, Returns: "bool"},
+	{Name: "is_upper", Doc: "Check if all characters are uppercase letters", Args: []string{"s"}, Returns: "bool"},
+	{Name: "is_lower", Doc: "Check if all characters are lowercase letters", Args: []string{"s"}, Returns: "bool"},
+	{Name: "is_punct", Doc: "Check if all characters are punctuation", Args: []string{"s"}, Returns: "bool"},
+	{Name: "is_symbol", Doc: "Check if all characters are symbols", Args: []string{"s"}, Returns: "bool"},
+	{Name: "is_mark", Doc: "Check if all characters are combining marks", Args: []string{"s"}, Returns: "bool"},
+	{Name: "is_control", Doc: "Check if all characters are control characters", Args: []string{"s"}, Returns: "bool"},
+	{Name: "is_print", Doc: "Check if all characters are printable", Args: []string{"s"}, Returns: "bool"},
+	{Name: "category", Doc: "General category of a character", Args: []string{"char"}, Returns: "string"},
+	{Name: "normalize", Doc: "Convert to a normalization form", Args: []string{"s", "form?"}, Returns: "string"},
+	{Name: "is_normalized", Doc: "Check if in a normalization form", Args: []string{"s", "form?"}, Returns: "bool"},
+	{Name: "graphemes", Doc: "Iterate over grapheme clusters", Args: []string{"s"}, Returns: "iter"},
+	{Name: "width", Doc: "Display width in terminal columns", Args: []string{"s"}, Returns: "int"},
+}
@@ -0,0 +1,200 @@
+package unicode
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+	"github.com/rivo/uniseg"
+	"golang.org/x/text/unicode/norm"
+)
+
+// predicates are the character class tests exposed as is_* functions.
+var predicates = []struct {
+	name string
+	test func(rune) bool
+}{
+	{"is_letter", unicode.IsLetter},
+	{"is_digit", unicode.IsDigit},
+	{"is_number", unicode.IsNumber},
+	{"is_space", unicode.IsSpace},
+	{"is_upper", unicode.IsUpper},
+	{"is_lower", unicode.IsLower},
+	{"is_punct", unicode.IsPunct},
+	{"is_symbol", unicode.IsSymbol},
+	{"is_mark", unicode.IsMark},
+	{"is_control", unicode.IsControl},
+	{"is_print", unicode.IsPrint},
+}
+
+// newPredicate returns a builtin that reports whether its argument is a
+// non-empty string made up only of characters that pass test.
+func newPredicate(name string, test func(rune) bool) object.BuiltinFunction {
+	return func(ctx context.Context, args ...object.Object) (object.Object, error) {
+		if len(args) != 1 {
+			return nil, object.NewArgsError("unicode."+name, 1, len(args))
+		}
+		s, err := object.AsString(args[0])
+		if err != nil {
+			return nil, err
+		}
+		if s == "" {
+			return object.False, nil
+		}
+		for _, r := range s {
+			if !test(r) {
+				return object.False, nil
+			}
+		}
+		return object.True, nil
+	}
+}
+
+// categories lists the two-letter general categories in the order they are
+// checked.
+var categories = []string{
+	"Lu", "Ll", "Lt", "Lm", "Lo",
+	"Mn", "Mc", "Me",
+	"Nd", "Nl", "No",
+	"Pc", "Pd", "Ps", "Pe", "Pi", "Pf", "Po",
+	"Sm", "Sc", "Sk", "So",
+	"Zs", "Zl", "Zp",
+	"Cc", "Cf", "Co", "Cs",
+}
+
+// Category returns the two-letter Unicode general category of a single
+// character, such as "Lu" for an uppercase letter. Unassigned characters
+// are reported as "Cn".
+func Category(ctx context.Context, args ...object.Object) (object.Object, error) {
+	if len(args) != 1 {
+		return nil, object.NewArgsError("unicode.category", 1, len(args))
+	}
+	s, err := object.AsString(args[0])
+	if err != nil {
+		return nil, err
+	}
+	runes := []rune(s)
+	if len(runes) != 1 {
+		return nil, object.ValueErrorf("unicode.category: expected a single character (got %d)", len(runes))
+	}
+	for _, name := range categories {
+		if unicode.Is(unicode.Categories[name], runes[0]) {
+			return object.NewString(name), nil
+		}
+	}
+	return object.NewString("Cn"), nil
+}
+
+// formArg returns the normalization form named by the optional argument at
+// args[index], defaulting to NFC.
+func formArg(fn string, args []object.Object, index int) (norm.Form, error) {
+	if len(args) <= index {
+		return norm.NFC, nil
+	}
+	name, err := object.AsString(args[index])
+	if err != nil {
+		return 0, err
+	}
+	switch strings.ToUpper(name) {
+	case "NFC":
+		return norm.NFC, nil
+	case "NFD":
+		return norm.NFD, nil
+	case "NFKC":
+		return norm.NFKC, nil
+	case "NFKD":
+		return norm.NFKD, nil
+	default:
+		return 0, object.ValueErrorf("unicode.%s: unknown normalization form %q (expected NFC, NFD, NFKC, or NFKD)", fn, name)
+	}
+}
+
+// Normalize returns a string converted to a normalization form: "NFC" (the
+// default), "NFD", "NFKC", or "NFKD".
+func Normalize(ctx context.Context, args ...object.Object) (object.Object, error) {
+	if len(args) < 1 || len(args) > 2 {
+		return nil, object.NewArgsRangeError("unicode.normalize", 1, 2, len(args))
+	}
+	s, err := object.AsString(args[0])
+	if err != nil {
+		return nil, err
+	}
+	form, err := formArg("normalize", args, 1)
+	if err != nil {
+		return nil, err
+	}
+	return object.NewString(form.String(s)), nil
+}
+
+// IsNormalized reports whether a string is already in a normalization form,
+// NFC by default.
+func IsNormalized(ctx context.Context, args ...object.Object) (object.Object, error) {
+	if len(args) < 1 || len(args) > 2 {
+		return nil, object.NewArgsRangeError("unicode.is_normalized", 1, 2, len(args))
+	}
+	s, err := object.AsString(args[0])
+	if err != nil {
+		return nil, err
+	}
+	form, err := formArg("is_normalized", args, 1)
+	if err != nil {
+		return nil, err
+	}
+	return object.NewBool(form.IsNormalString(s)), nil
+}
+
+// Graphemes returns an iterator over the grapheme clusters of a string: the
+// units a reader sees as single characters, such as a letter with combining
+// accents or an emoji sequence.
+func Graphemes(ctx context.Context, args ...object.Object) (object.Object, error) {
+	if len(args) != 1 {
+		return nil, object.NewArgsError("unicode.graphemes", 1, len(args))
+	}
+	s, err := object.AsString(args[0])
+	if err != nil {
+		return nil, err
+	}
+	desc := fmt.Sprintf("unicode.graphemes(%s)", args[0].Inspect())
+	return object.NewIterFunc(desc, func(ctx context.Context, fn func(key, value object.Object) bool) error {
+		rest, state, index := s, -1, int64(0)
+		for rest != "" {
+			var cluster string
+			cluster, rest, _, state = uniseg.FirstGraphemeClusterInString(rest, state)
+			if !fn(object.NewInt(index), object.NewString(cluster)) {
+				return nil
+			}
+			index++
+		}
+		return nil
+	}), nil
+}
+
+// Width returns the number of terminal columns a string occupies. Wide
+// characters such as CJK ideographs and most emoji count as two columns,
+// and combining marks count as none.
+func Width(ctx context.Context, args ...object.Object) (object.Object, error) {
+	if len(args) != 1 {
+		return nil, object.NewArgsError("unicode.width", 1, len(args))
+	}
+	s, err := object.AsString(args[0])
+	if err != nil {
+		return nil, err
+	}
+	return object.NewInt(int64(uniseg.StringWidth(s))), nil
+}
+
+func Module() *object.Module {
+	funcs := map[string]object.Object{
+		"category":      object.NewBuiltin("category", Category),
+		"normalize":     object.NewBuiltin("normalize", Normalize),
+		"is_normalized": object.NewBuiltin("is_normalized", IsNormalized),
+		"graphemes":     object.NewBuiltin("graphemes", Graphemes),
+		"width":         object.NewBuiltin("width", Width),
+	}
+	for _, p := range predicates {
+		funcs[p.name] = object.NewBuiltin(p.name, newPredicate(p.name, p.test))
+	}
+	return object.NewBuiltinsModule("unicode", funcs)
+}
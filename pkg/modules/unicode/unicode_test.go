@@ -0,0 +1,117 @@
+package unicode
+
+import (
+	"context"
+	"testing"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+func call(t *testing.T, name string, args ...object.Object) (object.Object, error) {
+	t.Helper()
+	fn, ok := Module().GetAttr(name)
+	assert.True(t, ok, "missing function %s", name)
+	return fn.(object.Callable).Call(context.Background(), args...)
+}
+
+func str(s string) *object.String {
+	return object.NewString(s)
+}
+
+func TestPredicates(t *testing.T) {
+	tests := []struct {
+		fn       string
+		s        string
+		expected bool
+	}{
+		{"is_letter", "abc", true},
+		{"is_letter", "日本", true},
+		{"is_letter", "ab1", false},
+		{"is_letter", "", false},
+		{"is_digit", "0123", true},
+		{"is_digit", "٣", true},
+		{"is_digit", "½", false},
+		{"is_number", "½", true},
+		{"is_space", " \t\n", true},
+		{"is_upper", "ÉA", true},
+		{"is_lower", "éa", true},
+		{"is_punct", "!?", true},
+		{"is_symbol", "+€", true},
+		{"is_mark", "́", true},
+		{"is_control", "\x00", true},
+		{"is_print", "a b", true},
+		{"is_print", "a\n", false},
+	}
+	for _, tc := range tests {
+		result, err := call(t, tc.fn, str(tc.s))
+		assert.Nil(t, err)
+		assert.Equal(t, result, object.NewBool(tc.expected), "%s(%q)", tc.fn, tc.s)
+	}
+	_, err := call(t, "is_letter", object.NewInt(1))
+	assert.Error(t, err)
+}
+
+func TestCategory(t *testing.T) {
+	tests := map[string]string{
+		"A": "Lu", "a": "Ll", "1": "Nd", " ": "Zs", "-": "Pd",
+		"€": "Sc", "́": "Mn", "‍": "Cf", "\U000E0080": "Cn",
+	}
+	for s, expected := range tests {
+		result, err := call(t, "category", str(s))
+		assert.Nil(t, err)
+		assert.Equal(t, result, str(expected), "category(%q)", s)
+	}
+	_, err := call(t, "category", str("ab"))
+	assert.Error(t, err)
+}
+
+func TestNormalize(t *testing.T) {
+	composed, decomposed := "café", "café"
+	result, err := call(t, "normalize", str(decomposed))
+	assert.Nil(t, err)
+	assert.Equal(t, result, str(composed))
+	result, err = call(t, "normalize", str(composed), str("nfd"))
+	assert.Nil(t, err)
+	assert.Equal(t, result, str(decomposed))
+	result, err = call(t, "normalize", str("ﬁ²"), str("NFKC"))
+	assert.Nil(t, err)
+	assert.Equal(t, result, str("fi2"))
+
+	result, err = call(t, "is_normalized", str(decomposed))
+	assert.Nil(t, err)
+	assert.Equal(t, result, object.False)
+	result, err = call(t, "is_normalized", str(decomposed), str("NFD"))
+	assert.Nil(t, err)
+	assert.Equal(t, result, object.True)
+
+	_, err = call(t, "normalize", str("a"), str("NFX"))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown normalization form")
+}
+
+func TestGraphemes(t *testing.T) {
+	ctx := context.Background()
+	result, err := call(t, "graphemes", str("é👍🏽🇯🇵!"))
+	assert.Nil(t, err)
+	list, err := result.(*object.Iter).Collect(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, list, object.NewStringList([]string{"é", "👍🏽", "🇯🇵", "!"}))
+
+	result, err = call(t, "graphemes", str(""))
+	assert.Nil(t, err)
+	list, err = result.(*object.Iter).Collect(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, list.Len(), object.NewInt(0))
+}
+
+func TestWidth(t *testing.T) {
+	tests := map[string]int64{
+		"abc": 3, "日本": 4, "é": 1, "👍": 2, "": 0,
+	}
+	for s, expected := range tests {
+		result, err := call(t, "width", str(s))
+		assert.Nil(t, err)
+		assert.Equal(t, result, object.NewInt(expected), "width(%q)", s)
+	}
+}
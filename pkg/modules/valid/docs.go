@@ -0,0 +1,27 @@
+package valid
+
+import "github.com/deepnoodle-ai/risor/v2/pkg/object"
+
+func init() {
+	object.RegisterModule("valid", ModuleDoc(), Docs)
+}
+
+// Docs returns documentation for the valid module.
+func Docs() []object.FuncSpec {
+	return validDocs
+}
+
+// ModuleDoc returns the module-level documentation.
+func ModuleDoc() string {
+	return "Schemas that validate and coerce untrusted input"
+}
+
+var validDocs = []object.FuncSpec{
+	{Name: "any", Doc: "Schema that accepts any non-null value", Returns: "valid_schema"},
+	{Name: "bool", Doc: "Schema for booleans, converting \"true\" and \"false\"", Returns: "valid_schema"},
+	{Name: "float", Doc: "Schema for numbers, converting ints and numeric strings", Returns: "valid_schema"},
+	{Name: "int", Doc: "Schema for integers, converting whole floats and numeric strings", Returns: "valid_schema"},
+	{Name: "list", Doc: "Schema for lists, optionally validating each item", Args: []string{"item?"}, Returns: "valid_schema"},
+	{Name: "map", Doc: "Schema for maps, optionally with a schema per field", Args: []string{"fields?"}, Returns: "valid_schema"},
+	{Name: "string", Doc: "Schema for strings", Returns: "valid_schema"},
+}
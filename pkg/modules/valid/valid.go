@@ -0,0 +1,74 @@
+package valid
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+)
+
+func scalar(kind string) object.BuiltinFunction {
+	return func(ctx context.Context, args ...object.Object) (object.Object, error) {
+		if len(args) != 0 {
+			return nil, object.NewArgsError("valid."+kind, 0, len(args))
+		}
+		return newSchema(kind), nil
+	}
+}
+
+// List returns a schema for lists. If an item schema is given, every item
+// is validated against it.
+func List(ctx context.Context, args ...object.Object) (object.Object, error) {
+	if len(args) > 1 {
+		return nil, object.NewArgsRangeError("valid.list", 0, 1, len(args))
+	}
+	s := newSchema("list")
+	if len(args) == 1 {
+		item, ok := args[0].(*Schema)
+		if !ok {
+			return nil, object.TypeErrorf("valid.list: expected a valid_schema (%s given)", args[0].Type())
+		}
+		s.item = item
+	}
+	return s, nil
+}
+
+// Map returns a schema for maps. If a map of field schemas is given, the
+// result holds only those fields, each validated against its schema.
+func Map(ctx context.Context, args ...object.Object) (object.Object, error) {
+	if len(args) > 1 {
+		return nil, object.NewArgsRangeError("valid.map", 0, 1, len(args))
+	}
+	s := newSchema("map")
+	if len(args) == 1 {
+		fields, err := object.AsMap(args[0])
+		if err != nil {
+			return nil, err
+		}
+		s.fields = make(map[string]*Schema, fields.Size())
+		if err := fields.CheckStringKeys(); err != nil {
+			return nil, fmt.Errorf("valid.map: %w", err)
+		}
+		for _, name := range fields.SortedKeys() {
+			field, ok := fields.Get(name).(*Schema)
+			if !ok {
+				return nil, object.TypeErrorf("valid.map: field %q expected a valid_schema (%s given)", name, fields.Get(name).Type())
+			}
+			s.fields[name] = field
+		}
+	}
+	return s, nil
+}
+
+// Module returns the valid module.
+func Module() *object.Module {
+	return object.NewBuiltinsModule("valid", map[string]object.Object{
+		"any":    object.NewBuiltin("any", scalar("any")),
+		"bool":   object.NewBuiltin("bool", scalar("bool")),
+		"float":  object.NewBuiltin("float", scalar("float")),
+		"int":    object.NewBuiltin("int", scalar("int")),
+		"list":   object.NewBuiltin("list", List),
+		"map":    object.NewBuiltin("map", Map),
+		"string": object.NewBuiltin("string", scalar("string")),
+	})
+}
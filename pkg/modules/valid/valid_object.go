@@ -0,0 +1,604 @@
+package valid
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+	"github.com/deepnoodle-ai/risor/v2/pkg/op"
+)
+
+const SCHEMA object.Type = "valid_schema"
+
+var schemaMethods = object.NewMethodRegistry[*Schema]("valid_schema")
+
+func init() {
+	schemaMethods.Define("kind").
+		Doc("Kind of value the schema accepts, such as int or map").
+		Returns("string").
+		Getter(func(s *Schema) object.Object {
+			return object.NewString(s.kind)
+		})
+
+	schemaMethods.Define("optional").
+		Doc("Allow the value to be null or missing").
+		Returns("valid_schema").
+		Impl(func(s *Schema, ctx context.Context, args ...object.Object) (object.Object, error) {
+			c := s.clone()
+			c.optional = true
+			return c, nil
+		})
+
+	schemaMethods.Define("default").
+		Doc("Use a value when the input is null or missing").
+		Arg("value").
+		Returns("valid_schema").
+		Impl(func(s *Schema, ctx context.Context, args ...object.Object) (object.Object, error) {
+			c := s.clone()
+			c.def = args[0]
+			return c, nil
+		})
+
+	schemaMethods.Define("min").
+		Doc("Minimum value of a number, or minimum length of a string or list").
+		Arg("n").
+		Returns("valid_schema").
+		Impl(func(s *Schema, ctx context.Context, args ...object.Object) (object.Object, error) {
+			return s.withBounds("min", args[0], nil)
+		})
+
+	schemaMethods.Define("max").
+		Doc("Maximum value of a number, or maximum length of a string or list").
+		Arg("n").
+		Returns("valid_schema").
+		Impl(func(s *Schema, ctx context.Context, args ...object.Object) (object.Object, error) {
+			return s.withBounds("max", nil, args[0])
+		})
+
+	schemaMethods.Define("range").
+		Doc("Set the minimum and maximum together").
+		Args("min", "max").
+		Returns("valid_schema").
+		Impl(func(s *Schema, ctx context.Context, args ...object.Object) (object.Object, error) {
+			return s.withBounds("range", args[0], args[1])
+		})
+
+	schemaMethods.Define("pattern").
+		Doc("Require a string to match a regular expression").
+		Arg("regex").
+		Returns("valid_schema").
+		Impl(func(s *Schema, ctx context.Context, args ...object.Object) (object.Object, error) {
+			if err := s.requireKind("pattern", "string"); err != nil {
+				return nil, err
+			}
+			pattern, err := object.AsString(args[0])
+			if err != nil {
+				return nil, err
+			}
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, object.ValueErrorf("valid_schema.pattern: %v", err)
+			}
+			c := s.clone()
+			c.pattern = re
+			return c, nil
+		})
+
+	schemaMethods.Define("trim").
+		Doc("Strip leading and trailing whitespace from a string before checking it").
+		Returns("valid_schema").
+		Impl(func(s *Schema, ctx context.Context, args ...object.Object) (object.Object, error) {
+			if err := s.requireKind("trim", "string"); err != nil {
+				return nil, err
+			}
+			c := s.clone()
+			c.trim = true
+			return c, nil
+		})
+
+	schemaMethods.Define("one_of").
+		Doc("Require the value to equal one of the given values").
+		Arg("values").
+		Returns("valid_schema").
+		Impl(func(s *Schema, ctx context.Context, args ...object.Object) (object.Object, error) {
+			values, err := object.AsList(args[0])
+			if err != nil {
+				return nil, err
+			}
+			c := s.clone()
+			c.oneOf = values.Value()
+			return c, nil
+		})
+
+	schemaMethods.Define("check").
+		Doc("Add a custom check; fn receives the value and returns true if it is valid").
+		Arg("fn").
+		OptionalArg("message").
+		Returns("valid_schema").
+		Impl(func(s *Schema, ctx context.Context, args ...object.Object) (object.Object, error) {
+			fn, ok := args[0].(object.Callable)
+			if !ok {
+				return nil, object.TypeErrorf("valid_schema.check: expected a function (%s given)", args[0].Type())
+			}
+			message := "is invalid"
+			if len(args) == 2 {
+				var err error
+				if message, err = object.AsString(args[1]); err != nil {
+					return nil, err
+				}
+			}
+			c := s.clone()
+			c.checks = append(append([]check{}, s.checks...), check{fn, message})
+			return c, nil
+		})
+
+	schemaMethods.Define("strict").
+		Doc("Reject map keys that have no field schema instead of dropping them").
+		Returns("valid_schema").
+		Impl(func(s *Schema, ctx context.Context, args ...object.Object) (object.Object, error) {
+			if err := s.requireKind("strict", "map"); err != nil {
+				return nil, err
+			}
+			c := s.clone()
+			c.strict = true
+			return c, nil
+		})
+
+	schemaMethods.Define("parse").
+		Doc("Validate and coerce a value, raising an error if it is invalid").
+		Arg("value").
+		Returns("any").
+		Impl(func(s *Schema, ctx context.Context, args ...object.Object) (object.Object, error) {
+			return s.Parse(ctx, args[0])
+		})
+
+	schemaMethods.Define("validate").
+		Doc("Validate and coerce a value, returning {ok, value, errors}").
+		Arg("value").
+		Returns("map").
+		Impl(func(s *Schema, ctx context.Context, args ...object.Object) (object.Object, error) {
+			value, errs, err := s.Validate(ctx, args[0])
+			if err != nil {
+				return nil, err
+			}
+			errors := make(map[string]object.Object, len(errs))
+			for path, msg := range errs {
+				errors[path] = object.NewString(msg)
+			}
+			if len(errs) > 0 {
+				value = object.Nil
+			}
+			return object.NewMap(map[string]object.Object{
+				"ok":     object.NewBool(len(errs) == 0),
+				"value":  value,
+				"errors": object.NewMap(errors),
+			}), nil
+		})
+}
+
+type check struct {
+	fn      object.Callable
+	message string
+}
+
+// Schema describes the values a script accepts. Schemas are immutable:
+// every builder method returns a new schema.
+type Schema struct {
+	kind     string
+	optional bool
+	def      object.Object
+	min, max *float64
+	pattern  *regexp.Regexp
+	trim     bool
+	oneOf    []object.Object
+	checks   []check
+	item     *Schema
+	fields   map[string]*Schema
+	strict   bool
+}
+
+func newSchema(kind string) *Schema {
+	return &Schema{kind: kind}
+}
+
+func (s *Schema) clone() *Schema {
+	c := *s
+	return &c
+}
+
+func (s *Schema) requireKind(method string, kinds ...string) error {
+	for _, kind := range kinds {
+		if s.kind == kind {
+			return nil
+		}
+	}
+	return object.TypeErrorf("valid_schema.%s: not supported for %s schemas", method, s.kind)
+}
+
+func (s *Schema) withBounds(method string, lo, hi object.Object) (object.Object, error) {
+	if err := s.requireKind(method, "int", "float", "string", "list"); err != nil {
+		return nil, err
+	}
+	c := s.clone()
+	for _, bound := range []struct {
+		value object.Object
+		dst   **float64
+	}{{lo, &c.min}, {hi, &c.max}} {
+		if bound.value == nil {
+			continue
+		}
+		f, err := object.AsFloat(bound.value)
+		if err != nil {
+			return nil, err
+		}
+		*bound.dst = &f
+	}
+	if c.min != nil && c.max != nil && *c.min > *c.max {
+		return nil, object.ValueErrorf("valid_schema.%s: min %v is greater than max %v", method, *c.min, *c.max)
+	}
+	return c, nil
+}
+
+// Parse validates and coerces a value. If the value is invalid, the error
+// is an *object.ScriptError with the code "invalid" whose data holds the
+// errors map.
+func (s *Schema) Parse(ctx context.Context, value object.Object) (object.Object, error) {
+	result, errs, err := s.Validate(ctx, value)
+	if err != nil {
+		return nil, err
+	}
+	if len(errs) == 0 {
+		return result, nil
+	}
+	paths := make([]string, 0, len(errs))
+	errors := make(map[string]object.Object, len(errs))
+	for path, msg := range errs {
+		paths = append(paths, path)
+		errors[path] = object.NewString(msg)
+	}
+	sort.Strings(paths)
+	se := object.NewScriptError("invalid", object.NewMap(map[string]object.Object{
+		"errors": object.NewMap(errors),
+	}))
+	se.Message = fmt.Sprintf("invalid value: %s %s", paths[0], errs[paths[0]])
+	if len(paths) > 1 {
+		se.Message += fmt.Sprintf(" (and %d more)", len(paths)-1)
+	}
+	return nil, se
+}
+
+// Validate validates and coerces a value. It returns the coerced value and
+// a map from the path of each invalid value, such as ".items[0].name", to a
+// message describing the problem. The root value's path is ".". The error
+// is non-nil only if a custom check raised one.
+func (s *Schema) Validate(ctx context.Context, value object.Object) (object.Object, map[string]string, error) {
+	errs := map[string]string{}
+	result, err := s.validate(ctx, value, ".", errs)
+	if err != nil {
+		return nil, nil, err
+	}
+	return result, errs, nil
+}
+
+func (s *Schema) defaultValue() object.Object {
+	switch def := s.def.(type) {
+	case *object.List:
+		return def.Copy()
+	case *object.Map:
+		return def.Copy()
+	}
+	return s.def
+}
+
+// validate checks one value, recording problems in errs. It returns the
+// coerced value, or nil if the value is invalid.
+func (s *Schema) validate(ctx context.Context, value object.Object, path string, errs map[string]string) (object.Object, error) {
+	if value == nil || value == object.Nil {
+		if s.def != nil {
+			return s.defaultValue(), nil
+		}
+		if s.optional {
+			return object.Nil, nil
+		}
+		errs[path] = "is required"
+		return nil, nil
+	}
+	value, msg := s.coerce(value)
+	if msg != "" {
+		errs[path] = msg
+		return nil, nil
+	}
+	if msg := s.checkConstraints(value); msg != "" {
+		errs[path] = msg
+		return nil, nil
+	}
+	var err error
+	switch s.kind {
+	case "list":
+		value, err = s.validateList(ctx, value.(*object.List), path, errs)
+	case "map":
+		value, err = s.validateMap(ctx, value.(*object.Map), path, errs)
+	}
+	if err != nil || value == nil {
+		return nil, err
+	}
+	for _, c := range s.checks {
+		result, err := c.fn.Call(ctx, value)
+		if err != nil {
+			return nil, err
+		}
+		if !result.IsTruthy() {
+			errs[path] = c.message
+			return nil, nil
+		}
+	}
+	return value, nil
+}
+
+// coerce converts a value to the schema's kind, or returns a message if it
+// cannot. Numeric and boolean strings are converted, since query strings,
+// form fields, and environment variables carry every value as text.
+func (s *Schema) coerce(value object.Object) (object.Object, string) {
+	switch s.kind {
+	case "string":
+		str, ok := value.(*object.String)
+		if !ok {
+			return nil, typeMessage("a string", value)
+		}
+		if s.trim {
+			return object.NewString(strings.TrimSpace(str.Value())), ""
+		}
+		return str, ""
+	case "int":
+		switch v := value.(type) {
+		case *object.Int:
+			return v, ""
+		case *object.Byte:
+			return object.NewInt(int64(v.Value())), ""
+		case *object.Float:
+			f := v.Value()
+			if f == math.Trunc(f) && f >= math.MinInt64 && f < math.MaxInt64 {
+				return object.NewInt(int64(f)), ""
+			}
+			return nil, "must be a whole number"
+		case *object.String:
+			if i, err := strconv.ParseInt(strings.TrimSpace(v.Value()), 10, 64); err == nil {
+				return object.NewInt(i), ""
+			}
+			return nil, fmt.Sprintf("must be an int (%q given)", v.Value())
+		}
+		return nil, typeMessage("an int", value)
+	case "float":
+		switch v := value.(type) {
+		case *object.Float:
+			return v, ""
+		case *object.Int:
+			return object.NewFloat(float64(v.Value())), ""
+		case *object.String:
+			f, err := strconv.ParseFloat(strings.TrimSpace(v.Value()), 64)
+			if err == nil && !math.IsNaN(f) && !math.IsInf(f, 0) {
+				return object.NewFloat(f), ""
+			}
+			return nil, fmt.Sprintf("must be a number (%q given)", v.Value())
+		}
+		return nil, typeMessage("a number", value)
+	case "bool":
+		switch v := value.(type) {
+		case *object.Bool:
+			return v, ""
+		case *object.String:
+			switch strings.ToLower(strings.TrimSpace(v.Value())) {
+			case "true", "1":
+				return object.True, ""
+			case "false", "0":
+				return object.False, ""
+			}
+			return nil, fmt.Sprintf("must be a bool (%q given)", v.Value())
+		}
+		return nil, typeMessage("a bool", value)
+	case "list":
+		if _, ok := value.(*object.List); !ok {
+			return nil, typeMessage("a list", value)
+		}
+	case "map":
+		if _, ok := value.(*object.Map); !ok {
+			return nil, typeMessage("a map", value)
+		}
+	}
+	return value, ""
+}
+
+func typeMessage(expected string, value object.Object) string {
+	return fmt.Sprintf("must be %s (%s given)", expected, value.Type())
+}
+
+func (s *Schema) checkConstraints(value object.Object) string {
+	if s.min != nil || s.max != nil {
+		var n float64
+		low, high := "must be at least %v", "must be at most %v"
+		switch v := value.(type) {
+		case *object.Int:
+			n = float64(v.Value())
+		case *object.Float:
+			n = v.Value()
+		case *object.String:
+			n = float64(len([]rune(v.Value())))
+			low, high = "must be at least %v characters long", "must be at most %v characters long"
+		case *object.List:
+			n = float64(len(v.Value()))
+			low, high = "must have at least %v items", "must have at most %v items"
+		}
+		if s.min != nil && n < *s.min {
+			return fmt.Sprintf(low, *s.min)
+		}
+		if s.max != nil && n > *s.max {
+			return fmt.Sprintf(high, *s.max)
+		}
+	}
+	if s.pattern != nil && !s.pattern.MatchString(value.(*object.String).Value()) {
+		return fmt.Sprintf("must match %s", s.pattern)
+	}
+	if s.oneOf != nil {
+		for _, allowed := range s.oneOf {
+			if value.Equals(allowed) {
+				return ""
+			}
+		}
+		names := make([]string, len(s.oneOf))
+		for i, allowed := range s.oneOf {
+			names[i] = allowed.Inspect()
+		}
+		return "must be one of " + strings.Join(names, ", ")
+	}
+	return ""
+}
+
+func (s *Schema) validateList(ctx context.Context, list *object.List, path string, errs map[string]string) (object.Object, error) {
+	items := list.Value()
+	if s.item == nil {
+		return object.NewList(append([]object.Object{}, items...)), nil
+	}
+	result := make([]object.Object, len(items))
+	valid := true
+	for i, item := range items {
+		var itemPath string
+		if path == "." {
+			itemPath = fmt.Sprintf(".[%d]", i)
+		} else {
+			itemPath = fmt.Sprintf("%s[%d]", path, i)
+		}
+		value, err := s.item.validate(ctx, item, itemPath, errs)
+		if err != nil {
+			return nil, err
+		}
+		if value == nil {
+			valid = false
+		}
+		result[i] = value
+	}
+	if !valid {
+		return nil, nil
+	}
+	return object.NewList(result), nil
+}
+
+func (s *Schema) validateMap(ctx context.Context, m *object.Map, path string, errs map[string]string) (object.Object, error) {
+	if s.fields == nil {
+		return m.Copy(), nil
+	}
+	result := make(map[string]object.Object, len(s.fields))
+	valid := true
+	names := make([]string, 0, len(s.fields))
+	for name := range s.fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	items := m.Value()
+	for _, name := range names {
+		field := s.fields[name]
+		value, present := items[name]
+		if !present && field.def == nil && field.optional {
+			continue
+		}
+		value, err := field.validate(ctx, value, fieldPath(path, name), errs)
+		if err != nil {
+			return nil, err
+		}
+		if value == nil {
+			valid = false
+			continue
+		}
+		result[name] = value
+	}
+	if s.strict {
+		for _, key := range m.Keys().Value() {
+			name, ok := key.(*object.String)
+			if !ok {
+				// Keys that are not strings never name a field
+				keyPath := path + "[" + key.Inspect() + "]"
+				if path == "." {
+					keyPath = ".[" + key.Inspect() + "]"
+				}
+				errs[keyPath] = "is not allowed"
+				valid = false
+				continue
+			}
+			if _, ok := s.fields[name.Value()]; !ok {
+				errs[fieldPath(path, name.Value())] = "is not allowed"
+				valid = false
+			}
+		}
+	}
+	if !valid {
+		return nil, nil
+	}
+	return object.NewMap(result), nil
+}
+
+func fieldPath(path, name string) string {
+	if path == "." {
+		path = ""
+	}
+	if !isIdentifier(name) {
+		return fmt.Sprintf(".%s[%q]", strings.TrimPrefix(path, "."), name)
+	}
+	return path + "." + name
+}
+
+func isIdentifier(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		if r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (i > 0 && r >= '0' && r <= '9') {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+func (s *Schema) Type() object.Type {
+	return SCHEMA
+}
+
+func (s *Schema) Inspect() string {
+	return fmt.Sprintf("valid_schema(%s)", s.kind)
+}
+
+func (s *Schema) String() string {
+	return s.Inspect()
+}
+
+func (s *Schema) Interface() any {
+	return s
+}
+
+func (s *Schema) Equals(other object.Object) bool {
+	return s == other
+}
+
+func (s *Schema) Attrs() []object.AttrSpec {
+	return schemaMethods.Specs()
+}
+
+func (s *Schema) GetAttr(name string) (object.Object, bool) {
+	return schemaMethods.GetAttr(s, name)
+}
+
+func (s *Schema) SetAttr(name string, value object.Object) error {
+	return object.TypeErrorf("valid_schema has no attribute %q", name)
+}
+
+func (s *Schema) IsTruthy() bool {
+	return true
+}
+
+func (s *Schema) RunOperation(opType op.BinaryOpType, right object.Object) (object.Object, error) {
+	return nil, object.TypeErrorf("unsupported operation for valid_schema: %v", opType)
+}
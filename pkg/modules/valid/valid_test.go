@@ -0,0 +1,191 @@
+package valid
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+func call(t *testing.T, obj object.Object, name string, args ...object.Object) object.Object {
+	t.Helper()
+	result, err := callErr(t, obj, name, args...)
+	assert.Nil(t, err)
+	return result
+}
+
+func callErr(t *testing.T, obj object.Object, name string, args ...object.Object) (object.Object, error) {
+	t.Helper()
+	fn, ok := obj.GetAttr(name)
+	assert.True(t, ok, name)
+	return fn.(object.Callable).Call(context.Background(), args...)
+}
+
+func userSchema(t *testing.T) object.Object {
+	mod := Module()
+	name := call(t, call(t, call(t, mod, "string"), "trim"), "min", object.NewInt(1))
+	age := call(t, call(t, mod, "int"), "range", object.NewInt(0), object.NewInt(150))
+	email := call(t, call(t, call(t, mod, "string"), "pattern", object.NewString("^[^@]+@[^@]+$")), "optional")
+	tags := call(t, call(t, mod, "list", call(t, mod, "string")), "default", object.NewList(nil))
+	return call(t, mod, "map", object.NewMap(map[string]object.Object{
+		"name":  name,
+		"age":   age,
+		"email": email,
+		"tags":  tags,
+	}))
+}
+
+func TestParse(t *testing.T) {
+	schema := userSchema(t)
+	input := object.NewMap(map[string]object.Object{
+		"name":  object.NewString("  Ada "),
+		"age":   object.NewString("36"),
+		"extra": object.True,
+	})
+	result := call(t, schema, "parse", input)
+	assert.Equal(t, result.Inspect(), `{"age": 36, "name": "Ada", "tags": []}`)
+}
+
+func TestValidateErrors(t *testing.T) {
+	schema := userSchema(t)
+	input := object.NewMap(map[string]object.Object{
+		"name":  object.NewString("   "),
+		"age":   object.NewFloat(200),
+		"email": object.NewString("nope"),
+		"tags":  object.NewList([]object.Object{object.NewString("a"), object.NewInt(1)}),
+	})
+	result := call(t, schema, "validate", input).(*object.Map)
+	assert.Equal(t, result.Get("ok"), object.False)
+	assert.Equal(t, result.Get("value"), object.Nil)
+	errs := result.Get("errors").(*object.Map)
+	assert.Equal(t, errs.Get(".name"), object.NewString("must be at least 1 characters long"))
+	assert.Equal(t, errs.Get(".age"), object.NewString("must be at most 150"))
+	assert.Equal(t, errs.Get(".email"), object.NewString("must match ^[^@]+@[^@]+$"))
+	assert.Equal(t, errs.Get(".tags[1]"), object.NewString("must be a string (int given)"))
+	assert.Equal(t, errs.Size(), 4)
+
+	result = call(t, schema, "validate", object.NewMap(nil)).(*object.Map)
+	assert.Equal(t, result.Get("errors").Inspect(), `{".age": "is required", ".name": "is required"}`)
+}
+
+func TestParseError(t *testing.T) {
+	schema := userSchema(t)
+	_, err := callErr(t, schema, "parse", object.NewMap(map[string]object.Object{"age": object.NewString("x")}))
+	assert.Error(t, err)
+	var se *object.ScriptError
+	assert.True(t, errors.As(err, &se))
+	assert.Equal(t, se.Code, "invalid")
+	assert.Equal(t, se.Message, `invalid value: .age must be an int ("x" given) (and 1 more)`)
+	assert.Equal(t, se.Data, map[string]any{"errors": map[string]any{
+		".age":  `must be an int ("x" given)`,
+		".name": "is required",
+	}})
+}
+
+func TestCoercion(t *testing.T) {
+	mod := Module()
+	tests := []struct {
+		kind     string
+		input    object.Object
+		expected object.Object
+		message  string
+	}{
+		{"int", object.NewInt(3), object.NewInt(3), ""},
+		{"int", object.NewFloat(3), object.NewInt(3), ""},
+		{"int", object.NewString(" -12 "), object.NewInt(-12), ""},
+		{"int", object.NewFloat(3.5), nil, "must be a whole number"},
+		{"int", object.True, nil, "must be an int (bool given)"},
+		{"float", object.NewInt(2), object.NewFloat(2), ""},
+		{"float", object.NewString("1.5"), object.NewFloat(1.5), ""},
+		{"float", object.NewString("NaN"), nil, `must be a number ("NaN" given)`},
+		{"bool", object.NewString("TRUE"), object.True, ""},
+		{"bool", object.NewString("0"), object.False, ""},
+		{"bool", object.NewString("yes"), nil, `must be a bool ("yes" given)`},
+		{"string", object.NewInt(1), nil, "must be a string (int given)"},
+		{"any", object.NewInt(1), object.NewInt(1), ""},
+		{"any", object.Nil, nil, "is required"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.kind+" "+tt.input.Inspect(), func(t *testing.T) {
+			schema := call(t, mod, tt.kind).(*Schema)
+			value, errs, err := schema.Validate(context.Background(), tt.input)
+			assert.Nil(t, err)
+			if tt.message != "" {
+				assert.Equal(t, errs, map[string]string{".": tt.message})
+				return
+			}
+			assert.Len(t, errs, 0)
+			assert.Equal(t, value, tt.expected)
+		})
+	}
+}
+
+func TestConstraints(t *testing.T) {
+	mod := Module()
+	color := call(t, call(t, mod, "string"), "one_of",
+		object.NewList([]object.Object{object.NewString("red"), object.NewString("blue")}))
+	_, err := callErr(t, color, "parse", object.NewString("green"))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `must be one of "red", "blue"`)
+
+	even := call(t, call(t, mod, "int"), "check",
+		object.NewBuiltin("even", func(ctx context.Context, args ...object.Object) (object.Object, error) {
+			return object.NewBool(args[0].(*object.Int).Value()%2 == 0), nil
+		}), object.NewString("must be even"))
+	assert.Equal(t, call(t, even, "parse", object.NewString("4")), object.NewInt(4))
+	_, err = callErr(t, even, "parse", object.NewInt(3))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "must be even")
+
+	items := call(t, call(t, mod, "list"), "max", object.NewInt(1))
+	_, err = callErr(t, items, "parse", object.NewList([]object.Object{object.Nil, object.Nil}))
+	assert.Contains(t, err.Error(), "must have at most 1 items")
+
+	strict := call(t, call(t, mod, "map", object.NewMap(map[string]object.Object{
+		"id": call(t, mod, "int"),
+	})), "strict")
+	result := call(t, strict, "validate", object.NewMap(map[string]object.Object{
+		"id":        object.NewInt(1),
+		"user name": object.NewString("x"),
+	}))
+	assert.Equal(t, result.(*object.Map).Get("errors").Inspect(), `{".[\"user name\"]": "is not allowed"}`)
+
+	keyed := object.NewMap(map[string]object.Object{"id": object.NewInt(1)})
+	keyed.SetItem(object.NewInt(2), object.NewString("x"))
+	result = call(t, strict, "validate", keyed)
+	assert.Equal(t, result.(*object.Map).Get("errors").Inspect(), `{".[2]": "is not allowed"}`)
+
+	fields := object.NewMap(nil)
+	fields.SetItem(object.NewInt(1), call(t, mod, "int"))
+	_, err = callErr(t, mod, "map", fields)
+	assert.Contains(t, err.Error(), "map keys must be strings")
+}
+
+func TestDefaultIsCopied(t *testing.T) {
+	mod := Module()
+	schema := call(t, call(t, mod, "list"), "default", object.NewList(nil))
+	first := call(t, schema, "parse", object.Nil).(*object.List)
+	first.Append(object.NewInt(1))
+	second := call(t, schema, "parse", object.Nil).(*object.List)
+	assert.Equal(t, second.Inspect(), "[]")
+}
+
+func TestBuilderErrors(t *testing.T) {
+	mod := Module()
+	_, err := callErr(t, call(t, mod, "bool"), "min", object.NewInt(1))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "valid_schema.min: not supported for bool schemas")
+	_, err = callErr(t, call(t, mod, "int"), "range", object.NewInt(5), object.NewInt(1))
+	assert.Error(t, err)
+	_, err = callErr(t, call(t, mod, "int"), "pattern", object.NewString("x"))
+	assert.Error(t, err)
+	_, err = callErr(t, call(t, mod, "string"), "pattern", object.NewString("("))
+	assert.Error(t, err)
+	_, err = callErr(t, mod, "map", object.NewMap(map[string]object.Object{"id": object.NewInt(1)}))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `field "id" expected a valid_schema (int given)`)
+	_, err = callErr(t, mod, "list", object.NewString("int"))
+	assert.Error(t, err)
+}
@@ -48,6 +48,18 @@ type TypeSpec struct {
 	Attrs []AttrSpec
 }
 
+// ModuleSpec describes a Risor module.
+type ModuleSpec struct {
+	// Name is the module name (e.g., "math", "regexp").
+	Name string
+
+	// Doc is a description of the module.
+	Doc string
+
+	// Funcs lists the functions the module provides.
+	Funcs []FuncSpec
+}
+
 // Introspectable is implemented by objects that can describe their attributes.
 // This enables tooling like :methods, risor doc, and autocomplete.
 type Introspectable interface {
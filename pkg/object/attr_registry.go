@@ -11,7 +11,8 @@ import (
 type AttrDef[T any] struct {
 	Spec       AttrSpec
 	IsProperty bool
-	MinArgs    int // Minimum required arguments (for optional arg support)
+	MinArgs    int  // Minimum required arguments (for optional arg support)
+	Variadic   bool // Whether the last argument accepts any number of values
 	// For methods:
 	MethodImpl func(self T, ctx context.Context, args ...Object) (Object, error)
 	// For properties:
@@ -32,6 +33,7 @@ type AttrBuilder[T any] struct {
 	doc         string
 	args        []string
 	optionalIdx int // Index where optional args start (0 means all required)
+	variadic    bool
 	returns     string
 }
 
@@ -75,10 +77,15 @@ func (r *AttrRegistry[T]) GetAttr(self T, name string) (Object, bool) {
 	minArgs := attr.MinArgs
 	maxArgs := len(attr.Spec.Args)
 	fullName := r.typeName + "." + name
+	receiver, _ := any(self).(Object)
 	return &Builtin{
-		name: fullName,
+		name:     fullName,
+		receiver: receiver,
 		fn: func(ctx context.Context, args ...Object) (Object, error) {
-			if len(args) < minArgs || len(args) > maxArgs {
+			if attr.Variadic && len(args) < minArgs {
+				return nil, argsMinError(fullName, minArgs, len(args))
+			}
+			if !attr.Variadic && (len(args) < minArgs || len(args) > maxArgs) {
 				return nil, argsRangeError(fullName, minArgs, maxArgs, len(args))
 			}
 			return attr.MethodImpl(self, ctx, args...)
@@ -125,6 +132,18 @@ func (b *AttrBuilder[T]) OptionalArg(name string) *AttrBuilder[T] {
 	return b
 }
 
+// Variadic adds a final argument that accepts any number of values, which
+// the method receives as the remaining arguments (for methods). No argument
+// may follow it.
+func (b *AttrBuilder[T]) Variadic(name string) *AttrBuilder[T] {
+	if b.optionalIdx == 0 {
+		b.optionalIdx = len(b.args) + 1
+	}
+	b.args = append(b.args, name+"...")
+	b.variadic = true
+	return b
+}
+
 // Returns sets the return type (for documentation/tooling).
 func (b *AttrBuilder[T]) Returns(typ string) *AttrBuilder[T] {
 	b.returns = typ
@@ -150,7 +169,7 @@ func (b *AttrBuilder[T]) Impl(fn func(T, context.Context, ...Object) (Object, er
 	if b.optionalIdx > 0 {
 		minArgs = b.optionalIdx - 1 // -1 because optionalIdx is 1-indexed
 	}
-	r.attrs[b.name] = AttrDef[T]{Spec: spec, MinArgs: minArgs, MethodImpl: fn}
+	r.attrs[b.name] = AttrDef[T]{Spec: spec, MinArgs: minArgs, Variadic: b.variadic, MethodImpl: fn}
 	r.specs = append(r.specs, spec)
 }
 
@@ -183,6 +202,15 @@ func argsError(methodName string, expected, got int) error {
 	return fmt.Errorf("%s: expected %d arguments, got %d", methodName, expected, got)
 }
 
+// argsMinError returns a grammatically correct argument count error for
+// variadic methods.
+func argsMinError(methodName string, min, got int) error {
+	if min == 1 {
+		return fmt.Errorf("%s: expected at least 1 argument, got %d", methodName, got)
+	}
+	return fmt.Errorf("%s: expected at least %d arguments, got %d", methodName, min, got)
+}
+
 // argsRangeError returns a grammatically correct argument count error for methods with optional args.
 func argsRangeError(methodName string, min, max, got int) error {
 	if min == max {
@@ -147,6 +147,35 @@ func TestAttrRegistrySingleArg(t *testing.T) {
 	assert.Contains(t, err.Error(), "expected 1 argument")
 }
 
+// TestAttrRegistryVariadic tests methods accepting any number of trailing args.
+func TestAttrRegistryVariadic(t *testing.T) {
+	type testObj struct{}
+	registry := NewAttrRegistry[*testObj]("test")
+
+	registry.Define("count").
+		Arg("first").
+		Variadic("rest").
+		Impl(func(obj *testObj, ctx context.Context, args ...Object) (Object, error) {
+			return NewInt(int64(len(args))), nil
+		})
+
+	method, _ := registry.GetAttr(&testObj{}, "count")
+	builtin := method.(*Builtin)
+	ctx := context.Background()
+
+	result, err := builtin.Call(ctx, NewInt(1))
+	assert.Nil(t, err)
+	assert.Equal(t, result, Object(NewInt(1)))
+	result, err = builtin.Call(ctx, NewInt(1), NewInt(2), NewInt(3), NewInt(4))
+	assert.Nil(t, err)
+	assert.Equal(t, result, Object(NewInt(4)))
+
+	_, err = builtin.Call(ctx)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "expected at least 1 argument, got 0")
+	assert.Equal(t, registry.Specs()[0].Args, []string{"first", "rest..."})
+}
+
 // TestArgHelper tests the Arg helper function.
 func TestArgHelper(t *testing.T) {
 	args := []Object{NewInt(42), NewString("hello")}
@@ -398,7 +427,7 @@ func TestBuiltinAttrs(t *testing.T) {
 func TestRangeAttrs(t *testing.T) {
 	r := NewRange(0, 10, 2)
 	attrs := r.Attrs()
-	assert.Equal(t, len(attrs), 6)
+	assert.Equal(t, len(attrs), 10)
 
 	// Verify it's a new slice (not the internal one)
 	attrs[0].Name = "modified"
@@ -410,7 +439,7 @@ func TestRangeAttrs(t *testing.T) {
 func TestErrorAttrs(t *testing.T) {
 	e := Errorf("test error")
 	attrs := e.Attrs()
-	assert.Equal(t, len(attrs), 8)
+	assert.Equal(t, len(attrs), 11)
 
 	names := make(map[string]bool)
 	for _, attr := range attrs {
@@ -420,6 +449,9 @@ func TestErrorAttrs(t *testing.T) {
 	assert.True(t, names["message"])
 	assert.True(t, names["line"])
 	assert.True(t, names["kind"])
+	assert.True(t, names["code"])
+	assert.True(t, names["data"])
+	assert.True(t, names["cause"])
 }
 
 // testColor implements color.Color for testing.
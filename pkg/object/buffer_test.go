@@ -0,0 +1,72 @@
+package object
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+func TestBufferWrite(t *testing.T) {
+	ctx := context.Background()
+	b := NewBuffer([]byte("head:"))
+	assert.Equal(t, b.Type(), BUFFER)
+
+	write, ok := b.GetAttr("write")
+	assert.True(t, ok)
+	n, err := write.(*Builtin).Call(ctx, NewString("abc"))
+	assert.Nil(t, err)
+	assert.Equal(t, n, NewInt(3))
+	_, err = write.(*Builtin).Call(ctx, NewBytes([]byte{'!'}))
+	assert.Nil(t, err)
+
+	writeln, _ := b.GetAttr("writeln")
+	n, err = writeln.(*Builtin).Call(ctx, NewString("x"))
+	assert.Nil(t, err)
+	assert.Equal(t, n, NewInt(2))
+	_, err = writeln.(*Builtin).Call(ctx)
+	assert.Nil(t, err)
+
+	assert.Equal(t, b.String(), "head:abc!x\n\n")
+	assert.Equal(t, b.Interface(), "head:abc!x\n\n")
+	assert.Equal(t, b.Len(), 12)
+	assert.Equal(t, b.Inspect(), "buffer(len=12)")
+
+	_, err = write.(*Builtin).Call(ctx, NewInt(1))
+	assert.NotNil(t, err)
+	assert.Equal(t, b.Len(), 12)
+}
+
+func TestBufferMethods(t *testing.T) {
+	ctx := context.Background()
+	b := NewBuffer(nil)
+	assert.False(t, b.IsTruthy())
+
+	// Go code can write to a buffer through io.Writer
+	fmt.Fprintf(b, "%d items", 3)
+	assert.True(t, b.IsTruthy())
+
+	call := func(name string) Object {
+		t.Helper()
+		method, ok := b.GetAttr(name)
+		assert.True(t, ok)
+		result, err := method.(*Builtin).Call(ctx)
+		assert.Nil(t, err)
+		return result
+	}
+	assert.Equal(t, call("len"), NewInt(7))
+	assert.Equal(t, call("string"), NewString("3 items"))
+	data := call("bytes").(*Bytes)
+	assert.Equal(t, string(data.Value()), "3 items")
+
+	// The bytes are a copy that later writes do not change
+	b.Write([]byte("!"))
+	assert.Equal(t, string(data.Value()), "3 items")
+
+	assert.Equal(t, call("reset"), Nil)
+	assert.Equal(t, b.Len(), 0)
+
+	assert.True(t, b.Equals(b))
+	assert.False(t, b.Equals(NewBuffer(nil)))
+}
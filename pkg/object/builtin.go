@@ -50,6 +50,12 @@ type Builtin struct {
 	// priority over module.Name() when set, allowing standalone builtins to
 	// report a module name without having an actual module reference.
 	moduleName string
+
+	// The object this function is a method of, if it is a bound method.
+	receiver Object
+
+	// The capabilities the function needs, sorted. See WithCapabilities.
+	capabilities []Capability
 }
 
 func (b *Builtin) Attrs() []AttrSpec {
@@ -99,6 +105,12 @@ func (b *Builtin) Name() string {
 	return b.name
 }
 
+// Receiver returns the object a bound method was retrieved from, or nil if
+// the function is not a method.
+func (b *Builtin) Receiver() Object {
+	return b.receiver
+}
+
 // Returns a string that uniquely identifies this builtin function.
 func (b *Builtin) Key() string {
 	if b.module == nil && b.moduleName == "" {
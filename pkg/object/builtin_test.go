@@ -50,6 +50,21 @@ func TestBuiltinWithNilModule(t *testing.T) {
 	assert.Equal(t, b.Key(), "test")
 }
 
+func TestBuiltinCapabilities(t *testing.T) {
+	b := NewNoopBuiltin("fetch").WithCapabilities(CapNet, CapFSWrite, CapNet)
+	assert.Equal(t, b.Capabilities(), []Capability{CapFSWrite, CapNet})
+	assert.Len(t, NewNoopBuiltin("len").Capabilities(), 0)
+
+	mod := NewBuiltinsModule("io", map[string]Object{
+		"fetch": b,
+		"now":   NewNoopBuiltin("now"),
+		"files": NewBuiltinsModule("files", map[string]Object{
+			"read": NewNoopBuiltin("read").WithCapabilities(CapFSRead),
+		}),
+	})
+	assert.Equal(t, mod.Capabilities(), []Capability{CapFSRead, CapFSWrite, CapNet})
+}
+
 func TestNewNoopBuiltin(t *testing.T) {
 	b := NewNoopBuiltin("noop")
 	assert.Equal(t, b.Name(), "noop")
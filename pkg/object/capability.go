@@ -0,0 +1,59 @@
+package object
+
+import (
+	"slices"
+)
+
+// Capability names a kind of access to the world outside the script that a
+// builtin function needs. Capabilities describe what a function can do;
+// they are not enforced. Security reviewers and hosts read them to see what
+// a script could do before running it.
+type Capability string
+
+const (
+	// CapNet marks functions that use the network.
+	CapNet Capability = "net"
+
+	// CapFSRead marks functions that read files.
+	CapFSRead Capability = "fs-read"
+
+	// CapFSWrite marks functions that create, modify, or delete files.
+	CapFSWrite Capability = "fs-write"
+
+	// CapExec marks functions that run other programs.
+	CapExec Capability = "exec"
+
+	// CapEnv marks functions that read or change environment variables.
+	CapEnv Capability = "env"
+)
+
+// WithCapabilities tags the builtin with the capabilities it needs.
+func (b *Builtin) WithCapabilities(caps ...Capability) *Builtin {
+	b.capabilities = sortCapabilities(append(b.capabilities, caps...))
+	return b
+}
+
+// Capabilities returns the capabilities the builtin is tagged with, sorted.
+func (b *Builtin) Capabilities() []Capability {
+	return slices.Clone(b.capabilities)
+}
+
+// Capabilities returns the capabilities of all builtins in the module,
+// sorted, including those of nested modules.
+func (m *Module) Capabilities() []Capability {
+	var caps []Capability
+	for _, value := range m.builtins {
+		switch value := value.(type) {
+		case *Builtin:
+			caps = append(caps, value.capabilities...)
+		case *Module:
+			caps = append(caps, value.Capabilities()...)
+		}
+	}
+	return sortCapabilities(caps)
+}
+
+func sortCapabilities(caps []Capability) []Capability {
+	slices.Sort(caps)
+	return slices.Compact(caps)
+}
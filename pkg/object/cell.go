@@ -45,6 +45,12 @@ func (c *Cell) Value() Object {
 	return *c.value
 }
 
+// Slot returns the variable the cell refers to. Cells that capture the same
+// variable return the same slot.
+func (c *Cell) Slot() *Object {
+	return c.value
+}
+
 func (c *Cell) Set(value Object) {
 	if c.value == nil {
 		return
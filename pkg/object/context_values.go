@@ -2,6 +2,9 @@ package object
 
 import (
 	"context"
+	"maps"
+	"slices"
+	"sync"
 )
 
 type contextKey string
@@ -36,3 +39,137 @@ func GetCallFunc(ctx context.Context) (CallFunc, bool) {
 	}
 	return nil, false
 }
+
+// ExitHookFunc registers a callable to run after the main program finishes.
+// The VM registers its implementation via WithExitHookFunc, and the atexit
+// module retrieves it to record hooks for the current run.
+type ExitHookFunc func(fn Callable) error
+
+const exitHookFuncKey = contextKey("risor:exit_hook")
+
+// WithExitHookFunc stores an ExitHookFunc in the context. Called by the VM
+// at the start of each run.
+func WithExitHookFunc(ctx context.Context, fn ExitHookFunc) context.Context {
+	return context.WithValue(ctx, exitHookFuncKey, fn)
+}
+
+// GetExitHookFunc retrieves the ExitHookFunc from the context.
+func GetExitHookFunc(ctx context.Context) (ExitHookFunc, bool) {
+	if fn, ok := ctx.Value(exitHookFuncKey).(ExitHookFunc); ok {
+		if fn != nil {
+			return fn, ok
+		}
+	}
+	return nil, false
+}
+
+// ForkFunc returns a CallFunc backed by a new VM, so closures from the
+// running program can be called on another goroutine. The VM registers its
+// implementation via WithForkFunc, and builtins that run callbacks in
+// parallel call it once per goroutine, before starting the goroutine.
+type ForkFunc func() (CallFunc, error)
+
+const forkFuncKey = contextKey("risor:fork")
+
+// WithForkFunc stores a ForkFunc in the context. Called by the VM at the
+// start of each run.
+func WithForkFunc(ctx context.Context, fn ForkFunc) context.Context {
+	return context.WithValue(ctx, forkFuncKey, fn)
+}
+
+// GetForkFunc retrieves the ForkFunc from the context.
+func GetForkFunc(ctx context.Context) (ForkFunc, bool) {
+	if fn, ok := ctx.Value(forkFuncKey).(ForkFunc); ok {
+		if fn != nil {
+			return fn, ok
+		}
+	}
+	return nil, false
+}
+
+// TaskFunc records a task started by the running program. The VM registers
+// its implementation via WithTaskFunc, and NewTask calls it so that the VM
+// can cancel and wait for the tasks a program leaves running when its run
+// ends.
+type TaskFunc func(t *Task)
+
+const taskFuncKey = contextKey("risor:task")
+
+// WithTaskFunc stores a TaskFunc in the context. Called by the VM at the
+// start of each run.
+func WithTaskFunc(ctx context.Context, fn TaskFunc) context.Context {
+	return context.WithValue(ctx, taskFuncKey, fn)
+}
+
+// GetTaskFunc retrieves the TaskFunc from the context.
+func GetTaskFunc(ctx context.Context) (TaskFunc, bool) {
+	if fn, ok := ctx.Value(taskFuncKey).(TaskFunc); ok {
+		if fn != nil {
+			return fn, ok
+		}
+	}
+	return nil, false
+}
+
+// ContextValues holds request-scoped values shared between a host and a
+// script, such as trace or user IDs. The host seeds them before a run, the
+// script reads and sets them with the ctx module, and Go builtins read them
+// from their context with GetContextValues. It is safe for concurrent use.
+type ContextValues struct {
+	mu     sync.RWMutex
+	values map[string]Object
+}
+
+// NewContextValues creates a store holding a copy of values.
+func NewContextValues(values map[string]Object) *ContextValues {
+	return &ContextValues{values: maps.Clone(values)}
+}
+
+// Get returns the value stored under key.
+func (c *ContextValues) Get(key string) (Object, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	value, ok := c.values[key]
+	return value, ok
+}
+
+// Set stores a value under key.
+func (c *ContextValues) Set(key string, value Object) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.values == nil {
+		c.values = map[string]Object{}
+	}
+	c.values[key] = value
+}
+
+// Keys returns the stored keys in sorted order.
+func (c *ContextValues) Keys() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return slices.Sorted(maps.Keys(c.values))
+}
+
+// All returns a copy of the stored values.
+func (c *ContextValues) All() map[string]Object {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return maps.Clone(c.values)
+}
+
+const contextValuesKey = contextKey("risor:values")
+
+// WithContextValues stores a ContextValues in the context.
+func WithContextValues(ctx context.Context, values *ContextValues) context.Context {
+	return context.WithValue(ctx, contextValuesKey, values)
+}
+
+// GetContextValues retrieves the ContextValues from the context.
+func GetContextValues(ctx context.Context) (*ContextValues, bool) {
+	if values, ok := ctx.Value(contextValuesKey).(*ContextValues); ok {
+		if values != nil {
+			return values, ok
+		}
+	}
+	return nil, false
+}
@@ -27,3 +27,32 @@ func TestContextCallFunc(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, result, NewInt(42))
 }
+
+func TestContextValues(t *testing.T) {
+	_, ok := GetContextValues(context.Background())
+	assert.False(t, ok)
+
+	seed := map[string]Object{"trace_id": NewString("abc")}
+	values := NewContextValues(seed)
+	ctx := WithContextValues(context.Background(), values)
+	got, ok := GetContextValues(ctx)
+	assert.True(t, ok)
+	assert.True(t, got == values)
+
+	value, ok := values.Get("trace_id")
+	assert.True(t, ok)
+	assert.Equal(t, value, NewString("abc"))
+	_, ok = values.Get("user_id")
+	assert.False(t, ok)
+
+	values.Set("user_id", NewInt(7))
+	assert.Equal(t, values.Keys(), []string{"trace_id", "user_id"})
+	assert.Equal(t, len(values.All()), 2)
+
+	// The seed map is copied
+	assert.Equal(t, len(seed), 1)
+
+	var empty ContextValues
+	empty.Set("k", True)
+	assert.Equal(t, empty.Keys(), []string{"k"})
+}
@@ -0,0 +1,270 @@
+package object
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/op"
+)
+
+var enumAttrs = NewAttrRegistry[*Enum]("enum")
+
+var enumMemberAttrs = NewAttrRegistry[*EnumMember]("enum_member")
+
+func init() {
+	enumAttrs.Define("name").
+		Doc("The name of the enum").
+		Returns("string").
+		Getter(func(e *Enum) Object {
+			return NewString(e.name)
+		})
+
+	enumAttrs.Define("values").
+		Doc("Return the members of the enum in declaration order").
+		Returns("list").
+		Impl(func(e *Enum, ctx context.Context, args ...Object) (Object, error) {
+			items := make([]Object, len(e.members))
+			for i, member := range e.members {
+				items[i] = member
+			}
+			return NewList(items), nil
+		})
+
+	enumAttrs.Define("names").
+		Doc("Return the member names of the enum in declaration order").
+		Returns("list").
+		Impl(func(e *Enum, ctx context.Context, args ...Object) (Object, error) {
+			items := make([]Object, len(e.members))
+			for i, member := range e.members {
+				items[i] = NewString(member.name)
+			}
+			return NewList(items), nil
+		})
+
+	enumAttrs.Define("from_ordinal").
+		Doc("Return the member with the given ordinal").
+		Arg("ordinal").
+		Returns("enum_member").
+		Impl(func(e *Enum, ctx context.Context, args ...Object) (Object, error) {
+			ordinal, err := AsInt(args[0])
+			if err != nil {
+				return nil, err
+			}
+			if ordinal < 0 || ordinal >= int64(len(e.members)) {
+				return nil, ValueErrorf("%s has no member with ordinal %d", e.name, ordinal)
+			}
+			return e.members[ordinal], nil
+		})
+
+	enumAttrs.Define("from_name").
+		Doc("Return the member with the given name").
+		Arg("name").
+		Returns("enum_member").
+		Impl(func(e *Enum, ctx context.Context, args ...Object) (Object, error) {
+			name, err := AsString(args[0])
+			if err != nil {
+				return nil, err
+			}
+			member, ok := e.Member(name)
+			if !ok {
+				return nil, ValueErrorf("%s has no member named %q", e.name, name)
+			}
+			return member, nil
+		})
+
+	enumAttrs.Define("has").
+		Doc("Return true if the enum has a member with the given name").
+		Arg("name").
+		Returns("bool").
+		Impl(func(e *Enum, ctx context.Context, args ...Object) (Object, error) {
+			name, err := AsString(args[0])
+			if err != nil {
+				return nil, err
+			}
+			_, ok := e.Member(name)
+			return NewBool(ok), nil
+		})
+
+	enumMemberAttrs.Define("name").
+		Doc("The name of the member").
+		Returns("string").
+		Getter(func(m *EnumMember) Object {
+			return NewString(m.name)
+		})
+
+	enumMemberAttrs.Define("ordinal").
+		Doc("The zero-based position of the member in its enum").
+		Returns("int").
+		Getter(func(m *EnumMember) Object {
+			return NewInt(int64(m.ordinal))
+		})
+}
+
+// Enum is an immutable, named set of members created by an enum declaration.
+// Members are accessed as attributes of the enum, e.g. Color.Red.
+type Enum struct {
+	name    string
+	members []*EnumMember
+	index   map[string]*EnumMember
+}
+
+// EnumMember is a single member of an Enum. Members are compared by
+// identity, so two members are equal only if they are the same member of
+// the same enum.
+type EnumMember struct {
+	enum    *Enum
+	name    string
+	ordinal int
+}
+
+// IsEnumAttr returns true if name is a built-in attribute of enum objects.
+// Enum members may not use these names.
+func IsEnumAttr(name string) bool {
+	_, ok := enumAttrs.attrs[name]
+	return ok
+}
+
+// NewEnum creates an enum with the given name and member names. Member
+// ordinals follow the order of the names. An error is returned if a member
+// name is repeated or conflicts with a built-in enum attribute.
+func NewEnum(name string, memberNames []string) (*Enum, error) {
+	e := &Enum{
+		name:    name,
+		members: make([]*EnumMember, 0, len(memberNames)),
+		index:   make(map[string]*EnumMember, len(memberNames)),
+	}
+	for i, memberName := range memberNames {
+		if _, exists := e.index[memberName]; exists {
+			return nil, ValueErrorf("enum %s has duplicate member %q", name, memberName)
+		}
+		if IsEnumAttr(memberName) {
+			return nil, ValueErrorf("enum %s member %q conflicts with a built-in enum attribute", name, memberName)
+		}
+		member := &EnumMember{enum: e, name: memberName, ordinal: i}
+		e.members = append(e.members, member)
+		e.index[memberName] = member
+	}
+	return e, nil
+}
+
+// Name returns the name of the enum.
+func (e *Enum) Name() string { return e.name }
+
+// Members returns the members of the enum in declaration order.
+func (e *Enum) Members() []*EnumMember {
+	members := make([]*EnumMember, len(e.members))
+	copy(members, e.members)
+	return members
+}
+
+// Member returns the member with the given name.
+func (e *Enum) Member(name string) (*EnumMember, bool) {
+	member, ok := e.index[name]
+	return member, ok
+}
+
+func (e *Enum) Attrs() []AttrSpec {
+	return enumAttrs.Specs()
+}
+
+func (e *Enum) GetAttr(name string) (Object, bool) {
+	if member, ok := e.index[name]; ok {
+		return member, true
+	}
+	return enumAttrs.GetAttr(e, name)
+}
+
+func (e *Enum) SetAttr(name string, value Object) error {
+	return TypeErrorf("cannot modify enum %s", e.name)
+}
+
+func (e *Enum) Type() Type { return ENUM }
+
+func (e *Enum) Inspect() string {
+	names := make([]string, len(e.members))
+	for i, member := range e.members {
+		names[i] = member.name
+	}
+	return fmt.Sprintf("enum %s { %s }", e.name, strings.Join(names, ", "))
+}
+
+func (e *Enum) String() string { return e.Inspect() }
+
+func (e *Enum) Interface() any {
+	names := make([]string, len(e.members))
+	for i, member := range e.members {
+		names[i] = member.name
+	}
+	return names
+}
+
+func (e *Enum) Equals(other Object) bool {
+	return e == other
+}
+
+func (e *Enum) IsTruthy() bool { return true }
+
+func (e *Enum) RunOperation(opType op.BinaryOpType, right Object) (Object, error) {
+	return nil, newTypeErrorf("unsupported operation for enum: %v", opType)
+}
+
+// Enumerate iterates over the members of the enum.
+func (e *Enum) Enumerate(ctx context.Context, fn func(key, value Object) bool) {
+	for i, member := range e.members {
+		if !fn(NewInt(int64(i)), member) {
+			return
+		}
+	}
+}
+
+// Enum returns the enum the member belongs to.
+func (m *EnumMember) Enum() *Enum { return m.enum }
+
+// Name returns the name of the member.
+func (m *EnumMember) Name() string { return m.name }
+
+// Ordinal returns the zero-based position of the member in its enum.
+func (m *EnumMember) Ordinal() int { return m.ordinal }
+
+func (m *EnumMember) Attrs() []AttrSpec {
+	return enumMemberAttrs.Specs()
+}
+
+func (m *EnumMember) GetAttr(name string) (Object, bool) {
+	return enumMemberAttrs.GetAttr(m, name)
+}
+
+func (m *EnumMember) SetAttr(name string, value Object) error {
+	return TypeErrorf("cannot modify enum member %s", m.Inspect())
+}
+
+func (m *EnumMember) Type() Type { return ENUM_MEMBER }
+
+func (m *EnumMember) Inspect() string {
+	return m.enum.name + "." + m.name
+}
+
+// String returns the member name, which is what string() converts to.
+func (m *EnumMember) String() string { return m.name }
+
+func (m *EnumMember) Interface() any { return m.name }
+
+func (m *EnumMember) Equals(other Object) bool {
+	return m == other
+}
+
+func (m *EnumMember) IsTruthy() bool { return true }
+
+// Compare orders members of the same enum by ordinal.
+func (m *EnumMember) Compare(other Object) (int, error) {
+	o, ok := other.(*EnumMember)
+	if !ok || o.enum != m.enum {
+		return 0, TypeErrorf("unable to compare %s and %s", m.Inspect(), other.Inspect())
+	}
+	return m.ordinal - o.ordinal, nil
+}
+
+func (m *EnumMember) RunOperation(opType op.BinaryOpType, right Object) (Object, error) {
+	return nil, newTypeErrorf("unsupported operation for enum member: %v", opType)
+}
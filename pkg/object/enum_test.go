@@ -0,0 +1,65 @@
+package object
+
+import (
+	"testing"
+
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+func TestEnum(t *testing.T) {
+	e, err := NewEnum("Color", []string{"Red", "Green", "Blue"})
+	assert.Nil(t, err)
+	assert.Equal(t, e.Type(), ENUM)
+	assert.Equal(t, e.Name(), "Color")
+	assert.Equal(t, e.Inspect(), "enum Color { Red, Green, Blue }")
+	assert.Equal(t, e.Interface(), []string{"Red", "Green", "Blue"})
+	assert.True(t, e.IsTruthy())
+	assert.Len(t, e.Members(), 3)
+
+	green, ok := e.Member("Green")
+	assert.True(t, ok)
+	assert.Equal(t, green.Type(), ENUM_MEMBER)
+	assert.Equal(t, green.Name(), "Green")
+	assert.Equal(t, green.Ordinal(), 1)
+	assert.Equal(t, green.Enum(), e)
+	assert.Equal(t, green.Inspect(), "Color.Green")
+	assert.Equal(t, green.String(), "Green")
+	assert.Equal(t, green.Interface(), "Green")
+
+	attr, ok := e.GetAttr("Green")
+	assert.True(t, ok)
+	assert.True(t, attr.Equals(green))
+
+	_, ok = e.GetAttr("Purple")
+	assert.False(t, ok)
+	assert.NotNil(t, e.SetAttr("Green", NewInt(1)))
+	assert.NotNil(t, green.SetAttr("ordinal", NewInt(1)))
+}
+
+func TestEnumMemberCompare(t *testing.T) {
+	e, err := NewEnum("Size", []string{"Small", "Large"})
+	assert.Nil(t, err)
+	small, _ := e.Member("Small")
+	large, _ := e.Member("Large")
+
+	cmp, err := small.Compare(large)
+	assert.Nil(t, err)
+	assert.True(t, cmp < 0)
+
+	other, err := NewEnum("Size", []string{"Small"})
+	assert.Nil(t, err)
+	otherSmall, _ := other.Member("Small")
+	assert.False(t, small.Equals(otherSmall))
+	_, err = small.Compare(otherSmall)
+	assert.NotNil(t, err)
+}
+
+func TestNewEnumErrors(t *testing.T) {
+	_, err := NewEnum("Color", []string{"Red", "Red"})
+	assert.NotNil(t, err)
+	assert.Equal(t, err.Error(), `value error: enum Color has duplicate member "Red"`)
+
+	_, err = NewEnum("Color", []string{"from_name"})
+	assert.NotNil(t, err)
+	assert.Equal(t, err.Error(), `value error: enum Color member "from_name" conflicts with a built-in enum attribute`)
+}
@@ -84,6 +84,37 @@ func init() {
 			return NewList(nil), nil
 		})
 
+	errorMethods.Define("code").
+		Doc("Get the error code, or null if the error has none").
+		Returns("string").
+		Impl(func(e *Error, ctx context.Context, args ...Object) (Object, error) {
+			if se, ok := e.ScriptError(); ok && se.Code != "" {
+				return NewString(se.Code), nil
+			}
+			return Nil, nil
+		})
+
+	errorMethods.Define("data").
+		Doc("Get the data attached to the error, or null if it has none").
+		Returns("map").
+		Impl(func(e *Error, ctx context.Context, args ...Object) (Object, error) {
+			se, ok := e.ScriptError()
+			if !ok {
+				return Nil, nil
+			}
+			return se.DataObject()
+		})
+
+	errorMethods.Define("cause").
+		Doc("Get the error this error wraps, or null if it wraps none").
+		Returns("error").
+		Impl(func(e *Error, ctx context.Context, args ...Object) (Object, error) {
+			if cause := e.Cause(); cause != nil {
+				return cause, nil
+			}
+			return Nil, nil
+		})
+
 	errorMethods.Define("kind").
 		Doc("Get the error kind (e.g., 'type', 'value', 'error')").
 		Returns("string").
@@ -226,6 +257,105 @@ func NewError(err error) *Error {
 	}
 }
 
+// ScriptError is an error carrying a code and a data payload. Scripts create
+// one with error(code, data), where data is a map, and read the parts back
+// with the code and data methods of the error. When such an error escapes a
+// script, the host receives it in the error chain and can retrieve it with
+// errors.As:
+//
+//	var se *object.ScriptError
+//	if errors.As(err, &se) {
+//		fmt.Println(se.Code, se.Data["resource"])
+//	}
+//
+// Go builtins may also return a ScriptError so scripts can branch on its
+// code.
+type ScriptError struct {
+	Code    string         // Machine-readable error code
+	Message string         // Human-readable message; defaults to the code
+	Data    map[string]any // Payload as Go values
+	Cause   error          // Underlying error, if any
+
+	data *Map // Payload as created by the script, if any
+}
+
+// NewScriptError creates a ScriptError from a code and a Risor data map. The
+// code also serves as the message. The data map may be nil.
+func NewScriptError(code string, data *Map) *ScriptError {
+	se := &ScriptError{Code: code, Message: code, data: data}
+	if data != nil {
+		se.Data = data.Interface().(map[string]any)
+	}
+	return se
+}
+
+func (e *ScriptError) Error() string {
+	if e.Message != "" {
+		return e.Message
+	}
+	return e.Code
+}
+
+func (e *ScriptError) Unwrap() error {
+	return e.Cause
+}
+
+// NewPanicError describes a panic recovered while calling the named Go
+// function. The error has the code "panic", and its data holds the panic
+// value and the Go stack. If the panic value is an error, it is the cause.
+func NewPanicError(name string, r any, stack []byte) *ScriptError {
+	se := &ScriptError{
+		Code:    "panic",
+		Message: fmt.Sprintf("panic in %s: %v", name, r),
+		Data: map[string]any{
+			"value": fmt.Sprint(r),
+			"stack": string(stack),
+		},
+	}
+	if err, ok := r.(error); ok {
+		se.Cause = err
+	}
+	return se
+}
+
+// DataObject returns the payload as a Risor map, converting Data if the
+// error was created in Go.
+func (e *ScriptError) DataObject() (Object, error) {
+	if e.data != nil {
+		return e.data, nil
+	}
+	if e.Data == nil {
+		return Nil, nil
+	}
+	return DefaultRegistry().FromGo(e.Data)
+}
+
+// Cause returns the error this error wraps, or nil if it wraps none. Layers
+// that only tag an error without changing its message, such as TypeError,
+// are skipped, so the cause is always a distinct error.
+func (e *Error) Cause() *Error {
+	err := e.err
+	for {
+		next := errors.Unwrap(err)
+		if next == nil {
+			return nil
+		}
+		if next.Error() != err.Error() {
+			return NewError(next)
+		}
+		err = next
+	}
+}
+
+// ScriptError returns the ScriptError in the error's chain, if any.
+func (e *Error) ScriptError() (*ScriptError, bool) {
+	var se *ScriptError
+	if errors.As(e.err, &se) {
+		return se, true
+	}
+	return nil, false
+}
+
 // NewErrorFromStructured creates a new Error from a StructuredError.
 func NewErrorFromStructured(se *StructuredError) *Error {
 	return &Error{err: se, structured: se}
@@ -96,3 +96,50 @@ func TestErrorMessage(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, result.(*String).Value(), "a")
 }
+
+func TestScriptError(t *testing.T) {
+	data := NewMap(map[string]Object{"resource": NewString("user/1")})
+	se := NewScriptError("not_found", data)
+	assert.Equal(t, se.Error(), "not_found")
+	assert.Equal(t, se.Data, map[string]any{"resource": "user/1"})
+
+	e := NewError(fmt.Errorf("lookup: %w", se))
+	code, ok := e.GetAttr("code")
+	assert.True(t, ok)
+	result, err := code.(*Builtin).Call(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, result, NewString("not_found"))
+
+	dataFn, _ := e.GetAttr("data")
+	result, err = dataFn.(*Builtin).Call(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, result, Object(data))
+
+	var target *ScriptError
+	assert.True(t, errors.As(e, &target))
+	assert.Equal(t, target, se)
+}
+
+func TestScriptErrorFromGo(t *testing.T) {
+	cause := errors.New("connection refused")
+	se := &ScriptError{Code: "unavailable", Data: map[string]any{"retry": true}, Cause: cause}
+	assert.Equal(t, se.Error(), "unavailable")
+	assert.True(t, errors.Is(se, cause))
+
+	dataFn, _ := NewError(se).GetAttr("data")
+	result, err := dataFn.(*Builtin).Call(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, result, NewMap(map[string]Object{"retry": True}))
+}
+
+func TestErrorWithoutCode(t *testing.T) {
+	e := NewError(errors.New("plain"))
+	code, _ := e.GetAttr("code")
+	result, err := code.(*Builtin).Call(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, result, Object(Nil))
+	data, _ := e.GetAttr("data")
+	result, err = data.(*Builtin).Call(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, result, Object(Nil))
+}
@@ -17,6 +17,7 @@ type (
 	TypeError       = errors.TypeError
 	ValueError      = errors.ValueError
 	IndexError      = errors.IndexError
+	ExitError       = errors.ExitError
 )
 
 // Re-export error kind constants
@@ -37,6 +38,7 @@ var (
 	NewTypeError        = errors.NewTypeError
 	NewValueError       = errors.NewValueError
 	NewIndexError       = errors.NewIndexError
+	NewExitError        = errors.NewExitError
 	NewStructuredError  = errors.NewStructuredError
 	NewStructuredErrorf = errors.NewStructuredErrorf
 )
@@ -0,0 +1,148 @@
+package object
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FormatOptions controls how Format renders an object.
+type FormatOptions struct {
+	// MaxDepth limits how many levels of nested lists, maps, and tuples are
+	// shown. Containers below the limit are written as "[...]", "{...}", or
+	// "(...)". Zero means no limit.
+	MaxDepth int
+
+	// MaxItems limits how many items of each container are shown. The
+	// remaining items are summarized as "... N more". Zero means no limit.
+	MaxItems int
+
+	// Pretty writes each container item on its own line, indented by two
+	// spaces per level.
+	Pretty bool
+
+	// Width keeps a container on one line when it fits within this many
+	// columns and writes it one item per line otherwise, as Pretty does.
+	// Zero means no limit.
+	Width int
+}
+
+// Format returns a string representation of obj like Inspect, with nesting
+// depth, item counts, and layout controlled by opts. A container that holds
+// itself is written as "[...]", "{...}", or "(...)" where it repeats.
+func Format(obj Object, opts FormatOptions) string {
+	f := &formatter{opts: opts, active: map[Object]bool{}}
+	f.write(obj, 0)
+	return f.out.String()
+}
+
+type formatter struct {
+	opts   FormatOptions
+	out    strings.Builder
+	active map[Object]bool
+
+	// lineStart is the offset in out where the current line begins
+	lineStart int
+}
+
+func (f *formatter) write(obj Object, depth int) {
+	var open, close string
+	var items []formatItem
+	switch obj := obj.(type) {
+	case *List:
+		open, close = "[", "]"
+		for _, item := range obj.items {
+			items = append(items, formatItem{value: item})
+		}
+	case *Tuple:
+		open, close = "(", ")"
+		for _, item := range obj.items {
+			items = append(items, formatItem{value: item})
+		}
+	case *Map:
+		open, close = "{", "}"
+		for _, entry := range obj.sortedEntries() {
+			var key string
+			if s, ok := entry.key.(*String); ok {
+				key = fmt.Sprintf("%q", s.value)
+			} else {
+				key = entry.key.Inspect()
+			}
+			items = append(items, formatItem{key: key, value: entry.value})
+		}
+	default:
+		f.out.WriteString(obj.Inspect())
+		return
+	}
+	if len(items) == 0 {
+		f.out.WriteString(open + close)
+		return
+	}
+	if f.active[obj] || (f.opts.MaxDepth > 0 && depth >= f.opts.MaxDepth) {
+		f.out.WriteString(open + "..." + close)
+		return
+	}
+	multiline := f.opts.Pretty || f.opts.Width > 0
+	if f.opts.Width > 0 {
+		// Use one line if the container fits in the remaining width
+		compact := &formatter{opts: f.opts, active: f.active}
+		compact.opts.Pretty, compact.opts.Width = false, 0
+		compact.write(obj, depth)
+		if f.out.Len()-f.lineStart+compact.out.Len() <= f.opts.Width {
+			f.out.WriteString(compact.out.String())
+			return
+		}
+	}
+	f.active[obj] = true
+	defer delete(f.active, obj)
+
+	shown := items
+	if f.opts.MaxItems > 0 && len(items) > f.opts.MaxItems {
+		shown = items[:f.opts.MaxItems]
+	}
+	f.out.WriteString(open)
+	for i, item := range shown {
+		if i > 0 {
+			f.separator(multiline)
+		}
+		f.newline(multiline, depth+1)
+		if item.key != "" {
+			f.out.WriteString(item.key)
+			f.out.WriteString(": ")
+		}
+		f.write(item.value, depth+1)
+	}
+	if len(shown) < len(items) {
+		f.separator(multiline)
+		f.newline(multiline, depth+1)
+		fmt.Fprintf(&f.out, "... %d more", len(items)-len(shown))
+	}
+	// A one-item tuple keeps its trailing comma so it reads as a tuple
+	if _, ok := obj.(*Tuple); ok && len(items) == 1 {
+		f.out.WriteString(",")
+	}
+	f.newline(multiline, depth)
+	f.out.WriteString(close)
+}
+
+// separator writes the comma between two items.
+func (f *formatter) separator(multiline bool) {
+	f.out.WriteString(",")
+	if !multiline {
+		f.out.WriteString(" ")
+	}
+}
+
+// newline starts a new line indented for the given depth, if the current
+// container is written across several lines.
+func (f *formatter) newline(multiline bool, depth int) {
+	if multiline {
+		f.out.WriteString("\n")
+		f.lineStart = f.out.Len()
+		f.out.WriteString(strings.Repeat("  ", depth))
+	}
+}
+
+type formatItem struct {
+	key   string
+	value Object
+}
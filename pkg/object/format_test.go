@@ -0,0 +1,97 @@
+package object
+
+import (
+	"testing"
+
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+func TestFormatMatchesInspect(t *testing.T) {
+	values := []Object{
+		NewInt(1),
+		NewString("a"),
+		NewList(nil),
+		NewList([]Object{NewInt(1), NewString("b")}),
+		NewTuple([]Object{NewInt(1)}),
+		NewMap(map[string]Object{"b": NewInt(2), "a": NewList([]Object{True})}),
+	}
+	for _, v := range values {
+		assert.Equal(t, Format(v, FormatOptions{}), v.Inspect())
+	}
+}
+
+func TestFormatDepth(t *testing.T) {
+	value := NewMap(map[string]Object{
+		"a": NewMap(map[string]Object{
+			"b": NewList([]Object{NewInt(1)}),
+		}),
+		"c": NewTuple([]Object{NewInt(2), NewInt(3)}),
+	})
+	assert.Equal(t, Format(value, FormatOptions{MaxDepth: 1}), `{"a": {...}, "c": (...)}`)
+	assert.Equal(t, Format(value, FormatOptions{MaxDepth: 2}), `{"a": {"b": [...]}, "c": (2, 3)}`)
+	// Empty containers are shown in full at any depth
+	assert.Equal(t, Format(NewList([]Object{NewList(nil)}), FormatOptions{MaxDepth: 1}), "[[]]")
+}
+
+func TestFormatItems(t *testing.T) {
+	list := NewList([]Object{NewInt(1), NewInt(2), NewInt(3), NewInt(4)})
+	assert.Equal(t, Format(list, FormatOptions{MaxItems: 2}), "[1, 2, ... 2 more]")
+	assert.Equal(t, Format(list, FormatOptions{MaxItems: 4}), "[1, 2, 3, 4]")
+}
+
+func TestFormatPretty(t *testing.T) {
+	value := NewMap(map[string]Object{
+		"a": NewList([]Object{NewInt(1), NewInt(2)}),
+		"b": NewTuple([]Object{NewInt(3)}),
+		"c": NewMap(nil),
+	})
+	expected := `{
+  "a": [
+    1,
+    2
+  ],
+  "b": (
+    3,
+  ),
+  "c": {}
+}`
+	assert.Equal(t, Format(value, FormatOptions{Pretty: true}), expected)
+}
+
+func TestFormatCycle(t *testing.T) {
+	list := NewList([]Object{NewInt(1)})
+	list.Append(list)
+	m := NewMap(map[string]Object{"list": list})
+	list.Append(m)
+	assert.Equal(t, Format(list, FormatOptions{}), `[1, [...], {"list": [...]}]`)
+	// The same list appearing twice without a cycle is shown both times
+	shared := NewList([]Object{NewInt(1)})
+	pair := NewList([]Object{shared, shared})
+	assert.Equal(t, Format(pair, FormatOptions{}), "[[1], [1]]")
+}
+
+func TestFormatWidth(t *testing.T) {
+	value := NewMap(map[string]Object{
+		"short": NewList([]Object{NewInt(1), NewInt(2)}),
+		"long": NewList([]Object{
+			NewString("alpha"), NewString("beta"), NewString("gamma"),
+		}),
+	})
+	// Containers that fit in the remaining width stay on one line
+	expected := `{
+  "long": [
+    "alpha",
+    "beta",
+    "gamma"
+  ],
+  "short": [1, 2]
+}`
+	assert.Equal(t, Format(value, FormatOptions{Width: 24}), expected)
+	assert.Equal(t, Format(value, FormatOptions{Width: 80}), value.Inspect())
+}
+
+func TestInspectCycleThroughPartial(t *testing.T) {
+	list := NewList(nil)
+	list.Append(NewPartial(NewBuiltin("f", nil), []Object{list}))
+	assert.Equal(t, list.Inspect(), "[partial(builtin(f), [...])]")
+}
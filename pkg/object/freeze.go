@@ -0,0 +1,100 @@
+package object
+
+import "fmt"
+
+// Freeze makes obj and every list and map reachable from it, including
+// through tuples, read-only to scripts. Mutating methods, index assignment
+// and deletion on a frozen container fail with a type error, while reads and
+// copy() work as usual, with copy() returning a mutable copy. Frozen data can be shared between
+// VMs running concurrently.
+//
+// Freezing happens in place and cannot be undone. Go code that holds a
+// frozen container can still modify it through methods such as
+// List.Append, and must not do so while scripts may be reading it.
+//
+// Containers that contain themselves cannot be frozen; Freeze returns an
+// error and leaves obj unchanged. Objects other than lists and maps are
+// ignored.
+func Freeze(obj Object) error {
+	if err := checkFreezable(obj, map[Object]bool{}); err != nil {
+		return err
+	}
+	freeze(obj)
+	return nil
+}
+
+// IsFrozen returns true if obj is a list or map that has been frozen.
+func IsFrozen(obj Object) bool {
+	switch obj := obj.(type) {
+	case *List:
+		return obj.frozen.Load()
+	case *Map:
+		return obj.frozen.Load()
+	}
+	return false
+}
+
+// checkFreezable returns an error if a cycle is reachable from obj. The
+// active map holds the containers on the current path.
+func checkFreezable(obj Object, active map[Object]bool) error {
+	var children []Object
+	switch obj := obj.(type) {
+	case *List:
+		if obj.frozen.Load() {
+			return nil
+		}
+		children = obj.items
+	case *Map:
+		if obj.frozen.Load() {
+			return nil
+		}
+		for _, value := range obj.items {
+			children = append(children, value)
+		}
+		for _, entry := range obj.keyed {
+			children = append(children, entry.key, entry.value)
+		}
+	case *Tuple:
+		children = obj.items
+	default:
+		return nil
+	}
+	if active[obj] {
+		return fmt.Errorf("cannot freeze %s that contains itself", obj.Type())
+	}
+	active[obj] = true
+	defer delete(active, obj)
+	for _, child := range children {
+		if err := checkFreezable(child, active); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func freeze(obj Object) {
+	switch obj := obj.(type) {
+	case *List:
+		if obj.frozen.Swap(true) {
+			return
+		}
+		for _, item := range obj.items {
+			freeze(item)
+		}
+	case *Map:
+		if obj.frozen.Swap(true) {
+			return
+		}
+		for _, value := range obj.items {
+			freeze(value)
+		}
+		for _, entry := range obj.keyed {
+			freeze(entry.key)
+			freeze(entry.value)
+		}
+	case *Tuple:
+		for _, item := range obj.items {
+			freeze(item)
+		}
+	}
+}
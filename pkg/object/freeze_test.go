@@ -0,0 +1,81 @@
+package object
+
+import (
+	"context"
+	"testing"
+
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+func TestFreeze(t *testing.T) {
+	ctx := context.Background()
+	inner := NewList([]Object{NewInt(1)})
+	m := NewMap(map[string]Object{"items": inner, "name": NewString("a")})
+	outer := NewList([]Object{m})
+
+	assert.Nil(t, Freeze(outer))
+	assert.True(t, IsFrozen(outer))
+	assert.True(t, IsFrozen(m))
+	assert.True(t, IsFrozen(inner))
+	assert.False(t, IsFrozen(NewInt(1)))
+
+	// Mutations through the script-facing API fail
+	assert.NotNil(t, outer.SetItem(NewInt(0), Nil))
+	assert.NotNil(t, outer.DelItem(NewInt(0)))
+	assert.NotNil(t, m.SetItem(NewString("name"), NewString("b")))
+	assert.NotNil(t, m.DelItem(NewString("name")))
+	assert.NotNil(t, m.SetAttr("name", NewString("b")))
+	for _, name := range []string{"append", "clear", "reverse", "sort"} {
+		method, ok := inner.GetAttr(name)
+		assert.True(t, ok)
+		var args []Object
+		if name == "append" {
+			args = []Object{NewInt(2)}
+		}
+		_, err := method.(Callable).Call(ctx, args...)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "list is frozen")
+	}
+	update, _ := m.GetAttr("update")
+	_, err := update.(Callable).Call(ctx, NewMap(map[string]Object{"x": NewInt(1)}))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "map is frozen")
+	assert.Equal(t, outer.Inspect(), `[{"items": [1], "name": "a"}]`)
+
+	// Mutations through the Go API fail too
+	assert.Error(t, inner.Append(NewInt(2)))
+	assert.Error(t, inner.Extend(NewList([]Object{NewInt(2)})))
+	assert.Error(t, inner.Insert(0, NewInt(0)))
+	assert.Error(t, inner.Reverse())
+	assert.Error(t, inner.Clear())
+	assert.Error(t, inner.Modify(func(items []Object) ([]Object, error) {
+		return nil, nil
+	}))
+	assert.Equal(t, inner.Inspect(), "[1]")
+
+	// Reads work and copies are mutable
+	value, getErr := m.GetItem(NewString("name"))
+	assert.Nil(t, getErr)
+	assert.Equal(t, value, NewString("a"))
+	copied := inner.Copy()
+	assert.False(t, IsFrozen(copied))
+	copied.Append(NewInt(2))
+	assert.Equal(t, inner.Inspect(), "[1]")
+	assert.False(t, IsFrozen(m.Copy()))
+}
+
+func TestFreezeCycle(t *testing.T) {
+	inner := NewList(nil)
+	outer := NewList([]Object{inner})
+	inner.Append(outer)
+	err := Freeze(outer)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "contains itself")
+	assert.False(t, IsFrozen(outer))
+	assert.False(t, IsFrozen(inner))
+
+	// Shared, acyclic references are fine
+	shared := NewMap(map[string]Object{})
+	assert.Nil(t, Freeze(NewList([]Object{shared, shared})))
+	assert.True(t, IsFrozen(shared))
+}
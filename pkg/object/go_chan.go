@@ -0,0 +1,236 @@
+package object
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/op"
+)
+
+var goChanMethods = NewMethodRegistry[*GoChan]("go_chan")
+
+func init() {
+	goChanMethods.Define("send").
+		Doc("Send a value, blocking until it is received or the script is cancelled").
+		Arg("value").
+		Returns("null").
+		Impl(func(c *GoChan, ctx context.Context, args ...Object) (Object, error) {
+			if err := c.Send(ctx, args[0]); err != nil {
+				return nil, err
+			}
+			return Nil, nil
+		})
+
+	goChanMethods.Define("recv").
+		Doc("Receive a value, blocking until one is available; null once closed").
+		Returns("object").
+		Impl(func(c *GoChan, ctx context.Context, args ...Object) (Object, error) {
+			value, _, err := c.Recv(ctx)
+			return value, err
+		})
+
+	goChanMethods.Define("try_recv").
+		Doc("Receive a value if one is ready, without blocking; null otherwise").
+		Returns("object").
+		Impl(func(c *GoChan, ctx context.Context, args ...Object) (Object, error) {
+			return c.TryRecv()
+		})
+
+	goChanMethods.Define("each").
+		Doc("Call function for each value received until the channel is closed").
+		Arg("fn").
+		Returns("null").
+		Impl(func(c *GoChan, ctx context.Context, args ...Object) (Object, error) {
+			return c.Each(ctx, args[0])
+		})
+
+	goChanMethods.Define("close").
+		Doc("Close the channel").
+		Returns("null").
+		Impl(func(c *GoChan, ctx context.Context, args ...Object) (Object, error) {
+			if err := c.Close(); err != nil {
+				return nil, err
+			}
+			return Nil, nil
+		})
+
+	goChanMethods.Define("len").
+		Doc("Number of values buffered in the channel").
+		Returns("int").
+		Impl(func(c *GoChan, ctx context.Context, args ...Object) (Object, error) {
+			return NewInt(int64(c.value.Len())), nil
+		})
+
+	goChanMethods.Define("cap").
+		Doc("Buffer capacity of the channel").
+		Returns("int").
+		Impl(func(c *GoChan, ctx context.Context, args ...Object) (Object, error) {
+			return NewInt(int64(c.value.Cap())), nil
+		})
+}
+
+// GoChan wraps a Go channel for use in Risor. Scripts send and receive with
+// the send and recv methods, which block until the operation completes or
+// the script's context is cancelled. Values are converted with the
+// channel's TypeRegistry. Spreading a channel, or calling each, receives
+// values until the channel is closed.
+type GoChan struct {
+	value    reflect.Value
+	registry *TypeRegistry
+}
+
+// NewGoChan creates a new GoChan wrapping the given Go channel.
+func NewGoChan(ch reflect.Value, registry *TypeRegistry) *GoChan {
+	if ch.Kind() != reflect.Chan {
+		panic(fmt.Sprintf("GoChan: expected chan, got %s", ch.Kind()))
+	}
+	return &GoChan{value: ch, registry: registry}
+}
+
+func (c *GoChan) Type() Type {
+	return GOCHAN
+}
+
+func (c *GoChan) Inspect() string {
+	return fmt.Sprintf("go_chan(%s)", c.value.Type())
+}
+
+func (c *GoChan) String() string {
+	return c.Inspect()
+}
+
+func (c *GoChan) Interface() any {
+	return c.value.Interface()
+}
+
+func (c *GoChan) Equals(other Object) bool {
+	otherChan, ok := other.(*GoChan)
+	if !ok {
+		return false
+	}
+	return c.value.Pointer() == otherChan.value.Pointer()
+}
+
+func (c *GoChan) Attrs() []AttrSpec {
+	return goChanMethods.Specs()
+}
+
+func (c *GoChan) GetAttr(name string) (Object, bool) {
+	return goChanMethods.GetAttr(c, name)
+}
+
+func (c *GoChan) SetAttr(name string, value Object) error {
+	return TypeErrorf("go_chan has no attribute %q", name)
+}
+
+func (c *GoChan) IsTruthy() bool {
+	return true
+}
+
+func (c *GoChan) RunOperation(opType op.BinaryOpType, right Object) (Object, error) {
+	return nil, newTypeErrorf("unsupported operation for go_chan: %v", opType)
+}
+
+// Send converts value to the channel's element type and sends it.
+func (c *GoChan) Send(ctx context.Context, value Object) error {
+	if c.value.Type().ChanDir()&reflect.SendDir == 0 {
+		return newTypeErrorf("cannot send on receive-only %s", c.value.Type())
+	}
+	elemType := c.value.Type().Elem()
+	goVal, err := c.registry.ToGo(value, elemType)
+	if err != nil {
+		return fmt.Errorf("go_chan.send: %w", err)
+	}
+	chosen, _, _ := reflect.Select([]reflect.SelectCase{
+		{Dir: reflect.SelectSend, Chan: c.value, Send: reflectValue(goVal, elemType)},
+		{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())},
+	})
+	if chosen == 1 {
+		return ctx.Err()
+	}
+	return nil
+}
+
+// Recv receives a value. It returns Nil and false once the channel is
+// closed and drained.
+func (c *GoChan) Recv(ctx context.Context) (Object, bool, error) {
+	if c.value.Type().ChanDir()&reflect.RecvDir == 0 {
+		return nil, false, newTypeErrorf("cannot receive from send-only %s", c.value.Type())
+	}
+	chosen, value, ok := reflect.Select([]reflect.SelectCase{
+		{Dir: reflect.SelectRecv, Chan: c.value},
+		{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())},
+	})
+	if chosen == 1 {
+		return nil, false, ctx.Err()
+	}
+	if !ok {
+		return Nil, false, nil
+	}
+	obj, err := c.registry.FromGo(value.Interface())
+	if err != nil {
+		return nil, false, fmt.Errorf("go_chan.recv: %w", err)
+	}
+	return obj, true, nil
+}
+
+// TryRecv receives a value if one is ready, returning Nil otherwise.
+func (c *GoChan) TryRecv() (Object, error) {
+	if c.value.Type().ChanDir()&reflect.RecvDir == 0 {
+		return nil, newTypeErrorf("cannot receive from send-only %s", c.value.Type())
+	}
+	value, ok := c.value.TryRecv()
+	if !ok {
+		return Nil, nil
+	}
+	return c.registry.FromGo(value.Interface())
+}
+
+// Close closes the channel. Closing a closed channel is an error rather
+// than a panic.
+func (c *GoChan) Close() (err error) {
+	if c.value.Type().ChanDir()&reflect.SendDir == 0 {
+		return newTypeErrorf("cannot close receive-only %s", c.value.Type())
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("go_chan.close: %v", r)
+		}
+	}()
+	c.value.Close()
+	return nil
+}
+
+func (c *GoChan) Each(ctx context.Context, fn Object) (Object, error) {
+	callable, ok := fn.(Callable)
+	if !ok {
+		return nil, newTypeErrorf("go_chan.each() expected a function (%s given)", fn.Type())
+	}
+	for {
+		value, ok, err := c.Recv(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return Nil, nil
+		}
+		if _, err := callable.Call(ctx, value); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Enumerate implements Enumerable, receiving values until the channel is
+// closed or the context is cancelled.
+func (c *GoChan) Enumerate(ctx context.Context, fn func(key, value Object) bool) {
+	for i := int64(0); ; i++ {
+		value, ok, err := c.Recv(ctx)
+		if err != nil || !ok {
+			return
+		}
+		if !fn(NewInt(i), value) {
+			return
+		}
+	}
+}
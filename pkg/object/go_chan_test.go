@@ -0,0 +1,99 @@
+package object
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+func TestGoChanSendRecv(t *testing.T) {
+	ctx := context.Background()
+	ch := make(chan int, 2)
+	obj, err := DefaultRegistry().FromGo(ch)
+	assert.Nil(t, err)
+	c, ok := obj.(*GoChan)
+	assert.True(t, ok)
+	assert.Equal(t, c.Type(), GOCHAN)
+	assert.Equal(t, c.Inspect(), "go_chan(chan int)")
+
+	assert.Nil(t, c.Send(ctx, NewInt(1)))
+	assert.Nil(t, c.Send(ctx, NewFloat(2)))
+	assert.Equal(t, len(ch), 2)
+
+	value, ok, err := c.Recv(ctx)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, value, NewInt(1))
+
+	value, err = c.TryRecv()
+	assert.Nil(t, err)
+	assert.Equal(t, value, NewInt(2))
+	value, err = c.TryRecv()
+	assert.Nil(t, err)
+	assert.Equal(t, value, Nil)
+
+	assert.NotNil(t, c.Send(ctx, NewString("x")))
+
+	assert.Nil(t, c.Close())
+	value, ok, err = c.Recv(ctx)
+	assert.Nil(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, value, Nil)
+	assert.NotNil(t, c.Close())
+}
+
+func TestGoChanCancellation(t *testing.T) {
+	c := NewGoChan(reflect.ValueOf(make(chan string)), DefaultRegistry())
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, _, err := c.Recv(ctx)
+	assert.Equal(t, err, context.DeadlineExceeded)
+	assert.Equal(t, c.Send(ctx, NewString("x")), context.DeadlineExceeded)
+}
+
+func TestGoChanDirection(t *testing.T) {
+	ctx := context.Background()
+	recvOnly := NewGoChan(reflect.ValueOf((<-chan int)(make(chan int))), DefaultRegistry())
+	assert.NotNil(t, recvOnly.Send(ctx, NewInt(1)))
+	assert.NotNil(t, recvOnly.Close())
+
+	sendOnly := NewGoChan(reflect.ValueOf((chan<- int)(make(chan int, 1))), DefaultRegistry())
+	_, _, err := sendOnly.Recv(ctx)
+	assert.NotNil(t, err)
+	_, err = sendOnly.TryRecv()
+	assert.NotNil(t, err)
+}
+
+func TestGoChanEnumerate(t *testing.T) {
+	ch := make(chan string, 3)
+	ch <- "a"
+	ch <- "b"
+	close(ch)
+	c := NewGoChan(reflect.ValueOf(ch), DefaultRegistry())
+	var values []Object
+	c.Enumerate(context.Background(), func(key, value Object) bool {
+		values = append(values, value)
+		return true
+	})
+	assert.Equal(t, values, []Object{NewString("a"), NewString("b")})
+}
+
+func TestGoChanToGo(t *testing.T) {
+	ch := make(chan int)
+	c := NewGoChan(reflect.ValueOf(ch), DefaultRegistry())
+
+	result, err := DefaultRegistry().ToGo(c, reflect.TypeOf(ch))
+	assert.Nil(t, err)
+	assert.True(t, result.(chan int) == ch)
+
+	recvOnly, err := DefaultRegistry().ToGo(c, reflect.TypeOf((<-chan int)(nil)))
+	assert.Nil(t, err)
+	_, ok := recvOnly.(<-chan int)
+	assert.True(t, ok)
+
+	_, err = DefaultRegistry().ToGo(c, reflect.TypeOf(make(chan string)))
+	assert.NotNil(t, err)
+}
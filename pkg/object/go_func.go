@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"runtime/debug"
 
 	"github.com/deepnoodle-ai/risor/v2/pkg/op"
 )
@@ -13,14 +14,14 @@ var _ Callable = (*GoFunc)(nil) // Ensure that *GoFunc implements Callable
 // GoFunc wraps an arbitrary Go function for use in Risor.
 // It uses reflection to handle argument conversion and function calls.
 type GoFunc struct {
-	fn         reflect.Value // The Go function
-	fnType     reflect.Type  // Cached type info
-	name       string        // For error messages
-	numIn      int           // Input count (excluding context if present)
-	isVariadic bool          // Whether the function is variadic
-	hasContext bool          // First param is context.Context
-	hasError   bool          // Last return is error
-	registry   *TypeRegistry // For type conversion
+	fn       reflect.Value // The Go function, or the method's func if receiver is set
+	fnType   reflect.Type  // Function type as called from Risor (without receiver)
+	name     string        // For error messages
+	sig      *funcSig      // Cached signature and converters
+	registry *TypeRegistry // For type conversion
+
+	receiver reflect.Value // Receiver passed as the first argument to a method
+	method   int           // Method index on the receiver's type
 }
 
 // NewGoFunc creates a new GoFunc wrapping the given Go function.
@@ -30,29 +31,37 @@ func NewGoFunc(fn reflect.Value, name string, registry *TypeRegistry) *GoFunc {
 	if fnType.Kind() != reflect.Func {
 		panic(fmt.Sprintf("GoFunc: expected func, got %s", fnType.Kind()))
 	}
-
-	g := &GoFunc{
-		fn:         fn,
-		fnType:     fnType,
-		name:       name,
-		isVariadic: fnType.IsVariadic(),
-		registry:   registry,
+	return &GoFunc{
+		fn:       fn,
+		fnType:   fnType,
+		name:     name,
+		sig:      registry.funcSig(fnType),
+		registry: registry,
 	}
+}
 
-	// Check if first param is context.Context
-	if fnType.NumIn() > 0 && fnType.In(0).Implements(contextInterface) {
-		g.hasContext = true
-		g.numIn = fnType.NumIn() - 1
-	} else {
-		g.numIn = fnType.NumIn()
+// newGoMethod creates a GoFunc for a method of receiver. Calling the
+// method's func with the receiver as the first argument avoids the cost of
+// reflecting through a bound method value on every call.
+func newGoMethod(receiver reflect.Value, m *structMethod, registry *TypeRegistry) *GoFunc {
+	return &GoFunc{
+		fn:       m.fn,
+		fnType:   m.fnType,
+		name:     m.name,
+		sig:      registry.funcSig(m.fnType),
+		registry: registry,
+		receiver: receiver,
+		method:   m.index,
 	}
+}
 
-	// Check if last return is error
-	if fnType.NumOut() > 0 && fnType.Out(fnType.NumOut()-1).Implements(errorInterface) {
-		g.hasError = true
+// value returns the function as a Go func value, binding the receiver of a
+// method.
+func (g *GoFunc) value() reflect.Value {
+	if g.receiver.IsValid() {
+		return g.receiver.Method(g.method)
 	}
-
-	return g
+	return g.fn
 }
 
 func (g *GoFunc) Type() Type {
@@ -68,7 +77,7 @@ func (g *GoFunc) String() string {
 }
 
 func (g *GoFunc) Interface() any {
-	return g.fn.Interface()
+	return g.value().Interface()
 }
 
 func (g *GoFunc) Equals(other Object) bool {
@@ -76,6 +85,11 @@ func (g *GoFunc) Equals(other Object) bool {
 	if !ok {
 		return false
 	}
+	if g.receiver.IsValid() || otherFunc.receiver.IsValid() {
+		return g.receiver.IsValid() && otherFunc.receiver.IsValid() &&
+			g.receiver.Pointer() == otherFunc.receiver.Pointer() &&
+			g.method == otherFunc.method
+	}
 	return g.fn.Pointer() == otherFunc.fn.Pointer()
 }
 
@@ -105,7 +119,7 @@ func (g *GoFunc) Call(ctx context.Context, args ...Object) (result Object, err e
 	// Panic recovery
 	defer func() {
 		if r := recover(); r != nil {
-			err = fmt.Errorf("panic in %s: %v", g.name, r)
+			err = NewPanicError(g.name, r, debug.Stack())
 			result = nil
 		}
 	}()
@@ -123,7 +137,7 @@ func (g *GoFunc) Call(ctx context.Context, args ...Object) (result Object, err e
 
 	// Call the function
 	var results []reflect.Value
-	if g.isVariadic {
+	if g.sig.isVariadic {
 		results = g.fn.CallSlice(callArgs)
 	} else {
 		results = g.fn.Call(callArgs)
@@ -133,103 +147,151 @@ func (g *GoFunc) Call(ctx context.Context, args ...Object) (result Object, err e
 }
 
 func (g *GoFunc) validateArgCount(numArgs int) error {
-	if g.isVariadic {
+	if g.sig.isVariadic {
 		// Variadic functions need at least numIn-1 arguments
-		minArgs := g.numIn - 1
+		minArgs := g.sig.numIn - 1
 		if numArgs < minArgs {
 			return fmt.Errorf("%s: expected at least %d argument(s), got %d", g.name, minArgs, numArgs)
 		}
 	} else {
-		if numArgs != g.numIn {
-			return fmt.Errorf("%s: expected %d argument(s), got %d", g.name, g.numIn, numArgs)
+		if numArgs != g.sig.numIn {
+			return fmt.Errorf("%s: expected %d argument(s), got %d", g.name, g.sig.numIn, numArgs)
 		}
 	}
 	return nil
 }
 
 func (g *GoFunc) buildCallArgs(ctx context.Context, args []Object) ([]reflect.Value, error) {
-	fnType := g.fnType
-	totalIn := fnType.NumIn()
+	sig := g.sig
+	callArgs := make([]reflect.Value, 0, len(args)+2)
 
-	var callArgs []reflect.Value
+	// Add the receiver and context if needed
+	if g.receiver.IsValid() {
+		callArgs = append(callArgs, g.receiver)
+	}
+	if sig.hasContext {
+		callArgs = append(callArgs, reflect.ValueOf(&ctx).Elem())
+	}
 
-	// Add context if needed
-	startIdx := 0
-	if g.hasContext {
-		callArgs = append(callArgs, reflect.ValueOf(ctx))
-		startIdx = 1
+	// Convert the non-variadic arguments
+	fixed := len(args)
+	if sig.isVariadic {
+		fixed = sig.numIn - 1
+	}
+	for i := 0; i < fixed; i++ {
+		argVal, err := sig.converters[i](ctx, args[i])
+		if err != nil {
+			return nil, fmt.Errorf("%s: argument %d (%s): %w", g.name, i+1, sig.params[i], err)
+		}
+		callArgs = append(callArgs, argVal)
 	}
 
-	if g.isVariadic {
-		// For variadic functions, we need to build a slice for the last parameter
-		// Convert non-variadic arguments
-		nonVariadicCount := g.numIn - 1
-		for i := 0; i < nonVariadicCount; i++ {
-			targetType := fnType.In(startIdx + i)
-			goVal, err := g.registry.ToGo(args[i], targetType)
+	// For variadic functions, build a slice for the last parameter
+	if sig.isVariadic {
+		elemType := sig.params[fixed]
+		convert := sig.converters[fixed]
+		variadicSlice := reflect.MakeSlice(reflect.SliceOf(elemType), 0, len(args)-fixed)
+		for i := fixed; i < len(args); i++ {
+			argVal, err := convert(ctx, args[i])
 			if err != nil {
-				return nil, fmt.Errorf("%s: argument %d: %w", g.name, i+1, err)
+				return nil, fmt.Errorf("%s: variadic argument %d (%s): %w", g.name, i+1, elemType, err)
 			}
-			callArgs = append(callArgs, reflect.ValueOf(goVal))
+			variadicSlice = reflect.Append(variadicSlice, argVal)
 		}
+		callArgs = append(callArgs, variadicSlice)
+	}
 
-		// Build variadic slice
-		variadicType := fnType.In(totalIn - 1) // This is a slice type
-		elemType := variadicType.Elem()
-		variadicSlice := reflect.MakeSlice(variadicType, 0, len(args)-nonVariadicCount)
-		for i := nonVariadicCount; i < len(args); i++ {
-			goVal, err := g.registry.ToGo(args[i], elemType)
-			if err != nil {
-				return nil, fmt.Errorf("%s: variadic argument %d: %w", g.name, i+1, err)
+	return callArgs, nil
+}
+
+// newCallback returns a Go function of type fnType that calls a Risor
+// callable. Arguments and results are converted with the registry; if the
+// function's last result is an error, errors from the call are returned
+// there, and otherwise they panic, which GoFunc.Call reports as an error.
+// The callback runs on the calling goroutine's VM, so it must only be called
+// while the Go function that received it is running, and not concurrently.
+func newCallback(ctx context.Context, callable Callable, fnType reflect.Type, registry *TypeRegistry) reflect.Value {
+	numOut := fnType.NumOut()
+	hasError := numOut > 0 && fnType.Out(numOut-1) == errorInterface
+	return reflect.MakeFunc(fnType, func(in []reflect.Value) []reflect.Value {
+		out := make([]reflect.Value, numOut)
+		for i := range out {
+			out[i] = reflect.Zero(fnType.Out(i))
+		}
+		fail := func(err error) []reflect.Value {
+			if !hasError {
+				panic(err)
 			}
-			variadicSlice = reflect.Append(variadicSlice, reflect.ValueOf(goVal))
+			out[numOut-1] = reflect.ValueOf(&err).Elem()
+			return out
 		}
-		callArgs = append(callArgs, variadicSlice)
-	} else {
-		// Convert all arguments
-		for i := 0; i < len(args); i++ {
-			targetType := fnType.In(startIdx + i)
-			goVal, err := g.registry.ToGo(args[i], targetType)
+
+		args := make([]Object, len(in))
+		for i, v := range in {
+			obj, err := registry.FromGo(v.Interface())
 			if err != nil {
-				return nil, fmt.Errorf("%s: argument %d: %w", g.name, i+1, err)
+				return fail(fmt.Errorf("callback argument %d: %w", i+1, err))
 			}
-			callArgs = append(callArgs, reflect.ValueOf(goVal))
+			args[i] = obj
+		}
+		result, err := callable.Call(ctx, args...)
+		if err != nil {
+			return fail(err)
 		}
-	}
 
-	return callArgs, nil
+		// Results other than the error: one value, or a list or tuple of
+		// values
+		values := out
+		if hasError {
+			values = out[:numOut-1]
+		}
+		results := []Object{result}
+		if len(values) > 1 {
+			switch result := result.(type) {
+			case *List:
+				results = result.items
+			case *Tuple:
+				results = result.items
+			default:
+				results = nil
+			}
+			if len(results) != len(values) {
+				return fail(fmt.Errorf("callback must return a list or tuple of %d values", len(values)))
+			}
+		}
+		for i := range values {
+			goVal, err := registry.ToGo(results[i], fnType.Out(i))
+			if err != nil {
+				return fail(fmt.Errorf("callback result: %w", err))
+			}
+			values[i] = reflectValue(goVal, fnType.Out(i))
+		}
+		return out
+	})
 }
 
 func (g *GoFunc) processResults(results []reflect.Value) (Object, error) {
-	numOut := len(results)
-	if numOut == 0 {
-		return Nil, nil
-	}
-
 	// Check for error in last position
-	if g.hasError {
-		errVal := results[numOut-1]
+	if g.sig.hasError {
+		errVal := results[len(results)-1]
 		if !errVal.IsNil() {
 			return nil, errVal.Interface().(error)
 		}
-		results = results[:numOut-1]
-		numOut--
+		results = results[:len(results)-1]
 	}
 
-	// No return values (or only error)
-	if numOut == 0 {
+	switch len(results) {
+	case 0:
+		// No return values (or only error)
 		return Nil, nil
-	}
-
-	// Single return value
-	if numOut == 1 {
-		return g.registry.FromGo(results[0].Interface())
+	case 1:
+		return g.sig.results[0](results[0])
 	}
 
 	// Multiple return values -> List
-	items := make([]Object, numOut)
+	items := make([]Object, len(results))
 	for i, rv := range results {
-		obj, err := g.registry.FromGo(rv.Interface())
+		obj, err := g.sig.results[i](rv)
 		if err != nil {
 			return nil, fmt.Errorf("%s: return value %d: %w", g.name, i+1, err)
 		}
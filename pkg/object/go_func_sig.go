@@ -0,0 +1,158 @@
+package object
+
+import (
+	"context"
+	"reflect"
+)
+
+var (
+	stringType  = reflect.TypeOf("")
+	intType     = reflect.TypeOf(int(0))
+	int64Type   = reflect.TypeOf(int64(0))
+	float64Type = reflect.TypeOf(float64(0))
+	boolType    = reflect.TypeOf(false)
+)
+
+// argConverter converts a Risor argument to a Go parameter value.
+type argConverter func(ctx context.Context, obj Object) (reflect.Value, error)
+
+// resultConverter converts a Go result value to a Risor Object.
+type resultConverter func(v reflect.Value) (Object, error)
+
+// funcSig holds what a GoFunc needs to call a function of one type: the
+// shape of its signature and a converter for each parameter and result.
+// Signatures are computed once per function type and registry, so repeated
+// calls do not re-inspect the type.
+type funcSig struct {
+	numIn      int  // Input count (excluding context if present)
+	isVariadic bool // Whether the function is variadic
+	hasContext bool // First param is context.Context
+	hasError   bool // Last return is error
+
+	params     []reflect.Type // Parameter types, excluding context; variadic elem last
+	converters []argConverter // One per entry in params
+	results    []resultConverter
+}
+
+// funcSig returns the cached signature for a function type.
+func (r *TypeRegistry) funcSig(fnType reflect.Type) *funcSig {
+	if sig, ok := r.funcSigs.Load(fnType); ok {
+		return sig.(*funcSig)
+	}
+	sig, _ := r.funcSigs.LoadOrStore(fnType, r.newFuncSig(fnType))
+	return sig.(*funcSig)
+}
+
+func (r *TypeRegistry) newFuncSig(fnType reflect.Type) *funcSig {
+	sig := &funcSig{isVariadic: fnType.IsVariadic()}
+
+	start := 0
+	if fnType.NumIn() > 0 && fnType.In(0).Implements(contextInterface) {
+		sig.hasContext = true
+		start = 1
+	}
+	sig.numIn = fnType.NumIn() - start
+	for i := start; i < fnType.NumIn(); i++ {
+		typ := fnType.In(i)
+		if sig.isVariadic && i == fnType.NumIn()-1 {
+			typ = typ.Elem()
+		}
+		sig.params = append(sig.params, typ)
+		sig.converters = append(sig.converters, r.argConverter(typ))
+	}
+
+	numOut := fnType.NumOut()
+	if numOut > 0 && fnType.Out(numOut-1).Implements(errorInterface) {
+		sig.hasError = true
+		numOut--
+	}
+	for i := 0; i < numOut; i++ {
+		sig.results = append(sig.results, r.resultConverter(fnType.Out(i)))
+	}
+	return sig
+}
+
+// argConverter returns a converter for a parameter type. Common predeclared
+// types get a direct conversion when no custom converter applies; all other
+// types go through ToGo.
+func (r *TypeRegistry) argConverter(typ reflect.Type) argConverter {
+	generic := func(ctx context.Context, obj Object) (reflect.Value, error) {
+		goVal, err := r.ToGo(obj, typ)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflectValue(goVal, typ), nil
+	}
+	if _, custom := r.toGo[typ]; custom {
+		return generic
+	}
+	switch typ {
+	case stringType:
+		return func(ctx context.Context, obj Object) (reflect.Value, error) {
+			if s, ok := obj.(*String); ok {
+				return reflect.ValueOf(s.value), nil
+			}
+			return generic(ctx, obj)
+		}
+	case intType:
+		return func(ctx context.Context, obj Object) (reflect.Value, error) {
+			if i, ok := obj.(*Int); ok {
+				return reflect.ValueOf(int(i.value)), nil
+			}
+			return generic(ctx, obj)
+		}
+	case int64Type:
+		return func(ctx context.Context, obj Object) (reflect.Value, error) {
+			if i, ok := obj.(*Int); ok {
+				return reflect.ValueOf(i.value), nil
+			}
+			return generic(ctx, obj)
+		}
+	case float64Type:
+		return func(ctx context.Context, obj Object) (reflect.Value, error) {
+			if f, ok := obj.(*Float); ok {
+				return reflect.ValueOf(f.value), nil
+			}
+			return generic(ctx, obj)
+		}
+	case boolType:
+		return func(ctx context.Context, obj Object) (reflect.Value, error) {
+			if b, ok := obj.(*Bool); ok {
+				return reflect.ValueOf(b.value), nil
+			}
+			return generic(ctx, obj)
+		}
+	}
+	if typ.Kind() == reflect.Func {
+		// Risor functions passed for func-typed parameters become callbacks
+		return func(ctx context.Context, obj Object) (reflect.Value, error) {
+			if goFunc, ok := obj.(*GoFunc); !ok || !goFunc.fnType.AssignableTo(typ) {
+				if callable, ok := obj.(Callable); ok {
+					return newCallback(ctx, callable, typ, r), nil
+				}
+			}
+			return generic(ctx, obj)
+		}
+	}
+	return generic
+}
+
+// resultConverter returns a converter for a result type, with direct
+// conversions for common predeclared types.
+func (r *TypeRegistry) resultConverter(typ reflect.Type) resultConverter {
+	if _, custom := r.fromGo[typ]; !custom {
+		switch typ {
+		case stringType:
+			return func(v reflect.Value) (Object, error) { return NewString(v.String()), nil }
+		case intType, int64Type:
+			return func(v reflect.Value) (Object, error) { return NewInt(v.Int()), nil }
+		case float64Type:
+			return func(v reflect.Value) (Object, error) { return NewFloat(v.Float()), nil }
+		case boolType:
+			return func(v reflect.Value) (Object, error) { return NewBool(v.Bool()), nil }
+		}
+	}
+	return func(v reflect.Value) (Object, error) {
+		return r.FromGo(v.Interface())
+	}
+}
@@ -911,14 +911,10 @@ func TestGoFunc_InterfaceParameter(t *testing.T) {
 	registry := DefaultRegistry()
 	goFunc := NewGoFunc(reflect.ValueOf(fn), "stringify", registry)
 
-	// Note: Passing Nil to an interface parameter causes a panic because
-	// reflect.Call requires a valid Value. This is a known limitation.
-	// Interface parameters work when you pass actual values that implement
-	// the interface.
-	_, err := goFunc.Call(context.Background(), Nil)
-	// This returns an error due to the reflection limitation
-	assert.NotNil(t, err)
-	assert.Contains(t, err.Error(), "panic")
+	// Nil converts to a nil interface value
+	result, err := goFunc.Call(context.Background(), Nil)
+	assert.Nil(t, err)
+	assert.Equal(t, result.(*String).Value(), "nil")
 }
 
 func TestGoFunc_ZeroValues(t *testing.T) {
@@ -934,3 +930,77 @@ func TestGoFunc_ZeroValues(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, result.(*String).Value(), "0,0.000000,,false")
 }
+
+func TestGoFunc_CallbackParameter(t *testing.T) {
+	fn := func(items []int, f func(int) int) []int {
+		var out []int
+		for _, item := range items {
+			out = append(out, f(item))
+		}
+		return out
+	}
+	goFunc := NewGoFunc(reflect.ValueOf(fn), "apply", DefaultRegistry())
+	double := NewBuiltin("double", func(ctx context.Context, args ...Object) (Object, error) {
+		return NewInt(args[0].(*Int).Value() * 2), nil
+	})
+
+	result, err := goFunc.Call(context.Background(), NewList([]Object{NewInt(1), NewInt(2)}), double)
+	assert.Nil(t, err)
+	assert.Equal(t, result, NewList([]Object{NewInt(2), NewInt(4)}))
+}
+
+func TestGoFunc_CallbackErrors(t *testing.T) {
+	failing := NewBuiltin("fail", func(ctx context.Context, args ...Object) (Object, error) {
+		return nil, errors.New("callback failed")
+	})
+
+	// Returned through the callback's error result
+	withError := func(f func() error) string {
+		if err := f(); err != nil {
+			return "got: " + err.Error()
+		}
+		return "ok"
+	}
+	goFunc := NewGoFunc(reflect.ValueOf(withError), "run", DefaultRegistry())
+	result, err := goFunc.Call(context.Background(), failing)
+	assert.Nil(t, err)
+	assert.Equal(t, result, NewString("got: callback failed"))
+
+	// Raised from the Go function when the callback cannot return it
+	withoutError := func(f func() int) int { return f() }
+	goFunc = NewGoFunc(reflect.ValueOf(withoutError), "run", DefaultRegistry())
+	_, err = goFunc.Call(context.Background(), failing)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "callback failed")
+
+	// Results of the wrong type
+	str := NewBuiltin("str", func(ctx context.Context, args ...Object) (Object, error) {
+		return NewString("x"), nil
+	})
+	_, err = goFunc.Call(context.Background(), str)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "callback result")
+}
+
+func TestGoFunc_CallbackMultipleResults(t *testing.T) {
+	fn := func(f func() (string, int, error)) string {
+		s, n, err := f()
+		return fmt.Sprintf("%s %d %v", s, n, err)
+	}
+	goFunc := NewGoFunc(reflect.ValueOf(fn), "run", DefaultRegistry())
+	pair := NewBuiltin("pair", func(ctx context.Context, args ...Object) (Object, error) {
+		return NewList([]Object{NewString("a"), NewInt(1)}), nil
+	})
+	result, err := goFunc.Call(context.Background(), pair)
+	assert.Nil(t, err)
+	assert.Equal(t, result, NewString("a 1 <nil>"))
+}
+
+func TestGoFunc_GoFuncArgument(t *testing.T) {
+	inc := func(x int) int { return x + 1 }
+	fn := func(f func(int) int) int { return f(1) }
+	goFunc := NewGoFunc(reflect.ValueOf(fn), "run", DefaultRegistry())
+	result, err := goFunc.Call(context.Background(), NewGoFunc(reflect.ValueOf(inc), "inc", DefaultRegistry()))
+	assert.Nil(t, err)
+	assert.Equal(t, result, NewInt(2))
+}
@@ -4,49 +4,135 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"slices"
+	"strings"
 	"sync"
+	"unicode"
 
 	"github.com/deepnoodle-ai/risor/v2/pkg/op"
 )
 
 // structMeta caches reflection metadata for a struct type.
 type structMeta struct {
-	fields  map[string]int // field name -> field index
-	methods map[string]int // method name -> method index (on pointer type)
+	fields     map[string]int           // Risor field name -> field index
+	fieldOrder []structField            // fields in declaration order
+	methods    map[string]*structMethod // method name -> method (on pointer type)
 }
 
-// structMetaCache stores metadata for struct types.
+// structMethod describes an exported method of a struct's pointer type.
+type structMethod struct {
+	index  int
+	name   string        // Qualified name for error messages, e.g. "Client.Get"
+	fn     reflect.Value // Method func taking the receiver as its first argument
+	fnType reflect.Type  // Method type without the receiver
+}
+
+// structField describes an exported struct field as seen by scripts.
+type structField struct {
+	name      string
+	index     int
+	omitEmpty bool
+}
+
+// structMetaCache stores metadata for struct types, for registries that
+// do not map field names.
 var structMetaCache sync.Map // map[reflect.Type]*structMeta
 
+// FieldNameFunc maps a Go struct field name to the name scripts use. It is
+// consulted for exported fields that have no name in a risor tag.
+type FieldNameFunc func(goName string) string
+
+// SnakeCase is a FieldNameFunc that converts Go field names to snake case,
+// keeping initialisms together: "UserID" becomes "user_id" and "HTTPPort"
+// becomes "http_port".
+func SnakeCase(goName string) string {
+	runes := []rune(goName)
+	var sb strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				prev := runes[i-1]
+				nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+				if !unicode.IsUpper(prev) || nextLower {
+					sb.WriteRune('_')
+				}
+			}
+			r = unicode.ToLower(r)
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
 // getStructMeta returns cached metadata for a struct type, creating it if needed.
 func getStructMeta(structType reflect.Type) *structMeta {
 	if meta, ok := structMetaCache.Load(structType); ok {
 		return meta.(*structMeta)
 	}
+	meta, _ := structMetaCache.LoadOrStore(structType, newStructMeta(structType, nil))
+	return meta.(*structMeta)
+}
 
+// newStructMeta indexes the fields and methods of a struct type. Field
+// names come from the `risor:"name,omitempty"` tag, then fieldName, then the
+// Go name. Fields tagged `risor:"-"` are hidden. If two fields end up with
+// the same name, the first one declared wins.
+func newStructMeta(structType reflect.Type, fieldName FieldNameFunc) *structMeta {
 	meta := &structMeta{
 		fields:  make(map[string]int),
-		methods: make(map[string]int),
+		methods: make(map[string]*structMethod),
 	}
 
 	// Index fields
 	for i := range structType.NumField() {
 		field := structType.Field(i)
-		if field.IsExported() {
-			meta.fields[field.Name] = i
+		if !field.IsExported() {
+			continue
+		}
+		tag, hasTag := field.Tag.Lookup("risor")
+		if tag == "-" {
+			continue
 		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = field.Name
+			if fieldName != nil {
+				name = fieldName(field.Name)
+			}
+		}
+		if _, ok := meta.fields[name]; ok {
+			continue
+		}
+		meta.fields[name] = i
+		meta.fieldOrder = append(meta.fieldOrder, structField{
+			name:      name,
+			index:     i,
+			omitEmpty: hasTag && slices.Contains(strings.Split(opts, ","), "omitempty"),
+		})
 	}
 
 	// Index methods on pointer type (includes both pointer and value receiver methods)
 	ptrType := reflect.PointerTo(structType)
 	for i := range ptrType.NumMethod() {
 		method := ptrType.Method(i)
-		if method.IsExported() {
-			meta.methods[method.Name] = i
+		if !method.IsExported() {
+			continue
+		}
+		in := make([]reflect.Type, method.Type.NumIn()-1)
+		for j := range in {
+			in[j] = method.Type.In(j + 1)
+		}
+		out := make([]reflect.Type, method.Type.NumOut())
+		for j := range out {
+			out[j] = method.Type.Out(j)
+		}
+		meta.methods[method.Name] = &structMethod{
+			index:  i,
+			name:   structType.Name() + "." + method.Name,
+			fn:     method.Func,
+			fnType: reflect.FuncOf(in, out, method.Type.IsVariadic()),
 		}
 	}
-
-	structMetaCache.Store(structType, meta)
 	return meta
 }
 
@@ -56,6 +142,7 @@ type GoStruct struct {
 	value      reflect.Value // The struct (always a pointer for addressability)
 	structType reflect.Type  // The struct type (non-pointer)
 	registry   *TypeRegistry // For type conversion
+	methods    sync.Map      // method name -> *GoFunc, bound on first access
 }
 
 // NewGoStruct creates a new GoStruct wrapping the given Go struct pointer.
@@ -101,11 +188,11 @@ func (g *GoStruct) Equals(other Object) bool {
 
 func (g *GoStruct) Attrs() []AttrSpec {
 	// Return specs for fields and methods
-	meta := getStructMeta(g.structType)
+	meta := g.registry.structMeta(g.structType)
 	specs := make([]AttrSpec, 0, len(meta.fields)+len(meta.methods))
 
-	for name := range meta.fields {
-		specs = append(specs, AttrSpec{Name: name})
+	for _, field := range meta.fieldOrder {
+		specs = append(specs, AttrSpec{Name: field.name})
 	}
 	for name := range meta.methods {
 		specs = append(specs, AttrSpec{Name: name})
@@ -115,11 +202,16 @@ func (g *GoStruct) Attrs() []AttrSpec {
 }
 
 func (g *GoStruct) GetAttr(name string) (Object, bool) {
-	meta := getStructMeta(g.structType)
+	meta := g.registry.structMeta(g.structType)
 
 	// Check for field first
 	if idx, ok := meta.fields[name]; ok {
 		fieldVal := g.value.Elem().Field(idx)
+		// Func fields are named after the field for error messages
+		if fieldVal.Kind() == reflect.Func && !fieldVal.IsNil() {
+			fieldName := fmt.Sprintf("%s.%s", g.structType.Name(), name)
+			return NewGoFunc(fieldVal, fieldName, g.registry), true
+		}
 		obj, err := g.registry.FromGo(fieldVal.Interface())
 		if err != nil {
 			return nil, false
@@ -128,17 +220,19 @@ func (g *GoStruct) GetAttr(name string) (Object, bool) {
 	}
 
 	// Check for method
-	if idx, ok := meta.methods[name]; ok {
-		method := g.value.Method(idx)
-		methodName := fmt.Sprintf("%s.%s", g.structType.Name(), name)
-		return NewGoFunc(method, methodName, g.registry), true
+	if m, ok := meta.methods[name]; ok {
+		if fn, ok := g.methods.Load(name); ok {
+			return fn.(*GoFunc), true
+		}
+		fn, _ := g.methods.LoadOrStore(name, newGoMethod(g.value, m, g.registry))
+		return fn.(*GoFunc), true
 	}
 
 	return nil, false
 }
 
 func (g *GoStruct) SetAttr(name string, value Object) error {
-	meta := getStructMeta(g.structType)
+	meta := g.registry.structMeta(g.structType)
 
 	idx, ok := meta.fields[name]
 	if !ok {
@@ -155,7 +249,7 @@ func (g *GoStruct) SetAttr(name string, value Object) error {
 		return fmt.Errorf("cannot set field %q: %w", name, err)
 	}
 
-	fieldVal.Set(reflect.ValueOf(goVal))
+	fieldVal.Set(reflectValue(goVal, fieldVal.Type()))
 	return nil
 }
 
@@ -1021,6 +1021,38 @@ func TestGoStruct_MetadataCaching(t *testing.T) {
 	assert.Equal(t, n3.(*String).Value(), "Charlie")
 }
 
+func TestGoStruct_MethodCaching(t *testing.T) {
+	registry := DefaultRegistry()
+	p1 := &testPerson{Name: "Alice"}
+	gs1 := NewGoStruct(reflect.ValueOf(p1), registry)
+	gs2 := NewGoStruct(reflect.ValueOf(&testPerson{Name: "Bob"}), registry)
+
+	// Methods are bound once per struct value
+	m1, _ := gs1.GetAttr("Greet")
+	m1Again, _ := gs1.GetAttr("Greet")
+	assert.True(t, m1 == m1Again)
+	assert.True(t, m1.Equals(m1Again))
+	m2, _ := gs2.GetAttr("Greet")
+	assert.False(t, m1.Equals(m2))
+
+	// Each bound method calls its own receiver
+	r1, err := m1.(*GoFunc).Call(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, r1, NewString("Hello, Alice"))
+	r2, err := m2.(*GoFunc).Call(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, r2, NewString("Hello, Bob"))
+
+	// The Go value of a method is bound to its receiver
+	greet, ok := m1.Interface().(func() string)
+	assert.True(t, ok)
+	assert.Equal(t, greet(), "Hello, Alice")
+
+	add, _ := gs1.GetAttr("Add")
+	_, err = add.(*GoFunc).Call(context.Background(), NewInt(1))
+	assert.Error(t, err, "testPerson.Add: expected 2 argument(s), got 1")
+}
+
 // =============================================================================
 // EDGE CASES
 // =============================================================================
@@ -1178,3 +1210,49 @@ func TestGoStruct_LargeStruct(t *testing.T) {
 	assert.True(t, ok)
 	assert.Equal(t, v.(*Float).Value(), 5.5)
 }
+
+// =============================================================================
+// BENCHMARKS
+// =============================================================================
+
+type benchCounter struct {
+	Total int
+}
+
+func (c *benchCounter) Add(ctx context.Context, name string, n int) (int, error) {
+	c.Total += n
+	return c.Total, nil
+}
+
+func BenchmarkGoStructMethodCall(b *testing.B) {
+	obj, err := DefaultRegistry().FromGo(&benchCounter{})
+	if err != nil {
+		b.Fatal(err)
+	}
+	gs := obj.(*GoStruct)
+	ctx := context.Background()
+	args := []Object{NewString("x"), NewInt(1)}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		method, _ := gs.GetAttr("Add")
+		if _, err := method.(Callable).Call(ctx, args...); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkGoStructFieldAccess(b *testing.B) {
+	obj, err := DefaultRegistry().FromGo(&benchCounter{Total: 3})
+	if err != nil {
+		b.Fatal(err)
+	}
+	gs := obj.(*GoStruct)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, ok := gs.GetAttr("Total"); !ok {
+			b.Fatal("missing field")
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package object
+
+import (
+	"strconv"
+	"strings"
+)
+
+// HashKey identifies a map key. Keys that are equal have the same HashKey,
+// so it can be used as the key of a Go map.
+type HashKey struct {
+	Type  Type
+	Value string
+}
+
+// Hashable is implemented by objects that can be used as map keys: strings,
+// ints, bools, and tuples of hashable items.
+type Hashable interface {
+	// HashKey returns the identity of the object as a map key. Returns
+	// false if the object cannot be hashed, such as a tuple holding a list.
+	HashKey() (HashKey, bool)
+}
+
+// HashKeyOf returns the identity of obj as a map key, or a type error if
+// obj cannot be used as one.
+func HashKeyOf(obj Object) (HashKey, *Error) {
+	if h, ok := obj.(Hashable); ok {
+		if key, ok := h.HashKey(); ok {
+			return key, nil
+		}
+	}
+	return HashKey{}, TypeErrorf("unhashable map key type: %s", obj.Type())
+}
+
+func (s *String) HashKey() (HashKey, bool) {
+	return HashKey{Type: STRING, Value: s.value}, true
+}
+
+func (i *Int) HashKey() (HashKey, bool) {
+	return HashKey{Type: INT, Value: strconv.FormatInt(i.value, 10)}, true
+}
+
+func (b *Bool) HashKey() (HashKey, bool) {
+	return HashKey{Type: BOOL, Value: strconv.FormatBool(b.value)}, true
+}
+
+func (t *Tuple) HashKey() (HashKey, bool) {
+	// Each item is written with its type and length so that different
+	// tuples cannot produce the same encoding
+	var b strings.Builder
+	for _, item := range t.items {
+		h, ok := item.(Hashable)
+		if !ok {
+			return HashKey{}, false
+		}
+		key, ok := h.HashKey()
+		if !ok {
+			return HashKey{}, false
+		}
+		b.WriteString(string(key.Type))
+		b.WriteByte(':')
+		b.WriteString(strconv.Itoa(len(key.Value)))
+		b.WriteByte(':')
+		b.WriteString(key.Value)
+	}
+	return HashKey{Type: TUPLE, Value: b.String()}, true
+}
+
+// compareKeys orders map keys for iteration: by type name, then by value.
+func compareKeys(a, b Object) int {
+	if c := CompareTypes(a, b); c != 0 {
+		return c
+	}
+	if comparable, ok := a.(Comparable); ok {
+		if c, err := comparable.Compare(b); err == nil {
+			return c
+		}
+	}
+	return strings.Compare(a.Inspect(), b.Inspect())
+}
@@ -0,0 +1,92 @@
+package object
+
+import (
+	"reflect"
+	"sort"
+)
+
+// Dir returns the sorted names of the attributes that can be read from obj
+// with dot syntax: its methods and properties, the members of a module or
+// enum, and the string keys of a map.
+func Dir(obj Object) []string {
+	names := map[string]bool{}
+	for _, spec := range obj.Attrs() {
+		names[spec.Name] = true
+	}
+	switch obj := obj.(type) {
+	case *Module:
+		for name := range obj.builtins {
+			names[name] = true
+		}
+		for name := range obj.globalsIndex {
+			names[name] = true
+		}
+	case *Enum:
+		for name := range obj.index {
+			names[name] = true
+		}
+	case *Map:
+		for name := range obj.items {
+			names[name] = true
+		}
+	}
+	result := make([]string, 0, len(names))
+	for name := range names {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// Approximate sizes in bytes of the Go values that hold container items.
+const (
+	sliceItemSize = 16 // one interface value
+	mapEntrySize  = 64 // a string key, an interface value, and map overhead
+)
+
+// SizeOf returns an estimate of the memory in bytes held by obj, including
+// the items of lists, maps, and tuples. An object reachable more than once
+// is counted once. The estimate is meant for comparing values and spotting
+// large ones, not for exact accounting.
+func SizeOf(obj Object) int64 {
+	return sizeOf(obj, map[Object]bool{})
+}
+
+func sizeOf(obj Object, seen map[Object]bool) int64 {
+	if obj == nil {
+		return 0
+	}
+	t := reflect.TypeOf(obj)
+	if t.Kind() == reflect.Pointer {
+		if seen[obj] {
+			return 0
+		}
+		seen[obj] = true
+		t = t.Elem()
+	}
+	size := int64(t.Size())
+	switch obj := obj.(type) {
+	case *String:
+		size += int64(len(obj.value))
+	case *Bytes:
+		size += int64(cap(obj.value))
+	case *List:
+		size += int64(cap(obj.items)) * sliceItemSize
+		for _, item := range obj.items {
+			size += sizeOf(item, seen)
+		}
+	case *Tuple:
+		size += int64(len(obj.items)) * sliceItemSize
+		for _, item := range obj.items {
+			size += sizeOf(item, seen)
+		}
+	case *Map:
+		for key, value := range obj.items {
+			size += mapEntrySize + int64(len(key)) + sizeOf(value, seen)
+		}
+		for _, entry := range obj.keyed {
+			size += mapEntrySize + sizeOf(entry.key, seen) + sizeOf(entry.value, seen)
+		}
+	}
+	return size
+}
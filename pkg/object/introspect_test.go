@@ -0,0 +1,61 @@
+package object
+
+import (
+	"testing"
+
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+func TestDir(t *testing.T) {
+	names := Dir(NewList(nil))
+	assert.Contains(t, names, "append")
+	assert.Contains(t, names, "sort")
+
+	m := NewMap(map[string]Object{"name": NewString("x"), "keys": NewInt(1)})
+	names = Dir(m)
+	assert.Contains(t, names, "name")
+	assert.Contains(t, names, "update")
+	// Keys that shadow methods are listed once
+	count := 0
+	for _, name := range names {
+		if name == "keys" {
+			count++
+		}
+	}
+	assert.Equal(t, count, 1)
+	assert.True(t, sortedStrings(names))
+
+	mod := NewBuiltinsModule("util", map[string]Object{"helper": Nil})
+	assert.Equal(t, Dir(mod), []string{"__name__", "helper"})
+
+	assert.Equal(t, len(Dir(Nil)), 0)
+}
+
+func TestSizeOf(t *testing.T) {
+	small := SizeOf(NewString("a"))
+	large := SizeOf(NewString("a much longer string value"))
+	assert.True(t, large > small)
+
+	item := NewString("shared item")
+	once := SizeOf(NewList([]Object{item}))
+	twice := SizeOf(NewList([]Object{item, item}))
+	// The second reference adds a slot, not another copy of the string
+	assert.True(t, twice-once < SizeOf(item))
+
+	m := NewMap(map[string]Object{"a": NewList([]Object{NewInt(1), NewInt(2)})})
+	assert.True(t, SizeOf(m) > SizeOf(m.Get("a")))
+
+	// Cycles terminate
+	list := NewList(nil)
+	list.Append(list)
+	assert.True(t, SizeOf(list) > 0)
+}
+
+func sortedStrings(values []string) bool {
+	for i := 1; i < len(values); i++ {
+		if values[i-1] > values[i] {
+			return false
+		}
+	}
+	return true
+}
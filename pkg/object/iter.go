@@ -10,15 +10,116 @@ import (
 // ITER type constant
 const ITER Type = "iter"
 
+var iterMethods = NewMethodRegistry[*Iter]("iter")
+
+func init() {
+	iterMethods.Define("map").
+		Doc("Lazily transform each value with fn").
+		Arg("fn").
+		Returns("iter").
+		Impl(func(it *Iter, ctx context.Context, args ...Object) (Object, error) {
+			return it.Map(args[0])
+		})
+
+	iterMethods.Define("filter").
+		Doc("Lazily keep values where fn returns true").
+		Arg("fn").
+		Returns("iter").
+		Impl(func(it *Iter, ctx context.Context, args ...Object) (Object, error) {
+			return it.Filter(args[0])
+		})
+
+	iterMethods.Define("take").
+		Doc("Lazily stop after the first n values").
+		Arg("n").
+		Returns("iter").
+		Impl(func(it *Iter, ctx context.Context, args ...Object) (Object, error) {
+			n, err := AsInt(args[0])
+			if err != nil {
+				return nil, err
+			}
+			return it.Take(n)
+		})
+
+	iterMethods.Define("drop").
+		Doc("Lazily skip the first n values").
+		Arg("n").
+		Returns("iter").
+		Impl(func(it *Iter, ctx context.Context, args ...Object) (Object, error) {
+			n, err := AsInt(args[0])
+			if err != nil {
+				return nil, err
+			}
+			return it.Drop(n)
+		})
+
+	iterMethods.Define("each").
+		Doc("Call fn for each value").
+		Arg("fn").
+		Returns("null").
+		Impl(func(it *Iter, ctx context.Context, args ...Object) (Object, error) {
+			callable, ok := args[0].(Callable)
+			if !ok {
+				return nil, newTypeErrorf("iter.each() expected a function (%s given)", args[0].Type())
+			}
+			var callErr error
+			err := it.Iterate(ctx, func(key, value Object) bool {
+				if _, callErr = callable.Call(ctx, value); callErr != nil {
+					return false
+				}
+				return true
+			})
+			if err == nil {
+				err = callErr
+			}
+			if err != nil {
+				return nil, err
+			}
+			return Nil, nil
+		})
+
+	iterMethods.Define("collect").
+		Doc("Run the iterator and return its values as a list").
+		Returns("list").
+		Impl(func(it *Iter, ctx context.Context, args ...Object) (Object, error) {
+			return it.Collect(ctx)
+		})
+}
+
+// Iterator is an Enumerable whose enumeration can fail, such as an iterator
+// whose map function raises an error. Consumers that can report errors
+// should use Iterate to enumerate any Enumerable.
+type Iterator interface {
+	Enumerable
+
+	// Iterate is like Enumerate, but returns the error that ended the
+	// enumeration early, if any.
+	Iterate(ctx context.Context, fn func(key, value Object) bool) error
+}
+
+// Iterate enumerates obj, returning any error reported by an Iterator.
+func Iterate(ctx context.Context, obj Enumerable, fn func(key, value Object) bool) error {
+	if it, ok := obj.(Iterator); ok {
+		return it.Iterate(ctx, fn)
+	}
+	obj.Enumerate(ctx, fn)
+	return nil
+}
+
 // Iter is a lazy iterator that wraps a generator function.
 // It implements Enumerable so it can be used with spread, list(), etc.
+//
+// The map, filter, take and drop methods return new iterators that do
+// their work as values are consumed, so long or unbounded sequences are
+// processed without building intermediate lists. Keys are the position of
+// each value in the iterator's output.
 type Iter struct {
 	// description for Inspect/debugging
 	desc string
 
 	// generator yields key-value pairs to the callback.
 	// Return false from the callback to stop iteration.
-	generator func(ctx context.Context, fn func(key, value Object) bool)
+	generator func(ctx context.Context, fn func(key, value Object) bool) error
 }
 
 func (it *Iter) Type() Type {
@@ -49,11 +150,11 @@ func (it *Iter) Equals(other Object) bool {
 }
 
 func (it *Iter) Attrs() []AttrSpec {
-	return nil
+	return iterMethods.Specs()
 }
 
 func (it *Iter) GetAttr(name string) (Object, bool) {
-	return nil, false
+	return iterMethods.GetAttr(it, name)
 }
 
 func (it *Iter) SetAttr(name string, value Object) error {
@@ -73,22 +174,157 @@ func (it *Iter) Enumerate(ctx context.Context, fn func(key, value Object) bool)
 	it.generator(ctx, fn)
 }
 
+// Iterate implements Iterator.
+func (it *Iter) Iterate(ctx context.Context, fn func(key, value Object) bool) error {
+	return it.generator(ctx, fn)
+}
+
+// Map returns an iterator that yields the result of calling fn on each
+// value.
+func (it *Iter) Map(fn Object) (*Iter, error) {
+	callable, ok := fn.(Callable)
+	if !ok {
+		return nil, newTypeErrorf("iter.map() expected a function (%s given)", fn.Type())
+	}
+	return NewIterFunc(it.desc+".map", func(ctx context.Context, yield func(key, value Object) bool) error {
+		var callErr error
+		err := it.Iterate(ctx, func(key, value Object) bool {
+			var result Object
+			if result, callErr = callable.Call(ctx, value); callErr != nil {
+				return false
+			}
+			return yield(key, result)
+		})
+		if err != nil {
+			return err
+		}
+		return callErr
+	}), nil
+}
+
+// Filter returns an iterator that yields the values for which fn returns
+// a truthy value.
+func (it *Iter) Filter(fn Object) (*Iter, error) {
+	callable, ok := fn.(Callable)
+	if !ok {
+		return nil, newTypeErrorf("iter.filter() expected a function (%s given)", fn.Type())
+	}
+	return NewIterFunc(it.desc+".filter", func(ctx context.Context, yield func(key, value Object) bool) error {
+		var callErr error
+		index := int64(0)
+		err := it.Iterate(ctx, func(key, value Object) bool {
+			var decision Object
+			if decision, callErr = callable.Call(ctx, value); callErr != nil {
+				return false
+			}
+			if !decision.IsTruthy() {
+				return true
+			}
+			index++
+			return yield(NewInt(index-1), value)
+		})
+		if err != nil {
+			return err
+		}
+		return callErr
+	}), nil
+}
+
+// Take returns an iterator that yields at most the first n values.
+func (it *Iter) Take(n int64) (*Iter, error) {
+	if n < 0 {
+		return nil, newValueErrorf("iter.take() expected a non-negative count (got %d)", n)
+	}
+	return NewIterFunc(it.desc+".take", func(ctx context.Context, yield func(key, value Object) bool) error {
+		if n == 0 {
+			return nil
+		}
+		count := int64(0)
+		return it.Iterate(ctx, func(key, value Object) bool {
+			count++
+			return yield(key, value) && count < n
+		})
+	}), nil
+}
+
+// Drop returns an iterator that skips the first n values.
+func (it *Iter) Drop(n int64) (*Iter, error) {
+	if n < 0 {
+		return nil, newValueErrorf("iter.drop() expected a non-negative count (got %d)", n)
+	}
+	return NewIterFunc(it.desc+".drop", func(ctx context.Context, yield func(key, value Object) bool) error {
+		count := int64(0)
+		return it.Iterate(ctx, func(key, value Object) bool {
+			count++
+			if count <= n {
+				return true
+			}
+			return yield(NewInt(count-n-1), value)
+		})
+	}), nil
+}
+
+// Collect runs the iterator and returns its values as a list.
+func (it *Iter) Collect(ctx context.Context) (*List, error) {
+	var items []Object
+	err := it.Iterate(ctx, func(key, value Object) bool {
+		items = append(items, value)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return NewList(items), nil
+}
+
 // NewIter creates a new iterator with a description and generator function.
 func NewIter(desc string, gen func(ctx context.Context, fn func(key, value Object) bool)) *Iter {
+	return NewIterFunc(desc, func(ctx context.Context, fn func(key, value Object) bool) error {
+		gen(ctx, fn)
+		return nil
+	})
+}
+
+// NewIterFunc creates an iterator whose generator can fail. An error
+// returned by the generator is reported to consumers that use Iterate.
+func NewIterFunc(desc string, gen func(ctx context.Context, fn func(key, value Object) bool) error) *Iter {
 	return &Iter{
 		desc:      desc,
 		generator: gen,
 	}
 }
 
+// IterOf returns an iterator over the values of obj, with keys renumbered
+// from 0. An Iter is returned as is. As with spread, iterating a map yields
+// its keys in sorted order.
+func IterOf(obj Enumerable) *Iter {
+	switch obj := obj.(type) {
+	case *Iter:
+		return obj
+	case *Map:
+		return NewMapKeyIter(obj)
+	}
+	desc := "enumerable"
+	if o, ok := obj.(Object); ok {
+		desc = string(o.Type())
+	}
+	return NewIterFunc(desc, func(ctx context.Context, fn func(key, value Object) bool) error {
+		index := int64(0)
+		return Iterate(ctx, obj, func(key, value Object) bool {
+			index++
+			return fn(NewInt(index-1), value)
+		})
+	})
+}
+
 // NewMapKeyIter creates an iterator over map keys.
 func NewMapKeyIter(m *Map) *Iter {
 	return NewIter("map.keys", func(ctx context.Context, fn func(key, value Object) bool) {
-		for i, k := range m.SortedKeys() {
+		for i, entry := range m.sortedEntries() {
 			if ctx.Err() != nil {
 				return
 			}
-			if !fn(NewInt(int64(i)), NewString(k)) {
+			if !fn(NewInt(int64(i)), entry.key) {
 				return
 			}
 		}
@@ -98,11 +334,11 @@ func NewMapKeyIter(m *Map) *Iter {
 // NewMapValueIter creates an iterator over map values.
 func NewMapValueIter(m *Map) *Iter {
 	return NewIter("map.values", func(ctx context.Context, fn func(key, value Object) bool) {
-		for i, k := range m.SortedKeys() {
+		for i, entry := range m.sortedEntries() {
 			if ctx.Err() != nil {
 				return
 			}
-			if !fn(NewInt(int64(i)), m.items[k]) {
+			if !fn(NewInt(int64(i)), entry.value) {
 				return
 			}
 		}
@@ -112,11 +348,11 @@ func NewMapValueIter(m *Map) *Iter {
 // NewMapItemIter creates an iterator over map [key, value] pairs.
 func NewMapItemIter(m *Map) *Iter {
 	return NewIter("map.entries", func(ctx context.Context, fn func(key, value Object) bool) {
-		for i, k := range m.SortedKeys() {
+		for i, entry := range m.sortedEntries() {
 			if ctx.Err() != nil {
 				return
 			}
-			pair := NewList([]Object{NewString(k), m.items[k]})
+			pair := NewList([]Object{entry.key, entry.value})
 			if !fn(NewInt(int64(i)), pair) {
 				return
 			}
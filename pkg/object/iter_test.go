@@ -85,7 +85,11 @@ func TestIterIsTruthy(t *testing.T) {
 
 func TestIterAttrs(t *testing.T) {
 	it := NewIter("test", func(ctx context.Context, fn func(key, value Object) bool) {})
-	assert.Nil(t, it.Attrs())
+	var names []string
+	for _, spec := range it.Attrs() {
+		names = append(names, spec.Name)
+	}
+	assert.Equal(t, names, []string{"map", "filter", "take", "drop", "each", "collect"})
 }
 
 func TestIterGetAttr(t *testing.T) {
@@ -167,3 +171,100 @@ func TestMapItemIter(t *testing.T) {
 	assert.Equal(t, items[0], []any{"a", int64(1)})
 	assert.Equal(t, items[1], []any{"b", int64(2)})
 }
+
+// naturals returns an unbounded iterator over 0, 1, 2, ...
+func naturals() *Iter {
+	return NewIter("naturals", func(ctx context.Context, fn func(key, value Object) bool) {
+		for i := int64(0); ; i++ {
+			if !fn(NewInt(i), NewInt(i)) {
+				return
+			}
+		}
+	})
+}
+
+func TestIterLazyChain(t *testing.T) {
+	ctx := context.Background()
+	calls := 0
+	double := NewBuiltin("double", func(ctx context.Context, args ...Object) (Object, error) {
+		calls++
+		return NewInt(args[0].(*Int).Value() * 2), nil
+	})
+	even := NewBuiltin("even", func(ctx context.Context, args ...Object) (Object, error) {
+		return NewBool(args[0].(*Int).Value()%4 == 0), nil
+	})
+
+	mapped, err := naturals().Map(double)
+	assert.Nil(t, err)
+	filtered, err := mapped.Filter(even)
+	assert.Nil(t, err)
+	dropped, err := filtered.Drop(1)
+	assert.Nil(t, err)
+	taken, err := dropped.Take(3)
+	assert.Nil(t, err)
+	assert.Equal(t, calls, 0)
+
+	result, err := taken.Collect(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, result.Inspect(), "[4, 8, 12]")
+	assert.Equal(t, calls, 7)
+
+	// Keys are renumbered by filter and drop
+	var keys []int64
+	taken.Enumerate(ctx, func(key, value Object) bool {
+		keys = append(keys, key.(*Int).Value())
+		return true
+	})
+	assert.Equal(t, keys, []int64{0, 1, 2})
+
+	empty, err := naturals().Take(0)
+	assert.Nil(t, err)
+	result, err = empty.Collect(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, result.Inspect(), "[]")
+	_, err = naturals().Drop(-1)
+	assert.Error(t, err)
+}
+
+func TestIterErrors(t *testing.T) {
+	ctx := context.Background()
+	fail := NewBuiltin("fail", func(ctx context.Context, args ...Object) (Object, error) {
+		if args[0].(*Int).Value() == 2 {
+			return nil, Errorf("bad value")
+		}
+		return args[0], nil
+	})
+	mapped, err := naturals().Map(fail)
+	assert.Nil(t, err)
+	_, err = mapped.Collect(ctx)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "bad value")
+
+	var seen []Object
+	err = Iterate(ctx, mapped, func(key, value Object) bool {
+		seen = append(seen, value)
+		return true
+	})
+	assert.Error(t, err)
+	assert.Len(t, seen, 2)
+
+	_, err = naturals().Map(NewInt(1))
+	assert.Error(t, err)
+}
+
+func TestIterOf(t *testing.T) {
+	ctx := context.Background()
+	it := naturals()
+	assert.Equal(t, IterOf(it), it)
+
+	m := NewMap(map[string]Object{"b": NewInt(2), "a": NewInt(1)})
+	result, err := IterOf(m).Collect(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, result.Inspect(), `["a", "b"]`)
+
+	ranged := IterOf(NewRange(0, 10, 3))
+	assert.Equal(t, ranged.Inspect(), "iter(range)")
+	result, err = ranged.Collect(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, result.Inspect(), "[0, 3, 6, 9]")
+}
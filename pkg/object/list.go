@@ -1,11 +1,10 @@
 package object
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
-	"strings"
+	"sync/atomic"
 
 	"github.com/deepnoodle-ai/risor/v2/pkg/op"
 )
@@ -18,7 +17,9 @@ func init() {
 		Arg("item").
 		Returns("list").
 		Impl(func(ls *List, ctx context.Context, args ...Object) (Object, error) {
-			ls.Append(args[0])
+			if err := ls.Append(args[0]); err != nil {
+				return nil, err
+			}
 			return ls, nil
 		})
 
@@ -26,7 +27,9 @@ func init() {
 		Doc("Remove all items").
 		Returns("list").
 		Impl(func(ls *List, ctx context.Context, args ...Object) (Object, error) {
-			ls.Clear()
+			if err := ls.Clear(); err != nil {
+				return nil, err
+			}
 			return ls, nil
 		})
 
@@ -62,7 +65,9 @@ func init() {
 			if err != nil {
 				return nil, err
 			}
-			ls.Extend(other)
+			if err := ls.Extend(other); err != nil {
+				return nil, err
+			}
 			return ls, nil
 		})
 
@@ -91,7 +96,9 @@ func init() {
 			if err != nil {
 				return nil, err
 			}
-			ls.Insert(index, args[1])
+			if err := ls.Insert(index, args[1]); err != nil {
+				return nil, err
+			}
 			return ls, nil
 		})
 
@@ -128,7 +135,9 @@ func init() {
 		Arg("item").
 		Returns("null").
 		Impl(func(ls *List, ctx context.Context, args ...Object) (Object, error) {
-			ls.Remove(args[0])
+			if err := ls.Remove(args[0]); err != nil {
+				return nil, err
+			}
 			return ls, nil
 		})
 
@@ -136,7 +145,9 @@ func init() {
 		Doc("Reverse list in place").
 		Returns("list").
 		Impl(func(ls *List, ctx context.Context, args ...Object) (Object, error) {
-			ls.Reverse()
+			if err := ls.Reverse(); err != nil {
+				return nil, err
+			}
 			return ls, nil
 		})
 
@@ -144,7 +155,12 @@ func init() {
 		Doc("Sort list in place").
 		Returns("list").
 		Impl(func(ls *List, ctx context.Context, args ...Object) (Object, error) {
-			if err := Sort(ls.items); err != nil {
+			if err := ls.Modify(func(items []Object) ([]Object, error) {
+				if err := Sort(items); err != nil {
+					return nil, err
+				}
+				return items, nil
+			}); err != nil {
 				return nil, err
 			}
 			return ls, nil
@@ -159,6 +175,9 @@ type List struct {
 	// Used to avoid the possibility of infinite recursion when inspecting.
 	// Similar to the usage of Py_ReprEnter in CPython.
 	inspectActive bool
+
+	// frozen is set by Freeze and makes the list read-only to scripts
+	frozen atomic.Bool
 }
 
 func (ls *List) Attrs() []AttrSpec {
@@ -173,6 +192,14 @@ func (ls *List) SetAttr(name string, value Object) error {
 	return TypeErrorf("list has no attribute %q", name)
 }
 
+// checkMutable returns an error if the list is frozen.
+func (ls *List) checkMutable() *Error {
+	if ls.frozen.Load() {
+		return TypeErrorf("list is frozen")
+	}
+	return nil
+}
+
 func (ls *List) Type() Type {
 	return LIST
 }
@@ -182,23 +209,20 @@ func (ls *List) Value() []Object {
 }
 
 func (ls *List) Inspect() string {
-	// A list can contain itself. Detect if we're already inspecting the list
-	// and return a placeholder if so.
-	if ls.inspectActive {
-		return "[...]"
+	// Format detects cycles among the lists, maps, and tuples it walks. A
+	// list can also be reached again through another object's Inspect, such
+	// as a partial holding it, so detect if we're already inspecting the list
+	// and return a placeholder if so. Frozen lists are acyclic and may be
+	// shared between goroutines, so they skip the bookkeeping.
+	if !ls.frozen.Load() {
+		if ls.inspectActive {
+			return "[...]"
+		}
+		ls.inspectActive = true
+		defer func() { ls.inspectActive = false }()
 	}
-	ls.inspectActive = true
-	defer func() { ls.inspectActive = false }()
 
-	var out bytes.Buffer
-	items := make([]string, 0)
-	for _, e := range ls.items {
-		items = append(items, e.Inspect())
-	}
-	out.WriteString("[")
-	out.WriteString(strings.Join(items, ", "))
-	out.WriteString("]")
-	return out.String()
+	return Format(ls, FormatOptions{})
 }
 
 func (ls *List) Map(ctx context.Context, fn Object) (Object, error) {
@@ -279,14 +303,34 @@ func (ls *List) Reduce(ctx context.Context, initial Object, fn Object) (Object,
 	return accumulator, nil
 }
 
+// Modify calls fn with the items of the list and replaces them with the
+// slice it returns. fn may change the items in place. If the list is
+// frozen, Modify returns an error without calling fn. Every method that
+// changes the list goes through Modify.
+func (ls *List) Modify(fn func(items []Object) ([]Object, error)) error {
+	if err := ls.checkMutable(); err != nil {
+		return err
+	}
+	items, err := fn(ls.items)
+	if err != nil {
+		return err
+	}
+	ls.items = items
+	return nil
+}
+
 // Append adds an item at the end of the list.
-func (ls *List) Append(obj Object) {
-	ls.items = append(ls.items, obj)
+func (ls *List) Append(obj Object) error {
+	return ls.Modify(func(items []Object) ([]Object, error) {
+		return append(items, obj), nil
+	})
 }
 
 // Clear removes all the items from the list.
-func (ls *List) Clear() {
-	ls.items = []Object{}
+func (ls *List) Clear() error {
+	return ls.Modify(func(items []Object) ([]Object, error) {
+		return []Object{}, nil
+	})
 }
 
 // Copy returns a shallow copy of the list.
@@ -308,8 +352,10 @@ func (ls *List) Count(obj Object) int64 {
 }
 
 // Extend adds the items of a list to the end of the current list.
-func (ls *List) Extend(other *List) {
-	ls.items = append(ls.items, other.items...)
+func (ls *List) Extend(other *List) error {
+	return ls.Modify(func(items []Object) ([]Object, error) {
+		return append(items, other.items...), nil
+	})
 }
 
 // Index returns the index of the first item with the specified value.
@@ -323,52 +369,58 @@ func (ls *List) Index(obj Object) int64 {
 }
 
 // Insert adds an item at the specified position.
-func (ls *List) Insert(index int64, obj Object) {
-	// Negative index is relative to the end of the list
-	if index < 0 {
-		index = int64(len(ls.items)) + index
+func (ls *List) Insert(index int64, obj Object) error {
+	return ls.Modify(func(items []Object) ([]Object, error) {
+		// Negative index is relative to the end of the list
 		if index < 0 {
-			index = 0
+			index = max(int64(len(items))+index, 0)
 		}
-	}
-	if index == 0 {
-		ls.items = append([]Object{obj}, ls.items...)
-		return
-	}
-	if index >= int64(len(ls.items)) {
-		ls.items = append(ls.items, obj)
-		return
-	}
-	ls.items = append(ls.items, nil)
-	copy(ls.items[index+1:], ls.items[index:])
-	ls.items[index] = obj
+		if index == 0 {
+			return append([]Object{obj}, items...), nil
+		}
+		if index >= int64(len(items)) {
+			return append(items, obj), nil
+		}
+		items = append(items, nil)
+		copy(items[index+1:], items[index:])
+		items[index] = obj
+		return items, nil
+	})
 }
 
 // Pop removes the item at the specified position.
 func (ls *List) Pop(index int64) (Object, error) {
-	idx, err := ResolveIndex(index, int64(len(ls.items)))
-	if err != nil {
-		return nil, err
-	}
-	result := ls.items[idx]
-	ls.items = append(ls.items[:idx], ls.items[idx+1:]...)
-	return result, nil
+	var result Object
+	err := ls.Modify(func(items []Object) ([]Object, error) {
+		idx, err := ResolveIndex(index, int64(len(items)))
+		if err != nil {
+			return nil, err
+		}
+		result = items[idx]
+		return append(items[:idx], items[idx+1:]...), nil
+	})
+	return result, err
 }
 
 // Remove removes the first item with the specified value.
-func (ls *List) Remove(obj Object) {
-	index := ls.Index(obj)
-	if index == -1 {
-		return
-	}
-	ls.items = append(ls.items[:index], ls.items[index+1:]...)
+func (ls *List) Remove(obj Object) error {
+	return ls.Modify(func(items []Object) ([]Object, error) {
+		index := ls.Index(obj)
+		if index == -1 {
+			return items, nil
+		}
+		return append(items[:index], items[index+1:]...), nil
+	})
 }
 
 // Reverse reverses the order of the list.
-func (ls *List) Reverse() {
-	for i, j := 0, len(ls.items)-1; i < j; i, j = i+1, j-1 {
-		ls.items[i], ls.items[j] = ls.items[j], ls.items[i]
-	}
+func (ls *List) Reverse() error {
+	return ls.Modify(func(items []Object) ([]Object, error) {
+		for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+			items[i], items[j] = items[j], items[i]
+		}
+		return items, nil
+	})
 }
 
 func (ls *List) Interface() interface{} {
@@ -473,6 +525,9 @@ func (ls *List) GetSlice(s Slice) (Object, *Error) {
 
 // SetItem implements the [key] = value operator for a container type.
 func (ls *List) SetItem(key, value Object) *Error {
+	if err := ls.checkMutable(); err != nil {
+		return err
+	}
 	indexObj, ok := key.(*Int)
 	if !ok {
 		return TypeErrorf("list index must be an int (got %s)", key.Type())
@@ -487,6 +542,9 @@ func (ls *List) SetItem(key, value Object) *Error {
 
 // DelItem implements the del [key] operator for a container type.
 func (ls *List) DelItem(key Object) *Error {
+	if err := ls.checkMutable(); err != nil {
+		return err
+	}
 	indexObj, ok := key.(*Int)
 	if !ok {
 		return TypeErrorf("list index must be an int (got %s)", key.Type())
@@ -586,7 +644,8 @@ func ResolveIndex(idx int64, size int64) (int64, error) {
 
 // ResolveIntSlice checks that the slice start and stop indices are inbounds and
 // transforms negative indices into the corresponding positive indices. If the
-// slice is out of bounds, an error is returned.
+// slice is out of bounds, an IndexError is returned. A start index equal to
+// the size selects an empty slice.
 func ResolveIntSlice(slice Slice, size int64) (start int64, stop int64, err error) {
 	if slice.Start != nil {
 		startObj, ok := slice.Start.(*Int)
@@ -609,27 +668,27 @@ func ResolveIntSlice(slice Slice, size int64) (start int64, stop int64, err erro
 	if start < 0 {
 		start = size + start
 		if start < 0 {
-			err = fmt.Errorf("slice error: start index is out of range")
+			err = NewIndexError(fmt.Errorf("slice error: start index is out of range"))
 			return
 		}
 	}
 	if stop < 0 {
 		stop = size + stop
 		if stop < 0 {
-			err = fmt.Errorf("slice error: stop index is out of range")
+			err = NewIndexError(fmt.Errorf("slice error: stop index is out of range"))
 			return
 		}
 	}
 	if start > stop {
-		err = fmt.Errorf("slice error: start index is greater than stop index")
+		err = NewIndexError(fmt.Errorf("slice error: start index is greater than stop index"))
 		return
 	}
-	if start > size-1 {
-		err = fmt.Errorf("slice error: start index is out of range")
+	if start > size {
+		err = NewIndexError(fmt.Errorf("slice error: start index is out of range"))
 		return
 	}
 	if stop > size {
-		err = fmt.Errorf("slice error: stop index is out of range")
+		err = NewIndexError(fmt.Errorf("slice error: stop index is out of range"))
 		return
 	}
 	return start, stop, nil
@@ -2,6 +2,7 @@ package object
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"github.com/deepnoodle-ai/risor/v2/pkg/op"
@@ -706,6 +707,20 @@ func TestResolveIntSlice(t *testing.T) {
 	// Negative stop out of range
 	_, _, err = ResolveIntSlice(Slice{Start: NewInt(0), Stop: NewInt(-10)}, 5)
 	assert.NotNil(t, err)
+	var indexErr *IndexError
+	assert.True(t, errors.As(err, &indexErr))
+
+	// Start at the end selects an empty slice
+	start, stop, err = ResolveIntSlice(Slice{Start: NewInt(5)}, 5)
+	assert.Nil(t, err)
+	assert.Equal(t, start, int64(5))
+	assert.Equal(t, stop, int64(5))
+
+	// Empty sequence
+	start, stop, err = ResolveIntSlice(Slice{}, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, start, int64(0))
+	assert.Equal(t, stop, int64(0))
 }
 
 // mockCallFunc creates a context with a CallFunc that invokes the closure.
@@ -1,12 +1,11 @@
 package object
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"sort"
-	"strings"
+	"sync/atomic"
 
 	"github.com/deepnoodle-ai/risor/v2/pkg/op"
 )
@@ -46,8 +45,8 @@ func init() {
 			if !ok {
 				return nil, newTypeErrorf("map.each() expected a function (%s given)", args[0].Type())
 			}
-			for _, k := range m.SortedKeys() {
-				if _, err := callable.Call(ctx, NewString(k), m.items[k]); err != nil {
+			for _, entry := range m.sortedEntries() {
+				if _, err := callable.Call(ctx, entry.key, entry.value); err != nil {
 					return nil, err
 				}
 			}
@@ -61,11 +60,10 @@ func init() {
 		OptionalArg("default").
 		Returns("any").
 		Impl(func(m *Map, ctx context.Context, args ...Object) (Object, error) {
-			key, err := Arg[*String](args, 0, "map.get")
+			value, found, err := m.lookup(args[0])
 			if err != nil {
 				return nil, err
 			}
-			value, found := m.items[key.value]
 			if found {
 				return value, nil
 			}
@@ -82,13 +80,15 @@ func init() {
 		OptionalArg("default").
 		Returns("any").
 		Impl(func(m *Map, ctx context.Context, args ...Object) (Object, error) {
-			key, err := Arg[*String](args, 0, "map.pop")
+			if err := m.checkMutable(); err != nil {
+				return nil, err
+			}
+			value, found, err := m.lookup(args[0])
 			if err != nil {
 				return nil, err
 			}
-			value, found := m.items[key.value]
 			if found {
-				delete(m.items, key.value)
+				m.remove(args[0])
 				return value, nil
 			}
 			if len(args) > 1 {
@@ -103,14 +103,18 @@ func init() {
 		Args("key", "value").
 		Returns("any").
 		Impl(func(m *Map, ctx context.Context, args ...Object) (Object, error) {
-			key, err := Arg[*String](args, 0, "map.setdefault")
+			if err := m.checkMutable(); err != nil {
+				return nil, err
+			}
+			value, found, err := m.lookup(args[0])
 			if err != nil {
 				return nil, err
 			}
-			if _, found := m.items[key.value]; !found {
-				m.items[key.value] = args[1]
+			if found {
+				return value, nil
 			}
-			return m.items[key.value], nil
+			m.store(args[0], args[1])
+			return args[1], nil
 		})
 
 	// Merge another map
@@ -119,6 +123,9 @@ func init() {
 		Arg("other").
 		Returns("null").
 		Impl(func(m *Map, ctx context.Context, args ...Object) (Object, error) {
+			if err := m.checkMutable(); err != nil {
+				return nil, err
+			}
 			other, ok := args[0].(*Map)
 			if !ok {
 				return nil, newTypeErrorf("map.update() expected a map (%s given)", args[0].Type())
@@ -127,9 +134,7 @@ func init() {
 			if other == m {
 				return Nil, nil
 			}
-			for k, v := range other.items {
-				m.items[k] = v
-			}
+			m.Update(other)
 			return Nil, nil
 		})
 
@@ -138,7 +143,10 @@ func init() {
 		Doc("Remove all items").
 		Returns("null").
 		Impl(func(m *Map, ctx context.Context, args ...Object) (Object, error) {
-			m.items = map[string]Object{}
+			if err := m.checkMutable(); err != nil {
+				return nil, err
+			}
+			m.Clear()
 			return Nil, nil
 		})
 
@@ -151,12 +159,112 @@ func init() {
 		})
 }
 
+// Map is a mutable mapping from keys to values. Keys are usually strings,
+// but ints, bools, and tuples of hashable items may be used as well. Keys
+// are enumerated in sorted order.
 type Map struct {
+	// items holds the entries with string keys
 	items map[string]Object
 
+	// keyed holds the entries with other keys, by the hash of their key. It
+	// is nil until such a key is stored.
+	keyed map[HashKey]mapEntry
+
 	// Used to avoid the possibility of infinite recursion when inspecting.
 	// Similar to the usage of Py_ReprEnter in CPython.
 	inspectActive bool
+
+	// frozen is set by Freeze and makes the map read-only to scripts
+	frozen atomic.Bool
+}
+
+// mapEntry is an entry of a map with a key that is not a string.
+type mapEntry struct {
+	key   Object
+	value Object
+}
+
+// checkMutable returns an error if the map is frozen.
+func (m *Map) checkMutable() *Error {
+	if m.frozen.Load() {
+		return TypeErrorf("map is frozen")
+	}
+	return nil
+}
+
+// lookup returns the value stored under key, and whether it was found.
+// Returns an error if key cannot be used as a map key.
+func (m *Map) lookup(key Object) (Object, bool, *Error) {
+	if s, ok := key.(*String); ok {
+		value, found := m.items[s.value]
+		return value, found, nil
+	}
+	hash, err := HashKeyOf(key)
+	if err != nil {
+		return nil, false, err
+	}
+	entry, found := m.keyed[hash]
+	return entry.value, found, nil
+}
+
+// store sets the value of key. Returns an error if key cannot be used as a
+// map key.
+func (m *Map) store(key, value Object) *Error {
+	if s, ok := key.(*String); ok {
+		m.items[s.value] = value
+		return nil
+	}
+	hash, err := HashKeyOf(key)
+	if err != nil {
+		return err
+	}
+	if m.keyed == nil {
+		m.keyed = map[HashKey]mapEntry{}
+	}
+	m.keyed[hash] = mapEntry{key: key, value: value}
+	return nil
+}
+
+// remove deletes key from the map. Returns an error if key cannot be used
+// as a map key.
+func (m *Map) remove(key Object) *Error {
+	if s, ok := key.(*String); ok {
+		delete(m.items, s.value)
+		return nil
+	}
+	hash, err := HashKeyOf(key)
+	if err != nil {
+		return err
+	}
+	delete(m.keyed, hash)
+	return nil
+}
+
+// sortedEntries returns every entry of the map in key order.
+func (m *Map) sortedEntries() []mapEntry {
+	entries := make([]mapEntry, 0, m.Size())
+	for _, k := range m.SortedKeys() {
+		entries = append(entries, mapEntry{key: NewString(k), value: m.items[k]})
+	}
+	if len(m.keyed) == 0 {
+		return entries
+	}
+	for _, entry := range m.keyed {
+		entries = append(entries, entry)
+	}
+	sort.SliceStable(entries, func(a, b int) bool {
+		return compareKeys(entries[a].key, entries[b].key) < 0
+	})
+	return entries
+}
+
+// keyString returns the string form of a key that is used when the map is
+// converted to Go or JSON.
+func keyString(key Object) string {
+	if s, ok := key.(*String); ok {
+		return s.value
+	}
+	return key.Inspect()
 }
 
 func (m *Map) Type() Type {
@@ -164,35 +272,34 @@ func (m *Map) Type() Type {
 }
 
 func (m *Map) Inspect() string {
-	// A map can contain itself. Detect if we're already inspecting the map
-	// and return a placeholder if so.
-	if m.inspectActive {
-		return "{...}"
+	// A map can also be reached again through another object's Inspect, as
+	// with lists. Frozen maps are acyclic and may be shared between
+	// goroutines, so they skip the bookkeeping.
+	if !m.frozen.Load() {
+		if m.inspectActive {
+			return "{...}"
+		}
+		m.inspectActive = true
+		defer func() { m.inspectActive = false }()
 	}
-	m.inspectActive = true
-	defer func() { m.inspectActive = false }()
 
-	var out bytes.Buffer
-	pairs := make([]string, 0)
-	for _, k := range m.SortedKeys() {
-		v := m.items[k]
-		pairs = append(pairs, fmt.Sprintf("%q: %s", k, v.Inspect()))
-	}
-	out.WriteString("{")
-	out.WriteString(strings.Join(pairs, ", "))
-	out.WriteString("}")
-	return out.String()
+	return Format(m, FormatOptions{})
 }
 
 func (m *Map) String() string {
 	return m.Inspect()
 }
 
+// Value returns the entries of the map that have string keys. Entries with
+// other keys, such as ints or tuples, are not included.
 func (m *Map) Value() map[string]Object {
 	return m.items
 }
 
 func (m *Map) SetAttr(name string, value Object) error {
+	if err := m.checkMutable(); err != nil {
+		return err
+	}
 	// Dot syntax only updates existing keys. Use bracket syntax to add new keys.
 	if _, exists := m.items[name]; !exists {
 		return fmt.Errorf("key error: %q does not exist (use m[%q] = value to add new keys)", name, name)
@@ -217,15 +324,16 @@ func (m *Map) GetAttr(name string) (Object, bool) {
 }
 
 func (m *Map) ListItems() *List {
-	items := make([]Object, 0, len(m.items))
-	for _, k := range m.SortedKeys() {
-		items = append(items, NewList([]Object{NewString(k), m.items[k]}))
+	items := make([]Object, 0, m.Size())
+	for _, entry := range m.sortedEntries() {
+		items = append(items, NewList([]Object{entry.key, entry.value}))
 	}
 	return NewList(items)
 }
 
 func (m *Map) Clear() {
 	m.items = map[string]Object{}
+	m.keyed = nil
 }
 
 func (m *Map) Copy() *Map {
@@ -233,7 +341,14 @@ func (m *Map) Copy() *Map {
 	for k, v := range m.items {
 		items[k] = v
 	}
-	return &Map{items: items}
+	result := &Map{items: items}
+	if len(m.keyed) > 0 {
+		result.keyed = make(map[HashKey]mapEntry, len(m.keyed))
+		for hash, entry := range m.keyed {
+			result.keyed[hash] = entry
+		}
+	}
+	return result
 }
 
 func (m *Map) Pop(key string, def Object) Object {
@@ -259,8 +374,31 @@ func (m *Map) Update(other *Map) {
 	for k, v := range other.items {
 		m.items[k] = v
 	}
+	if len(other.keyed) > 0 && m.keyed == nil {
+		m.keyed = make(map[HashKey]mapEntry, len(other.keyed))
+	}
+	for hash, entry := range other.keyed {
+		m.keyed[hash] = entry
+	}
 }
 
+// CheckStringKeys returns an error if the map has a key that is not a
+// string. Functions that read a map by name, such as a map of options, use
+// it to reject keys that SortedKeys and Get would otherwise skip.
+func (m *Map) CheckStringKeys() error {
+	if len(m.keyed) == 0 {
+		return nil
+	}
+	for _, entry := range m.sortedEntries() {
+		if _, ok := entry.key.(*String); !ok {
+			return TypeErrorf("map keys must be strings (got %s key %s)", entry.key.Type(), entry.key.Inspect())
+		}
+	}
+	return nil
+}
+
+// SortedKeys returns the string keys of the map in sorted order. Keys of
+// other types are not included; see CheckStringKeys.
 func (m *Map) SortedKeys() []string {
 	keys := make([]string, 0, len(m.items))
 	for k := range m.items {
@@ -271,17 +409,17 @@ func (m *Map) SortedKeys() []string {
 }
 
 func (m *Map) Keys() *List {
-	items := make([]Object, 0, len(m.items))
-	for _, k := range m.SortedKeys() {
-		items = append(items, NewString(k))
+	items := make([]Object, 0, m.Size())
+	for _, entry := range m.sortedEntries() {
+		items = append(items, entry.key)
 	}
 	return &List{items: items}
 }
 
 func (m *Map) Values() *List {
-	items := make([]Object, 0, len(m.items))
-	for _, k := range m.SortedKeys() {
-		items = append(items, m.items[k])
+	items := make([]Object, 0, m.Size())
+	for _, entry := range m.sortedEntries() {
+		items = append(items, entry.value)
 	}
 	return &List{items: items}
 }
@@ -320,23 +458,77 @@ func (m *Map) Set(key string, value Object) {
 }
 
 func (m *Map) Size() int {
-	return len(m.items)
+	return len(m.items) + len(m.keyed)
 }
 
+// Interface converts the map to a map[string]any. Keys that are not
+// strings are converted to their string form, such as "1" or "(1, 2)". If
+// that form is also a string key of the map, the entry with the string key
+// is kept; use CheckKeys to detect this first.
 func (m *Map) Interface() interface{} {
-	result := make(map[string]any, len(m.items))
-	for k, v := range m.items {
-		result[k] = v.Interface()
+	result := make(map[string]any, m.Size())
+	for _, entry := range m.keyed {
+		result[keyString(entry.key)] = entry.value.Interface()
+	}
+	for key, value := range m.items {
+		result[key] = value.Interface()
 	}
 	return result
 }
 
+// checkKeys returns an error if a key that is not a string has the same
+// string form as a string key of the map.
+func (m *Map) checkKeys() error {
+	for _, entry := range m.sortedEntries() {
+		if _, ok := entry.key.(*String); ok {
+			continue
+		}
+		name := keyString(entry.key)
+		if _, ok := m.items[name]; ok {
+			return ValueErrorf("map keys %s and %q have the same string form", entry.key.Inspect(), name)
+		}
+	}
+	return nil
+}
+
+// CheckKeys returns an error if obj is, or contains in a list, tuple, or
+// map, a map with two keys that have the same string form, such as 1 and
+// "1". Such a map loses an entry when converted to a Go value or to JSON.
+func CheckKeys(obj Object) error {
+	switch obj := obj.(type) {
+	case *Map:
+		if len(obj.keyed) > 0 {
+			if err := obj.checkKeys(); err != nil {
+				return err
+			}
+		}
+		for _, entry := range obj.sortedEntries() {
+			if err := CheckKeys(entry.value); err != nil {
+				return err
+			}
+		}
+	case *List:
+		for _, item := range obj.items {
+			if err := CheckKeys(item); err != nil {
+				return err
+			}
+		}
+	case *Tuple:
+		for _, item := range obj.Value() {
+			if err := CheckKeys(item); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 func (m *Map) Equals(other Object) bool {
 	otherMap, ok := other.(*Map)
 	if !ok {
 		return false
 	}
-	if len(m.items) != len(otherMap.items) {
+	if len(m.items) != len(otherMap.items) || len(m.keyed) != len(otherMap.keyed) {
 		return false
 	}
 	for k, v := range m.items {
@@ -348,6 +540,15 @@ func (m *Map) Equals(other Object) bool {
 			return false
 		}
 	}
+	for hash, entry := range m.keyed {
+		otherEntry, found := otherMap.keyed[hash]
+		if !found {
+			return false
+		}
+		if !entry.value.Equals(otherEntry.value) {
+			return false
+		}
+	}
 	return true
 }
 
@@ -356,13 +557,15 @@ func (m *Map) RunOperation(opType op.BinaryOpType, right Object) (Object, error)
 }
 
 func (m *Map) GetItem(key Object) (Object, *Error) {
-	strObj, ok := key.(*String)
-	if !ok {
-		return nil, TypeErrorf("map key must be a string (got %s)", key.Type())
+	value, found, err := m.lookup(key)
+	if err != nil {
+		return nil, err
 	}
-	value, found := m.items[strObj.value]
 	if !found {
-		return nil, Errorf("key error: %q", strObj.Value())
+		if s, ok := key.(*String); ok {
+			return nil, Errorf("key error: %q", s.value)
+		}
+		return nil, Errorf("key error: %s", key.Inspect())
 	}
 	return value, nil
 }
@@ -374,51 +577,44 @@ func (m *Map) GetSlice(s Slice) (Object, *Error) {
 
 // SetItem assigns a value to the given key in the map.
 func (m *Map) SetItem(key, value Object) *Error {
-	strObj, ok := key.(*String)
-	if !ok {
-		return TypeErrorf("map key must be a string (got %s)", key.Type())
+	if err := m.checkMutable(); err != nil {
+		return err
 	}
-	m.items[strObj.value] = value
-	return nil
+	return m.store(key, value)
 }
 
 // DelItem deletes the item with the given key from the map.
 func (m *Map) DelItem(key Object) *Error {
-	strObj, ok := key.(*String)
-	if !ok {
-		return TypeErrorf("map key must be a string (got %s)", key.Type())
+	if err := m.checkMutable(); err != nil {
+		return err
 	}
-	delete(m.items, strObj.value)
-	return nil
+	return m.remove(key)
 }
 
 // Contains returns true if the given item is found in this container.
 func (m *Map) Contains(key Object) *Bool {
-	strObj, ok := key.(*String)
-	if !ok {
-		return False
-	}
-	_, found := m.items[strObj.value]
-	return NewBool(found)
+	_, found, err := m.lookup(key)
+	return NewBool(err == nil && found)
 }
 
 func (m *Map) IsTruthy() bool {
-	return len(m.items) > 0
+	return m.Size() > 0
 }
 
 // Len returns the number of items in this container.
 func (m *Map) Len() *Int {
-	return NewInt(int64(len(m.items)))
+	return NewInt(int64(m.Size()))
 }
 
 func (m *Map) Enumerate(ctx context.Context, fn func(key, value Object) bool) {
-	for _, k := range m.SortedKeys() {
-		if !fn(NewString(k), m.items[k]) {
+	for _, entry := range m.sortedEntries() {
+		if !fn(entry.key, entry.value) {
 			return
 		}
 	}
 }
 
+// StringKeys returns the string keys of the map in no particular order.
 func (m *Map) StringKeys() []string {
 	keys := make([]string, 0, len(m.items))
 	for k := range m.items {
@@ -427,8 +623,21 @@ func (m *Map) StringKeys() []string {
 	return keys
 }
 
+// MarshalJSON encodes the map as a JSON object. Keys that are not strings
+// are written in their string form, and it is an error if that form is
+// also a string key of the map.
 func (m *Map) MarshalJSON() ([]byte, error) {
-	return json.Marshal(m.items)
+	if len(m.keyed) == 0 {
+		return json.Marshal(m.items)
+	}
+	if err := m.checkKeys(); err != nil {
+		return nil, err
+	}
+	items := make(map[string]Object, m.Size())
+	for _, entry := range m.sortedEntries() {
+		items[keyString(entry.key)] = entry.value
+	}
+	return json.Marshal(items)
 }
 
 func NewMap(m map[string]Object) *Map {
@@ -284,9 +284,13 @@ func TestMapGetItem(t *testing.T) {
 	_, err = m.GetItem(NewString("missing"))
 	assert.NotNil(t, err)
 
-	// Wrong key type
+	// Missing int key
 	_, err = m.GetItem(NewInt(1))
 	assert.NotNil(t, err)
+
+	// Unhashable key type
+	_, err = m.GetItem(NewList(nil))
+	assert.NotNil(t, err)
 }
 
 func TestMapGetSlice(t *testing.T) {
@@ -303,8 +307,8 @@ func TestMapSetItem(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, m.Get("key").(*Int).Value(), int64(42))
 
-	// Wrong key type
-	err = m.SetItem(NewInt(1), NewInt(42))
+	// Unhashable key type
+	err = m.SetItem(NewFloat(1.5), NewInt(42))
 	assert.NotNil(t, err)
 }
 
@@ -316,9 +320,77 @@ func TestMapDelItem(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, m.Get("key"), Nil)
 
-	// Wrong key type
-	err = m.DelItem(NewInt(1))
+	// Unhashable key type
+	err = m.DelItem(NewList(nil))
+	assert.NotNil(t, err)
+}
+
+func TestMapHashableKeys(t *testing.T) {
+	m := NewMap(map[string]Object{"a": NewInt(1)})
+	pair := NewTuple([]Object{NewInt(1), NewString("x")})
+	assert.Nil(t, m.SetItem(NewInt(2), NewString("two")))
+	assert.Nil(t, m.SetItem(True, NewString("yes")))
+	assert.Nil(t, m.SetItem(pair, NewString("pair")))
+
+	// Equal keys find the same entry
+	value, err := m.GetItem(NewTuple([]Object{NewInt(1), NewString("x")}))
+	assert.Nil(t, err)
+	assert.Equal(t, value, NewString("pair"))
+	assert.Equal(t, m.Contains(NewInt(2)), True)
+	assert.Equal(t, m.Contains(NewString("2")), False)
+
+	// Keys are ordered by type, then value
+	assert.Equal(t, m.Len().Value(), int64(4))
+	assert.Equal(t, m.Inspect(), `{true: "yes", 2: "two", "a": 1, (1, "x"): "pair"}`)
+	assert.Equal(t, m.Keys().Inspect(), `[true, 2, "a", (1, "x")]`)
+
+	// Go and JSON see the string form of the keys
+	assert.Equal(t, m.Interface(), map[string]any{
+		"a": int64(1), "2": "two", "true": "yes", `(1, "x")`: "pair",
+	})
+	data, jsonErr := m.MarshalJSON()
+	assert.Nil(t, jsonErr)
+	assert.Equal(t, string(data), `{"(1, \"x\")":"pair","2":"two","a":1,"true":"yes"}`)
+
+	// Copies and equality include the other keys
+	copied := m.Copy()
+	assert.True(t, copied.Equals(m))
+	assert.Nil(t, copied.DelItem(NewInt(2)))
+	assert.False(t, copied.Equals(m))
+	assert.Equal(t, copied.Len().Value(), int64(3))
+
+	// A tuple holding a list cannot be a key
+	err = m.SetItem(NewTuple([]Object{NewList(nil)}), Nil)
+	assert.NotNil(t, err)
+}
+
+func TestMapKeyCollision(t *testing.T) {
+	m := NewMap(map[string]Object{"1": NewString("b")})
+	assert.Nil(t, m.SetItem(NewInt(1), NewString("a")))
+
+	_, err := m.MarshalJSON()
+	assert.NotNil(t, err)
+	assert.Equal(t, err.Error(), `value error: map keys 1 and "1" have the same string form`)
+
+	nested := NewList([]Object{NewMap(nil), m})
+	err = CheckKeys(nested)
+	assert.NotNil(t, err)
+	assert.Nil(t, CheckKeys(NewList([]Object{NewMap(map[string]Object{"1": Nil})})))
+
+	// Interface keeps the entry with the string key
+	assert.Equal(t, m.Interface(), map[string]any{"1": "b"})
+
+	// Value has only the string keys
+	assert.Equal(t, len(m.Value()), 1)
+}
+
+func TestMapCheckStringKeys(t *testing.T) {
+	m := NewMap(map[string]Object{"a": NewInt(1)})
+	assert.Nil(t, m.CheckStringKeys())
+	assert.Nil(t, m.SetItem(NewTuple([]Object{NewInt(1), NewInt(2)}), Nil))
+	err := m.CheckStringKeys()
 	assert.NotNil(t, err)
+	assert.Equal(t, err.Error(), "type error: map keys must be strings (got tuple key (1, 2))")
 }
 
 func TestMapContains(t *testing.T) {
@@ -0,0 +1,80 @@
+package object
+
+import "sort"
+
+// moduleDocEntry holds documentation for a module.
+type moduleDocEntry struct {
+	description string
+	funcsFn     func() []FuncSpec
+}
+
+// moduleRegistry holds module documentation.
+var moduleRegistry = map[string]moduleDocEntry{}
+
+// RegisterModule registers documentation for a module.
+// This should be called from init() functions in module implementation
+// files, so that tools like risor doc find every module that is linked in.
+func RegisterModule(name, description string, funcsFn func() []FuncSpec) {
+	moduleRegistry[name] = moduleDocEntry{
+		description: description,
+		funcsFn:     funcsFn,
+	}
+}
+
+// ModuleDocs returns documentation for all registered modules, sorted by
+// name.
+func ModuleDocs() []ModuleSpec {
+	names := make([]string, 0, len(moduleRegistry))
+	for name := range moduleRegistry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	specs := make([]ModuleSpec, 0, len(names))
+	for _, name := range names {
+		spec, _ := ModuleDoc(name)
+		specs = append(specs, spec)
+	}
+	return specs
+}
+
+// ModuleDoc returns documentation for a specific module.
+func ModuleDoc(name string) (ModuleSpec, bool) {
+	entry, ok := moduleRegistry[name]
+	if !ok {
+		return ModuleSpec{}, false
+	}
+	var funcs []FuncSpec
+	if entry.funcsFn != nil {
+		funcs = entry.funcsFn()
+	}
+	return ModuleSpec{
+		Name:  name,
+		Doc:   entry.description,
+		Funcs: funcs,
+	}, true
+}
+
+// DescribeModule returns documentation for a module value, such as one a
+// host application added to the environment. A module whose name is
+// registered gets its registered documentation. Otherwise the spec lists
+// the module's functions by name only.
+func DescribeModule(m *Module) ModuleSpec {
+	if spec, ok := ModuleDoc(m.name); ok {
+		return spec
+	}
+	spec := ModuleSpec{Name: m.name}
+	for name, value := range m.builtins {
+		if _, ok := value.(Callable); ok {
+			spec.Funcs = append(spec.Funcs, FuncSpec{Name: name})
+		}
+	}
+	for name, index := range m.globalsIndex {
+		if _, ok := m.globals[index].(Callable); ok {
+			spec.Funcs = append(spec.Funcs, FuncSpec{Name: name})
+		}
+	}
+	sort.Slice(spec.Funcs, func(i, j int) bool {
+		return spec.Funcs[i].Name < spec.Funcs[j].Name
+	})
+	return spec
+}
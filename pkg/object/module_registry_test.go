@@ -0,0 +1,47 @@
+package object
+
+import (
+	"context"
+	"testing"
+
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+func TestModuleRegistry(t *testing.T) {
+	RegisterModule("zz_test", "A test module", func() []FuncSpec {
+		return []FuncSpec{{Name: "run", Doc: "Run it"}}
+	})
+	defer delete(moduleRegistry, "zz_test")
+
+	spec, ok := ModuleDoc("zz_test")
+	assert.True(t, ok)
+	assert.Equal(t, spec.Doc, "A test module")
+	assert.Equal(t, spec.Funcs[0].Name, "run")
+
+	_, ok = ModuleDoc("zz_missing")
+	assert.False(t, ok)
+
+	specs := ModuleDocs()
+	assert.Equal(t, specs[len(specs)-1].Name, "zz_test")
+}
+
+func TestDescribeModule(t *testing.T) {
+	noop := func(ctx context.Context, args ...Object) (Object, error) {
+		return Nil, nil
+	}
+	m := NewBuiltinsModule("host", map[string]Object{
+		"b":     NewBuiltin("b", noop),
+		"a":     NewBuiltin("a", noop),
+		"limit": NewInt(3),
+	})
+	spec := DescribeModule(m)
+	assert.Equal(t, spec.Name, "host")
+	assert.Equal(t, spec.Doc, "")
+	assert.Equal(t, len(spec.Funcs), 2)
+	assert.Equal(t, spec.Funcs[0].Name, "a")
+	assert.Equal(t, spec.Funcs[1].Name, "b")
+
+	RegisterModule("host", "A host module", nil)
+	defer delete(moduleRegistry, "host")
+	assert.Equal(t, DescribeModule(m).Doc, "A host module")
+}
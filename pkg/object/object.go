@@ -31,6 +31,7 @@ type Type string
 // Type constants
 const (
 	BOOL          Type = "bool"
+	BUFFER        Type = "buffer"
 	BUILTIN       Type = "builtin"
 	BYTE          Type = "byte"
 	BYTES         Type = "bytes"
@@ -39,6 +40,8 @@ const (
 	COMPLEX       Type = "complex"
 	COMPLEX_SLICE Type = "complex_slice"
 	DYNAMIC_ATTR  Type = "dynamic_attr"
+	ENUM          Type = "enum"
+	ENUM_MEMBER   Type = "enum_member"
 	ERROR         Type = "error"
 	FLOAT         Type = "float"
 	FUNCTION      Type = "function"
@@ -50,8 +53,12 @@ const (
 	PARTIAL       Type = "partial"
 	RANGE         Type = "range"
 	RESULT        Type = "result"
+	STREAM        Type = "stream"
 	STRING        Type = "string"
+	TASK          Type = "task"
 	TIME          Type = "time"
+	TUPLE         Type = "tuple"
+	GOCHAN        Type = "go_chan"
 	GOFUNC        Type = "go_func"
 	GOSTRUCT      Type = "go_struct"
 )
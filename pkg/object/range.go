@@ -54,6 +54,44 @@ func init() {
 		Impl(func(r *Range, ctx context.Context, args ...Object) (Object, error) {
 			return r.Each(ctx, args[0])
 		})
+
+	rangeAttrs.Define("take").
+		Doc("Lazily stop after the first n values").
+		Arg("n").
+		Returns("iter").
+		Impl(func(r *Range, ctx context.Context, args ...Object) (Object, error) {
+			n, err := AsInt(args[0])
+			if err != nil {
+				return nil, err
+			}
+			return IterOf(r).Take(n)
+		})
+
+	rangeAttrs.Define("drop").
+		Doc("Lazily skip the first n values").
+		Arg("n").
+		Returns("iter").
+		Impl(func(r *Range, ctx context.Context, args ...Object) (Object, error) {
+			n, err := AsInt(args[0])
+			if err != nil {
+				return nil, err
+			}
+			return IterOf(r).Drop(n)
+		})
+
+	rangeAttrs.Define("iter").
+		Doc("Return a lazy iterator over the values").
+		Returns("iter").
+		Impl(func(r *Range, ctx context.Context, args ...Object) (Object, error) {
+			return IterOf(r), nil
+		})
+
+	rangeAttrs.Define("collect").
+		Doc("Return the values as a list").
+		Returns("list").
+		Impl(func(r *Range, ctx context.Context, args ...Object) (Object, error) {
+			return IterOf(r).Collect(ctx)
+		})
 }
 
 // Range represents a lazy sequence of integers, similar to Python's range.
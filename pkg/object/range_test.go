@@ -160,6 +160,34 @@ func TestRangeEach(t *testing.T) {
 	assert.Equal(t, collected, []int64{0, 1, 2})
 }
 
+func TestRangeLazyMethods(t *testing.T) {
+	ctx := context.Background()
+	r := NewRange(0, 1<<62, 1)
+
+	call := func(obj Object, name string, args ...Object) Object {
+		method, ok := obj.GetAttr(name)
+		assert.True(t, ok, name)
+		result, err := method.(Callable).Call(ctx, args...)
+		assert.Nil(t, err, name)
+		return result
+	}
+
+	// take and drop return iterators, so huge ranges are not materialized
+	taken := call(call(r, "drop", NewInt(5)), "take", NewInt(3))
+	assert.Equal(t, taken.Type(), ITER)
+	assert.Equal(t, call(taken, "collect").Inspect(), "[5, 6, 7]")
+
+	it := call(r, "iter")
+	assert.Equal(t, it.Type(), ITER)
+	assert.Equal(t, call(call(it, "take", NewInt(2)), "collect").Inspect(), "[0, 1]")
+
+	assert.Equal(t, call(NewRange(3, 0, -1), "collect").Inspect(), "[3, 2, 1]")
+
+	take, _ := r.GetAttr("take")
+	_, err := take.(Callable).Call(ctx, NewInt(-1))
+	assert.NotNil(t, err)
+}
+
 func TestRangeMethodErrors(t *testing.T) {
 	ctx := context.Background()
 	r := NewRange(0, 5, 1)
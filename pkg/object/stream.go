@@ -0,0 +1,385 @@
+package object
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/op"
+)
+
+var streamMethods = NewMethodRegistry[*Stream]("stream")
+
+func init() {
+	streamMethods.Define("read").
+		Doc("Read up to n bytes, or all remaining bytes; null at end of stream").
+		OptionalArg("n").
+		Returns("bytes").
+		Impl(func(s *Stream, ctx context.Context, args ...Object) (Object, error) {
+			if len(args) == 0 {
+				return s.ReadAll()
+			}
+			n, err := AsInt(args[0])
+			if err != nil {
+				return nil, err
+			}
+			return s.ReadN(n)
+		})
+
+	streamMethods.Define("read_line").
+		Doc("Read the next line without its line ending; null at end of stream").
+		Returns("string").
+		Impl(func(s *Stream, ctx context.Context, args ...Object) (Object, error) {
+			return s.ReadLine()
+		})
+
+	streamMethods.Define("each").
+		Doc("Call function for each remaining line").
+		Arg("fn").
+		Returns("null").
+		Impl(func(s *Stream, ctx context.Context, args ...Object) (Object, error) {
+			return s.Each(ctx, args[0])
+		})
+
+	streamMethods.Define("write").
+		Doc("Write a string or bytes, returning the number of bytes written").
+		Arg("data").
+		Returns("int").
+		Impl(func(s *Stream, ctx context.Context, args ...Object) (Object, error) {
+			data, err := AsBytes(args[0])
+			if err != nil {
+				return nil, err
+			}
+			n, err := s.Write(data)
+			if err != nil {
+				return nil, err
+			}
+			return NewInt(int64(n)), nil
+		})
+
+	streamMethods.Define("read_lines").
+		Doc("Return a lazy iterator over the remaining lines").
+		Returns("iter").
+		Impl(func(s *Stream, ctx context.Context, args ...Object) (Object, error) {
+			return s.Lines(), nil
+		})
+
+	streamMethods.Define("write_lines").
+		Doc("Write each string in a list followed by a newline, returning the number of bytes written").
+		Arg("lines").
+		Returns("int").
+		Impl(func(s *Stream, ctx context.Context, args ...Object) (Object, error) {
+			return s.WriteLines(ctx, args[0])
+		})
+
+	streamMethods.Define("seek").
+		Doc("Move to an offset from the start, or from the current position (whence 1) or end (whence 2); returns the new position").
+		Arg("offset").
+		OptionalArg("whence").
+		Returns("int").
+		Impl(func(s *Stream, ctx context.Context, args ...Object) (Object, error) {
+			offset, err := AsInt(args[0])
+			if err != nil {
+				return nil, err
+			}
+			whence := int64(io.SeekStart)
+			if len(args) > 1 {
+				if whence, err = AsInt(args[1]); err != nil {
+					return nil, err
+				}
+			}
+			pos, err := s.Seek(offset, int(whence))
+			if err != nil {
+				return nil, err
+			}
+			return NewInt(pos), nil
+		})
+
+	streamMethods.Define("tell").
+		Doc("Return the current position").
+		Returns("int").
+		Impl(func(s *Stream, ctx context.Context, args ...Object) (Object, error) {
+			pos, err := s.Seek(0, io.SeekCurrent)
+			if err != nil {
+				return nil, err
+			}
+			return NewInt(pos), nil
+		})
+
+	streamMethods.Define("use").
+		Doc("Call function with the stream and close the stream afterwards, even if the function fails").
+		Arg("fn").
+		Returns("any").
+		Impl(func(s *Stream, ctx context.Context, args ...Object) (Object, error) {
+			return s.Use(ctx, args[0])
+		})
+
+	streamMethods.Define("close").
+		Doc("Close the stream if it can be closed").
+		Returns("null").
+		Impl(func(s *Stream, ctx context.Context, args ...Object) (Object, error) {
+			if err := s.Close(); err != nil {
+				return nil, err
+			}
+			return Nil, nil
+		})
+}
+
+// Stream exposes a Go io.Reader to scripts. Scripts read it incrementally
+// with read and read_line, or line by line with each and spread, so large
+// inputs such as files and HTTP bodies need not be loaded at once. If the
+// underlying value is also an io.Writer or io.Closer, write and close pass
+// through to it.
+//
+// Stream is itself an io.Reader, so a stream passed back to a Go function
+// that takes an io.Reader continues from where the script left off.
+type Stream struct {
+	src io.Reader
+	buf *bufio.Reader
+}
+
+// NewStream wraps a Go reader.
+func NewStream(r io.Reader) *Stream {
+	return &Stream{src: r, buf: bufio.NewReader(r)}
+}
+
+func (s *Stream) Type() Type {
+	return STREAM
+}
+
+func (s *Stream) Inspect() string {
+	return fmt.Sprintf("stream(%T)", s.src)
+}
+
+func (s *Stream) String() string {
+	return s.Inspect()
+}
+
+func (s *Stream) Interface() any {
+	return s
+}
+
+func (s *Stream) Equals(other Object) bool {
+	return s == other
+}
+
+func (s *Stream) Attrs() []AttrSpec {
+	return streamMethods.Specs()
+}
+
+func (s *Stream) GetAttr(name string) (Object, bool) {
+	return streamMethods.GetAttr(s, name)
+}
+
+func (s *Stream) SetAttr(name string, value Object) error {
+	return TypeErrorf("stream has no attribute %q", name)
+}
+
+func (s *Stream) IsTruthy() bool {
+	return true
+}
+
+func (s *Stream) RunOperation(opType op.BinaryOpType, right Object) (Object, error) {
+	return nil, newTypeErrorf("unsupported operation for stream: %v", opType)
+}
+
+// Read implements io.Reader.
+func (s *Stream) Read(p []byte) (int, error) {
+	return s.buf.Read(p)
+}
+
+// Write implements io.Writer if the underlying value is a writer.
+func (s *Stream) Write(p []byte) (int, error) {
+	w, ok := s.src.(io.Writer)
+	if !ok {
+		return 0, newTypeErrorf("stream is not writable")
+	}
+	// Data read ahead into the buffer has not been seen by the script, so
+	// write to a seekable value at the position the script has read up to
+	if _, ok := s.src.(io.Seeker); ok && s.buf.Buffered() > 0 {
+		if _, err := s.Seek(0, io.SeekCurrent); err != nil {
+			return 0, err
+		}
+	}
+	return w.Write(p)
+}
+
+// Seek implements io.Seeker if the underlying value is a seeker. Offsets
+// account for data read ahead into the stream's buffer.
+func (s *Stream) Seek(offset int64, whence int) (int64, error) {
+	seeker, ok := s.src.(io.Seeker)
+	if !ok {
+		return 0, newTypeErrorf("stream is not seekable")
+	}
+	if whence < io.SeekStart || whence > io.SeekEnd {
+		return 0, newValueErrorf("stream.seek() whence must be 0, 1, or 2 (got %d)", whence)
+	}
+	if whence == io.SeekCurrent {
+		offset -= int64(s.buf.Buffered())
+	}
+	pos, err := seeker.Seek(offset, whence)
+	if err != nil {
+		return 0, err
+	}
+	s.buf.Reset(s.src)
+	return pos, nil
+}
+
+// WriteLines writes each string or bytes value in the enumerable lines,
+// each followed by a newline, and returns the number of bytes written.
+func (s *Stream) WriteLines(ctx context.Context, lines Object) (Object, error) {
+	enumerable, ok := lines.(Enumerable)
+	if !ok {
+		return nil, newTypeErrorf("stream.write_lines() expected a list (%s given)", lines.Type())
+	}
+	var total int64
+	var writeErr error
+	err := Iterate(ctx, enumerable, func(key, value Object) bool {
+		data, err := AsBytes(value)
+		if err != nil {
+			writeErr = err
+			return false
+		}
+		line := make([]byte, len(data)+1)
+		copy(line, data)
+		line[len(data)] = '\n'
+		n, err := s.Write(line)
+		total += int64(n)
+		writeErr = err
+		return err == nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if writeErr != nil {
+		return nil, writeErr
+	}
+	return NewInt(total), nil
+}
+
+// Use calls fn with the stream and closes the stream when fn returns,
+// whether or not it fails. It returns the result of fn.
+func (s *Stream) Use(ctx context.Context, fn Object) (result Object, err error) {
+	callable, ok := fn.(Callable)
+	if !ok {
+		return nil, newTypeErrorf("stream.use() expected a function (%s given)", fn.Type())
+	}
+	defer func() {
+		if closeErr := s.Close(); closeErr != nil && err == nil {
+			result, err = nil, closeErr
+		}
+	}()
+	return callable.Call(ctx, s)
+}
+
+// Lines returns an iterator over the remaining lines. Lines are read as the
+// iterator is consumed, and a read error ends the iteration with that error.
+func (s *Stream) Lines() *Iter {
+	return NewIterFunc("stream lines", func(ctx context.Context, fn func(key, value Object) bool) error {
+		for i := int64(0); ; i++ {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			line, err := s.ReadLine()
+			if err != nil {
+				return err
+			}
+			if line == Nil || !fn(NewInt(i), line) {
+				return nil
+			}
+		}
+	})
+}
+
+// Close closes the underlying value if it is an io.Closer.
+func (s *Stream) Close() error {
+	if c, ok := s.src.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// ReadAll returns the remaining bytes, or Nil if the stream is exhausted.
+func (s *Stream) ReadAll() (Object, error) {
+	data, err := io.ReadAll(s.buf)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return Nil, nil
+	}
+	return NewBytes(data), nil
+}
+
+// ReadN returns up to n bytes, or Nil if the stream is exhausted.
+func (s *Stream) ReadN(n int64) (Object, error) {
+	if n <= 0 {
+		return nil, newValueErrorf("stream.read() expected a positive size (got %d)", n)
+	}
+	data := make([]byte, n)
+	count, err := io.ReadFull(s.buf, data)
+	if count == 0 && err != nil {
+		if err == io.EOF {
+			return Nil, nil
+		}
+		return nil, err
+	}
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, err
+	}
+	return NewBytes(data[:count]), nil
+}
+
+// ReadLine returns the next line without its line ending, or Nil if the
+// stream is exhausted.
+func (s *Stream) ReadLine() (Object, error) {
+	line, err := s.buf.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	if line == "" && err == io.EOF {
+		return Nil, nil
+	}
+	line = strings.TrimSuffix(line, "\n")
+	line = strings.TrimSuffix(line, "\r")
+	return NewString(line), nil
+}
+
+func (s *Stream) Each(ctx context.Context, fn Object) (Object, error) {
+	callable, ok := fn.(Callable)
+	if !ok {
+		return nil, newTypeErrorf("stream.each() expected a function (%s given)", fn.Type())
+	}
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		line, err := s.ReadLine()
+		if err != nil {
+			return nil, err
+		}
+		if line == Nil {
+			return Nil, nil
+		}
+		if _, err := callable.Call(ctx, line); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Enumerate implements Enumerable, yielding the remaining lines. Enumeration
+// stops at the first read error.
+func (s *Stream) Enumerate(ctx context.Context, fn func(key, value Object) bool) {
+	for i := int64(0); ctx.Err() == nil; i++ {
+		line, err := s.ReadLine()
+		if err != nil || line == Nil {
+			return
+		}
+		if !fn(NewInt(i), line) {
+			return
+		}
+	}
+}
@@ -0,0 +1,262 @@
+package object
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+func TestStreamReadLine(t *testing.T) {
+	s := NewStream(strings.NewReader("one\r\ntwo\nthree"))
+	for _, expected := range []string{"one", "two", "three"} {
+		line, err := s.ReadLine()
+		assert.Nil(t, err)
+		assert.Equal(t, line, NewString(expected))
+	}
+	line, err := s.ReadLine()
+	assert.Nil(t, err)
+	assert.Equal(t, line, Nil)
+}
+
+func TestStreamRead(t *testing.T) {
+	s := NewStream(strings.NewReader("abcdefg"))
+	chunk, err := s.ReadN(3)
+	assert.Nil(t, err)
+	assert.Equal(t, chunk, NewBytes([]byte("abc")))
+
+	rest, err := s.ReadAll()
+	assert.Nil(t, err)
+	assert.Equal(t, rest, NewBytes([]byte("defg")))
+
+	chunk, err = s.ReadN(3)
+	assert.Nil(t, err)
+	assert.Equal(t, chunk, Nil)
+	rest, err = s.ReadAll()
+	assert.Nil(t, err)
+	assert.Equal(t, rest, Nil)
+
+	_, err = s.ReadN(0)
+	assert.NotNil(t, err)
+}
+
+func TestStreamEnumerate(t *testing.T) {
+	s := NewStream(strings.NewReader("a\nb\nc\n"))
+	var lines []string
+	s.Enumerate(context.Background(), func(key, value Object) bool {
+		lines = append(lines, value.(*String).Value())
+		return key.(*Int).Value() < 1
+	})
+	assert.Equal(t, lines, []string{"a", "b"})
+
+	// Enumeration continues from where it stopped
+	line, err := s.ReadLine()
+	assert.Nil(t, err)
+	assert.Equal(t, line, NewString("c"))
+}
+
+func TestStreamEach(t *testing.T) {
+	s := NewStream(strings.NewReader("x\ny\n"))
+	var lines []Object
+	fn := NewBuiltin("collect", func(ctx context.Context, args ...Object) (Object, error) {
+		lines = append(lines, args[0])
+		return Nil, nil
+	})
+	result, err := s.Each(context.Background(), fn)
+	assert.Nil(t, err)
+	assert.Equal(t, result, Nil)
+	assert.Equal(t, lines, []Object{NewString("x"), NewString("y")})
+
+	_, err = NewStream(strings.NewReader("x")).Each(context.Background(), NewInt(1))
+	assert.NotNil(t, err)
+
+	failing := NewBuiltin("fail", func(ctx context.Context, args ...Object) (Object, error) {
+		return nil, errors.New("boom")
+	})
+	_, err = NewStream(strings.NewReader("x\ny")).Each(context.Background(), failing)
+	assert.Error(t, err, "boom")
+}
+
+type closeRecorder struct {
+	io.Reader
+	closed bool
+}
+
+func (c *closeRecorder) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestStreamWriteAndClose(t *testing.T) {
+	var buf bytes.Buffer
+	s := NewStream(&buf)
+	n, err := s.Write([]byte("hello"))
+	assert.Nil(t, err)
+	assert.Equal(t, n, 5)
+	assert.Equal(t, buf.String(), "hello")
+	assert.Nil(t, s.Close())
+
+	rc := &closeRecorder{Reader: strings.NewReader("")}
+	s = NewStream(rc)
+	_, err = s.Write([]byte("x"))
+	assert.Error(t, err, "type error: stream is not writable")
+	assert.Nil(t, s.Close())
+	assert.True(t, rc.closed)
+}
+
+func TestStreamReadLines(t *testing.T) {
+	ctx := context.Background()
+	s := NewStream(strings.NewReader("a\nb\nc\n"))
+	var first Object
+	assert.Nil(t, Iterate(ctx, s.Lines(), func(key, value Object) bool {
+		first = value
+		return false
+	}))
+	assert.Equal(t, first, NewString("a"))
+
+	// The iterator reads lazily, so the stream continues after it
+	line, err := s.ReadLine()
+	assert.Nil(t, err)
+	assert.Equal(t, line, NewString("b"))
+
+	var rest []string
+	assert.Nil(t, Iterate(ctx, s.Lines(), func(key, value Object) bool {
+		rest = append(rest, value.(*String).Value())
+		return true
+	}))
+	assert.Equal(t, rest, []string{"c"})
+}
+
+func TestStreamSeekAndTell(t *testing.T) {
+	f, err := os.CreateTemp(t.TempDir(), "stream")
+	assert.Nil(t, err)
+	defer f.Close()
+	_, err = f.WriteString("one\ntwo\nthree\n")
+	assert.Nil(t, err)
+
+	s := NewStream(f)
+	pos, err := s.Seek(0, io.SeekStart)
+	assert.Nil(t, err)
+	assert.Equal(t, pos, int64(0))
+
+	line, err := s.ReadLine()
+	assert.Nil(t, err)
+	assert.Equal(t, line, NewString("one"))
+	// The rest of the file was read ahead, but tell reports the position
+	// after the line
+	pos, err = s.Seek(0, io.SeekCurrent)
+	assert.Nil(t, err)
+	assert.Equal(t, pos, int64(4))
+
+	// Writes go to the position the script has read up to
+	_, err = s.Write([]byte("TWO"))
+	assert.Nil(t, err)
+	_, err = s.Seek(0, io.SeekStart)
+	assert.Nil(t, err)
+	all, err := s.ReadAll()
+	assert.Nil(t, err)
+	assert.Equal(t, string(all.(*Bytes).Value()), "one\nTWO\nthree\n")
+
+	pos, err = s.Seek(-6, io.SeekEnd)
+	assert.Nil(t, err)
+	assert.Equal(t, pos, int64(8))
+	line, err = s.ReadLine()
+	assert.Nil(t, err)
+	assert.Equal(t, line, NewString("three"))
+
+	_, err = s.Seek(0, 3)
+	assert.NotNil(t, err)
+	_, err = NewStream(&bytes.Buffer{}).Seek(0, io.SeekStart)
+	assert.Error(t, err, "type error: stream is not seekable")
+}
+
+func TestStreamWriteLines(t *testing.T) {
+	ctx := context.Background()
+	var buf bytes.Buffer
+	s := NewStream(&buf)
+	data := NewBytes(make([]byte, 1, 8))
+	n, err := s.WriteLines(ctx, NewList([]Object{NewString("a"), data}))
+	assert.Nil(t, err)
+	assert.Equal(t, n, NewInt(4))
+	assert.Equal(t, buf.String(), "a\n\x00\n")
+	// The bytes value is not modified
+	assert.Equal(t, len(data.Value()), 1)
+
+	_, err = s.WriteLines(ctx, NewList([]Object{NewInt(1)}))
+	assert.NotNil(t, err)
+	_, err = s.WriteLines(ctx, NewInt(1))
+	assert.NotNil(t, err)
+}
+
+func TestStreamUse(t *testing.T) {
+	ctx := context.Background()
+	rc := &closeRecorder{Reader: strings.NewReader("data")}
+	s := NewStream(rc)
+	result, err := s.Use(ctx, NewBuiltin("read", func(ctx context.Context, args ...Object) (Object, error) {
+		return args[0].(*Stream).ReadAll()
+	}))
+	assert.Nil(t, err)
+	assert.Equal(t, result, NewBytes([]byte("data")))
+	assert.True(t, rc.closed)
+
+	// The stream is closed when the function fails
+	rc = &closeRecorder{Reader: strings.NewReader("")}
+	_, err = NewStream(rc).Use(ctx, NewBuiltin("fail", func(ctx context.Context, args ...Object) (Object, error) {
+		return nil, errors.New("boom")
+	}))
+	assert.Error(t, err, "boom")
+	assert.True(t, rc.closed)
+
+	_, err = s.Use(ctx, NewInt(1))
+	assert.NotNil(t, err)
+}
+
+func TestTypeRegistryReaders(t *testing.T) {
+	registry := DefaultRegistry()
+
+	obj, err := registry.FromGo(strings.NewReader("data"))
+	assert.Nil(t, err)
+	s, ok := obj.(*Stream)
+	assert.True(t, ok)
+	assert.Equal(t, s.Type(), STREAM)
+
+	obj, err = registry.FromGo((*bytes.Buffer)(nil))
+	assert.Nil(t, err)
+	assert.Equal(t, obj, Nil)
+
+	readerType := reflect.TypeOf((*io.Reader)(nil)).Elem()
+	for _, input := range []Object{NewString("data"), NewBytes([]byte("data")), NewStream(strings.NewReader("data"))} {
+		r, err := registry.ToGo(input, readerType)
+		assert.Nil(t, err)
+		data, err := io.ReadAll(r.(io.Reader))
+		assert.Nil(t, err)
+		assert.Equal(t, string(data), "data")
+	}
+	_, err = registry.ToGo(NewInt(1), readerType)
+	assert.NotNil(t, err)
+
+	// A partly read stream continues where the script left off
+	s = NewStream(strings.NewReader("header\nbody"))
+	_, err = s.ReadLine()
+	assert.Nil(t, err)
+	r, err := registry.ToGo(s, readerType)
+	assert.Nil(t, err)
+	data, err := io.ReadAll(r.(io.Reader))
+	assert.Nil(t, err)
+	assert.Equal(t, string(data), "body")
+
+	writerType := reflect.TypeOf((*io.Writer)(nil)).Elem()
+	var buf bytes.Buffer
+	w, err := registry.ToGo(NewStream(&buf), writerType)
+	assert.Nil(t, err)
+	w.(io.Writer).Write([]byte("ok"))
+	assert.Equal(t, buf.String(), "ok")
+	_, err = registry.ToGo(NewString("x"), writerType)
+	assert.NotNil(t, err)
+}
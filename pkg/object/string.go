@@ -12,6 +12,13 @@ import (
 var stringMethods = NewMethodRegistry[*String]("string")
 
 func init() {
+	stringMethods.Define("camel_case").
+		Doc("Convert to camelCase").
+		Returns("string").
+		Impl(func(s *String, ctx context.Context, args ...Object) (Object, error) {
+			return s.CamelCase(), nil
+		})
+
 	stringMethods.Define("compare").
 		Doc("Compare to another string (-1, 0, or 1)").
 		Arg("other").
@@ -40,6 +47,13 @@ func init() {
 			return s.Count(args[0])
 		})
 
+	stringMethods.Define("dedent").
+		Doc("Remove common leading whitespace from lines").
+		Returns("string").
+		Impl(func(s *String, ctx context.Context, args ...Object) (Object, error) {
+			return s.Dedent(), nil
+		})
+
 	stringMethods.Define("fields").
 		Doc("Split on whitespace").
 		Returns("list").
@@ -63,6 +77,14 @@ func init() {
 			return s.HasSuffix(args[0])
 		})
 
+	stringMethods.Define("indent").
+		Doc("Add prefix to each non-blank line").
+		Arg("prefix").
+		Returns("string").
+		Impl(func(s *String, ctx context.Context, args ...Object) (Object, error) {
+			return s.Indent(args[0])
+		})
+
 	stringMethods.Define("index").
 		Doc("Find first index of substring (-1 if not found)").
 		Arg("substr").
@@ -79,6 +101,13 @@ func init() {
 			return s.Join(args[0])
 		})
 
+	stringMethods.Define("kebab_case").
+		Doc("Convert to kebab-case").
+		Returns("string").
+		Impl(func(s *String, ctx context.Context, args ...Object) (Object, error) {
+			return s.KebabCase(), nil
+		})
+
 	stringMethods.Define("last_index").
 		Doc("Find last index of substring (-1 if not found)").
 		Arg("substr").
@@ -87,6 +116,14 @@ func init() {
 			return s.LastIndex(args[0])
 		})
 
+	stringMethods.Define("levenshtein").
+		Doc("Edit distance to another string").
+		Arg("other").
+		Returns("int").
+		Impl(func(s *String, ctx context.Context, args ...Object) (Object, error) {
+			return s.Levenshtein(args[0])
+		})
+
 	stringMethods.Define("repeat").
 		Doc("Repeat string n times").
 		Arg("count").
@@ -103,6 +140,28 @@ func init() {
 			return s.ReplaceAll(args[0], args[1])
 		})
 
+	stringMethods.Define("similarity").
+		Doc("Similarity to another string from 0 to 1").
+		Arg("other").
+		Returns("float").
+		Impl(func(s *String, ctx context.Context, args ...Object) (Object, error) {
+			return s.Similarity(args[0])
+		})
+
+	stringMethods.Define("slugify").
+		Doc("Convert to a lowercase URL slug").
+		Returns("string").
+		Impl(func(s *String, ctx context.Context, args ...Object) (Object, error) {
+			return s.Slugify(), nil
+		})
+
+	stringMethods.Define("snake_case").
+		Doc("Convert to snake_case").
+		Returns("string").
+		Impl(func(s *String, ctx context.Context, args ...Object) (Object, error) {
+			return s.SnakeCase(), nil
+		})
+
 	stringMethods.Define("split").
 		Doc("Split by separator").
 		Arg("sep").
@@ -155,6 +214,14 @@ func init() {
 		Impl(func(s *String, ctx context.Context, args ...Object) (Object, error) {
 			return s.TrimSuffix(args[0])
 		})
+
+	stringMethods.Define("wrap").
+		Doc("Wrap lines to a maximum width").
+		Arg("width").
+		Returns("string").
+		Impl(func(s *String, ctx context.Context, args ...Object) (Object, error) {
+			return s.Wrap(args[0])
+		})
 }
 
 type String struct {
@@ -84,3 +84,84 @@ func TestStringGetItem(t *testing.T) {
 		}
 	}
 }
+
+func TestStringCaseConversion(t *testing.T) {
+	tests := []struct {
+		s     string
+		snake string
+		kebab string
+		camel string
+	}{
+		{"hello world", "hello_world", "hello-world", "helloWorld"},
+		{"parseHTTPResponse", "parse_http_response", "parse-http-response", "parseHttpResponse"},
+		{"UserID", "user_id", "user-id", "userId"},
+		{"already_snake_case", "already_snake_case", "already-snake-case", "alreadySnakeCase"},
+		{"--Mixed  Input--v2", "mixed_input_v2", "mixed-input-v2", "mixedInputV2"},
+		{"", "", "", ""},
+	}
+	for _, tc := range tests {
+		s := NewString(tc.s)
+		assert.Equal(t, s.SnakeCase(), NewString(tc.snake), "s: %q", tc.s)
+		assert.Equal(t, s.KebabCase(), NewString(tc.kebab), "s: %q", tc.s)
+		assert.Equal(t, s.CamelCase(), NewString(tc.camel), "s: %q", tc.s)
+	}
+}
+
+func TestStringSlugify(t *testing.T) {
+	assert.Equal(t, NewString("Hello, World!").Slugify(), NewString("hello-world"))
+	assert.Equal(t, NewString("  Release v2.1 -- Notes ").Slugify(), NewString("release-v2-1-notes"))
+	assert.Equal(t, NewString("Café Olé").Slugify(), NewString("café-olé"))
+}
+
+func TestStringLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		distance int64
+	}{
+		{"", "", 0},
+		{"", "abc", 3},
+		{"kitten", "sitting", 3},
+		{"flaw", "lawn", 2},
+		{"héllo", "hello", 1},
+	}
+	for _, tc := range tests {
+		result, err := NewString(tc.a).Levenshtein(NewString(tc.b))
+		assert.Nil(t, err)
+		assert.Equal(t, result, NewInt(tc.distance), "a: %q, b: %q", tc.a, tc.b)
+	}
+	_, err := NewString("a").Levenshtein(NewInt(1))
+	assert.Error(t, err)
+
+	result, err := NewString("abcd").Similarity(NewString("abed"))
+	assert.Nil(t, err)
+	assert.Equal(t, result, NewFloat(0.75))
+	result, err = NewString("").Similarity(NewString(""))
+	assert.Nil(t, err)
+	assert.Equal(t, result, NewFloat(1))
+}
+
+func TestStringWrap(t *testing.T) {
+	s := NewString("the quick brown fox jumps over the lazy dog")
+	result, err := s.Wrap(NewInt(10))
+	assert.Nil(t, err)
+	assert.Equal(t, result, NewString("the quick\nbrown fox\njumps over\nthe lazy\ndog"))
+
+	// Existing line breaks are kept and long words are not split
+	result, err = NewString("a b\n\nextraordinary c").Wrap(NewInt(5))
+	assert.Nil(t, err)
+	assert.Equal(t, result, NewString("a b\n\nextraordinary\nc"))
+
+	_, err = s.Wrap(NewInt(0))
+	assert.Error(t, err)
+}
+
+func TestStringIndentDedent(t *testing.T) {
+	result, err := NewString("a\n\n  b\n").Indent(NewString("> "))
+	assert.Nil(t, err)
+	assert.Equal(t, result, NewString("> a\n\n>   b\n"))
+
+	s := NewString("\n    def f():\n        return 1\n  \n    f()\n")
+	assert.Equal(t, s.Dedent(), NewString("\ndef f():\n    return 1\n\nf()\n"))
+	assert.Equal(t, NewString("\tx\n  y").Dedent(), NewString("\tx\n  y"))
+	assert.Equal(t, NewString("no indent").Dedent(), NewString("no indent"))
+}
@@ -0,0 +1,214 @@
+package object
+
+import (
+	"strings"
+	"unicode"
+)
+
+// splitWords breaks s into words for case conversion. Words are separated
+// by any rune that is not a letter or digit, and by changes in case:
+// "parseHTTPResponse" yields "parse", "HTTP", and "Response".
+func splitWords(s string) []string {
+	var words []string
+	var word []rune
+	runes := []rune(s)
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if len(word) > 0 {
+				words = append(words, string(word))
+				word = nil
+			}
+			continue
+		}
+		if len(word) > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if !unicode.IsUpper(prev) || nextLower {
+				words = append(words, string(word))
+				word = nil
+			}
+		}
+		word = append(word, r)
+	}
+	if len(word) > 0 {
+		words = append(words, string(word))
+	}
+	return words
+}
+
+// joinWords lowercases the words of s and joins them with sep.
+func joinWords(s, sep string) string {
+	words := splitWords(s)
+	for i, word := range words {
+		words[i] = strings.ToLower(word)
+	}
+	return strings.Join(words, sep)
+}
+
+func (s *String) SnakeCase() Object {
+	return NewString(joinWords(s.value, "_"))
+}
+
+func (s *String) KebabCase() Object {
+	return NewString(joinWords(s.value, "-"))
+}
+
+func (s *String) CamelCase() Object {
+	var b strings.Builder
+	for i, word := range splitWords(s.value) {
+		word = strings.ToLower(word)
+		if i > 0 {
+			runes := []rune(word)
+			runes[0] = unicode.ToUpper(runes[0])
+			word = string(runes)
+		}
+		b.WriteString(word)
+	}
+	return NewString(b.String())
+}
+
+// Slugify lowercases the string and replaces each run of characters other
+// than letters and digits with a single hyphen.
+func (s *String) Slugify() Object {
+	var b strings.Builder
+	pending := false
+	for _, r := range s.value {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			pending = b.Len() > 0
+			continue
+		}
+		if pending {
+			b.WriteByte('-')
+			pending = false
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return NewString(b.String())
+}
+
+// levenshtein returns the number of single-rune insertions, deletions, and
+// substitutions needed to turn a into b.
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+func (s *String) Levenshtein(obj Object) (Object, error) {
+	other, err := AsString(obj)
+	if err != nil {
+		return nil, err
+	}
+	return NewInt(int64(levenshtein([]rune(s.value), []rune(other)))), nil
+}
+
+// Similarity returns a score from 0 to 1 based on the Levenshtein distance,
+// where 1 means the strings are equal.
+func (s *String) Similarity(obj Object) (Object, error) {
+	other, err := AsString(obj)
+	if err != nil {
+		return nil, err
+	}
+	a, b := []rune(s.value), []rune(other)
+	longest := max(len(a), len(b))
+	if longest == 0 {
+		return NewFloat(1), nil
+	}
+	return NewFloat(1 - float64(levenshtein(a, b))/float64(longest)), nil
+}
+
+// Wrap breaks each line of the string so that it is at most width runes
+// long, splitting at whitespace. Words longer than width are put on a line
+// of their own. Existing line breaks are kept.
+func (s *String) Wrap(obj Object) (Object, error) {
+	width, err := AsInt(obj)
+	if err != nil {
+		return nil, err
+	}
+	if width < 1 {
+		return nil, newValueErrorf("wrap width must be positive (got %d)", width)
+	}
+	lines := strings.Split(s.value, "\n")
+	var wrapped []string
+	for _, line := range lines {
+		words := strings.Fields(line)
+		if len(words) == 0 {
+			wrapped = append(wrapped, "")
+			continue
+		}
+		current := words[0]
+		currentLen := len([]rune(current))
+		for _, word := range words[1:] {
+			wordLen := len([]rune(word))
+			if int64(currentLen+1+wordLen) > width {
+				wrapped = append(wrapped, current)
+				current, currentLen = word, wordLen
+				continue
+			}
+			current += " " + word
+			currentLen += 1 + wordLen
+		}
+		wrapped = append(wrapped, current)
+	}
+	return NewString(strings.Join(wrapped, "\n")), nil
+}
+
+// Indent adds prefix to the start of each line that is not blank.
+func (s *String) Indent(obj Object) (Object, error) {
+	prefix, err := AsString(obj)
+	if err != nil {
+		return nil, err
+	}
+	lines := strings.SplitAfter(s.value, "\n")
+	var b strings.Builder
+	for _, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			b.WriteString(prefix)
+		}
+		b.WriteString(line)
+	}
+	return NewString(b.String()), nil
+}
+
+// Dedent removes the leading whitespace that all non-blank lines have in
+// common. Blank lines are emptied.
+func (s *String) Dedent() Object {
+	lines := strings.Split(s.value, "\n")
+	margin := ""
+	found := false
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		if !found {
+			margin, found = indent, true
+			continue
+		}
+		for !strings.HasPrefix(indent, margin) {
+			margin = margin[:len(margin)-1]
+		}
+	}
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			lines[i] = ""
+		} else {
+			lines[i] = strings.TrimPrefix(line, margin)
+		}
+	}
+	return NewString(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,159 @@
+package object
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/op"
+)
+
+var taskMethods = NewMethodRegistry[*Task]("task")
+
+func init() {
+	taskMethods.Define("await").
+		Doc("Wait for the task to finish and return its result, raising its error if it failed").
+		Returns("object").
+		Impl(func(t *Task, ctx context.Context, args ...Object) (Object, error) {
+			return t.Await(ctx)
+		})
+
+	taskMethods.Define("done").
+		Doc("Report whether the task has finished").
+		Returns("bool").
+		Impl(func(t *Task, ctx context.Context, args ...Object) (Object, error) {
+			return NewBool(t.Done()), nil
+		})
+
+	taskMethods.Define("cancel").
+		Doc("Cancel the task's context; awaiting it then returns its error").
+		Returns("null").
+		Impl(func(t *Task, ctx context.Context, args ...Object) (Object, error) {
+			t.Cancel()
+			return Nil, nil
+		})
+}
+
+// Task is the result of an operation running on its own goroutine, such as
+// an HTTP request or a subprocess. Go builtins return a task from NewTask
+// to let a script start several slow operations before waiting for any of
+// them. The script calls await to wait for the result, which blocks only
+// the calling VM; the operations themselves run concurrently.
+type Task struct {
+	done   chan struct{}
+	cancel context.CancelFunc
+	result Object
+	err    error
+}
+
+// NewTask calls fn on a new goroutine and returns a task that holds its
+// result. The context passed to fn is cancelled when ctx is cancelled or
+// when the task is cancelled. A panic in fn fails the task rather than the
+// program.
+//
+// If ctx belongs to a running program, the task is also cancelled when the
+// run ends, and the run does not return until fn has returned, so fn must
+// return promptly once its context is cancelled.
+func NewTask(ctx context.Context, fn func(ctx context.Context) (Object, error)) *Task {
+	ctx, cancel := context.WithCancel(ctx)
+	t := &Task{done: make(chan struct{}), cancel: cancel}
+	if track, ok := GetTaskFunc(ctx); ok {
+		track(t)
+	}
+	go func() {
+		defer close(t.done)
+		defer cancel()
+		defer func() {
+			if r := recover(); r != nil {
+				t.result, t.err = nil, NewPanicError("task", r, debug.Stack())
+			}
+		}()
+		t.result, t.err = fn(ctx)
+		if t.err == nil && t.result == nil {
+			t.result = Nil
+		}
+	}()
+	return t
+}
+
+// Await waits for the task to finish and returns its result. It returns
+// ctx.Err() if ctx is cancelled first, leaving the task running.
+func (t *Task) Await(ctx context.Context) (Object, error) {
+	select {
+	case <-t.done:
+		return t.result, t.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Done reports whether the task has finished.
+func (t *Task) Done() bool {
+	select {
+	case <-t.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// Wait returns a channel that is closed when the task finishes.
+func (t *Task) Wait() <-chan struct{} {
+	return t.done
+}
+
+// Cancel cancels the context passed to the task's function. It does not
+// wait for the function to return.
+func (t *Task) Cancel() {
+	t.cancel()
+}
+
+func (t *Task) status() string {
+	if !t.Done() {
+		return "pending"
+	}
+	if t.err != nil {
+		return "failed"
+	}
+	return "done"
+}
+
+func (t *Task) Type() Type {
+	return TASK
+}
+
+func (t *Task) Inspect() string {
+	return fmt.Sprintf("task(%s)", t.status())
+}
+
+func (t *Task) String() string {
+	return t.Inspect()
+}
+
+func (t *Task) Interface() any {
+	return t
+}
+
+func (t *Task) Equals(other Object) bool {
+	return t == other
+}
+
+func (t *Task) Attrs() []AttrSpec {
+	return taskMethods.Specs()
+}
+
+func (t *Task) GetAttr(name string) (Object, bool) {
+	return taskMethods.GetAttr(t, name)
+}
+
+func (t *Task) SetAttr(name string, value Object) error {
+	return TypeErrorf("task has no attribute %q", name)
+}
+
+func (t *Task) IsTruthy() bool {
+	return true
+}
+
+func (t *Task) RunOperation(opType op.BinaryOpType, right Object) (Object, error) {
+	return nil, newTypeErrorf("unsupported operation for task: %v", opType)
+}
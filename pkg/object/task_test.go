@@ -0,0 +1,80 @@
+package object
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+func TestTaskAwait(t *testing.T) {
+	ctx := context.Background()
+	release := make(chan struct{})
+	task := NewTask(ctx, func(ctx context.Context) (Object, error) {
+		<-release
+		return NewInt(42), nil
+	})
+	assert.Equal(t, task.Type(), TASK)
+	assert.False(t, task.Done())
+	assert.Equal(t, task.Inspect(), "task(pending)")
+
+	close(release)
+	await, ok := task.GetAttr("await")
+	assert.True(t, ok)
+	result, err := await.(*Builtin).Call(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, result, NewInt(42))
+	assert.True(t, task.Done())
+	assert.Equal(t, task.Inspect(), "task(done)")
+
+	// A task without a result resolves to nil
+	result, err = NewTask(ctx, func(ctx context.Context) (Object, error) {
+		return nil, nil
+	}).Await(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, result, Object(Nil))
+}
+
+func TestTaskError(t *testing.T) {
+	ctx := context.Background()
+	task := NewTask(ctx, func(ctx context.Context) (Object, error) {
+		return nil, errors.New("request failed")
+	})
+	_, err := task.Await(ctx)
+	assert.Equal(t, err.Error(), "request failed")
+	assert.Equal(t, task.Inspect(), "task(failed)")
+
+	task = NewTask(ctx, func(ctx context.Context) (Object, error) {
+		panic("boom")
+	})
+	_, err = task.Await(ctx)
+	assert.Contains(t, err.Error(), "panic in task: boom")
+}
+
+func TestTaskCancel(t *testing.T) {
+	ctx := context.Background()
+	task := NewTask(ctx, func(ctx context.Context) (Object, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	cancel, _ := task.GetAttr("cancel")
+	_, err := cancel.(*Builtin).Call(ctx)
+	assert.Nil(t, err)
+	_, err = task.Await(ctx)
+	assert.True(t, errors.Is(err, context.Canceled))
+
+	// Cancelling the awaiting context stops the wait, not the task
+	task = NewTask(ctx, func(ctx context.Context) (Object, error) {
+		time.Sleep(20 * time.Millisecond)
+		return True, nil
+	})
+	waitCtx, stop := context.WithCancel(ctx)
+	stop()
+	_, err = task.Await(waitCtx)
+	assert.True(t, errors.Is(err, context.Canceled))
+	result, err := task.Await(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, result, Object(True))
+}
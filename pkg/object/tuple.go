@@ -0,0 +1,232 @@
+package object
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/op"
+)
+
+var tupleMethods = NewMethodRegistry[*Tuple]("tuple")
+
+func init() {
+	tupleMethods.Define("count").
+		Doc("Count occurrences of item").
+		Arg("item").
+		Returns("int").
+		Impl(func(t *Tuple, ctx context.Context, args ...Object) (Object, error) {
+			return NewInt(t.Count(args[0])), nil
+		})
+
+	tupleMethods.Define("index").
+		Doc("Find first index of item (-1 if not found)").
+		Arg("item").
+		Returns("int").
+		Impl(func(t *Tuple, ctx context.Context, args ...Object) (Object, error) {
+			return NewInt(t.Index(args[0])), nil
+		})
+
+	tupleMethods.Define("to_list").
+		Doc("Return the items as a new list").
+		Returns("list").
+		Impl(func(t *Tuple, ctx context.Context, args ...Object) (Object, error) {
+			return t.List(), nil
+		})
+}
+
+// Tuple is an immutable, fixed-length sequence of objects. Unlike a list,
+// its items cannot be replaced, added or removed once it is created, though
+// the items themselves may still be mutable.
+type Tuple struct {
+	items []Object
+}
+
+// NewTuple returns a tuple holding the given items. The tuple takes
+// ownership of the slice, which the caller must not modify afterwards.
+func NewTuple(items []Object) *Tuple {
+	return &Tuple{items: items}
+}
+
+func (t *Tuple) Attrs() []AttrSpec {
+	return tupleMethods.Specs()
+}
+
+func (t *Tuple) GetAttr(name string) (Object, bool) {
+	return tupleMethods.GetAttr(t, name)
+}
+
+func (t *Tuple) SetAttr(name string, value Object) error {
+	return TypeErrorf("tuple has no attribute %q", name)
+}
+
+func (t *Tuple) Type() Type {
+	return TUPLE
+}
+
+// Value returns the items of the tuple. The slice must not be modified.
+func (t *Tuple) Value() []Object {
+	return t.items
+}
+
+func (t *Tuple) Inspect() string {
+	return Format(t, FormatOptions{})
+}
+
+func (t *Tuple) String() string {
+	return t.Inspect()
+}
+
+func (t *Tuple) Interface() interface{} {
+	items := make([]interface{}, 0, len(t.items))
+	for _, item := range t.items {
+		items = append(items, item.Interface())
+	}
+	return items
+}
+
+// List returns a new list holding the items of the tuple.
+func (t *Tuple) List() *List {
+	items := make([]Object, len(t.items))
+	copy(items, t.items)
+	return NewList(items)
+}
+
+// Count returns the number of items with the specified value.
+func (t *Tuple) Count(obj Object) int64 {
+	count := int64(0)
+	for _, item := range t.items {
+		if Equals(obj, item) {
+			count++
+		}
+	}
+	return count
+}
+
+// Index returns the index of the first item with the specified value.
+func (t *Tuple) Index(obj Object) int64 {
+	for i, item := range t.items {
+		if Equals(obj, item) {
+			return int64(i)
+		}
+	}
+	return int64(-1)
+}
+
+func (t *Tuple) Compare(other Object) (int, error) {
+	otherTuple, ok := other.(*Tuple)
+	if !ok {
+		return 0, TypeErrorf("unable to compare tuple and %s", other.Type())
+	}
+	// Tuples compare item by item, with a shorter prefix ordering first
+	for i := 0; i < len(t.items) && i < len(otherTuple.items); i++ {
+		comparable, ok := t.items[i].(Comparable)
+		if !ok {
+			return 0, TypeErrorf("%s object is not comparable", t.items[i].Type())
+		}
+		comp, err := comparable.Compare(otherTuple.items[i])
+		if err != nil {
+			return 0, err
+		}
+		if comp != 0 {
+			return comp, nil
+		}
+	}
+	switch {
+	case len(t.items) < len(otherTuple.items):
+		return -1, nil
+	case len(t.items) > len(otherTuple.items):
+		return 1, nil
+	}
+	return 0, nil
+}
+
+func (t *Tuple) Equals(other Object) bool {
+	otherTuple, ok := other.(*Tuple)
+	if !ok {
+		return false
+	}
+	if len(t.items) != len(otherTuple.items) {
+		return false
+	}
+	for i, v := range t.items {
+		if !Equals(v, otherTuple.items[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (t *Tuple) IsTruthy() bool {
+	return len(t.items) > 0
+}
+
+func (t *Tuple) GetItem(key Object) (Object, *Error) {
+	indexObj, ok := key.(*Int)
+	if !ok {
+		return nil, TypeErrorf("tuple index must be an int (got %s)", key.Type())
+	}
+	idx, err := ResolveIndex(indexObj.value, int64(len(t.items)))
+	if err != nil {
+		return nil, NewError(err)
+	}
+	return t.items[idx], nil
+}
+
+// GetSlice implements the [start:stop] operator for a container type.
+func (t *Tuple) GetSlice(s Slice) (Object, *Error) {
+	start, stop, err := ResolveIntSlice(s, int64(len(t.items)))
+	if err != nil {
+		return nil, NewError(err)
+	}
+	items := make([]Object, stop-start)
+	copy(items, t.items[start:stop])
+	return NewTuple(items), nil
+}
+
+// SetItem implements the [key] = value operator for a container type.
+func (t *Tuple) SetItem(key, value Object) *Error {
+	return TypeErrorf("tuple does not support item assignment")
+}
+
+// DelItem implements the del [key] operator for a container type.
+func (t *Tuple) DelItem(key Object) *Error {
+	return TypeErrorf("tuple does not support item deletion")
+}
+
+// Contains returns true if the given item is found in this container.
+func (t *Tuple) Contains(item Object) *Bool {
+	for _, v := range t.items {
+		if Equals(v, item) {
+			return True
+		}
+	}
+	return False
+}
+
+// Len returns the number of items in this container.
+func (t *Tuple) Len() *Int {
+	return NewInt(int64(len(t.items)))
+}
+
+func (t *Tuple) Enumerate(ctx context.Context, fn func(key, value Object) bool) {
+	for i, item := range t.items {
+		if !fn(NewInt(int64(i)), item) {
+			return
+		}
+	}
+}
+
+func (t *Tuple) RunOperation(opType op.BinaryOpType, right Object) (Object, error) {
+	if right, ok := right.(*Tuple); ok && opType == op.Add {
+		combined := make([]Object, len(t.items)+len(right.items))
+		copy(combined, t.items)
+		copy(combined[len(t.items):], right.items)
+		return NewTuple(combined), nil
+	}
+	return nil, newTypeErrorf("unsupported operation for tuple: %v on type %s",
+		opType, right.Type())
+}
+
+func (t *Tuple) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.items)
+}
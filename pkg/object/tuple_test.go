@@ -0,0 +1,128 @@
+package object
+
+import (
+	"context"
+	"testing"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/op"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+func TestTupleBasics(t *testing.T) {
+	tup := NewTuple([]Object{NewInt(1), NewString("a")})
+	assert.Equal(t, tup.Type(), TUPLE)
+	assert.Equal(t, tup.Inspect(), `(1, "a")`)
+	assert.Equal(t, tup.Interface(), []any{int64(1), "a"})
+	assert.True(t, tup.IsTruthy())
+	assert.Equal(t, tup.Len().Value(), int64(2))
+
+	assert.Equal(t, NewTuple([]Object{NewInt(1)}).Inspect(), "(1,)")
+	assert.Equal(t, NewTuple(nil).Inspect(), "()")
+	assert.False(t, NewTuple(nil).IsTruthy())
+}
+
+func TestTupleGetItem(t *testing.T) {
+	tup := NewTuple([]Object{NewInt(1), NewInt(2), NewInt(3)})
+	item, err := tup.GetItem(NewInt(-1))
+	assert.Nil(t, err)
+	assert.Equal(t, item, NewInt(3))
+
+	_, err = tup.GetItem(NewInt(3))
+	assert.NotNil(t, err)
+	_, err = tup.GetItem(NewString("a"))
+	assert.NotNil(t, err)
+
+	slice, err := tup.GetSlice(Slice{Start: NewInt(1)})
+	assert.Nil(t, err)
+	assert.Equal(t, slice.Inspect(), "(2, 3)")
+}
+
+func TestTupleImmutable(t *testing.T) {
+	tup := NewTuple([]Object{NewInt(1)})
+	assert.NotNil(t, tup.SetItem(NewInt(0), NewInt(2)))
+	assert.NotNil(t, tup.DelItem(NewInt(0)))
+	assert.NotNil(t, tup.SetAttr("x", NewInt(2)))
+	assert.Equal(t, tup.Inspect(), "(1,)")
+}
+
+func TestTupleEqualsAndCompare(t *testing.T) {
+	a := NewTuple([]Object{NewInt(1), NewString("a")})
+	b := NewTuple([]Object{NewInt(1), NewString("a")})
+	assert.True(t, a.Equals(b))
+	assert.False(t, a.Equals(NewList([]Object{NewInt(1), NewString("a")})))
+
+	c := NewTuple([]Object{NewInt(1), NewString("b")})
+	cmp, err := a.Compare(c)
+	assert.Nil(t, err)
+	assert.Equal(t, cmp, -1)
+
+	cmp, err = NewTuple([]Object{NewInt(1)}).Compare(a)
+	assert.Nil(t, err)
+	assert.Equal(t, cmp, -1)
+
+	_, err = a.Compare(NewInt(1))
+	assert.NotNil(t, err)
+}
+
+func TestTupleAdd(t *testing.T) {
+	a := NewTuple([]Object{NewInt(1)})
+	result, err := a.RunOperation(op.Add, NewTuple([]Object{NewInt(2)}))
+	assert.Nil(t, err)
+	assert.Equal(t, result.Inspect(), "(1, 2)")
+	assert.Equal(t, a.Inspect(), "(1,)")
+
+	_, err = a.RunOperation(op.Add, NewList(nil))
+	assert.NotNil(t, err)
+}
+
+func TestTupleMethods(t *testing.T) {
+	ctx := context.Background()
+	tup := NewTuple([]Object{NewInt(1), NewInt(2), NewInt(1)})
+
+	count, ok := tup.GetAttr("count")
+	assert.True(t, ok)
+	result, err := count.(*Builtin).Call(ctx, NewInt(1))
+	assert.Nil(t, err)
+	assert.Equal(t, result, NewInt(2))
+
+	index, ok := tup.GetAttr("index")
+	assert.True(t, ok)
+	result, err = index.(*Builtin).Call(ctx, NewInt(2))
+	assert.Nil(t, err)
+	assert.Equal(t, result, NewInt(1))
+
+	toList, ok := tup.GetAttr("to_list")
+	assert.True(t, ok)
+	result, err = toList.(*Builtin).Call(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, result.Inspect(), "[1, 2, 1]")
+}
+
+func TestTupleMarshalJSON(t *testing.T) {
+	data, err := NewTuple([]Object{NewInt(1), NewString("a")}).MarshalJSON()
+	assert.Nil(t, err)
+	assert.Equal(t, string(data), `[1,"a"]`)
+}
+
+func TestFreezeThroughTuple(t *testing.T) {
+	inner := NewList(nil)
+	outer := NewList([]Object{NewTuple([]Object{inner})})
+	assert.Nil(t, Freeze(outer))
+	assert.True(t, IsFrozen(inner))
+}
+
+func TestTupleHashKey(t *testing.T) {
+	a, ok := NewTuple([]Object{NewString("a,b"), NewString("c")}).HashKey()
+	assert.True(t, ok)
+	b, ok := NewTuple([]Object{NewString("a"), NewString("b,c")}).HashKey()
+	assert.True(t, ok)
+	assert.True(t, a != b)
+
+	// Items of different types never collide
+	c, _ := NewTuple([]Object{NewInt(1)}).HashKey()
+	d, _ := NewTuple([]Object{NewString("1")}).HashKey()
+	assert.True(t, c != d)
+
+	_, ok = NewTuple([]Object{NewFloat(1.5)}).HashKey()
+	assert.False(t, ok)
+}
@@ -14,7 +14,11 @@ func init() {
 		return NewList(nil).Attrs()
 	})
 
-	RegisterType(MAP, "Mutable key-value mapping with string keys", func() []AttrSpec {
+	RegisterType(TUPLE, "Immutable fixed-length sequence", func() []AttrSpec {
+		return tupleMethods.Specs()
+	})
+
+	RegisterType(MAP, "Mutable key-value mapping with string, int, bool, or tuple keys", func() []AttrSpec {
 		return NewMap(nil).Attrs()
 	})
 
@@ -52,4 +56,28 @@ func init() {
 	RegisterType(RANGE, "Lazy sequence of integers", func() []AttrSpec {
 		return NewRange(0, 0, 1).Attrs()
 	})
+
+	RegisterType(STREAM, "Readable stream wrapping a Go io.Reader", func() []AttrSpec {
+		return streamMethods.Specs()
+	})
+
+	RegisterType(BUFFER, "Growable buffer for building strings and bytes", func() []AttrSpec {
+		return bufferMethods.Specs()
+	})
+
+	RegisterType(TASK, "Result of an operation running on its own goroutine", func() []AttrSpec {
+		return taskMethods.Specs()
+	})
+
+	RegisterType(GOCHAN, "Go channel that scripts can send to and receive from", func() []AttrSpec {
+		return goChanMethods.Specs()
+	})
+
+	RegisterType(ENUM, "Immutable named set of members declared with enum", func() []AttrSpec {
+		return enumAttrs.Specs()
+	})
+
+	RegisterType(ENUM_MEMBER, "Member of an enum with a name and ordinal", func() []AttrSpec {
+		return enumMemberAttrs.Specs()
+	})
 }
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io"
 	"reflect"
+	"sync"
 	"time"
 	"unicode/utf8"
 
@@ -17,6 +18,8 @@ import (
 var (
 	errorInterface   = reflect.TypeOf((*error)(nil)).Elem()
 	contextInterface = reflect.TypeOf((*context.Context)(nil)).Elem()
+	readerInterface  = reflect.TypeOf((*io.Reader)(nil)).Elem()
+	writerInterface  = reflect.TypeOf((*io.Writer)(nil)).Elem()
 )
 
 // *****************************************************************************
@@ -82,6 +85,24 @@ func AsFloat(obj Object) (float64, error) {
 	}
 }
 
+// AsDuration converts a number of seconds, or a string accepted by
+// time.ParseDuration such as "500ms" or "5m", to a duration.
+func AsDuration(obj Object) (time.Duration, error) {
+	switch obj := obj.(type) {
+	case *String:
+		d, err := time.ParseDuration(obj.value)
+		if err != nil {
+			return 0, newValueErrorf("invalid duration %q", obj.value)
+		}
+		return d, nil
+	case *Int, *Byte, *Float:
+		seconds, _ := AsFloat(obj)
+		return time.Duration(seconds * float64(time.Second)), nil
+	default:
+		return 0, newTypeErrorf("expected a duration in seconds or a string such as \"5m\" (%s given)", obj.Type())
+	}
+}
+
 func AsList(obj Object) (*List, error) {
 	list, ok := obj.(*List)
 	if !ok {
@@ -186,6 +207,16 @@ type RisorValuer interface {
 	RisorValue() Object
 }
 
+// RisorUnmarshaler is implemented by Go types that know how to set
+// themselves from Risor Objects. When converting a Risor Object to a Go
+// type T, the TypeRegistry calls UnmarshalRisor on a new *T if *T
+// implements this interface, before any other conversion.
+type RisorUnmarshaler interface {
+	UnmarshalRisor(obj Object) error
+}
+
+var unmarshalerInterface = reflect.TypeOf((*RisorUnmarshaler)(nil)).Elem()
+
 // *****************************************************************************
 // TypeRegistry
 // *****************************************************************************
@@ -198,9 +229,30 @@ type ToGoFunc func(obj Object, targetType reflect.Type) (any, error)
 
 // TypeRegistry handles conversion between Go values and Risor Objects.
 // It is immutable after construction and safe for concurrent use.
+//
+// Go structs are exposed to scripts by field name. A field's name can be set
+// with a `risor:"name"` tag, and a field tagged `risor:"-"` is hidden. The
+// omitempty tag option leaves zero-valued fields out of structs that are
+// converted to maps (see RegistryBuilder.RegisterStructAsMap).
 type TypeRegistry struct {
-	fromGo map[reflect.Type]FromGoFunc
-	toGo   map[reflect.Type]ToGoFunc
+	fromGo    map[reflect.Type]FromGoFunc
+	toGo      map[reflect.Type]ToGoFunc
+	fieldName FieldNameFunc
+	structs   sync.Map // map[reflect.Type]*structMeta, used with fieldName
+	funcSigs  sync.Map // map[reflect.Type]*funcSig
+}
+
+// structMeta returns the field and method metadata for a struct type, with
+// field names mapped by this registry.
+func (r *TypeRegistry) structMeta(structType reflect.Type) *structMeta {
+	if r == nil || r.fieldName == nil {
+		return getStructMeta(structType)
+	}
+	if meta, ok := r.structs.Load(structType); ok {
+		return meta.(*structMeta)
+	}
+	meta, _ := r.structs.LoadOrStore(structType, newStructMeta(structType, r.fieldName))
+	return meta.(*structMeta)
 }
 
 // FromGo converts a Go value to a Risor Object.
@@ -226,6 +278,14 @@ func (r *TypeRegistry) FromGo(v any) (Object, error) {
 		return fn(v)
 	}
 
+	// Readers, such as files and HTTP bodies, are streamed to scripts
+	if reader, ok := v.(io.Reader); ok {
+		if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr && rv.IsNil() {
+			return Nil, nil
+		}
+		return NewStream(reader), nil
+	}
+
 	// Handle by kind for common cases
 	return r.fromGoByKind(v, typ)
 }
@@ -269,6 +329,11 @@ func (r *TypeRegistry) fromGoByKind(v any, typ reflect.Type) (Object, error) {
 			return Nil, nil
 		}
 		return NewGoFunc(rv, typ.String(), r), nil
+	case reflect.Chan:
+		if rv.IsNil() {
+			return Nil, nil
+		}
+		return NewGoChan(rv, r), nil
 	case reflect.Struct:
 		// Wrap as GoStruct (create pointer for addressability)
 		ptrVal := reflect.New(typ)
@@ -340,8 +405,56 @@ func (r *TypeRegistry) ToGo(obj Object, targetType reflect.Type) (any, error) {
 		return fn(obj, targetType)
 	}
 
+	// Check if the target type implements RisorUnmarshaler
+	if reflect.PointerTo(targetType).Implements(unmarshalerInterface) {
+		ptr := reflect.New(targetType)
+		if err := ptr.Interface().(RisorUnmarshaler).UnmarshalRisor(obj); err != nil {
+			return nil, err
+		}
+		return ptr.Elem().Interface(), nil
+	}
+
+	// Wrapped Go values convert back to their own types
+	switch o := obj.(type) {
+	case *GoStruct:
+		if o.value.Type() == targetType {
+			return o.value.Interface(), nil
+		}
+		if o.structType == targetType {
+			return o.value.Elem().Interface(), nil
+		}
+	case *GoChan:
+		// Bidirectional channels may become send- or receive-only
+		if o.value.Type().AssignableTo(targetType) {
+			return o.value.Convert(targetType).Interface(), nil
+		}
+	case *GoFunc:
+		if o.fnType.AssignableTo(targetType) {
+			return o.value().Convert(targetType).Interface(), nil
+		}
+	}
+
 	// Handle by kind
-	return r.toGoByKind(obj, targetType)
+	result, err := r.toGoByKind(obj, targetType)
+	if err != nil || result == nil || targetType.Kind() == reflect.Interface {
+		return result, err
+	}
+	// Named types, such as `type Level int`, are converted from their
+	// underlying kind
+	if rv := reflect.ValueOf(result); rv.Type() != targetType && rv.Kind() == targetType.Kind() &&
+		rv.Type().ConvertibleTo(targetType) {
+		return rv.Convert(targetType).Interface(), nil
+	}
+	return result, nil
+}
+
+// reflectValue returns v as a reflect.Value of type typ. A nil v, which ToGo
+// returns for nil pointers and interfaces, becomes the zero value of typ.
+func reflectValue(v any, typ reflect.Type) reflect.Value {
+	if v == nil {
+		return reflect.Zero(typ)
+	}
+	return reflect.ValueOf(v)
 }
 
 func (r *TypeRegistry) toGoByKind(obj Object, target reflect.Type) (any, error) {
@@ -362,6 +475,8 @@ func (r *TypeRegistry) toGoByKind(obj Object, target reflect.Type) (any, error)
 		return r.toGoMap(obj, target)
 	case reflect.Ptr:
 		return r.toGoPointer(obj, target)
+	case reflect.Struct:
+		return r.toGoStruct(obj, target)
 	case reflect.Interface:
 		if target.NumMethod() == 0 {
 			// any / interface{}
@@ -371,6 +486,12 @@ func (r *TypeRegistry) toGoByKind(obj Object, target reflect.Type) (any, error)
 		if target.Implements(errorInterface) {
 			return toGoError(obj)
 		}
+		if target == readerInterface {
+			return AsReader(obj)
+		}
+		if target == writerInterface {
+			return AsWriter(obj)
+		}
 		if target.Implements(contextInterface) {
 			return nil, errors.New("context conversion not supported via ToGo")
 		}
@@ -407,7 +528,7 @@ func (r *TypeRegistry) toGoSlice(obj Object, target reflect.Type) (any, error) {
 		if err != nil {
 			return nil, fmt.Errorf("failed to convert slice element %d: %w", i, err)
 		}
-		slice = reflect.Append(slice, reflect.ValueOf(elem))
+		slice = reflect.Append(slice, reflectValue(elem, elemType))
 	}
 	return slice.Interface(), nil
 }
@@ -430,7 +551,7 @@ func (r *TypeRegistry) toGoArray(obj Object, target reflect.Type) (any, error) {
 		if err != nil {
 			return nil, fmt.Errorf("failed to convert array element %d: %w", i, err)
 		}
-		array.Index(i).Set(reflect.ValueOf(elem))
+		array.Index(i).Set(reflectValue(elem, elemType))
 	}
 	return array.Interface(), nil
 }
@@ -448,6 +569,9 @@ func (r *TypeRegistry) toGoMap(obj Object, target reflect.Type) (any, error) {
 	if target.Key().Kind() != reflect.String {
 		return nil, fmt.Errorf("unsupported map key type: %s", target.Key())
 	}
+	if len(m.keyed) > 0 {
+		return nil, newTypeErrorf("cannot convert a map with non-string keys to %s", target)
+	}
 
 	valueType := target.Elem()
 	result := reflect.MakeMapWithSize(target, m.Size())
@@ -457,11 +581,67 @@ func (r *TypeRegistry) toGoMap(obj Object, target reflect.Type) (any, error) {
 		if err != nil {
 			return nil, fmt.Errorf("failed to convert map value for key %q: %w", k, err)
 		}
-		result.SetMapIndex(reflect.ValueOf(k), reflect.ValueOf(goValue))
+		result.SetMapIndex(reflect.ValueOf(k).Convert(target.Key()), reflectValue(goValue, valueType))
 	}
 	return result.Interface(), nil
 }
 
+// toGoStruct converts a map to a struct, matching keys to field names as
+// scripts see them. Keys that match no field are an error, so that typos in
+// scripts are not silently ignored.
+func (r *TypeRegistry) toGoStruct(obj Object, target reflect.Type) (any, error) {
+	m, ok := obj.(*Map)
+	if !ok {
+		return nil, newTypeErrorf("expected a map, got %s", obj.Type())
+	}
+
+	if len(m.keyed) > 0 {
+		return nil, newTypeErrorf("cannot convert a map with non-string keys to %s", target)
+	}
+
+	meta := r.structMeta(target)
+	result := reflect.New(target).Elem()
+	for _, k := range m.SortedKeys() {
+		idx, ok := meta.fields[k]
+		if !ok {
+			return nil, fmt.Errorf("%s has no field %q", target, k)
+		}
+		field := result.Field(idx)
+		goValue, err := r.ToGo(m.items[k], field.Type())
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert field %q: %w", k, err)
+		}
+		field.Set(reflectValue(goValue, field.Type()))
+	}
+	return result.Interface(), nil
+}
+
+// structToMap converts a struct to a map keyed by field names as scripts
+// see them. Zero-valued fields tagged omitempty are left out.
+func (r *TypeRegistry) structToMap(rv reflect.Value) (Object, error) {
+	if rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return Nil, nil
+		}
+		rv = rv.Elem()
+	}
+
+	meta := r.structMeta(rv.Type())
+	result := make(map[string]Object, len(meta.fieldOrder))
+	for _, field := range meta.fieldOrder {
+		fv := rv.Field(field.index)
+		if field.omitEmpty && fv.IsZero() {
+			continue
+		}
+		val, err := r.FromGo(fv.Interface())
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert field %q: %w", field.name, err)
+		}
+		result[field.name] = val
+	}
+	return NewMap(result), nil
+}
+
 func (r *TypeRegistry) toGoPointer(obj Object, target reflect.Type) (any, error) {
 	if obj.Type() == NIL {
 		return reflect.Zero(target).Interface(), nil
@@ -474,7 +654,7 @@ func (r *TypeRegistry) toGoPointer(obj Object, target reflect.Type) (any, error)
 	}
 
 	ptr := reflect.New(elemType)
-	ptr.Elem().Set(reflect.ValueOf(elem))
+	ptr.Elem().Set(reflectValue(elem, elemType))
 	return ptr.Interface(), nil
 }
 
@@ -594,9 +774,11 @@ func toGoError(obj Object) (error, error) {
 
 // RegistryBuilder constructs a TypeRegistry with custom converters.
 type RegistryBuilder struct {
-	base   *TypeRegistry
-	fromGo map[reflect.Type]FromGoFunc
-	toGo   map[reflect.Type]ToGoFunc
+	base       *TypeRegistry
+	fromGo     map[reflect.Type]FromGoFunc
+	toGo       map[reflect.Type]ToGoFunc
+	fieldName  FieldNameFunc
+	mapStructs []reflect.Type
 }
 
 // NewRegistryBuilder creates a builder starting from the default registry.
@@ -620,6 +802,25 @@ func (b *RegistryBuilder) RegisterToGo(typ reflect.Type, fn ToGoFunc) *RegistryB
 	return b
 }
 
+// WithFieldNames sets the function that names struct fields for scripts,
+// such as SnakeCase. Fields named by a risor tag are not passed to it.
+func (b *RegistryBuilder) WithFieldNames(fn FieldNameFunc) *RegistryBuilder {
+	b.fieldName = fn
+	return b
+}
+
+// RegisterStructAsMap converts values of a struct type, and pointers to it,
+// to Risor maps instead of go_struct wrappers. Scripts then get a copy of
+// the data rather than access to the Go value and its methods. Maps convert
+// back to the struct type either way.
+func (b *RegistryBuilder) RegisterStructAsMap(typ reflect.Type) *RegistryBuilder {
+	if typ.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("RegisterStructAsMap: expected a struct type, got %s", typ))
+	}
+	b.mapStructs = append(b.mapStructs, typ)
+	return b
+}
+
 // Build creates an immutable TypeRegistry.
 func (b *RegistryBuilder) Build() *TypeRegistry {
 	fromGo := make(map[reflect.Type]FromGoFunc)
@@ -643,20 +844,34 @@ func (b *RegistryBuilder) Build() *TypeRegistry {
 		toGo[k] = v
 	}
 
-	return &TypeRegistry{fromGo: fromGo, toGo: toGo}
+	registry := &TypeRegistry{fromGo: fromGo, toGo: toGo, fieldName: b.fieldName}
+	if b.base != nil && registry.fieldName == nil {
+		registry.fieldName = b.base.fieldName
+	}
+	for _, typ := range b.mapStructs {
+		toMap := func(v any) (Object, error) {
+			return registry.structToMap(reflect.ValueOf(v))
+		}
+		fromGo[typ] = toMap
+		fromGo[reflect.PointerTo(typ)] = toMap
+	}
+	return registry
 }
 
 // *****************************************************************************
 // Default Registry
 // *****************************************************************************
 
-var defaultRegistry *TypeRegistry
+var (
+	defaultRegistry     *TypeRegistry
+	defaultRegistryOnce sync.Once
+)
 
 // DefaultRegistry returns a TypeRegistry with converters for all built-in types.
 func DefaultRegistry() *TypeRegistry {
-	if defaultRegistry == nil {
+	defaultRegistryOnce.Do(func() {
 		defaultRegistry = createDefaultRegistry()
-	}
+	})
 	return defaultRegistry
 }
 
@@ -2,6 +2,7 @@ package object
 
 import (
 	"encoding/json"
+	"fmt"
 	"reflect"
 	"testing"
 	"time"
@@ -931,3 +932,196 @@ func TestDefaultRegistrySingleton(t *testing.T) {
 	r2 := DefaultRegistry()
 	assert.True(t, r1 == r2) // Same instance
 }
+
+type taggedConfig struct {
+	HostName string `risor:"host"`
+	HTTPPort int
+	Token    string   `risor:"-"`
+	Labels   []string `risor:"labels,omitempty"`
+	Timeout  int      `risor:",omitempty"`
+	internal string
+}
+
+func TestStructTags(t *testing.T) {
+	registry := DefaultRegistry()
+	obj, err := registry.FromGo(&taggedConfig{HostName: "example.com", HTTPPort: 80, Token: "secret"})
+	assert.Nil(t, err)
+	gs := obj.(*GoStruct)
+
+	host, ok := gs.GetAttr("host")
+	assert.True(t, ok)
+	assert.Equal(t, host, NewString("example.com"))
+	_, ok = gs.GetAttr("HostName")
+	assert.False(t, ok)
+	_, ok = gs.GetAttr("Token")
+	assert.False(t, ok)
+	port, ok := gs.GetAttr("HTTPPort")
+	assert.True(t, ok)
+	assert.Equal(t, port, NewInt(80))
+
+	assert.Nil(t, gs.SetAttr("host", NewString("risor.io")))
+	assert.Equal(t, gs.Interface().(*taggedConfig).HostName, "risor.io")
+	assert.NotNil(t, gs.SetAttr("Token", NewString("x")))
+
+	var names []string
+	for _, spec := range gs.Attrs() {
+		names = append(names, spec.Name)
+	}
+	assert.Equal(t, names[:4], []string{"host", "HTTPPort", "labels", "Timeout"})
+}
+
+func TestRegistryFieldNames(t *testing.T) {
+	registry := NewRegistryBuilder().WithFieldNames(SnakeCase).Build()
+	obj, err := registry.FromGo(taggedConfig{HostName: "example.com", HTTPPort: 80})
+	assert.Nil(t, err)
+	gs := obj.(*GoStruct)
+
+	// Tags take precedence over the mapper
+	_, ok := gs.GetAttr("host")
+	assert.True(t, ok)
+	port, ok := gs.GetAttr("http_port")
+	assert.True(t, ok)
+	assert.Equal(t, port, NewInt(80))
+	_, ok = gs.GetAttr("HTTPPort")
+	assert.False(t, ok)
+
+	// The default registry is unaffected
+	obj, err = DefaultRegistry().FromGo(taggedConfig{})
+	assert.Nil(t, err)
+	_, ok = obj.(*GoStruct).GetAttr("HTTPPort")
+	assert.True(t, ok)
+}
+
+func TestSnakeCase(t *testing.T) {
+	tests := map[string]string{
+		"Name":       "name",
+		"UserID":     "user_id",
+		"HTTPPort":   "http_port",
+		"MaxRetries": "max_retries",
+		"URL":        "url",
+		"Field2":     "field2",
+	}
+	for input, expected := range tests {
+		assert.Equal(t, SnakeCase(input), expected, input)
+	}
+}
+
+func TestTypeRegistryToGoStruct(t *testing.T) {
+	registry := NewRegistryBuilder().WithFieldNames(SnakeCase).Build()
+	m := NewMap(map[string]Object{
+		"host":      NewString("example.com"),
+		"http_port": NewInt(8080),
+		"labels":    NewStringList([]string{"a", "b"}),
+	})
+
+	result, err := registry.ToGo(m, reflect.TypeOf(taggedConfig{}))
+	assert.Nil(t, err)
+	assert.Equal(t, result, taggedConfig{HostName: "example.com", HTTPPort: 8080, Labels: []string{"a", "b"}})
+
+	ptr, err := registry.ToGo(m, reflect.TypeOf(&taggedConfig{}))
+	assert.Nil(t, err)
+	assert.Equal(t, ptr.(*taggedConfig).HTTPPort, 8080)
+
+	_, err = registry.ToGo(NewMap(map[string]Object{"HTTPPort": NewInt(1)}), reflect.TypeOf(taggedConfig{}))
+	assert.Error(t, err, `object.taggedConfig has no field "HTTPPort"`)
+
+	_, err = registry.ToGo(NewMap(map[string]Object{"http_port": NewString("x")}), reflect.TypeOf(taggedConfig{}))
+	assert.NotNil(t, err)
+
+	_, err = registry.ToGo(NewInt(1), reflect.TypeOf(taggedConfig{}))
+	assert.NotNil(t, err)
+}
+
+func TestTypeRegistryGoStructToGo(t *testing.T) {
+	registry := DefaultRegistry()
+	cfg := &taggedConfig{HostName: "example.com"}
+	obj, err := registry.FromGo(cfg)
+	assert.Nil(t, err)
+
+	ptr, err := registry.ToGo(obj, reflect.TypeOf(cfg))
+	assert.Nil(t, err)
+	assert.True(t, ptr.(*taggedConfig) == cfg)
+
+	value, err := registry.ToGo(obj, reflect.TypeOf(taggedConfig{}))
+	assert.Nil(t, err)
+	assert.Equal(t, value.(taggedConfig).HostName, "example.com")
+}
+
+func TestRegisterStructAsMap(t *testing.T) {
+	registry := NewRegistryBuilder().
+		WithFieldNames(SnakeCase).
+		RegisterStructAsMap(reflect.TypeOf(taggedConfig{})).
+		Build()
+
+	obj, err := registry.FromGo(taggedConfig{HostName: "example.com", Token: "secret"})
+	assert.Nil(t, err)
+	// Zero-valued omitempty fields and hidden fields are left out
+	assert.Equal(t, obj, NewMap(map[string]Object{
+		"host":      NewString("example.com"),
+		"http_port": NewInt(0),
+	}))
+
+	obj, err = registry.FromGo(&taggedConfig{Labels: []string{"x"}, Timeout: 5})
+	assert.Nil(t, err)
+	m := obj.(*Map)
+	assert.Equal(t, m.Get("labels"), NewStringList([]string{"x"}))
+	assert.Equal(t, m.Get("timeout"), NewInt(5))
+
+	obj, err = registry.FromGo((*taggedConfig)(nil))
+	assert.Nil(t, err)
+	assert.Equal(t, obj, Nil)
+
+	// Round trip back to the struct
+	back, err := registry.ToGo(m, reflect.TypeOf(taggedConfig{}))
+	assert.Nil(t, err)
+	assert.Equal(t, back.(taggedConfig).Timeout, 5)
+}
+
+type testLevel int
+
+func (l *testLevel) UnmarshalRisor(obj Object) error {
+	s, err := AsString(obj)
+	if err != nil {
+		return err
+	}
+	switch s {
+	case "low":
+		*l = 1
+	case "high":
+		*l = 2
+	default:
+		return fmt.Errorf("invalid level %q", s)
+	}
+	return nil
+}
+
+func TestRisorUnmarshalerInterface(t *testing.T) {
+	registry := DefaultRegistry()
+	result, err := registry.ToGo(NewString("high"), reflect.TypeOf(testLevel(0)))
+	assert.Nil(t, err)
+	assert.Equal(t, result, testLevel(2))
+
+	ptr, err := registry.ToGo(NewString("low"), reflect.TypeOf((*testLevel)(nil)))
+	assert.Nil(t, err)
+	assert.Equal(t, *ptr.(*testLevel), testLevel(1))
+
+	_, err = registry.ToGo(NewString("medium"), reflect.TypeOf(testLevel(0)))
+	assert.Error(t, err, `invalid level "medium"`)
+}
+
+func TestAsDuration(t *testing.T) {
+	d, err := AsDuration(NewInt(2))
+	assert.Nil(t, err)
+	assert.Equal(t, d, 2*time.Second)
+	d, err = AsDuration(NewFloat(0.25))
+	assert.Nil(t, err)
+	assert.Equal(t, d, 250*time.Millisecond)
+	d, err = AsDuration(NewString("1m30s"))
+	assert.Nil(t, err)
+	assert.Equal(t, d, 90*time.Second)
+
+	_, err = AsDuration(NewString("soon"))
+	assert.Contains(t, err.Error(), `invalid duration "soon"`)
+	_, err = AsDuration(True)
+	assert.Contains(t, err.Error(), "expected a duration")
+}
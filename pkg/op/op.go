@@ -47,11 +47,13 @@ const (
 	// Build
 	BuildList   Code = 50
 	BuildMap    Code = 51
+	BuildTuple  Code = 52
 	BuildString Code = 53
 	ListAppend  Code = 54 // Append TOS to list at TOS-1
 	ListExtend  Code = 55 // Extend list at TOS-1 with iterable at TOS
 	MapMerge    Code = 56 // Merge map at TOS into map at TOS-1
 	MapSet      Code = 57 // Set key (TOS-1) to value (TOS) in map at TOS-2
+	BuildEnum   Code = 58 // Build enum from a name and operand member names on the stack
 
 	// Containers
 	BinarySubscr Code = 60
@@ -201,9 +203,11 @@ func init() {
 	ops := []opInfo{
 		{BinaryOp, "BINARY_OP", 1},
 		{BinarySubscr, "BINARY_SUBSCR", 0},
+		{BuildEnum, "BUILD_ENUM", 1},
 		{BuildList, "BUILD_LIST", 1},
 		{BuildMap, "BUILD_MAP", 1},
 		{BuildString, "BUILD_STRING", 1},
+		{BuildTuple, "BUILD_TUPLE", 1},
 		{Call, "CALL", 1},
 		{CallSpread, "CALL_SPREAD", 0},
 		{CompareOp, "COMPARE_OP", 1},
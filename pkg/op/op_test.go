@@ -46,11 +46,13 @@ func TestGetInfoAllOpcodes(t *testing.T) {
 		{UnaryNot, "UNARY_NOT", 0},
 		{BuildList, "BUILD_LIST", 1},
 		{BuildMap, "BUILD_MAP", 1},
+		{BuildTuple, "BUILD_TUPLE", 1},
 		{BuildString, "BUILD_STRING", 1},
 		{ListAppend, "LIST_APPEND", 0},
 		{ListExtend, "LIST_EXTEND", 0},
 		{MapMerge, "MAP_MERGE", 0},
 		{MapSet, "MAP_SET", 0},
+		{BuildEnum, "BUILD_ENUM", 1},
 		{BinarySubscr, "BINARY_SUBSCR", 0},
 		{StoreSubscr, "STORE_SUBSCR", 0},
 		{ContainsOp, "CONTAINS_OP", 1},
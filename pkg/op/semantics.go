@@ -0,0 +1,239 @@
+package op
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Semantics documents the behavior of an opcode: what its operands mean and
+// how it changes the value stack. It complements Info, which only describes
+// the encoding of an instruction.
+type Semantics struct {
+	Code     Code
+	Category string
+	Operands []string // operand names, in encoding order
+	Stack    string   // stack transition, e.g. "a, b -> result" (TOS rightmost)
+	Doc      string
+
+	// effect returns the number of values popped and pushed for the given
+	// operands. It is nil for instructions that transfer control in a way
+	// that depends on runtime state, such as returns and throws.
+	effect func(operands []Code) (pops, pushes int)
+}
+
+var semantics = make([]*Semantics, 256)
+
+// fixed returns an effect that does not depend on the operands.
+func fixed(pops, pushes int) func([]Code) (int, int) {
+	return func([]Code) (int, int) { return pops, pushes }
+}
+
+func init() {
+	defs := []Semantics{
+		// Execution
+		{Code: Nop, Category: "Execution", Stack: "->",
+			Doc: "Do nothing.", effect: fixed(0, 0)},
+		{Code: Halt, Category: "Execution", Stack: "->",
+			Doc: "Stop execution of the program, leaving the stack as is.", effect: fixed(0, 0)},
+		{Code: Call, Category: "Execution", Operands: []string{"argc"}, Stack: "fn, arg1, ..., argN -> result",
+			Doc:    "Call fn with argc arguments. A closure's result is pushed when it returns.",
+			effect: func(operands []Code) (int, int) { return int(operands[0]) + 1, 1 }},
+		{Code: CallSpread, Category: "Execution", Stack: "fn, args -> result",
+			Doc: "Call fn with the items of the list args as arguments.", effect: fixed(2, 1)},
+		{Code: ReturnValue, Category: "Execution", Stack: "value -> (caller) value",
+			Doc: "Return TOS to the caller, running pending finally blocks first."},
+
+		// Jump
+		{Code: JumpBackward, Category: "Jump", Operands: []string{"delta"}, Stack: "->",
+			Doc: "Jump to the instruction delta positions before this one.", effect: fixed(0, 0)},
+		{Code: JumpForward, Category: "Jump", Operands: []string{"delta"}, Stack: "->",
+			Doc: "Jump to the instruction delta positions after this one.", effect: fixed(0, 0)},
+		{Code: PopJumpForwardIfFalse, Category: "Jump", Operands: []string{"delta"}, Stack: "cond ->",
+			Doc: "Pop TOS and jump forward by delta if it is falsy.", effect: fixed(1, 0)},
+		{Code: PopJumpForwardIfTrue, Category: "Jump", Operands: []string{"delta"}, Stack: "cond ->",
+			Doc: "Pop TOS and jump forward by delta if it is truthy.", effect: fixed(1, 0)},
+		{Code: PopJumpForwardIfNotNil, Category: "Jump", Operands: []string{"delta"}, Stack: "value ->",
+			Doc: "Pop TOS and jump forward by delta if it is not nil.", effect: fixed(1, 0)},
+		{Code: PopJumpForwardIfNil, Category: "Jump", Operands: []string{"delta"}, Stack: "value ->",
+			Doc: "Pop TOS and jump forward by delta if it is nil.", effect: fixed(1, 0)},
+
+		// Load
+		{Code: LoadAttr, Category: "Load", Operands: []string{"name"}, Stack: "obj -> value",
+			Doc: "Replace TOS with its attribute names[name].", effect: fixed(1, 1)},
+		{Code: LoadFast, Category: "Load", Operands: []string{"local"}, Stack: "-> value",
+			Doc: "Push the local variable at index local.", effect: fixed(0, 1)},
+		{Code: LoadFree, Category: "Load", Operands: []string{"free"}, Stack: "-> value",
+			Doc: "Push the value of the captured variable at index free.", effect: fixed(0, 1)},
+		{Code: LoadGlobal, Category: "Load", Operands: []string{"global"}, Stack: "-> value",
+			Doc: "Push the global variable at index global.", effect: fixed(0, 1)},
+		{Code: LoadConst, Category: "Load", Operands: []string{"const"}, Stack: "-> value",
+			Doc: "Push the constant at index const.", effect: fixed(0, 1)},
+		{Code: LoadAttrOrNil, Category: "Load", Operands: []string{"name"}, Stack: "obj -> value",
+			Doc: "Like LOAD_ATTR, but push nil if obj is nil or lacks the attribute.", effect: fixed(1, 1)},
+
+		// Store
+		{Code: StoreAttr, Category: "Store", Operands: []string{"name"}, Stack: "value, obj ->",
+			Doc: "Set attribute names[name] of obj to value.", effect: fixed(2, 0)},
+		{Code: StoreFast, Category: "Store", Operands: []string{"local"}, Stack: "value ->",
+			Doc: "Pop TOS into the local variable at index local.", effect: fixed(1, 0)},
+		{Code: StoreFree, Category: "Store", Operands: []string{"free"}, Stack: "value ->",
+			Doc: "Pop TOS into the captured variable at index free.", effect: fixed(1, 0)},
+		{Code: StoreGlobal, Category: "Store", Operands: []string{"global"}, Stack: "value ->",
+			Doc: "Pop TOS into the global variable at index global.", effect: fixed(1, 0)},
+
+		// Operations
+		{Code: BinaryOp, Category: "Operations", Operands: []string{"op"}, Stack: "a, b -> result",
+			Doc: "Apply the binary operation op (a BinaryOpType) to a and b.", effect: fixed(2, 1)},
+		{Code: CompareOp, Category: "Operations", Operands: []string{"op"}, Stack: "a, b -> result",
+			Doc: "Compare a and b using op (a CompareOpType).", effect: fixed(2, 1)},
+		{Code: UnaryNegative, Category: "Operations", Stack: "value -> result",
+			Doc: "Negate a numeric TOS.", effect: fixed(1, 1)},
+		{Code: UnaryNot, Category: "Operations", Stack: "value -> result",
+			Doc: "Replace TOS with the boolean inverse of its truthiness.", effect: fixed(1, 1)},
+
+		// Build
+		{Code: BuildList, Category: "Build", Operands: []string{"count"}, Stack: "item1, ..., itemN -> list",
+			Doc:    "Build a list from the top count items.",
+			effect: func(operands []Code) (int, int) { return int(operands[0]), 1 }},
+		{Code: BuildMap, Category: "Build", Operands: []string{"count"}, Stack: "k1, v1, ..., kN, vN -> map",
+			Doc:    "Build a map from the top count key/value pairs.",
+			effect: func(operands []Code) (int, int) { return 2 * int(operands[0]), 1 }},
+		{Code: BuildTuple, Category: "Build", Operands: []string{"count"}, Stack: "item1, ..., itemN -> tuple",
+			Doc:    "Build a tuple from the top count items.",
+			effect: func(operands []Code) (int, int) { return int(operands[0]), 1 }},
+		{Code: BuildString, Category: "Build", Operands: []string{"count"}, Stack: "part1, ..., partN -> string",
+			Doc:    "Concatenate the top count values into a string.",
+			effect: func(operands []Code) (int, int) { return int(operands[0]), 1 }},
+		{Code: ListAppend, Category: "Build", Stack: "list, item -> list",
+			Doc: "Push a copy of list with item appended.", effect: fixed(2, 1)},
+		{Code: ListExtend, Category: "Build", Stack: "list, iterable -> list",
+			Doc: "Push a copy of list extended with the items of iterable.", effect: fixed(2, 1)},
+		{Code: MapMerge, Category: "Build", Stack: "map, source -> map",
+			Doc: "Push a copy of map with the entries of source merged in.", effect: fixed(2, 1)},
+		{Code: MapSet, Category: "Build", Stack: "map, key, value -> map",
+			Doc: "Push a copy of map with key set to value.", effect: fixed(3, 1)},
+		{Code: BuildEnum, Category: "Build", Operands: []string{"count"}, Stack: "name, member1, ..., memberN -> enum",
+			Doc:    "Build an enum from a name and count member names.",
+			effect: func(operands []Code) (int, int) { return int(operands[0]) + 1, 1 }},
+
+		// Containers
+		{Code: BinarySubscr, Category: "Containers", Stack: "container, index -> value",
+			Doc: "Push container[index].", effect: fixed(2, 1)},
+		{Code: StoreSubscr, Category: "Containers", Stack: "value, container, index ->",
+			Doc: "Set container[index] to value.", effect: fixed(3, 0)},
+		{Code: ContainsOp, Category: "Containers", Operands: []string{"invert"}, Stack: "container, item -> result",
+			Doc: "Push whether container contains item, inverted if invert is 1.", effect: fixed(2, 1)},
+		{Code: Length, Category: "Containers", Stack: "container -> length",
+			Doc: "Push the length of container.", effect: fixed(1, 1)},
+		{Code: Slice, Category: "Containers", Stack: "container, stop, start -> result",
+			Doc: "Push container[start:stop].", effect: fixed(3, 1)},
+		{Code: Unpack, Category: "Containers", Operands: []string{"count"}, Stack: "container -> item1, ..., itemN",
+			Doc:    "Push count items of container (keys for maps), padding with nil.",
+			effect: func(operands []Code) (int, int) { return 1, int(operands[0]) }},
+
+		// Stack
+		{Code: Swap, Category: "Stack", Operands: []string{"depth"}, Stack: "x, ..., tos -> tos, ..., x",
+			Doc: "Swap TOS with the item depth positions below it.", effect: fixed(0, 0)},
+		{Code: Copy, Category: "Stack", Operands: []string{"depth"}, Stack: "x, ... -> x, ..., x",
+			Doc: "Push a copy of the item depth positions below TOS.", effect: fixed(0, 1)},
+		{Code: PopTop, Category: "Stack", Stack: "value ->",
+			Doc: "Discard TOS.", effect: fixed(1, 0)},
+
+		// Push constants
+		{Code: Nil, Category: "Push constants", Stack: "-> nil",
+			Doc: "Push nil.", effect: fixed(0, 1)},
+		{Code: False, Category: "Push constants", Stack: "-> false",
+			Doc: "Push false.", effect: fixed(0, 1)},
+		{Code: True, Category: "Push constants", Stack: "-> true",
+			Doc: "Push true.", effect: fixed(0, 1)},
+
+		// Closures
+		{Code: LoadClosure, Category: "Closures", Operands: []string{"const", "free"},
+			Stack:  "cell1, ..., cellN -> closure",
+			Doc:    "Push a closure of function constant const capturing the top free cells.",
+			effect: func(operands []Code) (int, int) { return int(operands[1]), 1 }},
+		{Code: MakeCell, Category: "Closures", Operands: []string{"local", "depth"}, Stack: "-> cell",
+			Doc: "Push a cell referring to the local variable in the frame depth levels up.", effect: fixed(0, 1)},
+
+		// Partials
+		{Code: Partial, Category: "Partials", Operands: []string{"argc"}, Stack: "fn, arg1, ..., argN -> partial",
+			Doc:    "Push a partial application of fn to argc arguments.",
+			effect: func(operands []Code) (int, int) { return int(operands[0]) + 1, 1 }},
+
+		// Exception handling
+		{Code: PushExcept, Category: "Exception handling", Operands: []string{"catch", "finally"}, Stack: "->",
+			Doc:    "Push an exception handler whose catch and finally blocks start at the given offsets.",
+			effect: fixed(0, 0)},
+		{Code: PopExcept, Category: "Exception handling", Stack: "->",
+			Doc: "Pop the innermost exception handler.", effect: fixed(0, 0)},
+		{Code: Throw, Category: "Exception handling", Stack: "value -> (handler)",
+			Doc: "Raise TOS as an error, unwinding to the innermost handler."},
+		{Code: EndFinally, Category: "Exception handling", Stack: "-> (pending)",
+			Doc: "End a finally block, completing any pending return or re-raising any pending error."},
+	}
+	for i := range defs {
+		s := defs[i]
+		semantics[s.Code] = &s
+	}
+}
+
+// GetSemantics returns the documented semantics of the given opcode. The
+// second return value is false if the opcode is unknown.
+func GetSemantics(code Code) (Semantics, bool) {
+	if int(code) >= len(semantics) || semantics[code] == nil {
+		return Semantics{}, false
+	}
+	return *semantics[code], true
+}
+
+// StackEffect returns the number of values the instruction pops from and
+// pushes onto the value stack, given its operands. The last return value is
+// false if the opcode is unknown, the operand count is wrong, or the effect
+// depends on runtime state (ReturnValue, Throw, and EndFinally).
+func StackEffect(code Code, operands ...Code) (pops, pushes int, ok bool) {
+	s, found := GetSemantics(code)
+	if !found || s.effect == nil || len(operands) != GetInfo(code).OperandCount {
+		return 0, 0, false
+	}
+	pops, pushes = s.effect(operands)
+	return pops, pushes, true
+}
+
+// Reference returns a Markdown reference for the instruction set, generated
+// from the opcode table and the documented semantics.
+func Reference() string {
+	var codes []Code
+	for _, s := range semantics {
+		if s != nil {
+			codes = append(codes, s.Code)
+		}
+	}
+	sort.Slice(codes, func(i, j int) bool { return codes[i] < codes[j] })
+
+	var b strings.Builder
+	b.WriteString("# Risor Instruction Set\n\n")
+	b.WriteString("<!-- Code generated by TestReferenceUpToDate in pkg/op. DO NOT EDIT. -->\n\n")
+	b.WriteString("Each instruction is an opcode followed by its operands, all encoded as\n")
+	b.WriteString("16-bit values. Stack transitions list the values an instruction consumes\n")
+	b.WriteString("and produces, with the top of the stack (TOS) rightmost.\n")
+
+	category := ""
+	for _, code := range codes {
+		s := semantics[code]
+		info := GetInfo(code)
+		if s.Category != category {
+			category = s.Category
+			fmt.Fprintf(&b, "\n## %s\n\n", category)
+			b.WriteString("| Code | Name | Operands | Stack | Description |\n")
+			b.WriteString("| ---: | ---- | -------- | ----- | ----------- |\n")
+		}
+		operands := "-"
+		if len(s.Operands) > 0 {
+			operands = strings.Join(s.Operands, ", ")
+		}
+		fmt.Fprintf(&b, "| %d | `%s` | %s | `%s` | %s |\n",
+			code, info.Name, operands, s.Stack, s.Doc)
+	}
+	return b.String()
+}
@@ -0,0 +1,137 @@
+package op
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+// To update the golden files, set the environment variable:
+//
+//	UPDATE_GOLDEN=1 go test ./pkg/op/...
+func updateGolden() bool {
+	return os.Getenv("UPDATE_GOLDEN") == "1"
+}
+
+// checkGolden compares actual against the contents of path, rewriting the
+// file instead when UPDATE_GOLDEN is set.
+func checkGolden(t *testing.T, path, actual string) {
+	t.Helper()
+	if updateGolden() {
+		if err := os.WriteFile(path, []byte(actual), 0o644); err != nil {
+			t.Fatalf("failed to write golden file: %v", err)
+		}
+		return
+	}
+	expected, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read golden file (run with UPDATE_GOLDEN=1 to create): %v", err)
+	}
+	if string(expected) != actual {
+		t.Errorf("%s is out of date (run with UPDATE_GOLDEN=1 to update)\n\nexpected:\n%s\n\nactual:\n%s",
+			path, expected, actual)
+	}
+}
+
+func knownOpcodes() []Code {
+	var codes []Code
+	for i := range infos {
+		if infos[i].Name != "" {
+			codes = append(codes, Code(i))
+		}
+	}
+	return codes
+}
+
+func TestSemanticsCoverAllOpcodes(t *testing.T) {
+	for _, code := range knownOpcodes() {
+		info := GetInfo(code)
+		s, ok := GetSemantics(code)
+		assert.True(t, ok, "missing semantics for %s", info.Name)
+		assert.Equal(t, s.Code, code)
+		assert.Len(t, s.Operands, info.OperandCount, "operand names for %s", info.Name)
+		assert.NotEqual(t, s.Category, "", "category for %s", info.Name)
+		assert.NotEqual(t, s.Stack, "", "stack for %s", info.Name)
+		assert.NotEqual(t, s.Doc, "", "doc for %s", info.Name)
+	}
+	for i, s := range semantics {
+		if s != nil {
+			assert.NotEqual(t, GetInfo(Code(i)).Name, "", "semantics for unknown opcode %d", i)
+		}
+	}
+}
+
+func TestGetSemanticsUnknown(t *testing.T) {
+	_, ok := GetSemantics(Invalid)
+	assert.False(t, ok)
+	_, ok = GetSemantics(Code(1000))
+	assert.False(t, ok)
+}
+
+func TestStackEffect(t *testing.T) {
+	tests := []struct {
+		code     Code
+		operands []Code
+		pops     int
+		pushes   int
+	}{
+		{LoadConst, []Code{0}, 0, 1},
+		{BinaryOp, []Code{Code(Add)}, 2, 1},
+		{Call, []Code{2}, 3, 1},
+		{BuildList, []Code{4}, 4, 1},
+		{BuildMap, []Code{2}, 4, 1},
+		{BuildTuple, []Code{2}, 2, 1},
+		{BuildEnum, []Code{3}, 4, 1},
+		{Unpack, []Code{3}, 1, 3},
+		{LoadClosure, []Code{0, 2}, 2, 1},
+		{StoreSubscr, nil, 3, 0},
+	}
+	for _, tt := range tests {
+		pops, pushes, ok := StackEffect(tt.code, tt.operands...)
+		assert.True(t, ok, GetInfo(tt.code).Name)
+		assert.Equal(t, pops, tt.pops, GetInfo(tt.code).Name)
+		assert.Equal(t, pushes, tt.pushes, GetInfo(tt.code).Name)
+	}
+}
+
+func TestStackEffectNotStatic(t *testing.T) {
+	for _, code := range []Code{ReturnValue, Throw, EndFinally, Invalid} {
+		_, _, ok := StackEffect(code)
+		assert.False(t, ok, GetInfo(code).Name)
+	}
+	// Wrong operand count
+	_, _, ok := StackEffect(BuildList)
+	assert.False(t, ok)
+}
+
+// TestStackEffectsGolden records the stack effect of every opcode so that
+// changes to instruction semantics show up in review.
+func TestStackEffectsGolden(t *testing.T) {
+	var b strings.Builder
+	for _, code := range knownOpcodes() {
+		info := GetInfo(code)
+		operands := make([]Code, info.OperandCount)
+		parts := []string{info.Name}
+		for i := range operands {
+			operands[i] = 3
+			parts = append(parts, "3")
+		}
+		pops, pushes, ok := StackEffect(code, operands...)
+		if !ok {
+			fmt.Fprintf(&b, "%-40s dynamic\n", strings.Join(parts, " "))
+			continue
+		}
+		fmt.Fprintf(&b, "%-40s pops %d, pushes %d\n", strings.Join(parts, " "), pops, pushes)
+	}
+	checkGolden(t, filepath.Join("testdata", "stack_effects.golden"), b.String())
+}
+
+// TestReferenceUpToDate checks that the generated instruction set reference
+// matches the opcode table.
+func TestReferenceUpToDate(t *testing.T) {
+	checkGolden(t, filepath.Join("..", "..", "docs", "design", "instruction-set.md"), Reference())
+}
@@ -469,11 +469,38 @@ func TestArrowFunctionNested(t *testing.T) {
 	assert.Len(t, fn2.Params, 1)
 }
 
-func TestArrowFunctionCommaWithoutArrow(t *testing.T) {
-	// (1, 2) without arrow should error
-	_, err := Parse(context.Background(), "(1, 2)", nil)
+func TestTupleLiteral(t *testing.T) {
+	// (1, 2) without arrow is a tuple
+	program, err := Parse(context.Background(), "(1, 2)", nil)
+	assert.Nil(t, err)
+	tuple, ok := program.First().(*ast.Tuple)
+	assert.True(t, ok)
+	assert.Len(t, tuple.Items, 2)
+	assert.Equal(t, tuple.String(), "(1, 2)")
+
+	// A trailing comma makes a single-item tuple
+	program, err = Parse(context.Background(), "(x,\n)", nil)
+	assert.Nil(t, err)
+	tuple, ok = program.First().(*ast.Tuple)
+	assert.True(t, ok)
+	assert.Len(t, tuple.Items, 1)
+	assert.Equal(t, tuple.String(), "(x,)")
+
+	// A trailing comma is still allowed in arrow parameters
+	program, err = Parse(context.Background(), "(x,) => x", nil)
+	assert.Nil(t, err)
+	_, ok = program.First().(*ast.Func)
+	assert.True(t, ok)
+}
+
+func TestTupleLiteralErrors(t *testing.T) {
+	_, err := Parse(context.Background(), "(1, ...xs)", nil)
 	assert.NotNil(t, err)
-	assert.Contains(t, err.Error(), "comma-separated expressions require arrow function syntax")
+	assert.Contains(t, err.Error(), "spread is not supported in tuple literals")
+
+	_, err = Parse(context.Background(), "(x = 1, y)", nil)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "expected expression in tuple")
 }
 
 func TestEmptyParensWithoutArrow(t *testing.T) {
@@ -886,6 +913,27 @@ func TestLetSingleVariable(t *testing.T) {
 	assert.Equal(t, "x", varNode.Name.Name)
 }
 
+func TestLetAndConstTypeAnnotations(t *testing.T) {
+	program, err := Parse(context.Background(), "let x: int = 1\nconst name: string = \"a\"", nil)
+	assert.Nil(t, err)
+
+	varNode, ok := program.Stmts[0].(*ast.Var)
+	assert.True(t, ok)
+	assert.Equal(t, varNode.Type.Name, "int")
+	assert.Equal(t, varNode.String(), "let x: int = 1")
+
+	constNode, ok := program.Stmts[1].(*ast.Const)
+	assert.True(t, ok)
+	assert.Equal(t, constNode.Type.Name, "string")
+	assert.Equal(t, constNode.String(), `const name: string = "a"`)
+
+	// Annotations apply to single declarations only
+	for _, input := range []string{"let x: = 1", "let a: int, b = [1, 2]", "const c: int"} {
+		_, err := Parse(context.Background(), input, nil)
+		assert.NotNil(t, err, input)
+	}
+}
+
 // =============================================================================
 // NEWLINE HANDLING EDGE CASES
 // =============================================================================
@@ -28,7 +28,7 @@ func (p *Parser) parseIdent() (ast.Node, bool) {
 	if p.peekTokenIs(token.ARROW) && !p.inPatternContext {
 		arrowPos := p.curToken.StartPosition
 		p.nextToken() // move to '=>'
-		return p.parseArrowBody(arrowPos, []ast.FuncParam{ident}, nil)
+		return p.parseArrowBody(arrowPos, []ast.FuncParam{ident}, nil, nil)
 	}
 
 	return ident, true
@@ -101,7 +101,7 @@ func (p *Parser) parseGroupedExpr() (ast.Node, bool) {
 	if p.curTokenIs(token.RPAREN) {
 		if p.peekTokenIs(token.ARROW) {
 			p.nextToken() // move to '=>'
-			return p.parseArrowBody(openParen, nil, nil)
+			return p.parseArrowBody(openParen, nil, nil, nil)
 		}
 		p.setTokenError(p.curToken, "empty parentheses require arrow function syntax")
 		return nil, false
@@ -109,21 +109,27 @@ func (p *Parser) parseGroupedExpr() (ast.Node, bool) {
 
 	// Parse first item - could be expression or arrow param with default
 	// Use parseNode instead of parseExpression to allow Assign nodes for defaults
-	firstItem := p.parseNode(LOWEST)
+	var types map[string]*ast.TypeAnnotation
+	firstItem := p.parseGroupItem(&types)
 	if firstItem == nil {
 		return nil, false
 	}
 
-	// Check if we have a comma (multiple items = must be arrow function)
+	// Check if we have a comma (multiple items = arrow function or tuple)
 	var items []ast.Node
 	items = append(items, firstItem)
+	trailingComma := false
 
 	for p.peekTokenIs(token.COMMA) {
 		p.nextToken() // move to ','
+		if p.skipNewlinesAndPeek(token.RPAREN) {
+			trailingComma = true
+			break
+		}
 		p.nextToken() // move past ','
 		// Skip newlines after comma
 		p.eatNewlines()
-		item := p.parseNode(LOWEST)
+		item := p.parseGroupItem(&types)
 		if item == nil {
 			return nil, false
 		}
@@ -140,15 +146,18 @@ func (p *Parser) parseGroupedExpr() (ast.Node, bool) {
 	// Check for arrow function (but not in pattern context)
 	if p.peekTokenIs(token.ARROW) && !p.inPatternContext {
 		p.nextToken() // move to '=>'
-		return p.parseArrowParams(openParen, items)
+		return p.parseArrowParams(openParen, items, types)
 	}
-
-	// Not an arrow function - must be a single grouped expression
-	if len(items) > 1 {
-		p.setTokenError(p.curToken, "comma-separated expressions require arrow function syntax: (x, y) => ...")
+	if types != nil {
+		p.setTokenError(p.curToken, "type annotations are only allowed on arrow function parameters")
 		return nil, false
 	}
 
+	// Not an arrow function - a comma makes it a tuple
+	if len(items) > 1 || trailingComma {
+		return p.newTuple(openParen, items)
+	}
+
 	// Ensure the single item is an expression
 	expr, ok := firstItem.(ast.Expr)
 	if !ok {
@@ -159,8 +168,58 @@ func (p *Parser) parseGroupedExpr() (ast.Node, bool) {
 	return expr, true
 }
 
+// parseGroupItem parses one item of a parenthesized group. An identifier
+// followed by ":" is an annotated arrow function parameter, optionally with
+// a default value; its annotation is added to types.
+func (p *Parser) parseGroupItem(types *map[string]*ast.TypeAnnotation) ast.Node {
+	item := p.parseNode(LOWEST)
+	ident, ok := item.(*ast.Ident)
+	if !ok || !p.peekTokenIs(token.COLON) || p.inPatternContext {
+		return item
+	}
+	p.nextToken() // move to ':'
+	typ := p.parseTypeAnnotation()
+	if typ == nil {
+		return nil
+	}
+	if *types == nil {
+		*types = map[string]*ast.TypeAnnotation{}
+	}
+	(*types)[ident.Name] = typ
+	if !p.peekTokenIs(token.ASSIGN) {
+		return ident
+	}
+	p.nextToken() // move to '='
+	opPos := p.curToken.StartPosition
+	p.nextToken() // move past '='
+	value := p.parseExpression(LOWEST)
+	if value == nil {
+		return nil
+	}
+	return &ast.Assign{Name: ident, OpPos: opPos, Op: "=", Value: value}
+}
+
+// newTuple builds a tuple literal from the items of a parenthesized group.
+// The current token is the closing paren.
+func (p *Parser) newTuple(lparen token.Position, items []ast.Node) (ast.Node, bool) {
+	exprs := make([]ast.Expr, 0, len(items))
+	for _, item := range items {
+		expr, ok := item.(ast.Expr)
+		if !ok {
+			p.setTokenError(p.curToken, "expected expression in tuple")
+			return nil, false
+		}
+		if _, ok := expr.(*ast.Spread); ok {
+			p.setTokenError(p.curToken, "spread is not supported in tuple literals")
+			return nil, false
+		}
+		exprs = append(exprs, expr)
+	}
+	return &ast.Tuple{Lparen: lparen, Items: exprs, Rparen: p.curToken.StartPosition}, true
+}
+
 // parseArrowParams validates items as arrow function parameters and parses the body
-func (p *Parser) parseArrowParams(arrowPos token.Position, items []ast.Node) (ast.Node, bool) {
+func (p *Parser) parseArrowParams(arrowPos token.Position, items []ast.Node, types map[string]*ast.TypeAnnotation) (ast.Node, bool) {
 	params := make([]ast.FuncParam, 0, len(items))
 	defaults := make(map[string]ast.Expr)
 
@@ -196,7 +255,7 @@ func (p *Parser) parseArrowParams(arrowPos token.Position, items []ast.Node) (as
 		}
 	}
 
-	return p.parseArrowBody(arrowPos, params, defaults)
+	return p.parseArrowBody(arrowPos, params, defaults, types)
 }
 
 // convertMapToDestructureParam converts a Map literal to an ObjectDestructureParam.
@@ -277,7 +336,7 @@ func (p *Parser) convertListToDestructureParam(l *ast.List) *ast.ArrayDestructur
 }
 
 // parseArrowBody parses the body of an arrow function (expression or block)
-func (p *Parser) parseArrowBody(arrowPos token.Position, params []ast.FuncParam, defaults map[string]ast.Expr) (ast.Node, bool) {
+func (p *Parser) parseArrowBody(arrowPos token.Position, params []ast.FuncParam, defaults map[string]ast.Expr, types map[string]*ast.TypeAnnotation) (ast.Node, bool) {
 	p.nextToken() // move past '=>'
 
 	var body *ast.Block
@@ -306,14 +365,15 @@ func (p *Parser) parseArrowBody(arrowPos token.Position, params []ast.FuncParam,
 
 	// Arrow functions currently don't support rest parameters (nil)
 	return &ast.Func{
-		Func:      arrowPos,
-		Name:      nil,
-		Lparen:    arrowPos,
-		Params:    params,
-		Defaults:  defaults,
-		RestParam: nil,
-		Rparen:    arrowPos,
-		Body:      body,
+		Func:       arrowPos,
+		Name:       nil,
+		Lparen:     arrowPos,
+		Params:     params,
+		Defaults:   defaults,
+		ParamTypes: types,
+		RestParam:  nil,
+		Rparen:     arrowPos,
+		Body:       body,
 	}, true
 }
 
@@ -460,7 +520,57 @@ func (p *Parser) parseCall(functionNode ast.Node) (ast.Node, bool) {
 		return nil, false
 	}
 	rparen := p.curToken.StartPosition
-	return &ast.Call{Fun: function, Lparen: lparen, Args: arguments, Rparen: rparen}, true
+	call := &ast.Call{Fun: function, Lparen: lparen, Args: arguments, Rparen: rparen}
+	trailing, ok := p.parseTrailingFunc()
+	if !ok {
+		return nil, false
+	}
+	if trailing != nil {
+		call.Args = append(call.Args, trailing)
+		call.Rparen = trailing.End().Advance(-1)
+	}
+	return call, true
+}
+
+// parseTrailingFunc parses a function literal written after a call on the
+// same line, which is passed as the call's final argument:
+//
+//	items.each x => print(x)        // items.each(x => print(x))
+//	items.reduce(0) function(acc, x) { return acc + x }
+//
+// The trailing function must be a single-parameter arrow function or a
+// function literal. Returns nil if no trailing function follows.
+func (p *Parser) parseTrailingFunc() (*ast.Func, bool) {
+	if p.inPatternContext || !(p.peekTokenIs(token.IDENT) || p.peekTokenIs(token.FUNCTION)) {
+		return nil, true
+	}
+	p.nextToken()
+	var node ast.Node
+	var ok bool
+	if p.curTokenIs(token.FUNCTION) {
+		node, ok = p.parseFunc()
+	} else {
+		if !p.peekTokenIs(token.ARROW) {
+			p.setTokenError(p.curToken,
+				"unexpected %q after call (a trailing argument must be a function, e.g. x => ...)",
+				p.curToken.Literal)
+			return nil, false
+		}
+		node, ok = p.parseIdent()
+	}
+	if !ok || node == nil {
+		return nil, false
+	}
+	fn, isFunc := node.(*ast.Func)
+	if !isFunc {
+		p.setTokenError(p.curToken, "invalid trailing function")
+		return nil, false
+	}
+	if fn.Name != nil {
+		p.setTokenError(p.curToken, "trailing function %q must be anonymous", fn.Name.Name)
+		return nil, false
+	}
+	return fn, true
 }
 
 func (p *Parser) parsePipe(firstNode ast.Node) (ast.Node, bool) {
@@ -595,6 +705,20 @@ func (p *Parser) parseGetAttr(objNode ast.Node) (ast.Node, bool) {
 		}
 		return &ast.SetAttr{X: obj, Period: period, Attr: name, OpPos: opPos, Op: opLiteral, Value: right}, true
 	}
+	// A method name followed by a trailing function is a call without parens
+	trailing, ok := p.parseTrailingFunc()
+	if !ok {
+		return nil, false
+	}
+	if trailing != nil {
+		call := &ast.Call{
+			Fun:    name,
+			Lparen: trailing.Pos(),
+			Args:   []ast.Node{trailing},
+			Rparen: trailing.End().Advance(-1),
+		}
+		return &ast.ObjectCall{X: obj, Period: period, Call: call, Optional: false}, true
+	}
 	return &ast.GetAttr{X: obj, Period: period, Attr: name, Optional: false}, true
 }
 
@@ -707,6 +707,75 @@ func TestObjectCallAST(t *testing.T) {
 	assert.Len(t, objCall.Call.Args, 1)
 }
 
+func TestTrailingFunc(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		// Method name without parens
+		{`items.each x => print(x)`, `items.each(x => print(x))`},
+		// After a call with arguments
+		{`items.reduce(0) function(acc, x) { return acc + x }`, `items.reduce(0, function(acc, x) { return acc + x })`},
+		{`f(a) x => x + 1`, `f(a, x => x + 1)`},
+		// An expression body extends to the end of the expression
+		{`items.map x => x * 2 + 1`, `items.map(x => x * 2 + 1)`},
+		{`items.filter x => x > 1 && x < 3`, `items.filter(x => x > 1 && x < 3)`},
+		// A block body ends the trailing function, so chaining continues
+		{`items.map x => { x * 2 }.filter(y => y > 2)`, `items.map(x => { x * 2 }).filter(y => y > 2)`},
+		{`items.each function(x) { print(x) }.keys()`, `items.each(function(x) { print(x) }).keys()`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			program, err := Parse(context.Background(), tt.input, nil)
+			assert.Nil(t, err)
+			assert.Len(t, program.Stmts, 1)
+
+			want, err := Parse(context.Background(), tt.expected, nil)
+			assert.Nil(t, err)
+			assert.Equal(t, want.First().String(), program.First().String())
+		})
+	}
+}
+
+func TestTrailingFuncAST(t *testing.T) {
+	program, err := Parse(context.Background(), "items.each x => x", nil)
+	assert.Nil(t, err)
+
+	objCall, ok := program.First().(*ast.ObjectCall)
+	assert.True(t, ok)
+	assert.Equal(t, "each", objCall.Call.Fun.String())
+	assert.Len(t, objCall.Call.Args, 1)
+	_, ok = objCall.Call.Args[0].(*ast.Func)
+	assert.True(t, ok)
+	assert.Equal(t, objCall.End(), objCall.Call.Args[0].End())
+}
+
+func TestTrailingFuncErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		err   string
+	}{
+		{`items.each x`, `unexpected "x" after call`},
+		{`f(a) b`, `unexpected "b" after call`},
+		{`items.each function named(x) { x }`, `trailing function "named" must be anonymous`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := Parse(context.Background(), tt.input, nil)
+			assert.NotNil(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
+
+func TestTrailingFuncRequiresSameLine(t *testing.T) {
+	program, err := Parse(context.Background(), "items.each\nx => x", nil)
+	assert.Nil(t, err)
+	assert.Len(t, program.Stmts, 2)
+	_, ok := program.First().(*ast.GetAttr)
+	assert.True(t, ok)
+}
+
 func TestNullishCoalescing(t *testing.T) {
 	tests := []struct {
 		input    string
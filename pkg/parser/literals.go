@@ -390,11 +390,19 @@ func (p *Parser) parseFunc() (ast.Node, bool) {
 		return nil, false
 	}
 	lparen := p.curToken.StartPosition
-	defaults, params, restParam := p.parseFuncParams()
+	defaults, params, restParam, types := p.parseFuncParams()
 	if defaults == nil { // parseFuncParams encountered an error
 		return nil, false
 	}
 	rparen := p.curToken.StartPosition
+	// Optional result annotation: -> type
+	var returnType *ast.TypeAnnotation
+	if p.peekTokenIs(token.MINUS_GT) {
+		p.nextToken()
+		if returnType = p.parseTypeAnnotation(); returnType == nil {
+			return nil, false
+		}
+	}
 	if !p.expectPeek("function", token.LBRACE) { // move to the "{"
 		return nil, false
 	}
@@ -403,35 +411,71 @@ func (p *Parser) parseFunc() (ast.Node, bool) {
 		return nil, false
 	}
 	return &ast.Func{
-		Func:      funcPos,
-		Name:      ident,
-		Lparen:    lparen,
-		Params:    params,
-		Defaults:  defaults,
-		RestParam: restParam,
-		Rparen:    rparen,
-		Body:      body,
+		Func:       funcPos,
+		Name:       ident,
+		Lparen:     lparen,
+		Params:     params,
+		Defaults:   defaults,
+		ParamTypes: types,
+		RestParam:  restParam,
+		Rparen:     rparen,
+		ReturnType: returnType,
+		Body:       body,
 	}, true
 }
 
-func (p *Parser) parseFuncParams() (map[string]ast.Expr, []ast.FuncParam, *ast.Ident) {
+// parseTypeAnnotation parses the type name that follows the current ":" or
+// "->" token. Type names are identifiers, or the "function" and "null"
+// keywords.
+func (p *Parser) parseTypeAnnotation() *ast.TypeAnnotation {
+	p.nextToken()
+	switch p.curToken.Type {
+	case token.IDENT, token.FUNCTION, token.NIL:
+	default:
+		p.setTokenError(p.curToken, "expected a type name (got %s)", p.curToken.Literal)
+		return nil
+	}
+	return &ast.TypeAnnotation{NamePos: p.curToken.StartPosition, Name: p.curToken.Literal}
+}
+
+// parseFuncParams parses a parameter list, returning the parameters, their
+// default values, the rest parameter, and the parameter type annotations.
+// The annotations map is nil if no parameter is annotated.
+func (p *Parser) parseFuncParams() (map[string]ast.Expr, []ast.FuncParam, *ast.Ident, map[string]*ast.TypeAnnotation) {
 	// If the next parameter is ")", then there are no parameters
 	if p.peekTokenIs(token.RPAREN) {
 		p.nextToken()
-		return map[string]ast.Expr{}, nil, nil
+		return map[string]ast.Expr{}, nil, nil, nil
 	}
 	defaults := map[string]ast.Expr{}
 	params := make([]ast.FuncParam, 0)
 	var restParam *ast.Ident
+	var types map[string]*ast.TypeAnnotation
+	// If the current token is ":", parse the annotation of the named parameter
+	parseParamType := func(name string) bool {
+		if !p.curTokenIs(token.COLON) {
+			return true
+		}
+		typ := p.parseTypeAnnotation()
+		if typ == nil {
+			return false
+		}
+		if types == nil {
+			types = map[string]*ast.TypeAnnotation{}
+		}
+		types[name] = typ
+		p.nextToken()
+		return true
+	}
 	p.nextToken()
 	p.eatNewlines()
 	for !p.curTokenIs(token.RPAREN) { // Keep going until we find a ")"
 		if p.cancelled() {
-			return nil, nil, nil
+			return nil, nil, nil, nil
 		}
 		for p.curTokenIs(token.NEWLINE) {
 			if err := p.nextToken(); err != nil {
-				return nil, nil, nil
+				return nil, nil, nil, nil
 			}
 		}
 		// After eating newlines, check if we reached the closing paren
@@ -440,27 +484,30 @@ func (p *Parser) parseFuncParams() (map[string]ast.Expr, []ast.FuncParam, *ast.I
 		}
 		if p.curTokenIs(token.EOF) {
 			p.setTokenError(p.prevToken, "unterminated function parameters")
-			return nil, nil, nil
+			return nil, nil, nil, nil
 		}
 
 		// Check for rest parameter: ...ident
 		if p.curTokenIs(token.SPREAD) {
 			if restParam != nil {
 				p.setTokenError(p.curToken, "only one rest parameter is allowed")
-				return nil, nil, nil
+				return nil, nil, nil, nil
 			}
 			p.nextToken() // Move past ...
 			if !p.curTokenIs(token.IDENT) {
 				p.setTokenError(p.curToken, "expected identifier after ... in rest parameter")
-				return nil, nil, nil
+				return nil, nil, nil, nil
 			}
 			restParam = p.newIdent(p.curToken)
 			p.nextToken()
+			if !parseParamType(restParam.Name) {
+				return nil, nil, nil, nil
+			}
 			p.eatNewlines()
 			// Rest parameter must be last
 			if !p.curTokenIs(token.RPAREN) {
 				p.setTokenError(p.curToken, "rest parameter must be the last parameter")
-				return nil, nil, nil
+				return nil, nil, nil, nil
 			}
 			continue
 		}
@@ -469,7 +516,7 @@ func (p *Parser) parseFuncParams() (map[string]ast.Expr, []ast.FuncParam, *ast.I
 		if p.curTokenIs(token.LBRACE) {
 			param := p.parseObjectDestructureParam()
 			if param == nil {
-				return nil, nil, nil
+				return nil, nil, nil, nil
 			}
 			params = append(params, param)
 			if p.curTokenIs(token.COMMA) {
@@ -483,7 +530,7 @@ func (p *Parser) parseFuncParams() (map[string]ast.Expr, []ast.FuncParam, *ast.I
 		if p.curTokenIs(token.LBRACKET) {
 			param := p.parseArrayDestructureParam()
 			if param == nil {
-				return nil, nil, nil
+				return nil, nil, nil, nil
 			}
 			params = append(params, param)
 			if p.curTokenIs(token.COMMA) {
@@ -495,12 +542,15 @@ func (p *Parser) parseFuncParams() (map[string]ast.Expr, []ast.FuncParam, *ast.I
 
 		if !p.curTokenIs(token.IDENT) {
 			p.setTokenError(p.curToken, "expected an identifier (got %s)", p.curToken.Literal)
-			return nil, nil, nil
+			return nil, nil, nil, nil
 		}
 		ident := p.newIdent(p.curToken)
 		params = append(params, ident)
 		if err := p.nextToken(); err != nil {
-			return nil, nil, nil
+			return nil, nil, nil, nil
+		}
+		if !parseParamType(ident.Name) {
+			return nil, nil, nil, nil
 		}
 		// If there is "=expr" after the name then expr is a default value
 		if p.curTokenIs(token.ASSIGN) {
@@ -508,7 +558,7 @@ func (p *Parser) parseFuncParams() (map[string]ast.Expr, []ast.FuncParam, *ast.I
 			p.eatNewlines()
 			expr := p.parseExpression(LOWEST)
 			if expr == nil {
-				return nil, nil, nil
+				return nil, nil, nil, nil
 			}
 			defaults[ident.String()] = expr
 			p.nextToken()
@@ -518,7 +568,7 @@ func (p *Parser) parseFuncParams() (map[string]ast.Expr, []ast.FuncParam, *ast.I
 			p.eatNewlines()
 		}
 	}
-	return defaults, params, restParam
+	return defaults, params, restParam, types
 }
 
 // parseObjectDestructureParam parses an object destructuring parameter: {a, b, c: alias = default}
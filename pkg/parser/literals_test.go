@@ -706,6 +706,107 @@ func TestRestParameterErrors(t *testing.T) {
 	}
 }
 
+func TestFuncTypeAnnotations(t *testing.T) {
+	input := `function add(x: int, y: float = 1.5, ...rest: list) -> float { x + y }`
+	program, err := Parse(context.Background(), input, nil)
+	assert.Nil(t, err)
+
+	fn, ok := program.First().(*ast.Func)
+	assert.True(t, ok)
+	assert.Len(t, fn.Params, 2)
+	assert.Equal(t, fn.ParamTypes["x"].Name, "int")
+	assert.Equal(t, fn.ParamTypes["y"].Name, "float")
+	assert.Equal(t, fn.ParamTypes["rest"].Name, "list")
+	assert.Equal(t, fn.Defaults["y"].String(), "1.5")
+	assert.Equal(t, fn.ReturnType.Name, "float")
+	assert.Equal(t, fn.ReturnType.Pos().ColumnNumber(), 56)
+	assert.Equal(t, fn.String(), "function add(x: int, y: float) -> float { (x + y) }")
+
+	// Keyword type names and anonymous functions
+	program, err = Parse(context.Background(), `let f = function(cb: function) -> null { cb() }`, nil)
+	assert.Nil(t, err)
+	fn = program.First().(*ast.Var).Value.(*ast.Func)
+	assert.Equal(t, fn.ParamTypes["cb"].Name, "function")
+	assert.Equal(t, fn.ReturnType.Name, "null")
+
+	// Unannotated functions have no annotations
+	program, err = Parse(context.Background(), `function f(a) { a }`, nil)
+	assert.Nil(t, err)
+	fn = program.First().(*ast.Func)
+	assert.Nil(t, fn.ParamTypes)
+	assert.Nil(t, fn.ReturnType)
+}
+
+func TestArrowFuncTypeAnnotations(t *testing.T) {
+	program, err := Parse(context.Background(), `(a: int) => a`, nil)
+	assert.Nil(t, err)
+	fn, ok := program.First().(*ast.Func)
+	assert.True(t, ok)
+	assert.Len(t, fn.Params, 1)
+	assert.Equal(t, fn.ParamTypes["a"].Name, "int")
+	assert.Equal(t, fn.ParamTypes["a"].Pos().ColumnNumber(), 5)
+
+	// Mixed annotated, defaulted, and plain parameters
+	program, err = Parse(context.Background(), `let f = (x: int, y: float = 1.5, z) => x + y + z`, nil)
+	assert.Nil(t, err)
+	fn = program.First().(*ast.Var).Value.(*ast.Func)
+	assert.Len(t, fn.Params, 3)
+	assert.Equal(t, fn.ParamTypes["x"].Name, "int")
+	assert.Equal(t, fn.ParamTypes["y"].Name, "float")
+	assert.Nil(t, fn.ParamTypes["z"])
+	assert.Equal(t, fn.Defaults["y"].String(), "1.5")
+	assert.Equal(t, fn.String(), "function(x: int, y: float, z) { return ((x + y) + z) }")
+
+	// Arrow functions as arguments and keyword type names
+	program, err = Parse(context.Background(), `items.map((cb: function) => cb())`, nil)
+	assert.Nil(t, err)
+
+	// Unannotated arrow functions have no annotations
+	program, err = Parse(context.Background(), `(a, b) => a`, nil)
+	assert.Nil(t, err)
+	assert.Nil(t, program.First().(*ast.Func).ParamTypes)
+}
+
+func TestArrowFuncTypeAnnotationErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`(a:) => a`, "expected a type name"},
+		{`(a: 1) => a`, "expected a type name"},
+		{`(a: int)`, "type annotations are only allowed on arrow function parameters"},
+		{`(a: int, b)`, "type annotations are only allowed on arrow function parameters"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := Parse(context.Background(), tt.input, nil)
+			assert.NotNil(t, err)
+			assert.Contains(t, err.Error(), tt.expected)
+		})
+	}
+}
+
+func TestFuncTypeAnnotationErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`function f(x:) {}`, "expected a type name"},
+		{`function f(x: 1) {}`, "expected a type name"},
+		{`function f(x) -> {}`, "expected a type name"},
+		{`function f(x) -> int`, "expected {"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := Parse(context.Background(), tt.input, nil)
+			assert.NotNil(t, err)
+			assert.Contains(t, err.Error(), tt.expected)
+		})
+	}
+}
+
 // =============================================================================
 // DESTRUCTURING PARAMETERS
 // =============================================================================
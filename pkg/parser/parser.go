@@ -256,10 +256,24 @@ func (p *Parser) Parse(ctx context.Context) (*ast.Program, error) {
 		}
 		p.nextToken()
 	}
+	program := &ast.Program{Stmts: statements, Comments: p.comments()}
 	if p.hasErrors() {
-		return &ast.Program{Stmts: statements}, NewErrors(p.errors)
+		return program, NewErrors(p.errors)
 	}
-	return &ast.Program{Stmts: statements}, nil
+	return program, nil
+}
+
+// comments returns the comments the lexer skipped.
+func (p *Parser) comments() []*ast.Comment {
+	lexed := p.l.Comments()
+	if len(lexed) == 0 {
+		return nil
+	}
+	comments := make([]*ast.Comment, len(lexed))
+	for i, c := range lexed {
+		comments[i] = &ast.Comment{Slash: c.Position, Text: c.Text}
+	}
+	return comments
 }
 
 // registerPrefix registers a function for handling a prefix-based statement.
@@ -305,7 +319,7 @@ func (p *Parser) synchronize() {
 		}
 		// Stop at statement-starting keywords
 		switch p.curToken.Type {
-		case token.LET, token.CONST, token.RETURN, token.IF,
+		case token.LET, token.CONST, token.ENUM, token.RETURN, token.IF,
 			token.FUNCTION, token.TRY, token.THROW:
 			return
 		}
@@ -396,6 +410,10 @@ func (p *Parser) parseStatement() ast.Node {
 		if s := p.parseConst(); s != nil {
 			stmt = s
 		}
+	case token.ENUM:
+		if s := p.parseEnum(); s != nil {
+			stmt = s
+		}
 	case token.RETURN:
 		if s := p.parseReturn(); s != nil {
 			stmt = s
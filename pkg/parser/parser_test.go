@@ -604,3 +604,18 @@ func TestIdentPreservesName(t *testing.T) {
 		})
 	}
 }
+
+func TestComments(t *testing.T) {
+	input := `// Package doc.
+
+// add returns the sum.
+function add(a, b) { return a + b } // trailing
+`
+	program, err := Parse(context.Background(), input, nil)
+	assert.Nil(t, err)
+	assert.Len(t, program.Stmts, 1)
+	assert.Len(t, program.Comments, 3)
+	assert.Equal(t, program.Comments[0].Text, "// Package doc.")
+	assert.Equal(t, program.Comments[1].Pos().LineNumber(), 3)
+	assert.Equal(t, program.Comments[2].Text, "// trailing")
+}
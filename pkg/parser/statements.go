@@ -8,6 +8,7 @@ import (
 // Statement parsing methods for the Parser.
 // This file contains methods that parse statement constructs:
 // - Variable declarations (let, const)
+// - Enum declarations
 // - Destructuring patterns
 // - Return, throw statements
 // - Assignment statements
@@ -31,7 +32,15 @@ func (p *Parser) parseLet() ast.Node {
 		return nil
 	}
 	idents := []*ast.Ident{p.newIdent(p.curToken)}
-	for p.peekTokenIs(token.COMMA) {
+	// Optional type annotation: let x: int = 1
+	var typ *ast.TypeAnnotation
+	if p.peekTokenIs(token.COLON) {
+		p.nextToken()
+		if typ = p.parseTypeAnnotation(); typ == nil {
+			return nil
+		}
+	}
+	for typ == nil && p.peekTokenIs(token.COMMA) {
 		p.nextToken()
 		if !p.expectPeek("let statement", token.IDENT) {
 			return nil
@@ -49,7 +58,7 @@ func (p *Parser) parseLet() ast.Node {
 	if len(idents) > 1 {
 		return &ast.MultiVar{Let: letPos, Names: idents, Value: value}
 	}
-	return &ast.Var{Let: letPos, Name: idents[0], Value: value}
+	return &ast.Var{Let: letPos, Name: idents[0], Type: typ, Value: value}
 }
 
 func (p *Parser) parseObjectDestructure(letPos token.Position) ast.Node {
@@ -235,6 +244,13 @@ func (p *Parser) parseConst() *ast.Const {
 		return nil
 	}
 	ident := p.newIdent(p.curToken)
+	var typ *ast.TypeAnnotation
+	if p.peekTokenIs(token.COLON) {
+		p.nextToken()
+		if typ = p.parseTypeAnnotation(); typ == nil {
+			return nil
+		}
+	}
 	if !p.expectPeek("const statement", token.ASSIGN) {
 		return nil
 	}
@@ -243,7 +259,58 @@ func (p *Parser) parseConst() *ast.Const {
 	if value == nil {
 		return nil
 	}
-	return &ast.Const{Const: constPos, Name: ident, Value: value}
+	return &ast.Const{Const: constPos, Name: ident, Type: typ, Value: value}
+}
+
+// parseEnum parses an enum declaration: enum Name { A, B, C }. Members may
+// be separated by commas, newlines, or both, and a trailing comma is allowed.
+func (p *Parser) parseEnum() *ast.Enum {
+	enumPos := p.curToken.StartPosition
+	if !p.expectPeek("enum statement", token.IDENT) {
+		return nil
+	}
+	name := p.newIdent(p.curToken)
+	if !p.expectPeek("enum statement", token.LBRACE) {
+		return nil
+	}
+	lbrace := p.curToken.StartPosition
+	p.nextToken() // Move past '{'
+	p.eatNewlines()
+
+	var members []*ast.Ident
+	for !p.curTokenIs(token.RBRACE) {
+		if p.cancelled() {
+			return nil
+		}
+		if !p.curTokenIs(token.IDENT) {
+			p.setTokenError(p.curToken, "expected identifier in enum %q", name.Name)
+			return nil
+		}
+		members = append(members, p.newIdent(p.curToken))
+		p.nextToken()
+		separated := p.curTokenIs(token.NEWLINE)
+		p.eatNewlines()
+		if p.curTokenIs(token.COMMA) {
+			separated = true
+			p.nextToken()
+			p.eatNewlines()
+		}
+		if !separated && !p.curTokenIs(token.RBRACE) {
+			p.setTokenError(p.curToken, "expected ',' or '}' in enum %q", name.Name)
+			return nil
+		}
+	}
+	if len(members) == 0 {
+		p.setTokenError(p.curToken, "enum %q must have at least one member", name.Name)
+		return nil
+	}
+	return &ast.Enum{
+		Enum:    enumPos,
+		Name:    name,
+		Lbrace:  lbrace,
+		Members: members,
+		Rbrace:  p.curToken.StartPosition,
+	}
 }
 
 // parseAssignmentValue parses the right hand side of an assignment statement.
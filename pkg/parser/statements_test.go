@@ -169,6 +169,73 @@ func TestConstAST(t *testing.T) {
 	assert.Equal(t, 3.14, val.Value)
 }
 
+func TestEnum(t *testing.T) {
+	tests := []struct {
+		input   string
+		name    string
+		members []string
+	}{
+		{"enum Color { Red, Green, Blue }", "Color", []string{"Red", "Green", "Blue"}},
+		{"enum One { A }", "One", []string{"A"}},
+		{"enum Trailing { A, B, }", "Trailing", []string{"A", "B"}},
+		{"enum Lines {\n  A\n  B\n}", "Lines", []string{"A", "B"}},
+		{"enum Mixed {\n  A,\n  B,\n}", "Mixed", []string{"A", "B"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			program, err := Parse(context.Background(), tt.input, nil)
+			assert.Nil(t, err)
+			assert.Len(t, program.Stmts, 1)
+
+			stmt, ok := program.First().(*ast.Enum)
+			assert.True(t, ok)
+			assert.Equal(t, tt.name, stmt.Name.Name)
+			var members []string
+			for _, member := range stmt.Members {
+				members = append(members, member.Name)
+			}
+			assert.Equal(t, tt.members, members)
+		})
+	}
+}
+
+func TestEnumAST(t *testing.T) {
+	program, err := Parse(context.Background(), "enum Color { Red, Green }", nil)
+	assert.Nil(t, err)
+
+	stmt, ok := program.First().(*ast.Enum)
+	assert.True(t, ok)
+	assert.Equal(t, 0, stmt.Pos().Column)
+	assert.Equal(t, 11, stmt.Lbrace.Column)
+	assert.Equal(t, 24, stmt.Rbrace.Column)
+	assert.Equal(t, 25, stmt.End().Column)
+	assert.Equal(t, "enum Color { Red, Green }", stmt.String())
+}
+
+func TestBadEnumStatement(t *testing.T) {
+	inputs := []struct {
+		input string
+		err   string
+	}{
+		{"enum", "parse error: unexpected end of file while parsing enum statement (expected identifier)"},
+		{"enum Color", "parse error: unexpected end of file while parsing enum statement (expected {)"},
+		{"enum Color {}", `parse error: enum "Color" must have at least one member`},
+		{"enum Color { Red Green }", `parse error: expected ',' or '}' in enum "Color"`},
+		{"enum Color { Red, 1 }", `parse error: expected identifier in enum "Color"`},
+		{"enum Color { Red", `parse error: expected ',' or '}' in enum "Color"`},
+		{"enum Color { Red,", `parse error: expected identifier in enum "Color"`},
+	}
+	for _, tt := range inputs {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := Parse(context.Background(), tt.input, nil)
+			assert.NotNil(t, err)
+			e, ok := err.(ParserError)
+			assert.True(t, ok)
+			assert.Equal(t, tt.err, e.Error())
+		})
+	}
+}
+
 func TestBadVarConstStatement(t *testing.T) {
 	inputs := []struct {
 		input string
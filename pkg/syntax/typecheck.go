@@ -0,0 +1,511 @@
+package syntax
+
+import (
+	"fmt"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/ast"
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+)
+
+// TypeChecker is a Validator that checks optional type annotations, as in
+// "let x: int = 1" or "function add(a: int, b: int) -> int". Checking is
+// gradual: only values whose type is known at compile time are checked, and
+// untyped code is never reported. The checker reports:
+//
+//   - annotations that name an unknown type
+//   - declarations and assignments of a value of the wrong type to an
+//     annotated variable or constant
+//   - arguments of the wrong type passed to annotated parameters of a
+//     function called by name
+//   - results of the wrong type returned from an annotated function
+//
+// Types are known for literals, annotated variables and parameters,
+// constants, calls to functions with an annotated result, and arithmetic
+// and comparisons on values of known types. An int is accepted where a
+// float is expected, and the type "any" accepts every value. A rest
+// parameter's annotation is the type of each of its arguments.
+//
+// Annotations have no effect at runtime, so the checker is opt-in: add it
+// with WithValidator, or use the risor.WithTypeCheck option.
+type TypeChecker struct {
+	globals map[string]string
+}
+
+// NewTypeChecker creates a type annotation checker. The globals map gives
+// the types of globals supplied by the host, such as those declared in an
+// env schema, and may be nil.
+func NewTypeChecker(globals map[string]string) *TypeChecker {
+	return &TypeChecker{globals: globals}
+}
+
+// Validate checks the type annotations in the program.
+func (tc *TypeChecker) Validate(program *ast.Program) []ValidationError {
+	c := &typeCheck{}
+	c.push()
+	for name, typ := range tc.globals {
+		if !annotationTypes[typ] {
+			typ = ""
+		}
+		c.declare(name, binding{typ: typ})
+	}
+	c.push()
+	c.statements(program.Stmts)
+	return c.errors
+}
+
+// anyType is the annotation that accepts a value of any type.
+const anyType = "any"
+
+// annotationTypes are the type names accepted in annotations.
+var annotationTypes = map[string]bool{
+	anyType:                 true,
+	string(object.BOOL):     true,
+	string(object.BYTE):     true,
+	string(object.BYTES):    true,
+	string(object.ERROR):    true,
+	string(object.FLOAT):    true,
+	string(object.FUNCTION): true,
+	string(object.INT):      true,
+	string(object.LIST):     true,
+	string(object.MAP):      true,
+	string(object.MODULE):   true,
+	string(object.NIL):      true,
+	string(object.RANGE):    true,
+	string(object.STRING):   true,
+	string(object.TIME):     true,
+	string(object.TUPLE):    true,
+}
+
+// binding is what the checker knows about a name in scope.
+type binding struct {
+	typ string    // declared or inferred type; "" if unknown
+	fn  *ast.Func // the function the name refers to, if known
+}
+
+type typeScope struct {
+	parent   *typeScope
+	bindings map[string]binding
+}
+
+// typeCheck holds the state of one TypeChecker run.
+type typeCheck struct {
+	scope  *typeScope
+	result string // annotated result type of the enclosing function
+	errors []ValidationError
+}
+
+func (c *typeCheck) errorf(node ast.Node, format string, args ...any) {
+	c.errors = append(c.errors, ValidationError{
+		Message:  fmt.Sprintf(format, args...),
+		Node:     node,
+		Position: node.Pos(),
+	})
+}
+
+func (c *typeCheck) push() {
+	c.scope = &typeScope{parent: c.scope, bindings: map[string]binding{}}
+}
+
+func (c *typeCheck) pop() {
+	c.scope = c.scope.parent
+}
+
+func (c *typeCheck) declare(name string, b binding) {
+	c.scope.bindings[name] = b
+}
+
+// lookup returns the innermost binding of name and the scope it is in.
+func (c *typeCheck) lookup(name string) (binding, *typeScope) {
+	for s := c.scope; s != nil; s = s.parent {
+		if b, ok := s.bindings[name]; ok {
+			return b, s
+		}
+	}
+	return binding{}, nil
+}
+
+// annotation returns the type an annotation names, or "" for no annotation
+// or an unknown type, which is reported.
+func (c *typeCheck) annotation(typ *ast.TypeAnnotation) string {
+	if typ == nil {
+		return ""
+	}
+	name := typ.Name
+	if name == "nil" {
+		name = string(object.NIL)
+	}
+	if !annotationTypes[name] {
+		c.errorf(typ, "unknown type %q", typ.Name)
+		return ""
+	}
+	return name
+}
+
+// statements checks a list of statements in the current scope. Named
+// functions are declared first, since they can be called before their
+// definition.
+func (c *typeCheck) statements(stmts []ast.Node) {
+	for _, stmt := range stmts {
+		if fn, ok := stmt.(*ast.Func); ok && fn.Name != nil {
+			c.declare(fn.Name.Name, binding{typ: string(object.FUNCTION), fn: fn})
+		}
+	}
+	for _, stmt := range stmts {
+		c.check(stmt)
+	}
+}
+
+// check checks a node and everything beneath it.
+func (c *typeCheck) check(node ast.Node) {
+	if node == nil {
+		return
+	}
+	ast.Inspect(node, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.Block:
+			c.push()
+			c.statements(n.Stmts)
+			c.pop()
+			return false
+		case *ast.Func:
+			c.function(n)
+			return false
+		case *ast.Var:
+			c.check(n.Value)
+			typ := c.annotation(n.Type)
+			c.expect(typ, n.Value, "declaration of "+n.Name.Name)
+			c.declare(n.Name.Name, binding{typ: typ})
+			return false
+		case *ast.Const:
+			c.check(n.Value)
+			typ := c.annotation(n.Type)
+			c.expect(typ, n.Value, "declaration of "+n.Name.Name)
+			// Constants never change, so their type is known without an
+			// annotation, and so is the function they refer to.
+			b := binding{typ: typ}
+			if typ == "" {
+				b.typ = c.typeOf(n.Value)
+			}
+			if fn, ok := n.Value.(*ast.Func); ok {
+				b.fn = fn
+			}
+			c.declare(n.Name.Name, b)
+			return false
+		case *ast.MultiVar:
+			c.check(n.Value)
+			for _, name := range n.Names {
+				c.declare(name.Name, binding{})
+			}
+			return false
+		case *ast.ObjectDestructure:
+			c.check(n.Value)
+			c.declareBindings(n.Bindings)
+			return false
+		case *ast.ArrayDestructure:
+			c.check(n.Value)
+			c.declareElements(n.Elements)
+			return false
+		case *ast.Enum:
+			c.declare(n.Name.Name, binding{})
+			return false
+		case *ast.Assign:
+			if n.Index != nil {
+				c.check(n.Index)
+			}
+			c.check(n.Value)
+			c.assign(n)
+			return false
+		case *ast.Return:
+			c.check(n.Value)
+			if c.result != "" {
+				if n.Value == nil {
+					if !assignable(c.result, string(object.NIL)) {
+						c.errorf(n, "missing return value (function returns %s)", c.result)
+					}
+				} else {
+					c.expect(c.result, n.Value, "return value")
+				}
+			}
+			return false
+		case *ast.Try:
+			c.check(n.Body)
+			if n.CatchBlock != nil {
+				c.push()
+				if n.CatchIdent != nil {
+					c.declare(n.CatchIdent.Name, binding{})
+				}
+				c.statements(n.CatchBlock.Stmts)
+				c.pop()
+			}
+			if n.FinallyBlock != nil {
+				c.check(n.FinallyBlock)
+			}
+			return false
+		case *ast.Call:
+			c.call(n)
+		}
+		return true
+	})
+}
+
+func (c *typeCheck) declareBindings(bindings []ast.DestructureBinding) {
+	for _, b := range bindings {
+		c.check(b.Default)
+		name := b.Alias
+		if name == "" {
+			name = b.Key
+		}
+		c.declare(name, binding{})
+	}
+}
+
+func (c *typeCheck) declareElements(elements []ast.ArrayDestructureElement) {
+	for _, e := range elements {
+		c.check(e.Default)
+		c.declare(e.Name.Name, binding{})
+	}
+}
+
+// function checks a function definition: its parameter defaults, its body,
+// and the value its body ends with, which is its result.
+func (c *typeCheck) function(fn *ast.Func) {
+	outer := c.result
+	c.result = c.annotation(fn.ReturnType)
+	c.push()
+	defer func() {
+		c.pop()
+		c.result = outer
+	}()
+
+	if fn.Name != nil {
+		c.declare(fn.Name.Name, binding{typ: string(object.FUNCTION), fn: fn})
+	}
+	for _, param := range fn.Params {
+		switch param := param.(type) {
+		case *ast.Ident:
+			typ := c.annotation(fn.ParamTypes[param.Name])
+			if def := fn.Defaults[param.Name]; def != nil {
+				c.check(def)
+				c.expect(typ, def, "default value of "+param.Name)
+			}
+			c.declare(param.Name, binding{typ: typ})
+		case *ast.ObjectDestructureParam:
+			c.declareBindings(param.Bindings)
+		case *ast.ArrayDestructureParam:
+			c.declareElements(param.Elements)
+		}
+	}
+	if fn.RestParam != nil {
+		c.annotation(fn.ParamTypes[fn.RestParam.Name])
+		c.declare(fn.RestParam.Name, binding{typ: string(object.LIST)})
+	}
+	if fn.Body == nil {
+		return
+	}
+	stmts := fn.Body.Stmts
+	c.statements(stmts)
+	// A function returns the value of its last statement if that is an
+	// expression
+	if c.result != "" && len(stmts) > 0 {
+		if last, ok := stmts[len(stmts)-1].(ast.Expr); ok {
+			c.expect(c.result, last, "result of "+funcName(fn))
+		}
+	}
+}
+
+// assign checks an assignment to an annotated variable. Assigning to an
+// unannotated name forgets the function it referred to.
+func (c *typeCheck) assign(n *ast.Assign) {
+	if n.Name == nil {
+		return
+	}
+	b, scope := c.lookup(n.Name.Name)
+	if scope == nil {
+		return
+	}
+	if n.Op == "=" {
+		c.expect(b.typ, n.Value, "assignment to "+n.Name.Name)
+	}
+	if b.fn != nil {
+		b.fn = nil
+		scope.bindings[n.Name.Name] = b
+	}
+}
+
+// call checks the arguments of a call to a function known by name against
+// the function's parameter annotations.
+func (c *typeCheck) call(n *ast.Call) {
+	ident, ok := n.Fun.(*ast.Ident)
+	if !ok {
+		return
+	}
+	b, _ := c.lookup(ident.Name)
+	fn := b.fn
+	if fn == nil || (fn.ParamTypes == nil && fn.RestParam == nil) {
+		return
+	}
+	var restType string
+	if fn.RestParam != nil {
+		restType = annotationName(fn.ParamTypes[fn.RestParam.Name])
+	}
+	for i, arg := range n.Args {
+		expr, ok := arg.(ast.Expr)
+		if !ok {
+			return
+		}
+		if _, ok := arg.(*ast.Spread); ok {
+			// Arguments after a spread go to unknown parameters
+			return
+		}
+		context := fmt.Sprintf("argument %d to %s", i+1, ident.Name)
+		if i < len(fn.Params) {
+			if param, ok := fn.Params[i].(*ast.Ident); ok {
+				c.expectType(annotationName(fn.ParamTypes[param.Name]), c.typeOf(expr), expr, context)
+			}
+			continue
+		}
+		c.expectType(restType, c.typeOf(expr), expr, context)
+	}
+}
+
+// annotationName returns the type an annotation names without reporting
+// unknown types, which are reported where the annotation is declared.
+func annotationName(typ *ast.TypeAnnotation) string {
+	if typ == nil {
+		return ""
+	}
+	if typ.Name == "nil" {
+		return string(object.NIL)
+	}
+	if !annotationTypes[typ.Name] {
+		return ""
+	}
+	return typ.Name
+}
+
+// expect reports an error if expr is known to have a type other than want.
+func (c *typeCheck) expect(want string, expr ast.Expr, context string) {
+	if want == "" || expr == nil {
+		return
+	}
+	c.expectType(want, c.typeOf(expr), expr, context)
+}
+
+func (c *typeCheck) expectType(want, got string, node ast.Node, context string) {
+	if assignable(want, got) {
+		return
+	}
+	c.errorf(node, "cannot use %s (type %s) as %s in %s", describe(node), got, want, context)
+}
+
+// assignable reports whether a value of type got may be used where want is
+// expected. Unknown types are always assignable.
+func assignable(want, got string) bool {
+	switch {
+	case want == "" || got == "" || want == anyType || got == anyType:
+		return true
+	case want == got:
+		return true
+	case want == string(object.FLOAT) && got == string(object.INT):
+		return true
+	}
+	return false
+}
+
+// typeOf returns the type of an expression if it is known at compile time,
+// or "" if it is not.
+func (c *typeCheck) typeOf(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.Int:
+		return string(object.INT)
+	case *ast.Float:
+		return string(object.FLOAT)
+	case *ast.String:
+		return string(object.STRING)
+	case *ast.Bool:
+		return string(object.BOOL)
+	case *ast.Nil:
+		return string(object.NIL)
+	case *ast.List:
+		return string(object.LIST)
+	case *ast.Tuple:
+		return string(object.TUPLE)
+	case *ast.Map:
+		return string(object.MAP)
+	case *ast.Func:
+		return string(object.FUNCTION)
+	case *ast.Ident:
+		b, _ := c.lookup(e.Name)
+		return b.typ
+	case *ast.Prefix:
+		switch e.Op {
+		case "!", "not":
+			return string(object.BOOL)
+		case "-":
+			if t := c.typeOf(e.X); isNumeric(t) {
+				return t
+			}
+		}
+	case *ast.Infix:
+		return infixType(e.Op, c.typeOf(e.X), c.typeOf(e.Y))
+	case *ast.In, *ast.NotIn:
+		return string(object.BOOL)
+	case *ast.Call:
+		if ident, ok := e.Fun.(*ast.Ident); ok {
+			if b, _ := c.lookup(ident.Name); b.fn != nil {
+				return annotationName(b.fn.ReturnType)
+			}
+		}
+	}
+	return ""
+}
+
+// infixType returns the type of a binary operation on operands of the
+// given types, or "" if it is not known.
+func infixType(op, x, y string) string {
+	switch op {
+	case "==", "!=", "<", ">", "<=", ">=":
+		return string(object.BOOL)
+	case "+":
+		if x == y && (x == string(object.STRING) || x == string(object.LIST)) {
+			return x
+		}
+		return numericType(x, y)
+	case "-", "*", "/", "%":
+		return numericType(x, y)
+	}
+	return ""
+}
+
+// numericType returns the type of arithmetic on two numbers: int if both
+// are ints and float otherwise.
+func numericType(x, y string) string {
+	if !isNumeric(x) || !isNumeric(y) {
+		return ""
+	}
+	if x == string(object.INT) && y == string(object.INT) {
+		return string(object.INT)
+	}
+	return string(object.FLOAT)
+}
+
+func isNumeric(typ string) bool {
+	return typ == string(object.INT) || typ == string(object.FLOAT)
+}
+
+func funcName(fn *ast.Func) string {
+	if fn.Name != nil {
+		return fn.Name.Name
+	}
+	return "function"
+}
+
+// describe returns source text for a node, shortened for error messages.
+func describe(node ast.Node) string {
+	const maxLen = 40
+	s := node.String()
+	if len(s) > maxLen {
+		s = s[:maxLen-3] + "..."
+	}
+	return s
+}
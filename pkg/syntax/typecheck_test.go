@@ -0,0 +1,104 @@
+package syntax
+
+import (
+	"testing"
+
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+func TestTypeChecker_Errors(t *testing.T) {
+	tests := []struct {
+		source  string
+		wantErr string
+	}{
+		{`let x: int = "a"`, `cannot use "a" (type string) as int in declaration of x`},
+		{`const s: string = 1 + 2`, `cannot use (1 + 2) (type int) as string in declaration of s`},
+		{`let x: float = true`, `as float in declaration of x`},
+		{`let x: int = 1; x = "a"`, `as int in assignment to x`},
+		{`let x: intt = 1`, `unknown type "intt"`},
+		{`function f(a: strng) {}`, `unknown type "strng"`},
+		{`function f() -> lst { [] }`, `unknown type "lst"`},
+		{`function add(a: int, b: int) { a + b }; add(1, "2")`, `cannot use "2" (type string) as int in argument 2 to add`},
+		{`add(1.5, 2); function add(a: int, b: int) { a + b }`, `as int in argument 1 to add`},
+		{`const f = function(s: string) { s }; f(1)`, `as string in argument 1 to f`},
+		{`const f = (s: string) => s; f(1)`, `as string in argument 1 to f`},
+		{`const f = (n: int = "x") => n`, `as int in default value of n`},
+		{`function sum(...n: int) { n }; sum(1, 2, "3")`, `as int in argument 3 to sum`},
+		{`function f(a: int = "x") { a }`, `as int in default value of a`},
+		{`function f() -> int { return "a" }`, `as int in return value`},
+		{`function f() -> int { return }`, `missing return value (function returns int)`},
+		{`function f() -> string { 42 }`, `as string in result of f`},
+		{`function f() -> int { const s = "a"; return s + s }`, `(type string) as int in return value`},
+		{`function n() -> int { 1 }; let s: string = n()`, `(type int) as string in declaration of s`},
+		{`function f(a: int) -> bool { a > 1 }; let x: int = f(2)`, `(type bool) as int`},
+		{`let x: int = -1.5`, `(type float) as int`},
+		{`let ok: int = !true`, `(type bool) as int`},
+		{`function f(x: string) { let y: int = x }`, `(type string) as int in declaration of y`},
+		{`let g = function() { let z: map = [] }`, `(type list) as map in declaration of z`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			errs := NewTypeChecker(nil).Validate(parse(t, tt.source))
+			assert.Len(t, errs, 1)
+			assert.Contains(t, errs[0].Message, tt.wantErr)
+		})
+	}
+}
+
+func TestTypeChecker_Valid(t *testing.T) {
+	tests := []string{
+		// Untyped code is never reported
+		`let x = 1; x = "a"`,
+		`function f(a) { a }; f(1); f("a")`,
+		// Values of unknown type are not reported
+		`function f(a: int) { a }; let v = "a"; v = 1; f(v)`,
+		`function f(a: int) { a }; f(len("abc"))`,
+		`let x: int = len("abc")`,
+		// Compatible types
+		`let x: float = 1`,
+		`let x: any = "a"; x = 1`,
+		`let x: null = nil`,
+		`let f: function = function() {}`,
+		`let x: int = 1; x = 2; x += 1`,
+		`function add(a: int, b: float = 1.5) -> float { a + b }; add(1); add(1, 2)`,
+		`function f(...n: int) { n }; f(1, 2, ...[3])`,
+		`function f() -> int { return 1 }`,
+		`function f() -> null { return }`,
+		`function f() -> int { if (true) { 1 } else { 2 } }`,
+		`function f() -> list { let x = 1; return [x] }`,
+		// Shadowing hides annotations
+		`let x: int = 1; function f(x) { x = "a" }`,
+		`let x: int = 1; function f() { let x = "a"; x = "b" }`,
+		`let e: int = 1; try { throw error("x") } catch e { e = "caught" }`,
+		`function f(a: int) { a }; function g(f) { f("a") }`,
+		`let x: string = "a"; let { x } = {x: 1}; x = 2`,
+		// Reassigned functions are no longer checked
+		`function f(a: int) { a }; f = function(s) { s }; f("a")`,
+		// Keyword and builtin type names
+		`function f(cb: function, m: map, b: bytes) -> nil { nil }`,
+	}
+	for _, source := range tests {
+		t.Run(source, func(t *testing.T) {
+			errs := NewTypeChecker(nil).Validate(parse(t, source))
+			assert.Len(t, errs, 0)
+		})
+	}
+}
+
+func TestTypeChecker_Position(t *testing.T) {
+	errs := NewTypeChecker(nil).Validate(parse(t, "const a = 1\nlet b: string = a + 2"))
+	assert.Len(t, errs, 1)
+	assert.Equal(t, errs[0].Position.LineNumber(), 2)
+	assert.Equal(t, errs[0].Position.ColumnNumber(), 17)
+}
+
+func TestTypeChecker_Globals(t *testing.T) {
+	checker := NewTypeChecker(map[string]string{"price": "float", "user": "map", "data": "any"})
+
+	errs := checker.Validate(parse(t, `let label: string = price * 2`))
+	assert.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Message, "(type float) as string")
+
+	errs = checker.Validate(parse(t, `let total: float = price * 2; let d: int = data; let price = "x"`))
+	assert.Len(t, errs, 0)
+}
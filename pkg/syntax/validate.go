@@ -27,7 +27,7 @@ func (v *SyntaxValidator) Validate(program *ast.Program) []ValidationError {
 
 func (v *SyntaxValidator) checkNode(node ast.Node) *ValidationError {
 	switch n := node.(type) {
-	case *ast.Var, *ast.Const, *ast.MultiVar:
+	case *ast.Var, *ast.Const, *ast.MultiVar, *ast.Enum:
 		if v.config.DisallowVariableDecl {
 			return &ValidationError{
 				Message:  "variable declarations are not allowed",
@@ -0,0 +1,181 @@
+package testing
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/bytecode"
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+	"github.com/deepnoodle-ai/risor/v2/pkg/vm"
+)
+
+// maxBenchIterations caps the iteration count chosen by automatic scaling.
+const maxBenchIterations = 1_000_000_000
+
+// BenchConfig holds configuration for running benchmarks.
+type BenchConfig struct {
+	// RunPattern filters benchmarks to run by name regex.
+	RunPattern string
+
+	// Time is the target running time of each benchmark. The iteration count
+	// grows until a run takes at least this long. Default is one second.
+	Time time.Duration
+
+	// Iterations runs each benchmark exactly this many times instead of
+	// scaling the count to Time.
+	Iterations int
+}
+
+// BenchResult holds the outcome of a single benchmark function.
+type BenchResult struct {
+	Name     string        // Benchmark function name (e.g., "bench_sort")
+	N        int           // Iterations in the measured run
+	Duration time.Duration // Total time of the measured run
+	Allocs   uint64        // Go heap allocations during the measured run
+	Bytes    uint64        // Bytes allocated during the measured run
+	Error    error         // Error if the benchmark failed
+}
+
+// NsPerOp returns the average time of one iteration in nanoseconds.
+func (r *BenchResult) NsPerOp() int64 {
+	if r.N <= 0 {
+		return 0
+	}
+	return r.Duration.Nanoseconds() / int64(r.N)
+}
+
+// AllocsPerOp returns the average number of allocations per iteration. The
+// count includes allocations made by the VM itself, so it approximates the
+// number of objects a script creates rather than counting them exactly.
+func (r *BenchResult) AllocsPerOp() int64 {
+	if r.N <= 0 {
+		return 0
+	}
+	return int64(r.Allocs) / int64(r.N)
+}
+
+// BytesPerOp returns the average number of bytes allocated per iteration.
+func (r *BenchResult) BytesPerOp() int64 {
+	if r.N <= 0 {
+		return 0
+	}
+	return int64(r.Bytes) / int64(r.N)
+}
+
+// DiscoverBenchFunctions finds all bench_* functions in compiled code.
+func DiscoverBenchFunctions(code *bytecode.Code) []string {
+	var benchmarks []string
+	for _, name := range code.FunctionNames() {
+		if strings.HasPrefix(name, "bench_") {
+			benchmarks = append(benchmarks, name)
+		}
+	}
+	return benchmarks
+}
+
+// RunBenchmarks runs the bench_* functions in compiled code, each in a
+// fresh VM with the given globals. A benchmark function takes no arguments
+// and is called repeatedly. As with Go's testing.B, the iteration count
+// starts at one and grows until a run lasts at least cfg.Time.
+func RunBenchmarks(ctx context.Context, code *bytecode.Code, env map[string]any, cfg *BenchConfig) ([]*BenchResult, error) {
+	if cfg == nil {
+		cfg = &BenchConfig{}
+	}
+	var runRe *regexp.Regexp
+	if cfg.RunPattern != "" {
+		var err error
+		runRe, err = regexp.Compile(cfg.RunPattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid run pattern: %w", err)
+		}
+	}
+	var results []*BenchResult
+	for _, name := range DiscoverBenchFunctions(code) {
+		if runRe != nil && !runRe.MatchString(name) {
+			continue
+		}
+		results = append(results, runBenchmark(ctx, code, env, name, cfg))
+	}
+	return results, nil
+}
+
+// runBenchmark executes a single benchmark function.
+func runBenchmark(ctx context.Context, code *bytecode.Code, env map[string]any, name string, cfg *BenchConfig) *BenchResult {
+	result := &BenchResult{Name: name}
+
+	// Run the file to populate globals, then look up the function
+	machine, err := vm.New(code, vm.WithGlobals(env))
+	if err != nil {
+		result.Error = err
+		return result
+	}
+	if err := machine.Run(ctx); err != nil {
+		result.Error = err
+		return result
+	}
+	fn, err := machine.Get(name)
+	if err != nil {
+		result.Error = fmt.Errorf("benchmark function %q not found: %w", name, err)
+		return result
+	}
+	closure, ok := fn.(*object.Closure)
+	if !ok {
+		result.Error = fmt.Errorf("benchmark function %q is not a function (got %s)", name, fn.Type())
+		return result
+	}
+
+	run := func(n int) error {
+		runtime.GC()
+		var before, after runtime.MemStats
+		runtime.ReadMemStats(&before)
+		start := time.Now()
+		for i := 0; i < n; i++ {
+			if _, err := machine.Call(ctx, closure, nil); err != nil {
+				return err
+			}
+		}
+		result.Duration = time.Since(start)
+		runtime.ReadMemStats(&after)
+		result.N = n
+		result.Allocs = after.Mallocs - before.Mallocs
+		result.Bytes = after.TotalAlloc - before.TotalAlloc
+		return nil
+	}
+
+	if cfg.Iterations > 0 {
+		result.Error = run(cfg.Iterations)
+		return result
+	}
+	target := cfg.Time
+	if target <= 0 {
+		target = time.Second
+	}
+	for n := 1; ; n = nextBenchIterations(n, result.Duration, target) {
+		if err := run(n); err != nil {
+			result.Error = err
+			return result
+		}
+		if result.Duration >= target || n >= maxBenchIterations {
+			return result
+		}
+	}
+}
+
+// nextBenchIterations predicts the iteration count needed for a run to last
+// the target time, given that n iterations took elapsed. Like Go's
+// testing.B, it overshoots the prediction by a fifth and grows by at least
+// one and at most a hundredfold per step.
+func nextBenchIterations(n int, elapsed, target time.Duration) int {
+	next := int64(maxBenchIterations)
+	if ns := elapsed.Nanoseconds(); ns > 0 {
+		next = target.Nanoseconds() * int64(n) / ns
+	}
+	next += next / 5
+	next = min(next, 100*int64(n))
+	next = max(next, int64(n)+1)
+	return int(min(next, maxBenchIterations))
+}
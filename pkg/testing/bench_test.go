@@ -0,0 +1,89 @@
+package testing
+
+import (
+	"context"
+	stdt "testing"
+	"time"
+
+	"github.com/deepnoodle-ai/risor/v2"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+const benchSource = `
+let calls = 0
+
+function bench_count() {
+    calls += 1
+}
+
+function bench_list() {
+    [1, 2, 3]
+}
+
+function bench_fail() {
+    throw "boom"
+}
+
+function helper() {}
+`
+
+func TestDiscoverBenchFunctions(t *stdt.T) {
+	env := risor.Builtins()
+	code, err := risor.Compile(context.Background(), benchSource, risor.WithEnv(env))
+	assert.Nil(t, err)
+	assert.Equal(t, DiscoverBenchFunctions(code), []string{"bench_count", "bench_list", "bench_fail"})
+}
+
+func TestRunBenchmarks_Iterations(t *stdt.T) {
+	env := risor.Builtins()
+	code, err := risor.Compile(context.Background(), benchSource, risor.WithEnv(env))
+	assert.Nil(t, err)
+
+	results, err := RunBenchmarks(context.Background(), code, env, &BenchConfig{
+		RunPattern: "count|fail",
+		Iterations: 50,
+	})
+	assert.Nil(t, err)
+	assert.Len(t, results, 2)
+
+	assert.Equal(t, results[0].Name, "bench_count")
+	assert.Nil(t, results[0].Error)
+	assert.Equal(t, results[0].N, 50)
+	assert.True(t, results[0].NsPerOp() > 0)
+
+	assert.Equal(t, results[1].Name, "bench_fail")
+	assert.NotNil(t, results[1].Error)
+}
+
+func TestRunBenchmarks_Scaling(t *stdt.T) {
+	env := risor.Builtins()
+	code, err := risor.Compile(context.Background(), benchSource, risor.WithEnv(env))
+	assert.Nil(t, err)
+
+	results, err := RunBenchmarks(context.Background(), code, env, &BenchConfig{
+		RunPattern: "list",
+		Time:       20 * time.Millisecond,
+	})
+	assert.Nil(t, err)
+	assert.Len(t, results, 1)
+	assert.Nil(t, results[0].Error)
+	assert.True(t, results[0].N > 1)
+	assert.True(t, results[0].Duration >= 20*time.Millisecond)
+	assert.True(t, results[0].AllocsPerOp() > 0)
+}
+
+func TestRunBenchmarks_InvalidPattern(t *stdt.T) {
+	_, err := RunBenchmarks(context.Background(), nil, nil, &BenchConfig{RunPattern: "("})
+	assert.NotNil(t, err)
+}
+
+func TestNextBenchIterations(t *stdt.T) {
+	// 10 iterations took 1ms; 1s needs 10000, plus a fifth, capped at 100x
+	assert.Equal(t, nextBenchIterations(10, time.Millisecond, time.Second), 1000)
+	// 100 iterations took 500ms; 1s needs 200, plus a fifth
+	assert.Equal(t, nextBenchIterations(100, 500*time.Millisecond, time.Second), 240)
+	// Always grows by at least one
+	assert.Equal(t, nextBenchIterations(5, time.Hour, time.Second), 6)
+	// No measurable time grows by the maximum
+	assert.Equal(t, nextBenchIterations(3, 0, time.Second), 300)
+}
@@ -23,6 +23,7 @@ type TestContext struct {
 	failures   []AssertionError         // Assertion failures
 	filename   string                   // Source file for error reporting
 	attrs      map[string]object.Object // Cached method wrappers
+	lineFunc   func() int               // Returns the line being executed
 }
 
 // NewTestContext creates a new TestContext for a test function.
@@ -35,6 +36,20 @@ func NewTestContext(name, filename string) *TestContext {
 	return t
 }
 
+// SetLineFunc sets the function used to find the source line of a failed
+// assertion. It is called while the assertion runs and returns 0 if the
+// line is unknown.
+func (t *TestContext) SetLineFunc(fn func() int) {
+	t.lineFunc = fn
+}
+
+func (t *TestContext) currentLine() int {
+	if t.lineFunc == nil {
+		return 0
+	}
+	return t.lineFunc()
+}
+
 // initAttrs creates the builtin method wrappers.
 func (t *TestContext) initAttrs() {
 	t.attrs = map[string]object.Object{
@@ -306,6 +321,7 @@ func (t *TestContext) addFailure(msg string, got, want object.Object) {
 	t.failures = append(t.failures, AssertionError{
 		Message: msg,
 		File:    t.filename,
+		Line:    t.currentLine(),
 		Got:     got,
 		Want:    want,
 	})
@@ -316,6 +332,7 @@ func (t *TestContext) addFailureWithValues(msg string, got, want object.Object)
 	t.failures = append(t.failures, AssertionError{
 		Message: msg,
 		File:    t.filename,
+		Line:    t.currentLine(),
 		Got:     got,
 		Want:    want,
 	})
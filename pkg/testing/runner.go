@@ -256,6 +256,12 @@ func runSingleTest(ctx context.Context, code *bytecode.Code, env map[string]any,
 
 	// Create the test context
 	testCtx := NewTestContext(testName, filename)
+	testCtx.SetLineFunc(func() int {
+		if frames := machine.Frames(); len(frames) > 0 {
+			return frames[0].Location().Line
+		}
+		return 0
+	})
 
 	// Call the test function with the test context
 	_, err = machine.Call(ctx, closure, []object.Object{testCtx})
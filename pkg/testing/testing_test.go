@@ -435,3 +435,22 @@ function test_gamma(t) {
 	assert.Equal(t, summary.TotalTests(), 2)
 	assert.Equal(t, summary.Passed, 2)
 }
+
+func TestRun_FailureLine(t *stdt.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "line_test.risor")
+
+	source := `function test_failure(t) {
+    t.assert(true)
+    t.assert_eq(1, 2)
+}
+`
+	assert.Nil(t, os.WriteFile(testFile, []byte(source), 0o644))
+
+	summary, err := Run(context.Background(), &Config{Patterns: []string{tmpDir}})
+	assert.Nil(t, err)
+
+	failures := summary.Files[0].Tests[0].Failures
+	assert.Equal(t, len(failures), 1)
+	assert.Equal(t, failures[0].Line, 3)
+}
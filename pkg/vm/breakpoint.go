@@ -0,0 +1,124 @@
+package vm
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+)
+
+// Breakpoint stops execution when the program reaches a source line.
+type Breakpoint struct {
+	// File is the filename the code was compiled with. An empty File
+	// matches code from any file.
+	File string
+
+	// Line is the 1-based source line.
+	Line int
+
+	// Condition is an optional Risor expression evaluated each time the
+	// line is reached, with access to the globals and the locals of the
+	// current frame. The breakpoint only counts a hit when it is truthy.
+	// A condition that fails to compile or raises an error always breaks,
+	// with the error in BreakpointEvent.Err. Conditions run in a separate
+	// VM with a step and time limit, so they cannot disturb the paused
+	// program's stack or frames, but they should not call functions that
+	// modify shared values.
+	Condition string
+
+	// HitCount, if greater than one, ignores the first HitCount-1 hits so
+	// that the breakpoint first breaks on the HitCount-th hit.
+	HitCount int
+}
+
+// BreakpointObserver is implemented by observers that set
+// ObserverConfig.Breakpoints.
+type BreakpointObserver interface {
+	// OnBreakpoint is called when the program reaches a breakpoint whose
+	// condition and hit count are satisfied, before the first instruction
+	// of the line executes. Returns false to halt execution immediately.
+	OnBreakpoint(event BreakpointEvent) bool
+}
+
+// BreakpointEvent describes a breakpoint that was hit.
+type BreakpointEvent struct {
+	// Index is the position of the breakpoint in ObserverConfig.Breakpoints.
+	Index int
+
+	// Breakpoint is the breakpoint that was hit.
+	Breakpoint Breakpoint
+
+	// Hits is the number of times the breakpoint has been hit, including
+	// this one.
+	Hits int
+
+	// Err is set if the breakpoint's condition could not be evaluated.
+	Err error
+
+	// Location is the source location where execution stopped.
+	Location object.SourceLocation
+
+	// FrameDepth is the current depth of the call stack.
+	FrameDepth int
+}
+
+// breakpointState tracks the hits of a configured breakpoint.
+type breakpointState struct {
+	index int
+	bp    Breakpoint
+	hits  int
+}
+
+// setBreakpoints indexes the configured breakpoints by line.
+func (vm *VirtualMachine) setBreakpoints(breakpoints []Breakpoint) {
+	vm.breaker, vm.breakpoints = nil, nil
+	handler, ok := vm.observer.(BreakpointObserver)
+	if !ok || len(breakpoints) == 0 {
+		return
+	}
+	vm.breaker = handler
+	vm.breakpoints = map[int][]*breakpointState{}
+	for i, bp := range breakpoints {
+		vm.breakpoints[bp.Line] = append(vm.breakpoints[bp.Line], &breakpointState{index: i, bp: bp})
+	}
+}
+
+// checkBreakpoints calls the observer for each breakpoint on the line the
+// current frame just moved to. Returns an error if the observer halts
+// execution.
+func (vm *VirtualMachine) checkBreakpoints() error {
+	loc := vm.activeCode.LocationAt(vm.ip)
+	if loc.Line == 0 || loc.Line == vm.activeFrame.breakLine {
+		return nil
+	}
+	vm.activeFrame.breakLine = loc.Line
+	for _, state := range vm.breakpoints[loc.Line] {
+		if state.bp.File != "" && state.bp.File != loc.Filename {
+			continue
+		}
+		var condErr error
+		if state.bp.Condition != "" {
+			result, err := vm.evalInFrame(context.Background(), vm.fp, state.bp.Condition)
+			if err == nil && !result.IsTruthy() {
+				continue
+			}
+			condErr = err
+		}
+		state.hits++
+		if condErr == nil && state.hits < state.bp.HitCount {
+			continue
+		}
+		event := BreakpointEvent{
+			Index:      state.index,
+			Breakpoint: state.bp,
+			Hits:       state.hits,
+			Err:        condErr,
+			Location:   loc,
+			FrameDepth: vm.fp + 1,
+		}
+		if !vm.breaker.OnBreakpoint(event) {
+			return fmt.Errorf("execution halted by observer")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package vm
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/compiler"
+	"github.com/deepnoodle-ai/risor/v2/pkg/parser"
+)
+
+// BreakpointRecorder records the breakpoints that were hit.
+type BreakpointRecorder struct {
+	NoOpObserver
+	Breakpoints []Breakpoint
+	Events      []BreakpointEvent
+	HaltAt      int
+}
+
+func (o *BreakpointRecorder) Config() ObserverConfig {
+	cfg := NewObserverConfig(StepNone)
+	cfg.Breakpoints = o.Breakpoints
+	return cfg
+}
+
+func (o *BreakpointRecorder) OnBreakpoint(event BreakpointEvent) bool {
+	o.Events = append(o.Events, event)
+	return o.HaltAt == 0 || len(o.Events) < o.HaltAt
+}
+
+const breakpointSource = `let scale = 10
+function f(n) {
+  let y = n * scale
+  return y
+}
+[1, 2, 3, 4].map(f)`
+
+func TestBreakpointLine(t *testing.T) {
+	observer := &BreakpointRecorder{Breakpoints: []Breakpoint{{Line: 3}}}
+	if err := runWatched(t, breakpointSource, observer); err != nil {
+		t.Fatal(err)
+	}
+	// Each call stops once, even though the line has several instructions
+	if len(observer.Events) != 4 {
+		t.Fatalf("expected 4 breakpoint events, got %d: %+v", len(observer.Events), observer.Events)
+	}
+	for i, event := range observer.Events {
+		if event.Hits != i+1 || event.Location.Line != 3 || event.FrameDepth != 2 {
+			t.Errorf("unexpected event %d: %+v", i, event)
+		}
+	}
+}
+
+func TestBreakpointCondition(t *testing.T) {
+	observer := &BreakpointRecorder{Breakpoints: []Breakpoint{
+		{Line: 3, Condition: "n == 2"},
+		{Line: 3, Condition: "n * scale > 20"},
+	}}
+	if err := runWatched(t, breakpointSource, observer); err != nil {
+		t.Fatal(err)
+	}
+	if len(observer.Events) != 3 {
+		t.Fatalf("expected 3 breakpoint events, got %d: %+v", len(observer.Events), observer.Events)
+	}
+	if e := observer.Events[0]; e.Index != 0 || e.Hits != 1 || e.Err != nil {
+		t.Errorf("unexpected first event: %+v", e)
+	}
+	for _, e := range observer.Events[1:] {
+		if e.Index != 1 || e.Location.Line != 3 {
+			t.Errorf("unexpected event: %+v", e)
+		}
+	}
+}
+
+func TestBreakpointHitCount(t *testing.T) {
+	observer := &BreakpointRecorder{Breakpoints: []Breakpoint{{Line: 3, HitCount: 3}}}
+	if err := runWatched(t, breakpointSource, observer); err != nil {
+		t.Fatal(err)
+	}
+	if len(observer.Events) != 2 {
+		t.Fatalf("expected 2 breakpoint events, got %d: %+v", len(observer.Events), observer.Events)
+	}
+	if observer.Events[0].Hits != 3 || observer.Events[1].Hits != 4 {
+		t.Errorf("expected hits 3 and 4, got %d and %d", observer.Events[0].Hits, observer.Events[1].Hits)
+	}
+}
+
+func TestBreakpointConditionHitCount(t *testing.T) {
+	// Only hits that satisfy the condition are counted
+	observer := &BreakpointRecorder{Breakpoints: []Breakpoint{
+		{Line: 3, Condition: "n > 1", HitCount: 2},
+	}}
+	if err := runWatched(t, breakpointSource, observer); err != nil {
+		t.Fatal(err)
+	}
+	if len(observer.Events) != 2 || observer.Events[0].Hits != 2 {
+		t.Fatalf("unexpected events: %+v", observer.Events)
+	}
+}
+
+func TestBreakpointConditionError(t *testing.T) {
+	observer := &BreakpointRecorder{Breakpoints: []Breakpoint{
+		{Line: 3, Condition: "missing > 1"},
+	}}
+	if err := runWatched(t, breakpointSource, observer); err != nil {
+		t.Fatal(err)
+	}
+	if len(observer.Events) != 4 {
+		t.Fatalf("expected 4 breakpoint events, got %d", len(observer.Events))
+	}
+	if err := observer.Events[0].Err; err == nil || !strings.Contains(err.Error(), "missing") {
+		t.Errorf("expected undefined variable error, got %v", err)
+	}
+}
+
+func TestBreakpointConditionStatement(t *testing.T) {
+	observer := &BreakpointRecorder{Breakpoints: []Breakpoint{
+		{Line: 3, Condition: "n = 5"},
+	}}
+	if err := runWatched(t, breakpointSource, observer); err != nil {
+		t.Fatal(err)
+	}
+	if len(observer.Events) == 0 || observer.Events[0].Err == nil {
+		t.Fatalf("expected a condition error, got %+v", observer.Events)
+	}
+}
+
+func TestBreakpointFile(t *testing.T) {
+	run := func(file string) int {
+		ast, err := parser.Parse(context.Background(), breakpointSource, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		code, err := compiler.Compile(ast, &compiler.Config{Filename: "main.risor"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		observer := &BreakpointRecorder{Breakpoints: []Breakpoint{{File: file, Line: 3}}}
+		if _, err := Run(context.Background(), code, WithObserver(observer)); err != nil {
+			t.Fatal(err)
+		}
+		return len(observer.Events)
+	}
+	if n := run("main.risor"); n != 4 {
+		t.Errorf("expected 4 events for the matching file, got %d", n)
+	}
+	if n := run("other.risor"); n != 0 {
+		t.Errorf("expected no events for another file, got %d", n)
+	}
+}
+
+func TestBreakpointHalt(t *testing.T) {
+	observer := &BreakpointRecorder{Breakpoints: []Breakpoint{{Line: 3}}, HaltAt: 2}
+	err := runWatched(t, breakpointSource, observer)
+	if err == nil || !strings.Contains(err.Error(), "halted by observer") {
+		t.Fatalf("expected halt error, got %v", err)
+	}
+	if len(observer.Events) != 2 {
+		t.Errorf("expected execution to stop at the second hit, got %d events", len(observer.Events))
+	}
+}
@@ -2,9 +2,12 @@ package vm
 
 import (
 	"context"
+	"errors"
 	"testing"
 
+	"github.com/deepnoodle-ai/risor/v2/pkg/compiler"
 	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+	"github.com/deepnoodle-ai/risor/v2/pkg/parser"
 	"github.com/deepnoodle-ai/wonton/assert"
 )
 
@@ -2178,3 +2181,51 @@ func TestClosureCreationWithTryCatch(t *testing.T) {
 		})
 	}
 }
+
+func TestPanicRecovery(t *testing.T) {
+	ctx := context.Background()
+	explode := object.NewBuiltin("explode", func(ctx context.Context, args ...object.Object) (object.Object, error) {
+		var m map[string]int
+		m["x"] = 1 // panics: assignment to entry in nil map
+		return object.Nil, nil
+	})
+	source := `
+	let e = try { explode() } catch e { e }
+	[e.code(), e.message(), e.data().value]
+	`
+	ast, err := parser.Parse(ctx, source, nil)
+	assert.Nil(t, err)
+	code, err := compiler.Compile(ast, &compiler.Config{GlobalNames: []string{"explode"}})
+	assert.Nil(t, err)
+	globals := WithGlobals(map[string]any{"explode": explode})
+
+	result, err := Run(ctx, code, globals, WithPanicRecovery())
+	assert.Nil(t, err)
+	assert.Equal(t, result, object.NewList([]object.Object{
+		object.NewString("panic"),
+		object.NewString("panic in explode: assignment to entry in nil map"),
+		object.NewString("assignment to entry in nil map"),
+	}))
+
+	// Without recovery the panic stops the script
+	_, err = Run(ctx, code, globals)
+	assert.NotNil(t, err)
+}
+
+func TestPanicRecoveryUncaught(t *testing.T) {
+	ctx := context.Background()
+	explode := object.NewBuiltin("explode", func(ctx context.Context, args ...object.Object) (object.Object, error) {
+		panic("boom")
+	})
+	ast, err := parser.Parse(ctx, "explode()", nil)
+	assert.Nil(t, err)
+	code, err := compiler.Compile(ast, &compiler.Config{GlobalNames: []string{"explode"}})
+	assert.Nil(t, err)
+
+	_, err = Run(ctx, code, WithGlobals(map[string]any{"explode": explode}), WithPanicRecovery())
+	var se *object.ScriptError
+	assert.True(t, errors.As(err, &se))
+	assert.Equal(t, se.Code, "panic")
+	assert.Equal(t, se.Data["value"], "boom")
+	assert.Contains(t, se.Data["stack"].(string), "runtime/debug.Stack")
+}
@@ -0,0 +1,52 @@
+package vm
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+)
+
+// fork returns a CallFunc that calls closures on a new VM, so that a
+// builtin can run closures from this program on another goroutine. It must
+// be called on the goroutine running this VM.
+//
+// The new VM has the same code, type registry, interceptor, and stack
+// limits. It shares this VM's step counter, so the steps of every fork
+// count against the step limit of the run. It starts with a copy of the
+// global variables, so assigning a global in one VM does not change it in
+// the other. The objects the globals refer to, and the
+// variables captured by closures, are shared rather than copied, so
+// callbacks must not modify them without the sync module. The observer and
+// exit hooks are not carried over.
+func (vm *VirtualMachine) fork() (object.CallFunc, error) {
+	root, ok := vm.loadedCode[vm.main]
+	if !ok {
+		return nil, errors.New("fork: no program is loaded")
+	}
+	clone, err := createVM(nil)
+	if err != nil {
+		return nil, err
+	}
+	clone.main = vm.main
+	clone.typeRegistry = vm.typeRegistry
+	clone.interceptor = vm.interceptor
+	clone.recoverPanics = vm.recoverPanics
+	clone.freezeGlobals = vm.freezeGlobals
+	clone.contextCheckInterval = vm.contextCheckInterval
+	clone.maxValueStackDepth = vm.maxValueStackDepth
+	clone.maxFrameDepth = vm.maxFrameDepth
+	clone.maxSteps = vm.maxSteps
+	clone.stepCount = vm.stepCount
+
+	// The clone gets its own copy of the globals array, which the code of
+	// its functions shares when it is loaded
+	cloneRoot := *root
+	cloneRoot.Globals = slices.Clone(root.Globals)
+	clone.loadedCode[vm.main] = &cloneRoot
+
+	return func(ctx context.Context, fn *object.Closure, args []object.Object) (object.Object, error) {
+		return clone.Call(ctx, fn, args)
+	}, nil
+}
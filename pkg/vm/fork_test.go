@@ -0,0 +1,74 @@
+package vm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+// forkCall returns a builtin that calls its closure argument on a forked VM
+// from another goroutine.
+func forkCall() *object.Builtin {
+	return object.NewBuiltin("fork_call", func(ctx context.Context, args ...object.Object) (object.Object, error) {
+		fork, ok := object.GetForkFunc(ctx)
+		if !ok {
+			return nil, errors.New("no fork func")
+		}
+		call, err := fork()
+		if err != nil {
+			return nil, err
+		}
+		type result struct {
+			value object.Object
+			err   error
+		}
+		done := make(chan result)
+		go func() {
+			value, err := call(ctx, args[0].(*object.Closure), args[1:])
+			done <- result{value, err}
+		}()
+		r := <-done
+		return r.value, r.err
+	})
+}
+
+func TestFork(t *testing.T) {
+	ctx := context.Background()
+	code := `
+	let base = 10
+	let items = [1]
+	function f(x) {
+		base = 100
+		return base + x + len(items)
+	}
+	let result = fork_call(f, 5)
+	[result, base]
+	`
+	result, err := run(ctx, code, runOpts{Globals: map[string]any{"fork_call": forkCall()}})
+	assert.Nil(t, err)
+	// The forked VM sees the globals but assigns its own copy of them
+	assert.Equal(t, result.Inspect(), "[106, 10]")
+}
+
+func TestForkError(t *testing.T) {
+	ctx := context.Background()
+	code := `fork_call(x => { throw "boom" }, 1)`
+	_, err := run(ctx, code, runOpts{Globals: map[string]any{"fork_call": forkCall()}})
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "boom")
+}
+
+func TestForkStepLimit(t *testing.T) {
+	ctx := context.Background()
+	code := `
+	fork_call(n => range(n).each(i => i), 1000000)
+	`
+	vm, err := newVM(ctx, code, runOpts{Globals: map[string]any{"fork_call": forkCall()}})
+	assert.Nil(t, err)
+	vm.maxSteps = 10_000
+	err = vm.Run(ctx)
+	assert.ErrorIs(t, err, ErrStepLimitExceeded)
+}
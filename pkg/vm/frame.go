@@ -26,6 +26,7 @@ type frame struct {
 	locals         []object.Object
 	extendedLocals []object.Object
 	capturedLocals []object.Object
+	breakLine      int // last source line checked for breakpoints
 }
 
 func (f *frame) ActivateCode(code *loadedCode) {
@@ -35,6 +36,7 @@ func (f *frame) ActivateCode(code *loadedCode) {
 	f.callSiteIP = 0
 	f.localsCount = uint16(code.LocalsCount())
 	f.capturedLocals = nil
+	f.breakLine = 0
 
 	// Decide where to store local variables. If the frame storage has enough
 	// space, use that. Otherwise, reuse extendedLocals if large enough, or
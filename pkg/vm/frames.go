@@ -0,0 +1,209 @@
+package vm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/ast"
+	"github.com/deepnoodle-ai/risor/v2/pkg/compiler"
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+	"github.com/deepnoodle-ai/risor/v2/pkg/parser"
+)
+
+// Limits on evaluating an expression against a paused program, with
+// EvalInFrame or as a breakpoint condition.
+const (
+	debugEvalMaxSteps = 100_000
+	debugEvalTimeout  = time.Second
+)
+
+// Frame describes a call frame of a paused program. Its values are copied
+// when Frames is called, so a Frame stays valid after the program resumes,
+// but it does not see later changes.
+type Frame struct {
+	index    int
+	function string
+	location object.SourceLocation
+	locals   map[string]object.Object
+}
+
+// Index returns the position of the frame in the call stack, with 0 the
+// frame that is executing.
+func (f *Frame) Index() int {
+	return f.index
+}
+
+// FunctionName returns the name of the function running in the frame,
+// "<anonymous>" for an unnamed function, or "__main__" for the main
+// program.
+func (f *Frame) FunctionName() string {
+	return f.function
+}
+
+// Location returns the source location the frame is stopped at: the next
+// instruction of the executing frame, or the call in progress for its
+// callers.
+func (f *Frame) Location() object.SourceLocation {
+	return f.location
+}
+
+// Locals returns the frame's named local variables that have been assigned.
+// The main program has no locals, since its variables are globals; use
+// VirtualMachine.Get for those. The returned map is a copy owned by the
+// caller, but the values are the program's own objects and should not be
+// modified.
+func (f *Frame) Locals() map[string]object.Object {
+	return f.locals
+}
+
+// Frames returns the call stack of the program, starting with the frame
+// that is executing. Call it from an observer callback or while the VM is
+// paused or stopped, never while it runs on another goroutine.
+func (vm *VirtualMachine) Frames() []*Frame {
+	var frames []*Frame
+	for fp := vm.fp; fp >= 0; fp-- {
+		if vm.frames[fp].code == nil {
+			continue
+		}
+		frames = append(frames, &Frame{
+			index:    len(frames),
+			function: vm.frameFunctionName(fp),
+			location: vm.frames[fp].code.LocationAt(vm.frameIP(fp, vm.ip)),
+			locals:   vm.frameLocals(fp),
+		})
+	}
+	return frames
+}
+
+// frameFunctionName returns the name of the function running in the frame
+// at index fp.
+func (vm *VirtualMachine) frameFunctionName(fp int) string {
+	f := &vm.frames[fp]
+	if f.fn != nil {
+		if name := f.fn.Name(); name != "" {
+			return name
+		}
+		return "<anonymous>"
+	}
+	if name := f.code.CodeName(); name != "" {
+		return name
+	}
+	return "__main__"
+}
+
+// frameIP returns the instruction the frame at index fp is stopped at, given
+// the instruction pointer ip of the active frame. Callers are stopped at
+// the call instruction, whose address is one before the callee's callSiteIP.
+func (vm *VirtualMachine) frameIP(fp, ip int) int {
+	if fp < vm.fp {
+		ip = vm.frames[fp+1].callSiteIP - 1
+	}
+	return max(ip, 0)
+}
+
+// frameLocals returns the named locals of the frame at index fp that have
+// been assigned.
+func (vm *VirtualMachine) frameLocals(fp int) map[string]object.Object {
+	locals := map[string]object.Object{}
+	f := &vm.frames[fp]
+	if f.code == nil || f.fn == nil {
+		return locals
+	}
+	for i, value := range f.Locals() {
+		if name := f.code.LocalNameAt(i); name != "" && value != nil {
+			locals[name] = value
+		}
+	}
+	return locals
+}
+
+// frameScope returns the variables visible in the frame at index fp: the
+// globals, overridden by the frame's named locals.
+func (vm *VirtualMachine) frameScope(fp int) map[string]object.Object {
+	scope := map[string]object.Object{}
+	if root, ok := vm.loadedCode[vm.main]; ok {
+		for i, value := range root.Globals {
+			if name := vm.main.GlobalNameAt(i); name != "" && value != nil {
+				scope[name] = value
+			}
+		}
+	}
+	for name, value := range vm.frameLocals(fp) {
+		scope[name] = value
+	}
+	return scope
+}
+
+// EvalInFrame evaluates an expression with access to the globals and the
+// locals of a frame of a paused program, as returned by Frames. Index 0 is
+// the executing frame. Call it from an observer callback or while the VM
+// is paused, as with Frames.
+//
+// The expression is compiled and run on a separate VM with a step limit and
+// timeout, so the paused program's stack, frames, and variables are not
+// changed: assignments are rejected, since only expressions are accepted.
+// Functions of the program may be called, and see its globals, but anything
+// they or the expression do to shared lists, maps, or other objects is
+// visible to the program when it resumes.
+func (vm *VirtualMachine) EvalInFrame(ctx context.Context, frameIndex int, expr string) (object.Object, error) {
+	index := 0
+	for fp := vm.fp; fp >= 0; fp-- {
+		if vm.frames[fp].code == nil {
+			continue
+		}
+		if index == frameIndex {
+			return vm.evalInFrame(ctx, fp, expr)
+		}
+		index++
+	}
+	return nil, fmt.Errorf("frame index %d out of range (%d frames)", frameIndex, index)
+}
+
+// evalInFrame evaluates a single expression with access to the variables of
+// the frame at index fp.
+func (vm *VirtualMachine) evalInFrame(ctx context.Context, fp int, expr string) (object.Object, error) {
+	program, err := parser.Parse(ctx, expr, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(program.Stmts) != 1 {
+		return nil, errors.New("expected a single expression")
+	}
+	if _, ok := program.Stmts[0].(ast.Expr); !ok {
+		return nil, errors.New("expected an expression, not a statement")
+	}
+	scope := vm.frameScope(fp)
+	code, err := compiler.Compile(program, &compiler.Config{
+		GlobalNames: slices.Sorted(maps.Keys(scope)),
+	})
+	if err != nil {
+		return nil, err
+	}
+	globals := make(map[string]any, len(scope))
+	for name, value := range scope {
+		globals[name] = value
+	}
+	machine, err := New(code,
+		WithGlobals(globals),
+		WithTypeRegistry(vm.typeRegistry),
+		WithMaxSteps(debugEvalMaxSteps),
+		WithTimeout(debugEvalTimeout))
+	if err != nil {
+		return nil, err
+	}
+	// Share this VM's loaded code, so that functions of the program called
+	// by the expression use the program's globals rather than the
+	// expression's
+	maps.Copy(machine.loadedCode, vm.loadedCode)
+	if err := machine.Run(ctx); err != nil {
+		return nil, err
+	}
+	if result, exists := machine.TOS(); exists {
+		return result, nil
+	}
+	return object.Nil, nil
+}
@@ -0,0 +1,218 @@
+package vm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/compiler"
+	"github.com/deepnoodle-ai/risor/v2/pkg/parser"
+)
+
+// frameRecorder captures the call stack at each breakpoint.
+type frameRecorder struct {
+	BreakpointRecorder
+	vm     *VirtualMachine
+	frames [][]*Frame
+}
+
+func (o *frameRecorder) OnBreakpoint(event BreakpointEvent) bool {
+	o.frames = append(o.frames, o.vm.Frames())
+	return true
+}
+
+func TestFramesAtBreakpoint(t *testing.T) {
+	ctx := context.Background()
+	ast, err := parser.Parse(ctx, `let total = 0
+function sum(n) {
+  let half = n / 2
+  if (n == 0) { return 0 }
+  return n + sum(n - 1)
+}
+sum(3)`, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, err := compiler.Compile(ast, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	observer := &frameRecorder{}
+	observer.Breakpoints = []Breakpoint{{Line: 4, Condition: "n == 0"}}
+	vm, err := New(code, WithObserver(observer))
+	if err != nil {
+		t.Fatal(err)
+	}
+	observer.vm = vm
+	if err := vm.Run(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(observer.frames) != 1 {
+		t.Fatalf("expected 1 breakpoint, got %d", len(observer.frames))
+	}
+
+	frames := observer.frames[0]
+	if len(frames) != 5 {
+		t.Fatalf("expected 5 frames, got %d", len(frames))
+	}
+	for i, f := range frames[:4] {
+		if f.Index() != i || f.FunctionName() != "sum" {
+			t.Errorf("unexpected frame %d: %d %s", i, f.Index(), f.FunctionName())
+		}
+		if n := f.Locals()["n"]; n == nil || n.Inspect() != string(rune('0'+i)) {
+			t.Errorf("frame %d: expected n = %d, got %v", i, i, n)
+		}
+	}
+	if loc := frames[0].Location(); loc.Line != 4 {
+		t.Errorf("expected the active frame at line 4, got %d", loc.Line)
+	}
+	if loc := frames[1].Location(); loc.Line != 5 {
+		t.Errorf("expected callers at the recursive call on line 5, got %d", loc.Line)
+	}
+
+	main := frames[4]
+	if main.FunctionName() != "__main__" || main.Location().Line != 7 {
+		t.Errorf("unexpected main frame: %s at line %d", main.FunctionName(), main.Location().Line)
+	}
+	if len(main.Locals()) != 0 {
+		t.Errorf("expected no locals in the main frame, got %v", main.Locals())
+	}
+}
+
+func TestFramesWhilePaused(t *testing.T) {
+	ctx := context.Background()
+	vm, err := newVM(ctx, `function count(n) {
+  let i = n + 1
+  return i
+}
+count(1)
+count(2)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	status, err := vm.RunSteps(ctx, 1)
+	for status == StatusYielded {
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, f := range vm.Frames() {
+			if f.Location().Line == 0 {
+				t.Errorf("frame %d has no location", f.Index())
+			}
+		}
+		status, err = vm.RunSteps(ctx, 1)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	frames := vm.Frames()
+	if len(frames) != 1 || frames[0].FunctionName() != "__main__" {
+		t.Errorf("expected only the main frame after the run, got %d frames", len(frames))
+	}
+}
+
+// evalRecorder evaluates expressions in each frame at a breakpoint.
+type evalRecorder struct {
+	BreakpointRecorder
+	vm      *VirtualMachine
+	exprs   map[int]string
+	results map[int]string
+	errs    map[int]error
+}
+
+func (o *evalRecorder) OnBreakpoint(event BreakpointEvent) bool {
+	for index, expr := range o.exprs {
+		result, err := o.vm.EvalInFrame(context.Background(), index, expr)
+		if err != nil {
+			o.errs[index] = err
+			continue
+		}
+		o.results[index] = result.Inspect()
+	}
+	return true
+}
+
+func TestEvalInFrame(t *testing.T) {
+	ctx := context.Background()
+	ast, err := parser.Parse(ctx, `let scale = 10
+function scaled(x) { return x * scale }
+function outer(a) {
+  return inner(a + 1)
+}
+function inner(b) {
+  let c = b * 2
+  return c
+}
+outer(1)`, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, err := compiler.Compile(ast, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	observer := &evalRecorder{
+		exprs: map[int]string{
+			0: "scaled(b) + c",
+			1: "a",
+			2: "scale",
+			3: "b",
+		},
+		results: map[int]string{},
+		errs:    map[int]error{},
+	}
+	observer.Breakpoints = []Breakpoint{{Line: 8}}
+	vm, err := New(code, WithObserver(observer))
+	if err != nil {
+		t.Fatal(err)
+	}
+	observer.vm = vm
+	if err := vm.Run(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// Program functions called by the expression see the program's globals
+	if got := observer.results[0]; got != "24" {
+		t.Errorf("expected scaled(b) + c = 24 in inner, got %q (%v)", got, observer.errs[0])
+	}
+	if got := observer.results[1]; got != "1" {
+		t.Errorf("expected a = 1 in outer, got %q (%v)", got, observer.errs[1])
+	}
+	if got := observer.results[2]; got != "10" {
+		t.Errorf("expected scale = 10 in main, got %q (%v)", got, observer.errs[2])
+	}
+	if observer.errs[3] == nil {
+		t.Errorf("expected an error for a local of another frame")
+	}
+	if _, err := vm.EvalInFrame(ctx, 5, "1"); err == nil {
+		t.Errorf("expected an error for an out of range frame")
+	}
+
+	// Evaluation does not change the program's result
+	result, ok := vm.TOS()
+	if !ok || result.Inspect() != "4" {
+		t.Errorf("expected the program to return 4, got %v", result)
+	}
+}
+
+func TestEvalInFrameRejectsStatements(t *testing.T) {
+	ctx := context.Background()
+	vm, err := newVM(ctx, `let x = 1`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := vm.Run(ctx); err != nil {
+		t.Fatal(err)
+	}
+	for _, expr := range []string{"x = 2", "let y = 3", "1; 2"} {
+		if _, err := vm.EvalInFrame(ctx, 0, expr); err == nil {
+			t.Errorf("expected an error evaluating %q", expr)
+		}
+	}
+	if got, _ := vm.Get("x"); got.Inspect() != "1" {
+		t.Errorf("expected x to be unchanged, got %v", got)
+	}
+	result, err := vm.EvalInFrame(ctx, 0, "x + 1")
+	if err != nil || result.Inspect() != "2" {
+		t.Errorf("expected x + 1 = 2, got %v (%v)", result, err)
+	}
+}
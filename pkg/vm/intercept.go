@@ -0,0 +1,104 @@
+package vm
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+)
+
+// CallInterceptor is notified of every call a script makes to a Go
+// callable: builtins, module functions, methods of built-in types such as
+// string.to_upper, and Go functions exposed as *object.GoFunc. Calls to Risor
+// functions are not intercepted, and neither are calls a Go function makes
+// itself. Use it for audit logging, dry runs, and enforcing a policy on
+// which functions a script may call.
+//
+// Both methods are called synchronously on the goroutine running the VM.
+type CallInterceptor interface {
+	// BeforeCall is called before the function runs. Returning a nil result
+	// and a nil error lets the call proceed. Returning an error fails the
+	// call with that error, as if the function had returned it, so the
+	// script can catch it. Returning a result skips the function, and the
+	// script receives the result instead.
+	BeforeCall(ctx context.Context, call *GoCall) (object.Object, error)
+
+	// AfterCall is called when the call completes, including calls that
+	// BeforeCall answered or rejected.
+	AfterCall(ctx context.Context, call *GoCall, result CallResult)
+}
+
+// GoCall describes a call to a Go callable.
+type GoCall struct {
+	// Name is the qualified name of a builtin, such as "math.sqrt" or
+	// "string.to_upper", or the Go name of a Go function. A Go function is
+	// not named after its key in the globals, so compare Function to
+	// recognize a specific one.
+	Name string
+
+	// Function is the callable being called.
+	Function object.Callable
+
+	// Args are the arguments passed by the script. The slice belongs to
+	// the interceptor, but the values are the script's own objects and
+	// should not be modified.
+	Args []object.Object
+
+	// Location is the source location of the call.
+	Location object.SourceLocation
+}
+
+// CallResult describes the outcome of a call to a Go callable.
+type CallResult struct {
+	// Result is the value returned to the script, or nil if Err is set.
+	Result object.Object
+
+	// Err is the error the call failed with.
+	Err error
+
+	// Duration is the time the function took to run, which is zero if it
+	// did not run.
+	Duration time.Duration
+
+	// Intercepted is true if BeforeCall answered or rejected the call, so
+	// the function did not run.
+	Intercepted bool
+}
+
+// interceptCall calls a Go callable through the configured interceptor.
+func (vm *VirtualMachine) interceptCall(ctx context.Context, fn object.Callable, args []object.Object) (object.Object, error) {
+	call := &GoCall{
+		Name:     callableName(fn),
+		Function: fn,
+		Args:     slices.Clone(args),
+		Location: vm.getCurrentLocation(),
+	}
+	result, err := vm.interceptor.BeforeCall(ctx, call)
+	if result != nil || err != nil {
+		if err != nil {
+			result = nil
+		}
+		vm.interceptor.AfterCall(ctx, call, CallResult{Result: result, Err: err, Intercepted: true})
+		return result, err
+	}
+	start := time.Now()
+	if vm.recoverPanics {
+		result, err = vm.callRecovered(ctx, fn, args)
+	} else {
+		result, err = fn.Call(ctx, args...)
+	}
+	vm.interceptor.AfterCall(ctx, call, CallResult{Result: result, Err: err, Duration: time.Since(start)})
+	return result, err
+}
+
+// callableName returns the qualified name of a Go callable, if it has one.
+func callableName(fn object.Callable) string {
+	switch fn := fn.(type) {
+	case *object.Builtin:
+		return fn.Key()
+	case interface{ Name() string }:
+		return fn.Name()
+	}
+	return ""
+}
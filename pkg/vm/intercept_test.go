@@ -0,0 +1,99 @@
+package vm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+)
+
+// callRecorder records intercepted calls, answering or rejecting some.
+type callRecorder struct {
+	answers map[string]object.Object
+	denied  map[string]bool
+	before  []string
+	after   []CallResult
+	calls   []*GoCall
+}
+
+func (r *callRecorder) BeforeCall(ctx context.Context, call *GoCall) (object.Object, error) {
+	r.before = append(r.before, call.Name)
+	if r.denied[call.Name] {
+		return nil, errors.New(call.Name + " is not allowed")
+	}
+	return r.answers[call.Name], nil
+}
+
+func (r *callRecorder) AfterCall(ctx context.Context, call *GoCall, result CallResult) {
+	r.calls = append(r.calls, call)
+	r.after = append(r.after, result)
+}
+
+func runIntercepted(t *testing.T, source string, interceptor CallInterceptor) object.Object {
+	t.Helper()
+	ctx := context.Background()
+	vm, err := newVM(ctx, source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vm.interceptor = interceptor
+	if err := vm.Run(ctx); err != nil {
+		t.Fatal(err)
+	}
+	result, _ := vm.TOS()
+	return result
+}
+
+func TestCallInterceptor(t *testing.T) {
+	r := &callRecorder{}
+	result := runIntercepted(t, `function f(x) { return x * 2 }
+let a = math.sqrt(16)
+let b = f(len([1, 2]))
+"hi".to_upper() + string(a + b)`, r)
+	if result.Inspect() != `"HI8"` {
+		t.Errorf("unexpected result %s", result.Inspect())
+	}
+
+	// Risor functions are not intercepted
+	want := []string{"math.sqrt", "len", "string.to_upper", "string"}
+	if len(r.before) != len(want) {
+		t.Fatalf("expected calls %v, got %v", want, r.before)
+	}
+	for i, name := range want {
+		if r.before[i] != name || r.calls[i].Name != name {
+			t.Errorf("call %d: expected %s, got %s", i, name, r.before[i])
+		}
+	}
+	if r.calls[0].Location.Line != 2 || r.calls[1].Location.Line != 3 {
+		t.Errorf("unexpected call locations: %v, %v", r.calls[0].Location, r.calls[1].Location)
+	}
+	if len(r.calls[0].Args) != 1 || r.calls[0].Args[0].Inspect() != "16" {
+		t.Errorf("unexpected args for math.sqrt: %v", r.calls[0].Args)
+	}
+	if res := r.after[0]; res.Result.Inspect() != "4" || res.Err != nil || res.Intercepted {
+		t.Errorf("unexpected result for math.sqrt: %+v", res)
+	}
+}
+
+func TestCallInterceptorAnswer(t *testing.T) {
+	r := &callRecorder{answers: map[string]object.Object{"math.sqrt": object.NewInt(99)}}
+	result := runIntercepted(t, `math.sqrt(16)`, r)
+	if result.Inspect() != "99" {
+		t.Errorf("expected the interceptor's answer, got %s", result.Inspect())
+	}
+	if len(r.after) != 1 || !r.after[0].Intercepted || r.after[0].Duration != 0 {
+		t.Errorf("expected an intercepted call, got %+v", r.after)
+	}
+}
+
+func TestCallInterceptorDeny(t *testing.T) {
+	r := &callRecorder{denied: map[string]bool{"len": true}}
+	result := runIntercepted(t, `try { len([1]) } catch e { e.message() }`, r)
+	if result.Inspect() != `"len is not allowed"` {
+		t.Errorf("expected a catchable error, got %s", result.Inspect())
+	}
+	if len(r.after) == 0 || r.after[0].Err == nil || !r.after[0].Intercepted {
+		t.Errorf("expected a rejected call, got %+v", r.after)
+	}
+}
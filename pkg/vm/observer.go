@@ -42,6 +42,23 @@ type ObserverConfig struct {
 
 	// ObserveReturns enables OnReturn callbacks.
 	ObserveReturns bool
+
+	// StackSnapshot is the number of values from the top of the value stack
+	// to copy into each StepEvent. Zero, the default, copies none.
+	StackSnapshot int
+
+	// Watch lists the names of variables to watch. When a global or a
+	// function local with one of these names is assigned a different value,
+	// the VM calls the observer's OnWatch method. The observer must
+	// implement Watcher; otherwise Watch is ignored.
+	Watch []string
+
+	// Breakpoints lists source lines to stop at. When execution reaches one
+	// of them, the VM calls the observer's OnBreakpoint method. The
+	// observer must implement BreakpointObserver; otherwise Breakpoints is
+	// ignored. Breakpoints are checked in every StepMode, including
+	// StepNone.
+	Breakpoints []Breakpoint
 }
 
 // NewObserverConfig creates a config with safe defaults.
@@ -112,6 +129,14 @@ type StepEvent struct {
 
 	// FrameDepth is the current depth of the call stack.
 	FrameDepth int
+
+	// Operands are the operands of the instruction. The slice refers to
+	// the code being executed and must not be modified.
+	Operands []op.Code
+
+	// Stack holds up to ObserverConfig.StackSnapshot values from the top
+	// of the value stack, topmost first, before the instruction executes.
+	Stack []object.Object
 }
 
 // CallEvent contains information about a function call.
@@ -142,6 +167,62 @@ type ReturnEvent struct {
 	FrameDepth int
 }
 
+// Watcher is implemented by observers that watch variables with
+// ObserverConfig.Watch, such as debuggers offering data breakpoints.
+type Watcher interface {
+	// OnWatch is called after a watched variable is assigned a value that
+	// differs from its previous one. Returns false to halt execution
+	// immediately.
+	OnWatch(event WatchEvent) bool
+}
+
+// WatchScope identifies the kind of variable in a WatchEvent.
+type WatchScope uint8
+
+const (
+	// WatchGlobal is a global variable, including top-level variables of
+	// the program and globals provided by the host.
+	WatchGlobal WatchScope = iota
+
+	// WatchLocal is a local variable or parameter of a function.
+	WatchLocal
+)
+
+// String returns "global" or "local".
+func (s WatchScope) String() string {
+	if s == WatchLocal {
+		return "local"
+	}
+	return "global"
+}
+
+// WatchEvent describes a change to a watched variable. Assignments to
+// variables a closure captured from an enclosing function are made through
+// the closure's cells and are not reported.
+type WatchEvent struct {
+	// Name is the name of the variable.
+	Name string
+
+	// Scope is the kind of variable that changed.
+	Scope WatchScope
+
+	// Old is the previous value, or nil if the variable had no value yet.
+	Old object.Object
+
+	// New is the value that was assigned.
+	New object.Object
+
+	// FunctionName is the name of the function whose local changed.
+	// It is empty for globals and anonymous functions.
+	FunctionName string
+
+	// Location is the source location of the assignment.
+	Location object.SourceLocation
+
+	// FrameDepth is the current depth of the call stack.
+	FrameDepth int
+}
+
 // NoOpObserver is an Observer implementation that does nothing.
 // Embed this in your observer to provide default implementations
 // for methods you don't need.
@@ -155,9 +236,15 @@ func (NoOpObserver) Config() ObserverConfig {
 	return NewObserverConfig(StepAll)
 }
 
-func (NoOpObserver) OnStep(StepEvent) bool     { return true }
-func (NoOpObserver) OnCall(CallEvent) bool     { return true }
-func (NoOpObserver) OnReturn(ReturnEvent) bool { return true }
-
-// Ensure NoOpObserver implements Observer.
-var _ Observer = NoOpObserver{}
+func (NoOpObserver) OnStep(StepEvent) bool             { return true }
+func (NoOpObserver) OnCall(CallEvent) bool             { return true }
+func (NoOpObserver) OnReturn(ReturnEvent) bool         { return true }
+func (NoOpObserver) OnWatch(WatchEvent) bool           { return true }
+func (NoOpObserver) OnBreakpoint(BreakpointEvent) bool { return true }
+
+// Ensure NoOpObserver implements Observer, Watcher, and BreakpointObserver.
+var (
+	_ Observer           = NoOpObserver{}
+	_ Watcher            = NoOpObserver{}
+	_ BreakpointObserver = NoOpObserver{}
+)
@@ -2,6 +2,7 @@ package vm
 
 import (
 	"context"
+	"strings"
 	"testing"
 
 	"github.com/deepnoodle-ai/risor/v2/pkg/compiler"
@@ -562,3 +563,117 @@ let y = id(42)`
 		t.Errorf("expected at least 2 line change events for cross-function call, got %d", len(observer.Lines))
 	}
 }
+
+// WatchObserver records changes to watched variables.
+type WatchObserver struct {
+	NoOpObserver
+	Names  []string
+	Events []WatchEvent
+	HaltAt int
+}
+
+func (o *WatchObserver) Config() ObserverConfig {
+	cfg := NewObserverConfig(StepNone)
+	cfg.Watch = o.Names
+	return cfg
+}
+
+func (o *WatchObserver) OnWatch(event WatchEvent) bool {
+	o.Events = append(o.Events, event)
+	return o.HaltAt == 0 || len(o.Events) < o.HaltAt
+}
+
+func runWatched(t *testing.T, source string, observer Observer) error {
+	t.Helper()
+	ast, err := parser.Parse(context.Background(), source, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, err := compiler.Compile(ast, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vm, err := New(code, WithObserver(observer))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return vm.Run(context.Background())
+}
+
+func TestObserverWatchGlobal(t *testing.T) {
+	source := `let count = 1
+let other = 5
+count = 1
+count = 2
+other = 6`
+	observer := &WatchObserver{Names: []string{"count"}}
+	if err := runWatched(t, source, observer); err != nil {
+		t.Fatal(err)
+	}
+
+	// Assigning the same value again is not a change
+	if len(observer.Events) != 2 {
+		t.Fatalf("expected 2 watch events, got %d: %+v", len(observer.Events), observer.Events)
+	}
+	first, second := observer.Events[0], observer.Events[1]
+	if first.Name != "count" || first.Scope != WatchGlobal || first.Old != nil || first.New.Inspect() != "1" {
+		t.Errorf("unexpected first event: %+v", first)
+	}
+	if second.Old.Inspect() != "1" || second.New.Inspect() != "2" || second.Location.Line != 4 {
+		t.Errorf("unexpected second event: %+v", second)
+	}
+}
+
+func TestObserverWatchLocal(t *testing.T) {
+	source := `function bump(n) {
+	let total = n
+	total = total + 10
+	return total
+}
+bump(1)`
+	observer := &WatchObserver{Names: []string{"total"}}
+	if err := runWatched(t, source, observer); err != nil {
+		t.Fatal(err)
+	}
+	if len(observer.Events) != 2 {
+		t.Fatalf("expected 2 watch events, got %d: %+v", len(observer.Events), observer.Events)
+	}
+	event := observer.Events[1]
+	if event.Scope != WatchLocal || event.FunctionName != "bump" || event.FrameDepth != 2 {
+		t.Errorf("unexpected local event: %+v", event)
+	}
+	if event.Old.Inspect() != "1" || event.New.Inspect() != "11" {
+		t.Errorf("expected 1 -> 11, got %v -> %v", event.Old, event.New)
+	}
+	if event.Scope.String() != "local" {
+		t.Errorf("expected scope local, got %s", event.Scope)
+	}
+}
+
+func TestObserverWatchHalt(t *testing.T) {
+	observer := &WatchObserver{Names: []string{"x"}, HaltAt: 2}
+	err := runWatched(t, "let x = 1\nx = 2\nx = 3", observer)
+	if err == nil || !strings.Contains(err.Error(), "halted by observer") {
+		t.Fatalf("expected halt error, got %v", err)
+	}
+	if len(observer.Events) != 2 {
+		t.Errorf("expected execution to stop at the second change, got %d events", len(observer.Events))
+	}
+}
+
+func TestObserverWatchRequiresWatcher(t *testing.T) {
+	// An observer without OnWatch that sets Watch is still valid
+	observer := &watchConfigOnlyObserver{}
+	if err := runWatched(t, "let x = 1\nx = 2", observer); err != nil {
+		t.Fatal(err)
+	}
+}
+
+type watchConfigOnlyObserver struct{}
+
+func (watchConfigOnlyObserver) Config() ObserverConfig {
+	return ObserverConfig{StepMode: StepNone, Watch: []string{"x"}}
+}
+func (watchConfigOnlyObserver) OnStep(StepEvent) bool     { return true }
+func (watchConfigOnlyObserver) OnCall(CallEvent) bool     { return true }
+func (watchConfigOnlyObserver) OnReturn(ReturnEvent) bool { return true }
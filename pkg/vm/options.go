@@ -122,3 +122,32 @@ func WithTimeout(d time.Duration) Option {
 		vm.timeout = d
 	}
 }
+
+// WithPanicRecovery makes panics in Go callables, such as builtins,
+// catchable by scripts. A recovered panic becomes an
+// error with the code "panic" whose data holds the panic value and the Go
+// stack trace. Without this option, a panic still stops the VM without
+// crashing the host, but the script cannot catch it.
+func WithPanicRecovery() Option {
+	return func(vm *VirtualMachine) {
+		vm.recoverPanics = true
+	}
+}
+
+// WithCallInterceptor sets an interceptor that is notified before and after
+// every call from the script to a Go callable. See CallInterceptor.
+func WithCallInterceptor(interceptor CallInterceptor) Option {
+	return func(vm *VirtualMachine) {
+		vm.interceptor = interceptor
+	}
+}
+
+// WithFrozenGlobals freezes every list and map provided as a global, along
+// with any containers they hold, using object.Freeze. Scripts can read the
+// globals but not modify them, so the same globals can be shared between
+// VMs running concurrently.
+func WithFrozenGlobals() Option {
+	return func(vm *VirtualMachine) {
+		vm.freezeGlobals = true
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/deepnoodle-ai/risor/v2/pkg/builtins"
 	"github.com/deepnoodle-ai/risor/v2/pkg/bytecode"
 	"github.com/deepnoodle-ai/risor/v2/pkg/compiler"
+	modAtexit "github.com/deepnoodle-ai/risor/v2/pkg/modules/atexit"
 	modMath "github.com/deepnoodle-ai/risor/v2/pkg/modules/math"
 	modRand "github.com/deepnoodle-ai/risor/v2/pkg/modules/rand"
 	"github.com/deepnoodle-ai/risor/v2/pkg/object"
@@ -40,7 +41,8 @@ func RunCodeOnVM(ctx context.Context, vm *VirtualMachine, code *bytecode.Code, o
 }
 
 type runOpts struct {
-	Globals map[string]interface{}
+	Globals    map[string]interface{}
+	ResultMode compiler.ResultMode
 }
 
 // Run the given source code in a new VM. Used for testing.
@@ -65,16 +67,21 @@ func newVM(ctx context.Context, source string, opts ...runOpts) (*VirtualMachine
 		return nil, err
 	}
 	globals := basicBuiltins()
+	var resultMode compiler.ResultMode
 	if len(opts) > 0 {
 		for k, v := range opts[0].Globals {
 			globals[k] = v
 		}
+		resultMode = opts[0].ResultMode
 	}
 	var globalNames []string
 	for k := range globals {
 		globalNames = append(globalNames, k)
 	}
-	main, err := compiler.Compile(ast, &compiler.Config{GlobalNames: globalNames})
+	main, err := compiler.Compile(ast, &compiler.Config{
+		GlobalNames: globalNames,
+		ResultMode:  resultMode,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -84,8 +91,9 @@ func newVM(ctx context.Context, source string, opts ...runOpts) (*VirtualMachine
 // Builtins to be used in VM tests.
 func basicBuiltins() map[string]any {
 	globals := map[string]any{
-		"math": modMath.Module(),
-		"rand": modRand.Module(),
+		"atexit": modAtexit.Module(),
+		"math":   modMath.Module(),
+		"rand":   modRand.Module(),
 	}
 	for k, v := range builtins.Builtins() {
 		globals[k] = v
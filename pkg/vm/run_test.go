@@ -2,6 +2,7 @@ package vm
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"github.com/deepnoodle-ai/risor/v2/pkg/compiler"
@@ -110,6 +111,88 @@ outer()`
 	assert.Equal(t, stack[1].Function, "outer")
 }
 
+func TestRunError_ThrownStackTrace(t *testing.T) {
+	ctx := context.Background()
+	source := `function inner() {
+	throw "boom"
+}
+
+function outer() {
+	return inner()
+}
+
+outer()`
+
+	ast, err := parser.Parse(ctx, source, nil)
+	assert.Nil(t, err)
+	code, err := compiler.Compile(ast, &compiler.Config{Filename: "throw.risor", Source: source})
+	assert.Nil(t, err)
+
+	_, err = Run(ctx, code)
+	structuredErr, ok := err.(*object.StructuredError)
+	assert.True(t, ok, "should be StructuredError")
+	assert.Equal(t, structuredErr.Kind, object.ErrRuntime)
+	assert.Equal(t, structuredErr.Message, "boom")
+	assert.Equal(t, structuredErr.Location.Line, 2)
+	assert.Equal(t, structuredErr.Location.Filename, "throw.risor")
+
+	stack := structuredErr.Stack
+	assert.Equal(t, len(stack), 3)
+	assert.Equal(t, stack[0].Function, "inner")
+	assert.Equal(t, stack[1].Function, "outer")
+	assert.Equal(t, stack[1].Location.Line, 6)
+	assert.Equal(t, stack[2].Function, "__main__")
+
+	friendly := structuredErr.FriendlyErrorMessage()
+	assert.Contains(t, friendly, "runtime error: boom (2:")
+	assert.Contains(t, friendly, `throw "boom"`)
+	assert.Contains(t, friendly, "inner")
+}
+
+func TestRunError_BuiltinErrorCause(t *testing.T) {
+	ctx := context.Background()
+	sentinel := errors.New("not allowed")
+	source := `function check() {
+	return guard()
+}
+check()`
+
+	ast, err := parser.Parse(ctx, source, nil)
+	assert.Nil(t, err)
+	code, err := compiler.Compile(ast, &compiler.Config{GlobalNames: []string{"guard"}})
+	assert.Nil(t, err)
+
+	guard := object.NewBuiltin("guard", func(ctx context.Context, args ...object.Object) (object.Object, error) {
+		return nil, sentinel
+	})
+	_, err = Run(ctx, code, WithGlobals(map[string]any{"guard": guard}))
+	structuredErr, ok := err.(*object.StructuredError)
+	assert.True(t, ok, "should be StructuredError")
+	assert.True(t, errors.Is(err, sentinel))
+	assert.Equal(t, structuredErr.Location.Line, 2)
+	assert.Equal(t, structuredErr.Stack[0].Function, "check")
+}
+
+func TestRunError_RethrowKeepsTrace(t *testing.T) {
+	ctx := context.Background()
+	source := `function fail() {
+	throw "first"
+}
+let e = try { fail() } catch e { e }
+throw e`
+
+	ast, err := parser.Parse(ctx, source, nil)
+	assert.Nil(t, err)
+	code, err := compiler.Compile(ast, nil)
+	assert.Nil(t, err)
+
+	_, err = Run(ctx, code)
+	structuredErr, ok := err.(*object.StructuredError)
+	assert.True(t, ok, "should be StructuredError")
+	assert.Equal(t, structuredErr.Location.Line, 2)
+	assert.Equal(t, structuredErr.Stack[0].Function, "fail")
+}
+
 func TestRunError_NestedAttributeAccess(t *testing.T) {
 	ctx := context.Background()
 	// Type error: accessing attribute on wrong type inside nested structure
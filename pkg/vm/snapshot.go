@@ -0,0 +1,905 @@
+package vm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/bytecode"
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+)
+
+// snapshotVersion is the version of the format written by Snapshot.
+const snapshotVersion = 1
+
+// Snapshot serializes the state of a paused VM: its code, globals, value
+// stack, call frames, and active exception handlers. Pass the result to
+// Restore, possibly in another process, to continue the program later.
+//
+// Lists, maps, functions, and the variables functions capture keep their
+// identity, so values shared before the snapshot are still shared after it.
+// Go values, such as builtins and other objects provided as globals, are
+// recorded by their global name, or by their qualified name for builtins
+// of a module such as math.sqrt, and looked up again on restore. Snapshot
+// returns an error if the program holds any other value that cannot be
+// serialized.
+func (vm *VirtualMachine) Snapshot() ([]byte, error) {
+	vm.runMutex.Lock()
+	defer vm.runMutex.Unlock()
+	if vm.running || !vm.paused {
+		return nil, errors.New("snapshot: vm is not paused")
+	}
+	code, err := bytecode.Marshal(vm.main)
+	if err != nil {
+		return nil, fmt.Errorf("snapshot: %w", err)
+	}
+	enc := newSnapshotEncoder(vm)
+	enc.state.Code = code
+	if err := enc.encodeVM(); err != nil {
+		return nil, fmt.Errorf("snapshot: %w", err)
+	}
+	return json.Marshal(enc.state)
+}
+
+// Restore creates a paused VM from data written by Snapshot. Call Resume on
+// it to continue the program. The options must provide the same globals the
+// original VM had, under the same names, for any Go values the snapshot
+// refers to.
+func Restore(data []byte, options ...Option) (*VirtualMachine, error) {
+	var state snapshotState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("restore: %w", err)
+	}
+	if state.Version != snapshotVersion {
+		return nil, fmt.Errorf("restore: unsupported snapshot version %d", state.Version)
+	}
+	main, err := bytecode.Unmarshal(state.Code)
+	if err != nil {
+		return nil, fmt.Errorf("restore: %w", err)
+	}
+	if err := bytecode.Verify(main); err != nil {
+		return nil, fmt.Errorf("restore: %w", err)
+	}
+	vm, err := New(main, options...)
+	if err != nil {
+		return nil, err
+	}
+	dec := newSnapshotDecoder(vm, &state)
+	if err := dec.decodeVM(); err != nil {
+		return nil, fmt.Errorf("restore: %w", err)
+	}
+	vm.paused = true
+	return vm, nil
+}
+
+// snapshotState is the serialized form of a paused VM. Objects are stored
+// once in Values and referred to by their position plus one, so that zero
+// stands for an unset slot.
+type snapshotState struct {
+	Version   int             `json:"version"`
+	Code      json.RawMessage `json:"code"`
+	Values    []valueDef      `json:"values"`
+	Slots     []slotDef       `json:"slots,omitempty"`
+	Globals   []int           `json:"globals"`
+	Stack     []int           `json:"stack"`
+	Frames    []frameDef      `json:"frames"`
+	Handlers  []handlerDef    `json:"handlers,omitempty"`
+	ExitHooks []int           `json:"exit_hooks,omitempty"`
+	IP        int             `json:"ip"`
+}
+
+type valueDef struct {
+	Type  string   `json:"type"`
+	Bool  bool     `json:"bool,omitempty"`
+	Int   int64    `json:"int,omitempty"`
+	Float float64  `json:"float,omitempty"`
+	Str   string   `json:"str,omitempty"` // String, error message, global name, or non-finite float
+	Bytes []byte   `json:"bytes,omitempty"`
+	Keys  []string `json:"keys,omitempty"`  // Map keys
+	Refs  []int    `json:"refs,omitempty"`  // Map keys that are not strings
+	Items []int    `json:"items,omitempty"` // List or tuple items, or map values
+	Code  int      `json:"code,omitempty"`  // Function code index
+	Slots []int    `json:"slots,omitempty"` // Function captures or cell slot
+	Kind  int      `json:"kind,omitempty"`  // Error kind
+	Ecode string   `json:"ecode,omitempty"` // Error code
+	Data  int      `json:"data,omitempty"`  // Error data
+}
+
+// slotDef is a variable captured by a closure. A variable of a frame that
+// is still active refers to the frame's locals; others hold their value.
+type slotDef struct {
+	Frame int `json:"frame"`
+	Index int `json:"index,omitempty"`
+	Value int `json:"value,omitempty"`
+}
+
+type frameDef struct {
+	Code       int   `json:"code"`
+	Fn         int   `json:"fn,omitempty"`
+	ReturnAddr int   `json:"return_addr"`
+	ReturnSp   int   `json:"return_sp"`
+	CallSiteIP int   `json:"call_site_ip"`
+	Locals     []int `json:"locals"`
+	Captured   bool  `json:"captured,omitempty"`
+}
+
+type handlerDef struct {
+	Code          int  `json:"code"`
+	FP            int  `json:"fp"`
+	TryStart      int  `json:"try_start"`
+	TryEnd        int  `json:"try_end"`
+	CatchStart    int  `json:"catch_start"`
+	FinallyStart  int  `json:"finally_start"`
+	CatchVarIdx   int  `json:"catch_var_idx"`
+	PendingError  int  `json:"pending_error,omitempty"`
+	PendingReturn int  `json:"pending_return,omitempty"`
+	InCatch       bool `json:"in_catch,omitempty"`
+	InFinally     bool `json:"in_finally,omitempty"`
+}
+
+type snapshotEncoder struct {
+	vm         *VirtualMachine
+	state      *snapshotState
+	codes      map[*bytecode.Code]int
+	env        map[object.Object]string
+	refs       map[object.Object]int
+	slots      map[*object.Object]int
+	frameSlots map[*object.Object]slotDef
+}
+
+func newSnapshotEncoder(vm *VirtualMachine) *snapshotEncoder {
+	enc := &snapshotEncoder{
+		vm:         vm,
+		state:      &snapshotState{Version: snapshotVersion, IP: vm.ip},
+		codes:      map[*bytecode.Code]int{},
+		env:        map[object.Object]string{},
+		refs:       map[object.Object]int{},
+		slots:      map[*object.Object]int{},
+		frameSlots: map[*object.Object]slotDef{},
+	}
+	for i, code := range vm.main.Flatten() {
+		enc.codes[code] = i
+	}
+	names := make([]string, 0, len(vm.globals))
+	for name := range vm.globals {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if obj := vm.globals[name]; comparable(obj) {
+			if _, found := enc.env[obj]; !found {
+				enc.env[obj] = name
+			}
+		}
+	}
+	for i := 0; i <= vm.fp; i++ {
+		captured := vm.frames[i].capturedLocals
+		for j := range captured {
+			enc.frameSlots[&captured[j]] = slotDef{Frame: i, Index: j}
+		}
+	}
+	return enc
+}
+
+func (enc *snapshotEncoder) encodeVM() error {
+	vm := enc.vm
+	var err error
+	main := vm.loadedCode[vm.main]
+	if enc.state.Globals, err = enc.encodeAll(main.Globals); err != nil {
+		return err
+	}
+	if enc.state.Stack, err = enc.encodeAll(vm.stack[:vm.sp+1]); err != nil {
+		return err
+	}
+	for i := 0; i <= vm.fp; i++ {
+		f := &vm.frames[i]
+		code, found := enc.codes[f.code.Code]
+		if !found {
+			return errors.New("cannot serialize a frame running code outside the program")
+		}
+		def := frameDef{
+			Code:       code,
+			ReturnAddr: f.returnAddr,
+			ReturnSp:   f.returnSp,
+			CallSiteIP: f.callSiteIP,
+			Captured:   f.capturedLocals != nil,
+		}
+		if f.fn != nil {
+			if def.Fn, err = enc.encode(f.fn); err != nil {
+				return err
+			}
+		}
+		if def.Locals, err = enc.encodeAll(f.Locals()); err != nil {
+			return err
+		}
+		enc.state.Frames = append(enc.state.Frames, def)
+	}
+	for i := 0; i < vm.excStackSize; i++ {
+		exc := &vm.excStack[i]
+		code, found := enc.codes[exc.code.Code]
+		if !found {
+			return errors.New("cannot serialize a handler in code outside the program")
+		}
+		def := handlerDef{
+			Code:         code,
+			FP:           exc.fp,
+			TryStart:     exc.handler.TryStart,
+			TryEnd:       exc.handler.TryEnd,
+			CatchStart:   exc.handler.CatchStart,
+			FinallyStart: exc.handler.FinallyStart,
+			CatchVarIdx:  exc.handler.CatchVarIdx,
+			InCatch:      exc.inCatch,
+			InFinally:    exc.inFinally,
+		}
+		if exc.pendingError != nil {
+			if def.PendingError, err = enc.encode(exc.pendingError); err != nil {
+				return err
+			}
+		}
+		if def.PendingReturn, err = enc.encode(exc.pendingReturn); err != nil {
+			return err
+		}
+		enc.state.Handlers = append(enc.state.Handlers, def)
+	}
+	for _, hook := range vm.exitHooks {
+		obj, ok := hook.(object.Object)
+		if !ok {
+			return fmt.Errorf("cannot serialize exit hook %T", hook)
+		}
+		ref, err := enc.encode(obj)
+		if err != nil {
+			return err
+		}
+		enc.state.ExitHooks = append(enc.state.ExitHooks, ref)
+	}
+	return nil
+}
+
+func (enc *snapshotEncoder) encodeAll(objs []object.Object) ([]int, error) {
+	refs := make([]int, len(objs))
+	for i, obj := range objs {
+		ref, err := enc.encode(obj)
+		if err != nil {
+			return nil, err
+		}
+		refs[i] = ref
+	}
+	return refs, nil
+}
+
+// encode records obj in the value table and returns its reference.
+func (enc *snapshotEncoder) encode(obj object.Object) (int, error) {
+	if obj == nil {
+		return 0, nil
+	}
+	if !comparable(obj) {
+		return 0, fmt.Errorf("cannot serialize %s value", obj.Type())
+	}
+	if ref, found := enc.refs[obj]; found {
+		return ref, nil
+	}
+	if name, found := enc.env[obj]; found {
+		return enc.add(obj, valueDef{Type: "global", Str: name}), nil
+	}
+	switch obj := obj.(type) {
+	case *object.NilType:
+		return enc.add(obj, valueDef{Type: "nil"}), nil
+	case *object.Bool:
+		return enc.add(obj, valueDef{Type: "bool", Bool: obj.Value()}), nil
+	case *object.Int:
+		return enc.add(obj, valueDef{Type: "int", Int: obj.Value()}), nil
+	case *object.Float:
+		// JSON has no NaN or infinities, so those are stored by name
+		if v := obj.Value(); math.IsNaN(v) || math.IsInf(v, 0) {
+			return enc.add(obj, valueDef{Type: "float", Str: strconv.FormatFloat(v, 'g', -1, 64)}), nil
+		}
+		return enc.add(obj, valueDef{Type: "float", Float: obj.Value()}), nil
+	case *object.String:
+		return enc.add(obj, valueDef{Type: "string", Str: obj.Value()}), nil
+	case *object.Byte:
+		return enc.add(obj, valueDef{Type: "byte", Int: int64(obj.Value())}), nil
+	case *object.Bytes:
+		return enc.add(obj, valueDef{Type: "bytes", Bytes: obj.Value()}), nil
+	case *object.List:
+		// Add the list before its items, which may refer back to it
+		ref := enc.add(obj, valueDef{Type: "list"})
+		items, err := enc.encodeAll(obj.Value())
+		if err != nil {
+			return 0, err
+		}
+		enc.state.Values[ref-1].Items = items
+		return ref, nil
+	case *object.Tuple:
+		ref := enc.add(obj, valueDef{Type: "tuple"})
+		items, err := enc.encodeAll(obj.Value())
+		if err != nil {
+			return 0, err
+		}
+		enc.state.Values[ref-1].Items = items
+		return ref, nil
+	case *object.Map:
+		ref := enc.add(obj, valueDef{Type: "map"})
+		keys := obj.SortedKeys()
+		items := make([]int, len(keys))
+		for i, key := range keys {
+			item, err := enc.encode(obj.Get(key))
+			if err != nil {
+				return 0, err
+			}
+			items[i] = item
+		}
+		// Values of other keys follow the values of the string keys
+		var refs []int
+		var encErr error
+		obj.Enumerate(context.Background(), func(key, value object.Object) bool {
+			if _, ok := key.(*object.String); ok {
+				return true
+			}
+			keyRef, err := enc.encode(key)
+			if err != nil {
+				encErr = err
+				return false
+			}
+			item, err := enc.encode(value)
+			if err != nil {
+				encErr = err
+				return false
+			}
+			refs = append(refs, keyRef)
+			items = append(items, item)
+			return true
+		})
+		if encErr != nil {
+			return 0, encErr
+		}
+		enc.state.Values[ref-1].Keys = keys
+		enc.state.Values[ref-1].Refs = refs
+		enc.state.Values[ref-1].Items = items
+		return ref, nil
+	case *object.Closure:
+		code, found := enc.codes[obj.Code()]
+		if !found {
+			return 0, fmt.Errorf("cannot serialize function %q defined outside the program", obj.Name())
+		}
+		ref := enc.add(obj, valueDef{Type: "function", Code: code})
+		slots := make([]int, obj.FreeVarCount())
+		for i := range slots {
+			slot, err := enc.slot(obj.FreeVar(i))
+			if err != nil {
+				return 0, err
+			}
+			slots[i] = slot
+		}
+		enc.state.Values[ref-1].Slots = slots
+		return ref, nil
+	case *object.Cell:
+		ref := enc.add(obj, valueDef{Type: "cell"})
+		slot, err := enc.slot(obj)
+		if err != nil {
+			return 0, err
+		}
+		enc.state.Values[ref-1].Slots = []int{slot}
+		return ref, nil
+	case *object.Error:
+		return enc.encodeError(obj)
+	case *object.Builtin:
+		// Methods are retrieved from their receiver again, and builtins of
+		// modules are looked up through their module
+		if receiver := obj.Receiver(); receiver != nil {
+			ref := enc.add(obj, valueDef{Type: "method", Str: obj.Name()[strings.LastIndex(obj.Name(), ".")+1:]})
+			item, err := enc.encode(receiver)
+			if err != nil {
+				return 0, err
+			}
+			enc.state.Values[ref-1].Items = []int{item}
+			return ref, nil
+		}
+		if name := obj.Key(); strings.Contains(name, ".") {
+			return enc.add(obj, valueDef{Type: "global", Str: name}), nil
+		}
+	}
+	return 0, fmt.Errorf("cannot serialize %s value", obj.Type())
+}
+
+func (enc *snapshotEncoder) encodeError(obj *object.Error) (int, error) {
+	def := valueDef{Type: "error", Str: obj.Value().Error()}
+	if se, ok := obj.ScriptError(); ok {
+		def.Ecode = se.Code
+		def.Str = se.Message
+		data, err := se.DataObject()
+		if err != nil {
+			return 0, err
+		}
+		if data != object.Nil {
+			if def.Data, err = enc.encode(data); err != nil {
+				return 0, err
+			}
+		}
+	} else if structured := obj.Structured(); structured != nil {
+		def.Kind = int(structured.Kind)
+		def.Str = structured.Message
+	}
+	return enc.add(obj, def), nil
+}
+
+func (enc *snapshotEncoder) add(obj object.Object, def valueDef) int {
+	enc.state.Values = append(enc.state.Values, def)
+	ref := len(enc.state.Values)
+	enc.refs[obj] = ref
+	return ref
+}
+
+// slot records the variable a cell refers to and returns its index.
+func (enc *snapshotEncoder) slot(cell *object.Cell) (int, error) {
+	ptr := cell.Slot()
+	if ptr == nil {
+		return 0, errors.New("cannot serialize empty cell")
+	}
+	if index, found := enc.slots[ptr]; found {
+		return index, nil
+	}
+	index := len(enc.state.Slots)
+	enc.slots[ptr] = index
+	if def, found := enc.frameSlots[ptr]; found {
+		enc.state.Slots = append(enc.state.Slots, def)
+		return index, nil
+	}
+	enc.state.Slots = append(enc.state.Slots, slotDef{Frame: -1})
+	value, err := enc.encode(*ptr)
+	if err != nil {
+		return 0, err
+	}
+	enc.state.Slots[index].Value = value
+	return index, nil
+}
+
+// comparable reports whether obj can be used as a map key.
+func comparable(obj object.Object) bool {
+	return obj != nil && reflect.TypeOf(obj).Comparable()
+}
+
+type snapshotDecoder struct {
+	vm        *VirtualMachine
+	state     *snapshotState
+	codes     []*loadedCode
+	functions map[*bytecode.Code]*bytecode.Function
+	values    []object.Object
+	slots     []*object.Object
+}
+
+func newSnapshotDecoder(vm *VirtualMachine, state *snapshotState) *snapshotDecoder {
+	dec := &snapshotDecoder{
+		vm:        vm,
+		state:     state,
+		functions: map[*bytecode.Code]*bytecode.Function{},
+		values:    make([]object.Object, len(state.Values)),
+	}
+	for _, code := range vm.main.Flatten() {
+		dec.codes = append(dec.codes, vm.loadCode(code))
+		for i := 0; i < code.ConstantCount(); i++ {
+			if fn, ok := code.ConstantAt(i).(*bytecode.Function); ok {
+				dec.functions[fn.Code()] = fn
+			}
+		}
+	}
+	return dec
+}
+
+func (dec *snapshotDecoder) decodeVM() error {
+	vm, state := dec.vm, dec.state
+	if len(state.Frames) == 0 || len(state.Frames) > MaxFrameDepth {
+		return fmt.Errorf("invalid frame count %d", len(state.Frames))
+	}
+	if len(state.Stack) > MaxStackDepth {
+		return fmt.Errorf("invalid stack size %d", len(state.Stack))
+	}
+	if len(state.Handlers) > MaxFrameDepth {
+		return fmt.Errorf("invalid handler count %d", len(state.Handlers))
+	}
+
+	// Frames come first, since captured variables may live in their locals
+	if err := vm.ensureFrameCapacity(len(state.Frames) - 1); err != nil {
+		return err
+	}
+	for i, def := range state.Frames {
+		code, err := dec.code(def.Code)
+		if err != nil {
+			return err
+		}
+		if len(def.Locals) != code.LocalsCount() {
+			return fmt.Errorf("frame %d has %d locals, expected %d", i, len(def.Locals), code.LocalsCount())
+		}
+		if i > 0 && (def.ReturnSp < -1 || def.ReturnSp >= len(state.Stack) || def.ReturnAddr < 0) {
+			return fmt.Errorf("frame %d has an invalid return address", i)
+		}
+		f := &vm.frames[i]
+		f.ActivateCode(code)
+		f.returnAddr = def.ReturnAddr
+		f.returnSp = def.ReturnSp
+		f.callSiteIP = def.CallSiteIP
+		if def.Captured {
+			f.CaptureLocals()
+		}
+	}
+	for _, def := range state.Slots {
+		if def.Frame < 0 {
+			dec.slots = append(dec.slots, new(object.Object))
+			continue
+		}
+		if def.Frame >= len(state.Frames) {
+			return fmt.Errorf("invalid slot frame %d", def.Frame)
+		}
+		captured := vm.frames[def.Frame].capturedLocals
+		if def.Index < 0 || def.Index >= len(captured) {
+			return fmt.Errorf("invalid slot index %d", def.Index)
+		}
+		dec.slots = append(dec.slots, &captured[def.Index])
+	}
+
+	if err := dec.decodeValues(); err != nil {
+		return err
+	}
+	for i, def := range state.Slots {
+		if def.Frame < 0 {
+			value, err := dec.ref(def.Value)
+			if err != nil {
+				return err
+			}
+			*dec.slots[i] = value
+		}
+	}
+
+	// With all values available, fill in the frames, globals, and stack
+	for i, def := range state.Frames {
+		f := &vm.frames[i]
+		if def.Fn != 0 {
+			fn, err := dec.ref(def.Fn)
+			if err != nil {
+				return err
+			}
+			closure, ok := fn.(*object.Closure)
+			if !ok {
+				return fmt.Errorf("frame %d has a %s as its function", i, fn.Type())
+			}
+			f.fn = closure
+		}
+		for j, ref := range def.Locals {
+			value, err := dec.ref(ref)
+			if err != nil {
+				return err
+			}
+			f.locals[j] = value
+		}
+	}
+	main := dec.codes[0]
+	if len(state.Globals) != len(main.Globals) {
+		return fmt.Errorf("snapshot has %d globals, expected %d", len(state.Globals), len(main.Globals))
+	}
+	for i, ref := range state.Globals {
+		value, err := dec.ref(ref)
+		if err != nil {
+			return err
+		}
+		main.Globals[i] = value
+	}
+	for i, ref := range state.Stack {
+		value, err := dec.ref(ref)
+		if err != nil {
+			return err
+		}
+		vm.stack[i] = value
+	}
+	if err := dec.decodeHandlers(); err != nil {
+		return err
+	}
+	for _, ref := range state.ExitHooks {
+		value, err := dec.ref(ref)
+		if err != nil {
+			return err
+		}
+		hook, ok := value.(object.Callable)
+		if !ok {
+			return fmt.Errorf("exit hook is a %s", value.Type())
+		}
+		vm.exitHooks = append(vm.exitHooks, hook)
+	}
+
+	vm.fp = len(state.Frames) - 1
+	vm.sp = len(state.Stack) - 1
+	vm.activeFrame = &vm.frames[vm.fp]
+	vm.activeCode = vm.activeFrame.code
+	if state.IP < 0 || state.IP > len(vm.activeCode.Instructions) {
+		return fmt.Errorf("invalid instruction pointer %d", state.IP)
+	}
+	vm.ip = state.IP
+	return nil
+}
+
+// decodeValues creates the objects of the value table. Lists and maps are
+// created empty first and filled last, so that references between values
+// resolve regardless of order.
+func (dec *snapshotDecoder) decodeValues() error {
+	for i, def := range dec.state.Values {
+		var obj object.Object
+		switch def.Type {
+		case "nil":
+			obj = object.Nil
+		case "bool":
+			obj = object.NewBool(def.Bool)
+		case "int":
+			obj = object.NewInt(def.Int)
+		case "float":
+			value := def.Float
+			if def.Str != "" {
+				var err error
+				if value, err = strconv.ParseFloat(def.Str, 64); err != nil {
+					return fmt.Errorf("invalid float %q", def.Str)
+				}
+			}
+			obj = object.NewFloat(value)
+		case "string":
+			obj = object.NewString(def.Str)
+		case "byte":
+			obj = object.NewByte(byte(def.Int))
+		case "bytes":
+			obj = object.NewBytes(def.Bytes)
+		case "list":
+			obj = object.NewList(make([]object.Object, 0, len(def.Items)))
+		case "tuple":
+			obj = object.NewTuple(make([]object.Object, len(def.Items)))
+		case "map":
+			obj = object.NewMap(nil)
+		case "function":
+			code, err := dec.code(def.Code)
+			if err != nil {
+				return err
+			}
+			fn, found := dec.functions[code.Code]
+			if !found {
+				return fmt.Errorf("code %d is not a function", def.Code)
+			}
+			cells, err := dec.cells(def.Slots)
+			if err != nil {
+				return err
+			}
+			obj = object.CloneWithCaptures(object.NewClosure(fn), cells)
+		case "cell":
+			cells, err := dec.cells(def.Slots)
+			if err != nil {
+				return err
+			}
+			if len(cells) != 1 {
+				return errors.New("invalid cell")
+			}
+			obj = cells[0]
+		case "global":
+			value, err := dec.global(def.Str)
+			if err != nil {
+				return err
+			}
+			obj = value
+		case "error", "method":
+			// Decoded below, once the values they refer to exist
+			continue
+		default:
+			return fmt.Errorf("unknown value type %q", def.Type)
+		}
+		dec.values[i] = obj
+	}
+	for i, def := range dec.state.Values {
+		if def.Type != "error" {
+			continue
+		}
+		var err error
+		if dec.values[i], err = dec.decodeError(def); err != nil {
+			return err
+		}
+	}
+	for i, def := range dec.state.Values {
+		if def.Type != "method" {
+			continue
+		}
+		if len(def.Items) != 1 {
+			return errors.New("invalid method")
+		}
+		receiver, err := dec.ref(def.Items[0])
+		if err != nil {
+			return err
+		}
+		if receiver == nil {
+			return errors.New("method has no receiver")
+		}
+		method, found := receiver.GetAttr(def.Str)
+		if !found {
+			return fmt.Errorf("%s has no method %q", receiver.Type(), def.Str)
+		}
+		dec.values[i] = method
+	}
+	for i, def := range dec.state.Values {
+		switch obj := dec.values[i].(type) {
+		case *object.List:
+			for _, ref := range def.Items {
+				item, err := dec.ref(ref)
+				if err != nil {
+					return err
+				}
+				if err := obj.Append(item); err != nil {
+					return err
+				}
+			}
+		case *object.Tuple:
+			// Items are filled in place since they may refer back to the
+			// tuple through a list or map
+			items := obj.Value()
+			for j, ref := range def.Items {
+				item, err := dec.ref(ref)
+				if err != nil {
+					return err
+				}
+				items[j] = item
+			}
+		}
+	}
+	// Maps are filled last, so that tuples used as keys have their items
+	for i, def := range dec.state.Values {
+		obj, ok := dec.values[i].(*object.Map)
+		if !ok {
+			continue
+		}
+		if len(def.Keys)+len(def.Refs) != len(def.Items) {
+			return errors.New("invalid map")
+		}
+		for j, key := range def.Keys {
+			item, err := dec.ref(def.Items[j])
+			if err != nil {
+				return err
+			}
+			obj.Set(key, item)
+		}
+		for j, ref := range def.Refs {
+			key, err := dec.ref(ref)
+			if err != nil {
+				return err
+			}
+			if key == nil {
+				return errors.New("invalid map key")
+			}
+			item, err := dec.ref(def.Items[len(def.Keys)+j])
+			if err != nil {
+				return err
+			}
+			if err := obj.SetItem(key, item); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (dec *snapshotDecoder) decodeError(def valueDef) (object.Object, error) {
+	if def.Ecode == "" && def.Data == 0 {
+		if def.Kind != 0 {
+			return object.NewError(object.NewStructuredError(
+				object.ErrorKind(def.Kind), def.Str, object.SourceLocation{}, nil)), nil
+		}
+		return object.NewError(errors.New(def.Str)), nil
+	}
+	var data *object.Map
+	if def.Data != 0 {
+		value, err := dec.ref(def.Data)
+		if err != nil {
+			return nil, err
+		}
+		var ok bool
+		if data, ok = value.(*object.Map); !ok {
+			return nil, fmt.Errorf("error data is a %s", value.Type())
+		}
+	}
+	se := object.NewScriptError(def.Ecode, data)
+	se.Message = def.Str
+	return object.NewError(se), nil
+}
+
+func (dec *snapshotDecoder) decodeHandlers() error {
+	vm := dec.vm
+	for _, def := range dec.state.Handlers {
+		code, err := dec.code(def.Code)
+		if err != nil {
+			return err
+		}
+		if def.FP < 0 || def.FP >= len(dec.state.Frames) {
+			return fmt.Errorf("invalid handler frame %d", def.FP)
+		}
+		handler := &bytecode.ExceptionHandler{
+			TryStart:     def.TryStart,
+			TryEnd:       def.TryEnd,
+			CatchStart:   def.CatchStart,
+			FinallyStart: def.FinallyStart,
+			CatchVarIdx:  def.CatchVarIdx,
+		}
+		for i := range code.ExceptionHandlers {
+			if code.ExceptionHandlers[i] == *handler {
+				handler = &code.ExceptionHandlers[i]
+				break
+			}
+		}
+		exc := exceptionFrame{
+			handler:   handler,
+			code:      code,
+			fp:        def.FP,
+			inCatch:   def.InCatch,
+			inFinally: def.InFinally,
+		}
+		if def.PendingError != 0 {
+			value, err := dec.ref(def.PendingError)
+			if err != nil {
+				return err
+			}
+			errObj, ok := value.(*object.Error)
+			if !ok {
+				return fmt.Errorf("pending error is a %s", value.Type())
+			}
+			exc.pendingError = errObj
+		}
+		if exc.pendingReturn, err = dec.ref(def.PendingReturn); err != nil {
+			return err
+		}
+		if vm.excStackSize >= len(vm.excStack) {
+			vm.excStack = append(vm.excStack, make([]exceptionFrame, len(vm.excStack))...)
+		}
+		vm.excStack[vm.excStackSize] = exc
+		vm.excStackSize++
+	}
+	return nil
+}
+
+func (dec *snapshotDecoder) code(index int) (*loadedCode, error) {
+	if index < 0 || index >= len(dec.codes) {
+		return nil, fmt.Errorf("invalid code index %d", index)
+	}
+	return dec.codes[index], nil
+}
+
+func (dec *snapshotDecoder) ref(ref int) (object.Object, error) {
+	if ref < 0 || ref > len(dec.values) {
+		return nil, fmt.Errorf("invalid value reference %d", ref)
+	}
+	if ref == 0 {
+		return nil, nil
+	}
+	return dec.values[ref-1], nil
+}
+
+func (dec *snapshotDecoder) cells(slots []int) ([]*object.Cell, error) {
+	cells := make([]*object.Cell, len(slots))
+	for i, slot := range slots {
+		if slot < 0 || slot >= len(dec.slots) {
+			return nil, fmt.Errorf("invalid slot %d", slot)
+		}
+		cells[i] = object.NewCell(dec.slots[slot])
+	}
+	return cells, nil
+}
+
+// global looks up a Go value by the name it was recorded under: a global
+// name, or a module name and attribute separated by a dot.
+func (dec *snapshotDecoder) global(name string) (object.Object, error) {
+	parts := strings.Split(name, ".")
+	value, found := dec.vm.globals[parts[0]]
+	for _, attr := range parts[1:] {
+		if !found {
+			break
+		}
+		value, found = value.GetAttr(attr)
+	}
+	if !found {
+		return nil, fmt.Errorf("global %q is not defined", name)
+	}
+	return value, nil
+}
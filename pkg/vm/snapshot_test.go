@@ -0,0 +1,350 @@
+package vm
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/compiler"
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+	"github.com/deepnoodle-ai/risor/v2/pkg/parser"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+// pausingGlobals returns globals for a VM whose checkpoint() function
+// pauses the VM that *machine points to.
+func pausingGlobals(machine **VirtualMachine) map[string]any {
+	globals := basicBuiltins()
+	globals["checkpoint"] = object.NewBuiltin("checkpoint", func(ctx context.Context, args ...object.Object) (object.Object, error) {
+		(*machine).Pause()
+		return object.Nil, nil
+	})
+	return globals
+}
+
+func compilePausing(t *testing.T, source string) (*VirtualMachine, **VirtualMachine) {
+	t.Helper()
+	ctx := context.Background()
+	machine := new(*VirtualMachine)
+	globals := pausingGlobals(machine)
+	var names []string
+	for name := range globals {
+		names = append(names, name)
+	}
+	ast, err := parser.Parse(ctx, source, nil)
+	assert.Nil(t, err)
+	code, err := compiler.Compile(ast, &compiler.Config{GlobalNames: names, Source: source})
+	assert.Nil(t, err)
+	vm, err := New(code, WithGlobals(globals))
+	assert.Nil(t, err)
+	*machine = vm
+	return vm, machine
+}
+
+// restorePausing snapshots a paused VM and restores it in a new VM.
+func restorePausing(t *testing.T, vm *VirtualMachine) *VirtualMachine {
+	t.Helper()
+	data, err := vm.Snapshot()
+	assert.Nil(t, err)
+	machine := new(*VirtualMachine)
+	restored, err := Restore(data, WithGlobals(pausingGlobals(machine)))
+	assert.Nil(t, err)
+	*machine = restored
+	assert.True(t, restored.Paused())
+	return restored
+}
+
+func TestPauseAndResume(t *testing.T) {
+	ctx := context.Background()
+	vm, _ := compilePausing(t, `
+let count = 0
+function step() {
+	count = count + 1
+	checkpoint()
+	return count * 10
+}
+let results = [step(), step()]
+results
+`)
+	assert.Equal(t, vm.Run(ctx), ErrPaused)
+	assert.True(t, vm.Paused())
+	assert.Equal(t, vm.Resume(ctx), ErrPaused)
+	assert.Nil(t, vm.Resume(ctx))
+	assert.False(t, vm.Paused())
+
+	result, ok := vm.TOS()
+	assert.True(t, ok)
+	assert.Equal(t, result.Inspect(), "[10, 20]")
+}
+
+func TestPauseInCallbackIsDeferred(t *testing.T) {
+	ctx := context.Background()
+	vm, _ := compilePausing(t, `
+let after = false
+let doubled = [1, 2].map(x => { checkpoint(); return x * 2 })
+after = true
+doubled
+`)
+	assert.Equal(t, vm.Run(ctx), ErrPaused)
+
+	// The pause took effect once map returned to the program
+	after, err := vm.Get("after")
+	assert.Nil(t, err)
+	assert.Equal(t, after, object.False)
+
+	assert.Nil(t, vm.Resume(ctx))
+	after, err = vm.Get("after")
+	assert.Nil(t, err)
+	assert.Equal(t, after, object.True)
+	doubled, err := vm.Get("doubled")
+	assert.Nil(t, err)
+	assert.Equal(t, doubled.Inspect(), "[2, 4]")
+}
+
+func TestResumeErrors(t *testing.T) {
+	ctx := context.Background()
+	vm, _ := compilePausing(t, `checkpoint(); 1`)
+	assert.Error(t, vm.Resume(ctx))
+	_, err := vm.Snapshot()
+	assert.Error(t, err)
+
+	assert.Equal(t, vm.Run(ctx), ErrPaused)
+	assert.Error(t, vm.Run(ctx))
+	assert.Nil(t, vm.Resume(ctx))
+}
+
+func TestSnapshotRestore(t *testing.T) {
+	ctx := context.Background()
+	vm, _ := compilePausing(t, `
+function counter() {
+	let n = 0
+	return function() { n = n + 1; return n }
+}
+let next = counter()
+let items = []
+let shared = {"items": items, "sqrt": math.sqrt}
+let total = 0
+function step(label) {
+	let before = next()
+	checkpoint()
+	items.append(label)
+	total = total + before
+	return before
+}
+step("a")
+step("b")
+try {
+	step("c")
+	throw "boom"
+} catch e {
+	shared.items.append(e.message())
+}
+[total, next(), items, shared.sqrt(16.0)]
+`)
+	err := vm.Run(ctx)
+	for err == ErrPaused {
+		vm = restorePausing(t, vm)
+		err = vm.Resume(ctx)
+	}
+	assert.Nil(t, err)
+
+	result, ok := vm.TOS()
+	assert.True(t, ok)
+	assert.Equal(t, result.Inspect(), `[6, 4, ["a", "b", "c", "boom"], 4]`)
+}
+
+func TestSnapshotSharedCaptures(t *testing.T) {
+	ctx := context.Background()
+	vm, _ := compilePausing(t, `
+function pair() {
+	let value = 1
+	let get = () => value
+	let set = v => { value = v }
+	checkpoint()
+	set(value + 41)
+	return [get(), value]
+}
+pair()
+`)
+	assert.Equal(t, vm.Run(ctx), ErrPaused)
+	vm = restorePausing(t, vm)
+	assert.Nil(t, vm.Resume(ctx))
+
+	result, ok := vm.TOS()
+	assert.True(t, ok)
+	assert.Equal(t, result.Inspect(), "[42, 42]")
+}
+
+func TestSnapshotTuple(t *testing.T) {
+	ctx := context.Background()
+	vm, _ := compilePausing(t, `
+let items = []
+let pair = (1, items)
+items.append(pair)
+checkpoint()
+items.append(2)
+[pair, pair[1][0][0], len(pair[1])]
+`)
+	assert.Equal(t, vm.Run(ctx), ErrPaused)
+	vm = restorePausing(t, vm)
+	assert.Nil(t, vm.Resume(ctx))
+
+	result, ok := vm.TOS()
+	assert.True(t, ok)
+	assert.Equal(t, result.Inspect(), "[(1, [(...), 2]), 1, 2]")
+}
+
+func TestSnapshotMapKeys(t *testing.T) {
+	ctx := context.Background()
+	vm, _ := compilePausing(t, `
+let point = (1, 2)
+let m = {"a": 1, 2: "two", true: "yes", (1, 2): point}
+checkpoint()
+m[3] = "three"
+[m, m[point], m[(1, 2)] == point]
+`)
+	assert.Equal(t, vm.Run(ctx), ErrPaused)
+	vm = restorePausing(t, vm)
+	assert.Nil(t, vm.Resume(ctx))
+
+	result, ok := vm.TOS()
+	assert.True(t, ok)
+	assert.Equal(t, result.Inspect(),
+		`[{true: "yes", 2: "two", 3: "three", "a": 1, (1, 2): (1, 2)}, (1, 2), true]`)
+}
+
+func TestSnapshotNonFiniteFloats(t *testing.T) {
+	ctx := context.Background()
+	vm, _ := compilePausing(t, `
+let values = [math.inf * 2, -math.inf * 2, math.inf * 0, 1.5]
+checkpoint()
+[values, values[2] == values[2]]
+`)
+	assert.Equal(t, vm.Run(ctx), ErrPaused)
+	vm = restorePausing(t, vm)
+	assert.Nil(t, vm.Resume(ctx))
+
+	result, ok := vm.TOS()
+	assert.True(t, ok)
+	assert.Equal(t, result.Inspect(), "[[+Inf, -Inf, NaN, 1.5], false]")
+}
+
+func TestSnapshotUnserializableValue(t *testing.T) {
+	ctx := context.Background()
+	machine := new(*VirtualMachine)
+	globals := pausingGlobals(machine)
+	globals["open_stream"] = object.NewBuiltin("open_stream", func(ctx context.Context, args ...object.Object) (object.Object, error) {
+		return object.NewStream(strings.NewReader("data")), nil
+	})
+	var names []string
+	for name := range globals {
+		names = append(names, name)
+	}
+	source := `let s = open_stream(); checkpoint(); s.read()`
+	ast, err := parser.Parse(ctx, source, nil)
+	assert.Nil(t, err)
+	code, err := compiler.Compile(ast, &compiler.Config{GlobalNames: names})
+	assert.Nil(t, err)
+	vm, err := New(code, WithGlobals(globals))
+	assert.Nil(t, err)
+	*machine = vm
+
+	assert.Equal(t, vm.Run(ctx), ErrPaused)
+	_, err = vm.Snapshot()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot serialize stream value")
+}
+
+func TestRestoreMissingGlobal(t *testing.T) {
+	ctx := context.Background()
+	vm, _ := compilePausing(t, `let f = math.sqrt; checkpoint(); f(4.0)`)
+	assert.Equal(t, vm.Run(ctx), ErrPaused)
+	data, err := vm.Snapshot()
+	assert.Nil(t, err)
+
+	globals := pausingGlobals(new(*VirtualMachine))
+	delete(globals, "math")
+	_, err = Restore(data, WithGlobals(globals))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `global "math" is not defined`)
+}
+
+func TestRunSteps(t *testing.T) {
+	ctx := context.Background()
+	source := `
+function sum(n) {
+	if (n == 0) { return 0 }
+	return n + sum(n - 1)
+}
+sum(%d)
+`
+	a, err := newVM(ctx, strings.ReplaceAll(source, "%d", "20"))
+	assert.Nil(t, err)
+	b, err := newVM(ctx, strings.ReplaceAll(source, "%d", "30"))
+	assert.Nil(t, err)
+
+	// Interleave both programs on this goroutine
+	statusA, statusB := StatusYielded, StatusYielded
+	turns := 0
+	for statusA == StatusYielded || statusB == StatusYielded {
+		if statusA == StatusYielded {
+			statusA, err = a.RunSteps(ctx, 10)
+			assert.Nil(t, err)
+		}
+		if statusB == StatusYielded {
+			statusB, err = b.RunSteps(ctx, 10)
+			assert.Nil(t, err)
+		}
+		turns++
+	}
+	assert.Greater(t, turns, 10)
+
+	result, ok := a.TOS()
+	assert.True(t, ok)
+	assert.Equal(t, result, object.NewInt(210))
+	result, ok = b.TOS()
+	assert.True(t, ok)
+	assert.Equal(t, result, object.NewInt(465))
+}
+
+func TestRunStepsSnapshot(t *testing.T) {
+	ctx := context.Background()
+	vm, _ := compilePausing(t, `
+let values = []
+function add(x) { values.append(x * x) }
+add(1)
+add(2)
+add(3)
+values
+`)
+	status, err := vm.RunSteps(ctx, 5)
+	for status == StatusYielded {
+		assert.Nil(t, err)
+		vm = restorePausing(t, vm)
+		status, err = vm.RunSteps(ctx, 5)
+	}
+	assert.Nil(t, err)
+
+	result, ok := vm.TOS()
+	assert.True(t, ok)
+	assert.Equal(t, result.Inspect(), "[1, 4, 9]")
+}
+
+func TestRunStepsErrors(t *testing.T) {
+	ctx := context.Background()
+	vm, err := newVM(ctx, `throw "boom"`)
+	assert.Nil(t, err)
+	_, err = vm.RunSteps(ctx, 0)
+	assert.Error(t, err)
+
+	status, err := vm.RunSteps(ctx, 100)
+	assert.Equal(t, status, StatusFinished)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "boom")
+
+	vm, _ = compilePausing(t, `checkpoint(); 1`)
+	status, err = vm.RunSteps(ctx, 100)
+	assert.Equal(t, status, StatusFinished)
+	assert.Equal(t, err, ErrPaused)
+	assert.True(t, vm.Paused())
+}
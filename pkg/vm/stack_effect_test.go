@@ -0,0 +1,188 @@
+package vm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/bytecode"
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+	"github.com/deepnoodle-ai/risor/v2/pkg/op"
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+// stackEffectCase runs a single instruction and records the value stack
+// depth before it and at the following instruction.
+type stackEffectCase struct {
+	main   []op.Code // main program
+	fnBody []op.Code // body of constant 4, if the instruction runs inside it
+	at     int       // IP of the instruction under test
+	next   int       // IP reached afterwards (defaults to the next instruction)
+}
+
+// stackEffectSeq returns a case that runs the prelude and then the
+// instruction in the main program.
+func stackEffectSeq(prelude []op.Code, instr ...op.Code) stackEffectCase {
+	main := append(append(append([]op.Code{}, prelude...), instr...), op.Nop, op.Halt)
+	return stackEffectCase{main: main, at: len(prelude)}
+}
+
+// stackEffectInFn returns a case that runs the body in a closure capturing
+// one cell, with the instruction under test at the given IP.
+func stackEffectInFn(body []op.Code, at int) stackEffectCase {
+	return stackEffectCase{
+		main:   []op.Code{op.Nil, op.StoreFast, 0, op.MakeCell, 0, 0, op.LoadClosure, 4, 1, op.Call, 0, op.Halt},
+		fnBody: body,
+		at:     at,
+	}
+}
+
+var stackEffectCases = map[op.Code]stackEffectCase{
+	op.Nop:                    stackEffectSeq(nil, op.Nop),
+	op.Call:                   stackEffectSeq([]op.Code{op.LoadGlobal, 0, op.LoadConst, 0, op.LoadConst, 1}, op.Call, 2),
+	op.CallSpread:             stackEffectSeq([]op.Code{op.LoadGlobal, 0, op.LoadConst, 0, op.BuildList, 1}, op.CallSpread),
+	op.JumpForward:            stackEffectSeq(nil, op.JumpForward, 2),
+	op.JumpBackward:           {main: []op.Code{op.JumpForward, 4, op.Nop, op.Halt, op.JumpBackward, 2}, at: 4, next: 2},
+	op.PopJumpForwardIfFalse:  stackEffectSeq([]op.Code{op.True}, op.PopJumpForwardIfFalse, 2),
+	op.PopJumpForwardIfTrue:   stackEffectSeq([]op.Code{op.False}, op.PopJumpForwardIfTrue, 2),
+	op.PopJumpForwardIfNotNil: stackEffectSeq([]op.Code{op.Nil}, op.PopJumpForwardIfNotNil, 2),
+	op.PopJumpForwardIfNil:    stackEffectSeq([]op.Code{op.True}, op.PopJumpForwardIfNil, 2),
+	op.LoadAttr:               stackEffectSeq([]op.Code{op.LoadConst, 2, op.LoadConst, 0, op.BuildMap, 1}, op.LoadAttr, 0),
+	op.LoadFast:               stackEffectSeq(nil, op.LoadFast, 0),
+	op.LoadFree:               stackEffectInFn([]op.Code{op.LoadFree, 0, op.Nop, op.ReturnValue}, 0),
+	op.LoadGlobal:             stackEffectSeq(nil, op.LoadGlobal, 1),
+	op.LoadConst:              stackEffectSeq(nil, op.LoadConst, 0),
+	op.LoadAttrOrNil:          stackEffectSeq([]op.Code{op.Nil}, op.LoadAttrOrNil, 0),
+	op.StoreAttr:              stackEffectSeq([]op.Code{op.LoadConst, 1, op.LoadConst, 2, op.LoadConst, 0, op.BuildMap, 1}, op.StoreAttr, 0),
+	op.StoreFast:              stackEffectSeq([]op.Code{op.LoadConst, 0}, op.StoreFast, 0),
+	op.StoreFree:              stackEffectInFn([]op.Code{op.Nil, op.StoreFree, 0, op.Nop, op.Nil, op.ReturnValue}, 1),
+	op.StoreGlobal:            stackEffectSeq([]op.Code{op.LoadConst, 0}, op.StoreGlobal, 1),
+	op.BinaryOp:               stackEffectSeq([]op.Code{op.LoadConst, 0, op.LoadConst, 1}, op.BinaryOp, op.Code(op.Add)),
+	op.CompareOp:              stackEffectSeq([]op.Code{op.LoadConst, 0, op.LoadConst, 1}, op.CompareOp, op.Code(op.LessThan)),
+	op.UnaryNegative:          stackEffectSeq([]op.Code{op.LoadConst, 1}, op.UnaryNegative),
+	op.UnaryNot:               stackEffectSeq([]op.Code{op.True}, op.UnaryNot),
+	op.BuildList:              stackEffectSeq([]op.Code{op.LoadConst, 0, op.LoadConst, 1}, op.BuildList, 2),
+	op.BuildMap:               stackEffectSeq([]op.Code{op.LoadConst, 2, op.LoadConst, 0, op.LoadConst, 3, op.LoadConst, 1}, op.BuildMap, 2),
+	op.BuildTuple:             stackEffectSeq([]op.Code{op.LoadConst, 0, op.LoadConst, 1}, op.BuildTuple, 2),
+	op.BuildString:            stackEffectSeq([]op.Code{op.LoadConst, 2, op.LoadConst, 0}, op.BuildString, 2),
+	op.ListAppend:             stackEffectSeq([]op.Code{op.BuildList, 0, op.LoadConst, 0}, op.ListAppend),
+	op.ListExtend:             stackEffectSeq([]op.Code{op.BuildList, 0, op.LoadConst, 0, op.BuildList, 1}, op.ListExtend),
+	op.MapMerge:               stackEffectSeq([]op.Code{op.BuildMap, 0, op.LoadConst, 2, op.LoadConst, 0, op.BuildMap, 1}, op.MapMerge),
+	op.MapSet:                 stackEffectSeq([]op.Code{op.BuildMap, 0, op.LoadConst, 2, op.LoadConst, 0}, op.MapSet),
+	op.BuildEnum:              stackEffectSeq([]op.Code{op.LoadConst, 2, op.LoadConst, 3}, op.BuildEnum, 1),
+	op.BinarySubscr:           stackEffectSeq([]op.Code{op.LoadConst, 1, op.BuildList, 1, op.LoadConst, 0}, op.BinarySubscr),
+	op.StoreSubscr:            stackEffectSeq([]op.Code{op.LoadConst, 1, op.LoadConst, 1, op.BuildList, 1, op.LoadConst, 0}, op.StoreSubscr),
+	op.ContainsOp:             stackEffectSeq([]op.Code{op.LoadConst, 0, op.BuildList, 1, op.LoadConst, 0}, op.ContainsOp, 0),
+	op.Length:                 stackEffectSeq([]op.Code{op.BuildList, 0}, op.Length),
+	op.Slice:                  stackEffectSeq([]op.Code{op.LoadConst, 1, op.BuildList, 1, op.LoadConst, 1, op.LoadConst, 0}, op.Slice),
+	op.Unpack:                 stackEffectSeq([]op.Code{op.LoadConst, 0, op.LoadConst, 1, op.BuildList, 2}, op.Unpack, 3),
+	op.Swap:                   stackEffectSeq([]op.Code{op.LoadConst, 0, op.LoadConst, 1}, op.Swap, 1),
+	op.Copy:                   stackEffectSeq([]op.Code{op.LoadConst, 0}, op.Copy, 0),
+	op.PopTop:                 stackEffectSeq([]op.Code{op.Nil}, op.PopTop),
+	op.Nil:                    stackEffectSeq(nil, op.Nil),
+	op.False:                  stackEffectSeq(nil, op.False),
+	op.True:                   stackEffectSeq(nil, op.True),
+	op.LoadClosure:            stackEffectSeq([]op.Code{op.MakeCell, 0, 0}, op.LoadClosure, 4, 1),
+	op.MakeCell:               stackEffectSeq(nil, op.MakeCell, 0, 0),
+	op.Partial:                stackEffectSeq([]op.Code{op.LoadGlobal, 0, op.LoadConst, 0}, op.Partial, 1),
+	op.PushExcept:             stackEffectSeq(nil, op.PushExcept, 3, 0),
+	op.PopExcept:              stackEffectSeq(nil, op.PopExcept),
+}
+
+// Opcodes with a static stack effect that cannot be observed by running
+// another instruction afterwards.
+var stackEffectExempt = map[op.Code]string{
+	op.Halt: "execution stops",
+}
+
+func (c stackEffectCase) code() *bytecode.Code {
+	fnBody := c.fnBody
+	if fnBody == nil {
+		fnBody = []op.Code{op.Nil, op.ReturnValue}
+	}
+	fnCode := bytecode.NewCode(bytecode.CodeParams{
+		ID:           "fn",
+		Name:         "fn",
+		Instructions: fnBody,
+	})
+	fn := bytecode.NewFunction(bytecode.FunctionParams{ID: "fn", Name: "fn", Code: fnCode})
+	return bytecode.NewCode(bytecode.CodeParams{
+		ID:           "main",
+		Name:         "main",
+		Instructions: c.main,
+		Constants:    []any{int64(0), int64(1), "a", "b", fn},
+		Names:        []string{"a"},
+		LocalCount:   1,
+		GlobalCount:  2,
+		GlobalNames:  []string{"f", "x"},
+		Children:     []*bytecode.Code{fnCode},
+	})
+}
+
+// TestStackEffects runs every opcode and checks that the observed change in
+// stack depth matches op.StackEffect, so that VM changes cannot silently
+// alter the documented instruction semantics.
+func TestStackEffects(t *testing.T) {
+	for i := 0; i < 256; i++ {
+		code := op.Code(i)
+		info := op.GetInfo(code)
+		if info.Name == "" {
+			continue
+		}
+		t.Run(info.Name, func(t *testing.T) {
+			if _, ok := stackEffectExempt[code]; ok {
+				t.Skip(stackEffectExempt[code])
+			}
+			tc, hasCase := stackEffectCases[code]
+			bc := tc.code()
+			var instrs []op.Code
+			frameDepth := 1
+			if tc.fnBody != nil {
+				instrs, frameDepth = tc.fnBody, 2
+			} else {
+				instrs = tc.main
+			}
+			if !hasCase {
+				_, _, static := op.StackEffect(code, make([]op.Code, info.OperandCount)...)
+				if static {
+					t.Fatalf("no stack effect case for %s", info.Name)
+				}
+				t.Skip("stack effect depends on runtime state")
+			}
+			assert.Equal(t, instrs[tc.at], code)
+			assert.Nil(t, bytecode.Verify(bc))
+
+			operands := instrs[tc.at+1 : tc.at+1+info.OperandCount]
+			pops, pushes, ok := op.StackEffect(code, operands...)
+			assert.True(t, ok)
+			next := tc.next
+			if next == 0 {
+				next = tc.at + 1 + info.OperandCount
+			}
+
+			observer := &TestObserver{}
+			f := object.NewBuiltin("f", func(ctx context.Context, args ...object.Object) (object.Object, error) {
+				return object.Nil, nil
+			})
+			machine, err := New(bc, WithObserver(observer), WithGlobals(map[string]any{"f": f}))
+			assert.Nil(t, err)
+			assert.Nil(t, machine.Run(context.Background()))
+
+			before, after := -1, -1
+			for _, step := range observer.Steps {
+				if step.FrameDepth != frameDepth {
+					continue
+				}
+				if before < 0 && step.IP == tc.at {
+					before = step.StackDepth
+				} else if before >= 0 && step.IP == next {
+					after = step.StackDepth
+					break
+				}
+			}
+			assert.True(t, before >= 0, "instruction was not executed")
+			assert.True(t, after >= 0, "next instruction was not reached")
+			assert.True(t, before >= pops, "stack underflow: depth %d, pops %d", before, pops)
+			assert.Equal(t, after-before, pushes-pops, "stack depth change")
+		})
+	}
+}
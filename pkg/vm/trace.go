@@ -0,0 +1,268 @@
+package vm
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+)
+
+// DefaultTraceCapacity is the number of entries a TraceRecorder keeps when
+// TraceConfig.Capacity is not set.
+const DefaultTraceCapacity = 10000
+
+// maxTraceValueLength limits the length of stack values recorded in a trace.
+const maxTraceValueLength = 80
+
+// TraceConfig configures a TraceRecorder.
+type TraceConfig struct {
+	// StepMode selects the instructions that are recorded: StepAll records
+	// every instruction, StepOnLine one instruction per source line
+	// executed, and StepNone only calls and returns.
+	StepMode StepMode
+
+	// StackSnapshot is the number of values from the top of the value
+	// stack to record with each instruction.
+	StackSnapshot int
+
+	// Capacity is the number of most recent entries to keep in memory.
+	// Older entries are discarded. Defaults to DefaultTraceCapacity.
+	Capacity int
+
+	// Output, if set, receives every entry as a line of JSON as soon as it
+	// is recorded, in addition to the entries kept in memory.
+	Output io.Writer
+}
+
+// TraceEntry is one event in an execution trace.
+type TraceEntry struct {
+	// Seq numbers entries in the order they were recorded, starting at 1.
+	Seq int64 `json:"seq"`
+
+	// Kind is "step", "call", or "return".
+	Kind string `json:"kind"`
+
+	// IP, Opcode, and Operands describe the instruction of a step.
+	IP       int    `json:"ip,omitempty"`
+	Opcode   string `json:"op,omitempty"`
+	Operands []int  `json:"operands,omitempty"`
+
+	// Function is the function called or returning, and ArgCount the
+	// number of arguments passed to a call.
+	Function string `json:"function,omitempty"`
+	ArgCount int    `json:"args,omitempty"`
+
+	// File, Line, and Column give the source location of the event.
+	File   string `json:"file,omitempty"`
+	Line   int    `json:"line,omitempty"`
+	Column int    `json:"column,omitempty"`
+
+	// FrameDepth is the depth of the call stack, and StackDepth the depth
+	// of the value stack at a step.
+	FrameDepth int `json:"frame_depth"`
+	StackDepth int `json:"stack_depth,omitempty"`
+
+	// Stack holds the values at the top of the value stack, topmost first,
+	// as inspected strings.
+	Stack []string `json:"stack,omitempty"`
+}
+
+// TraceRecorder is an Observer that records VM execution into a ring buffer
+// of the most recent entries, and optionally streams every entry to a
+// writer as newline-delimited JSON. Use it to capture what a script did
+// leading up to a failure:
+//
+//	rec := vm.NewTraceRecorder(vm.TraceConfig{StepMode: vm.StepOnLine})
+//	_, err := risor.Run(ctx, code, risor.WithObserver(rec))
+//	if err != nil {
+//		rec.WriteTo(traceFile)
+//	}
+//
+// Traces are read back with ReadTrace. A TraceRecorder is safe for
+// concurrent use.
+type TraceRecorder struct {
+	cfg     TraceConfig
+	mu      sync.Mutex
+	entries []TraceEntry
+	next    int // index of the next entry to write in entries
+	seq     int64
+	enc     *json.Encoder
+	err     error
+}
+
+// NewTraceRecorder creates a trace recorder with the given configuration.
+func NewTraceRecorder(cfg TraceConfig) *TraceRecorder {
+	if cfg.Capacity <= 0 {
+		cfg.Capacity = DefaultTraceCapacity
+	}
+	r := &TraceRecorder{cfg: cfg}
+	if cfg.Output != nil {
+		r.enc = json.NewEncoder(cfg.Output)
+	}
+	return r
+}
+
+// Config implements Observer.
+func (r *TraceRecorder) Config() ObserverConfig {
+	cfg := NewObserverConfig(r.cfg.StepMode)
+	cfg.StackSnapshot = r.cfg.StackSnapshot
+	return cfg
+}
+
+// OnStep implements Observer.
+func (r *TraceRecorder) OnStep(event StepEvent) bool {
+	entry := TraceEntry{
+		Kind:       "step",
+		IP:         event.IP,
+		Opcode:     event.OpcodeName,
+		File:       event.Location.Filename,
+		Line:       event.Location.Line,
+		Column:     event.Location.Column,
+		FrameDepth: event.FrameDepth,
+		StackDepth: event.StackDepth,
+	}
+	for _, operand := range event.Operands {
+		entry.Operands = append(entry.Operands, int(operand))
+	}
+	for _, value := range event.Stack {
+		entry.Stack = append(entry.Stack, traceValue(value))
+	}
+	r.record(entry)
+	return true
+}
+
+// OnCall implements Observer.
+func (r *TraceRecorder) OnCall(event CallEvent) bool {
+	r.record(TraceEntry{
+		Kind:       "call",
+		Function:   event.FunctionName,
+		ArgCount:   event.ArgCount,
+		File:       event.Location.Filename,
+		Line:       event.Location.Line,
+		Column:     event.Location.Column,
+		FrameDepth: event.FrameDepth,
+	})
+	return true
+}
+
+// OnReturn implements Observer.
+func (r *TraceRecorder) OnReturn(event ReturnEvent) bool {
+	r.record(TraceEntry{
+		Kind:       "return",
+		Function:   event.FunctionName,
+		File:       event.Location.Filename,
+		Line:       event.Location.Line,
+		Column:     event.Location.Column,
+		FrameDepth: event.FrameDepth,
+	})
+	return true
+}
+
+func (r *TraceRecorder) record(entry TraceEntry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.seq++
+	entry.Seq = r.seq
+	if len(r.entries) < r.cfg.Capacity {
+		r.entries = append(r.entries, entry)
+	} else {
+		r.entries[r.next] = entry
+	}
+	r.next = (r.next + 1) % r.cfg.Capacity
+	if r.enc != nil && r.err == nil {
+		r.err = r.enc.Encode(entry)
+	}
+}
+
+// Entries returns the entries kept in memory, oldest first.
+func (r *TraceRecorder) Entries() []TraceEntry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.entries) < r.cfg.Capacity {
+		return append([]TraceEntry(nil), r.entries...)
+	}
+	entries := make([]TraceEntry, 0, len(r.entries))
+	entries = append(entries, r.entries[r.next:]...)
+	return append(entries, r.entries[:r.next]...)
+}
+
+// Dropped returns the number of entries discarded from memory because the
+// recorder reached its capacity.
+func (r *TraceRecorder) Dropped() int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.seq - int64(len(r.entries))
+}
+
+// Err returns the first error encountered writing to TraceConfig.Output.
+// Once writing fails, entries are no longer streamed but are still kept
+// in memory.
+func (r *TraceRecorder) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+// WriteTo writes the entries kept in memory to w as newline-delimited JSON,
+// the format read by ReadTrace.
+func (r *TraceRecorder) WriteTo(w io.Writer) (int64, error) {
+	cw := &countingWriter{w: w}
+	enc := json.NewEncoder(cw)
+	for _, entry := range r.Entries() {
+		if err := enc.Encode(entry); err != nil {
+			return cw.n, err
+		}
+	}
+	return cw.n, nil
+}
+
+// ReadTrace reads a trace written by a TraceRecorder.
+func ReadTrace(r io.Reader) ([]TraceEntry, error) {
+	var entries []TraceEntry
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry TraceEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("trace line %d: %w", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// traceValue returns the inspected form of a stack value, truncated to keep
+// traces small.
+func traceValue(value object.Object) string {
+	if value == nil {
+		return "<empty>"
+	}
+	s := value.Inspect()
+	if utf8.RuneCountInString(s) > maxTraceValueLength {
+		s = string([]rune(s)[:maxTraceValueLength-3]) + "..."
+	}
+	return s
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
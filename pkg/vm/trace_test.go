@@ -0,0 +1,136 @@
+package vm
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/compiler"
+	"github.com/deepnoodle-ai/risor/v2/pkg/parser"
+)
+
+func runTraced(t *testing.T, source string, rec *TraceRecorder) error {
+	t.Helper()
+	ast, err := parser.Parse(context.Background(), source, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, err := compiler.Compile(ast, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vm, err := New(code, WithObserver(rec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return vm.Run(context.Background())
+}
+
+func TestTraceRecorderSteps(t *testing.T) {
+	rec := NewTraceRecorder(TraceConfig{StackSnapshot: 2})
+	if err := runTraced(t, "let x = 40\nx + 2", rec); err != nil {
+		t.Fatal(err)
+	}
+
+	entries := rec.Entries()
+	if len(entries) == 0 {
+		t.Fatal("expected trace entries")
+	}
+	if entries[0].Seq != 1 || entries[0].Kind != "step" || entries[0].Opcode != "LOAD_CONST" {
+		t.Errorf("unexpected first entry: %+v", entries[0])
+	}
+	if len(entries[0].Operands) != 1 {
+		t.Errorf("expected LOAD_CONST to record its operand, got %v", entries[0].Operands)
+	}
+
+	// The add instruction sees both of its inputs on the stack
+	var found bool
+	for _, entry := range entries {
+		if entry.Opcode == "BINARY_OP" {
+			found = true
+			if entry.Line != 2 || strings.Join(entry.Stack, ",") != "2,40" {
+				t.Errorf("unexpected BINARY_OP entry: %+v", entry)
+			}
+		}
+	}
+	if !found {
+		t.Error("expected a BINARY_OP entry")
+	}
+}
+
+func TestTraceRecorderCalls(t *testing.T) {
+	rec := NewTraceRecorder(TraceConfig{StepMode: StepNone})
+	source := "function add(a, b) { a + b }\nadd(1, 2)"
+	if err := runTraced(t, source, rec); err != nil {
+		t.Fatal(err)
+	}
+	entries := rec.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("expected a call and a return, got %+v", entries)
+	}
+	if entries[0].Kind != "call" || entries[0].Function != "add" || entries[0].ArgCount != 2 {
+		t.Errorf("unexpected call entry: %+v", entries[0])
+	}
+	if entries[1].Kind != "return" || entries[1].Function != "add" {
+		t.Errorf("unexpected return entry: %+v", entries[1])
+	}
+}
+
+func TestTraceRecorderRingBuffer(t *testing.T) {
+	rec := NewTraceRecorder(TraceConfig{Capacity: 5})
+	if err := runTraced(t, "[1, 2, 3, 4, 5].map(x => x * 2)", rec); err != nil {
+		t.Fatal(err)
+	}
+	entries := rec.Entries()
+	if len(entries) != 5 {
+		t.Fatalf("expected 5 entries, got %d", len(entries))
+	}
+	if rec.Dropped() == 0 {
+		t.Error("expected dropped entries")
+	}
+	for i := 1; i < len(entries); i++ {
+		if entries[i].Seq != entries[i-1].Seq+1 {
+			t.Errorf("entries out of order: %d after %d", entries[i].Seq, entries[i-1].Seq)
+		}
+	}
+	if last := entries[len(entries)-1]; last.Seq != rec.Dropped()+5 {
+		t.Errorf("expected the most recent entries, last seq is %d", last.Seq)
+	}
+}
+
+func TestTraceRecorderOutputAndReadTrace(t *testing.T) {
+	var stream bytes.Buffer
+	rec := NewTraceRecorder(TraceConfig{StepMode: StepOnLine, Capacity: 2, Output: &stream})
+	err := runTraced(t, "let x = 1\nlet y = 2\nthrow \"boom\"", rec)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	// The stream has every entry, while memory keeps the last two
+	streamed, err := ReadTrace(&stream)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(streamed) != 3 || streamed[2].Line != 3 {
+		t.Errorf("expected one entry per line, got %+v", streamed)
+	}
+
+	var saved bytes.Buffer
+	n, err := rec.WriteTo(&saved)
+	if err != nil || n != int64(saved.Len()) {
+		t.Fatalf("WriteTo returned %d, %v", n, err)
+	}
+	loaded, err := ReadTrace(&saved)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded) != 2 || loaded[0].Seq != 2 || loaded[1].Line != 3 {
+		t.Errorf("unexpected saved trace: %+v", loaded)
+	}
+
+	if _, err := ReadTrace(strings.NewReader("{\"seq\": 1}\nnot json\n")); err == nil ||
+		!strings.Contains(err.Error(), "trace line 2") {
+		t.Errorf("expected a line number in the error, got %v", err)
+	}
+}
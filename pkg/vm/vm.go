@@ -5,6 +5,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"runtime/debug"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -29,12 +30,30 @@ const (
 	// DefaultContextCheckInterval is the number of instructions between
 	// deterministic checks of ctx.Done(). Set to 0 to disable.
 	DefaultContextCheckInterval = 1000
+
+	// ExitHookTimeout is how long exit hooks may run after the context of
+	// the run was cancelled or timed out.
+	ExitHookTimeout = time.Second
 )
 
 var (
 	ErrGlobalNotFound    = errors.New("global not found")
 	ErrStepLimitExceeded = errors.New("step limit exceeded")
 	ErrStackOverflow     = errors.New("stack overflow")
+
+	// ErrPaused is returned by Run, RunCode, and Resume when the program
+	// paused at the request of Pause. The VM keeps its state, so the program
+	// can continue with Resume, or be saved with Snapshot.
+	ErrPaused = errors.New("paused")
+
+	// errStepBudget stops a program that used the step budget of RunSteps.
+	errStepBudget = errors.New("step budget exhausted")
+)
+
+// Values of the halt flag, which the eval loop checks before each instruction
+const (
+	haltCancel = 1 // The context was cancelled
+	haltPause  = 2 // Pause was requested
 )
 
 type VirtualMachine struct {
@@ -50,7 +69,10 @@ type VirtualMachine struct {
 	globals      map[string]object.Object
 	loadedCode   map[*bytecode.Code]*loadedCode
 	running      bool
+	paused       bool // Stopped by Pause, with the program ready to resume
 	runMutex     sync.Mutex
+	stopWatch    chan struct{} // stops the context watcher of the current run
+	watchDone    chan struct{} // closed when the context watcher exits
 	tmp          [MaxArgs]object.Object
 	stack        [MaxStackDepth]object.Object
 	frames       []frame // Dynamically sized, grows up to MaxFrameDepth
@@ -72,6 +94,15 @@ type VirtualMachine struct {
 	// observerConfig caches the normalized config from the observer.
 	observerConfig ObserverConfig
 
+	// watcher and watched are set when the observer watches variables.
+	watcher Watcher
+	watched map[string]bool
+
+	// breaker and breakpoints are set when the observer sets breakpoints.
+	// breakpoints is keyed by source line.
+	breaker     BreakpointObserver
+	breakpoints map[int][]*breakpointState
+
 	// Observer state for StepSampled and StepOnLine modes.
 	sampleCount      int         // Counter for StepSampled mode
 	lastObservedCode *loadedCode // Code object from last OnStep (changes on function call/return)
@@ -86,6 +117,37 @@ type VirtualMachine struct {
 	// ensuring we preserve the full call stack when a panic occurs.
 	panicStack []object.StackFrame
 
+	// evalDepth counts the evals on the Go stack, and pausable is set while
+	// the program itself runs, as opposed to exit hooks or calls made by the
+	// host. A pause is only honored by the outermost eval of the program,
+	// since evals nested under a Go function cannot be resumed later.
+	evalDepth int
+	pausable  bool
+
+	// stepBudget is the number of instructions that may still execute, if
+	// budgeted is set. Once it is used, the run stops with budgetErr: either
+	// errStepBudget, which pauses the program for RunSteps, or
+	// ErrStepLimitExceeded for the limits of CallWithLimits.
+	stepBudget int64
+	budgeted   bool
+	budgetErr  error
+
+	// raised is the most recent error raised in the program, with the
+	// location and stack where it was first raised. An error that escapes
+	// the program is reported with this trace.
+	raised      error
+	raisedLoc   object.SourceLocation
+	raisedStack []object.StackFrame
+
+	// exitHooks are callables registered by the running program (for example
+	// via atexit.register) to run after the main program finishes.
+	exitHooks []object.Callable
+
+	// tasks are the tasks started by the current run or call, which are
+	// cancelled and waited for when it ends.
+	tasksMu sync.Mutex
+	tasks   []*object.Task
+
 	// typeRegistry handles Go/Risor type conversions.
 	// If nil, object.DefaultRegistry() is used.
 	typeRegistry *object.TypeRegistry
@@ -100,6 +162,15 @@ type VirtualMachine struct {
 	maxFrameDepth int
 	timeout       time.Duration // Execution timeout. 0 = no timeout.
 
+	// recoverPanics converts panics in Go callables into catchable errors.
+	recoverPanics bool
+
+	// interceptor is notified of calls to Go callables, if set.
+	interceptor CallInterceptor
+
+	// freezeGlobals makes list and map globals read-only to scripts.
+	freezeGlobals bool
+
 	// Step counting state for resource limits. These fields are stored on the
 	// VM (rather than as local variables in eval) so that step counting persists
 	// across recursive eval calls. This is important because methods like
@@ -110,8 +181,11 @@ type VirtualMachine struct {
 	// Note: Step counting is approximate for performance. Steps are counted in
 	// batches of contextCheckInterval, so actual execution may exceed maxSteps
 	// by up to (contextCheckInterval - 1) instructions before detection.
-	stepCount        int64 // Approximate total instructions executed across all eval calls
-	stepCheckCounter int   // Instructions since last periodic check
+	//
+	// VMs forked from this one share its step counter, so steps run on other
+	// goroutines count against the same limit.
+	stepCount        *atomic.Int64 // Approximate total instructions executed across all eval calls
+	stepCheckCounter int           // Instructions since last periodic check
 }
 
 // exceptionFrame represents an active exception handler on the exception stack.
@@ -149,6 +223,7 @@ func createVM(options []Option) (*VirtualMachine, error) {
 		globals:              map[string]object.Object{},
 		loadedCode:           map[*bytecode.Code]*loadedCode{},
 		contextCheckInterval: DefaultContextCheckInterval,
+		stepCount:            new(atomic.Int64),
 		frames:               make([]frame, InitialFrameCapacity),
 		excStack:             make([]exceptionFrame, 8), // Small initial exception stack
 	}
@@ -180,6 +255,13 @@ func (vm *VirtualMachine) applyOptions(options []Option) error {
 	if err != nil {
 		return fmt.Errorf("invalid global provided: %v", err)
 	}
+	if vm.freezeGlobals {
+		for name, value := range vm.globals {
+			if err := object.Freeze(value); err != nil {
+				return fmt.Errorf("invalid global %q: %v", name, err)
+			}
+		}
+	}
 	return nil
 }
 
@@ -188,7 +270,21 @@ func (vm *VirtualMachine) configureObserver() {
 	if vm.observer == nil {
 		return
 	}
-	vm.observerConfig = NormalizeConfig(vm.observer.Config())
+	vm.setObserverConfig(vm.observer.Config())
+}
+
+// setObserverConfig normalizes and caches the observer configuration.
+func (vm *VirtualMachine) setObserverConfig(cfg ObserverConfig) {
+	vm.observerConfig = NormalizeConfig(cfg)
+	vm.watcher, vm.watched = nil, nil
+	if watcher, ok := vm.observer.(Watcher); ok && len(cfg.Watch) > 0 {
+		vm.watcher = watcher
+		vm.watched = make(map[string]bool, len(cfg.Watch))
+		for _, name := range cfg.Watch {
+			vm.watched[name] = true
+		}
+	}
+	vm.setBreakpoints(cfg.Breakpoints)
 }
 
 // SetObserverConfig updates the observer configuration on a paused VM.
@@ -199,7 +295,7 @@ func (vm *VirtualMachine) SetObserverConfig(cfg ObserverConfig) error {
 	if vm.running {
 		return errors.New("cannot update observer config while VM is running")
 	}
-	vm.observerConfig = NormalizeConfig(cfg)
+	vm.setObserverConfig(cfg)
 	return nil
 }
 
@@ -210,6 +306,12 @@ func (vm *VirtualMachine) dispatchObserver(opcode op.Code) error {
 		return nil
 	}
 
+	if vm.breakpoints != nil {
+		if err := vm.checkBreakpoints(); err != nil {
+			return err
+		}
+	}
+
 	cfg := vm.observerConfig
 	var shouldStep bool
 	var loc object.SourceLocation
@@ -238,20 +340,54 @@ func (vm *VirtualMachine) dispatchObserver(opcode op.Code) error {
 		loc = vm.activeCode.LocationAt(vm.ip)
 	}
 
+	info := op.GetInfo(opcode)
 	event := StepEvent{
 		IP:         vm.ip,
 		Opcode:     opcode,
-		OpcodeName: op.GetInfo(opcode).Name,
+		OpcodeName: info.Name,
 		Location:   loc,
 		StackDepth: vm.sp + 1,
 		FrameDepth: vm.fp + 1,
 	}
+	if end := vm.ip + 1 + info.OperandCount; info.OperandCount > 0 && end <= len(vm.activeCode.Instructions) {
+		event.Operands = vm.activeCode.Instructions[vm.ip+1 : end : end]
+	}
+	if n := min(cfg.StackSnapshot, vm.sp+1); n > 0 {
+		event.Stack = make([]object.Object, n)
+		for i := range n {
+			event.Stack[i] = vm.stack[vm.sp-i]
+		}
+	}
 	if !vm.observer.OnStep(event) {
 		return fmt.Errorf("execution halted by observer")
 	}
 	return nil
 }
 
+// notifyWatch calls the observer's OnWatch method if the named variable is
+// watched and its value changed. Returns an error if the observer halts
+// execution.
+func (vm *VirtualMachine) notifyWatch(name string, scope WatchScope, old, value object.Object) error {
+	if !vm.watched[name] || (old != nil && value != nil && old.Equals(value)) {
+		return nil
+	}
+	event := WatchEvent{
+		Name:       name,
+		Scope:      scope,
+		Old:        old,
+		New:        value,
+		Location:   vm.getCurrentLocation(),
+		FrameDepth: vm.fp + 1,
+	}
+	if scope == WatchLocal && vm.activeFrame.fn != nil {
+		event.FunctionName = vm.activeFrame.fn.Name()
+	}
+	if !vm.watcher.OnWatch(event) {
+		return fmt.Errorf("execution halted by observer")
+	}
+	return nil
+}
+
 // checkLineChanged returns true if the source location has changed since
 // the last OnStep call, along with the current location.
 func (vm *VirtualMachine) checkLineChanged() (bool, object.SourceLocation) {
@@ -280,21 +416,52 @@ func (vm *VirtualMachine) start(ctx context.Context) error {
 	}
 	vm.running = true
 	vm.startCount++
-	// Halt execution when the context is cancelled
-	vm.halt = 0
-	if doneChan := ctx.Done(); doneChan != nil {
-		go func() {
-			<-doneChan
-			atomic.StoreInt32(&vm.halt, 1)
-		}()
-	}
+	vm.evalDepth = 0
+	vm.pausable = false
+	vm.budgeted = false
+	// Halt execution when the context is cancelled. The watcher exits when
+	// the run stops, so cancelling the context afterwards cannot halt a
+	// later run on the same VM.
+	atomic.StoreInt32(&vm.halt, 0)
+	vm.watchContext(ctx)
 	return nil
 }
 
+// watchContext starts a goroutine that sets the halt flag when ctx is
+// cancelled. The caller must hold runMutex.
+func (vm *VirtualMachine) watchContext(ctx context.Context) {
+	doneChan := ctx.Done()
+	if doneChan == nil {
+		return
+	}
+	stopWatch := make(chan struct{})
+	watchDone := make(chan struct{})
+	vm.stopWatch, vm.watchDone = stopWatch, watchDone
+	go func() {
+		defer close(watchDone)
+		select {
+		case <-doneChan:
+			atomic.StoreInt32(&vm.halt, haltCancel)
+		case <-stopWatch:
+		}
+	}()
+}
+
+// stopWatching stops the context watcher, if any. The caller must hold
+// runMutex.
+func (vm *VirtualMachine) stopWatching() {
+	if vm.stopWatch != nil {
+		close(vm.stopWatch)
+		<-vm.watchDone
+		vm.stopWatch, vm.watchDone = nil, nil
+	}
+}
+
 func (vm *VirtualMachine) stop() {
 	vm.runMutex.Lock()
 	defer vm.runMutex.Unlock()
 	vm.running = false
+	vm.stopWatching()
 }
 
 // TypeRegistry returns the VM's type registry for Go/Risor conversions.
@@ -324,7 +491,102 @@ func (vm *VirtualMachine) RunCode(ctx context.Context, codeToRun *bytecode.Code,
 }
 
 // runCodeInternal is the shared implementation for Run and RunCode
-func (vm *VirtualMachine) runCodeInternal(ctx context.Context, codeToRun *bytecode.Code, resetState bool) (err error) {
+func (vm *VirtualMachine) runCodeInternal(ctx context.Context, codeToRun *bytecode.Code, resetState bool) error {
+	if vm.paused {
+		return errors.New("vm is paused; call Resume to continue")
+	}
+	return vm.execute(ctx, func(ctx context.Context) error {
+		return vm.runMain(ctx, codeToRun, resetState)
+	})
+}
+
+// Resume continues a program that stopped with ErrPaused, whether on this
+// VM or on one restored from a snapshot. Like Run, it returns ErrPaused if
+// the program pauses again.
+func (vm *VirtualMachine) Resume(ctx context.Context) error {
+	if !vm.paused {
+		return errors.New("vm is not paused")
+	}
+	return vm.execute(ctx, vm.resume)
+}
+
+func (vm *VirtualMachine) resume(ctx context.Context) error {
+	vm.paused = false
+	return vm.evalMain(vm.initContext(ctx))
+}
+
+// RunStatus describes the state of a program after RunSteps.
+type RunStatus int
+
+const (
+	// StatusFinished means the program ran to completion, or failed with
+	// the error RunSteps returned.
+	StatusFinished RunStatus = iota
+
+	// StatusYielded means the program used its step budget. It is paused,
+	// and continues with the next call to RunSteps or Resume.
+	StatusYielded
+)
+
+// RunSteps runs the program for at most n instructions, starting it if it
+// has not started and resuming it if it is paused. When the budget runs out
+// the program pauses and RunSteps returns StatusYielded, so a host can
+// interleave many programs on one goroutine by calling RunSteps on each in
+// turn. A paused program can also be snapshotted between calls.
+//
+// Instructions of script functions called back by Go functions, as with
+// list.map, count against the budget, but the program can only yield once
+// the Go function returns, so a budget may be overrun while such a callback
+// runs. If Pause is called during the run, RunSteps returns ErrPaused.
+func (vm *VirtualMachine) RunSteps(ctx context.Context, n int64) (RunStatus, error) {
+	if n <= 0 {
+		return StatusFinished, fmt.Errorf("step budget must be positive (got %d)", n)
+	}
+	if !vm.paused && vm.main == nil {
+		return StatusFinished, fmt.Errorf("no main code available")
+	}
+	paused := vm.paused
+	err := vm.execute(ctx, func(ctx context.Context) error {
+		vm.budgeted = true
+		vm.stepBudget = n
+		vm.budgetErr = errStepBudget
+		if paused {
+			return vm.resume(ctx)
+		}
+		return vm.runMain(ctx, vm.main, false)
+	})
+	if err == errStepBudget {
+		return StatusYielded, nil
+	}
+	return StatusFinished, err
+}
+
+// isPause reports whether err stopped the program in a resumable state.
+func isPause(err error) bool {
+	return err == ErrPaused || err == errStepBudget
+}
+
+// Pause asks the running program to stop before its next instruction. Run,
+// RunCode, or Resume then return ErrPaused, leaving the program ready to be
+// resumed or snapshotted. Pause may be called from any goroutine, including
+// from a Go function the program called, in which case the program pauses
+// once that function returns. While a script function runs as a callback of
+// a Go function, as with list.map, the pause is deferred until control is
+// back in the program. Pause has no effect if the VM is not running.
+func (vm *VirtualMachine) Pause() {
+	atomic.CompareAndSwapInt32(&vm.halt, 0, haltPause)
+}
+
+// Paused reports whether the VM holds a paused program.
+func (vm *VirtualMachine) Paused() bool {
+	vm.runMutex.Lock()
+	defer vm.runMutex.Unlock()
+	return vm.paused && !vm.running
+}
+
+// execute runs fn with the VM marked as running, applying the configured
+// timeout to its context.
+func (vm *VirtualMachine) execute(ctx context.Context, fn func(ctx context.Context) error) (err error) {
 	// Apply timeout to context if configured
 	if vm.timeout > 0 {
 		var cancel context.CancelFunc
@@ -345,6 +607,11 @@ func (vm *VirtualMachine) runCodeInternal(ctx context.Context, codeToRun *byteco
 		}
 		vm.stop()
 	}()
+	return fn(ctx)
+}
+
+// runMain activates codeToRun in frame zero and evaluates it.
+func (vm *VirtualMachine) runMain(ctx context.Context, codeToRun *bytecode.Code, resetState bool) error {
 
 	// Reset VM state for new code execution if requested
 	if resetState && vm.startCount > 1 {
@@ -397,8 +664,109 @@ func (vm *VirtualMachine) runCodeInternal(ctx context.Context, codeToRun *byteco
 		return err
 	}
 
-	// Run the entrypoint until completion
-	return vm.eval(vm.initContext(ctx))
+	// Run the entrypoint until completion, then any registered exit hooks
+	vm.exitHooks = nil
+	vm.raised = nil
+	return vm.evalMain(vm.initContext(ctx))
+}
+
+// evalMain evaluates the program from its current state, then runs the exit
+// hooks, unless the program paused.
+func (vm *VirtualMachine) evalMain(ctx context.Context) error {
+	vm.pausable = true
+	err := vm.eval(ctx)
+	vm.pausable = false
+	if isPause(err) {
+		vm.paused = true
+		return err
+	}
+	if err != nil {
+		// Frames of a resumed program were entered by earlier evals
+		for vm.fp > 0 {
+			vm.leaveFrame()
+		}
+	}
+	err = vm.runExitHooks(ctx, err)
+	vm.stopTasks()
+	return vm.traceError(err)
+}
+
+// registerExitHook records a callable to run once the main program finishes.
+func (vm *VirtualMachine) registerExitHook(fn object.Callable) error {
+	vm.exitHooks = append(vm.exitHooks, fn)
+	return nil
+}
+
+// registerTask records a task started by the running program.
+func (vm *VirtualMachine) registerTask(t *object.Task) {
+	vm.tasksMu.Lock()
+	defer vm.tasksMu.Unlock()
+	vm.tasks = append(vm.tasks, t)
+}
+
+// stopTasks cancels the tasks started by the program and waits for them to
+// finish, so that none are left running once the run or call returns.
+func (vm *VirtualMachine) stopTasks() {
+	vm.tasksMu.Lock()
+	tasks := vm.tasks
+	vm.tasks = nil
+	vm.tasksMu.Unlock()
+	for _, t := range tasks {
+		t.Cancel()
+	}
+	for _, t := range tasks {
+		<-t.Wait()
+	}
+}
+
+// runExitHooks calls the registered exit hooks in reverse registration order.
+// Each hook receives the error the main program failed with, or nil if it
+// succeeded or exited with status 0; hooks that declare no parameters are
+// called without arguments. Every hook runs even if an earlier one fails.
+// The main program's error takes precedence, otherwise the first hook error
+// is returned. If the context was cancelled, the hooks still run with the
+// context's values, and are stopped once ExitHookTimeout passes in total.
+func (vm *VirtualMachine) runExitHooks(ctx context.Context, mainErr error) error {
+	hooks := vm.exitHooks
+	vm.exitHooks = nil
+	if len(hooks) == 0 {
+		return mainErr
+	}
+	if ctx.Err() != nil {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(context.WithoutCancel(ctx), ExitHookTimeout)
+		defer cancel()
+		vm.runMutex.Lock()
+		vm.stopWatching()
+		atomic.CompareAndSwapInt32(&vm.halt, haltCancel, 0)
+		vm.watchContext(ctx)
+		vm.runMutex.Unlock()
+	}
+	var errArg object.Object = object.Nil
+	var exitErr *object.ExitError
+	if mainErr != nil && !(errors.As(mainErr, &exitErr) && exitErr.Code == 0) {
+		errArg = object.NewError(mainErr)
+	}
+	var hookErr error
+	for i := len(hooks) - 1; i >= 0; i-- {
+		var args []object.Object
+		if !isNullary(hooks[i]) {
+			args = []object.Object{errArg}
+		}
+		if _, err := hooks[i].Call(ctx, args...); err != nil && hookErr == nil {
+			hookErr = err
+		}
+	}
+	if mainErr != nil {
+		return mainErr
+	}
+	return hookErr
+}
+
+// isNullary reports whether fn is a closure that accepts no arguments.
+func isNullary(fn object.Callable) bool {
+	closure, ok := fn.(*object.Closure)
+	return ok && closure.ParameterCount() == 0 && !closure.HasRestParam()
 }
 
 // resetForNewCode resets the VM state for running a new code object
@@ -425,7 +793,7 @@ func (vm *VirtualMachine) resetForNewCode() {
 	vm.sp = -1
 	vm.ip = 0
 	vm.fp = 0
-	vm.halt = 0
+	atomic.StoreInt32(&vm.halt, 0)
 	vm.activeFrame = nil
 	vm.activeCode = nil
 	vm.loadedCode = map[*bytecode.Code]*loadedCode{}
@@ -484,6 +852,23 @@ func (vm *VirtualMachine) GlobalNames() []string {
 // Assuming this function returns without error, the result of the evaluation
 // will be on the top of the stack.
 func (vm *VirtualMachine) eval(ctx context.Context) error {
+	baseFP := vm.fp
+	vm.evalDepth++
+	defer func() { vm.evalDepth-- }()
+	err := vm.run(ctx)
+	if err != nil && !isPause(err) {
+		// Leave the frames of functions called inline from this eval, as
+		// returning through recursive evals would have
+		for vm.fp > baseFP {
+			vm.leaveFrame()
+		}
+	}
+	return err
+}
+
+// run executes instructions until the frame eval started in returns, the
+// active code ends, or an error escapes.
+func (vm *VirtualMachine) run(ctx context.Context) error {
 	// Use VM fields for step counting so counts persist across recursive calls
 	checkInterval := vm.contextCheckInterval
 	doneChan := ctx.Done()
@@ -498,8 +883,27 @@ func (vm *VirtualMachine) eval(ctx context.Context) error {
 evalLoop:
 	for vm.ip < len(vm.activeCode.Instructions) {
 
-		if atomic.LoadInt32(&vm.halt) == 1 {
-			return ctx.Err()
+		if halt := atomic.LoadInt32(&vm.halt); halt != 0 {
+			if halt == haltCancel {
+				return ctx.Err()
+			}
+			if vm.pausable && vm.evalDepth == 1 {
+				return ErrPaused
+			}
+		}
+
+		// Stop once the step budget is used. Yielding to RunSteps waits
+		// until the program can pause.
+		if vm.budgeted {
+			if vm.stepBudget <= 0 {
+				if vm.budgetErr != errStepBudget {
+					return vm.budgetErr
+				}
+				if vm.pausable && vm.evalDepth == 1 {
+					return errStepBudget
+				}
+			}
+			vm.stepBudget--
 		}
 
 		// Periodic checks (context, steps, stack) every N instructions.
@@ -511,11 +915,12 @@ evalLoop:
 			if vm.stepCheckCounter >= checkInterval {
 				vm.stepCheckCounter = 0
 
-				// Context cancellation check
+				// Context cancellation check. The halt flag is left to the
+				// watcher of the run's context: a callback may run with a
+				// shorter deadline than its caller, which keeps running.
 				if doneChan != nil {
 					select {
 					case <-doneChan:
-						atomic.StoreInt32(&vm.halt, 1)
 						return ctx.Err()
 					default:
 					}
@@ -523,8 +928,7 @@ evalLoop:
 
 				// Step limit check
 				if vm.maxSteps > 0 {
-					vm.stepCount += int64(checkInterval)
-					if vm.stepCount > vm.maxSteps {
+					if vm.stepCount.Add(int64(checkInterval)) > vm.maxSteps {
 						return ErrStepLimitExceeded
 					}
 				}
@@ -611,9 +1015,24 @@ evalLoop:
 		case op.StoreFast:
 			idx := vm.fetch()
 			obj := vm.pop()
-			vm.activeFrame.Locals()[idx] = obj
+			locals := vm.activeFrame.Locals()
+			old := locals[idx]
+			locals[idx] = obj
+			if vm.watched != nil {
+				if err := vm.notifyWatch(vm.activeCode.LocalNameAt(int(idx)), WatchLocal, old, obj); err != nil {
+					return err
+				}
+			}
 		case op.StoreGlobal:
-			vm.activeCode.Globals[vm.fetch()] = vm.pop()
+			idx := vm.fetch()
+			obj := vm.pop()
+			old := vm.activeCode.Globals[idx]
+			vm.activeCode.Globals[idx] = obj
+			if vm.watched != nil {
+				if err := vm.notifyWatch(vm.main.GlobalNameAt(int(idx)), WatchGlobal, old, obj); err != nil {
+					return err
+				}
+			}
 		case op.StoreFree:
 			idx := vm.fetch()
 			obj := vm.pop()
@@ -708,7 +1127,7 @@ evalLoop:
 				args[argIndex] = vm.pop()
 			}
 			obj := vm.pop()
-			if err := vm.callObject(ctx, obj, args); err != nil {
+			if err := vm.callInline(ctx, obj, args); err != nil {
 				if herr := vm.tryHandleError(err); herr != nil {
 					return herr
 				}
@@ -735,7 +1154,7 @@ evalLoop:
 			}
 			args := list.Value()
 			obj := vm.pop()
-			if err := vm.callObject(ctx, obj, args); err != nil {
+			if err := vm.callInline(ctx, obj, args); err != nil {
 				if herr := vm.tryHandleError(err); herr != nil {
 					return herr
 				}
@@ -783,6 +1202,11 @@ evalLoop:
 				}
 			}
 
+			// Drop handlers of try blocks the function is returning from
+			for vm.excStackSize > 0 && vm.excStack[vm.excStackSize-1].fp >= vm.fp {
+				vm.excStackSize--
+			}
+
 			returnAddr := activeFrame.returnAddr
 			returnSp := activeFrame.returnSp
 			returnFp := vm.fp - 1
@@ -831,15 +1255,55 @@ evalLoop:
 				items[count-1-i] = vm.pop()
 			}
 			vm.push(object.NewList(items))
+		case op.BuildTuple:
+			count := vm.fetch()
+			items := make([]object.Object, count)
+			for i := uint16(0); i < count; i++ {
+				items[count-1-i] = vm.pop()
+			}
+			vm.push(object.NewTuple(items))
 		case op.BuildMap:
 			count := vm.fetch()
-			items := make(map[string]object.Object, count)
+			m := object.NewMap(make(map[string]object.Object, count))
+			var keyErr *object.Error
 			for i := uint16(0); i < count; i++ {
 				v := vm.pop()
 				k := vm.pop()
-				items[k.(*object.String).Value()] = v
+				if err := m.SetItem(k, v); err != nil && keyErr == nil {
+					keyErr = err
+				}
+			}
+			if keyErr != nil {
+				if herr := vm.handleException(keyErr); herr != nil {
+					return herr
+				}
+				continue
 			}
-			vm.push(object.NewMap(items))
+			vm.push(m)
+		case op.BuildEnum:
+			count := vm.fetch()
+			names := make([]string, count+1)
+			var err error
+			for i := int(count); i >= 0; i-- {
+				value := vm.pop()
+				s, ok := value.(*object.String)
+				if !ok {
+					err = vm.typeError("enum names must be strings (got %s)", value.Type())
+					continue
+				}
+				names[i] = s.Value()
+			}
+			var enum *object.Enum
+			if err == nil {
+				enum, err = object.NewEnum(names[0], names[1:])
+			}
+			if err != nil {
+				if herr := vm.tryHandleError(err); herr != nil {
+					return herr
+				}
+				continue
+			}
+			vm.push(enum)
 		case op.ListAppend:
 			// Append TOS to list at TOS-1
 			item := vm.pop()
@@ -883,10 +1347,15 @@ evalLoop:
 				continue
 			}
 			newItems := list.Value()
-			enumerable.Enumerate(ctx, func(key, value object.Object) bool {
+			if err := object.Iterate(ctx, enumerable, func(key, value object.Object) bool {
 				newItems = append(newItems, value)
 				return true
-			})
+			}); err != nil {
+				if herr := vm.tryHandleError(err); herr != nil {
+					return herr
+				}
+				continue
+			}
 			vm.push(object.NewList(newItems))
 		case op.MapMerge:
 			// Merge map at TOS into map at TOS-1
@@ -907,14 +1376,9 @@ evalLoop:
 				continue
 			}
 			// Merge source into target (creating a new map)
-			newItems := make(map[string]object.Object)
-			for k, v := range target.Value() {
-				newItems[k] = v
-			}
-			for k, v := range source.Value() {
-				newItems[k] = v
-			}
-			vm.push(object.NewMap(newItems))
+			merged := target.Copy()
+			merged.Update(source)
+			vm.push(merged)
 		case op.MapSet:
 			// Set key (TOS-1) to value (TOS) in map at TOS-2
 			value := vm.pop()
@@ -927,20 +1391,15 @@ evalLoop:
 				}
 				continue
 			}
-			key, ok := keyObj.(*object.String)
-			if !ok {
-				if herr := vm.tryHandleError(vm.typeError("map key must be string (got %s)", keyObj.Type())); herr != nil {
+			// Create a new map with the key-value pair
+			updated := target.Copy()
+			if err := updated.SetItem(keyObj, value); err != nil {
+				if herr := vm.handleException(err); herr != nil {
 					return herr
 				}
 				continue
 			}
-			// Create a new map with the key-value pair
-			newItems := make(map[string]object.Object)
-			for k, v := range target.Value() {
-				newItems[k] = v
-			}
-			newItems[key.Value()] = value
-			vm.push(object.NewMap(newItems))
+			vm.push(updated)
 		case op.BinarySubscr:
 			idx := vm.pop()
 			lhs := vm.pop()
@@ -1163,6 +1622,7 @@ evalLoop:
 			}
 
 			// Handle the exception
+			vm.recordRaise(errObj.Value())
 			if err := vm.handleException(errObj); err != nil {
 				return err
 			}
@@ -1307,18 +1767,82 @@ func (vm *VirtualMachine) Call(
 	fn *object.Closure,
 	args []object.Object,
 ) (result object.Object, err error) {
+	return vm.CallWithLimits(ctx, fn, args, Limits{})
+}
+
+// Limits constrains the resources of a single call made with
+// CallWithLimits. A zero field leaves the VM's own limit in place, and no
+// field can loosen a limit the VM was configured with.
+type Limits struct {
+	// MaxSteps is the number of instructions the call may execute. If it is
+	// exceeded, the call returns ErrStepLimitExceeded.
+	MaxSteps int64
+
+	// Timeout bounds the duration of the call. If it is exceeded, the call
+	// returns context.DeadlineExceeded.
+	Timeout time.Duration
+
+	// MaxStackDepth limits the value stack and call frame depth of the
+	// call, counted from where the call starts. If it is exceeded, the call
+	// returns ErrStackOverflow.
+	MaxStackDepth int
+}
+
+// CallWithLimits is like Call, but constrains the call with the given
+// limits. This lets a host run callbacks supplied by users, such as hooks,
+// under tighter limits than the rest of the program. Unlike the VM-wide step
+// limit, which is checked in batches, the step limit of a call is exact.
+func (vm *VirtualMachine) CallWithLimits(
+	ctx context.Context,
+	fn *object.Closure,
+	args []object.Object,
+	limits Limits,
+) (result object.Object, err error) {
+	if limits.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, limits.Timeout)
+		defer cancel()
+	}
 	if err := vm.start(ctx); err != nil {
 		return nil, err
 	}
+	restore := vm.applyLimits(limits)
 	defer func() {
 		if r := recover(); r != nil {
 			err = vm.panicToError(r)
 		}
+		restore()
+		vm.stopTasks()
 		vm.stop()
 	}()
 	return vm.callFunction(vm.initContext(ctx), fn, args)
 }
 
+// applyLimits tightens the VM's limits for a call starting at the current
+// frame and returns a function that restores them.
+func (vm *VirtualMachine) applyLimits(limits Limits) func() {
+	maxValueStackDepth := vm.maxValueStackDepth
+	maxFrameDepth := vm.maxFrameDepth
+	restore := func() {
+		vm.maxValueStackDepth = maxValueStackDepth
+		vm.maxFrameDepth = maxFrameDepth
+	}
+	if limits.MaxSteps > 0 {
+		vm.budgeted = true
+		vm.stepBudget = limits.MaxSteps
+		vm.budgetErr = ErrStepLimitExceeded
+	}
+	if limits.MaxStackDepth > 0 {
+		if limit := vm.sp + 1 + limits.MaxStackDepth; maxValueStackDepth == 0 || limit < maxValueStackDepth {
+			vm.maxValueStackDepth = limit
+		}
+		if limit := vm.fp + 1 + limits.MaxStackDepth; maxFrameDepth == 0 || limit < maxFrameDepth {
+			vm.maxFrameDepth = limit
+		}
+	}
+	return restore
+}
+
 // callFunction executes a compiled function with the given arguments. This is
 // used internally when a Risor object invokes a callback, e.g.:
 //
@@ -1328,23 +1852,15 @@ func (vm *VirtualMachine) Call(
 // resource limits apply to callback execution. The VM's stepCount field
 // persists across these recursive calls, ensuring that callbacks cannot
 // bypass step limits by executing in a "fresh" eval context.
+//
+// Calls from one Risor function to another do not come through here. The
+// Call instruction activates the callee's frame with enterFunction and the
+// running eval continues in it, so script calls do not grow the Go stack.
 func (vm *VirtualMachine) callFunction(
 	ctx context.Context,
 	fn *object.Closure,
 	args []object.Object,
 ) (result object.Object, resultErr error) {
-	// Check that the argument count is appropriate
-	paramsCount := fn.ParameterCount()
-	argc := len(args)
-
-	if argc > MaxArgs {
-		return nil, vm.evalError("max args limit of %d exceeded (got %d)",
-			MaxArgs, argc)
-	}
-	if err := checkCallArgs(fn, argc); err != nil {
-		return nil, err
-	}
-
 	baseFP := vm.fp
 	baseIP := vm.ip
 	baseSP := vm.sp
@@ -1363,6 +1879,36 @@ func (vm *VirtualMachine) callFunction(
 		vm.resumeFrame(baseFP, baseIP, baseSP)
 	}()
 
+	if err := vm.enterFunction(fn, args); err != nil {
+		return nil, err
+	}
+
+	// Setting StopSignal as the return address will cause the eval function to
+	// stop execution when it reaches the end of the active code.
+	vm.activeFrame.returnAddr = StopSignal
+
+	// Evaluate the function code then return the result from TOS
+	if err := vm.eval(ctx); err != nil {
+		return nil, err
+	}
+	return vm.pop(), nil
+}
+
+// enterFunction checks the arguments of a call to fn and activates a new
+// frame for it. The frame returns to the current instruction pointer.
+func (vm *VirtualMachine) enterFunction(fn *object.Closure, args []object.Object) error {
+	// Check that the argument count is appropriate
+	paramsCount := fn.ParameterCount()
+	argc := len(args)
+
+	if argc > MaxArgs {
+		return vm.evalError("max args limit of %d exceeded (got %d)",
+			MaxArgs, argc)
+	}
+	if err := checkCallArgs(fn, argc); err != nil {
+		return err
+	}
+
 	// Assemble frame local variables in vm.tmp. The local variable order is:
 	// 1. Function parameters
 	// 2. Rest parameter (if any)
@@ -1415,7 +1961,7 @@ func (vm *VirtualMachine) callFunction(
 
 	// Activate a frame for the function call
 	if _, err := vm.activateFunction(vm.fp+1, 0, fn, vm.tmp[:argc]); err != nil {
-		return nil, err
+		return err
 	}
 
 	// Call observer if present and configured to observe calls
@@ -1427,19 +1973,20 @@ func (vm *VirtualMachine) callFunction(
 			FrameDepth:   vm.fp + 1,
 		}
 		if !vm.observer.OnCall(event) {
-			return nil, fmt.Errorf("execution halted by observer")
+			return fmt.Errorf("execution halted by observer")
 		}
 	}
+	return nil
+}
 
-	// Setting StopSignal as the return address will cause the eval function to
-	// stop execution when it reaches the end of the active code.
-	vm.activeFrame.returnAddr = StopSignal
-
-	// Evaluate the function code then return the result from TOS
-	if err := vm.eval(ctx); err != nil {
-		return nil, err
+// callInline calls fn from the running eval. A Risor function gets a new
+// frame that eval continues in, and its result is pushed when it returns.
+// Other callables are called via callObject.
+func (vm *VirtualMachine) callInline(ctx context.Context, fn object.Object, args []object.Object) error {
+	if closure, ok := fn.(*object.Closure); ok {
+		return vm.enterFunction(closure, args)
 	}
-	return vm.pop(), nil
+	return vm.callObject(ctx, fn, args)
 }
 
 // Call a callable object with the given arguments. Returns an error if the
@@ -1459,7 +2006,15 @@ func (vm *VirtualMachine) callObject(
 		vm.push(result)
 		return nil
 	case object.Callable:
-		result, err := fn.Call(ctx, args...)
+		var result object.Object
+		var err error
+		if vm.interceptor != nil {
+			result, err = vm.interceptCall(ctx, fn, args)
+		} else if vm.recoverPanics {
+			result, err = vm.callRecovered(ctx, fn, args)
+		} else {
+			result, err = fn.Call(ctx, args...)
+		}
 		if err != nil {
 			return err
 		}
@@ -1483,6 +2038,42 @@ func (vm *VirtualMachine) callObject(
 	}
 }
 
+// callRecovered calls a Go callable, converting a panic into a ScriptError
+// with the code "panic". The error's data holds the panic value and the Go
+// stack of the panic. Panics caused by resource limits keep unwinding.
+func (vm *VirtualMachine) callRecovered(ctx context.Context, fn object.Callable, args []object.Object) (result object.Object, err error) {
+	defer func() {
+		r := recover()
+		if r == nil {
+			return
+		}
+		if rErr, ok := r.(error); ok && (errors.Is(rErr, ErrStackOverflow) || errors.Is(rErr, ErrStepLimitExceeded)) {
+			panic(r)
+		}
+		name := "function"
+		if named, ok := fn.(interface{ Name() string }); ok && named.Name() != "" {
+			name = named.Name()
+		}
+		vm.panicStack = nil
+		result, err = nil, object.NewPanicError(name, r, debug.Stack())
+	}()
+	return fn.Call(ctx, args...)
+}
+
+// leaveFrame abandons the active frame, which was entered by a Call
+// instruction of its caller, and resumes the caller without a result.
+func (vm *VirtualMachine) leaveFrame() {
+	f := vm.activeFrame
+	for i := vm.sp; i > f.returnSp; i-- {
+		vm.stack[i] = nil
+	}
+	vm.sp = f.returnSp
+	vm.fp--
+	vm.ip = f.returnAddr
+	vm.activeFrame = &vm.frames[vm.fp]
+	vm.activeCode = vm.activeFrame.code
+}
+
 // Resume the frame at the given frame pointer, restoring the given IP and SP.
 func (vm *VirtualMachine) resumeFrame(fp, ip, sp int) *frame {
 	// The return value of the previous frame is on the top of the stack
@@ -1596,6 +2187,9 @@ func (vm *VirtualMachine) reloadCode(main *bytecode.Code) *loadedCode {
 }
 
 func (vm *VirtualMachine) initContext(ctx context.Context) context.Context {
+	ctx = object.WithExitHookFunc(ctx, vm.registerExitHook)
+	ctx = object.WithForkFunc(ctx, vm.fork)
+	ctx = object.WithTaskFunc(ctx, vm.registerTask)
 	return object.WithCallFunc(ctx, vm.callFunction)
 }
 
@@ -1603,51 +2197,17 @@ func (vm *VirtualMachine) initContext(ctx context.Context) context.Context {
 func (vm *VirtualMachine) captureStack() []object.StackFrame {
 	var frames []object.StackFrame
 
-	// Walk through all active frames
+	// Walk through all active frames. The active frame is at the current
+	// instruction, whose ip was already incremented, and callers are at
+	// their call instruction.
 	for i := vm.fp; i >= 0; i-- {
 		frame := &vm.frames[i]
 		if frame.code == nil {
 			continue
 		}
-
-		// Get the function name
-		funcName := ""
-		if frame.fn != nil {
-			funcName = frame.fn.Name()
-			if funcName == "" {
-				funcName = "<anonymous>"
-			}
-		} else if frame.code.CodeName() != "" {
-			funcName = frame.code.CodeName()
-		} else {
-			funcName = "__main__"
-		}
-
-		// Get the location:
-		// - Active frame: use current ip (where the error occurred)
-		// - Caller frames: use callSiteIP from the callee frame (where the call was made)
-		ip := 0
-		if i == vm.fp {
-			ip = vm.ip - 1 // Current instruction (ip was already incremented)
-			if ip < 0 {
-				ip = 0
-			}
-		} else if i < vm.fp {
-			// For caller frames, the call site is stored in the callee's callSiteIP.
-			// callSiteIP is captured as vm.ip after the Call instruction was read,
-			// so subtract 1 to get the actual Call instruction's source location.
-			calleeFrame := &vm.frames[i+1]
-			ip = calleeFrame.callSiteIP - 1
-			if ip < 0 {
-				ip = 0
-			}
-		}
-
-		loc := frame.code.LocationAt(ip)
-
 		frames = append(frames, object.StackFrame{
-			Function: funcName,
-			Location: loc,
+			Function: vm.frameFunctionName(i),
+			Location: frame.code.LocationAt(vm.frameIP(i, vm.ip-1)),
 		})
 	}
 	return frames
@@ -1683,22 +2243,58 @@ func (vm *VirtualMachine) evalError(format string, args ...any) *object.Structur
 // wrapError wraps an existing error with location and stack trace.
 // It determines the error kind from the error type.
 func (vm *VirtualMachine) wrapError(err error) *object.StructuredError {
-	kind := object.ErrRuntime
-	msg := err.Error()
+	return object.NewStructuredError(errorKind(err), err.Error(), vm.getCurrentLocation(), vm.captureStack())
+}
 
+// errorKind returns the kind of error that best describes err.
+func errorKind(err error) object.ErrorKind {
 	switch err.(type) {
 	case *object.TypeError:
-		kind = object.ErrType
+		return object.ErrType
 	case *object.ValueError:
-		kind = object.ErrValue
+		return object.ErrValue
 	case *object.IndexError:
-		kind = object.ErrValue // Index errors are a kind of value error
+		return object.ErrValue // Index errors are a kind of value error
 	}
-	return object.NewStructuredError(kind, msg, vm.getCurrentLocation(), vm.captureStack())
+	return object.ErrRuntime
+}
+
+// recordRaise notes where an error was raised, unless it already carries a
+// stack trace or wraps the error raised last. A re-raised or wrapped error
+// therefore keeps the trace of the place it was first raised.
+func (vm *VirtualMachine) recordRaise(err error) {
+	if err == nil || hasStack(err) {
+		return
+	}
+	if vm.raised != nil && errors.Is(err, vm.raised) {
+		vm.raised = err
+		return
+	}
+	vm.raised = err
+	vm.raisedLoc = vm.getCurrentLocation()
+	vm.raisedStack = vm.captureStack()
+}
+
+// traceError converts an error escaping the program into a StructuredError
+// carrying the location and stack where it was raised. The original error
+// remains its cause. Errors that already carry a stack trace, and errors
+// not raised by the program, such as cancellation, are returned unchanged.
+func (vm *VirtualMachine) traceError(err error) error {
+	if err == nil || vm.raised == nil || hasStack(err) || !errors.Is(err, vm.raised) {
+		return err
+	}
+	return object.NewStructuredError(errorKind(err), err.Error(), vm.raisedLoc, vm.raisedStack).WithCause(err)
+}
+
+// hasStack reports whether err carries a script stack trace.
+func hasStack(err error) bool {
+	var se *object.StructuredError
+	return errors.As(err, &se) && len(se.Stack) > 0
 }
 
 // panicToError converts a recovered panic value to a structured error.
 // It attempts to categorize common Go runtime panics into user-friendly errors.
+// The cause of the error is a *object.ScriptError with the code "panic".
 func (vm *VirtualMachine) panicToError(r any) error {
 	// Check if it's one of our sentinel errors - return directly to preserve error chain
 	if err, ok := r.(error); ok {
@@ -1750,7 +2346,10 @@ func (vm *VirtualMachine) panicToError(r any) error {
 	// Clear the panic stack for next use
 	vm.panicStack = nil
 
-	return object.NewStructuredError(kind, friendlyMsg, loc, stack)
+	// Keep the panic as the cause, so hosts and fuzzers can tell a Go panic
+	// apart from an error raised by the script
+	return object.NewStructuredError(kind, friendlyMsg, loc, stack).
+		WithCause(object.NewPanicError("vm", r, debug.Stack()))
 }
 
 // handleException handles a thrown exception by finding an appropriate handler.
@@ -1763,14 +2362,20 @@ func (vm *VirtualMachine) handleException(errObj *object.Error) error {
 		// Check if this handler is for the current frame
 		if excFrame.fp != vm.fp || excFrame.code != vm.activeCode {
 			// Handler is for a different frame
-			if excFrame.fp > vm.fp {
-				// Handler is for a deeper frame we've returned from - pop it as stale
+			if excFrame.fp >= vm.fp {
+				// Handler is for a frame we've returned from - pop it as stale
 				vm.excStackSize--
 				continue
 			}
-			// Handler is for a caller frame - let error propagate up
-			// The caller's tryHandleError will find this handler after frame is restored
-			return errObj.Value()
+			// Handler is for a caller frame. If the caller called this
+			// function inline, leave the frame and look again from there.
+			// Otherwise let the error propagate up; the caller's
+			// tryHandleError will find this handler after frame is restored.
+			if vm.activeFrame.returnAddr == StopSignal {
+				return errObj.Value()
+			}
+			vm.leaveFrame()
+			continue
 		}
 
 		handler := excFrame.handler
@@ -1828,6 +2433,12 @@ func (vm *VirtualMachine) handleException(errObj *object.Error) error {
 // If a handler is found and jumped to, returns nil (exception was handled).
 // If no handler is found, returns the error to propagate up.
 func (vm *VirtualMachine) tryHandleError(err error) error {
+	// An exit stops the program; it is not an exception to catch
+	var exitErr *object.ExitError
+	if errors.As(err, &exitErr) {
+		return err
+	}
+	vm.recordRaise(err)
 	// Convert error to object.Error
 	errObj := object.NewError(err)
 	return vm.handleException(errObj)
@@ -2,12 +2,16 @@ package vm
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"reflect"
 	"testing"
 	"time"
 
 	"github.com/deepnoodle-ai/risor/v2/pkg/bytecode"
 	"github.com/deepnoodle-ai/risor/v2/pkg/compiler"
 	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+	"github.com/deepnoodle-ai/risor/v2/pkg/op"
 	"github.com/deepnoodle-ai/risor/v2/pkg/parser"
 	"github.com/deepnoodle-ai/wonton/assert"
 )
@@ -665,6 +669,72 @@ func TestConstantFunction(t *testing.T) {
 	assert.Equal(t, err.Error(), "compile error: cannot assign to constant \"add\"\n\nlocation: unknown:3:2")
 }
 
+func TestEnum(t *testing.T) {
+	tests := []testCase{
+		{`enum Color { Red, Green, Blue }; Color.Green.ordinal`, object.NewInt(1)},
+		{`enum Color { Red, Green, Blue }; Color.Blue.name`, object.NewString("Blue")},
+		{`enum Color { Red, Green, Blue }; Color.name`, object.NewString("Color")},
+		{`enum Color { Red, Green, Blue }; string(Color.Red)`, object.NewString("Red")},
+		{`enum Color { Red, Green, Blue }; int(Color.Blue)`, object.NewInt(2)},
+		{`enum Color { Red, Green, Blue }; Color.from_ordinal(1) == Color.Green`, object.True},
+		{`enum Color { Red, Green, Blue }; Color.from_name("Blue") == Color.Blue`, object.True},
+		{`enum Color { Red, Green, Blue }; Color.has("Purple")`, object.False},
+		{`enum Color { Red, Green, Blue }; Color.names()`, object.NewList([]object.Object{
+			object.NewString("Red"), object.NewString("Green"), object.NewString("Blue"),
+		})},
+		{`enum Color { Red, Green, Blue }; Color.values().map(c => c.ordinal)`, object.NewList([]object.Object{
+			object.NewInt(0), object.NewInt(1), object.NewInt(2),
+		})},
+		{`enum Color { Red, Green }; Color.Red == Color.Red`, object.True},
+		{`enum Color { Red, Green }; Color.Red != Color.Green`, object.True},
+		{`enum Color { Red, Green }; Color.Red < Color.Green`, object.True},
+		{`enum A { X }; enum B { X }; A.X == B.X`, object.False},
+		{`enum Color { Red, Green }; match Color.Green { Color.Red => "r", Color.Green => "g", _ => "?" }`,
+			object.NewString("g")},
+		{`function f() { enum Local { A, B }; return Local.B.ordinal }; f()`, object.NewInt(1)},
+	}
+	runTests(t, tests)
+}
+
+func TestEnumErrors(t *testing.T) {
+	tests := []struct {
+		input       string
+		expectedErr string
+	}{
+		{`enum Color { Red }; Color = 1`, `cannot assign to constant "Color"`},
+		{`enum Color { Red }; Color.Red = 1`, `cannot modify enum Color`},
+		{`enum Color { Red }; Color.Red.ordinal = 1`, `cannot modify enum member Color.Red`},
+		{`enum Color { Red, Red }`, `enum Color has duplicate member "Red"`},
+		{`enum Color { Red, values }`, `enum Color member "values" conflicts with a built-in enum attribute`},
+		{`enum Color { Red }; Color.from_ordinal(1)`, `Color has no member with ordinal 1`},
+		{`enum Color { Red }; Color.from_name("Blue")`, `Color has no member named "Blue"`},
+		{`enum Color { Red }; Color.Blue`, `attribute "Blue" not found`},
+		{`enum A { X, Y }; enum B { X }; A.X < B.X`, `unable to compare A.X and B.X`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := run(context.Background(), tt.input)
+			assert.NotNil(t, err)
+			assert.Contains(t, err.Error(), tt.expectedErr)
+		})
+	}
+}
+
+func TestEnumNonStringNames(t *testing.T) {
+	// Hand-built bytecode that the verifier would reject
+	code := bytecode.NewCode(bytecode.CodeParams{
+		ID:           "main",
+		Name:         "main",
+		Instructions: []op.Code{op.LoadConst, 0, op.LoadConst, 1, op.BuildEnum, 1, op.ReturnValue},
+		Constants:    []any{"Color", int64(1)},
+	})
+	machine, err := New(code)
+	assert.Nil(t, err)
+	err = machine.Run(context.Background())
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "enum names must be strings (got int)")
+}
+
 func TestStatementsNilValue(t *testing.T) {
 	// The result value of a statement is always nil
 	tests := []testCase{
@@ -674,6 +744,7 @@ func TestStatementsNilValue(t *testing.T) {
 		{`let x = 0; x += 1`, object.Nil},
 		{`let x = 0; x -= 1`, object.Nil},
 		{`const x = 0`, object.Nil},
+		{`enum E { A }`, object.Nil},
 		{`let x = 0`, object.Nil},
 		{`let x, y = [0, 0]`, object.Nil},
 		{`let x = [1]; x[0] = 2`, object.Nil},
@@ -681,6 +752,75 @@ func TestStatementsNilValue(t *testing.T) {
 	runTests(t, tests)
 }
 
+func TestResultModeLastValue(t *testing.T) {
+	// Declarations and assignments evaluate to the value they store
+	tests := []testCase{
+		{`let x = 5`, object.NewInt(5)},
+		{`const x = "a"`, object.NewString("a")},
+		{`let x = 1; x += 2`, object.NewInt(3)},
+		{`let x = 1; x = 7`, object.NewInt(7)},
+		{`let x = 5; x++`, object.NewInt(6)},
+		{`let x = 5; x--`, object.NewInt(4)},
+		{`let x, y = [1, 2]`, object.NewList([]object.Object{object.NewInt(1), object.NewInt(2)})},
+		{`let [a, b] = [1, 2]`, object.NewList([]object.Object{object.NewInt(1), object.NewInt(2)})},
+		{`let {a} = {a: 1}`, object.NewMap(map[string]object.Object{"a": object.NewInt(1)})},
+		{`let x = [1]; x[0] = 2`, object.NewInt(2)},
+		{`let x = [1]; x[0] += 2`, object.NewInt(3)},
+		{`let m = {a: 1}; m.a = 9`, object.NewInt(9)},
+		{`let m = {a: 1}; m.a *= 4`, object.NewInt(4)},
+		{`let m = {a: 1}; m.a++`, object.NewInt(2)},
+		{`let _ = 3`, object.NewInt(3)},
+		{`let y = 0; if (true) { y = 4 }`, object.NewInt(4)},
+		{`let y = 0; if (false) { y = 4 }`, object.Nil},
+		{`let y = 0; if (false) { 1 } else { y = 8 }`, object.NewInt(8)},
+		{`function f() { let z = 10 }; f()`, object.NewInt(10)},
+		{`function f() { let z = 10; return 1 }; f()`, object.NewInt(1)},
+		{`let f = function() { let z = 1; z += 1 }; f()`, object.NewInt(2)},
+		// Nested statements do not leave values behind
+		{`let x = if (true) { let y = 2 } else { 3 }; x`, object.NewInt(2)},
+		{`let a = 1; let b = a + 1; let c = b * 10`, object.NewInt(20)},
+		// Expressions are unaffected
+		{`let x = 5; x`, object.NewInt(5)},
+		{`if (true) { 99 }`, object.NewInt(99)},
+	}
+	ctx := context.Background()
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := run(ctx, tt.input, runOpts{ResultMode: compiler.ResultLastValue})
+			assert.Nil(t, err)
+			assert.Equal(t, result, tt.expected)
+		})
+	}
+	// The value is an enum
+	result, err := run(ctx, `enum E { A }`, runOpts{ResultMode: compiler.ResultLastValue})
+	assert.Nil(t, err)
+	assert.Equal(t, result.Type(), object.ENUM)
+}
+
+func TestResultModeNil(t *testing.T) {
+	// Control flow at the end of a program evaluates to nil
+	tests := []testCase{
+		{`let x = 77; if (x > 0) { 99 }`, object.Nil},
+		{`if (true) { 1 } else { 2 }`, object.Nil},
+		{`match 1 { 1 => "one", _ => "other" }`, object.Nil},
+		{`try { 1 } catch e { 2 }`, object.Nil},
+		{`let x = 0`, object.Nil},
+		// Plain expressions and control flow within expressions keep values
+		{`let x = if (true) { 1 } else { 2 }; x`, object.NewInt(1)},
+		{`let x = 5; x * 2`, object.NewInt(10)},
+		{`function f() { if (true) { 3 } }; f()`, object.NewInt(3)},
+		{`[if (true) { 1 } else { 2 }]`, object.NewList([]object.Object{object.NewInt(1)})},
+	}
+	ctx := context.Background()
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := run(ctx, tt.input, runOpts{ResultMode: compiler.ResultNil})
+			assert.Nil(t, err)
+			assert.Equal(t, result, tt.expected)
+		})
+	}
+}
+
 func TestPostfixOperators(t *testing.T) {
 	tests := []testCase{
 		// Simple variable postfix
@@ -976,7 +1116,9 @@ func TestStringTemplateWithThrow(t *testing.T) {
 	` + "`the err string is: ${raise(\"oops\")}. sad!`"
 	_, err := run(context.Background(), code)
 	assert.NotNil(t, err)
-	assert.Equal(t, err.Error(), "oops")
+	structuredErr, ok := err.(*object.StructuredError)
+	assert.True(t, ok)
+	assert.Equal(t, structuredErr.Message, "oops")
 }
 
 func TestStringTemplateWithErrorValue(t *testing.T) {
@@ -1512,6 +1654,53 @@ func TestCallWithClosure(t *testing.T) {
 	}
 }
 
+func TestCallWithLimits(t *testing.T) {
+	ctx := context.Background()
+	source := `
+	function spin(n) {
+		if (n == 0) { return "done" }
+		return spin(n - 1)
+	}
+	function forever() { return forever() }
+	function wait(ch) { return ch.recv() }
+	`
+	vm, err := newVM(ctx, source)
+	assert.Nil(t, err)
+	assert.Nil(t, vm.Run(ctx))
+
+	get := func(name string) *object.Closure {
+		obj, err := vm.Get(name)
+		assert.Nil(t, err)
+		return obj.(*object.Closure)
+	}
+	spin := get("spin")
+
+	// Within the limits
+	result, err := vm.CallWithLimits(ctx, spin, []object.Object{object.NewInt(5)},
+		Limits{MaxSteps: 1000, MaxStackDepth: 10})
+	assert.Nil(t, err)
+	assert.Equal(t, result, object.NewString("done"))
+
+	// The step limit is exact, unlike the batched VM-wide limit
+	_, err = vm.CallWithLimits(ctx, spin, []object.Object{object.NewInt(5)}, Limits{MaxSteps: 20})
+	assert.ErrorIs(t, err, ErrStepLimitExceeded)
+
+	_, err = vm.CallWithLimits(ctx, spin, []object.Object{object.NewInt(50)}, Limits{MaxStackDepth: 10})
+	assert.ErrorIs(t, err, ErrStackOverflow)
+
+	ch := make(chan int)
+	_, err = vm.CallWithLimits(ctx, get("wait"), []object.Object{object.NewGoChan(reflect.ValueOf(ch), object.DefaultRegistry())},
+		Limits{Timeout: 10 * time.Millisecond})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// The limits apply to one call only
+	result, err = vm.Call(ctx, spin, []object.Object{object.NewInt(50)})
+	assert.Nil(t, err)
+	assert.Equal(t, result, object.NewString("done"))
+	_, err = vm.CallWithLimits(ctx, get("forever"), nil, Limits{MaxSteps: 100000})
+	assert.Error(t, err)
+}
+
 func TestFreeVariableAssignment(t *testing.T) {
 	ctx := context.Background()
 	source := `
@@ -1703,6 +1892,60 @@ func TestLists(t *testing.T) {
 	runTests(t, tests)
 }
 
+func TestTuples(t *testing.T) {
+	tests := []testCase{
+		{`(1, "a")`, object.NewTuple([]object.Object{
+			object.NewInt(1),
+			object.NewString("a"),
+		})},
+		{`(1,)`, object.NewTuple([]object.Object{object.NewInt(1)})},
+		{`(1,
+		   2,
+		)`, object.NewTuple([]object.Object{
+			object.NewInt(1),
+			object.NewInt(2),
+		})},
+		{`(1, 2) + (3,)`, object.NewTuple([]object.Object{
+			object.NewInt(1),
+			object.NewInt(2),
+			object.NewInt(3),
+		})},
+		{`let [a, b] = (1, 2); a + b`, object.NewInt(3)},
+		{`let t = (1, 2, 3); t[-1] + len(t)`, object.NewInt(6)},
+		{`(1, 2) == (1, 2)`, object.True},
+		{`(1, 2) == [1, 2]`, object.False},
+		{`(1, 2) < (1, 2, 0)`, object.True},
+		{`2 in (1, 2)`, object.True},
+		{`(1)`, object.NewInt(1)},
+	}
+	runTests(t, tests)
+}
+
+func TestTupleImmutable(t *testing.T) {
+	_, err := run(context.Background(), `let t = (1, 2); t[0] = 3`)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "tuple does not support item assignment")
+}
+
+func TestMapKeyTypes(t *testing.T) {
+	tests := []testCase{
+		{`let m = {1: "a", true: "b", (1, 2): "c"}; m[1] + m[true] + m[(1, 2)]`, object.NewString("abc")},
+		{`let m = {}; m[42] = "x"; m[42]`, object.NewString("x")},
+		{`let key = 3; {key: 1}["key"]`, object.NewInt(1)},
+		{`{1: "a", "1": "b"}[1]`, object.NewString("a")},
+		{`let m = {1: "a"}; {...m, 2: "b"}[1]`, object.NewString("a")},
+		{`2 in {2: null}`, object.True},
+		{`len({1: 1, 2: 2})`, object.NewInt(2)},
+	}
+	runTests(t, tests)
+}
+
+func TestMapUnhashableKey(t *testing.T) {
+	_, err := run(context.Background(), `{[1]: "a"}`)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "unhashable map key type: list")
+}
+
 func TestMultivar(t *testing.T) {
 	code := `
 	let x, y = [1, 2]
@@ -1752,6 +1995,39 @@ func TestContextDone(t *testing.T) {
 	assert.NotNil(t, d)
 }
 
+func TestCancelAfterRun(t *testing.T) {
+	// Cancelling the context of a finished run must not halt the next run
+	// on the same VM.
+	ctx := context.Background()
+	vm, err := newVM(ctx, "1")
+	assert.Nil(t, err)
+	for i := 0; i < 100; i++ {
+		runCtx, cancel := context.WithCancel(ctx)
+		assert.Nil(t, vm.Run(runCtx))
+		cancel()
+	}
+	assert.Nil(t, vm.Run(ctx))
+	tos, ok := vm.TOS()
+	assert.True(t, ok)
+	assert.Equal(t, tos, object.NewInt(1))
+}
+
+func TestCallbackDeadline(t *testing.T) {
+	// A callback that runs out of a deadline shorter than the program's
+	// fails on its own; the caller catches the error and keeps running.
+	deadline := object.NewBuiltin("deadline", func(ctx context.Context, args ...object.Object) (object.Object, error) {
+		ctx, cancel := context.WithTimeout(ctx, 5*time.Millisecond)
+		defer cancel()
+		return args[0].(object.Callable).Call(ctx)
+	})
+	result, err := run(context.Background(), `
+	let caught = try { deadline(() => range(1000000000).each(i => i)) } catch e { "caught" }
+	[caught, 1 + 1]
+	`, runOpts{Globals: map[string]any{"deadline": deadline}})
+	assert.Nil(t, err)
+	assert.Equal(t, result.Inspect(), `["caught", 2]`)
+}
+
 type testCase struct {
 	input    string
 	expected object.Object
@@ -2660,3 +2936,217 @@ func TestTimeout(t *testing.T) {
 	assert.NotNil(t, err)
 	assert.Equal(t, err, context.DeadlineExceeded)
 }
+
+func TestExitHooks(t *testing.T) {
+	ctx := context.Background()
+	code := `
+	let log = []
+	atexit.register(function(err) { log.append(["first", err]) })
+	atexit.register(function() { log.append("second") })
+	let result = "done"
+	result
+	`
+	vm, err := newVM(ctx, code)
+	assert.Nil(t, err)
+	assert.Nil(t, vm.Run(ctx))
+
+	// The main program's result is preserved and hooks ran in reverse order
+	tos, ok := vm.TOS()
+	assert.True(t, ok)
+	assert.Equal(t, tos, object.NewString("done"))
+	log, err := vm.Get("log")
+	assert.Nil(t, err)
+	assert.Equal(t, log.Inspect(), `["second", ["first", null]]`)
+}
+
+func TestExitHooksReceiveError(t *testing.T) {
+	ctx := context.Background()
+	code := `
+	let seen = null
+	atexit.register(function(err) { seen = err.message() })
+	throw "boom"
+	`
+	vm, err := newVM(ctx, code)
+	assert.Nil(t, err)
+	err = vm.Run(ctx)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "boom")
+	seen, getErr := vm.Get("seen")
+	assert.Nil(t, getErr)
+	assert.Equal(t, seen, object.NewString("boom"))
+}
+
+func TestExitHookError(t *testing.T) {
+	ctx := context.Background()
+	code := `
+	let ran = false
+	atexit.register(function() { ran = true })
+	atexit.register(function() { throw "hook failed" })
+	1
+	`
+	vm, err := newVM(ctx, code)
+	assert.Nil(t, err)
+	err = vm.Run(ctx)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "hook failed")
+
+	// Later hooks still run after one fails
+	ran, getErr := vm.Get("ran")
+	assert.Nil(t, getErr)
+	assert.Equal(t, ran, object.True)
+}
+
+func TestExitHooksRunAfterCancel(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	code := `
+	let seen = null
+	atexit.register(function(err) { seen = err.message() })
+	range(1 << 62).each(x => x)
+	`
+	vm, err := newVM(context.Background(), code)
+	assert.Nil(t, err)
+	err = vm.Run(ctx)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	seen, getErr := vm.Get("seen")
+	assert.Nil(t, getErr)
+	assert.Equal(t, seen, object.NewString(context.DeadlineExceeded.Error()))
+}
+
+func TestExitHooksAfterCancelAreBounded(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	code := `
+	atexit.register(function() { range(1 << 62).each(x => x) })
+	range(1 << 62).each(x => x)
+	`
+	vm, err := newVM(context.Background(), code)
+	assert.Nil(t, err)
+	start := time.Now()
+	err = vm.Run(ctx)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.True(t, time.Since(start) < ExitHookTimeout+time.Second)
+}
+
+func exitBuiltin() *object.Builtin {
+	return object.NewBuiltin("exit", func(ctx context.Context, args ...object.Object) (object.Object, error) {
+		code, err := object.AsInt(args[0])
+		if err != nil {
+			return nil, err
+		}
+		return nil, object.NewExitError(int(code))
+	})
+}
+
+func TestExitIsNotCaught(t *testing.T) {
+	ctx := context.Background()
+	code := `
+	let caught = false
+	let cleaned = false
+	try {
+		[1, 2].each(function(x) { exit(3) })
+	} catch e {
+		caught = true
+	} finally {
+		cleaned = true
+	}
+	"unreachable"
+	`
+	vm, err := newVM(ctx, code, runOpts{Globals: map[string]any{"exit": exitBuiltin()}})
+	assert.Nil(t, err)
+	err = vm.Run(ctx)
+	var exitErr *object.ExitError
+	assert.True(t, errors.As(err, &exitErr))
+	assert.Equal(t, exitErr.Code, 3)
+
+	// Neither the catch nor the finally block ran
+	caught, getErr := vm.Get("caught")
+	assert.Nil(t, getErr)
+	assert.Equal(t, caught, object.False)
+	cleaned, getErr := vm.Get("cleaned")
+	assert.Nil(t, getErr)
+	assert.Equal(t, cleaned, object.False)
+}
+
+func TestExitRunsExitHooks(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		code int
+		seen string
+	}{
+		{0, "null"},
+		{2, `"exit status 2"`},
+	}
+	for _, tt := range tests {
+		code := fmt.Sprintf(`
+		let seen = "not run"
+		atexit.register(function(err) { seen = if (err) { err.message() } else { nil } })
+		exit(%d)
+		`, tt.code)
+		vm, err := newVM(ctx, code, runOpts{Globals: map[string]any{"exit": exitBuiltin()}})
+		assert.Nil(t, err)
+		err = vm.Run(ctx)
+		var exitErr *object.ExitError
+		assert.True(t, errors.As(err, &exitErr))
+		assert.Equal(t, exitErr.Code, tt.code)
+		seen, getErr := vm.Get("seen")
+		assert.Nil(t, getErr)
+		assert.Equal(t, seen.Inspect(), tt.seen)
+	}
+}
+
+func TestTrailingFunc(t *testing.T) {
+	tests := []testCase{
+		{`[1, 2, 3].map x => x * 10`, object.NewList([]object.Object{
+			object.NewInt(10), object.NewInt(20), object.NewInt(30),
+		})},
+		{`[1, 2, 3].reduce(0) function(acc, x) { return acc + x }`, object.NewInt(6)},
+		{`len([1, 2, 3].map x => { x * 2 }.filter(y => y > 2))`, object.NewInt(2)},
+	}
+	runTests(t, tests)
+}
+
+func TestWithFrozenGlobals(t *testing.T) {
+	ctx := context.Background()
+	items := object.NewList([]object.Object{object.NewInt(1)})
+	ast, err := parser.Parse(ctx, `items[0] = 2`, nil)
+	assert.Nil(t, err)
+	code, err := compiler.Compile(ast, &compiler.Config{GlobalNames: []string{"items"}})
+	assert.Nil(t, err)
+	machine, err := New(code, WithGlobals(map[string]any{"items": items}), WithFrozenGlobals())
+	assert.Nil(t, err)
+	err = machine.Run(ctx)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "list is frozen")
+	assert.Equal(t, items.Inspect(), "[1]")
+
+	cyclic := object.NewList(nil)
+	cyclic.Append(cyclic)
+	_, err = New(code, WithGlobals(map[string]any{"items": cyclic}), WithFrozenGlobals())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid global "items"`)
+}
+
+func TestLazyIterators(t *testing.T) {
+	ctx := context.Background()
+	globals := basicBuiltins()
+	globals["iter"] = object.NewBuiltin("iter", func(ctx context.Context, args ...object.Object) (object.Object, error) {
+		return object.IterOf(args[0].(object.Enumerable)), nil
+	})
+	result, err := run(ctx, `
+let squares = iter(range(1000000000)).map(x => x * x).filter(x => x % 2 == 1)
+[...squares.drop(1).take(3)]
+`, runOpts{Globals: globals})
+	assert.Nil(t, err)
+	assert.Equal(t, result.Inspect(), "[9, 25, 49]")
+
+	result, err = run(ctx, `
+try {
+	[...iter([1, 2]).map(x => { throw "bad item" })]
+} catch e {
+	e.message()
+}
+`, runOpts{Globals: globals})
+	assert.Nil(t, err)
+	assert.Equal(t, result, object.NewString("bad item"))
+}
@@ -5,15 +5,38 @@ import (
 	"errors"
 	"fmt"
 	"maps"
+	"reflect"
+	"runtime"
 	"slices"
+	"strings"
 	"time"
 
 	"github.com/deepnoodle-ai/risor/v2/pkg/builtins"
 	"github.com/deepnoodle-ai/risor/v2/pkg/bytecode"
 	"github.com/deepnoodle-ai/risor/v2/pkg/compiler"
+	modAtexit "github.com/deepnoodle-ai/risor/v2/pkg/modules/atexit"
+	modBinary "github.com/deepnoodle-ai/risor/v2/pkg/modules/binary"
+	modCache "github.com/deepnoodle-ai/risor/v2/pkg/modules/cache"
+	modCtx "github.com/deepnoodle-ai/risor/v2/pkg/modules/ctx"
+	modDiff "github.com/deepnoodle-ai/risor/v2/pkg/modules/diff"
+	modErrors "github.com/deepnoodle-ai/risor/v2/pkg/modules/errors"
+	modEvents "github.com/deepnoodle-ai/risor/v2/pkg/modules/events"
+	modFuncs "github.com/deepnoodle-ai/risor/v2/pkg/modules/funcs"
+	modIters "github.com/deepnoodle-ai/risor/v2/pkg/modules/iters"
 	modMath "github.com/deepnoodle-ai/risor/v2/pkg/modules/math"
+	modNet "github.com/deepnoodle-ai/risor/v2/pkg/modules/net"
+	modParallel "github.com/deepnoodle-ai/risor/v2/pkg/modules/parallel"
+	modPrompt "github.com/deepnoodle-ai/risor/v2/pkg/modules/prompt"
 	modRand "github.com/deepnoodle-ai/risor/v2/pkg/modules/rand"
+	modRate "github.com/deepnoodle-ai/risor/v2/pkg/modules/rate"
 	modRegexp "github.com/deepnoodle-ai/risor/v2/pkg/modules/regexp"
+	modRetry "github.com/deepnoodle-ai/risor/v2/pkg/modules/retry"
+	modStats "github.com/deepnoodle-ai/risor/v2/pkg/modules/stats"
+	modSync "github.com/deepnoodle-ai/risor/v2/pkg/modules/sync"
+	modTable "github.com/deepnoodle-ai/risor/v2/pkg/modules/table"
+	modTask "github.com/deepnoodle-ai/risor/v2/pkg/modules/task"
+	modTerm "github.com/deepnoodle-ai/risor/v2/pkg/modules/term"
+	modValid "github.com/deepnoodle-ai/risor/v2/pkg/modules/valid"
 	"github.com/deepnoodle-ai/risor/v2/pkg/object"
 	"github.com/deepnoodle-ai/risor/v2/pkg/parser"
 	"github.com/deepnoodle-ai/risor/v2/pkg/syntax"
@@ -40,6 +63,42 @@ type (
 	TransformerFunc  = syntax.TransformerFunc
 )
 
+// Runtime errors returned by Eval and Run are *StructuredError values, which
+// record the kind of error, the location where it was raised, and the script
+// stack at that point. The error the script raised or a builtin returned is
+// kept as the Cause, so errors.Is and errors.As see through to it. Use
+// FriendlyErrorMessage for a multi-line rendering with the source line and
+// stack trace.
+type (
+	StructuredError = object.StructuredError
+	StackFrame      = object.StackFrame
+	SourceLocation  = object.SourceLocation
+)
+
+// ScriptError is an error with a code and data payload, created in scripts
+// with error(code, data). Use errors.As to retrieve it from the error
+// returned by Eval or Run.
+type ScriptError = object.ScriptError
+
+// Type is the name of a Risor value type, such as "int" or "string". It is
+// used to declare env globals with WithEnvSchema.
+type Type = object.Type
+
+// TypeAny declares an env global that may hold a value of any type.
+const TypeAny Type = compiler.AnyType
+
+// ResultMode controls what a program evaluates to when its last statement is
+// a declaration, an assignment, or a control flow expression. It is used
+// with WithResultMode.
+type ResultMode = compiler.ResultMode
+
+// Result modes.
+const (
+	ResultDefault   = compiler.ResultDefault
+	ResultLastValue = compiler.ResultLastValue
+	ResultNil       = compiler.ResultNil
+)
+
 // Re-export presets.
 var (
 	ExpressionOnly = syntax.ExpressionOnly
@@ -52,16 +111,24 @@ type Option func(*options)
 
 type options struct {
 	env          map[string]any
+	envSchema    map[string]Type
 	filename     string
+	resultMode   ResultMode
 	observer     vm.Observer
+	interceptor  vm.CallInterceptor
 	typeRegistry *object.TypeRegistry
 	rawResult    bool
+	// Request-scoped values exposed through the ctx module
+	contextValues map[string]any
 	// Resource limits
 	maxSteps      int64
 	maxStackDepth int
 	timeout       time.Duration
+	recoverPanics bool
+	frozenEnv     bool
 	// AST validation and transformation
 	syntaxConfig *syntax.SyntaxConfig
+	typeCheck    bool
 	validators   []syntax.Validator
 	transformers []syntax.Transformer
 }
@@ -81,9 +148,16 @@ func (o *options) compilerConfig() *compiler.Config {
 	if len(o.env) > 0 {
 		cfg.GlobalNames = slices.Sorted(maps.Keys(o.env))
 	}
+	if len(o.envSchema) > 0 {
+		cfg.EnvSchema = make(map[string]string, len(o.envSchema))
+		for name, typ := range o.envSchema {
+			cfg.EnvSchema[name] = string(typ)
+		}
+	}
 	if o.filename != "" {
 		cfg.Filename = o.filename
 	}
+	cfg.ResultMode = o.resultMode
 	return cfg
 }
 
@@ -95,6 +169,9 @@ func (o *options) vmOpts() []vm.Option {
 	if o.observer != nil {
 		opts = append(opts, vm.WithObserver(o.observer))
 	}
+	if o.interceptor != nil {
+		opts = append(opts, vm.WithCallInterceptor(o.interceptor))
+	}
 	if o.typeRegistry != nil {
 		opts = append(opts, vm.WithTypeRegistry(o.typeRegistry))
 	}
@@ -107,9 +184,36 @@ func (o *options) vmOpts() []vm.Option {
 	if o.timeout > 0 {
 		opts = append(opts, vm.WithTimeout(o.timeout))
 	}
+	if o.recoverPanics {
+		opts = append(opts, vm.WithPanicRecovery())
+	}
+	if o.frozenEnv {
+		opts = append(opts, vm.WithFrozenGlobals())
+	}
 	return opts
 }
 
+// withContextValues attaches a fresh context value store for one run,
+// seeded from the values on ctx and those supplied with WithContextValues.
+func (o *options) withContextValues(ctx context.Context) (context.Context, error) {
+	values := map[string]object.Object{}
+	if parent, ok := object.GetContextValues(ctx); ok {
+		maps.Copy(values, parent.All())
+	}
+	registry := o.typeRegistry
+	if registry == nil {
+		registry = object.DefaultRegistry()
+	}
+	for key, value := range o.contextValues {
+		obj, err := registry.FromGo(value)
+		if err != nil {
+			return nil, fmt.Errorf("context value %q: %w", key, err)
+		}
+		values[key] = obj
+	}
+	return object.WithContextValues(ctx, object.NewContextValues(values)), nil
+}
+
 // WithEnv provides environment variables that are made available to Risor
 // scripts. This option is additive, so multiple WithEnv options may be
 // supplied. If the same key is supplied multiple times, the last value wins.
@@ -141,6 +245,40 @@ func WithEnv(env map[string]any) Option {
 	}
 }
 
+// WithContextValues provides request-scoped values, such as trace IDs or the
+// current user, that scripts read with the ctx module. This option is
+// additive; if the same key is supplied multiple times, the last value wins.
+//
+//	result, err := risor.Eval(ctx, `ctx.get("user_id")`,
+//		risor.WithEnv(risor.Builtins()),
+//		risor.WithContextValues(map[string]any{"user_id": 42}))
+//
+// Each run gets its own copy of the values, seeded from any values already
+// on the context passed to Run. Values the script sets with ctx.set are
+// visible to Go builtins called later in the run through ContextValue.
+func WithContextValues(values map[string]any) Option {
+	return func(o *options) {
+		if o.contextValues == nil {
+			o.contextValues = map[string]any{}
+		}
+		maps.Copy(o.contextValues, values)
+	}
+}
+
+// ContextValue returns a context value of the current run as a Go value. It
+// is intended for Go builtins, which receive the run's context.
+func ContextValue(ctx context.Context, key string) (any, bool) {
+	values, ok := object.GetContextValues(ctx)
+	if !ok {
+		return nil, false
+	}
+	value, ok := values.Get(key)
+	if !ok {
+		return nil, false
+	}
+	return value.Interface(), true
+}
+
 // WithFilename sets the filename for the source code being evaluated.
 // This is used for error messages and stack traces.
 func WithFilename(filename string) Option {
@@ -159,6 +297,29 @@ func WithObserver(observer vm.Observer) Option {
 	}
 }
 
+// WithCallInterceptor sets an interceptor that is notified before and after
+// every call from the script to a builtin, module function, or Go function.
+// The interceptor can reject a call or answer it without running the
+// function, which supports audit logs, dry runs, and call policies.
+//
+// Example:
+//
+//	type denyWrites struct{}
+//
+//	func (denyWrites) BeforeCall(ctx context.Context, call *vm.GoCall) (object.Object, error) {
+//	    if call.Name == "db.write" {
+//	        return nil, errors.New("writes are disabled")
+//	    }
+//	    return nil, nil
+//	}
+//
+//	func (denyWrites) AfterCall(ctx context.Context, call *vm.GoCall, result vm.CallResult) {}
+func WithCallInterceptor(interceptor vm.CallInterceptor) Option {
+	return func(o *options) {
+		o.interceptor = interceptor
+	}
+}
+
 // WithTypeRegistry sets a custom type registry for Go/Risor type conversions.
 // Use NewTypeRegistry() to create a registry with custom converters.
 //
@@ -254,6 +415,50 @@ func WithTimeout(d time.Duration) Option {
 	}
 }
 
+// WithPanicRecovery converts panics in builtins into errors that scripts can
+// catch, which keeps a faulty host function from aborting the whole script.
+// A recovered panic is a ScriptError with the code "panic" whose Data holds
+// the panic "value" and the Go "stack". Go functions and methods wrapped by
+// the TypeRegistry, such as methods of Go structs, always recover panics
+// this way; this option extends the same guarantee to every callable.
+//
+// Example:
+//
+//	_, err := risor.Eval(ctx, source, risor.WithPanicRecovery())
+//	var se *risor.ScriptError
+//	if errors.As(err, &se) && se.Code == "panic" {
+//	    log.Print(se.Data["stack"])
+//	}
+//
+// Without this option, a panic in a builtin stops the script with an error
+// rather than crashing the host, but the script cannot catch it. Panics in
+// goroutines started by host code cannot be recovered either way.
+func WithPanicRecovery() Option {
+	return func(o *options) {
+		o.recoverPanics = true
+	}
+}
+
+// WithFrozenEnv makes lists and maps in the environment read-only to
+// scripts. Each one is frozen in place with object.Freeze, including any
+// lists and maps nested inside it. A script that tries to modify frozen data
+// gets a type error it can catch.
+//
+// Go maps and slices in the environment are converted for each run, so
+// freezing protects the converted copies. To share one set of data between
+// VMs running concurrently, put *object.List and *object.Map values in the
+// environment; once frozen, they are safe to read from many goroutines.
+//
+// Example:
+//
+//	config := object.NewMap(map[string]object.Object{"region": object.NewString("us")})
+//	risor.Eval(ctx, source, risor.WithEnv(map[string]any{"config": config}), risor.WithFrozenEnv())
+func WithFrozenEnv() Option {
+	return func(o *options) {
+		o.frozenEnv = true
+	}
+}
+
 // WithSyntax applies a syntax configuration that restricts allowed constructs.
 // The validator runs after parsing and before any transformers.
 //
@@ -270,6 +475,52 @@ func WithSyntax(config SyntaxConfig) Option {
 	}
 }
 
+// WithResultMode sets the rules for the value a program evaluates to. By
+// default, a program ending in an expression, including if, match, and try,
+// evaluates to that expression, and a program ending in a declaration or
+// assignment evaluates to nil.
+//
+//   - ResultLastValue makes declarations and assignments evaluate to the
+//     value they store, in programs, blocks, and function bodies alike, so
+//     "let total = price * qty" evaluates to the total.
+//   - ResultNil makes a program ending in if, match, or try evaluate to nil,
+//     so that only a trailing plain expression produces a result.
+//
+// The result mode is applied at compile time. It has no effect when passed
+// to Run, since the code is already compiled.
+//
+// Example:
+//
+//	result, err := risor.Eval(ctx, "let x = 1; x += 2",
+//	    risor.WithResultMode(risor.ResultLastValue),
+//	)
+//	// result == int64(3)
+func WithResultMode(mode ResultMode) Option {
+	return func(o *options) {
+		o.resultMode = mode
+	}
+}
+
+// WithTypeCheck enables checking of optional type annotations at compile
+// time, such as "let total: float = ..." and "function f(x: int) -> string".
+// Values of the wrong type that are known at compile time, such as literals
+// and annotated variables, are reported as compile errors. Types declared
+// with WithEnvSchema are used for env globals. Without this option
+// annotations are parsed but not checked, and they never affect how a
+// script runs.
+//
+// Example:
+//
+//	code, err := risor.Compile(ctx, source,
+//	    risor.WithEnvSchema(map[string]risor.Type{"price": "float"}),
+//	    risor.WithTypeCheck(),
+//	)
+func WithTypeCheck() Option {
+	return func(o *options) {
+		o.typeCheck = true
+	}
+}
+
 // WithValidator adds a custom validator to run after parsing.
 // Multiple validators can be added; they run in order.
 // Validation runs before transformation.
@@ -336,6 +587,51 @@ func NewTypeRegistry() *object.RegistryBuilder {
 	return object.NewRegistryBuilder()
 }
 
+// Bind wraps a Go function as a Risor callable. Arguments are converted to
+// the function's parameter types and results back to Risor values using the
+// default type registry; maps convert to struct parameters. A leading
+// context.Context parameter receives the script's context, a trailing error
+// result is raised as a Risor error, and multiple results are returned as a
+// list. Calls with the wrong number or types of arguments fail with an error
+// naming the function and the offending argument. Bind panics if fn is not a
+// function.
+//
+// Functions placed directly in the env are wrapped the same way; Bind is
+// useful for functions stored in modules or maps, and gives the function its
+// Go name in error messages.
+//
+// Example:
+//
+//	env := risor.Builtins()
+//	env["greet"] = risor.Bind(func(ctx context.Context, name string) (string, error) {
+//	    return "hello " + name, nil
+//	})
+func Bind(fn any) object.Object {
+	rv := reflect.ValueOf(fn)
+	if rv.Kind() != reflect.Func || rv.IsNil() {
+		panic(fmt.Sprintf("risor.Bind: expected a function, got %T", fn))
+	}
+	return object.NewGoFunc(rv, funcName(rv), object.DefaultRegistry())
+}
+
+// funcName returns a short name for a function, such as "Upload" for
+// "example.com/store.Upload" or "Client.Get" for a method value.
+func funcName(rv reflect.Value) string {
+	f := runtime.FuncForPC(rv.Pointer())
+	if f == nil {
+		return rv.Type().String()
+	}
+	name := f.Name()
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	if i := strings.Index(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+	name = strings.TrimSuffix(name, "-fm")
+	return strings.NewReplacer("(", "", ")", "", "*", "").Replace(name)
+}
+
 // Builtins returns a map of standard builtins and modules for Risor scripts.
 // This includes only the builtins and modules that are always available,
 // without pulling in additional Go dependencies.
@@ -364,18 +660,91 @@ func Builtins() map[string]any {
 
 func defaultModules() map[string]object.Object {
 	return map[string]object.Object{
+		"atexit": modAtexit.Module(),
 		"math":   modMath.Module(),
 		"rand":   modRand.Module(),
 		"regexp": modRegexp.Module(),
 	}
 }
 
+// StandardModules returns the standard modules that are not part of
+// Builtins. Their names are common identifiers such as ctx, errors, and
+// table, which scripts could no longer declare as variables if the modules
+// were always present, so hosts opt in to them explicitly:
+//
+//	env := risor.Builtins()
+//	maps.Copy(env, risor.StandardModules())
+//	result, _ := risor.Eval(ctx, source, risor.WithEnv(env))
+//
+// Each call returns new module values. Hosts that want only some of the
+// modules can copy those entries or import the module packages directly.
+// The proto, render, and unicode modules are separate Go modules, so that
+// their dependencies are only downloaded by programs that use them, and are
+// not included.
+func StandardModules() map[string]any {
+	return map[string]any{
+		"binary":   modBinary.Module(),
+		"cache":    modCache.Module(),
+		"ctx":      modCtx.Module(),
+		"diff":     modDiff.Module(),
+		"errors":   modErrors.Module(),
+		"events":   modEvents.Module(),
+		"funcs":    modFuncs.Module(),
+		"iters":    modIters.Module(),
+		"net":      modNet.Module(),
+		"parallel": modParallel.Module(),
+		"prompt":   modPrompt.Module(),
+		"rate":     modRate.Module(),
+		"retry":    modRetry.Module(),
+		"stats":    modStats.Module(),
+		"sync":     modSync.Module(),
+		"table":    modTable.Module(),
+		"task":     modTask.Module(),
+		"term":     modTerm.Module(),
+		"valid":    modValid.Module(),
+	}
+}
+
+// WithEnvSchema declares the globals a script expects from its environment,
+// along with the type of each one. This option is additive, so multiple
+// WithEnvSchema options may be supplied.
+//
+// The schema turns the env into an enforced contract:
+//
+//   - Compile makes every declared name available as a global, even if no
+//     value is supplied via WithEnv at compile time
+//   - Compile rejects references to names that are neither declared nor
+//     supplied via WithEnv, and the error lists the declared globals
+//   - Compile rejects usages that can never succeed, such as calling a
+//     global declared as an int or accessing a method a string does not have
+//   - Run rejects an env that is missing a declared global or that supplies
+//     a value of the wrong type
+//
+// Types are Risor type names like "int", "float", "string", "list", or
+// "map". The type "function" accepts any callable value, including Go
+// functions. Use TypeAny to declare a global without constraining its type.
+//
+// Example:
+//
+//	schema := map[string]risor.Type{"user": "map", "limit": "int"}
+//	code, err := risor.Compile(ctx, source, risor.WithEnvSchema(schema))
+//	...
+//	result, err := risor.Run(ctx, code, risor.WithEnv(env))
+func WithEnvSchema(schema map[string]Type) Option {
+	return func(o *options) {
+		if o.envSchema == nil {
+			o.envSchema = make(map[string]Type, len(schema))
+		}
+		maps.Copy(o.envSchema, schema)
+	}
+}
+
 // validateGlobals checks that the env keys match the globals expected by the
 // bytecode. Returns an error if there's a mismatch.
 //
 // This uses EnvKeys() which tracks only the globals that were provided via
 // the environment at compile time (not globals defined within the script).
-func validateGlobals(code *bytecode.Code, env map[string]any) error {
+func validateGlobals(code *bytecode.Code, env map[string]any, registry *object.TypeRegistry) error {
 	// EnvKeys returns only the globals from compile-time env,
 	// not script-defined globals like functions or let bindings.
 	required := code.EnvKeys()
@@ -402,9 +771,45 @@ func validateGlobals(code *bytecode.Code, env map[string]any) error {
 			missing, required)
 	}
 
+	return validateEnvSchema(code.EnvSchema(), env, registry)
+}
+
+// validateEnvSchema checks that each env value has the type declared for it
+// in the schema the bytecode was compiled with.
+func validateEnvSchema(schema map[string]string, env map[string]any, registry *object.TypeRegistry) error {
+	if len(schema) == 0 {
+		return nil
+	}
+	if registry == nil {
+		registry = object.DefaultRegistry()
+	}
+	for _, name := range slices.Sorted(maps.Keys(schema)) {
+		want := schema[name]
+		if want == compiler.AnyType {
+			continue
+		}
+		value, err := registry.FromGo(env[name])
+		if err != nil {
+			return fmt.Errorf("env value %q: %w", name, err)
+		}
+		if !matchesEnvType(value, want) {
+			return fmt.Errorf("env value %q has type %s (env schema requires %s)",
+				name, value.Type(), want)
+		}
+	}
 	return nil
 }
 
+// matchesEnvType reports whether the value satisfies the schema type. The
+// "function" type accepts any callable value.
+func matchesEnvType(value object.Object, want string) bool {
+	if want == string(object.FUNCTION) {
+		_, ok := value.(object.Callable)
+		return ok && value.Type() != object.MODULE
+	}
+	return string(value.Type()) == want
+}
+
 // Compile parses and compiles source code into executable bytecode.
 // The returned Code is immutable and safe for concurrent use.
 // Multiple goroutines can execute the same Code simultaneously.
@@ -454,6 +859,17 @@ func Compile(ctx context.Context, source string, opts ...Option) (*bytecode.Code
 		}
 	}
 
+	// Check type annotations (if enabled)
+	if o.typeCheck {
+		globals := make(map[string]string, len(o.envSchema))
+		for name, typ := range o.envSchema {
+			globals[name] = string(typ)
+		}
+		if errs := syntax.NewTypeChecker(globals).Validate(program); len(errs) > 0 {
+			return nil, syntax.NewValidationErrors(errs)
+		}
+	}
+
 	// Run custom validators
 	for _, v := range o.validators {
 		if errs := v.Validate(program); len(errs) > 0 {
@@ -513,7 +929,12 @@ func Run(ctx context.Context, code *bytecode.Code, opts ...Option) (any, error)
 	o := collectOptions(opts...)
 
 	// Validate that env keys match the globals expected by the bytecode
-	if err := validateGlobals(code, o.env); err != nil {
+	if err := validateGlobals(code, o.env, o.typeRegistry); err != nil {
+		return nil, err
+	}
+
+	ctx, err := o.withContextValues(ctx)
+	if err != nil {
 		return nil, err
 	}
 
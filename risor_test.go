@@ -3,6 +3,9 @@ package risor
 import (
 	"context"
 	"errors"
+	"fmt"
+	"io"
+	"maps"
 	"reflect"
 	"strings"
 	"sync"
@@ -11,6 +14,7 @@ import (
 
 	"github.com/deepnoodle-ai/risor/v2/pkg/ast"
 	"github.com/deepnoodle-ai/risor/v2/pkg/object"
+	"github.com/deepnoodle-ai/risor/v2/pkg/vm"
 	"github.com/deepnoodle-ai/wonton/assert"
 )
 
@@ -232,6 +236,29 @@ func TestWithBuiltins(t *testing.T) {
 	}
 }
 
+// standardEnv returns the builtins along with the optional standard modules.
+func standardEnv() map[string]any {
+	env := Builtins()
+	maps.Copy(env, StandardModules())
+	return env
+}
+
+func TestStandardModulesAreOptIn(t *testing.T) {
+	env := Builtins()
+	for name := range StandardModules() {
+		_, ok := env[name]
+		assert.False(t, ok, name)
+	}
+	// Scripts can use the module names as variables
+	result, err := Eval(context.Background(), "let ctx = 1; let errors = [ctx]; errors", WithEnv(env))
+	assert.Nil(t, err)
+	assert.Equal(t, result, []any{int64(1)})
+
+	result, err = Eval(context.Background(), `ctx.has("k")`, WithEnv(standardEnv()))
+	assert.Nil(t, err)
+	assert.Equal(t, result, false)
+}
+
 func TestWithEnv(t *testing.T) {
 	// Test providing custom environment
 	result, err := Eval(context.Background(), "x + y", WithEnv(map[string]any{
@@ -416,6 +443,36 @@ func TestWithRawResult(t *testing.T) {
 	assert.Equal(t, list.Len().Value(), int64(3))
 }
 
+func TestWithResultMode(t *testing.T) {
+	ctx := context.Background()
+	source := "let total = price * qty"
+	env := WithEnv(map[string]any{"price": 3, "qty": 4})
+
+	result, err := Eval(ctx, source, env)
+	assert.Nil(t, err)
+	assert.Nil(t, result)
+
+	result, err = Eval(ctx, source, env, WithResultMode(ResultLastValue))
+	assert.Nil(t, err)
+	assert.Equal(t, result, int64(12))
+
+	rule := "if (qty > 2) { \"bulk\" }"
+	result, err = Eval(ctx, rule, env)
+	assert.Nil(t, err)
+	assert.Equal(t, result, "bulk")
+
+	result, err = Eval(ctx, rule, env, WithResultMode(ResultNil))
+	assert.Nil(t, err)
+	assert.Nil(t, result)
+
+	// The mode is applied at compile time
+	code, err := Compile(ctx, source, env, WithResultMode(ResultLastValue))
+	assert.Nil(t, err)
+	result, err = Run(ctx, code, env)
+	assert.Nil(t, err)
+	assert.Equal(t, result, int64(12))
+}
+
 // Test WithRawResult for types without Go equivalent
 func TestWithRawResultClosure(t *testing.T) {
 	// Closures normally return their Inspect() string
@@ -1518,6 +1575,133 @@ func TestCompileRunSeparation(t *testing.T) {
 	}
 }
 
+func TestEnvSchema(t *testing.T) {
+	ctx := context.Background()
+	schema := map[string]Type{"name": "string", "limit": "int", "fn": "function"}
+
+	// Declared names are globals even without a compile-time env
+	code, err := Compile(ctx, "fn(name.to_upper(), limit)", WithEnvSchema(schema))
+	assert.Nil(t, err)
+	assert.Equal(t, code.EnvSchema(), map[string]string{
+		"name": "string", "limit": "int", "fn": "function",
+	})
+
+	join := object.NewBuiltin("join", func(ctx context.Context, args ...object.Object) (object.Object, error) {
+		return object.NewString(args[0].Inspect() + ":" + args[1].Inspect()), nil
+	})
+	result, err := Run(ctx, code, WithEnv(map[string]any{
+		"name":  "risor",
+		"limit": 3,
+		"fn":    join,
+	}))
+	assert.Nil(t, err)
+	assert.Equal(t, result, `"RISOR":3`)
+}
+
+func TestEnvSchemaCompileErrors(t *testing.T) {
+	ctx := context.Background()
+	schema := WithEnvSchema(map[string]Type{"count": "int", "name": "string", "data": TypeAny})
+
+	tests := []struct {
+		input   string
+		wantErr string
+	}{
+		{"count()", "cannot call count: env schema declares it as int"},
+		{"count.foo", `int has no attribute "foo"`},
+		{"name.nope()", `string has no attribute "nope"`},
+	}
+	for _, tt := range tests {
+		_, err := Compile(ctx, tt.input, schema)
+		assert.NotNil(t, err, tt.input)
+		assert.Contains(t, err.Error(), tt.wantErr)
+	}
+
+	// Valid usages, untyped globals, and shadowed names compile
+	for _, input := range []string{
+		"name.to_upper()",
+		"count + 1",
+		"data.anything()",
+		"count?.foo",
+		"function f(count) { return count() }",
+	} {
+		_, err := Compile(ctx, input, schema)
+		assert.Nil(t, err, input)
+	}
+}
+
+func TestTypeAnnotations(t *testing.T) {
+	ctx := context.Background()
+	source := `function half(x: int) -> float { x / 2.0 }
+let label: string = half(3)
+label`
+
+	// Annotations are not checked or enforced by default
+	result, err := Eval(ctx, source)
+	assert.Nil(t, err)
+	assert.Equal(t, result, 1.5)
+
+	_, err = Compile(ctx, source, WithTypeCheck())
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "cannot use half(3) (type float) as string in declaration of label")
+
+	// Env schema types are known to the checker
+	schema := WithEnvSchema(map[string]Type{"qty": "int"})
+	_, err = Compile(ctx, `let s: string = qty + 1`, schema, WithTypeCheck())
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "(type int) as string")
+
+	result, err = Eval(ctx, `let total: float = qty * 1.5; total`, schema,
+		WithTypeCheck(), WithEnv(map[string]any{"qty": 2}))
+	assert.Nil(t, err)
+	assert.Equal(t, result, 3.0)
+}
+
+func TestEnvSchemaUndefinedVariable(t *testing.T) {
+	ctx := context.Background()
+	schema := WithEnvSchema(map[string]Type{"price": "float", "quantity": "int"})
+
+	// Undeclared names are rejected at compile time, including inside
+	// function bodies that never run
+	for _, input := range []string{
+		"price * markup",
+		"function f() { return markup }\nprice",
+	} {
+		_, err := Compile(ctx, input, schema)
+		assert.NotNil(t, err, input)
+		assert.Contains(t, err.Error(), `undefined variable "markup" (not declared in the env schema)`)
+	}
+
+	_, err := Compile(ctx, "quanity + 1", schema)
+	// The friendly message lists the declared names
+	friendly, ok := err.(object.FriendlyError)
+	assert.True(t, ok)
+	assert.Contains(t, friendly.FriendlyErrorMessage(), "declared env globals: price, quantity")
+}
+
+func TestEnvSchemaRunValidation(t *testing.T) {
+	ctx := context.Background()
+	code, err := Compile(ctx, "count + 1", WithEnvSchema(map[string]Type{"count": "int"}))
+	assert.Nil(t, err)
+
+	_, err = Run(ctx, code)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "missing required globals")
+
+	_, err = Run(ctx, code, WithEnv(map[string]any{"count": "ten"}))
+	assert.NotNil(t, err)
+	assert.Equal(t, err.Error(), `env value "count" has type string (env schema requires int)`)
+
+	result, err := Run(ctx, code, WithEnv(map[string]any{"count": 9}))
+	assert.Nil(t, err)
+	assert.Equal(t, result, int64(10))
+
+	// Eval applies both the compile-time and run-time checks
+	_, err = Eval(ctx, "count", WithEnvSchema(map[string]Type{"count": "int"}),
+		WithEnv(map[string]any{"count": 1.5}))
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "env schema requires int")
+}
+
 // =============================================================================
 // SYNTAX VALIDATION TESTS
 // =============================================================================
@@ -2030,3 +2214,444 @@ func TestBasicScriptingLoops(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, result, int64(6)) // 1 + 2 + 3
 }
+
+func TestStreamsInEnv(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("string passed as io.Reader", func(t *testing.T) {
+		var uploaded string
+		env := map[string]any{
+			"upload": func(key string, body io.Reader) error {
+				data, err := io.ReadAll(body)
+				uploaded = key + "=" + string(data)
+				return err
+			},
+		}
+		_, err := Eval(ctx, `upload("notes.txt", "hello")`, WithEnv(env))
+		assert.Nil(t, err)
+		assert.Equal(t, uploaded, "notes.txt=hello")
+	})
+
+	t.Run("reader streamed to script", func(t *testing.T) {
+		env := Builtins()
+		env["body"] = strings.NewReader("alpha\nbeta\ngamma\n")
+		result, err := Eval(ctx, `
+			let first = body.read_line()
+			let rest = [...body]
+			[first, len(rest)]
+		`, WithEnv(env))
+		assert.Nil(t, err)
+		assert.Equal(t, result, []any{"alpha", int64(2)})
+	})
+
+	t.Run("each line", func(t *testing.T) {
+		env := Builtins()
+		env["body"] = strings.NewReader("1\n2\n3\n")
+		result, err := Eval(ctx, `
+			let total = 0
+			body.each(line => { total += int(line) })
+			total
+		`, WithEnv(env))
+		assert.Nil(t, err)
+		assert.Equal(t, result, int64(6))
+	})
+}
+
+type bindLevel int
+
+type bindOptions struct {
+	Name  string `risor:"name"`
+	Level bindLevel
+	Tags  []string `risor:"tags"`
+}
+
+func bindDescribe(ctx context.Context, opts bindOptions, extra ...int) (string, error) {
+	if opts.Name == "" {
+		return "", errors.New("name is required")
+	}
+	return fmt.Sprintf("%s:%d:%v:%v", opts.Name, opts.Level, opts.Tags, extra), nil
+}
+
+func TestBind(t *testing.T) {
+	ctx := context.Background()
+	env := Builtins()
+	env["lib"] = map[string]any{"describe": Bind(bindDescribe)}
+
+	t.Run("struct param and variadic args", func(t *testing.T) {
+		result, err := Eval(ctx, `lib.describe({name: "a", Level: 2, tags: ["x"]}, 1, 2)`, WithEnv(env))
+		assert.Nil(t, err)
+		assert.Equal(t, result, "a:2:[x]:[1 2]")
+	})
+
+	t.Run("error return is raised", func(t *testing.T) {
+		result, err := Eval(ctx, `try { lib.describe({}) } catch (e) { e.message() }`, WithEnv(env))
+		assert.Nil(t, err)
+		assert.Equal(t, result, "name is required")
+	})
+
+	t.Run("argument count error", func(t *testing.T) {
+		_, err := Eval(ctx, `lib.describe()`, WithEnv(env))
+		assert.NotNil(t, err)
+		assert.Contains(t, err.Error(), "bindDescribe: expected at least 1 argument(s), got 0")
+	})
+
+	t.Run("argument type error", func(t *testing.T) {
+		_, err := Eval(ctx, `lib.describe({name: "a"}, "x")`, WithEnv(env))
+		assert.NotNil(t, err)
+		assert.Contains(t, err.Error(), "bindDescribe: variadic argument 2 (int)")
+
+		_, err = Eval(ctx, `lib.describe({nme: "a"})`, WithEnv(env))
+		assert.NotNil(t, err)
+		assert.Contains(t, err.Error(), `has no field "nme"`)
+	})
+
+	t.Run("method value", func(t *testing.T) {
+		var sb strings.Builder
+		fn := Bind(sb.WriteString)
+		assert.Equal(t, fn.(*object.GoFunc).Name(), "Builder.WriteString")
+	})
+
+	t.Run("not a function", func(t *testing.T) {
+		assert.Panics(t, func() { Bind(42) })
+	})
+}
+
+type chanHost struct {
+	Events  chan string
+	Results chan<- int
+	Lookup  func(key string) (string, error)
+}
+
+func TestGoChannelsAndFuncFields(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("channel fields", func(t *testing.T) {
+		events := make(chan string, 3)
+		events <- "a"
+		events <- "b"
+		close(events)
+		results := make(chan int, 1)
+		env := Builtins()
+		env["host"] = &chanHost{Events: events, Results: results}
+		result, err := Eval(ctx, `
+			let seen = [...host.Events]
+			host.Results.send(len(seen))
+			seen
+		`, WithEnv(env))
+		assert.Nil(t, err)
+		assert.Equal(t, result, []any{"a", "b"})
+		assert.Equal(t, <-results, 2)
+	})
+
+	t.Run("receive is cancelled with the script", func(t *testing.T) {
+		env := map[string]any{"host": &chanHost{Events: make(chan string)}}
+		_, err := Eval(ctx, `host.Events.recv()`, WithEnv(env), WithTimeout(20*time.Millisecond))
+		assert.NotNil(t, err)
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	})
+
+	t.Run("func field", func(t *testing.T) {
+		env := map[string]any{"host": &chanHost{Lookup: func(key string) (string, error) {
+			if key == "" {
+				return "", errors.New("empty key")
+			}
+			return "value:" + key, nil
+		}}}
+		result, err := Eval(ctx, `host.Lookup("k")`, WithEnv(env))
+		assert.Nil(t, err)
+		assert.Equal(t, result, "value:k")
+
+		_, err = Eval(ctx, `host.Lookup(1)`, WithEnv(env))
+		assert.NotNil(t, err)
+		assert.Contains(t, err.Error(), "chanHost.Lookup: argument 1 (string)")
+	})
+
+	t.Run("script function as callback", func(t *testing.T) {
+		env := map[string]any{"each_pair": func(m map[string]int, fn func(string, int) error) error {
+			for _, k := range []string{"a", "b"} {
+				if err := fn(k, m[k]); err != nil {
+					return err
+				}
+			}
+			return nil
+		}}
+		result, err := Eval(ctx, `
+			let out = []
+			each_pair({a: 1, b: 2}, (k, v) => { out.append([k, v]) })
+			out
+		`, WithEnv(env))
+		assert.Nil(t, err)
+		assert.Equal(t, result, []any{[]any{"a", int64(1)}, []any{"b", int64(2)}})
+	})
+}
+
+func TestContextValues(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("script reads host values", func(t *testing.T) {
+		result, err := Eval(ctx, `[ctx.get("trace_id"), ctx.get("missing", "none"), ctx.has("user_id")]`,
+			WithEnv(standardEnv()),
+			WithContextValues(map[string]any{"trace_id": "abc123", "user_id": 42}))
+		assert.Nil(t, err)
+		assert.Equal(t, result, []any{"abc123", "none", true})
+	})
+
+	t.Run("builtins read script values", func(t *testing.T) {
+		var seen any
+		env := standardEnv()
+		env["record"] = object.NewBuiltin("record", func(ctx context.Context, args ...object.Object) (object.Object, error) {
+			seen, _ = ContextValue(ctx, "step")
+			return object.Nil, nil
+		})
+		_, err := Eval(ctx, `ctx.set("step", "validate"); record()`, WithEnv(env))
+		assert.Nil(t, err)
+		assert.Equal(t, seen, "validate")
+	})
+
+	t.Run("values from the parent context", func(t *testing.T) {
+		parent := object.WithContextValues(ctx, object.NewContextValues(map[string]object.Object{
+			"tenant": object.NewString("acme"),
+		}))
+		result, err := Eval(parent, `ctx.set("tenant", "other"); ctx.keys()`,
+			WithEnv(standardEnv()),
+			WithContextValues(map[string]any{"user_id": 1}))
+		assert.Nil(t, err)
+		assert.Equal(t, result, []any{"tenant", "user_id"})
+
+		// Runs get their own copy, so the parent store is unchanged
+		tenant, _ := ContextValue(parent, "tenant")
+		assert.Equal(t, tenant, "acme")
+	})
+
+	t.Run("runs are isolated", func(t *testing.T) {
+		code, err := Compile(ctx, `let before = ctx.has("k"); ctx.set("k", 1); before`, WithEnv(standardEnv()))
+		assert.Nil(t, err)
+		for i := 0; i < 2; i++ {
+			result, err := Run(ctx, code, WithEnv(standardEnv()))
+			assert.Nil(t, err)
+			assert.Equal(t, result, false)
+		}
+	})
+
+	t.Run("unconvertible value", func(t *testing.T) {
+		_, err := Eval(ctx, `1`, WithContextValues(map[string]any{"bad": map[int]string{1: "x"}}))
+		assert.Error(t, err, `context value "bad": unsupported map key type: int (only string keys supported)`)
+	})
+}
+
+func TestScriptError(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("caught in script", func(t *testing.T) {
+		result, err := Eval(ctx, `
+		try {
+			throw error("not_found", {code: 404, resource: "user/7"})
+		} catch e {
+			[e.code(), e.message(), e.data().code, e.data().resource]
+		}
+		`, WithEnv(standardEnv()))
+		assert.Nil(t, err)
+		assert.Equal(t, result, []any{"not_found", "not_found", int64(404), "user/7"})
+	})
+
+	t.Run("returned to host", func(t *testing.T) {
+		_, err := Eval(ctx, `
+		function load(id) { throw error("not_found", {resource: id}) }
+		load("user/7")
+		`, WithEnv(standardEnv()))
+		var se *ScriptError
+		assert.True(t, errors.As(err, &se))
+		assert.Equal(t, se.Code, "not_found")
+		assert.Equal(t, se.Data, map[string]any{"resource": "user/7"})
+	})
+
+	t.Run("returned by a builtin", func(t *testing.T) {
+		env := standardEnv()
+		env["fetch"] = object.NewBuiltin("fetch", func(ctx context.Context, args ...object.Object) (object.Object, error) {
+			return nil, &ScriptError{Code: "unavailable", Message: "service unavailable"}
+		})
+		result, err := Eval(ctx, `try { fetch() } catch e { [e.code(), e.message()] }`, WithEnv(env))
+		assert.Nil(t, err)
+		assert.Equal(t, result, []any{"unavailable", "service unavailable"})
+	})
+
+	t.Run("formatted message without data", func(t *testing.T) {
+		result, err := Eval(ctx, `let e = error("bad value %d", 3); [e.message(), e.code(), e.data()]`,
+			WithEnv(standardEnv()))
+		assert.Nil(t, err)
+		assert.Equal(t, result, []any{"bad value 3", nil, nil})
+	})
+}
+
+func TestErrorsModule(t *testing.T) {
+	ctx := context.Background()
+	result, err := Eval(ctx, `
+	let not_found = error("not found")
+	function load(id) {
+		try {
+			throw not_found
+		} catch e {
+			throw errors.wrap(e, "loading %s", id)
+		}
+	}
+	let e = try { load("user/7") } catch e { e }
+	[e.message(), e.cause().message(), errors.is(e, not_found), errors.is(e, error("not found")),
+		errors.is(not_found, error("not found")) == (not_found == error("not found")),
+		errors.is(e, error("loading"))]
+	`, WithEnv(standardEnv()))
+	assert.Nil(t, err)
+	assert.Equal(t, result, []any{"loading user/7: not found", "not found", true, true, true, false})
+
+	result, err = Eval(ctx, `
+	let e = try { 1 + "a" } catch e { errors.wrap(e, "adding") }
+	[errors.as(e, "type").kind(), errors.as(e, "value")]
+	`, WithEnv(standardEnv()))
+	assert.Nil(t, err)
+	assert.Equal(t, result, []any{"type error", nil})
+
+	// Wrapped errors returned to the host keep their chain
+	sentinel := errors.New("unavailable")
+	env := standardEnv()
+	env["fetch"] = object.NewBuiltin("fetch", func(ctx context.Context, args ...object.Object) (object.Object, error) {
+		return nil, sentinel
+	})
+	_, err = Eval(ctx, `try { fetch() } catch e { throw errors.wrap(e, "fetching") }`, WithEnv(env))
+	assert.True(t, errors.Is(err, sentinel))
+}
+
+func TestRuntimeErrorStackTrace(t *testing.T) {
+	ctx := context.Background()
+	sentinel := errors.New("permission denied")
+	env := Builtins()
+	env["open"] = object.NewBuiltin("open", func(ctx context.Context, args ...object.Object) (object.Object, error) {
+		return nil, sentinel
+	})
+	source := `function load(name) {
+	return open(name)
+}
+["a"].map(load)`
+
+	_, err := Eval(ctx, source, WithEnv(env), WithFilename("load.risor"))
+	var se *StructuredError
+	assert.True(t, errors.As(err, &se))
+	assert.True(t, errors.Is(err, sentinel))
+	assert.Equal(t, se.Message, "permission denied")
+	assert.Equal(t, se.Location.Filename, "load.risor")
+	assert.Equal(t, se.Location.Line, 2)
+	assert.Equal(t, se.Location.Source, "\treturn open(name)")
+	functions := make([]string, len(se.Stack))
+	for i, frame := range se.Stack {
+		functions[i] = frame.Function
+	}
+	assert.Equal(t, functions, []string{"load", "__main__"})
+	assert.Contains(t, se.FriendlyErrorMessage(), "at load (load.risor:2:")
+}
+
+type panickyService struct{}
+
+func (s *panickyService) Lookup(key string) string {
+	panic(fmt.Errorf("lookup %s: not initialized", key))
+}
+
+func TestPanicRecovery(t *testing.T) {
+	ctx := context.Background()
+	env := Builtins()
+	env["svc"] = &panickyService{}
+
+	result, err := Eval(ctx, `
+	let e = try { svc.Lookup("a") } catch e { e }
+	[e.code(), e.message()]
+	`, WithEnv(env), WithPanicRecovery())
+	assert.Nil(t, err)
+	assert.Equal(t, result, []any{"panic", "panic in panickyService.Lookup: lookup a: not initialized"})
+
+	_, err = Eval(ctx, `svc.Lookup("b")`, WithEnv(env), WithPanicRecovery())
+	var se *ScriptError
+	assert.True(t, errors.As(err, &se))
+	assert.Equal(t, se.Code, "panic")
+	assert.Contains(t, se.Data["stack"].(string), "panickyService")
+	assert.Equal(t, se.Cause.Error(), "lookup b: not initialized")
+}
+
+// auditInterceptor logs Go calls and blocks calls to deny.
+type auditInterceptor struct {
+	deny object.Callable
+	log  []string
+}
+
+func (a *auditInterceptor) BeforeCall(ctx context.Context, call *vm.GoCall) (object.Object, error) {
+	if a.deny != nil && call.Function == a.deny {
+		return nil, fmt.Errorf("%s is not allowed", call.Name)
+	}
+	return nil, nil
+}
+
+func (a *auditInterceptor) AfterCall(ctx context.Context, call *vm.GoCall, result vm.CallResult) {
+	a.log = append(a.log, fmt.Sprintf("%s(%d) err=%v", call.Name, len(call.Args), result.Err))
+}
+
+func TestWithCallInterceptor(t *testing.T) {
+	ctx := context.Background()
+	var sent []string
+	send := Bind(func(msg string) string {
+		sent = append(sent, msg)
+		return "ok"
+	})
+	env := Builtins()
+	env["send"] = send
+
+	audit := &auditInterceptor{deny: send.(object.Callable)}
+	_, err := Eval(ctx, `let n = len("abc")
+send(string(n))`, WithEnv(env), WithCallInterceptor(audit))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "TestWithCallInterceptor.func1 is not allowed")
+	assert.Len(t, sent, 0)
+	assert.Equal(t, audit.log, []string{
+		"len(1) err=<nil>",
+		"string(1) err=<nil>",
+		"TestWithCallInterceptor.func1(1) err=TestWithCallInterceptor.func1 is not allowed",
+	})
+
+	audit = &auditInterceptor{}
+	result, err := Eval(ctx, `send("hi")`, WithEnv(env), WithCallInterceptor(audit))
+	assert.Nil(t, err)
+	assert.Equal(t, result, "ok")
+	assert.Equal(t, sent, []string{"hi"})
+	assert.Len(t, audit.log, 1)
+}
+
+func TestWithFrozenEnv(t *testing.T) {
+	ctx := context.Background()
+	config := object.NewMap(map[string]object.Object{
+		"hosts": object.NewList([]object.Object{object.NewString("a")}),
+	})
+	env := map[string]any{"config": config}
+	source := `
+let errors = []
+try { config.hosts.append("b") } catch e { errors.append(e.message()) }
+try { config["port"] = 80 } catch e { errors.append(e.message()) }
+[errors, config.hosts.copy().append("c"), config.hosts]
+`
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := Eval(ctx, source, WithEnv(env), WithFrozenEnv())
+			assert.Nil(t, err)
+			assert.Equal(t, result, []any{
+				[]any{"type error: list is frozen", "type error: map is frozen"},
+				[]any{"a", "c"},
+				[]any{"a"},
+			})
+		}()
+	}
+	wg.Wait()
+	assert.True(t, object.IsFrozen(config))
+
+	// Without the option, the environment stays writable
+	writable := object.NewList(nil)
+	_, err := Eval(ctx, `items.append(1)`, WithEnv(map[string]any{"items": writable}))
+	assert.Nil(t, err)
+	assert.Equal(t, writable.Inspect(), "[1]")
+}
@@ -0,0 +1,85 @@
+package risor
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"github.com/deepnoodle-ai/risor/v2/pkg/bytecode"
+)
+
+// Script holds compiled code that can be replaced while it is in use. It is
+// intended for long-running services that reload user-edited scripts, such as
+// rules or hooks, while serving traffic.
+//
+// The options given to NewScript are kept and reused by every Reload and Run,
+// so new source is compiled against the same env, schema, and syntax
+// configuration as the code it replaces. A reload that fails to compile
+// leaves the current code in place.
+//
+// Run always executes a complete version of the script: calls that start
+// before a Reload finish on the old code, and calls that start after it use
+// the new code. Each Run starts from the env given in the options, so no
+// global state carries over from one run or version to the next. Script is
+// safe for concurrent use.
+type Script struct {
+	opts    []Option
+	mu      sync.Mutex // serializes reloads, including compilation
+	current atomic.Pointer[scriptVersion]
+}
+
+type scriptVersion struct {
+	code    *bytecode.Code
+	version int
+}
+
+// NewScript compiles source and returns a Script holding the result. The
+// options are used for compilation and as the base options for Run.
+func NewScript(ctx context.Context, source string, opts ...Option) (*Script, error) {
+	code, err := Compile(ctx, source, opts...)
+	if err != nil {
+		return nil, err
+	}
+	s := &Script{opts: slices.Clone(opts)}
+	s.current.Store(&scriptVersion{code: code, version: 1})
+	return s, nil
+}
+
+// Reload compiles source with the script's options and, if compilation
+// succeeds, atomically makes it the current code. On error the current code
+// is unchanged and the error is returned. Concurrent reloads take effect in
+// the order they acquire the script, so a slow compile cannot replace code
+// from a reload that started after it.
+func (s *Script) Reload(ctx context.Context, source string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	code, err := Compile(ctx, source, s.opts...)
+	if err != nil {
+		return err
+	}
+	prev := s.current.Load()
+	s.current.Store(&scriptVersion{code: code, version: prev.version + 1})
+	return nil
+}
+
+// Code returns the current compiled code.
+func (s *Script) Code() *bytecode.Code {
+	return s.current.Load().code
+}
+
+// Version returns the number of times the script has been successfully
+// compiled, starting at 1 for the code given to NewScript.
+func (s *Script) Version() int {
+	return s.current.Load().version
+}
+
+// Run executes the current code. The options are applied after the script's
+// own options, so they can add env values or override limits for this call.
+// See Run for how the result is converted.
+func (s *Script) Run(ctx context.Context, opts ...Option) (any, error) {
+	code := s.current.Load().code
+	all := make([]Option, 0, len(s.opts)+len(opts))
+	all = append(append(all, s.opts...), opts...)
+	return Run(ctx, code, all...)
+}
@@ -0,0 +1,110 @@
+package risor
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/deepnoodle-ai/wonton/assert"
+)
+
+func TestScriptReload(t *testing.T) {
+	ctx := context.Background()
+	env := WithEnv(map[string]any{"amount": 10})
+
+	script, err := NewScript(ctx, "amount * 2", env)
+	assert.Nil(t, err)
+	assert.Equal(t, script.Version(), 1)
+
+	result, err := script.Run(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, result, int64(20))
+
+	assert.Nil(t, script.Reload(ctx, "amount * 3"))
+	assert.Equal(t, script.Version(), 2)
+
+	result, err = script.Run(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, result, int64(30))
+
+	// Per-call options are applied after the script's own options
+	result, err = script.Run(ctx, WithEnv(map[string]any{"amount": 5}))
+	assert.Nil(t, err)
+	assert.Equal(t, result, int64(15))
+}
+
+func TestScriptReloadErrorKeepsCode(t *testing.T) {
+	ctx := context.Background()
+	env := WithEnv(map[string]any{"amount": 10})
+
+	script, err := NewScript(ctx, "amount + 1", env)
+	assert.Nil(t, err)
+	code := script.Code()
+
+	// Syntax errors and references to unknown globals are both rejected
+	assert.NotNil(t, script.Reload(ctx, "amount +"))
+	assert.NotNil(t, script.Reload(ctx, "missing + 1"))
+	assert.Equal(t, script.Version(), 1)
+	assert.True(t, script.Code() == code)
+
+	result, err := script.Run(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, result, int64(11))
+}
+
+func TestNewScriptError(t *testing.T) {
+	script, err := NewScript(context.Background(), "1 +")
+	assert.NotNil(t, err)
+	assert.Nil(t, script)
+}
+
+func TestScriptReloadSchema(t *testing.T) {
+	ctx := context.Background()
+	opts := []Option{
+		WithEnvSchema(map[string]Type{"limit": "int"}),
+		WithEnv(map[string]any{"limit": 3}),
+	}
+	script, err := NewScript(ctx, "limit + 1", opts...)
+	assert.Nil(t, err)
+
+	// The schema given to NewScript still applies to reloaded code
+	assert.NotNil(t, script.Reload(ctx, "limit()"))
+	assert.Nil(t, script.Reload(ctx, "limit * 2"))
+
+	result, err := script.Run(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, result, int64(6))
+}
+
+func TestScriptConcurrentReload(t *testing.T) {
+	ctx := context.Background()
+	script, err := NewScript(ctx, "1")
+	assert.Nil(t, err)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 100)
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			errs <- script.Reload(ctx, "2")
+		}()
+		go func() {
+			defer wg.Done()
+			result, err := script.Run(ctx)
+			if err != nil {
+				errs <- err
+				return
+			}
+			if result != int64(1) && result != int64(2) {
+				t.Errorf("unexpected result: %v", result)
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		assert.Nil(t, err)
+	}
+	assert.Equal(t, script.Version(), 11)
+}